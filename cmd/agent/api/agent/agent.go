@@ -14,6 +14,9 @@ import (
 	"html"
 	"net/http"
 	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 
@@ -24,8 +27,15 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
 	"github.com/DataDog/datadog-agent/pkg/flare"
+	"github.com/DataDog/datadog-agent/pkg/logs"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+	"github.com/DataDog/datadog-agent/pkg/networkpath"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -44,8 +54,12 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/stop", stopAgent).Methods("POST")
 	r.HandleFunc("/status", getStatus).Methods("GET")
 	r.HandleFunc("/dogstatsd-stats", getDogstatsdStats).Methods("GET")
+	r.HandleFunc("/stream-logs", streamLogs).Methods("POST")
+	r.HandleFunc("/network-path", getNetworkPath).Methods("GET")
+	r.HandleFunc("/diagnose/metric", traceMetric).Methods("GET", "POST", "DELETE")
 	r.HandleFunc("/status/formatted", getFormattedStatus).Methods("GET")
 	r.HandleFunc("/status/health", getHealth).Methods("GET")
+	r.HandleFunc("/status/section/{section}", getStatusSection).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusGetterHandler).Methods("GET")
 	r.HandleFunc("/{component}/status", componentStatusHandler).Methods("POST")
 	r.HandleFunc("/{component}/configs", componentConfigHandler).Methods("GET")
@@ -55,6 +69,7 @@ func SetupHandlers(r *mux.Router) *mux.Router {
 	r.HandleFunc("/config/list-runtime", getRuntimeConfigurableSettings).Methods("GET")
 	r.HandleFunc("/config/{setting}", getRuntimeConfig).Methods("GET")
 	r.HandleFunc("/config/{setting}", setRuntimeConfig).Methods("POST")
+	r.HandleFunc("/check/{id}/run", runCheckInstance).Methods("POST")
 	r.HandleFunc("/tagger-list", getTaggerList).Methods("GET")
 	r.HandleFunc("/secrets", secretInfo).Methods("GET")
 
@@ -137,6 +152,37 @@ func componentStatusHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func getStatusSection(w http.ResponseWriter, r *http.Request) {
+	section := mux.Vars(r)["section"]
+	log.Infof("Got a request for the %s section of the status. Making status.", section)
+	w.Header().Set("Content-Type", "application/json")
+
+	s, err := status.GetStatus()
+	if err != nil {
+		log.Errorf("Error getting status. Error: %v, Status: %v", err, s)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	filtered, err := status.FilterSection(s, section)
+	if err != nil {
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 404)
+		return
+	}
+
+	jsonStats, err := json.Marshal(filtered)
+	if err != nil {
+		log.Errorf("Error marshalling status. Error: %v, Status: %v", err, filtered)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	w.Write(jsonStats)
+}
+
 func getStatus(w http.ResponseWriter, r *http.Request) {
 	log.Info("Got a request for the status. Making status.")
 	s, err := status.GetStatus()
@@ -240,6 +286,49 @@ func getCSRFToken(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(gui.CsrfToken))
 }
 
+// checkInstances returns new instances of the check with the given name, built from the
+// current configuration
+var checkInstances = func(name string) []check.Check {
+	return collector.GetChecksByNameForConfigs(name, common.AC.GetAllConfigs())
+}
+
+// runCheckInstance runs once a new instance of the check with the given ID, built from the
+// current configuration, and returns its stats. Its samples are sent to the aggregator like
+// those of the scheduled runs.
+func runCheckInstance(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	id := check.ID(mux.Vars(r)["id"])
+	// the check IDs are "check_name:config_hash"
+	name := strings.SplitN(string(id), ":", 2)[0]
+
+	for _, ch := range checkInstances(name) {
+		if ch.ID() != id {
+			continue
+		}
+
+		log.Infof("Running the check instance %s once, as requested through the API", id)
+		s := check.NewStats(ch)
+		t0 := time.Now()
+		err := ch.Run()
+		warnings := ch.GetWarnings()
+		mStats, _ := ch.GetMetricStats()
+		s.Add(time.Since(t0), err, warnings, mStats)
+
+		body, err := json.Marshal(s)
+		if err != nil {
+			log.Errorf("Unable to marshal the stats of the check instance %s: %s", id, err)
+			body, _ := json.Marshal(map[string]string{"error": err.Error()})
+			http.Error(w, string(body), 500)
+			return
+		}
+		w.Write(body)
+		return
+	}
+
+	body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("check instance %s not found", id)})
+	http.Error(w, string(body), 404)
+}
+
 func getConfigCheck(w http.ResponseWriter, r *http.Request) {
 	var response response.ConfigCheckResponse
 
@@ -391,6 +480,54 @@ func secretInfo(w http.ResponseWriter, r *http.Request) {
 	w.Write(jsonInfo)
 }
 
+// streamLogs streams the logs processed by the logs-agent matching the filters of the request.
+// The stream ends before the write timeout of the server, the client is expected to reconnect.
+func streamLogs(w http.ResponseWriter, r *http.Request) {
+	log.Info("Got a request to stream logs.")
+	w.Header().Set("Content-Type", "text/plain")
+
+	receiver := logs.GetMessageReceiver()
+	if receiver == nil {
+		body, _ := json.Marshal(map[string]string{"error": "The logs agent is not running"})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		body, _ := json.Marshal(map[string]string{"error": "Streaming is not supported by the connection"})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	var filters diagnostic.Filters
+	if err := json.NewDecoder(r.Body).Decode(&filters); err != nil {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("Invalid filters: %v", err)})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	lines, unsubscribe := receiver.Subscribe(&filters)
+	defer unsubscribe()
+
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	timeout := time.NewTimer(time.Duration(max(config.Datadog.GetInt("server_timeout")-1, 1)) * time.Second)
+	defer timeout.Stop()
+	for {
+		select {
+		case line := <-lines:
+			fmt.Fprintln(w, line)
+			flusher.Flush()
+		case <-timeout.C:
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
 // max returns the maximum value between a and b.
 func max(a, b int) int {
 	if a > b {
@@ -398,3 +535,85 @@ func max(a, b int) int {
 	}
 	return b
 }
+
+// getNetworkPath runs a traceroute from the system-probe to the destination of the request.
+func getNetworkPath(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	query := r.URL.Query()
+	dest := traceroute.Destination{
+		Host:     query.Get("host"),
+		Protocol: query.Get("protocol"),
+	}
+	if port := query.Get("port"); port != "" {
+		p, err := strconv.Atoi(port)
+		if err != nil {
+			body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid port %s", port)})
+			http.Error(w, string(body), 400)
+			return
+		}
+		dest.Port = p
+	}
+
+	path, err := networkpath.Trace(dest)
+	if err != nil {
+		log.Errorf("Unable to run the traceroute to %s: %s", dest.Host, err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+
+	jsonPath, err := json.Marshal(path)
+	if err != nil {
+		log.Errorf("Unable to marshal the network path: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonPath)
+}
+
+// traceMetric starts (POST), reports (GET) and stops (DELETE) the tracing of a metric through
+// the pipeline, for `agent diagnose metric`
+func traceMetric(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		body, _ := json.Marshal(map[string]string{"error": "missing metric name"})
+		http.Error(w, string(body), 400)
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		var ttl time.Duration
+		if t := r.URL.Query().Get("ttl"); t != "" {
+			var err error
+			if ttl, err = time.ParseDuration(t); err != nil {
+				body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("invalid ttl %s", t)})
+				http.Error(w, string(body), 400)
+				return
+			}
+		}
+		log.Infof("Tracing the metric %s through the pipeline", name)
+		metrictracer.Start(name, ttl)
+	case "DELETE":
+		metrictracer.Stop(name)
+		w.Write([]byte("{}"))
+		return
+	}
+
+	report, ok := metrictracer.Get(name)
+	if !ok {
+		body, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("the metric %s is not traced", name)})
+		http.Error(w, string(body), 404)
+		return
+	}
+	jsonReport, err := json.Marshal(report)
+	if err != nil {
+		log.Errorf("Unable to marshal the metric trace: %s", err)
+		body, _ := json.Marshal(map[string]string{"error": err.Error()})
+		http.Error(w, string(body), 500)
+		return
+	}
+	w.Write(jsonReport)
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+type testCheck struct {
+	id   check.ID
+	err  error
+	runs int
+}
+
+func (c *testCheck) Run() error                                           { c.runs++; return c.err }
+func (c *testCheck) Stop()                                                {}
+func (c *testCheck) String() string                                       { return "test" }
+func (c *testCheck) Configure(a, b integration.Data, source string) error { return nil }
+func (c *testCheck) Interval() time.Duration                              { return 15 * time.Second }
+func (c *testCheck) ID() check.ID                                         { return c.id }
+func (c *testCheck) GetWarnings() []error                                 { return []error{errors.New("a warning")} }
+func (c *testCheck) GetMetricStats() (map[string]int64, error) {
+	return map[string]int64{"MetricSamples": 3}, nil
+}
+func (c *testCheck) Version() string          { return "1.0.0" }
+func (c *testCheck) ConfigSource() string     { return "file:test.yaml" }
+func (c *testCheck) IsTelemetryEnabled() bool { return false }
+
+func TestRunCheckInstance(t *testing.T) {
+	instances := []*testCheck{{id: "test:1"}, {id: "test:2", err: errors.New("the check failed")}}
+	var names []string
+	defer func(f func(string) []check.Check) { checkInstances = f }(checkInstances)
+	checkInstances = func(name string) []check.Check {
+		names = append(names, name)
+		return []check.Check{instances[0], instances[1]}
+	}
+	router := mux.NewRouter()
+	SetupHandlers(router)
+
+	for _, tc := range []struct {
+		id        string
+		code      int
+		runs      []int
+		lastError string
+	}{
+		{"test:1", http.StatusOK, []int{1, 0}, ""},
+		{"test:2", http.StatusOK, []int{1, 1}, "the check failed"},
+		{"test:3", http.StatusNotFound, []int{1, 1}, ""},
+	} {
+		t.Run(tc.id, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest("POST", "/check/"+tc.id+"/run", nil))
+			require.Equal(t, tc.code, rec.Code)
+			assert.Equal(t, tc.runs, []int{instances[0].runs, instances[1].runs})
+			if tc.code != http.StatusOK {
+				assert.Contains(t, rec.Body.String(), "check instance "+tc.id+" not found")
+				return
+			}
+
+			stats := check.Stats{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &stats))
+			assert.Equal(t, check.ID(tc.id), stats.CheckID)
+			assert.Equal(t, uint64(1), stats.TotalRuns)
+			assert.Equal(t, int64(3), stats.MetricSamples)
+			assert.Equal(t, []string{"a warning"}, stats.LastWarnings)
+			assert.Contains(t, stats.LastError, tc.lastError)
+		})
+	}
+	assert.Equal(t, []string{"test", "test", "test"}, names)
+}
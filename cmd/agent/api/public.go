@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	gorilla "github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+const publicServerTimeout = time.Second
+
+var publicListener net.Listener
+
+// publicStatus is the minimal status served without authentication, it must not expose
+// the configuration, the tags or anything else than the state of the agent process
+type publicStatus struct {
+	Version    string   `json:"version"`
+	Flavor     string   `json:"flavor"`
+	PID        int      `json:"pid"`
+	AgentStart string   `json:"agent_start"`
+	Healthy    bool     `json:"healthy"`
+	Unhealthy  []string `json:"unhealthy,omitempty"`
+}
+
+// StartPublicServer starts the unauthenticated HTTP server serving the health and a minimal
+// status of the agent on the IPC address, which is always a loopback address, so that the
+// local tools can probe the agent without reading the auth token. It's disabled when
+// public_status_port is 0.
+func StartPublicServer() error {
+	port := config.Datadog.GetInt("public_status_port")
+	if port == 0 {
+		return nil
+	}
+	address, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	publicListener, err = net.Listen("tcp", net.JoinHostPort(address, fmt.Sprintf("%d", port)))
+	if err != nil {
+		return fmt.Errorf("Unable to create the public status server: %v", err)
+	}
+	log.Infof("Public status server listening on %s", publicListener.Addr())
+
+	srv := &http.Server{
+		Handler:           newPublicRouter(),
+		ReadTimeout:       publicServerTimeout,
+		ReadHeaderTimeout: publicServerTimeout,
+		WriteTimeout:      publicServerTimeout,
+	}
+	go srv.Serve(publicListener) //nolint:errcheck
+	return nil
+}
+
+// newPublicRouter returns the router of the public status server
+func newPublicRouter() *gorilla.Router {
+	r := gorilla.NewRouter()
+	r.HandleFunc("/live", publicLiveHandler).Methods("GET")
+	r.HandleFunc("/ready", publicReadyHandler).Methods("GET")
+	r.HandleFunc("/status", publicStatusHandler).Methods("GET")
+	return r
+}
+
+// StopPublicServer closes the listener of the public status server
+func StopPublicServer() {
+	if publicListener != nil {
+		publicListener.Close()
+	}
+}
+
+func publicLiveHandler(w http.ResponseWriter, r *http.Request) {
+	publicHealthHandler(health.GetLiveNonBlocking, w)
+}
+
+func publicReadyHandler(w http.ResponseWriter, r *http.Request) {
+	publicHealthHandler(health.GetReadyNonBlocking, w)
+}
+
+func publicHealthHandler(getStatusNonBlocking func() (health.Status, error), w http.ResponseWriter) {
+	h, err := getStatusNonBlocking()
+	if err != nil {
+		writePublicJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	code := http.StatusOK
+	if len(h.Unhealthy) > 0 {
+		code = http.StatusInternalServerError
+	}
+	writePublicJSON(w, code, h)
+}
+
+func publicStatusHandler(w http.ResponseWriter, r *http.Request) {
+	s := publicStatus{
+		Version:    version.AgentVersion,
+		Flavor:     flavor.GetFlavor(),
+		PID:        os.Getpid(),
+		AgentStart: status.GetStartTime().Format(time.RFC3339),
+	}
+	h, err := health.GetReadyNonBlocking()
+	if err == nil {
+		s.Unhealthy = h.Unhealthy
+	} else {
+		s.Unhealthy = []string{err.Error()}
+	}
+	s.Healthy = len(s.Unhealthy) == 0
+	writePublicJSON(w, http.StatusOK, s)
+}
+
+func writePublicJSON(w http.ResponseWriter, code int, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Errorf("Error marshalling the public status: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(body)
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/status/health"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+func getPublic(t *testing.T, server *httptest.Server, path string) (int, string) {
+	resp, err := http.Get(server.URL + path)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var body json.RawMessage
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	return resp.StatusCode, string(body)
+}
+
+func TestPublicServerHealthy(t *testing.T) {
+	server := httptest.NewServer(newPublicRouter())
+	defer server.Close()
+
+	code, _ := getPublic(t, server, "/live")
+	assert.Equal(t, http.StatusOK, code)
+	code, _ = getPublic(t, server, "/ready")
+	assert.Equal(t, http.StatusOK, code)
+
+	code, body := getPublic(t, server, "/status")
+	assert.Equal(t, http.StatusOK, code)
+	var s publicStatus
+	require.NoError(t, json.Unmarshal([]byte(body), &s))
+	assert.Equal(t, version.AgentVersion, s.Version)
+	assert.Equal(t, os.Getpid(), s.PID)
+	assert.True(t, s.Healthy)
+	assert.Empty(t, s.Unhealthy)
+}
+
+func TestPublicServerUnhealthy(t *testing.T) {
+	server := httptest.NewServer(newPublicRouter())
+	defer server.Close()
+
+	// the components are unhealthy until they read their first health ping
+	readiness := health.RegisterReadiness("test-readiness")
+	defer health.Deregister(readiness) //nolint:errcheck
+
+	code, _ := getPublic(t, server, "/live")
+	assert.Equal(t, http.StatusOK, code)
+	code, body := getPublic(t, server, "/ready")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Contains(t, body, "test-readiness")
+
+	liveness := health.RegisterLiveness("test-liveness")
+	defer health.Deregister(liveness) //nolint:errcheck
+
+	code, body = getPublic(t, server, "/live")
+	assert.Equal(t, http.StatusInternalServerError, code)
+	assert.Contains(t, body, "test-liveness")
+	assert.NotContains(t, body, "test-readiness")
+
+	// the status is served with the unhealthy components
+	code, body = getPublic(t, server, "/status")
+	assert.Equal(t, http.StatusOK, code)
+	var s publicStatus
+	require.NoError(t, json.Unmarshal([]byte(body), &s))
+	assert.False(t, s.Healthy)
+	assert.ElementsMatch(t, []string{"test-liveness", "test-readiness"}, s.Unhealthy)
+}
+
+func TestPublicServerNoInternalData(t *testing.T) {
+	for key, value := range map[string]interface{}{
+		"api_key":  "0123456789abcdef0123456789abcdef",
+		"hostname": "secret-hostname",
+		"tags":     []string{"secret-tag:value"},
+		"site":     "secret-site.example.com",
+	} {
+		defer config.Datadog.Set(key, config.Datadog.Get(key))
+		config.Datadog.Set(key, value)
+	}
+	server := httptest.NewServer(newPublicRouter())
+	defer server.Close()
+
+	for _, path := range []string{"/live", "/ready", "/status"} {
+		_, body := getPublic(t, server, path)
+		for _, secret := range []string{"0123456789abcdef", "secret-hostname", "secret-tag", "secret-site"} {
+			assert.NotContains(t, body, secret, "%s exposes %s", path, secret)
+		}
+	}
+
+	_, body := getPublic(t, server, "/status")
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(body), &fields))
+	for field := range fields {
+		assert.Contains(t, []string{"version", "flavor", "pid", "agent_start", "healthy"}, field)
+	}
+}
+
+func TestPublicServerRoutes(t *testing.T) {
+	server := httptest.NewServer(newPublicRouter())
+	defer server.Close()
+
+	// only the health and the minimal status are served, and only read
+	for _, path := range []string{"/", "/agent/status", "/config", "/status/health"} {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+	resp, err := http.Post(server.URL+"/status", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
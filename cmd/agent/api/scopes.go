@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"net/http"
+
+	gorilla "github.com/gorilla/mux"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// endpointScopes maps the endpoints of the IPC API, as "<method> <path template>", to the scope
+// required to call them. The endpoints missing from the map require the admin scope.
+var endpointScopes = map[string]security.Scope{
+	"GET /agent/version":                  security.ScopeStatusRead,
+	"GET /agent/hostname":                 security.ScopeHostnameRead,
+	"POST /agent/flare":                   security.ScopeFlare,
+	"POST /agent/stop":                    security.ScopeControl,
+	"GET /agent/status":                   security.ScopeStatusRead,
+	"GET /agent/dogstatsd-stats":          security.ScopeStatusRead,
+	"POST /agent/stream-logs":             security.ScopeControl,
+	"GET /agent/network-path":             security.ScopeControl,
+	"GET /agent/status/formatted":         security.ScopeStatusRead,
+	"GET /agent/status/health":            security.ScopeStatusRead,
+	"GET /agent/status/section/{section}": security.ScopeStatusRead,
+	"GET /agent/{component}/status":       security.ScopeStatusRead,
+	"POST /agent/{component}/status":      security.ScopeStatusRead,
+	"GET /agent/{component}/configs":      security.ScopeConfigRead,
+	"GET /agent/gui/csrf-token":           security.ScopeAdmin,
+	"GET /agent/config-check":             security.ScopeConfigRead,
+	"GET /agent/config":                   security.ScopeConfigRead,
+	"GET /agent/config/list-runtime":      security.ScopeRuntimeSettings,
+	"GET /agent/config/{setting}":         security.ScopeRuntimeSettings,
+	"POST /agent/config/{setting}":        security.ScopeRuntimeSettings,
+	"POST /agent/check/{id}/run":          security.ScopeChecksRun,
+	"GET /agent/tagger-list":              security.ScopeTaggerRead,
+	"GET /agent/secrets":                  security.ScopeConfigRead,
+	"GET /check/":                         security.ScopeStatusRead,
+	"GET /check/{name}":                   security.ScopeStatusRead,
+	"DELETE /check/{name}":                security.ScopeChecksRun,
+	"POST /check/{name}/reload":           security.ScopeChecksRun,
+}
+
+// endpointScope returns the scope required to call the endpoint matched by the request
+func endpointScope(prefix string, r *http.Request) security.Scope {
+	route := gorilla.CurrentRoute(r)
+	if route == nil {
+		return security.ScopeAdmin
+	}
+	path, err := route.GetPathTemplate()
+	if err != nil {
+		return security.ScopeAdmin
+	}
+	if scope, found := endpointScopes[r.Method+" "+prefix+path]; found {
+		return scope
+	}
+	return security.ScopeAdmin
+}
+
+// validateScope returns a middleware validating that the requests to the endpoints of a router
+// mounted at prefix are granted the scope of the endpoint. The denied calls are audit logged.
+func validateScope(prefix string) gorilla.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scope := endpointScope(prefix, r)
+			if err := util.ValidateScope(w, r, scope); err != nil {
+				log.Warnf("IPC API audit: denied %s %s%s from %s requiring the %s scope: %v", r.Method, prefix, r.URL.Path, r.RemoteAddr, scope, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
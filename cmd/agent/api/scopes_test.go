@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	gorilla "github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/api/agent"
+	"github.com/DataDog/datadog-agent/cmd/agent/api/check"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// newTestScopedServer mounts routers like the IPC API server, with the same path templates as
+// some of its endpoints, behind the scope validation
+func newTestScopedServer() http.Handler {
+	ok := func(w http.ResponseWriter, r *http.Request) {}
+
+	agentMux := gorilla.NewRouter()
+	agentMux.Use(validateScope("/agent"))
+	agentMux.HandleFunc("/hostname", ok).Methods("GET")
+	agentMux.HandleFunc("/status", ok).Methods("GET")
+	agentMux.HandleFunc("/flare", ok).Methods("POST")
+	agentMux.HandleFunc("/config", ok).Methods("GET")
+	agentMux.HandleFunc("/config/{setting}", ok).Methods("GET", "POST")
+	agentMux.HandleFunc("/gui/csrf-token", ok).Methods("GET")
+	// an endpoint missing from endpointScopes
+	agentMux.HandleFunc("/unscoped", ok).Methods("GET")
+
+	checkMux := gorilla.NewRouter()
+	checkMux.Use(validateScope("/check"))
+	checkMux.HandleFunc("/{name}", ok).Methods("GET", "DELETE")
+
+	mux := http.NewServeMux()
+	mux.Handle("/agent/", http.StripPrefix("/agent", agentMux))
+	mux.Handle("/check/", http.StripPrefix("/check", checkMux))
+	return mux
+}
+
+func TestValidateScope(t *testing.T) {
+	dir, err := ioutil.TempDir("", "scopes")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	authTokenFilePath := config.Datadog.GetString("auth_token_file_path")
+	defer config.Datadog.Set("auth_token_file_path", authTokenFilePath)
+	config.Datadog.Set("auth_token_file_path", filepath.Join(dir, "auth_token"))
+
+	require.NoError(t, util.CreateAndSetAuthToken())
+	require.NoError(t, util.CreateAndSetScopedAuthTokens())
+	guiToken, err := security.FetchScopedToken(security.GUITokenName)
+	require.NoError(t, err)
+	processAgentToken, err := security.FetchScopedToken(security.ProcessAgentTokenName)
+	require.NoError(t, err)
+	adminToken := util.GetAuthToken()
+
+	server := newTestScopedServer()
+	for _, tc := range []struct {
+		name   string
+		token  string
+		method string
+		path   string
+		code   int
+	}{
+		{"gui status", guiToken, "GET", "/agent/status", http.StatusOK},
+		{"gui runtime setting", guiToken, "POST", "/agent/config/log_level", http.StatusOK},
+		{"gui check", guiToken, "DELETE", "/check/cpu", http.StatusOK},
+		{"gui full config", guiToken, "GET", "/agent/config", http.StatusForbidden},
+		{"gui flare", guiToken, "POST", "/agent/flare", http.StatusForbidden},
+		{"gui csrf token", guiToken, "GET", "/agent/gui/csrf-token", http.StatusForbidden},
+		{"gui unscoped", guiToken, "GET", "/agent/unscoped", http.StatusForbidden},
+		{"process-agent hostname", processAgentToken, "GET", "/agent/hostname", http.StatusOK},
+		{"process-agent status", processAgentToken, "GET", "/agent/status", http.StatusForbidden},
+		{"process-agent check", processAgentToken, "GET", "/check/cpu", http.StatusForbidden},
+		{"admin full config", adminToken, "GET", "/agent/config", http.StatusOK},
+		{"admin flare", adminToken, "POST", "/agent/flare", http.StatusOK},
+		{"admin unscoped", adminToken, "GET", "/agent/unscoped", http.StatusOK},
+		{"admin check", adminToken, "DELETE", "/check/cpu", http.StatusOK},
+		{"invalid token", strings.Repeat("a", 64), "GET", "/agent/status", http.StatusForbidden},
+		{"no token", "", "GET", "/agent/status", http.StatusUnauthorized},
+		{"unknown route", adminToken, "GET", "/agent/unknown", http.StatusNotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tc.token)
+			}
+			rec := httptest.NewRecorder()
+			server.ServeHTTP(rec, req)
+			assert.Equal(t, tc.code, rec.Code)
+		})
+	}
+}
+
+// TestEndpointScopes checks that every endpoint of endpointScopes is an endpoint of the IPC API,
+// the scope of a mistyped endpoint would silently be the admin one
+func TestEndpointScopes(t *testing.T) {
+	endpoints := make(map[string]struct{})
+	walk := func(prefix string) gorilla.WalkFunc {
+		return func(route *gorilla.Route, router *gorilla.Router, ancestors []*gorilla.Route) error {
+			path, err := route.GetPathTemplate()
+			if err != nil {
+				return err
+			}
+			methods, err := route.GetMethods()
+			if err != nil {
+				return err
+			}
+			for _, method := range methods {
+				endpoints[method+" "+prefix+path] = struct{}{}
+			}
+			return nil
+		}
+	}
+	require.NoError(t, agent.SetupHandlers(gorilla.NewRouter()).Walk(walk("/agent")))
+	require.NoError(t, check.SetupHandlers(gorilla.NewRouter()).Walk(walk("/check")))
+
+	for endpoint := range endpointScopes {
+		assert.Contains(t, endpoints, endpoint)
+	}
+}
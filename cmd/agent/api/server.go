@@ -82,6 +82,11 @@ func StartServer() error {
 		return err
 	}
 
+	err = util.CreateAndSetScopedAuthTokens()
+	if err != nil {
+		return err
+	}
+
 	// gRPC server
 	mux := http.NewServeMux()
 	opts := []grpc.ServerOption{
@@ -90,10 +95,7 @@ func StartServer() error {
 	s := grpc.NewServer(opts...)
 	pb.RegisterAgentServer(s, &server{})
 
-	dcreds := credentials.NewTLS(&tls.Config{
-		ServerName: tlsAddr,
-		RootCAs:    tlsCertPool,
-	})
+	dcreds := credentials.NewTLS(gatewayTLSConfig())
 	dopts := []grpc.DialOption{grpc.WithTransportCredentials(dcreds)}
 
 	// starting grpc gateway
@@ -109,9 +111,9 @@ func StartServer() error {
 	// create the REST HTTP router
 	agentMux := gorilla.NewRouter()
 	checkMux := gorilla.NewRouter()
-	// Validate token for every request
-	agentMux.Use(validateToken)
-	checkMux.Use(validateToken)
+	// Validate the token and its scope for every request
+	agentMux.Use(validateScope("/agent"))
+	checkMux.Use(validateScope("/check"))
 
 	mux.Handle("/agent/", http.StripPrefix("/agent", agent.SetupHandlers(agentMux)))
 	mux.Handle("/check/", http.StripPrefix("/check", check.SetupHandlers(checkMux)))
@@ -121,10 +123,7 @@ func StartServer() error {
 		Addr:    tlsAddr,
 		Handler: grpcHandlerFunc(s, mux),
 		// Handler: grpcHandlerFunc(s, r),
-		TLSConfig: &tls.Config{
-			Certificates: []tls.Certificate{*tlsKeyPair},
-			NextProtos:   []string{"h2"},
-		},
+		TLSConfig: serverTLSConfig(),
 		ErrorLog: stdLog.New(&config.ErrorLogWriter{
 			AdditionalDepth: 4, // Use a stack depth of 4 on top of the default one to get a relevant filename in the stdlib
 		}, "Error from the agent http API server: ", 0), // log errors to seelog,
@@ -143,18 +142,10 @@ func StopServer() {
 	if listener != nil {
 		listener.Close()
 	}
+	stopIPCCertificatesRotation()
 }
 
 // ServerAddress retruns the server address.
 func ServerAddress() *net.TCPAddr {
 	return listener.Addr().(*net.TCPAddr)
 }
-
-func validateToken(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if err := util.Validate(w, r); err != nil {
-			return
-		}
-		next.ServeHTTP(w, r)
-	})
-}
@@ -4,24 +4,43 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
+// ipcCertsRotationInterval is how often the IPC certificates close to their expiration are rotated
+const ipcCertsRotationInterval = 24 * time.Hour
+
 var (
 	tlsKeyPair  *tls.Certificate
 	tlsCertPool *x509.CertPool
 	tlsAddr     string
-)
 
-func buildSelfSignedKeyPair() ([]byte, []byte) {
+	// ipcCerts are the mTLS certificates of the IPC API, nil when mTLS is disabled
+	ipcCerts         *security.IPCCertificates
+	ipcCertsMutex    sync.RWMutex
+	ipcCertsRotation chan struct{}
+)
 
+// ipcHosts returns the hosts the IPC API is reachable at
+func ipcHosts() []string {
 	hosts := []string{"127.0.0.1", "localhost", "::1"}
-	ipcAddr, err := getIPCAddressPort()
-	if err == nil {
+	if ipcAddress, err := config.GetIPCAddress(); err == nil {
+		hosts = append(hosts, ipcAddress)
+	}
+	if ipcAddr, err := getIPCAddressPort(); err == nil {
 		hosts = append(hosts, ipcAddr)
 	}
-	_, rootCertPEM, rootKey, err := security.GenerateRootCert(hosts, 2048)
+	return hosts
+}
+
+func buildSelfSignedKeyPair() ([]byte, []byte) {
+	_, rootCertPEM, rootKey, err := security.GenerateRootCert(ipcHosts(), 2048)
 	if err != nil {
 		return nil, nil
 	}
@@ -36,6 +55,24 @@ func buildSelfSignedKeyPair() ([]byte, []byte) {
 }
 
 func initializeTLS() {
+	var err error
+	tlsAddr, err = getIPCAddressPort()
+	if err != nil {
+		panic("unable to get IPC address and port")
+	}
+
+	if config.Datadog.GetBool("ipc_mtls.enabled") {
+		certs, err := security.CreateOrRotateIPCCertificates(ipcHosts())
+		if err == nil {
+			setIPCCertificates(certs)
+			tlsKeyPair = certs.Server
+			tlsCertPool = certs.CAPool
+			ipcCertsRotation = make(chan struct{})
+			go rotateIPCCertificates(ipcCertsRotation)
+			return
+		}
+		log.Errorf("Unable to set up mTLS on the IPC API, only the auth token will be accepted: %v", err)
+	}
 
 	cert, key := buildSelfSignedKeyPair()
 	if cert == nil {
@@ -51,9 +88,121 @@ func initializeTLS() {
 	if !ok {
 		panic("bad certs")
 	}
+}
 
-	tlsAddr, err = getIPCAddressPort()
-	if err != nil {
-		panic("unable to get IPC address and port")
+func getIPCCertificates() *security.IPCCertificates {
+	ipcCertsMutex.RLock()
+	defer ipcCertsMutex.RUnlock()
+	return ipcCerts
+}
+
+func setIPCCertificates(certs *security.IPCCertificates) {
+	ipcCertsMutex.Lock()
+	defer ipcCertsMutex.Unlock()
+	ipcCerts = certs
+}
+
+// rotateIPCCertificates periodically renews the IPC certificates close to their expiration, so that
+// an agent running for a long time keeps serving valid ones
+func rotateIPCCertificates(stop chan struct{}) {
+	ticker := time.NewTicker(ipcCertsRotationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			certs, err := security.CreateOrRotateIPCCertificates(ipcHosts())
+			if err != nil {
+				log.Errorf("Unable to rotate the IPC certificates: %v", err)
+				continue
+			}
+			setIPCCertificates(certs)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// stopIPCCertificatesRotation stops the rotation of the IPC certificates, if any
+func stopIPCCertificatesRotation() {
+	if ipcCertsRotation != nil {
+		close(ipcCertsRotation)
+		ipcCertsRotation = nil
 	}
 }
+
+// serverTLSConfig returns the TLS config of the IPC API. With mTLS, the client certificates are
+// verified against the IPC CA, and are required unless the auth token is still accepted for the
+// clients predating mTLS. The config is built on each handshake to use the rotated certificates.
+func serverTLSConfig() *tls.Config {
+	if getIPCCertificates() == nil {
+		return &tls.Config{
+			Certificates: []tls.Certificate{*tlsKeyPair},
+			NextProtos:   []string{"h2"},
+		}
+	}
+
+	clientAuth := tls.VerifyClientCertIfGiven
+	if !config.Datadog.GetBool("ipc_mtls.allow_token_auth") {
+		clientAuth = tls.RequireAndVerifyClientCert
+	}
+	return &tls.Config{
+		NextProtos: []string{"h2"},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			certs := getIPCCertificates()
+			return &tls.Config{
+				Certificates: []tls.Certificate{*certs.Server},
+				ClientCAs:    certs.CAPool,
+				ClientAuth:   clientAuth,
+				NextProtos:   []string{"h2"},
+			}, nil
+		},
+	}
+}
+
+// gatewayTLSConfig returns the TLS config of the grpc gateway, which is a client of the IPC API.
+// With mTLS, the server certificate is verified against the IPC CA on each handshake, so that the
+// gateway trusts the CA the rotated certificates are issued by.
+func gatewayTLSConfig() *tls.Config {
+	if getIPCCertificates() == nil {
+		return &tls.Config{
+			ServerName: tlsAddr,
+			RootCAs:    tlsCertPool,
+		}
+	}
+	return &tls.Config{
+		// the server certificate is verified by VerifyPeerCertificate instead
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyServerCertificate(rawCerts, getIPCCertificates().CAPool, tlsAddr)
+		},
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &getIPCCertificates().Client, nil
+		},
+	}
+}
+
+// verifyServerCertificate verifies the certificate chain presented by a server against the roots
+func verifyServerCertificate(rawCerts [][]byte, roots *x509.CertPool, serverName string) error {
+	if len(rawCerts) == 0 {
+		return errors.New("the server presented no certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// newTestIPCCertificates creates IPC certificates issued by a new CA
+func newTestIPCCertificates(t *testing.T) *security.IPCCertificates {
+	dir, err := ioutil.TempDir("", "ipc-certs")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	authTokenFilePath := config.Datadog.GetString("auth_token_file_path")
+	defer config.Datadog.Set("auth_token_file_path", authTokenFilePath)
+	config.Datadog.Set("auth_token_file_path", filepath.Join(dir, "auth_token"))
+
+	certs, err := security.CreateOrRotateIPCCertificates([]string{"127.0.0.1:5001"})
+	require.NoError(t, err)
+	return certs
+}
+
+func TestGatewayTLSConfigRotatedCA(t *testing.T) {
+	defer func(addr string) { tlsAddr = addr }(tlsAddr)
+	tlsAddr = "127.0.0.1:5001"
+	defer setIPCCertificates(getIPCCertificates())
+
+	certs := newTestIPCCertificates(t)
+	setIPCCertificates(certs)
+	tlsConfig := gatewayTLSConfig()
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate(certs.Server.Certificate, nil))
+
+	// the gateway trusts the new CA once the certificates are rotated, and only it
+	rotated := newTestIPCCertificates(t)
+	setIPCCertificates(rotated)
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate(rotated.Server.Certificate, nil))
+	assert.Error(t, tlsConfig.VerifyPeerCertificate(certs.Server.Certificate, nil))
+
+	client, err := tlsConfig.GetClientCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, rotated.Client.Certificate, client.Certificate)
+
+	tlsAddr = "127.0.0.2:5001"
+	assert.Error(t, tlsConfig.VerifyPeerCertificate(rotated.Server.Certificate, nil))
+	assert.Error(t, tlsConfig.VerifyPeerCertificate(nil, nil))
+}
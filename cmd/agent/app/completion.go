@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	AgentCmd.AddCommand(completionCmd)
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate the completion script of the agent for a shell",
+	Long: `Print the completion script of the agent commands for the given shell, e.g. to load it in bash:
+
+  source <(agent completion bash)`,
+	Args:                  cobra.ExactValidArgs(1),
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		switch args[0] {
+		case "bash":
+			return AgentCmd.GenBashCompletion(os.Stdout)
+		case "zsh":
+			return AgentCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			return AgentCmd.GenFishCompletion(os.Stdout, true)
+		}
+		return AgentCmd.GenPowerShellCompletion(os.Stdout)
+	},
+}
@@ -91,7 +91,7 @@ func showRuntimeConfiguration(cmd *cobra.Command, args []string) error {
 }
 
 func requestConfig() (string, error) {
-	c := util.GetClient(false)
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return "", err
@@ -119,7 +119,7 @@ func listRuntimeConfigurableValue(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	c := util.GetClient(false)
+	c := util.GetIPCClient()
 	settings, err := getRuntimeSettingsList(c)
 	if err != nil {
 		return err
@@ -143,7 +143,7 @@ func setConfigValue(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	c := util.GetClient(false)
+	c := util.GetIPCClient()
 	settings, err := getRuntimeSettingsList(c)
 	if err != nil {
 		return err
@@ -181,7 +181,7 @@ func getConfigValue(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return err
 	}
-	c := util.GetClient(false)
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
@@ -6,6 +6,7 @@
 package app
 
 import (
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -18,6 +19,7 @@ func (s *Servicedef) IsEnabled() bool {
 }
 
 func startDependentServices() {
+	var started []Servicedef
 	for _, svc := range subservices {
 		if svc.IsEnabled() {
 			log.Debugf("Attempting to start service: %s", svc.name)
@@ -26,9 +28,15 @@ func startDependentServices() {
 				log.Warnf("Failed to start services %s: %s", svc.name, err.Error())
 			} else {
 				log.Debugf("Started service %s", svc.name)
+				started = append(started, svc)
 			}
 		} else {
 			log.Infof("Service %s is disabled, not starting", svc.name)
 		}
 	}
+
+	// watch the started services and restart them when they crash
+	if len(started) > 0 {
+		go superviseDependentServices(common.MainCtx, started)
+	}
 }
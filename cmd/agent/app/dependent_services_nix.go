@@ -6,6 +6,8 @@
 
 package app
 
+import "context"
+
 // Servicedef defines a service
 type Servicedef struct {
 	name      string
@@ -23,3 +25,6 @@ func (s *Servicedef) Start() error {
 func (s *Servicedef) Stop() error {
 	return nil
 }
+
+// superviseDependentServices is a no-op, there are no dependent services outside of Windows
+func superviseDependentServices(ctx context.Context, services []Servicedef) {}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+// +build windows
+
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// supervisorInterval is the interval between two checks of the dependent services
+	supervisorInterval = 10 * time.Second
+	// restartBackoffInitial is the delay before restarting a service after its first crash
+	restartBackoffInitial = 5 * time.Second
+	// restartBackoffMax is the maximum delay before restarting a crashed service
+	restartBackoffMax = 5 * time.Minute
+	// restartBackoffReset is how long a service has to run before its crashes are forgotten
+	restartBackoffReset = 10 * time.Minute
+	// crashReportsFile is the name of the file holding the crash reports, next to the agent log
+	// file so that it's part of the flares
+	crashReportsFile = "service_crashes.log"
+)
+
+// crashReport is the structured report of a crash of a dependent service
+type crashReport struct {
+	Time                    time.Time `json:"time"`
+	Service                 string    `json:"service"`
+	Win32ExitCode           uint32    `json:"win32_exit_code"`
+	ServiceSpecificExitCode uint32    `json:"service_specific_exit_code"`
+	Crashes                 int       `json:"crashes"`
+	RestartDelay            string    `json:"restart_delay"`
+}
+
+// supervisedService is the state of a dependent service watched by the supervisor
+type supervisedService struct {
+	Servicedef
+	crashes      int
+	restartAt    time.Time
+	runningSince time.Time
+}
+
+// restartBackoff returns the delay before restarting a service after the given number of crashes
+func restartBackoff(crashes int) time.Duration {
+	backoff := restartBackoffInitial
+	for i := 1; i < crashes && backoff < restartBackoffMax; i++ {
+		backoff *= 2
+	}
+	if backoff > restartBackoffMax {
+		backoff = restartBackoffMax
+	}
+	return backoff
+}
+
+// superviseDependentServices watches the dependent services until the context is done. A service
+// stopped with an error exit code crashed: the crash is reported and the service is restarted, with
+// an exponential backoff between the restarts.
+func superviseDependentServices(ctx context.Context, services []Servicedef) {
+	supervised := make([]*supervisedService, 0, len(services))
+	for _, s := range services {
+		supervised = append(supervised, &supervisedService{Servicedef: s, runningSince: time.Now()})
+	}
+
+	ticker := time.NewTicker(supervisorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, s := range supervised {
+				s.check(now)
+			}
+		}
+	}
+}
+
+// check restarts the service if it crashed and its restart delay is over
+func (s *supervisedService) check(now time.Time) {
+	if !s.restartAt.IsZero() {
+		if now.Before(s.restartAt) {
+			return
+		}
+		s.restartAt = time.Time{}
+		log.Infof("Restarting service %s after %d crash(es)", s.name, s.crashes)
+		if err := s.Start(); err != nil {
+			log.Warnf("Failed to restart service %s: %s", s.name, err)
+			s.crashed(now, windows.SERVICE_STATUS{})
+			return
+		}
+		s.runningSince = now
+		return
+	}
+
+	status, err := s.QueryStatus()
+	if err != nil {
+		log.Debugf("Failed to query the status of service %s: %s", s.name, err)
+		return
+	}
+
+	switch status.CurrentState {
+	case windows.SERVICE_RUNNING:
+		if s.crashes > 0 && now.Sub(s.runningSince) > restartBackoffReset {
+			log.Infof("Service %s has been running for %s, resetting its crash count", s.name, restartBackoffReset)
+			s.crashes = 0
+		}
+	case windows.SERVICE_STOPPED:
+		// a service stopped through the service control manager exits without error
+		if status.Win32ExitCode != windows.NO_ERROR || status.ServiceSpecificExitCode != 0 {
+			s.crashed(now, status)
+		}
+	}
+}
+
+// crashed reports a crash of the service and schedules its restart
+func (s *supervisedService) crashed(now time.Time, status windows.SERVICE_STATUS) {
+	s.crashes++
+	delay := restartBackoff(s.crashes)
+	s.restartAt = now.Add(delay)
+
+	report := crashReport{
+		Time:                    now,
+		Service:                 s.serviceName,
+		Win32ExitCode:           status.Win32ExitCode,
+		ServiceSpecificExitCode: status.ServiceSpecificExitCode,
+		Crashes:                 s.crashes,
+		RestartDelay:            delay.String(),
+	}
+	log.Errorf("Service %s crashed (exit code %d, service exit code %d), restarting it in %s",
+		s.serviceName, status.Win32ExitCode, status.ServiceSpecificExitCode, delay)
+	if err := writeCrashReport(report); err != nil {
+		log.Warnf("Failed to write the crash report of service %s: %s", s.serviceName, err)
+	}
+}
+
+// writeCrashReport appends the crash report to the crash reports file, as a JSON line
+func writeCrashReport(report crashReport) error {
+	logFile := config.Datadog.GetString("log_file")
+	if logFile == "" {
+		logFile = common.DefaultLogFile
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath.Join(filepath.Dir(logFile), crashReportsFile), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -70,6 +70,37 @@ func (s *Servicedef) Start() error {
 		}
 	}
 
+	m, scm, err := s.open(windows.SERVICE_START | windows.SERVICE_STOP)
+	if err != nil {
+		return err
+	}
+	defer m.Disconnect()
+	defer scm.Close()
+	err = scm.Start("is", "manual-started")
+	if err != nil {
+		log.Warnf("Failed to start service %v", err)
+		return fmt.Errorf("could not start service: %v", err)
+	}
+
+	return nil
+}
+
+// QueryStatus returns the status of the service as reported by the service control manager
+func (s *Servicedef) QueryStatus() (windows.SERVICE_STATUS, error) {
+	var status windows.SERVICE_STATUS
+	m, scm, err := s.open(windows.SERVICE_QUERY_STATUS)
+	if err != nil {
+		return status, err
+	}
+	defer m.Disconnect()
+	defer scm.Close()
+
+	err = windows.QueryServiceStatus(scm.Handle, &status)
+	return status, err
+}
+
+// open connects to the service control manager and opens the service with the given access rights
+func (s *Servicedef) open(access uint32) (*mgr.Mgr, *mgr.Service, error) {
 	/*
 	 * default go impolementations of mgr.Connect and mgr.OpenService use way too
 	 * open permissions by default.  Use those structures so the other methods
@@ -79,26 +110,17 @@ func (s *Servicedef) Start() error {
 	h, err := windows.OpenSCManager(nil, nil, windows.SC_MANAGER_CONNECT)
 	if err != nil {
 		log.Warnf("Failed to connect to scm %v", err)
-		return err
+		return nil, nil, err
 	}
 	m := &mgr.Mgr{Handle: h}
-	defer m.Disconnect()
 
-	hSvc, err := windows.OpenService(m.Handle, syscall.StringToUTF16Ptr(s.serviceName),
-		windows.SERVICE_START|windows.SERVICE_STOP)
+	hSvc, err := windows.OpenService(m.Handle, syscall.StringToUTF16Ptr(s.serviceName), access)
 	if err != nil {
+		m.Disconnect()
 		log.Warnf("Failed to open service %v", err)
-		return fmt.Errorf("could not access service: %v", err)
+		return nil, nil, fmt.Errorf("could not access service: %v", err)
 	}
-	scm := &mgr.Service{Name: s.serviceName, Handle: hSvc}
-	defer scm.Close()
-	err = scm.Start("is", "manual-started")
-	if err != nil {
-		log.Warnf("Failed to start service %v", err)
-		return fmt.Errorf("could not start service: %v", err)
-	}
-
-	return nil
+	return m, &mgr.Service{Name: s.serviceName, Handle: hSvc}, nil
 }
 
 // Stop stops the service
@@ -7,6 +7,7 @@ package app
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -17,6 +18,10 @@ import (
 )
 
 func init() {
+	diagnoseCommand.AddCommand(metadataAvailabilityCommand)
+	diagnoseCommand.AddCommand(proxyCommand)
+	diagnoseCommand.AddCommand(metricCommand)
+	metricCommand.Flags().DurationVarP(&metricTraceDuration, "duration", "d", 30*time.Second, "How long to trace the metric, it should cover a flush of the aggregator")
 	AgentCmd.AddCommand(diagnoseCommand)
 }
 
@@ -27,7 +32,65 @@ var diagnoseCommand = &cobra.Command{
 	RunE:  doDiagnose,
 }
 
+var metadataAvailabilityCommand = &cobra.Command{
+	Use:   "metadata-availability",
+	Short: "Check the availability of the cloud provider metadata endpoints, the kubelet and the container runtimes",
+	Long: `Probe the metadata endpoints of the cloud providers (EC2, GCE, Azure, Alibaba), the kubelet
+and the docker and containerd sockets, and report which host metadata providers are reachable
+and which credentials are missing. The same results are part of the diagnose.log file of the flare.`,
+	RunE: doDiagnoseMetadataAvailability,
+}
+
+var proxyCommand = &cobra.Command{
+	Use:   "proxy",
+	Short: "Show which proxy each endpoint of the Agent uses",
+	Long: `Resolve the proxy settings, from the configuration and the environment, for the metrics,
+logs and APM endpoints, and show which proxy each of them uses or which no_proxy entry
+excludes it.`,
+	RunE: doDiagnoseProxy,
+}
+
+var metricTraceDuration time.Duration
+
+var metricCommand = &cobra.Command{
+	Use:   "metric <name>",
+	Short: "Trace a metric through the pipeline of the running Agent to find out why it's missing",
+	Long: `Ask the running Agent to count the samples of the metric at each stage of its pipeline,
+from the DogStatsD parsing to the aggregator, the metrics_exclude rules and the serializer, and
+show the stage where the metric disappears.`,
+	Args: cobra.ExactArgs(1),
+	RunE: doDiagnoseMetric,
+}
+
 func doDiagnose(cmd *cobra.Command, args []string) error {
+	if err := setupDiagnose(); err != nil {
+		return err
+	}
+	return diagnose.RunAll(color.Output)
+}
+
+func doDiagnoseMetadataAvailability(cmd *cobra.Command, args []string) error {
+	if err := setupDiagnose(); err != nil {
+		return err
+	}
+	return diagnose.RunMetadataAvailability(color.Output)
+}
+
+func doDiagnoseProxy(cmd *cobra.Command, args []string) error {
+	if err := setupDiagnose(); err != nil {
+		return err
+	}
+	return diagnose.RunProxy(color.Output)
+}
+
+func doDiagnoseMetric(cmd *cobra.Command, args []string) error {
+	if err := setupDiagnose(); err != nil {
+		return err
+	}
+	return diagnose.RunMetric(color.Output, args[0], metricTraceDuration)
+}
+
+func setupDiagnose() error {
 	// Global config setup
 	err := common.SetupConfig(confFilePath)
 	if err != nil {
@@ -50,6 +113,5 @@ func doDiagnose(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		return fmt.Errorf("Error while setting up logging, exiting: %v", err)
 	}
-
-	return diagnose.RunAll(color.Output)
+	return nil
 }
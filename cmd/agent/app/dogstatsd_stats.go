@@ -62,7 +62,7 @@ func requestDogstatsdStats() error {
 	fmt.Printf("Getting the dogstatsd stats from the agent.\n\n")
 	var e error
 	var s string
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
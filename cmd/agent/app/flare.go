@@ -119,7 +119,7 @@ func makeFlare(caseID string) error {
 func requestArchive(logFile string) (string, error) {
 	fmt.Fprintln(color.Output, color.BlueString("Asking the agent to build the flare archive."))
 	var e error
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		fmt.Fprintln(color.Output, color.RedString(fmt.Sprintf("Error getting IPC address for the agent: %s", err)))
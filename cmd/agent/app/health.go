@@ -53,7 +53,7 @@ var healthCmd = &cobra.Command{
 
 func requestHealth() error {
 
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
@@ -14,8 +14,11 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var hostnameVerbose bool
+
 func init() {
 	AgentCmd.AddCommand(getHostnameCommand)
+	getHostnameCommand.Flags().BoolVarP(&hostnameVerbose, "verbose", "v", false, "print the answer of every hostname provider")
 }
 
 var getHostnameCommand = &cobra.Command{
@@ -40,6 +43,10 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if hostnameVerbose {
+		return printHostnameDiagnosis()
+	}
+
 	hname, err := util.GetHostname()
 	if err != nil {
 		return fmt.Errorf("Error getting the hostname: %v", err)
@@ -48,3 +55,32 @@ func doGetHostname(cmd *cobra.Command, args []string) error {
 	fmt.Println(hname)
 	return nil
 }
+
+func printHostnameDiagnosis() error {
+	data, results, err := util.GetHostnameDiagnosis()
+
+	fmt.Println("Hostname providers, in order of precedence:")
+	for _, result := range results {
+		marker := " "
+		if result.Selected {
+			marker = "*"
+		}
+		switch {
+		case result.Disabled:
+			fmt.Printf("%s %s: disabled by `hostname_providers`\n", marker, result.Provider)
+		case result.Error != nil:
+			fmt.Printf("%s %s: error: %v\n", marker, result.Provider, result.Error)
+		case result.Unused != nil:
+			fmt.Printf("%s %s: '%s', unused: %v\n", marker, result.Provider, result.Hostname, result.Unused)
+		default:
+			fmt.Printf("%s %s: '%s'\n", marker, result.Provider, result.Hostname)
+		}
+	}
+	fmt.Println()
+
+	if err != nil {
+		return fmt.Errorf("Error getting the hostname: %v", err)
+	}
+	fmt.Printf("Hostname: '%s' (provider: %s)\n", data.Hostname, data.Provider)
+	return nil
+}
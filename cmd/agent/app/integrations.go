@@ -42,6 +42,14 @@ try:
 	print(pkg_resources.get_distribution('%s').version)
 except pkg_resources.DistributionNotFound:
 	pass
+`
+	// Imports the module of an integration and checks that it exports a check
+	integrationImportScript = `
+import importlib, inspect
+from datadog_checks.base import AgentCheck
+module = importlib.import_module('datadog_checks.%s')
+if not any(inspect.isclass(c) and issubclass(c, AgentCheck) and c is not AgentCheck for c in vars(module).values()):
+	raise ImportError('the module datadog_checks.%s does not export any check')
 `
 )
 
@@ -365,6 +373,12 @@ func install(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("error installing wheel %s: %v", wheelPath, err)
 		}
 
+		// Verify that the check can be loaded by the agent
+		if err := verifyIntegration(integration); err != nil {
+			return fmt.Errorf("installed %s from %s but its check can't be imported, remove it with `integration remove %s`: %v",
+				integration, wheelPath, integration, err)
+		}
+
 		// Move configuration files
 		if err := moveConfigurationFilesOf(integration); err != nil {
 			fmt.Printf("Installed %s from %s\n", integration, wheelPath)
@@ -442,6 +456,12 @@ func install(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("error installing wheel %s: %v", wheelPath, err)
 	}
 
+	// Verify that the check can be loaded by the agent
+	if err := verifyIntegration(integration); err != nil {
+		return fmt.Errorf("installed %s %s but its check can't be imported, install another version or remove it with `integration remove %s`: %v",
+			integration, versionToInstall, integration, err)
+	}
+
 	// Move configuration files
 	if err := moveConfigurationFilesOf(integration); err != nil {
 		fmt.Printf("Installed %s %s", integration, versionToInstall)
@@ -709,6 +729,36 @@ func installedVersion(integration string) (*semver.Version, bool, error) {
 	return version, true, nil
 }
 
+// verifyIntegration imports the module of an installed integration with the python of the agent,
+// and returns an error if it can't be imported or doesn't export a check
+func verifyIntegration(integration string) error {
+	pythonPath, err := getCommandPython()
+	if err != nil {
+		return err
+	}
+
+	// the module names use underscores, e.g. datadog-go-metro is imported as datadog_checks.go_metro
+	moduleName := strings.Replace(getIntegrationName(integration), "-", "_", -1)
+	validName, err := regexp.MatchString("^[0-9a-z_]+$", moduleName)
+	if err != nil {
+		return fmt.Errorf("Error validating integration name: %s", err)
+	}
+	if !validName {
+		return fmt.Errorf("Cannot verify %s: invalid integration name", integration)
+	}
+
+	pythonCmd := exec.Command(pythonPath, "-c", fmt.Sprintf(integrationImportScript, moduleName, moduleName))
+	if _, err := pythonCmd.Output(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			// the last line of the traceback holds the error
+			lines := strings.Split(strings.TrimSpace(string(exitErr.Stderr)), "\n")
+			return errors.New(lines[len(lines)-1])
+		}
+		return fmt.Errorf("error executing python: %v", err)
+	}
+	return nil
+}
+
 // Parse requirements lines to get a package version.
 // Returns the version and whether or not it was found
 func getVersionFromReqLine(integration string, lines string) (*semver.Version, bool, error) {
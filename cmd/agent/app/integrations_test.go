@@ -10,6 +10,7 @@ package app
 import (
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"testing"
 
@@ -187,3 +188,32 @@ func TestParseWheelPackageNameErrorCases(t *testing.T) {
 		assert.Contains(t, err.Error(), test.expectedErr)
 	}
 }
+
+func TestVerifyIntegration(t *testing.T) {
+	if _, err := exec.LookPath(pythonBin); err != nil {
+		t.Skipf("%s is not available", pythonBin)
+	}
+	defer func(previous bool) { useSysPython = previous }(useSysPython)
+	useSysPython = true
+
+	pythonPath, _ := ioutil.TempDir("", "pythonpath")
+	defer os.RemoveAll(pythonPath)
+	modules := map[string]string{
+		"datadog_checks/__init__.py":          "__path__ = __import__('pkgutil').extend_path(__path__, __name__)\n",
+		"datadog_checks/base/__init__.py":     "class AgentCheck(object):\n    pass\n",
+		"datadog_checks/foo/__init__.py":      "from datadog_checks.base import AgentCheck\nclass FooCheck(AgentCheck):\n    pass\n",
+		"datadog_checks/no_check/__init__.py": "from datadog_checks.base import AgentCheck\n",
+	}
+	for name, content := range modules {
+		path := filepath.Join(pythonPath, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		ioutil.WriteFile(path, []byte(content), 0644)
+	}
+	defer os.Setenv("PYTHONPATH", os.Getenv("PYTHONPATH"))
+	os.Setenv("PYTHONPATH", pythonPath)
+
+	assert.NoError(t, verifyIntegration("datadog-foo"))
+	assert.Error(t, verifyIntegration("datadog-no-check"))
+	assert.Error(t, verifyIntegration("datadog-missing"))
+	assert.Error(t, verifyIntegration("datadog-foo;import os"))
+}
@@ -50,7 +50,7 @@ func launchGui(cmd *cobra.Command, args []string) error {
 	}
 
 	// Get the CSRF token from the agent
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
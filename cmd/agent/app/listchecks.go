@@ -47,7 +47,7 @@ var listCheckCommand = &cobra.Command{
 
 // query for the version
 func doListChecks() error {
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	networkPathPort     int
+	networkPathProtocol string
+)
+
+func init() {
+	AgentCmd.AddCommand(networkPathCmd)
+	networkPathCmd.Flags().IntVarP(&networkPathPort, "port", "p", 0, "Destination port, defaults to 33434 for udp and 443 for tcp")
+	networkPathCmd.Flags().StringVar(&networkPathProtocol, "protocol", traceroute.ProtocolUDP, "Protocol of the probes, udp or tcp")
+}
+
+var networkPathCmd = &cobra.Command{
+	Use:   "network-path <host>",
+	Short: "Print the network path from the host to a destination",
+	Long:  `Run a traceroute from the system-probe to the destination and print the hops with their latency. The system-probe must run with the traceroute module enabled.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		return networkPath(args[0])
+	},
+}
+
+func networkPath(host string) error {
+	c := util.GetIPCClient()
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+
+	query := url.Values{}
+	query.Set("host", host)
+	query.Set("protocol", networkPathProtocol)
+	if networkPathPort > 0 {
+		query.Set("port", strconv.Itoa(networkPathPort))
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/network-path?%s", ipcAddress, config.Datadog.GetInt("cmd_port"), query.Encode())
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		if r != nil && string(r) != "" {
+			fmt.Fprintln(color.Output, fmt.Sprintf("The agent ran into an error while running the traceroute: %s", string(r)))
+		} else {
+			fmt.Fprintln(color.Output, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+		}
+		return err
+	}
+
+	path := traceroute.Path{}
+	if err := json.Unmarshal(r, &path); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(color.Output, fmt.Sprintf("=== Network path to %s (%s) ===", color.GreenString(path.Destination.String()), path.IP))
+	for _, hop := range path.Hops {
+		if hop.IP == "" {
+			fmt.Fprintln(color.Output, fmt.Sprintf("%3d  *", hop.TTL))
+			continue
+		}
+		fmt.Fprintln(color.Output, fmt.Sprintf("%3d  %s  %.3f ms", hop.TTL, color.BlueString(hop.IP), hop.RTT))
+	}
+	if path.Reached {
+		fmt.Fprintln(color.Output, color.GreenString("Destination reached"))
+	} else {
+		fmt.Fprintln(color.Output, color.YellowString("Destination not reached"))
+	}
+	return nil
+}
@@ -60,7 +60,7 @@ func doReloadCheck(checkName string) error {
 		return fmt.Errorf("Must supply a check name to query")
 	}
 
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"runtime"
 	"syscall"
+	"time"
 
 	_ "expvar" // Blank import used because this isn't directly used in this file
 	"net/http"
@@ -25,23 +26,34 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/agent/common/signals"
 	"github.com/DataDog/datadog-agent/cmd/agent/gui"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/collector/corechecks/embed/jmx"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/dbm"
+	"github.com/DataDog/datadog-agent/pkg/diagnose"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
+	"github.com/DataDog/datadog-agent/pkg/netflow"
+	"github.com/DataDog/datadog-agent/pkg/networkpath"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
 	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/process/discovery"
+	"github.com/DataDog/datadog-agent/pkg/sbom"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/capabilities"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/version"
 	"github.com/spf13/cobra"
-	"gopkg.in/DataDog/dd-trace-go.v1/profiler"
 
 	// register core checks
 	_ "github.com/DataDog/datadog-agent/pkg/collector/corechecks/cluster"
@@ -69,6 +81,7 @@ var (
 var (
 	// flags variables
 	pidfilePath string
+	runOnce     bool
 )
 
 func init() {
@@ -78,10 +91,15 @@ func init() {
 
 	// local flags
 	runCmd.Flags().StringVarP(&pidfilePath, "pidfile", "p", "", "path to the pidfile")
+	runCmd.Flags().BoolVar(&runOnce, "once", false, "run the configured checks once, flush their data and exit, the exit code is non-zero if a check failed or some data wasn't delivered")
 }
 
 // Start the main loop
 func run(cmd *cobra.Command, args []string) error {
+	if runOnce {
+		return runChecksOnce()
+	}
+
 	defer func() {
 		StopAgent()
 	}()
@@ -180,13 +198,20 @@ func StartAgent() error {
 
 	log.Infof("Starting Datadog Agent v%v", version.AgentVersion)
 
+	// validate the FIPS proxy the intake traffic goes through
+	if config.Datadog.GetBool("fips.enabled") {
+		if err := diagnose.CheckFipsProxy(); err != nil {
+			log.Warnf("FIPS mode is enabled but %v, the data sent to Datadog may be lost", err)
+		}
+	}
+
 	// init settings that can be changed at runtime
 	if err := settings.InitRuntimeSettings(); err != nil {
 		log.Warnf("Can't initiliaze the runtime settings: %v", err)
 	}
 
 	// Setup Profiling
-	if config.Datadog.GetBool("profiling.enabled") {
+	if profiling.IsEnabled() {
 		err := settings.SetRuntimeSetting("profiling", true)
 		if err != nil {
 			log.Errorf("Error starting profiler: %v", err)
@@ -194,20 +219,26 @@ func StartAgent() error {
 	}
 
 	// Setup expvar server
-	var port = config.Datadog.GetString("expvar_port")
 	if config.Datadog.GetBool("telemetry.enabled") {
 		http.Handle("/telemetry", telemetry.Handler())
 	}
-	go http.ListenAndServe("127.0.0.1:"+port, http.DefaultServeMux) //nolint:errcheck
+	expvarListener, listenErr := debugport.Listen("127.0.0.1", config.Datadog.GetInt("expvar_port"))
+	if listenErr != nil {
+		log.Errorf("Error starting the expvar server: %v", listenErr)
+	} else {
+		if err := debugport.Advertise(debugport.Agent, debugport.Expvar, debugport.Port(expvarListener)); err != nil {
+			log.Debugf("Unable to advertise the expvar port: %v", err)
+		}
+		go http.Serve(expvarListener, http.DefaultServeMux) //nolint:errcheck
+	}
 
 	// Setup healthcheck port
 	var healthPort = config.Datadog.GetInt("health_port")
 	if healthPort > 0 {
-		err := healthprobe.Serve(common.MainCtx, healthPort)
+		err := healthprobe.Serve(common.MainCtx, debugport.Agent, healthPort)
 		if err != nil {
 			return log.Errorf("Error starting health port, exiting: %v", err)
 		}
-		log.Debugf("Health check listening on port %d", healthPort)
 	}
 
 	if pidfilePath != "" {
@@ -224,6 +255,22 @@ func StartAgent() error {
 	}
 	log.Infof("Hostname is: %s", hostname)
 
+	// probe the system capabilities, the components depending on them report why they're disabled
+	for _, c := range capabilities.Get().Capabilities {
+		if c.Available && c.Detail != "" {
+			log.Infof("System capability %s is available: %s", c.Name, c.Detail)
+		} else if c.Available {
+			log.Infof("System capability %s is available", c.Name)
+		} else {
+			log.Infof("System capability %s is not available: %s", c.Name, c.Reason)
+		}
+	}
+	if config.Datadog.GetBool("system_probe_config.enabled") && runtime.GOOS == "linux" {
+		if err := capabilities.Require(capabilities.EBPF); err != nil {
+			log.Warnf("The system-probe is enabled but can't run: %s", err)
+		}
+	}
+
 	// HACK: init host metadata module (CPU) early to avoid any
 	//       COM threading model conflict with the python checks
 	err = host.InitHostMetadata()
@@ -236,6 +283,9 @@ func StartAgent() error {
 		if err = api.StartServer(); err != nil {
 			return log.Errorf("Error while starting api server, exiting: %v", err)
 		}
+		if err = api.StartPublicServer(); err != nil {
+			return log.Errorf("Error while starting the public status server, exiting: %v", err)
+		}
 	}
 
 	// start clc runner server
@@ -269,6 +319,12 @@ func StartAgent() error {
 	agg := aggregator.InitAggregator(s, hostname)
 	agg.AddAgentStartupTelemetry(version.AgentVersion)
 
+	// setup the event platform forwarder, shipping the structured events of the checks
+	common.EventPlatformForwarder = epforwarder.NewEventPlatformForwarder()
+	common.EventPlatformForwarder.Start()
+	agg.SetEventPlatformForwarder(common.EventPlatformForwarder)
+	dbm.SetForwarder(common.EventPlatformForwarder)
+
 	// start dogstatsd
 	if config.Datadog.GetBool("use_dogstatsd") {
 		var err error
@@ -279,6 +335,22 @@ func StartAgent() error {
 	}
 	log.Debugf("statsd started")
 
+	// start the collection of the flows exported by the network devices
+	if netflow.IsEnabled() {
+		common.NetFlowServer, err = netflow.NewServer(common.EventPlatformForwarder, hostname)
+		if err != nil {
+			log.Errorf("Could not start the flow collection: %s", err)
+		}
+	}
+
+	// start the SNMP traps listener
+	if traps.IsEnabled() {
+		common.SnmpTrapsServer, err = traps.NewServer(common.EventPlatformForwarder)
+		if err != nil {
+			log.Errorf("Could not start the SNMP traps listener: %s", err)
+		}
+	}
+
 	// start logs-agent
 	if config.Datadog.GetBool("logs_enabled") || config.Datadog.GetBool("log_enabled") {
 		if config.Datadog.GetBool("log_enabled") {
@@ -292,6 +364,14 @@ func StartAgent() error {
 		log.Info("logs-agent disabled")
 	}
 
+	// start the OTLP receiver, once the logs-agent receiving the OTLP logs is running
+	if otlp.IsEnabled() {
+		common.OTLPServer, err = otlp.NewServer(agg, logs.GetPipelineProvider(), hostname)
+		if err != nil {
+			log.Errorf("Could not start the OTLP receiver: %s", err)
+		}
+	}
+
 	if err = common.SetupSystemProbeConfig(sysProbeConfFilePath); err != nil {
 		log.Infof("System probe config not found, disabling pulling system probe info in the status page: %v", err)
 	}
@@ -319,6 +399,17 @@ func StartAgent() error {
 		}
 	}
 
+	// start the SBOM collection
+	sbom.Start(common.Forwarder, hostname)
+
+	// start the collection of the network paths traced by the system-probe
+	networkpath.Start(common.Forwarder, hostname)
+
+	// start the process discovery
+	if err := discovery.Start(hostname); err != nil {
+		log.Errorf("Error while starting the process discovery: %v", err)
+	}
+
 	// start dependent services
 	startDependentServices()
 	return nil
@@ -338,28 +429,62 @@ func StopAgent() {
 	// gracefully shut down any component
 	common.MainCtxCancel()
 
+	// the components are stopped in order, the intakes first, so that the data they
+	// already received is flushed, and within the stop timeout
+	stopTimeout := config.Datadog.GetDuration("stop_timeout") * time.Second
+	stopped := make(chan struct{})
+	go func() {
+		stopComponents()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(stopTimeout):
+		log.Warnf("The agent components did not stop within %s, exiting now", stopTimeout)
+	}
+
+	os.Remove(pidfilePath)
+	debugport.Remove(debugport.Agent)
+	log.Info("See ya!")
+	log.Flush()
+}
+
+// stopComponents stops the components of the agent
+func stopComponents() {
 	if common.DSD != nil {
 		common.DSD.Stop()
 	}
+	if common.OTLPServer != nil {
+		common.OTLPServer.Stop()
+	}
+	if common.NetFlowServer != nil {
+		common.NetFlowServer.Stop()
+	}
+	if common.SnmpTrapsServer != nil {
+		common.SnmpTrapsServer.Stop()
+	}
 	if common.AC != nil {
 		common.AC.Stop()
 	}
 	if common.MetadataScheduler != nil {
 		common.MetadataScheduler.Stop()
 	}
+	sbom.Stop()
+	discovery.Stop()
+	networkpath.Stop()
 	api.StopServer()
+	api.StopPublicServer()
 	clcrunnerapi.StopCLCRunnerServer()
 	jmx.StopJmxfetch()
 	aggregator.StopDefaultAggregator()
 	if common.Forwarder != nil {
 		common.Forwarder.Stop()
 	}
+	if common.EventPlatformForwarder != nil {
+		common.EventPlatformForwarder.Stop()
+	}
 
 	logs.Stop()
 	gui.StopGUIServer()
-	profiler.Stop()
-
-	os.Remove(pidfilePath)
-	log.Info("See ya!")
-	log.Flush()
+	profiling.Stop()
 }
@@ -0,0 +1,106 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/serializer"
+	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// runChecksOnce runs every configured check once, flushes their data synchronously and returns
+// an error when a check failed or when some data could not be delivered. It's meant for cron
+// jobs and CI runners, where running the agent as a daemon isn't appropriate.
+func runChecksOnce() error {
+	err := common.SetupConfig(confFilePath)
+	if err != nil {
+		return fmt.Errorf("unable to set up global agent configuration: %v", err)
+	}
+
+	err = config.SetupLogger(
+		loggerName,
+		config.Datadog.GetString("log_level"),
+		"", // log to the console only
+		"",
+		false,
+		true,
+		config.Datadog.GetBool("log_format_json"),
+	)
+	if err != nil {
+		return fmt.Errorf("Error while setting up logging, exiting: %v", err)
+	}
+
+	if !config.Datadog.IsSet("api_key") {
+		return fmt.Errorf("no API key configured, exiting")
+	}
+
+	hostname, err := util.GetHostname()
+	if err != nil {
+		return fmt.Errorf("Error while getting hostname, exiting: %v", err)
+	}
+
+	keysPerDomain, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return fmt.Errorf("Misconfiguration of agent endpoints: %v", err)
+	}
+	f := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	f.Start() //nolint:errcheck
+	common.Forwarder = f
+
+	// the aggregator doesn't flush periodically, its data is flushed when it's stopped
+	s := serializer.NewSerializer(f)
+	agg := aggregator.InitAggregatorWithFlushInterval(s, hostname, 0)
+
+	common.SetupAutoConfig(config.Datadog.GetString("confd_path"))
+	var configs []integration.Config
+	for _, c := range common.AC.GetAllConfigs() {
+		if check.IsJMXConfig(c) {
+			log.Warnf("Skipping the JMX check %s, JMX checks can't run once", c.Name)
+			continue
+		}
+		configs = append(configs, c)
+	}
+
+	checks := collector.GetChecksForConfigs(configs)
+	log.Infof("Running %d check instance(s) once", len(checks))
+
+	var failed []check.ID
+	for _, c := range checks {
+		if err := c.Run(); err != nil {
+			log.Errorf("Error running check %s: %s", c.ID(), err)
+			failed = append(failed, c.ID())
+		}
+		for _, w := range c.GetWarnings() {
+			log.Warnf("Check %s: %s", c.ID(), w)
+		}
+		c.Stop()
+	}
+
+	// flush the data of the checks and wait for its delivery
+	agg.Stop()
+	f.Stop()
+
+	stats := forwarder.GetTransactionsStats()
+	log.Infof("Transactions: %d delivered, %d errors, %d dropped, %d waiting to be retried",
+		stats.Success, stats.Errors, stats.Dropped+stats.DroppedOnInput, stats.RetryQueueSize)
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d check instance(s) failed: %v", len(failed), failed)
+	}
+	if !stats.Delivered() {
+		return fmt.Errorf("some data could not be delivered to Datadog")
+	}
+	return nil
+}
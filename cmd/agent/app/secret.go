@@ -59,7 +59,7 @@ var secretInfoCommand = &cobra.Command{
 }
 
 func showSecretInfo() error {
-	c := util.GetClient(false)
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
@@ -10,7 +10,6 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/profiling"
-	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
 // profilingRuntimeSetting wraps operations to change log level at runtime
@@ -29,7 +28,7 @@ func (l profilingRuntimeSetting) Name() string {
 }
 
 func (l profilingRuntimeSetting) Get() (interface{}, error) {
-	return config.Datadog.GetBool("profiling.enabled"), nil
+	return profiling.IsEnabled(), nil
 }
 
 func (l profilingRuntimeSetting) Set(v interface{}) error {
@@ -43,32 +42,13 @@ func (l profilingRuntimeSetting) Set(v interface{}) error {
 	}
 
 	if profile {
-		// populate site
-		s := config.DefaultSite
-		if config.Datadog.IsSet("site") {
-			s = config.Datadog.GetString("site")
-		}
-
-		// allow full url override for development use
-		site := fmt.Sprintf(profiling.ProfileURLTemplate, s)
-		if config.Datadog.IsSet("profiling.profile_dd_url") {
-			site = config.Datadog.GetString("profiling.profile_dd_url")
-		}
-
-		v, _ := version.Agent()
-		err := profiling.Start(
-			config.Datadog.GetString("api_key"),
-			site,
-			config.Datadog.GetString("env"),
-			profiling.ProfileCoreService,
-			fmt.Sprintf("version:%v", v),
-		)
+		err := profiling.StartFromConfig(profiling.ProfileCoreService)
 		if err == nil {
-			config.Datadog.Set("profiling.enabled", true)
+			config.Datadog.Set("internal_profiling.enabled", true)
 		}
 	} else {
 		profiling.Stop()
-		config.Datadog.Set("profiling.enabled", false)
+		config.Datadog.Set("internal_profiling.enabled", false)
 	}
 
 	return nil
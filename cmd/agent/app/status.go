@@ -13,6 +13,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/cmd/agent/common"
@@ -36,6 +37,7 @@ func init() {
 	statusCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	statusCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the status command to a file")
 	statusCmd.AddCommand(componentCmd)
+	componentCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
 	componentCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
 	componentCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the status command to a file")
 }
@@ -66,9 +68,10 @@ var statusCmd = &cobra.Command{
 }
 
 var componentCmd = &cobra.Command{
-	Use:   "component",
+	Use:   "component [name]",
 	Short: "Print the component status",
-	Long:  ``,
+	Long: `Print the status of a component, or only a section of the agent status.
+The sections are: ` + strings.Join(status.Sections(), ", "),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		err := common.SetupConfigWithoutSecrets(confFilePath, "")
 		if err != nil {
@@ -81,6 +84,9 @@ var componentCmd = &cobra.Command{
 		if len(args) != 1 {
 			return fmt.Errorf("a component name must be specified")
 		}
+		if status.IsSection(args[0]) {
+			return sectionStatus(args[0])
+		}
 		return componentStatus(args[0])
 	},
 }
@@ -167,6 +173,51 @@ func getAPMStatus() map[string]interface{} {
 	return status
 }
 
+func sectionStatus(section string) error {
+	var s string
+
+	var r []byte
+	if section == "apm" {
+		// the trace-agent status is not part of the agent status, it's requested from the trace-agent
+		var err error
+		if r, err = json.Marshal(map[string]interface{}{"apmStats": getAPMStatus()}); err != nil {
+			return err
+		}
+	} else {
+		ipcAddress, err := config.GetIPCAddress()
+		if err != nil {
+			return err
+		}
+		urlstr := fmt.Sprintf("https://%v:%v/agent/status/section/%s", ipcAddress, config.Datadog.GetInt("cmd_port"), section)
+		if r, err = makeRequest(urlstr); err != nil {
+			return err
+		}
+	}
+
+	// The rendering is done in the client so that the agent has less work to do
+	if prettyPrintJSON {
+		var prettyJSON bytes.Buffer
+		json.Indent(&prettyJSON, r, "", "  ") //nolint:errcheck
+		s = prettyJSON.String()
+	} else if jsonStatus {
+		s = string(r)
+	} else {
+		formattedStatus, err := status.FormatSection(section, r)
+		if err != nil {
+			return err
+		}
+		s = formattedStatus
+	}
+
+	if statusFilePath != "" {
+		ioutil.WriteFile(statusFilePath, []byte(s), 0644) //nolint:errcheck
+	} else {
+		fmt.Println(s)
+	}
+
+	return nil
+}
+
 func componentStatus(component string) error {
 	var s string
 
@@ -197,7 +248,7 @@ func componentStatus(component string) error {
 
 func makeRequest(url string) ([]byte, error) {
 	var e error
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 
 	// Set session token
 	e = util.SetAuthToken()
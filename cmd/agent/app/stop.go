@@ -37,7 +37,7 @@ func stop(*cobra.Command, []string) error {
 	if err != nil {
 		return fmt.Errorf("unable to set up global agent configuration: %v", err)
 	}
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 
 	// Set session token
 	e := util.SetAuthToken()
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	streamLogsFilters  diagnostic.Filters
+	streamLogsDuration time.Duration
+)
+
+func init() {
+	AgentCmd.AddCommand(streamLogsCmd)
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Name, "name", "", "Filter by integration name")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Type, "type", "", "Filter by type")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Source, "source", "", "Filter by source")
+	streamLogsCmd.Flags().StringVar(&streamLogsFilters.Service, "service", "", "Filter by service")
+	streamLogsCmd.Flags().DurationVarP(&streamLogsDuration, "duration", "d", 0, "Stop streaming after the duration, e.g. 30s, streams until interrupted by default")
+}
+
+var streamLogsCmd = &cobra.Command{
+	Use:   "stream-logs",
+	Short: "Stream the logs being processed by a running agent",
+	Long:  `Print the logs sent by the logs-agent once the processing rules have been applied, along with their source, service and tags.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		return streamLogs()
+	},
+}
+
+// streamLogs prints the logs streamed by the agent, a new request is sent
+// each time the agent ends the stream until the duration elapses.
+func streamLogs() error {
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	urlstr := fmt.Sprintf("https://%v:%v/agent/stream-logs", ipcAddress, config.Datadog.GetInt("cmd_port"))
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(&streamLogsFilters)
+	if err != nil {
+		return err
+	}
+
+	var deadline time.Time
+	if streamLogsDuration > 0 {
+		deadline = time.Now().Add(streamLogsDuration)
+	}
+
+	c := util.GetIPCClient()
+	for deadline.IsZero() || time.Now().Before(deadline) {
+		if !deadline.IsZero() {
+			c.Timeout = time.Until(deadline)
+		}
+		err := util.DoPostChunked(c, urlstr, "application/json", bytes.NewBuffer(body), func(chunk []byte) {
+			fmt.Print(string(chunk))
+		})
+		if err != nil && !deadline.IsZero() && !time.Now().Before(deadline) {
+			// the client timed out at the end of the duration
+			return nil
+		}
+		if err != nil {
+			fmt.Printf("Could not stream logs from the agent: %v \nMake sure the agent is running with logs_enabled before streaming logs and contact support if you continue having issues. \n", err)
+			return err
+		}
+	}
+	return nil
+}
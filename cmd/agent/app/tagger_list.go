@@ -46,7 +46,7 @@ var taggerListCommand = &cobra.Command{
 			return err
 		}
 
-		c := util.GetClient(false) // FIX: get certificates right then make this true
+		c := util.GetIPCClient()
 
 		// Set session token
 		err = util.SetAuthToken()
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+	"github.com/DataDog/datadog-agent/pkg/config"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	AgentCmd.AddCommand(traceAgentCmd)
+	traceAgentCmd.AddCommand(traceAgentStatusCmd)
+	traceAgentStatusCmd.Flags().BoolVarP(&jsonStatus, "json", "j", false, "print out raw json")
+	traceAgentStatusCmd.Flags().BoolVarP(&prettyPrintJSON, "pretty-json", "p", false, "pretty print JSON")
+	traceAgentStatusCmd.Flags().StringVarP(&statusFilePath, "file", "o", "", "Output the trace-agent status to a file")
+}
+
+var traceAgentCmd = &cobra.Command{
+	Use:   "trace-agent",
+	Short: "Trace Agent commands",
+	Long:  ``,
+}
+
+var traceAgentStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Print the status of the Trace Agent",
+	Long: `Print the receiver rates per client language, the sampling rates, the writers stats
+and the errors of the Trace Agent, as reported by its expvar server.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		err := common.SetupConfigWithoutSecrets(confFilePath, "")
+		if err != nil {
+			return fmt.Errorf("unable to set up global agent configuration: %v", err)
+		}
+
+		err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+		if err != nil {
+			fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+			return err
+		}
+
+		return sectionStatus("apm")
+	},
+}
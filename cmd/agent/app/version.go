@@ -8,6 +8,7 @@ package app
 import (
 	"fmt"
 	"runtime"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -43,5 +44,8 @@ var versionCmd = &cobra.Command{
 				color.RedString(runtime.Version()),
 			),
 		)
+		if components := version.Components(); len(components) > 0 {
+			fmt.Fprintln(color.Output, fmt.Sprintf("Build components: %s", strings.Join(components, ", ")))
+		}
 	},
 }
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package app
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/common"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	wizardConfdPath string
+	wizardForce     bool
+	wizardOffline   bool
+)
+
+func init() {
+	AgentCmd.AddCommand(wizardCmd)
+	wizardCmd.Flags().StringVar(&wizardConfdPath, "confd", "", "path to the conf.d directory of the integrations, defaults to conf.d in the configuration directory")
+	wizardCmd.Flags().BoolVarP(&wizardForce, "force", "f", false, "overwrite the existing configuration files")
+	wizardCmd.Flags().BoolVar(&wizardOffline, "offline", false, "don't check the API key against Datadog")
+}
+
+var wizardCmd = &cobra.Command{
+	Use:   "wizard",
+	Short: "Interactively generate the configuration of the agent",
+	Long: `Ask for the Datadog site, the API key and the features to enable, and write datadog.yaml.
+Then ask for the required parameters of the integrations to configure, as documented in their conf.yaml.example, and write their conf.yaml.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if flagNoColor {
+			color.NoColor = true
+		}
+
+		confPath := confFilePath
+		if confPath == "" {
+			confPath = common.DefaultConfPath
+		}
+		confdPath := wizardConfdPath
+		if confdPath == "" {
+			confdPath = filepath.Join(confPath, "conf.d")
+		}
+		return common.RunWizard(os.Stdin, color.Output, confPath, confdPath, wizardForce, wizardOffline)
+	},
+}
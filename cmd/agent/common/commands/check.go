@@ -57,6 +57,11 @@ var (
 	profileMemoryFilters string
 	profileMemoryUnit    string
 	profileMemoryVerbose string
+	profileCheck         bool
+	profileDir           string
+	checkDiff            bool
+	checkDiffInterval    int
+	checkMemory          bool
 )
 
 func setupCmd(cmd *cobra.Command) {
@@ -68,6 +73,11 @@ func setupCmd(cmd *cobra.Command) {
 	cmd.Flags().BoolVarP(&formatJSON, "json", "", false, "format aggregator and check runner output as json")
 	cmd.Flags().StringVarP(&breakPoint, "breakpoint", "b", "", "set a breakpoint at a particular line number (Python checks only)")
 	cmd.Flags().BoolVarP(&profileMemory, "profile-memory", "m", false, "run the memory profiler (Python checks only)")
+	cmd.Flags().BoolVar(&profileCheck, "profile", false, "run the check under CPU and memory profiling, writing flame-graph-ready pprof profiles")
+	cmd.Flags().StringVar(&profileDir, "profile-dir", "", "the directory in which to write the profiles (default: a new temporary directory)")
+	cmd.Flags().BoolVar(&checkDiff, "diff", false, "run the check twice and print the values the aggregator computes from the two runs, e.g. the rates and the monotonic counts")
+	cmd.Flags().IntVar(&checkDiffInterval, "diff-interval", 1000, "interval between the two runs of the diff mode, in milliseconds")
+	cmd.Flags().BoolVar(&checkMemory, "memory", false, "snapshot the Python objects before and after every run and print their growth (Python checks only)")
 	cmd.Flags().BoolVar(&fullSketches, "full-sketches", false, "output sketches with bins information")
 	config.Datadog.BindPFlag("cmd.check.fullsketches", cmd.Flags().Lookup("full-sketches")) //nolint:errcheck
 
@@ -115,6 +125,17 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				return nil
 			}
 
+			if checkDiff && (checkRate || formatJSON || profileMemory) {
+				return fmt.Errorf("the --diff flag can't be used with --check-rate, --json or --profile-memory")
+			}
+			if checkDiff && checkDiffInterval <= 0 {
+				return fmt.Errorf("--diff-interval must be a positive number of milliseconds")
+			}
+
+			if checkMemory {
+				config.Datadog.Set("python_memory_tracker", true)
+			}
+
 			hostname, err := util.GetHostname()
 			if err != nil {
 				fmt.Printf("Cannot get hostname, exiting: %v\n", err)
@@ -250,6 +271,39 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				}
 			}
 
+			if profileCheck {
+				profileDir, err = profileDirectory(profileDir, checkName)
+				if err != nil {
+					return err
+				}
+
+				// the Python checks are profiled by the embedded profiler of the base check
+				for idx := range allConfigs {
+					conf := &allConfigs[idx]
+					if conf.Name != checkName {
+						continue
+					}
+
+					var data map[string]interface{}
+
+					err = yaml.Unmarshal(conf.InitConfig, &data)
+					if err != nil {
+						return err
+					}
+
+					if data == nil {
+						data = make(map[string]interface{})
+					}
+
+					data["profile_cpu"] = filepath.Join(profileDir, pythonProfileDir)
+
+					y, _ := yaml.Marshal(data)
+					conf.InitConfig = y
+
+					break
+				}
+			}
+
 			cs := collector.GetChecksByNameForConfigs(checkName, allConfigs)
 
 			// something happened while getting the check(s), display some info.
@@ -282,8 +336,25 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				fmt.Println("Multiple check instances found, running each of them")
 			}
 
+			var profiler *checkProfiler
+			if profileCheck {
+				profiler, err = startCheckProfiler(profileDir)
+				if err != nil {
+					return fmt.Errorf("unable to start the profiler: %v", err)
+				}
+			}
+
 			var instancesData []interface{}
 			for _, c := range cs {
+				if checkDiff {
+					s, first, second := runCheckDiff(c, agg)
+					fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Deltas")))
+					printDeltas(color.Output, first, second)
+					checkStatus, _ := status.GetCheckStatus(c, s)
+					fmt.Println(string(checkStatus))
+					continue
+				}
+
 				s := runCheck(c, agg)
 
 				// Sleep for a while to allow the aggregator to finish ingesting all the metrics/events/sc
@@ -313,6 +384,9 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 						"runner":      runnerData,
 						"inventories": collectorData["inventories"],
 					}
+					if memoryStats, ok := getCheckMemoryStats(c.ID()); checkMemory && ok {
+						instanceData["python_memory"] = memoryStats
+					}
 					instancesData = append(instancesData, instanceData)
 				} else if profileMemory {
 					// Every instance will create its own directory
@@ -372,6 +446,27 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 					checkStatus, _ := status.GetCheckStatus(c, s)
 					fmt.Println(string(checkStatus))
 				}
+
+				if checkMemory && !formatJSON {
+					fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Python Memory")))
+					if memoryStats, ok := getCheckMemoryStats(c.ID()); ok {
+						printCheckMemory(color.Output, memoryStats)
+					} else {
+						fmt.Println("No memory was tracked, only the memory of the Python checks is tracked")
+					}
+				}
+			}
+
+			if profiler != nil {
+				profiles, err := profiler.stop()
+				if err != nil {
+					return fmt.Errorf("unable to write the profiles: %v", err)
+				}
+				fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("Profiles")))
+				for _, profile := range profiles {
+					fmt.Println(profile)
+				}
+				color.Yellow("Render the profiles as flame graphs with: go tool pprof -http=: %s", filepath.Join(profileDir, cpuProfileName))
 			}
 
 			if runtime.GOOS == "windows" {
@@ -382,8 +477,8 @@ func Check(loggerName config.LoggerName, confFilePath *string, flagNoColor *bool
 				fmt.Fprintln(color.Output, fmt.Sprintf("=== %s ===", color.BlueString("JSON")))
 				instancesJSON, _ := json.MarshalIndent(instancesData, "", "  ")
 				fmt.Println(string(instancesJSON))
-			} else if singleCheckRun() {
-				if profileMemory {
+			} else if singleCheckRun() && !checkDiff {
+				if profileMemory || checkMemory {
 					color.Yellow("Check has run only once, to collect diff data run the check multiple times with the -t/--check-times flag.")
 				} else {
 					color.Yellow("Check has run only once, if some metrics are missing you can try again with --check-rate to see any other metric if available.")
@@ -416,7 +511,12 @@ func runCheck(c check.Check, agg *aggregator.BufferedAggregator) *check.Stats {
 	}
 	for i := 0; i < times; i++ {
 		t0 := time.Now()
-		err := c.Run()
+		var err error
+		if profileCheck {
+			err = runWithProfileLabels(c)
+		} else {
+			err = c.Run()
+		}
 		warnings := c.GetWarnings()
 		mStats, _ := c.GetMetricStats()
 		s.Add(time.Since(t0), err, warnings, mStats)
@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package commands
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// runCheckDiff runs the check twice, checkDiffInterval apart, and returns the series flushed by the
+// aggregator after each run. The rates and the monotonic counts are only computed from the second run.
+func runCheckDiff(c check.Check, agg *aggregator.BufferedAggregator) (*check.Stats, metrics.Series, metrics.Series) {
+	s := check.NewStats(c)
+	var flushed [2]metrics.Series
+	for i := range flushed {
+		if i > 0 {
+			time.Sleep(time.Duration(checkDiffInterval) * time.Millisecond)
+		}
+		t0 := time.Now()
+		err := c.Run()
+		warnings := c.GetWarnings()
+		mStats, _ := c.GetMetricStats()
+		s.Add(time.Since(t0), err, warnings, mStats)
+
+		// Sleep for a while to allow the aggregator to finish ingesting all the metrics
+		time.Sleep(time.Duration(checkDelay) * time.Millisecond)
+		flushed[i], _ = agg.GetSeriesAndSketches()
+	}
+	return s, flushed[0], flushed[1]
+}
+
+// serieDelta is a context of the series flushed by the aggregator after the first and the second runs
+type serieDelta struct {
+	name   string
+	host   string
+	tags   string
+	mtype  metrics.APIMetricType
+	first  *float64
+	second *float64
+}
+
+// note explains how the aggregator computed the value sent to the backend
+func (d *serieDelta) note() string {
+	switch {
+	case d.second == nil:
+		return "not submitted by the second run"
+	case d.first == nil && d.mtype == metrics.APICountType:
+		return "monotonic_count: increase between the runs"
+	case d.first == nil:
+		return "rate: per second increase between the runs"
+	case d.mtype == metrics.APICountType:
+		return "count: sum of the values submitted by the run"
+	default:
+		return "gauge: last value submitted by the run"
+	}
+}
+
+func serieContext(serie *metrics.Serie) string {
+	tags := append([]string{}, serie.Tags...)
+	sort.Strings(tags)
+	return serie.Name + "|" + serie.Host + "|" + strings.Join(tags, ",")
+}
+
+// computeDeltas matches the series of the two runs by context, sorted by metric name and tags
+func computeDeltas(first, second metrics.Series) []*serieDelta {
+	contexts := make(map[string]*serieDelta)
+	var deltas []*serieDelta
+	add := func(serie *metrics.Serie, second bool) {
+		if len(serie.Points) == 0 {
+			return
+		}
+		key := serieContext(serie)
+		d, ok := contexts[key]
+		if !ok {
+			tags := append([]string{}, serie.Tags...)
+			sort.Strings(tags)
+			d = &serieDelta{name: serie.Name, host: serie.Host, tags: strings.Join(tags, ",")}
+			contexts[key] = d
+			deltas = append(deltas, d)
+		}
+		value := serie.Points[len(serie.Points)-1].Value
+		d.mtype = serie.MType
+		if second {
+			d.second = &value
+		} else {
+			d.first = &value
+		}
+	}
+	for _, serie := range first {
+		add(serie, false)
+	}
+	for _, serie := range second {
+		add(serie, true)
+	}
+
+	sort.Slice(deltas, func(i, j int) bool {
+		if deltas[i].name != deltas[j].name {
+			return deltas[i].name < deltas[j].name
+		}
+		if deltas[i].tags != deltas[j].tags {
+			return deltas[i].tags < deltas[j].tags
+		}
+		return deltas[i].host < deltas[j].host
+	})
+	return deltas
+}
+
+// printDeltas prints the values flushed after each run, the values of the second run being the
+// ones the backend would receive
+func printDeltas(w io.Writer, first, second metrics.Series) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "METRIC\tTAGS\tFIRST RUN\tSECOND RUN\tNOTE")
+	for _, d := range computeDeltas(first, second) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.name, d.tags, formatDeltaValue(d.first), formatDeltaValue(d.second), d.note())
+	}
+	tw.Flush()
+}
+
+func formatDeltaValue(value *float64) string {
+	if value == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*value, 'g', -1, 64)
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package commands
+
+import (
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+// checkMemoryStats is the memory tracked by the python package for the runs of a Python check
+type checkMemoryStats struct {
+	Runs        int
+	LastGrowth  int64
+	TotalGrowth int64
+	GrowingRuns int
+	TopTypes    []struct {
+		Type     string
+		NObjects int
+		Size     int
+	}
+	Warning string
+}
+
+// getCheckMemoryStats returns the memory tracked for the check, if it's a Python check
+func getCheckMemoryStats(id check.ID) (*checkMemoryStats, bool) {
+	v := expvar.Get("pythonMemory")
+	if v == nil {
+		return nil, false
+	}
+	stats := make(map[string]*checkMemoryStats)
+	if err := json.Unmarshal([]byte(v.String()), &stats); err != nil {
+		return nil, false
+	}
+	s, ok := stats[string(id)]
+	return s, ok
+}
+
+// printCheckMemory prints the growth of the Python objects over the runs of the check and the
+// types that grew the most during the last run
+func printCheckMemory(w io.Writer, stats *checkMemoryStats) {
+	fmt.Fprintf(w, "Tracked runs: %d\n", stats.Runs)
+	fmt.Fprintf(w, "Growth during the last run: %d bytes\n", stats.LastGrowth)
+	fmt.Fprintf(w, "Growth since the first run: %d bytes\n", stats.TotalGrowth)
+	fmt.Fprintf(w, "Consecutive growing runs: %d\n", stats.GrowingRuns)
+	if stats.Warning != "" {
+		fmt.Fprintf(w, "Warning: %s\n", stats.Warning)
+	}
+	if len(stats.TopTypes) == 0 {
+		return
+	}
+
+	fmt.Fprintln(w, "\nTypes that grew the most during the last run:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TYPE\tOBJECTS\tSIZE (BYTES)")
+	for _, t := range stats.TopTypes {
+		fmt.Fprintf(tw, "%s\t%+d\t%+d\n", t.Type, t.NObjects, t.Size)
+	}
+	tw.Flush()
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package commands
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+const (
+	cpuProfileName  = "cpu.pprof"
+	heapProfileName = "heap.pprof"
+	// pythonProfileDir is the sub-directory in which the Python checks write their profiles
+	pythonProfileDir = "python"
+)
+
+// checkProfiler profiles the CPU and the memory of the agent while the check runs. The profiles
+// are written in the pprof format, `go tool pprof -http=: <profile>` renders them as flame graphs.
+type checkProfiler struct {
+	dir     string
+	cpuFile *os.File
+}
+
+// profileDirectory returns the directory in which the profiles of the check are written,
+// a new directory under the temporary directory when none is given
+func profileDirectory(dir, checkName string) (string, error) {
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), fmt.Sprintf("datadog-agent-profile-%s-%s", checkName, time.Now().Format("20060102-150405")))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("unable to create the profile directory: %v", err)
+	}
+	return dir, nil
+}
+
+// startCheckProfiler starts the CPU profiling, writing the profile in the given directory
+func startCheckProfiler(dir string) (*checkProfiler, error) {
+	f, err := os.Create(filepath.Join(dir, cpuProfileName))
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &checkProfiler{dir: dir, cpuFile: f}, nil
+}
+
+// stop stops the CPU profiling, writes the heap profile and returns the paths of the profiles
+func (p *checkProfiler) stop() ([]string, error) {
+	pprof.StopCPUProfile()
+	if err := p.cpuFile.Close(); err != nil {
+		return nil, err
+	}
+
+	heapPath := filepath.Join(p.dir, heapProfileName)
+	f, err := os.Create(heapPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	// collect the garbage so that the profile reflects the live objects
+	runtime.GC()
+	if err := pprof.WriteHeapProfile(f); err != nil {
+		return nil, err
+	}
+
+	profiles := []string{p.cpuFile.Name(), heapPath}
+	// the profiles of the Python checks are written by the embedded profiler
+	if _, err := os.Stat(filepath.Join(p.dir, pythonProfileDir)); err == nil {
+		profiles = append(profiles, filepath.Join(p.dir, pythonProfileDir))
+	}
+	return profiles, nil
+}
+
+// runWithProfileLabels runs the check with pprof labels identifying it, so that the samples of the
+// CPU profile can be attributed to the check and its instance, e.g. with `go tool pprof -tagfocus`
+func runWithProfileLabels(c check.Check) error {
+	var err error
+	labels := pprof.Labels("check", c.String(), "check_id", string(c.ID()))
+	pprof.Do(context.Background(), labels, func(context.Context) {
+		err = c.Run()
+	})
+	return err
+}
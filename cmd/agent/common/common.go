@@ -17,8 +17,12 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
 	"github.com/DataDog/datadog-agent/pkg/metadata"
+	"github.com/DataDog/datadog-agent/pkg/netflow"
+	"github.com/DataDog/datadog-agent/pkg/otlp"
+	"github.com/DataDog/datadog-agent/pkg/snmp/traps"
 	"github.com/DataDog/datadog-agent/pkg/util/executable"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -33,12 +37,24 @@ var (
 	// DSD is the global dogstatsd instance
 	DSD *dogstatsd.Server
 
+	// OTLPServer is the global receiver of the OTLP metrics and logs
+	OTLPServer *otlp.Server
+
+	// NetFlowServer is the global server of the flows exported by the network devices
+	NetFlowServer *netflow.Server
+
+	// SnmpTrapsServer is the global server of the SNMP traps sent by the network devices
+	SnmpTrapsServer *traps.Server
+
 	// MetadataScheduler is responsible to orchestrate metadata collection
 	MetadataScheduler *metadata.Scheduler
 
 	// Forwarder is the global forwarder instance
 	Forwarder forwarder.Forwarder
 
+	// EventPlatformForwarder is the global forwarder of the events sent to the event platform intake tracks
+	EventPlatformForwarder epforwarder.EventPlatformForwarder
+
 	// MainCtx is the main agent context passed to components
 	MainCtx context.Context
 
@@ -0,0 +1,394 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// WizardSites are the Datadog sites offered by the wizard
+var WizardSites = []string{"datadoghq.com", "datadoghq.eu", "us3.datadoghq.com", "ddog-gov.com"}
+
+var apiKeyRegexp = regexp.MustCompile(`^[0-9a-fA-F]{32}$`)
+
+// paramRegexp matches the `## @param <name> - <type> - required|optional` lines of the
+// configuration examples of the integrations
+var paramRegexp = regexp.MustCompile(`^(\s*)## @param (\S+) - (.+?) - (required|optional)(?: - default: (.*))?$`)
+
+// Wizard asks for the settings of a first install of the agent and writes its configuration
+type Wizard struct {
+	in        *bufio.Reader
+	out       io.Writer
+	confPath  string // the folder containing datadog.yaml
+	confdPath string
+	force     bool
+	// validateAPIKey checks the API key against the site, it's nil to skip the check
+	validateAPIKey func(apiKey, site string) (bool, error)
+}
+
+// IntegrationParam is a parameter of the instances of an integration, as documented in its
+// configuration example
+type IntegrationParam struct {
+	Name        string
+	Type        string
+	Required    bool
+	Default     string
+	Description string
+}
+
+// RunWizard interactively generates datadog.yaml in confPath, and the configuration files of
+// the integrations in confdPath. The API key is checked online unless offline is set.
+func RunWizard(in io.Reader, out io.Writer, confPath, confdPath string, force, offline bool) error {
+	w := &Wizard{
+		in:             bufio.NewReader(in),
+		out:            out,
+		confPath:       confPath,
+		confdPath:      confdPath,
+		force:          force,
+		validateAPIKey: ValidateAPIKey,
+	}
+	if offline {
+		w.validateAPIKey = nil
+	}
+	return w.Run()
+}
+
+// Run asks for the settings and writes the configuration files
+func (w *Wizard) Run() error {
+	datadogYaml := filepath.Join(w.confPath, "datadog.yaml")
+	if _, err := os.Stat(datadogYaml); err == nil && !w.force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", datadogYaml)
+	}
+
+	fmt.Fprintln(w.out, color.BlueString("This wizard generates the configuration of the agent, press Enter to keep the [default] values."))
+
+	site, err := w.askChoice("Datadog site", WizardSites, 0)
+	if err != nil {
+		return err
+	}
+	apiKey, err := w.askAPIKey(site)
+	if err != nil {
+		return err
+	}
+	logs, err := w.askBool("Enable the logs collection", false)
+	if err != nil {
+		return err
+	}
+	apm, err := w.askBool("Enable APM", true)
+	if err != nil {
+		return err
+	}
+	process, err := w.askBool("Enable the live processes collection", false)
+	if err != nil {
+		return err
+	}
+
+	if err := writeYaml(datadogYaml, datadogConfig(apiKey, site, logs, apm, process)); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "%s %s\n", color.GreenString("Wrote"), datadogYaml)
+
+	for {
+		name, err := w.ask("Integration to configure (empty to finish)", "")
+		if err != nil {
+			return err
+		}
+		if name == "" {
+			break
+		}
+		if err := w.configureIntegration(name); err != nil {
+			fmt.Fprintln(w.out, color.RedString("Could not configure %s: %v", name, err))
+		}
+	}
+
+	fmt.Fprintln(w.out, "Restart the agent to apply the configuration.")
+	return nil
+}
+
+// datadogConfig returns the content of datadog.yaml for the given settings
+func datadogConfig(apiKey, site string, logs, apm, process bool) yaml.MapSlice {
+	processEnabled := "false"
+	if process {
+		processEnabled = "true"
+	}
+	return yaml.MapSlice{
+		{Key: "api_key", Value: apiKey},
+		{Key: "site", Value: site},
+		{Key: "logs_enabled", Value: logs},
+		{Key: "apm_config", Value: yaml.MapSlice{{Key: "enabled", Value: apm}}},
+		{Key: "process_config", Value: yaml.MapSlice{{Key: "enabled", Value: processEnabled}}},
+	}
+}
+
+// askAPIKey asks for an API key until it has a valid format and, when it can be checked, is
+// accepted by the site
+func (w *Wizard) askAPIKey(site string) (string, error) {
+	for {
+		apiKey, err := w.ask("API key", "")
+		if err != nil {
+			return "", err
+		}
+		if !apiKeyRegexp.MatchString(apiKey) {
+			fmt.Fprintln(w.out, color.RedString("An API key is made of 32 hexadecimal characters"))
+			continue
+		}
+		if w.validateAPIKey == nil {
+			return apiKey, nil
+		}
+		valid, err := w.validateAPIKey(apiKey, site)
+		if err != nil {
+			// the agent may be installed before its network access is set up
+			fmt.Fprintln(w.out, color.YellowString("Could not check the API key, keeping it: %v", err))
+			return apiKey, nil
+		}
+		if valid {
+			return apiKey, nil
+		}
+		fmt.Fprintln(w.out, color.RedString("The API key is not valid for %s", site))
+	}
+}
+
+// configureIntegration asks for the required parameters of an instance of the integration
+// and writes its conf.yaml
+func (w *Wizard) configureIntegration(name string) error {
+	dir := filepath.Join(w.confdPath, name+".d")
+	example, err := ioutil.ReadFile(filepath.Join(dir, "conf.yaml.example"))
+	if err != nil {
+		return fmt.Errorf("no configuration example for the integration, is it installed? %v", err)
+	}
+	confYaml := filepath.Join(dir, "conf.yaml")
+	if _, err := os.Stat(confYaml); err == nil && !w.force {
+		return fmt.Errorf("%s already exists, use --force to overwrite it", confYaml)
+	}
+
+	instance := yaml.MapSlice{}
+	for _, param := range ParseInstanceParams(example) {
+		if !param.Required {
+			continue
+		}
+		if param.Description != "" {
+			fmt.Fprintln(w.out, color.New(color.Faint).Sprint(param.Description))
+		}
+		for {
+			raw, err := w.ask(fmt.Sprintf("%s (%s)", param.Name, param.Type), param.Default)
+			if err != nil {
+				return err
+			}
+			value, err := ParseParamValue(param.Type, raw)
+			if err != nil {
+				fmt.Fprintln(w.out, color.RedString("%v", err))
+				continue
+			}
+			instance = append(instance, yaml.MapItem{Key: param.Name, Value: value})
+			break
+		}
+	}
+
+	conf := yaml.MapSlice{
+		{Key: "init_config", Value: map[string]interface{}{}},
+		{Key: "instances", Value: []interface{}{instance}},
+	}
+	if err := writeYaml(confYaml, conf); err != nil {
+		return err
+	}
+	fmt.Fprintf(w.out, "%s %s\n", color.GreenString("Wrote"), confYaml)
+	return nil
+}
+
+// ParseInstanceParams returns the top-level parameters of the instances documented in the
+// configuration example of an integration
+func ParseInstanceParams(example []byte) []IntegrationParam {
+	var params []IntegrationParam
+	inInstances := false
+	indent := -1
+	var last *IntegrationParam
+	for _, line := range strings.Split(string(example), "\n") {
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "#") && strings.TrimSpace(line) != "" {
+			inInstances = strings.HasPrefix(line, "instances:")
+			last = nil
+			continue
+		}
+		if !inInstances {
+			continue
+		}
+		if m := paramRegexp.FindStringSubmatch(line); m != nil {
+			last = nil
+			// the parameters of the nested options are more indented
+			if indent >= 0 && len(m[1]) > indent {
+				continue
+			}
+			indent = len(m[1])
+			params = append(params, IntegrationParam{
+				Name:     m[2],
+				Type:     m[3],
+				Required: m[4] == "required",
+				Default:  strings.TrimSpace(m[5]),
+			})
+			last = &params[len(params)-1]
+			continue
+		}
+		// the first line of the description follows the @param line
+		if last != nil {
+			if trimmed := strings.TrimSpace(line); strings.HasPrefix(trimmed, "##") {
+				last.Description = strings.TrimSpace(strings.TrimPrefix(trimmed, "##"))
+			}
+			last = nil
+		}
+	}
+	return params
+}
+
+// ParseParamValue converts the answer for a parameter to its type, documented with the
+// `@param` annotations
+func ParseParamValue(paramType, raw string) (interface{}, error) {
+	if raw == "" {
+		return nil, fmt.Errorf("a value is required")
+	}
+	switch paramType {
+	case "string":
+		return raw, nil
+	case "integer":
+		v, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", raw)
+		}
+		return v, nil
+	case "number", "double", "float":
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", raw)
+		}
+		return v, nil
+	case "boolean":
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean, use true or false", raw)
+		}
+		return v, nil
+	case "list of strings":
+		var values []string
+		for _, v := range strings.Split(raw, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		return values, nil
+	}
+	// the other types, e.g. mappings, are entered in the YAML flow style
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(raw), &v); err != nil {
+		return nil, fmt.Errorf("%q is not a valid %s: %v", raw, paramType, err)
+	}
+	return v, nil
+}
+
+// ValidateAPIKey checks an API key against the validation endpoint of the site
+func ValidateAPIKey(apiKey, site string) (bool, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://api.%s/api/v1/validate", site), nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("DD-API-KEY", apiKey)
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusForbidden:
+		return false, nil
+	}
+	return false, fmt.Errorf("unexpected response code from the API key validation endpoint: %d", resp.StatusCode)
+}
+
+func (w *Wizard) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(w.out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(w.out, "%s: ", question)
+	}
+	answer, err := w.in.ReadString('\n')
+	if err != nil && (err != io.EOF || answer == "") {
+		return "", fmt.Errorf("no answer: %v", err)
+	}
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+func (w *Wizard) askBool(question string, defaultValue bool) (bool, error) {
+	choices := "y/N"
+	if defaultValue {
+		choices = "Y/n"
+	}
+	for {
+		answer, err := w.ask(fmt.Sprintf("%s (%s)", question, choices), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(w.out, color.RedString("Please answer yes or no"))
+	}
+}
+
+func (w *Wizard) askChoice(question string, choices []string, defaultIndex int) (string, error) {
+	for i, choice := range choices {
+		fmt.Fprintf(w.out, "  %d. %s\n", i+1, choice)
+	}
+	for {
+		answer, err := w.ask(question, strconv.Itoa(defaultIndex+1))
+		if err != nil {
+			return "", err
+		}
+		if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(choices) {
+			return choices[i-1], nil
+		}
+		for _, choice := range choices {
+			if answer == choice {
+				return choice, nil
+			}
+		}
+		fmt.Fprintln(w.out, color.RedString("Please pick one of the numbers above"))
+	}
+}
+
+// writeYaml writes the configuration, readable by the agent only since it may hold secrets
+func writeYaml(path string, content yaml.MapSlice) error {
+	b, err := yaml.Marshal(content)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0640)
+}
@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package common
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const testConfExample = `init_config:
+
+    ## @param service - string - optional
+    ## Attach the tag service:<SERVICE> to every metric.
+    #
+    # service: <SERVICE>
+
+instances:
+
+    ## @param url - string - required
+    ## The URL of the status page.
+    #
+  - url: http://localhost/status
+
+    ## @param port - integer - required - default: 8080
+    ## The port of the server.
+    #
+    port: 8080
+
+    ## @param ssl_verify - boolean - optional - default: true
+    #
+    # ssl_verify: true
+
+    ## @param auth - mapping - optional
+    ## The authentication settings.
+    #
+    # auth:
+      ## @param username - string - required
+      #
+      # username: <USERNAME>
+`
+
+func TestParseInstanceParams(t *testing.T) {
+	params := ParseInstanceParams([]byte(testConfExample))
+	assert.Equal(t, []IntegrationParam{
+		{Name: "url", Type: "string", Required: true, Description: "The URL of the status page."},
+		{Name: "port", Type: "integer", Required: true, Default: "8080", Description: "The port of the server."},
+		{Name: "ssl_verify", Type: "boolean", Default: "true"},
+		{Name: "auth", Type: "mapping", Description: "The authentication settings."},
+	}, params)
+}
+
+func TestParseParamValue(t *testing.T) {
+	for _, tc := range []struct {
+		paramType string
+		raw       string
+		expected  interface{}
+	}{
+		{"string", "foo", "foo"},
+		{"integer", "42", 42},
+		{"number", "1.5", 1.5},
+		{"boolean", "true", true},
+		{"list of strings", "a, b,c", []string{"a", "b", "c"}},
+		{"mapping", "{a: b}", map[interface{}]interface{}{"a": "b"}},
+	} {
+		v, err := ParseParamValue(tc.paramType, tc.raw)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, v)
+	}
+
+	for _, tc := range [][]string{{"integer", "foo"}, {"boolean", "maybe"}, {"string", ""}, {"mapping", "{a"}} {
+		_, err := ParseParamValue(tc[0], tc[1])
+		assert.Error(t, err)
+	}
+}
+
+func TestWizard(t *testing.T) {
+	confPath, err := ioutil.TempDir("", "wizard")
+	require.NoError(t, err)
+	defer os.RemoveAll(confPath)
+	confdPath := filepath.Join(confPath, "conf.d")
+	require.NoError(t, os.MkdirAll(filepath.Join(confdPath, "nginx.d"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(confdPath, "nginx.d", "conf.yaml.example"), []byte(testConfExample), 0644))
+
+	apiKey := strings.Repeat("a", 32)
+	input := strings.Join([]string{
+		"2",         // site
+		"not-a-key", // invalid format
+		strings.Repeat("b", 32),
+		apiKey,
+		"y",     // logs
+		"",      // APM, enabled by default
+		"maybe", // invalid answer
+		"n",     // processes
+		"nginx",
+		"http://localhost/nginx_status",
+		"not-a-port",
+		"", // default port
+		"unknown",
+		"",
+	}, "\n") + "\n"
+
+	var validated []string
+	w := &Wizard{
+		in:        bufio.NewReader(strings.NewReader(input)),
+		out:       ioutil.Discard,
+		confPath:  confPath,
+		confdPath: confdPath,
+		validateAPIKey: func(key, site string) (bool, error) {
+			validated = append(validated, site)
+			return key == apiKey, nil
+		},
+	}
+	require.NoError(t, w.Run())
+	assert.Equal(t, []string{"datadoghq.eu", "datadoghq.eu"}, validated)
+
+	var datadogYaml map[string]interface{}
+	b, err := ioutil.ReadFile(filepath.Join(confPath, "datadog.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(b, &datadogYaml))
+	assert.Equal(t, map[string]interface{}{
+		"api_key":        apiKey,
+		"site":           "datadoghq.eu",
+		"logs_enabled":   true,
+		"apm_config":     map[interface{}]interface{}{"enabled": true},
+		"process_config": map[interface{}]interface{}{"enabled": "false"},
+	}, datadogYaml)
+
+	var confYaml map[string]interface{}
+	b, err = ioutil.ReadFile(filepath.Join(confdPath, "nginx.d", "conf.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(b, &confYaml))
+	assert.Equal(t, []interface{}{
+		map[interface{}]interface{}{"url": "http://localhost/nginx_status", "port": 8080},
+	}, confYaml["instances"])
+
+	// the existing configuration is kept without --force
+	w.in = bufio.NewReader(strings.NewReader(input))
+	assert.Error(t, w.Run())
+}
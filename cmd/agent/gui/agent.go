@@ -33,6 +33,8 @@ func agentHandler(r *mux.Router) {
 	r.HandleFunc("/getConfig", http.HandlerFunc(getConfigFile)).Methods("POST")
 	r.HandleFunc("/getConfig/{setting}", http.HandlerFunc(getConfigSetting)).Methods("GET")
 	r.HandleFunc("/setConfig", http.HandlerFunc(setConfigFile)).Methods("POST")
+	r.HandleFunc("/runtimeSettings", http.HandlerFunc(listRuntimeSettings)).Methods("POST")
+	r.HandleFunc("/runtimeSettings/{setting}", http.HandlerFunc(changeRuntimeSetting)).Methods("POST")
 }
 
 // Sends a simple reply (for checking connection to server)
@@ -226,3 +228,37 @@ func setConfigFile(w http.ResponseWriter, r *http.Request) {
 	log.Infof("Successfully wrote new config file.")
 	w.Write([]byte("Success"))
 }
+
+// Sends the settings which can be changed at runtime along with their current values
+func listRuntimeSettings(w http.ResponseWriter, r *http.Request) {
+	runtimeSettings, e := getRuntimeSettings()
+	if e != nil {
+		log.Errorf("Error getting the runtime settings: " + e.Error())
+		w.WriteHeader(http.StatusInternalServerError)
+		w.Write([]byte("Error getting the runtime settings: " + e.Error()))
+		return
+	}
+
+	res, _ := json.Marshal(runtimeSettings)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// Changes the value of a runtime setting, without restarting the agent
+func changeRuntimeSetting(w http.ResponseWriter, r *http.Request) {
+	setting := mux.Vars(r)["setting"]
+	payload, e := parseBody(r)
+	if e != nil {
+		w.Write([]byte(e.Error()))
+		return
+	}
+
+	if e := setRuntimeSetting(setting, payload.Config); e != nil {
+		log.Errorf("Error setting %s: %s", setting, e.Error())
+		w.Write([]byte("Error: " + e.Error()))
+		return
+	}
+
+	log.Infof("Runtime setting %s set to %s from the GUI", setting, payload.Config)
+	w.Write([]byte("Success"))
+}
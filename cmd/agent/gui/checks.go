@@ -41,6 +41,8 @@ func checkHandler(r *mux.Router) {
 	r.HandleFunc("/running", http.HandlerFunc(sendRunningChecks)).Methods("POST")
 	r.HandleFunc("/run/{name}", http.HandlerFunc(runCheck)).Methods("POST")
 	r.HandleFunc("/run/{name}/once", http.HandlerFunc(runCheckOnce)).Methods("POST")
+	r.HandleFunc("/instances/{name}", http.HandlerFunc(listCheckInstances)).Methods("POST")
+	r.HandleFunc("/runInstance/{id}", http.HandlerFunc(runCheckInstanceOnce)).Methods("POST")
 	r.HandleFunc("/reload/{name}", http.HandlerFunc(reloadCheck)).Methods("POST")
 	r.HandleFunc("/getConfig/{fileName}", http.HandlerFunc(getCheckConfigFile)).Methods("POST")
 	r.HandleFunc("/getConfig/{checkFolder}/{fileName}", http.HandlerFunc(getCheckConfigFile)).Methods("POST")
@@ -132,6 +134,42 @@ func runCheckOnce(w http.ResponseWriter, r *http.Request) {
 	w.Write(res)
 }
 
+// Sends the IDs of the instances of a check
+func listCheckInstances(w http.ResponseWriter, r *http.Request) {
+	name := mux.Vars(r)["name"]
+	ids := []string{}
+	for _, ch := range collector.GetChecksByNameForConfigs(name, common.AC.GetAllConfigs()) {
+		ids = append(ids, string(ch.ID()))
+	}
+
+	res, _ := json.Marshal(ids)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
+// Runs a specific check instance once through the agent API, and renders its stats
+func runCheckInstanceOnce(w http.ResponseWriter, r *http.Request) {
+	response := make(map[string]string)
+	id := check.ID(mux.Vars(r)["id"])
+
+	var output string
+	s, e := runCheckInstance(id)
+	if e == nil {
+		output, e = renderCheck(s.CheckName, []*check.Stats{s})
+	}
+	if e != nil {
+		response["success"] = ""
+		response["html"] = html.EscapeString("Error running the check instance " + string(id) + ": " + e.Error())
+	} else {
+		response["success"] = "true"
+		response["html"] = output
+	}
+
+	res, _ := json.Marshal(response)
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(res)
+}
+
 // Reloads a running check
 func reloadCheck(w http.ResponseWriter, r *http.Request) {
 	name := html.EscapeString(mux.Vars(r)["name"])
@@ -1,10 +1,17 @@
 package gui
 
 import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
 	"sort"
 	"testing"
 
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 )
 
 func TestReadConfDir(t *testing.T) {
@@ -39,3 +46,22 @@ func TestConfigsInPath(t *testing.T) {
 
 	assert.Equal(t, expected, files)
 }
+
+func TestRunCheckInstanceOnceError(t *testing.T) {
+	var ids []check.ID
+	defer func(f func(check.ID) (*check.Stats, error)) { runCheckInstance = f }(runCheckInstance)
+	runCheckInstance = func(id check.ID) (*check.Stats, error) {
+		ids = append(ids, id)
+		return nil, errors.New("<script>alert(1)</script> not found")
+	}
+	router := mux.NewRouter()
+	checkHandler(router)
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest("POST", "/runInstance/test%3Cb%3E:1", nil))
+	response := make(map[string]string)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, []check.ID{"test<b>:1"}, ids)
+	assert.Equal(t, "", response["success"])
+	assert.Equal(t, "Error running the check instance test&lt;b&gt;:1: &lt;script&gt;alert(1)&lt;/script&gt; not found", response["html"])
+}
@@ -0,0 +1,125 @@
+package gui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/url"
+
+	"github.com/DataDog/datadog-agent/cmd/agent/app/settings"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// The GUI changes the state of the running agent through the authenticated IPC API,
+// like the agent commands do. It authenticates with the GUI scoped token, which can read the
+// status and change the runtime settings, but can't read the full config or create flares.
+
+// ipcURL returns the URL of an endpoint of the IPC API
+func ipcURL(path string) (string, error) {
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("https://%v:%v%s", ipcAddress, config.Datadog.GetInt("cmd_port"), path), nil
+}
+
+// ipcError returns the error reported by the IPC API in its response body, if any
+func ipcError(body []byte, err error) error {
+	var errMap = make(map[string]string)
+	json.Unmarshal(body, &errMap) //nolint:errcheck
+	if e, found := errMap["error"]; found {
+		return fmt.Errorf(e)
+	}
+	return err
+}
+
+func ipcGet(path string) ([]byte, error) {
+	token, err := security.FetchScopedToken(security.GUITokenName)
+	if err != nil {
+		return nil, err
+	}
+	u, err := ipcURL(path)
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.DoGetWithToken(util.GetIPCClient(), u, token)
+	if err != nil {
+		return nil, ipcError(body, err)
+	}
+	return body, nil
+}
+
+func ipcPost(path string, contentType string, data []byte) ([]byte, error) {
+	token, err := security.FetchScopedToken(security.GUITokenName)
+	if err != nil {
+		return nil, err
+	}
+	u, err := ipcURL(path)
+	if err != nil {
+		return nil, err
+	}
+	body, err := util.DoPostWithToken(util.GetIPCClient(), u, contentType, bytes.NewBuffer(data), token)
+	if err != nil {
+		return nil, ipcError(body, err)
+	}
+	return body, nil
+}
+
+// runtimeSetting is a setting changeable at runtime, along with its current value
+type runtimeSetting struct {
+	Description string      `json:"description"`
+	Value       interface{} `json:"value"`
+}
+
+// getRuntimeSettings returns the visible runtime settings and their values
+func getRuntimeSettings() (map[string]runtimeSetting, error) {
+	body, err := ipcGet("/agent/config/list-runtime")
+	if err != nil {
+		return nil, err
+	}
+	list := make(map[string]settings.RuntimeSettingResponse)
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, err
+	}
+
+	runtimeSettings := make(map[string]runtimeSetting)
+	for name, s := range list {
+		if s.Hidden {
+			continue
+		}
+		body, err := ipcGet("/agent/config/" + url.PathEscape(name))
+		if err != nil {
+			return nil, err
+		}
+		value := make(map[string]interface{})
+		if err := json.Unmarshal(body, &value); err != nil {
+			return nil, err
+		}
+		runtimeSettings[name] = runtimeSetting{Description: s.Description, Value: value["value"]}
+	}
+	return runtimeSettings, nil
+}
+
+// setRuntimeSetting changes the value of a runtime setting
+func setRuntimeSetting(name, value string) error {
+	data := []byte(fmt.Sprintf("value=%s", html.EscapeString(value)))
+	_, err := ipcPost("/agent/config/"+url.PathEscape(name), "application/x-www-form-urlencoded", data)
+	return err
+}
+
+// runCheckInstance runs once the check instance with the given ID and returns its stats
+var runCheckInstance = func(id check.ID) (*check.Stats, error) {
+	body, err := ipcPost("/agent/check/"+url.PathEscape(string(id))+"/run", "application/json", nil)
+	if err != nil {
+		return nil, err
+	}
+	stats := &check.Stats{}
+	if err := json.Unmarshal(body, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
@@ -81,6 +81,7 @@ $(document).ready(function(){
   $("#submit_flare").click(submitFlare);
   $("#log_button").click(loadLog);
   $("#restart_button").click(restartAgent)
+  $("#run_instance_list").click(listCheckInstances);
 
   setupHomePage()
 });
@@ -305,6 +306,48 @@ function submitSettings(editor) {
 }
 
 
+// Fetches the settings which can be changed without restarting the agent, along with their values
+function loadRuntimeSettings() {
+  $(".page").css("display", "none");
+  $("#runtime_settings").css("display", "block");
+
+  sendMessage("agent/runtimeSettings", "", "post",
+  function(data, status, xhr){
+    var table = $('<table class="runtime_settings"></table>');
+    Object.keys(data).sort().forEach(function(name) {
+      var input = $('<input type="text" class="runtime_setting_value"/>').val(data[name].value);
+      var button = $('<input type="button" class="runtime_setting_submit" value="Apply"/>');
+      button.click(function() {
+        submitRuntimeSetting(name, input, button);
+      });
+      table.append($("<tr></tr>").append(
+        $('<td class="runtime_setting_name"></td>').text(name),
+        $("<td></td>").text(data[name].description),
+        $("<td></td>").append(input),
+        $("<td></td>").append(button)
+      ));
+    });
+    $("#runtime_settings").empty().append(table);
+  }, function(){
+    $("#runtime_settings").html("<span class='center'>An error occurred.</span>");
+  });
+}
+
+// Changes the value of a runtime setting, the change doesn't persist across restarts
+function submitRuntimeSetting(name, input, button) {
+  sendMessage("agent/runtimeSettings/" + encodeURIComponent(name), JSON.stringify({config: input.val()}), "post",
+  function(data, status, xhr) {
+    $(".success, .unsuccessful, .msg").remove();
+    if (data == "Success") {
+      button.after('<i class="fa fa-check fa-lg success"></i>');
+    } else {
+      button.after($('<i class="fa fa-times fa-lg unsuccessful"></i>'), $('<div class="msg"></div>').text(data));
+    }
+    $(".success, .unsuccessful, .msg").delay(5000).fadeOut("slow");
+  });
+}
+
+
 /*************************************************************************
                             Manage Checks
 *************************************************************************/
@@ -703,6 +746,49 @@ function seeRunningChecks() {
 }
 
 
+// Display the page to run a specific check instance once
+function loadRunInstance() {
+  $(".page").css("display", "none");
+  $("#run_instance").css("display", "block");
+}
+
+// Lists the instances of the check entered on the 'run a check instance' page
+function listCheckInstances() {
+  var name = $("#run_instance_check").val();
+  if (name == "") return;
+
+  $(".run_instance_output").empty();
+  sendMessage("checks/instances/" + encodeURIComponent(name), "", "post",
+  function(data, status, xhr){
+    if (data.length == 0) {
+      $(".run_instance_list").text("No instance of the check " + name + " is configured.");
+      return;
+    }
+    $(".run_instance_list").empty();
+    data.forEach(function(id) {
+      var button = $('<input type="button" class="run_instance_submit" value="Run once"/>').attr("data-id", id);
+      button.click(function() {
+        runCheckInstance(id);
+      });
+      $(".run_instance_list").append($('<div class="run_instance_item"></div>').text(id + " ").append(button));
+    });
+  }, function() {
+    $(".run_instance_list").text("An error occurred.");
+  });
+}
+
+// Runs a check instance once through the agent and displays its stats
+function runCheckInstance(id) {
+  $(".run_instance_output").html('<i class="fa fa-spinner fa-pulse fa-2x fa-fw"></i>');
+  sendMessage("checks/runInstance/" + encodeURIComponent(id), "", "post",
+  function(data, status, xhr){
+    $(".run_instance_output").html(data.html);
+  }, function() {
+    $(".run_instance_output").html("An error occurred.");
+  });
+}
+
+
 /*************************************************************************
                                 Flare
 *************************************************************************/
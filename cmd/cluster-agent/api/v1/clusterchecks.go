@@ -27,6 +27,7 @@ import (
 func installClusterCheckEndpoints(r *mux.Router, sc clusteragent.ServerContext) {
 	r.HandleFunc("/clusterchecks/status/{nodeName}", postCheckStatus(sc)).Methods("POST")
 	r.HandleFunc("/clusterchecks/configs/{nodeName}", getCheckConfigs(sc)).Methods("GET")
+	r.HandleFunc("/clusterchecks/rebalance", postRebalanceChecks(sc)).Methods("POST")
 	r.HandleFunc("/clusterchecks", getState(sc)).Methods("GET")
 }
 
@@ -114,6 +115,25 @@ func getState(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http
 	}
 }
 
+// postRebalanceChecks is used by the clusterchecks rebalance command
+func postRebalanceChecks(sc clusteragent.ServerContext) func(w http.ResponseWriter, r *http.Request) {
+	if sc.ClusterCheckHandler == nil {
+		return clusterChecksDisabledHandler
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		// No redirection for this one, internal endpoint
+		response, err := sc.ClusterCheckHandler.Rebalance()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			incrementRequestMetric("postRebalanceChecks", http.StatusInternalServerError)
+			return
+		}
+
+		writeJSONResponse(w, response, "postRebalanceChecks")
+	}
+}
+
 // writeJSONResponse serialises and writes data to the response
 func writeJSONResponse(w http.ResponseWriter, data interface{}, handler string) {
 	slcB, err := json.Marshal(data)
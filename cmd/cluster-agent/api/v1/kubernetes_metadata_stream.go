@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	as "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/gorilla/mux"
+)
+
+func installMetadataStreamEndpoint(r *mux.Router) {
+	r.HandleFunc("/metadata/stream", streamMetadata).Methods("GET")
+}
+
+// streamMetadata is used by the followers to replicate the cluster metadata of the leader,
+// see `cluster_agent.metadata_replication.enabled`
+func streamMetadata(w http.ResponseWriter, r *http.Request) {
+	le, err := leaderelection.GetLeaderEngine()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		apiRequests.Inc("streamMetadata", strconv.Itoa(http.StatusInternalServerError))
+		return
+	}
+	if !le.IsLeader() {
+		http.Error(w, "not the leader", http.StatusServiceUnavailable)
+		apiRequests.Inc("streamMetadata", strconv.Itoa(http.StatusServiceUnavailable))
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported by the connection", http.StatusInternalServerError)
+		apiRequests.Inc("streamMetadata", strconv.Itoa(http.StatusInternalServerError))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	apiRequests.Inc("streamMetadata", strconv.Itoa(http.StatusOK))
+
+	log.Infof("Streaming the cluster metadata to the follower %s", r.RemoteAddr)
+	encoder := json.NewEncoder(w)
+	err = as.StreamMetadata(le.IsLeader, r.Context().Done(), func(event as.MetadataReplicationEvent) error {
+		if err := encoder.Encode(event); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	log.Infof("Stopped streaming the cluster metadata to the follower %s: %v", r.RemoteAddr, err)
+}
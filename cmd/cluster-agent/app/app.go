@@ -28,6 +28,7 @@ import (
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/api"
 	"github.com/DataDog/datadog-agent/cmd/cluster-agent/custommetrics"
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent"
 	admissionpkg "github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
@@ -44,6 +45,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/clustername"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -147,14 +149,21 @@ func start(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileClusterService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
 	// Setup healthcheck port
 	var healthPort = config.Datadog.GetInt("health_port")
 	if healthPort > 0 {
-		err := healthprobe.Serve(mainCtx, healthPort)
+		err := healthprobe.Serve(mainCtx, debugport.ClusterAgent, healthPort)
 		if err != nil {
 			return log.Errorf("Error starting health port, exiting: %v", err)
 		}
-		log.Debugf("Health check listening on port %d", healthPort)
 	}
 
 	// get hostname
@@ -193,6 +202,11 @@ func start(cmd *cobra.Command, args []string) error {
 			return err
 		}
 
+		if config.Datadog.GetBool("cluster_agent.metadata_replication.enabled") && config.Datadog.GetBool("leader_election") {
+			// the replication of the cluster metadata follows the leadership
+			le.StartLeaderElectionRun()
+		}
+
 		// Create event recorder
 		eventBroadcaster := record.NewBroadcaster()
 		eventBroadcaster.StartLogging(log.Infof)
@@ -208,6 +222,7 @@ func start(cmd *cobra.Command, args []string) error {
 			DDInformerFactory:  apiCl.DDInformerFactory,
 			Client:             apiCl.Cl,
 			IsLeaderFunc:       le.IsLeader,
+			LeaderIPFunc:       le.GetLeaderIP,
 			EventRecorder:      eventRecorder,
 			StopCh:             stopCh,
 		}
@@ -231,6 +246,7 @@ func start(cmd *cobra.Command, args []string) error {
 		orchestratorCtx := orchestrator.ControllerContext{
 			IsLeaderFunc:                 le.IsLeader,
 			UnassignedPodInformerFactory: apiCl.UnassignedPodInformerFactory,
+			InformerFactory:              apiCl.InformerFactory,
 			Client:                       apiCl.Cl,
 			StopCh:                       stopCh,
 			Hostname:                     hostname,
@@ -310,6 +326,9 @@ func start(cmd *cobra.Command, args []string) error {
 		server := admissioncmd.NewServer()
 		server.Register(config.Datadog.GetString("admission_controller.inject_config.endpoint"), mutate.InjectConfig, apiCl.DynamicCl)
 		server.Register(config.Datadog.GetString("admission_controller.inject_tags.endpoint"), mutate.InjectTags, apiCl.DynamicCl)
+		if config.Datadog.GetBool("admission_controller.auto_instrumentation.enabled") {
+			server.Register(config.Datadog.GetString("admission_controller.auto_instrumentation.endpoint"), mutate.InjectAutoInstrumentation, apiCl.DynamicCl)
+		}
 
 		// Start the k8s admission webhook server
 		wg.Add(1)
@@ -70,7 +70,7 @@ func startCompliance(stopper restart.Stopper, apiCl *apiserver.APIClient) error
 	stopper.Add(auditor)
 
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, nil, endpoints, destinationsCtx)
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, nil, endpoints, destinationsCtx, nil, nil, nil)
 	pipelineProvider.Start()
 	stopper.Add(pipelineProvider)
 
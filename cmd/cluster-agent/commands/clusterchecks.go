@@ -49,5 +49,32 @@ func GetClusterChecksCobraCmd(flagNoColor *bool, confPath *string, loggerName co
 		},
 	}
 
+	rebalanceCmd := &cobra.Command{
+		Use:   "rebalance",
+		Short: "Rebalances cluster checks based on the runners stats",
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			if *flagNoColor {
+				color.NoColor = true
+			}
+
+			// we'll search for a config file named `datadog-cluster.yaml`
+			config.Datadog.SetConfigName("datadog-cluster")
+			err := common.SetupConfig(*confPath)
+			if err != nil {
+				return fmt.Errorf("unable to set up global cluster agent configuration: %v", err)
+			}
+
+			err = config.SetupLogger(loggerName, config.GetEnv("DD_LOG_LEVEL", "off"), "", "", false, true, false)
+			if err != nil {
+				fmt.Printf("Cannot setup logger, exiting: %v\n", err)
+				return err
+			}
+
+			return flare.RebalanceClusterChecks(color.Output)
+		},
+	}
+	clusterChecksCmd.AddCommand(rebalanceCmd)
+
 	return clusterChecksCmd
 }
@@ -11,15 +11,18 @@ import (
 	"context"
 	_ "expvar"
 	"fmt"
+	"net/http"
 	_ "net/http/pprof"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/api/healthprobe"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd"
@@ -28,8 +31,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
@@ -68,6 +73,7 @@ extensions for special Datadog features.`,
 
 	metaScheduler *metadata.Scheduler
 	statsd        *dogstatsd.Server
+	dsdForwarder  *forwarder.DefaultForwarder
 )
 
 const (
@@ -157,15 +163,38 @@ func runAgent(ctx context.Context) (err error) {
 		return
 	}
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileDogstatsdService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
+	// Expose the internal telemetry on the expvar server
+	if config.Datadog.GetBool("telemetry.enabled") {
+		http.Handle("/telemetry", telemetry.Handler())
+	}
+
+	// go_expvar server
+	expvarListener, listenErr := debugport.Listen("127.0.0.1", config.Datadog.GetInt("dogstatsd_stats_port"))
+	if listenErr != nil {
+		log.Errorf("Error starting the expvar server: %v", listenErr)
+	} else {
+		if err := debugport.Advertise(debugport.Dogstatsd, debugport.Expvar, debugport.Port(expvarListener)); err != nil {
+			log.Debugf("Unable to advertise the expvar port: %v", err)
+		}
+		go http.Serve(expvarListener, http.DefaultServeMux) //nolint:errcheck
+	}
+
 	// Setup healthcheck port
 	var healthPort = config.Datadog.GetInt("health_port")
 	if healthPort > 0 {
-		err = healthprobe.Serve(ctx, healthPort)
+		err = healthprobe.Serve(ctx, debugport.Dogstatsd, healthPort)
 		if err != nil {
 			err = log.Errorf("Error starting health port, exiting: %v", err)
 			return
 		}
-		log.Debugf("Health check listening on port %d", healthPort)
 	}
 
 	// setup the forwarder
@@ -173,9 +202,9 @@ func runAgent(ctx context.Context) (err error) {
 	if err != nil {
 		log.Error("Misconfiguration of agent endpoints: ", err)
 	}
-	f := forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
-	f.Start() //nolint:errcheck
-	s := serializer.NewSerializer(f)
+	dsdForwarder = forwarder.NewDefaultForwarder(forwarder.NewOptions(keysPerDomain))
+	dsdForwarder.Start() //nolint:errcheck
+	s := serializer.NewSerializer(dsdForwarder)
 
 	hname, err := util.GetHostname()
 	if err != nil {
@@ -250,15 +279,31 @@ func stopAgent(cancel context.CancelFunc) {
 	// gracefully shut down any component
 	cancel()
 
-	// stop metaScheduler and statsd if they are instantiated
-	if metaScheduler != nil {
-		metaScheduler.Stop()
-	}
-
-	if statsd != nil {
-		statsd.Stop()
+	// stop the components in order, the intake first, so that the data already received
+	// is flushed, and within the stop timeout
+	stopTimeout := config.Datadog.GetDuration("stop_timeout") * time.Second
+	stopped := make(chan struct{})
+	go func() {
+		// stop metaScheduler, statsd and the pipeline if they are instantiated
+		if metaScheduler != nil {
+			metaScheduler.Stop()
+		}
+		if statsd != nil {
+			statsd.Stop()
+		}
+		aggregator.StopDefaultAggregator()
+		if dsdForwarder != nil {
+			dsdForwarder.Stop()
+		}
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(stopTimeout):
+		log.Warnf("Dogstatsd did not stop within %s, exiting now", stopTimeout)
 	}
 
+	debugport.Remove(debugport.Dogstatsd)
 	log.Info("See ya!")
 	log.Flush()
 	return
@@ -8,12 +8,9 @@
 package main
 
 import (
-	"fmt"
-	"net/http"
 	_ "net/http/pprof"
 	"os"
 
-	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -23,11 +20,6 @@ const defaultLogFile = "/var/log/datadog/dogstatsd.log"
 func main() {
 	flavor.SetFlavor(flavor.Dogstatsd)
 
-	// go_expvar server
-	go http.ListenAndServe( //nolint:errcheck
-		fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_stats_port")),
-		http.DefaultServeMux)
-
 	if err := dogstatsdCmd.Execute(); err != nil {
 		log.Error(err)
 		os.Exit(-1)
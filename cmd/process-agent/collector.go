@@ -46,6 +46,10 @@ type checkPayload struct {
 
 // Collector will collect metrics from the local system and ship to the backend.
 type Collector struct {
+	// End of the local real-time session in nanoseconds since epoch, 0 if there is none.
+	// It comes first to be 64-bit aligned for the atomic accesses.
+	realTimeSessionEnd int64
+
 	// Set to 1 if enabled 0 is not. We're using an integer
 	// so we can use the sync/atomic for thread-safe access.
 	realTimeEnabled int32
@@ -257,8 +261,7 @@ func (l *Collector) run(exit chan struct{}) error {
 			for {
 				select {
 				case <-ticker.C:
-					realTimeEnabled := atomic.LoadInt32(&l.realTimeEnabled) == 1
-					if !c.RealTime() || realTimeEnabled {
+					if !c.RealTime() || l.realTimeActive() {
 						l.runCheck(c, results)
 					}
 				case d := <-l.rtIntervalCh:
@@ -16,7 +16,9 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 )
 
 const loggerName ddconfig.LoggerName = "PROCESS"
@@ -108,6 +110,14 @@ func runAgent(exit chan struct{}) {
 
 	log.Infof("running version: %s", versionString(", "))
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileProcessService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
 	// Tagger must be initialized after agent config has been setup
 	tagger.Init()
 	defer tagger.Stop() //nolint:errcheck
@@ -163,6 +173,11 @@ func runAgent(exit chan struct{}) {
 		return
 	}
 
+	// Expose the internal telemetry on the profile server
+	if ddconfig.Datadog.GetBool("telemetry.enabled") {
+		http.Handle("/telemetry", telemetry.Handler())
+	}
+
 	// Run a profile server.
 	go func() {
 		http.ListenAndServe(fmt.Sprintf("localhost:%d", cfg.ProcessExpVarPort), nil) //nolint:errcheck
@@ -174,6 +189,9 @@ func runAgent(exit chan struct{}) {
 		cleanupAndExit(1)
 		return
 	}
+	// Let the real-time mode be switched on locally, for a limited time
+	http.HandleFunc("/realtime", cl.handleRealTime)
+
 	if err := cl.run(exit); err != nil {
 		log.Criticalf("Error starting collector: %s", err)
 		os.Exit(1)
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// defaultRealTimeSessionDuration is the duration of the local real-time sessions started
+	// without a duration
+	defaultRealTimeSessionDuration = 5 * time.Minute
+	// maxRealTimeSessionDuration caps the duration of the local real-time sessions, so that a
+	// forgotten session doesn't keep the 2s collection running
+	maxRealTimeSessionDuration = 30 * time.Minute
+)
+
+// realTimeStatus is the response of the /realtime endpoint
+type realTimeStatus struct {
+	// Enabled reports whether the real-time checks run, for a backend client or a local session
+	Enabled bool `json:"enabled"`
+	// SessionEnd is the end of the local session, if any
+	SessionEnd *time.Time `json:"session_end,omitempty"`
+}
+
+// realTimeActive reports whether the real-time checks should run, either because the backend
+// has active clients or because a local session is in progress.
+func (l *Collector) realTimeActive() bool {
+	return atomic.LoadInt32(&l.realTimeEnabled) == 1 || time.Now().UnixNano() < atomic.LoadInt64(&l.realTimeSessionEnd)
+}
+
+// startRealTimeSession switches the real-time checks on for the given duration, after which
+// they revert to being driven by the backend. It returns the end of the session.
+func (l *Collector) startRealTimeSession(d time.Duration) time.Time {
+	if d <= 0 {
+		d = defaultRealTimeSessionDuration
+	}
+	if d > maxRealTimeSessionDuration {
+		d = maxRealTimeSessionDuration
+	}
+	end := time.Now().Add(d)
+	atomic.StoreInt64(&l.realTimeSessionEnd, end.UnixNano())
+	log.Infof("Enabling real-time mode for a local session of %s", d)
+	return end
+}
+
+// stopRealTimeSession ends the local real-time session, if any.
+func (l *Collector) stopRealTimeSession() {
+	if atomic.SwapInt64(&l.realTimeSessionEnd, 0) > time.Now().UnixNano() {
+		log.Info("Local real-time session stopped")
+	}
+}
+
+// handleRealTime starts (POST, with an optional duration parameter such as "10m"), stops
+// (DELETE) or reports (GET) the local real-time session.
+func (l *Collector) handleRealTime(w http.ResponseWriter, req *http.Request) {
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		if !l.cfg.AllowRealTime {
+			http.Error(w, "real-time mode is disabled by the configuration", http.StatusForbidden)
+			return
+		}
+		var d time.Duration
+		if v := req.URL.Query().Get("duration"); v != "" {
+			var err error
+			if d, err = time.ParseDuration(v); err != nil {
+				http.Error(w, fmt.Sprintf("invalid duration %q: %s", v, err), http.StatusBadRequest)
+				return
+			}
+		}
+		l.startRealTimeSession(d)
+	case http.MethodDelete:
+		l.stopRealTimeSession()
+	default:
+		w.Header().Set("Allow", "GET, POST, DELETE")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	status := realTimeStatus{Enabled: l.realTimeActive()}
+	if end := atomic.LoadInt64(&l.realTimeSessionEnd); end > time.Now().UnixNano() {
+		t := time.Unix(0, end)
+		status.SessionEnd = &t
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status) //nolint:errcheck
+}
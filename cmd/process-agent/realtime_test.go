@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealTimeSession(t *testing.T) {
+	assert := assert.New(t)
+	c := NewCollectorWithChecks(config.NewDefaultAgentConfig(false), nil)
+	assert.False(c.realTimeActive())
+
+	end := c.startRealTimeSession(time.Hour)
+	assert.True(c.realTimeActive())
+	assert.WithinDuration(time.Now().Add(maxRealTimeSessionDuration), end, time.Minute)
+
+	c.stopRealTimeSession()
+	assert.False(c.realTimeActive())
+
+	// the session reverts by itself once it's over
+	atomic.StoreInt64(&c.realTimeSessionEnd, time.Now().Add(-time.Second).UnixNano())
+	assert.False(c.realTimeActive())
+
+	// the backend clients still enable the real-time mode without a session
+	atomic.StoreInt32(&c.realTimeEnabled, 1)
+	assert.True(c.realTimeActive())
+}
+
+func TestHandleRealTime(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig(false)
+	c := NewCollectorWithChecks(cfg, nil)
+
+	do := func(method, url string) (int, realTimeStatus) {
+		rr := httptest.NewRecorder()
+		c.handleRealTime(rr, httptest.NewRequest(method, url, nil))
+		var status realTimeStatus
+		if rr.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rr.Body).Decode(&status))
+		}
+		return rr.Code, status
+	}
+
+	code, status := do("GET", "/realtime")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, status.Enabled)
+	assert.Nil(t, status.SessionEnd)
+
+	code, status = do("POST", "/realtime?duration=10m")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, status.Enabled)
+	if assert.NotNil(t, status.SessionEnd) {
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), *status.SessionEnd, time.Minute)
+	}
+
+	code, status = do("DELETE", "/realtime")
+	assert.Equal(t, http.StatusOK, code)
+	assert.False(t, status.Enabled)
+
+	code, _ = do("POST", "/realtime?duration=soon")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	code, _ = do("PUT", "/realtime")
+	assert.Equal(t, http.StatusMethodNotAllowed, code)
+
+	cfg.AllowRealTime = false
+	code, _ = do("POST", "/realtime")
+	assert.Equal(t, http.StatusForbidden, code)
+	assert.False(t, c.realTimeActive())
+}
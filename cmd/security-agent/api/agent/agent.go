@@ -10,6 +10,7 @@ package agent
 
 import (
 	"encoding/json"
+	"expvar"
 	"net/http"
 
 	"github.com/gorilla/mux"
@@ -72,6 +73,13 @@ func getStatus(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// results of the compliance rules, published by the compliance agent
+	if complianceVar := expvar.Get("compliance"); complianceVar != nil {
+		complianceStatus := make(map[string]interface{})
+		json.Unmarshal([]byte(complianceVar.String()), &complianceStatus) //nolint:errcheck
+		s["complianceStatus"] = complianceStatus
+	}
+
 	jsonStats, err := json.Marshal(s)
 	if err != nil {
 		log.Errorf("Error marshalling status. Error: %v, Status: %v", err, s)
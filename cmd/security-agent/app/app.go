@@ -23,6 +23,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
 	coreconfig "github.com/DataDog/datadog-agent/pkg/config"
@@ -145,6 +146,14 @@ func start(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileSecurityService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
 	// get hostname
 	hostname, err := util.GetHostname()
 	if err != nil {
@@ -221,7 +230,7 @@ func newComplianceReporter(stopper restart.Stopper, sourceName, sourceType strin
 	stopper.Add(auditor)
 
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, nil, endpoints, destinationsCtx)
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, nil, endpoints, destinationsCtx, nil, nil, nil)
 	pipelineProvider.Start()
 	stopper.Add(pipelineProvider)
 
@@ -21,6 +21,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/net"
 	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 )
 
 // All System Probe modules should register their factories here
@@ -28,6 +29,7 @@ var factories = []api.Factory{
 	modules.NetworkTracer,
 	modules.TCPQueueLength,
 	modules.OOMKillProbe,
+	modules.Traceroute,
 }
 
 // Flag values
@@ -86,6 +88,14 @@ func runAgent(exit <-chan struct{}) {
 
 	log.Infof("running system-probe with version: %s", versionString(", "))
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileSystemProbeService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
 	// configure statsd
 	if err := statsd.Configure(cfg); err != nil {
 		log.Criticalf("Error configuring statsd: %s", err)
@@ -76,6 +76,28 @@ func (nt *networkTracer) Register(httpMux *http.ServeMux) error {
 		logRequests(id, count, len(cs.Conns), start)
 	})
 
+	// returns the requests stats aggregated per service since the last call
+	httpMux.HandleFunc("/services", func(w http.ResponseWriter, req *http.Request) {
+		services, err := nt.tracer.GetServiceStats()
+		if err != nil {
+			log.Errorf("unable to retrieve service stats: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+		utils.WriteAsJSON(w, services)
+	})
+
+	// returns the DNS stats of the connections returned to the client since the last call, by process and domain
+	httpMux.HandleFunc("/dns/domains", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.GetDNSDomainStats(getClientID(req))
+		if err != nil {
+			log.Errorf("unable to retrieve DNS domain stats: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+		utils.WriteAsJSON(w, stats)
+	})
+
 	httpMux.HandleFunc("/debug/net_maps", func(w http.ResponseWriter, req *http.Request) {
 		cs, err := nt.tracer.DebugNetworkMaps()
 		if err != nil {
@@ -100,6 +122,39 @@ func (nt *networkTracer) Register(httpMux *http.ServeMux) error {
 		utils.WriteAsJSON(w, stats)
 	})
 
+	httpMux.HandleFunc("/debug/conntrack", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.DebugConntrack()
+		if err != nil {
+			log.Errorf("unable to retrieve conntrack state: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, stats)
+	})
+
+	httpMux.HandleFunc("/debug/ebpf_maps", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.DebugEBPFMaps()
+		if err != nil {
+			log.Errorf("unable to retrieve eBPF maps stats: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, stats)
+	})
+
+	httpMux.HandleFunc("/debug/kernel_telemetry", func(w http.ResponseWriter, req *http.Request) {
+		stats, err := nt.tracer.DebugKernelTelemetry()
+		if err != nil {
+			log.Errorf("unable to retrieve kernel telemetry: %s", err)
+			w.WriteHeader(500)
+			return
+		}
+
+		utils.WriteAsJSON(w, stats)
+	})
+
 	// Convenience logging if nothing has made any requests to the system-probe in some time, let's log something.
 	// This should be helpful for customers + support to debug the underlying issue.
 	time.AfterFunc(inactivityLogDuration, func() {
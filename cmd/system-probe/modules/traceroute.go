@@ -0,0 +1,86 @@
+package modules
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/cmd/system-probe/api"
+	"github.com/DataDog/datadog-agent/cmd/system-probe/utils"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Traceroute Factory
+var Traceroute = api.Factory{
+	Name: "traceroute",
+	Fn: func(cfg *config.AgentConfig) (api.Module, error) {
+		if !cfg.CheckIsEnabled("traceroute") {
+			log.Info("Traceroute module disabled")
+			return nil, api.ErrNotEnabled
+		}
+		if !traceroute.Supported {
+			log.Warn("Traceroute module not started: ", traceroute.ErrNotImplemented)
+			return nil, api.ErrNotEnabled
+		}
+
+		m := traceroute.NewMonitor(cfg.TracerouteDestinations, cfg.TracerouteInterval, traceroute.Config{
+			MaxTTL:  cfg.TracerouteMaxTTL,
+			Timeout: cfg.TracerouteTimeout,
+		})
+		m.Start()
+		return &tracerouteModule{m}, nil
+	},
+}
+
+var _ api.Module = &tracerouteModule{}
+
+type tracerouteModule struct {
+	*traceroute.Monitor
+}
+
+func (t *tracerouteModule) Register(httpMux *http.ServeMux) error {
+	// returns the paths traced since the last call
+	httpMux.HandleFunc("/traceroute/paths", func(w http.ResponseWriter, req *http.Request) {
+		utils.WriteAsJSON(w, t.Monitor.GetAndFlush())
+	})
+
+	// runs a traceroute on demand
+	httpMux.HandleFunc("/traceroute", func(w http.ResponseWriter, req *http.Request) {
+		query := req.URL.Query()
+		dest := traceroute.Destination{
+			Host:     query.Get("host"),
+			Protocol: query.Get("protocol"),
+		}
+		if port := query.Get("port"); port != "" {
+			p, err := strconv.Atoi(port)
+			if err != nil {
+				http.Error(w, "invalid port "+port, http.StatusBadRequest)
+				return
+			}
+			dest.Port = p
+		}
+		if err := dest.Normalize(); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		path, err := t.Monitor.Trace(dest)
+		if err != nil {
+			log.Errorf("unable to run the traceroute to %s: %s", dest, err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		utils.WriteAsJSON(w, path)
+	})
+
+	return nil
+}
+
+func (t *tracerouteModule) GetStats() map[string]interface{} {
+	return nil
+}
+
+func (t *tracerouteModule) Close() {
+	t.Monitor.Stop()
+}
@@ -52,7 +52,7 @@ func doConfigure() error {
 	}
 
 	// Get the CSRF token from the agent
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return err
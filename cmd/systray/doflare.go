@@ -178,7 +178,7 @@ func requestFlare(caseID, customerEmail string) (response string, e error) {
 	if e != nil {
 		return
 	}
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 	ipcAddress, err := config.GetIPCAddress()
 	if err != nil {
 		return "", err
@@ -14,12 +14,16 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/DataDog/datadog-agent/pkg/version"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -76,8 +80,9 @@ func expStatsMap(statsMap map[string]*Stats) func() interface{} {
 	}
 }
 
+// timeNowNano returns the time of the clock provider, which never goes back
 func timeNowNano() float64 {
-	return float64(time.Now().UnixNano()) / float64(time.Second) // Unix time with nanosecond precision
+	return float64(clock.Now().UnixNano()) / float64(time.Second) // Unix time with nanosecond precision
 }
 
 var (
@@ -103,6 +108,10 @@ var (
 	aggregatorServiceCheck                     = expvar.Int{}
 	aggregatorEvent                            = expvar.Int{}
 	aggregatorHostnameUpdate                   = expvar.Int{}
+	aggregatorEventPlatformEvents              = expvar.Map{}
+	aggregatorEventPlatformEventsErrors        = expvar.Map{}
+	aggregatorSeriesPointsExcluded             = expvar.Int{}
+	aggregatorSketchesPointsExcluded           = expvar.Int{}
 
 	tlmFlush = telemetry.NewCounter("aggregator", "flush",
 		[]string{"data_type", "state"}, "Count of flush")
@@ -110,6 +119,8 @@ var (
 		[]string{"data_type"}, "Amount of metrics/services_checks/events processed by the aggregator")
 	tlmHostnameUpdate = telemetry.NewCounter("aggregator", "hostname_update",
 		nil, "Count of hostname update")
+	tlmPointsExcluded = telemetry.NewCounter("aggregator", "points_excluded",
+		[]string{"data_type"}, "Count of points dropped by the metrics_exclude rules")
 
 	// Hold series to be added to aggregated series on each flush
 	recurrentSeries     metrics.Series
@@ -145,6 +156,10 @@ func init() {
 	aggregatorExpvars.Set("ServiceCheck", &aggregatorServiceCheck)
 	aggregatorExpvars.Set("Event", &aggregatorEvent)
 	aggregatorExpvars.Set("HostnameUpdate", &aggregatorHostnameUpdate)
+	aggregatorExpvars.Set("EventPlatformEvents", &aggregatorEventPlatformEvents)
+	aggregatorExpvars.Set("EventPlatformEventsErrors", &aggregatorEventPlatformEventsErrors)
+	aggregatorExpvars.Set("SeriesPointsExcluded", &aggregatorSeriesPointsExcluded)
+	aggregatorExpvars.Set("SketchesPointsExcluded", &aggregatorSketchesPointsExcluded)
 }
 
 // InitAggregator returns the Singleton instance
@@ -190,6 +205,11 @@ type BufferedAggregator struct {
 
 	checkMetricIn          chan senderMetricSample
 	checkHistogramBucketIn chan senderHistogramBucket
+	eventPlatformIn        chan senderEventPlatformEvent
+
+	// eventPlatformForwarder forwards the event platform events of the checks, they are dropped when it's nil
+	eventPlatformForwarder      epforwarder.EventPlatformForwarder
+	eventPlatformForwarderMutex sync.RWMutex
 
 	// metricSamplePool is a pool of slices of metric sample to avoid allocations.
 	// Used by the Dogstatsd Batcher.
@@ -197,6 +217,7 @@ type BufferedAggregator struct {
 
 	statsdSampler      TimeSampler
 	checkSamplers      map[check.ID]*CheckSampler
+	metricFilter       *metricFilter // drops the series and sketches matching metrics_exclude, nil when not set
 	serviceChecks      metrics.ServiceChecks
 	events             metrics.Events
 	flushInterval      time.Duration
@@ -232,11 +253,13 @@ func NewBufferedAggregator(s serializer.MetricSerializer, hostname string, flush
 
 		checkMetricIn:          make(chan senderMetricSample, bufferSize),
 		checkHistogramBucketIn: make(chan senderHistogramBucket, bufferSize),
+		eventPlatformIn:        make(chan senderEventPlatformEvent, bufferSize),
 
 		MetricSamplePool: metrics.NewMetricSamplePool(MetricSamplePoolBatchSize),
 
 		statsdSampler:      *NewTimeSampler(bucketSize),
 		checkSamplers:      make(map[check.ID]*CheckSampler),
+		metricFilter:       newMetricFilterFromConfig(),
 		flushInterval:      flushInterval,
 		serializer:         s,
 		hostname:           hostname,
@@ -260,7 +283,7 @@ func AddRecurrentSeries(newSerie *metrics.Serie) {
 // IsInputQueueEmpty returns true if every input channel for the aggregator are
 // empty. This is mainly useful for tests and benchmark
 func (agg *BufferedAggregator) IsInputQueueEmpty() bool {
-	if len(agg.checkMetricIn)+len(agg.serviceCheckIn)+len(agg.eventIn)+len(agg.checkHistogramBucketIn) == 0 {
+	if len(agg.checkMetricIn)+len(agg.serviceCheckIn)+len(agg.eventIn)+len(agg.checkHistogramBucketIn)+len(agg.eventPlatformIn) == 0 {
 		return true
 	}
 	return false
@@ -276,6 +299,13 @@ func (agg *BufferedAggregator) GetBufferedChannels() (chan []metrics.MetricSampl
 	return agg.bufferedMetricIn, agg.bufferedEventIn, agg.bufferedServiceCheckIn
 }
 
+// SetEventPlatformForwarder sets the forwarder of the event platform events submitted by the checks
+func (agg *BufferedAggregator) SetEventPlatformForwarder(forwarder epforwarder.EventPlatformForwarder) {
+	agg.eventPlatformForwarderMutex.Lock()
+	defer agg.eventPlatformForwarderMutex.Unlock()
+	agg.eventPlatformForwarder = forwarder
+}
+
 // SetHostname sets the hostname that the aggregator uses by default on all the data it sends
 // Blocks until the main aggregator goroutine has finished handling the update
 func (agg *BufferedAggregator) SetHostname(hostname string) {
@@ -333,6 +363,7 @@ func (agg *BufferedAggregator) handleSenderSample(ss senderMetricSample) {
 		} else {
 			ss.metricSample.Tags = util.SortUniqInPlace(ss.metricSample.Tags)
 			checkSampler.addSample(ss.metricSample)
+			metrictracer.Record(metrictracer.StageAggregator, ss.metricSample.Name, 1)
 		}
 	} else {
 		log.Debugf("CheckSampler with ID '%s' doesn't exist, can't handle senderMetricSample", ss.id)
@@ -351,10 +382,27 @@ func (agg *BufferedAggregator) handleSenderBucket(checkBucket senderHistogramBuc
 	}
 }
 
+// handleEventPlatformEvent forwards an event platform event submitted by a check as it is
+func (agg *BufferedAggregator) handleEventPlatformEvent(event senderEventPlatformEvent) {
+	agg.eventPlatformForwarderMutex.RLock()
+	forwarder := agg.eventPlatformForwarder
+	agg.eventPlatformForwarderMutex.RUnlock()
+
+	if forwarder == nil {
+		log.Debugf("No event platform forwarder, dropping the %s event of the check %s", event.eventType, event.id)
+		return
+	}
+	err := forwarder.SendEventPlatformEvent(message.NewMessage([]byte(event.rawEvent), nil, ""), event.eventType)
+	if err != nil {
+		aggregatorEventPlatformEventsErrors.Add(event.eventType, 1)
+		log.Debugf("Error submitting the %s event of the check %s: %v", event.eventType, event.id, err)
+	}
+}
+
 // addServiceCheck adds the service check to the slice of current service checks
 func (agg *BufferedAggregator) addServiceCheck(sc metrics.ServiceCheck) {
 	if sc.Ts == 0 {
-		sc.Ts = time.Now().Unix()
+		sc.Ts = clock.Now().Unix()
 	}
 	sc.Tags = util.SortUniqInPlace(sc.Tags)
 
@@ -364,7 +412,7 @@ func (agg *BufferedAggregator) addServiceCheck(sc metrics.ServiceCheck) {
 // addEvent adds the event to the slice of current events
 func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 	if e.Ts == 0 {
-		e.Ts = time.Now().Unix()
+		e.Ts = clock.Now().Unix()
 	}
 	e.Tags = util.SortUniqInPlace(e.Tags)
 
@@ -375,12 +423,19 @@ func (agg *BufferedAggregator) addEvent(e metrics.Event) {
 func (agg *BufferedAggregator) addSample(metricSample *metrics.MetricSample, timestamp float64) {
 	metricSample.Tags = util.SortUniqInPlace(metricSample.Tags)
 	agg.statsdSampler.addSample(metricSample, timestamp)
+	metrictracer.Record(metrictracer.StageAggregator, metricSample.Name, 1)
 }
 
 // GetSeriesAndSketches grabs all the series & sketches from the queue and clears the queue
 func (agg *BufferedAggregator) GetSeriesAndSketches() (metrics.Series, metrics.SketchSeriesList) {
+	return agg.getSeriesAndSketches(timeNowNano())
+}
+
+// getSeriesAndSketches grabs the series & sketches of the checks and of the dogstatsd buckets
+// closed at the given timestamp, and clears them
+func (agg *BufferedAggregator) getSeriesAndSketches(timestamp float64) (metrics.Series, metrics.SketchSeriesList) {
 	agg.mu.Lock()
-	series, sketches := agg.statsdSampler.flush(timeNowNano())
+	series, sketches := agg.statsdSampler.flush(timestamp)
 
 	for _, checkSampler := range agg.checkSamplers {
 		s, sk := checkSampler.flush()
@@ -400,6 +455,7 @@ func (agg *BufferedAggregator) pushSketches(start time.Time, sketches metrics.Sk
 		aggregatorSketchesFlushErrors.Add(1)
 		state = stateError
 	}
+	traceSketches(serializerStage(err), sketches)
 	addFlushTime("MetricSketchFlushTime", int64(time.Since(start)))
 	aggregatorSketchesFlushed.Add(int64(len(sketches)))
 	tlmFlush.Add(float64(len(sketches)), "sketches", state)
@@ -414,11 +470,39 @@ func (agg *BufferedAggregator) pushSeries(start time.Time, series metrics.Series
 		aggregatorSeriesFlushErrors.Add(1)
 		state = stateError
 	}
+	traceSeries(serializerStage(err), series)
 	addFlushTime("ChecksMetricSampleFlushTime", int64(time.Since(start)))
 	aggregatorSeriesFlushed.Add(int64(len(series)))
 	tlmFlush.Add(float64(len(series)), "series", state)
 }
 
+// traceSeries records the traced series at the given stage, see `agent diagnose metric`
+func traceSeries(stage metrictracer.Stage, series metrics.Series) {
+	if !metrictracer.Active() {
+		return
+	}
+	for _, serie := range series {
+		metrictracer.Record(stage, serie.Name, 1)
+	}
+}
+
+// traceSketches records the traced sketches at the given stage, see `agent diagnose metric`
+func traceSketches(stage metrictracer.Stage, sketches metrics.SketchSeriesList) {
+	if !metrictracer.Active() {
+		return
+	}
+	for _, sketch := range sketches {
+		metrictracer.Record(stage, sketch.Name, 1)
+	}
+}
+
+func serializerStage(err error) metrictracer.Stage {
+	if err != nil {
+		return metrictracer.StageSerializerError
+	}
+	return metrictracer.StageSerialized
+}
+
 func (agg *BufferedAggregator) sendSeries(start time.Time, series metrics.Series, waitForSerializer bool) {
 	recurrentSeriesLock.Lock()
 	// Adding recurrentSeries to the flushed ones
@@ -503,6 +587,18 @@ func (agg *BufferedAggregator) sendSketches(start time.Time, sketches metrics.Sk
 
 func (agg *BufferedAggregator) flushSeriesAndSketches(start time.Time, waitForSerializer bool) {
 	series, sketches := agg.GetSeriesAndSketches()
+	traceSeries(metrictracer.StageFlushed, series)
+	traceSketches(metrictracer.StageFlushed, sketches)
+
+	if agg.metricFilter != nil {
+		var seriesPoints, sketchesPoints int
+		series, seriesPoints = agg.metricFilter.filterSeries(series)
+		sketches, sketchesPoints = agg.metricFilter.filterSketches(sketches)
+		aggregatorSeriesPointsExcluded.Add(int64(seriesPoints))
+		aggregatorSketchesPointsExcluded.Add(int64(sketchesPoints))
+		tlmPointsExcluded.Add(float64(seriesPoints), "series")
+		tlmPointsExcluded.Add(float64(sketchesPoints), "sketches")
+	}
 
 	agg.sendSketches(start, sketches, waitForSerializer)
 	agg.sendSeries(start, series, waitForSerializer)
@@ -605,13 +701,42 @@ func (agg *BufferedAggregator) flushEvents(start time.Time, waitForSerializer bo
 }
 
 func (agg *BufferedAggregator) flush(start time.Time, waitForSerializer bool) {
+	agg.addClockJumpEvents()
 	agg.flushSeriesAndSketches(start, waitForSerializer)
 	agg.flushServiceChecks(start, waitForSerializer)
 	agg.flushEvents(start, waitForSerializer)
 }
 
+// addClockJumpEvents reports the backward jumps of the system clock detected since the last
+// flush as agent events
+func (agg *BufferedAggregator) addClockJumpEvents() {
+	for _, jump := range clock.Default().TakeJumps() {
+		log.Warnf("The system clock went back by %s, the metrics timestamps are smoothed until it catches up", jump.Duration)
+		agg.addEvent(metrics.Event{
+			Title:          "Agent Clock Jump",
+			Text:           fmt.Sprintf("The system clock went back by %s, the metrics timestamps are smoothed until it catches up", jump.Duration),
+			Ts:             jump.Time.Unix(),
+			Host:           agg.hostname,
+			AlertType:      metrics.EventAlertTypeWarning,
+			SourceTypeName: "System",
+			EventType:      "Agent Clock Jump",
+		})
+	}
+}
+
+// flushAll flushes all the data of the aggregator, including the dogstatsd buckets that
+// are still open, and waits for the serializer. It's used when stopping the aggregator so
+// that the last seconds of data aren't lost.
+func (agg *BufferedAggregator) flushAll(start time.Time) {
+	series, sketches := agg.getSeriesAndSketches(timeNowNano() + bucketSize)
+	agg.sendSketches(start, sketches, true)
+	agg.sendSeries(start, series, true)
+	agg.flushServiceChecks(start, true)
+	agg.flushEvents(start, true)
+}
+
 // Stop stops the aggregator. Based on 'flushData' waiting metrics (from checks
-// or closed dogstatsd buckets) will be sent to the serializer before stopping.
+// or dogstatsd buckets) will be sent to the serializer before stopping.
 func (agg *BufferedAggregator) Stop() {
 	agg.stopChan <- struct{}{}
 
@@ -619,7 +744,7 @@ func (agg *BufferedAggregator) Stop() {
 	if timeout > 0 {
 		done := make(chan struct{})
 		go func() {
-			agg.flush(time.Now(), true)
+			agg.flushAll(time.Now())
 			done <- struct{}{}
 		}()
 
@@ -645,6 +770,7 @@ func (agg *BufferedAggregator) run() {
 		select {
 		case <-agg.stopChan:
 			log.Info("Stopping aggregator")
+			agg.drainInputs()
 			return
 		case <-agg.health.C:
 		case <-agg.TickerChan:
@@ -660,6 +786,10 @@ func (agg *BufferedAggregator) run() {
 			aggregatorCheckHistogramBucketMetricSample.Add(1)
 			tlmProcessed.Inc("histogram_bucket")
 			agg.handleSenderBucket(checkHistogramBucket)
+		case event := <-agg.eventPlatformIn:
+			aggregatorEventPlatformEvents.Add(event.eventType, 1)
+			tlmProcessed.Inc("event_platform_events")
+			agg.handleEventPlatformEvent(event)
 		case metric := <-agg.metricIn:
 			aggregatorDogstatsdMetricSample.Add(1)
 			tlmProcessed.Inc("dogstatsd_metrics")
@@ -700,3 +830,39 @@ func (agg *BufferedAggregator) run() {
 		}
 	}
 }
+
+// drainInputs processes the data already queued in the input channels of the aggregator,
+// without waiting for more, so that it's part of the last flush
+func (agg *BufferedAggregator) drainInputs() {
+	for {
+		select {
+		case checkMetric := <-agg.checkMetricIn:
+			agg.handleSenderSample(checkMetric)
+		case checkHistogramBucket := <-agg.checkHistogramBucketIn:
+			agg.handleSenderBucket(checkHistogramBucket)
+		case event := <-agg.eventPlatformIn:
+			agg.handleEventPlatformEvent(event)
+		case metric := <-agg.metricIn:
+			agg.addSample(metric, timeNowNano())
+		case event := <-agg.eventIn:
+			agg.addEvent(event)
+		case serviceCheck := <-agg.serviceCheckIn:
+			agg.addServiceCheck(serviceCheck)
+		case ms := <-agg.bufferedMetricIn:
+			for i := 0; i < len(ms); i++ {
+				agg.addSample(&ms[i], timeNowNano())
+			}
+			agg.MetricSamplePool.PutBatch(ms)
+		case serviceChecks := <-agg.bufferedServiceCheckIn:
+			for _, serviceCheck := range serviceChecks {
+				agg.addServiceCheck(*serviceCheck)
+			}
+		case events := <-agg.bufferedEventIn:
+			for _, event := range events {
+				agg.addEvent(*event)
+			}
+		default:
+			return
+		}
+	}
+}
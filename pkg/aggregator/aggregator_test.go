@@ -257,3 +257,25 @@ func TestRecurentSeries(t *testing.T) {
 	s.AssertNotCalled(t, "SendSketch")
 
 }
+
+func TestGetSeriesAndSketchesOpenBuckets(t *testing.T) {
+	resetAggregator()
+	agg := NewBufferedAggregator(nil, "hostname", DefaultFlushInterval)
+
+	agg.addSample(&metrics.MetricSample{
+		Name:       "my.metric.name",
+		Value:      1,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"foo", "bar"},
+		SampleRate: 1,
+	}, timeNowNano())
+
+	// the bucket of the sample is still open
+	series, _ := agg.GetSeriesAndSketches()
+	assert.Len(t, series, 0)
+
+	// it's flushed when stopping the aggregator
+	series, _ = agg.getSeriesAndSketches(timeNowNano() + bucketSize)
+	require.Len(t, series, 1)
+	assert.Equal(t, "my.metric.name", series[0].Name)
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// MetricExcludeRule is a rule of the metrics_exclude option, the series and sketches whose
+// name matches Name and which have a tag matching each of Tags are dropped. Name and Tags
+// are glob patterns, where * matches any characters and ? a single one.
+type MetricExcludeRule struct {
+	Name string   `mapstructure:"name"`
+	Tags []string `mapstructure:"tags"`
+}
+
+// metricExcludeMatcher is the compiled version of a MetricExcludeRule
+type metricExcludeMatcher struct {
+	name *regexp.Regexp
+	tags []*regexp.Regexp
+}
+
+// metricFilter drops the series and sketches matching the metrics_exclude rules after the
+// aggregation, so that the known noisy series are not sent
+type metricFilter struct {
+	matchers []metricExcludeMatcher
+}
+
+// globToRegexp compiles a glob pattern, where * matches any characters, including the
+// dots of the metric names and the slashes of the tag values, and ? a single one
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// newMetricFilter compiles the exclude rules, it returns nil when there is no rule
+func newMetricFilter(rules []MetricExcludeRule) (*metricFilter, error) {
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	filter := &metricFilter{}
+	for _, rule := range rules {
+		if rule.Name == "" {
+			return nil, fmt.Errorf("metrics_exclude rule without name: %v", rule)
+		}
+		name, err := globToRegexp(rule.Name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid metrics_exclude name %q: %s", rule.Name, err)
+		}
+		matcher := metricExcludeMatcher{name: name}
+		for _, tag := range rule.Tags {
+			tagRegexp, err := globToRegexp(tag)
+			if err != nil {
+				return nil, fmt.Errorf("invalid metrics_exclude tag %q: %s", tag, err)
+			}
+			matcher.tags = append(matcher.tags, tagRegexp)
+		}
+		filter.matchers = append(filter.matchers, matcher)
+	}
+	return filter, nil
+}
+
+// newMetricFilterFromConfig returns the filter of the metrics_exclude option, nil when it's not
+// set or invalid
+func newMetricFilterFromConfig() *metricFilter {
+	var rules []MetricExcludeRule
+	if err := config.Datadog.UnmarshalKey("metrics_exclude", &rules); err != nil {
+		log.Errorf("Invalid metrics_exclude option, no metric will be excluded: %s", err)
+		return nil
+	}
+	filter, err := newMetricFilter(rules)
+	if err != nil {
+		log.Errorf("Invalid metrics_exclude option, no metric will be excluded: %s", err)
+		return nil
+	}
+	return filter
+}
+
+// isExcluded returns whether a metric matches one of the rules
+func (f *metricFilter) isExcluded(name string, tags []string) bool {
+	for _, matcher := range f.matchers {
+		if matcher.match(name, tags) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *metricExcludeMatcher) match(name string, tags []string) bool {
+	if !m.name.MatchString(name) {
+		return false
+	}
+IterTags:
+	for _, tagRegexp := range m.tags {
+		for _, tag := range tags {
+			if tagRegexp.MatchString(tag) {
+				continue IterTags
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// filterSeries removes the excluded series, in place, and returns the number of points dropped
+func (f *metricFilter) filterSeries(series metrics.Series) (metrics.Series, int) {
+	droppedPoints := 0
+	kept := series[:0]
+	for _, serie := range series {
+		if f.isExcluded(serie.Name, serie.Tags) {
+			droppedPoints += len(serie.Points)
+			metrictracer.Record(metrictracer.StageExcluded, serie.Name, 1)
+			continue
+		}
+		kept = append(kept, serie)
+	}
+	return kept, droppedPoints
+}
+
+// filterSketches removes the excluded sketches, in place, and returns the number of points dropped
+func (f *metricFilter) filterSketches(sketches metrics.SketchSeriesList) (metrics.SketchSeriesList, int) {
+	droppedPoints := 0
+	kept := sketches[:0]
+	for _, sketch := range sketches {
+		if f.isExcluded(sketch.Name, sketch.Tags) {
+			droppedPoints += len(sketch.Points)
+			metrictracer.Record(metrictracer.StageExcluded, sketch.Name, 1)
+			continue
+		}
+		kept = append(kept, sketch)
+	}
+	return kept, droppedPoints
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package aggregator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestMetricFilterNoRule(t *testing.T) {
+	filter, err := newMetricFilter(nil)
+	require.NoError(t, err)
+	assert.Nil(t, filter)
+
+	_, err = newMetricFilter([]MetricExcludeRule{{Tags: []string{"env:dev"}}})
+	assert.Error(t, err)
+}
+
+func TestMetricFilterIsExcluded(t *testing.T) {
+	filter, err := newMetricFilter([]MetricExcludeRule{
+		{Name: "noisy.*"},
+		{Name: "http.request.?xx", Tags: []string{"env:dev*", "service:web"}},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, filter)
+
+	assert.True(t, filter.isExcluded("noisy.metric.count", nil))
+	assert.False(t, filter.isExcluded("not.noisy.metric", nil))
+	assert.True(t, filter.isExcluded("http.request.5xx", []string{"service:web", "env:dev-eu"}))
+	assert.False(t, filter.isExcluded("http.request.5xx", []string{"env:dev-eu"}))
+	assert.False(t, filter.isExcluded("http.request.5xx", []string{"service:web", "env:prod"}))
+	assert.False(t, filter.isExcluded("http.request.500", []string{"service:web", "env:dev"}))
+}
+
+func TestMetricFilterSeriesAndSketches(t *testing.T) {
+	filter, err := newMetricFilter([]MetricExcludeRule{{Name: "noisy.*", Tags: []string{"env:dev"}}})
+	require.NoError(t, err)
+
+	series := metrics.Series{
+		{Name: "noisy.gauge", Tags: []string{"env:dev"}, Points: []metrics.Point{{Ts: 1, Value: 1}, {Ts: 2, Value: 2}}},
+		{Name: "noisy.gauge", Tags: []string{"env:prod"}, Points: []metrics.Point{{Ts: 1, Value: 1}}},
+		{Name: "other.gauge", Tags: []string{"env:dev"}, Points: []metrics.Point{{Ts: 1, Value: 1}}},
+	}
+	series, dropped := filter.filterSeries(series)
+	assert.Equal(t, 2, dropped)
+	require.Len(t, series, 2)
+	assert.Equal(t, []string{"env:prod"}, series[0].Tags)
+	assert.Equal(t, "other.gauge", series[1].Name)
+
+	sketches := metrics.SketchSeriesList{
+		{Name: "noisy.latency", Tags: []string{"env:dev"}, Points: []metrics.SketchPoint{{Ts: 1}}},
+		{Name: "other.latency", Tags: []string{"env:dev"}, Points: []metrics.SketchPoint{{Ts: 1}}},
+	}
+	sketches, dropped = filter.filterSketches(sketches)
+	assert.Equal(t, 1, dropped)
+	require.Len(t, sketches, 1)
+	assert.Equal(t, "other.latency", sketches[0].Name)
+}
@@ -24,6 +24,11 @@ func (m *MockSender) MonotonicCount(metric string, value float64, hostname strin
 	m.Called(metric, value, hostname, tags)
 }
 
+//MonotonicCountWithResets adds a monotonic count type with its resets flag to the mock calls.
+func (m *MockSender) MonotonicCountWithResets(metric string, value float64, hostname string, tags []string, resetsPossible bool) {
+	m.Called(metric, value, hostname, tags, resetsPossible)
+}
+
 //Counter adds a counter type to the mock calls.
 func (m *MockSender) Counter(metric string, value float64, hostname string, tags []string) {
 	m.Called(metric, value, hostname, tags)
@@ -59,6 +64,11 @@ func (m *MockSender) Event(e metrics.Event) {
 	m.Called(e)
 }
 
+//EventPlatformEvent enables the event platform event mock call.
+func (m *MockSender) EventPlatformEvent(rawEvent string, eventType string) {
+	m.Called(rawEvent, eventType)
+}
+
 //HistogramBucket enables the histogram bucket mock call.
 func (m *MockSender) HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string) {
 	m.Called(metric, value, lowerBound, upperBound, monotonic, hostname, tags)
@@ -84,6 +94,11 @@ func (m *MockSender) FinalizeCheckServiceTag() {
 	m.Called()
 }
 
+//SetExternalTags enables the set external tags mock call.
+func (m *MockSender) SetExternalTags(hostname, sourceType string, tags []string) {
+	m.Called(hostname, sourceType, tags)
+}
+
 //GetMetricStats enables the get metric stats mock call.
 func (m *MockSender) GetMetricStats() map[string]int64 {
 	m.Called()
@@ -46,6 +46,13 @@ func (m *MockSender) SetupAcceptAll() {
 			mock.AnythingOfType("[]string"), // Tags
 		).Return()
 	}
+	m.On("MonotonicCountWithResets",
+		mock.AnythingOfType("string"),   // Metric
+		mock.AnythingOfType("float64"),  // Value
+		mock.AnythingOfType("string"),   // Hostname
+		mock.AnythingOfType("[]string"), // Tags
+		mock.AnythingOfType("bool"),     // Resets possible
+	).Return()
 	m.On("ServiceCheck",
 		mock.AnythingOfType("string"),                     // checkName (e.g: docker.exit)
 		mock.AnythingOfType("metrics.ServiceCheckStatus"), // (e.g: metrics.ServiceCheckOK)
@@ -54,6 +61,7 @@ func (m *MockSender) SetupAcceptAll() {
 		mock.AnythingOfType("string"),                     // message
 	).Return()
 	m.On("Event", mock.AnythingOfType("metrics.Event")).Return()
+	m.On("EventPlatformEvent", mock.AnythingOfType("string"), mock.AnythingOfType("string")).Return()
 	m.On("HistogramBucket",
 		mock.AnythingOfType("string"),   // metric name
 		mock.AnythingOfType("int64"),    // value
@@ -9,11 +9,13 @@ import (
 	"errors"
 	"fmt"
 	"sync"
-	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/dbm"
+	"github.com/DataDog/datadog-agent/pkg/metadata/externalhost"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
 )
 
@@ -28,17 +30,20 @@ type Sender interface {
 	Rate(metric string, value float64, hostname string, tags []string)
 	Count(metric string, value float64, hostname string, tags []string)
 	MonotonicCount(metric string, value float64, hostname string, tags []string)
+	MonotonicCountWithResets(metric string, value float64, hostname string, tags []string, resetsPossible bool)
 	Counter(metric string, value float64, hostname string, tags []string)
 	Histogram(metric string, value float64, hostname string, tags []string)
 	Historate(metric string, value float64, hostname string, tags []string)
 	ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string)
 	HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string)
 	Event(e metrics.Event)
+	EventPlatformEvent(rawEvent string, eventType string)
 	GetMetricStats() map[string]int64
 	DisableDefaultHostname(disable bool)
 	SetCheckCustomTags(tags []string)
 	SetCheckService(service string)
 	FinalizeCheckServiceTag()
+	SetExternalTags(hostname, sourceType string, tags []string)
 }
 
 type metricStats struct {
@@ -67,6 +72,7 @@ type checkSender struct {
 	serviceCheckOut         chan<- metrics.ServiceCheck
 	eventOut                chan<- metrics.Event
 	histogramBucketOut      chan<- senderHistogramBucket
+	eventPlatformOut        chan<- senderEventPlatformEvent
 	checkTags               []string
 	service                 string
 }
@@ -82,6 +88,12 @@ type senderHistogramBucket struct {
 	bucket *metrics.HistogramBucket
 }
 
+type senderEventPlatformEvent struct {
+	id        check.ID
+	rawEvent  string
+	eventType string
+}
+
 type checkSenderPool struct {
 	senders map[check.ID]Sender
 	m       sync.Mutex
@@ -93,7 +105,7 @@ func init() {
 	}
 }
 
-func newCheckSender(id check.ID, defaultHostname string, smsOut chan<- senderMetricSample, serviceCheckOut chan<- metrics.ServiceCheck, eventOut chan<- metrics.Event, bucketOut chan<- senderHistogramBucket) *checkSender {
+func newCheckSender(id check.ID, defaultHostname string, smsOut chan<- senderMetricSample, serviceCheckOut chan<- metrics.ServiceCheck, eventOut chan<- metrics.Event, bucketOut chan<- senderHistogramBucket, eventPlatformOut chan<- senderEventPlatformEvent) *checkSender {
 	return &checkSender{
 		id:                 id,
 		defaultHostname:    defaultHostname,
@@ -103,6 +115,7 @@ func newCheckSender(id check.ID, defaultHostname string, smsOut chan<- senderMet
 		metricStats:        metricStats{},
 		priormetricStats:   metricStats{},
 		histogramBucketOut: bucketOut,
+		eventPlatformOut:   eventPlatformOut,
 	}
 }
 
@@ -145,7 +158,7 @@ func GetDefaultSender() (Sender, error) {
 	senderInit.Do(func() {
 		var defaultCheckID check.ID                       // the default value is the zero value
 		aggregatorInstance.registerSender(defaultCheckID) //nolint:errcheck
-		senderInstance = newCheckSender(defaultCheckID, aggregatorInstance.hostname, aggregatorInstance.checkMetricIn, aggregatorInstance.serviceCheckIn, aggregatorInstance.eventIn, aggregatorInstance.checkHistogramBucketIn)
+		senderInstance = newCheckSender(defaultCheckID, aggregatorInstance.hostname, aggregatorInstance.checkMetricIn, aggregatorInstance.serviceCheckIn, aggregatorInstance.eventIn, aggregatorInstance.checkHistogramBucketIn, aggregatorInstance.eventPlatformIn)
 	})
 
 	return senderInstance, nil
@@ -185,6 +198,12 @@ func (s *checkSender) FinalizeCheckServiceTag() {
 	}
 }
 
+// SetExternalTags sets the tags of a host discovered by the check, e.g. a VM of a hypervisor,
+// which doesn't run an agent. The tags are sent with the host metadata.
+func (s *checkSender) SetExternalTags(hostname, sourceType string, tags []string) {
+	externalhost.SetExternalTags(hostname, sourceType, tags)
+}
+
 // Commit commits the metric samples & histogram buckets that were added during a check run
 // Should be called at the end of every check run
 func (s *checkSender) Commit() {
@@ -228,18 +247,23 @@ func (s *checkSender) SendRawMetricSample(sample *metrics.MetricSample) {
 }
 
 func (s *checkSender) sendMetricSample(metric string, value float64, hostname string, tags []string, mType metrics.MetricType) {
+	s.sendMetricSampleWithResets(metric, value, hostname, tags, mType, metrics.CounterResetsIgnored)
+}
+
+func (s *checkSender) sendMetricSampleWithResets(metric string, value float64, hostname string, tags []string, mType metrics.MetricType, resets metrics.CounterResets) {
 	tags = append(tags, s.checkTags...)
 
 	log.Trace(mType.String(), " sample: ", metric, ": ", value, " for hostname: ", hostname, " tags: ", tags)
 
 	metricSample := &metrics.MetricSample{
-		Name:       metric,
-		Value:      value,
-		Mtype:      mType,
-		Tags:       tags,
-		Host:       hostname,
-		SampleRate: 1,
-		Timestamp:  timeNowNano(),
+		Name:          metric,
+		Value:         value,
+		Mtype:         mType,
+		Tags:          tags,
+		Host:          hostname,
+		SampleRate:    1,
+		Timestamp:     timeNowNano(),
+		CounterResets: resets,
 	}
 
 	if hostname == "" && !s.defaultHostnameDisabled {
@@ -273,6 +297,18 @@ func (s *checkSender) MonotonicCount(metric string, value float64, hostname stri
 	s.sendMetricSample(metric, value, hostname, tags, metrics.MonotonicCountType)
 }
 
+// MonotonicCountWithResets should be used to track the increase of a monotonic raw counter, resetsPossible
+// telling whether a decrease of the counter is a reset to zero or a glitch. On a reset, the value of the
+// counter is counted as its increase since the reset. A glitch is dropped, the next samples being compared
+// to the last sample before it. Neither emits a negative value or counts an increase twice.
+func (s *checkSender) MonotonicCountWithResets(metric string, value float64, hostname string, tags []string, resetsPossible bool) {
+	resets := metrics.CounterResetsImpossible
+	if resetsPossible {
+		resets = metrics.CounterResetsPossible
+	}
+	s.sendMetricSampleWithResets(metric, value, hostname, tags, metrics.MonotonicCountType, resets)
+}
+
 // Counter is DEPRECATED and only implemented to preserve backward compatibility with python checks. Prefer using either:
 // * `Gauge` if you're counting states
 // * `Count` if you're counting events
@@ -342,7 +378,7 @@ func (s *checkSender) ServiceCheck(checkName string, status metrics.ServiceCheck
 		CheckName: checkName,
 		Status:    status,
 		Host:      hostname,
-		Ts:        time.Now().Unix(),
+		Ts:        clock.Now().Unix(),
 		Tags:      append(tags, s.checkTags...),
 		Message:   message,
 	}
@@ -375,6 +411,24 @@ func (s *checkSender) Event(e metrics.Event) {
 	s.metricStats.Lock.Unlock()
 }
 
+// EventPlatformEvent submits a raw event, usually a JSON payload, to the event platform intake track of its type
+func (s *checkSender) EventPlatformEvent(rawEvent string, eventType string) {
+	log.Trace("Event platform event submitted: ", eventType)
+	if dbm.IsEventType(eventType) {
+		// the database monitoring payloads are already aggregated by the checks and too large
+		// to go through the aggregator, they are sent straight to their track
+		if err := dbm.Submit(rawEvent, eventType); err != nil {
+			log.Debugf("Dropping a %s payload of the check %s: %v", eventType, s.id, err)
+		}
+		return
+	}
+	s.eventPlatformOut <- senderEventPlatformEvent{
+		id:        s.id,
+		rawEvent:  rawEvent,
+		eventType: eventType,
+	}
+}
+
 // changeAllSendersDefaultHostname u
 func (sp *checkSenderPool) changeAllSendersDefaultHostname(hostname string) {
 	sp.m.Lock()
@@ -403,7 +457,7 @@ func (sp *checkSenderPool) mkSender(id check.ID) (Sender, error) {
 	defer sp.m.Unlock()
 
 	err := aggregatorInstance.registerSender(id)
-	sender := newCheckSender(id, aggregatorInstance.hostname, aggregatorInstance.checkMetricIn, aggregatorInstance.serviceCheckIn, aggregatorInstance.eventIn, aggregatorInstance.checkHistogramBucketIn)
+	sender := newCheckSender(id, aggregatorInstance.hostname, aggregatorInstance.checkMetricIn, aggregatorInstance.serviceCheckIn, aggregatorInstance.eventIn, aggregatorInstance.checkHistogramBucketIn, aggregatorInstance.eventPlatformIn)
 	sp.senders[id] = sender
 	return sender, err
 }
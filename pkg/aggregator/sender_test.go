@@ -115,7 +115,7 @@ func TestGetAndSetSender(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	testCheckSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	testCheckSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 
 	err := SetSender(testCheckSender, checkID1)
 	assert.Nil(t, err)
@@ -147,7 +147,7 @@ func TestGetSenderServiceTagMetrics(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 	checkTags := []string{"check:tag1", "check:tag2"}
 
 	// only tags added by the check
@@ -174,7 +174,7 @@ func TestGetSenderServiceTagServiceCheck(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 	checkTags := []string{"check:tag1", "check:tag2"}
 
 	// only tags added by the check
@@ -201,7 +201,7 @@ func TestGetSenderServiceTagEvent(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 	checkTags := []string{"check:tag1", "check:tag2"}
 
 	event := metrics.Event{
@@ -236,7 +236,7 @@ func TestGetSenderAddCheckCustomTagsMetrics(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 
 	// no custom tags
 	checkSender.sendMetricSample("metric.test", 42.0, "testhostname", nil, metrics.CounterType)
@@ -273,7 +273,7 @@ func TestGetSenderAddCheckCustomTagsService(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 
 	// no custom tags
 	checkSender.ServiceCheck("test", metrics.ServiceCheckOK, "testhostname", nil, "test message")
@@ -310,7 +310,7 @@ func TestGetSenderAddCheckCustomTagsEvent(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 
 	event := metrics.Event{
 		Title: "title",
@@ -358,7 +358,7 @@ func TestGetSenderAddCheckCustomTagsHistogramBucket(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 
 	// no custom tags
 	checkSender.HistogramBucket("my.histogram_bucket", 42, 1.0, 2.0, true, "my-hostname", nil)
@@ -392,11 +392,13 @@ func TestCheckSenderInterface(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "default-hostname", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	eventPlatformChan := make(chan senderEventPlatformEvent, 10)
+	checkSender := newCheckSender(checkID1, "default-hostname", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, eventPlatformChan)
 	checkSender.Gauge("my.metric", 1.0, "my-hostname", []string{"foo", "bar"})
 	checkSender.Rate("my.rate_metric", 2.0, "my-hostname", []string{"foo", "bar"})
 	checkSender.Count("my.count_metric", 123.0, "my-hostname", []string{"foo", "bar"})
 	checkSender.MonotonicCount("my.monotonic_count_metric", 12.0, "my-hostname", []string{"foo", "bar"})
+	checkSender.MonotonicCountWithResets("my.monotonic_count_resets_metric", 12.0, "my-hostname", []string{"foo", "bar"}, true)
 	checkSender.Counter("my.counter_metric", 1.0, "my-hostname", []string{"foo", "bar"})
 	checkSender.Histogram("my.histo_metric", 3.0, "my-hostname", []string{"foo", "bar"})
 	checkSender.HistogramBucket("my.histogram_bucket", 42, 1.0, 2.0, true, "my-hostname", []string{"foo", "bar"})
@@ -414,6 +416,7 @@ func TestCheckSenderInterface(t *testing.T) {
 		SourceTypeName: "docker",
 	}
 	checkSender.Event(submittedEvent)
+	checkSender.EventPlatformEvent(`{"device":"router"}`, "network-devices-metadata")
 
 	gaugeSenderSample := <-senderMetricSampleChan
 	assert.EqualValues(t, checkID1, gaugeSenderSample.id)
@@ -434,8 +437,15 @@ func TestCheckSenderInterface(t *testing.T) {
 	monotonicCountSenderSample := <-senderMetricSampleChan
 	assert.EqualValues(t, checkID1, monotonicCountSenderSample.id)
 	assert.Equal(t, metrics.MonotonicCountType, monotonicCountSenderSample.metricSample.Mtype)
+	assert.Equal(t, metrics.CounterResetsIgnored, monotonicCountSenderSample.metricSample.CounterResets)
 	assert.Equal(t, false, monotonicCountSenderSample.commit)
 
+	monotonicCountResetsSenderSample := <-senderMetricSampleChan
+	assert.EqualValues(t, checkID1, monotonicCountResetsSenderSample.id)
+	assert.Equal(t, metrics.MonotonicCountType, monotonicCountResetsSenderSample.metricSample.Mtype)
+	assert.Equal(t, metrics.CounterResetsPossible, monotonicCountResetsSenderSample.metricSample.CounterResets)
+	assert.Equal(t, false, monotonicCountResetsSenderSample.commit)
+
 	CounterSenderSample := <-senderMetricSampleChan
 	assert.EqualValues(t, checkID1, CounterSenderSample.id)
 	assert.Equal(t, metrics.CounterType, CounterSenderSample.metricSample.Mtype)
@@ -468,6 +478,11 @@ func TestCheckSenderInterface(t *testing.T) {
 	assert.Equal(t, true, histogramBucket.bucket.Monotonic)
 	assert.Equal(t, "my-hostname", histogramBucket.bucket.Host)
 	assert.Equal(t, []string{"foo", "bar"}, histogramBucket.bucket.Tags)
+
+	eventPlatformEvent := <-eventPlatformChan
+	assert.EqualValues(t, checkID1, eventPlatformEvent.id)
+	assert.Equal(t, `{"device":"router"}`, eventPlatformEvent.rawEvent)
+	assert.Equal(t, "network-devices-metadata", eventPlatformEvent.eventType)
 }
 
 func TestCheckSenderHostname(t *testing.T) {
@@ -504,7 +519,7 @@ func TestCheckSenderHostname(t *testing.T) {
 			serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 			eventChan := make(chan metrics.Event, 10)
 			bucketChan := make(chan senderHistogramBucket, 10)
-			checkSender := newCheckSender(checkID1, defaultHostname, senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+			checkSender := newCheckSender(checkID1, defaultHostname, senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 			checkSender.DisableDefaultHostname(tc.defaultHostnameDisabled)
 
 			checkSender.Gauge("my.metric", 1.0, tc.submittedHostname, []string{"foo", "bar"})
@@ -550,7 +565,7 @@ func TestChangeAllSendersDefaultHostname(t *testing.T) {
 	serviceCheckChan := make(chan metrics.ServiceCheck, 10)
 	eventChan := make(chan metrics.Event, 10)
 	bucketChan := make(chan senderHistogramBucket, 10)
-	checkSender := newCheckSender(checkID1, "hostname1", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan)
+	checkSender := newCheckSender(checkID1, "hostname1", senderMetricSampleChan, serviceCheckChan, eventChan, bucketChan, nil)
 	SetSender(checkSender, checkID1)
 
 	checkSender.Gauge("my.metric", 1.0, "", nil)
@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package debugport listens on the debug ports of the agent processes, like the expvar and
+// health ports, falling back to another port when the configured one is in use, for instance
+// when several agents run on the same host. The ports in use are advertised in a runtime file
+// so that the commands, like status and flare, can still reach the debug endpoints.
+package debugport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/pidfile"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The processes advertising their debug ports
+const (
+	Agent        = "agent"
+	Dogstatsd    = "dogstatsd"
+	ClusterAgent = "cluster-agent"
+)
+
+// The debug endpoints advertised by the processes
+const (
+	Expvar = "expvar"
+	Health = "health"
+)
+
+// fallbackAttempts is the number of ports tried after the configured one before letting the OS pick one
+const fallbackAttempts = 10
+
+// advertisement is the content of the runtime file of a process
+type advertisement struct {
+	PID   int            `json:"pid"`
+	Ports map[string]int `json:"ports"`
+}
+
+var (
+	advertised      = map[string]*advertisement{}
+	advertisedMutex sync.Mutex
+)
+
+// Listen listens on host:port. When the port is in use and `debug_port_fallback` is enabled,
+// it listens on the next available port, or on a port picked by the OS.
+func Listen(host string, port int) (net.Listener, error) {
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprintf("%d", port)))
+	if err == nil || !config.Datadog.GetBool("debug_port_fallback") || port == 0 {
+		return ln, err
+	}
+
+	for p := port + 1; p <= port+fallbackAttempts && p <= 65535; p++ {
+		if ln, fallbackErr := net.Listen("tcp", net.JoinHostPort(host, fmt.Sprintf("%d", p))); fallbackErr == nil {
+			log.Warnf("Port %d is in use (%v), listening on port %d instead", port, err, p)
+			return ln, nil
+		}
+	}
+	ln, fallbackErr := net.Listen("tcp", net.JoinHostPort(host, "0"))
+	if fallbackErr != nil {
+		return nil, err
+	}
+	log.Warnf("Port %d is in use (%v), listening on port %d instead", port, err, Port(ln))
+	return ln, nil
+}
+
+// Port returns the port a listener listens on
+func Port(ln net.Listener) int {
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok {
+		return addr.Port
+	}
+	return 0
+}
+
+// getFilepath returns the path of the runtime file advertising the debug ports of a process
+func getFilepath(process string) string {
+	return filepath.Join(config.Datadog.GetString("run_path"), process+"_debug_ports.json")
+}
+
+// Advertise records the port of a debug endpoint of the current process in its runtime file
+func Advertise(process, endpoint string, port int) error {
+	advertisedMutex.Lock()
+	defer advertisedMutex.Unlock()
+
+	a, found := advertised[process]
+	if !found {
+		a = &advertisement{PID: os.Getpid(), Ports: map[string]int{}}
+		advertised[process] = a
+	}
+	a.Ports[endpoint] = port
+
+	content, err := json.Marshal(a)
+	if err != nil {
+		return err
+	}
+	path := getFilepath(process)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0644)
+}
+
+// Remove removes the runtime file of a process, when it's stopping
+func Remove(process string) {
+	advertisedMutex.Lock()
+	defer advertisedMutex.Unlock()
+
+	if _, found := advertised[process]; !found {
+		return
+	}
+	delete(advertised, process)
+	if err := os.Remove(getFilepath(process)); err != nil && !os.IsNotExist(err) {
+		log.Debugf("Unable to remove the debug ports file of %s: %v", process, err)
+	}
+}
+
+// Lookup returns the port of a debug endpoint advertised by a running process,
+// or defaultPort when the process doesn't advertise it
+func Lookup(process, endpoint string, defaultPort int) int {
+	content, err := ioutil.ReadFile(getFilepath(process))
+	if err != nil {
+		return defaultPort
+	}
+	var a advertisement
+	if err := json.Unmarshal(content, &a); err != nil {
+		log.Debugf("Invalid debug ports file for %s: %v", process, err)
+		return defaultPort
+	}
+	// ignore the file left by a process that didn't stop cleanly
+	if !pidfile.IsRunning(a.PID) {
+		return defaultPort
+	}
+	if port, found := a.Ports[endpoint]; found {
+		return port
+	}
+	return defaultPort
+}
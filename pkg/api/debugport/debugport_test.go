@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package debugport
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestListenFallback(t *testing.T) {
+	mockConfig := config.Mock()
+
+	used, err := Listen("127.0.0.1", 0)
+	require.NoError(t, err)
+	defer used.Close()
+	port := Port(used)
+
+	ln, err := Listen("127.0.0.1", port)
+	require.NoError(t, err)
+	defer ln.Close()
+	assert.NotEqual(t, port, Port(ln))
+
+	mockConfig.Set("debug_port_fallback", false)
+	defer mockConfig.Set("debug_port_fallback", true)
+	_, err = Listen("127.0.0.1", port)
+	assert.Error(t, err)
+}
+
+func TestAdvertiseLookup(t *testing.T) {
+	runPath, err := ioutil.TempDir("", "debugport")
+	require.NoError(t, err)
+	defer os.RemoveAll(runPath)
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", runPath)
+
+	assert.Equal(t, 5000, Lookup(Agent, Expvar, 5000))
+
+	require.NoError(t, Advertise(Agent, Expvar, 5001))
+	require.NoError(t, Advertise(Agent, Health, 5556))
+	assert.Equal(t, 5001, Lookup(Agent, Expvar, 5000))
+	assert.Equal(t, 5556, Lookup(Agent, Health, 5555))
+	assert.Equal(t, 5000, Lookup(Dogstatsd, Expvar, 5000))
+
+	Remove(Agent)
+	assert.Equal(t, 5000, Lookup(Agent, Expvar, 5000))
+}
@@ -9,11 +9,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"net"
 	"net/http"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/gorilla/mux"
@@ -23,15 +22,22 @@ const defaultTimeout = time.Second
 
 // Serve configures and starts the http server for the health check.
 // It returns an error if the setup failed, or runs the server in a goroutine.
+// The port the server listens on is advertised for the given process, since it
+// differs from the configured one when that one is in use.
 // Stop the server by cancelling the passed context.
-func Serve(ctx context.Context, port int) error {
+func Serve(ctx context.Context, process string, port int) error {
 	if port == 0 {
 		return errors.New("port should be non-zero")
 	}
-	ln, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%v", port))
+	ln, err := debugport.Listen("0.0.0.0", port)
 	if err != nil {
 		return err
 	}
+	port = debugport.Port(ln)
+	if err := debugport.Advertise(process, debugport.Health, port); err != nil {
+		log.Debugf("Unable to advertise the health port: %v", err)
+	}
+	log.Debugf("Health check listening on port %d", port)
 
 	r := mux.NewRouter()
 	r.HandleFunc("/live", liveHandler)
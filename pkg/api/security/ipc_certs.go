@@ -0,0 +1,260 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package security
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The agent processes authenticate each other on the IPC API with certificates issued by a
+// local CA. The CA and the certificates are stored next to the auth token, with the same permissions:
+// like the auth token, the client key grants access to the IPC API to whoever can read it.
+const (
+	ipcCACertName     = "ipc_ca.crt"
+	ipcCAKeyName      = "ipc_ca.key"
+	ipcServerCertName = "ipc_server.crt"
+	ipcServerKeyName  = "ipc_server.key"
+	ipcClientCertName = "ipc_client.crt"
+	ipcClientKeyName  = "ipc_client.key"
+
+	ipcKeyBits = 2048
+	// ipcCertLifetime is the validity of the server and client certificates, the CA one is valid 10 years
+	ipcCertLifetime = 365 * 24 * time.Hour
+	// ipcCertRenewBefore is how long before their expiration the certificates are rotated
+	ipcCertRenewBefore = 30 * 24 * time.Hour
+)
+
+// IPCCertificates holds the certificates used to authenticate the agent processes on the IPC API
+type IPCCertificates struct {
+	// CAPool holds the local CA issuing the server and client certificates
+	CAPool *x509.CertPool
+	// Server is nil when the certificates are fetched by a client
+	Server *tls.Certificate
+	Client tls.Certificate
+}
+
+// ipcKeyPair is a certificate along with its private key
+type ipcKeyPair struct {
+	cert    *x509.Certificate
+	key     *rsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func (p *ipcKeyPair) tlsCertificate() (tls.Certificate, error) {
+	return tls.X509KeyPair(p.certPEM, p.keyPEM)
+}
+
+// expiresSoon returns whether the certificate has to be rotated
+func (p *ipcKeyPair) expiresSoon(now time.Time) bool {
+	return now.Add(ipcCertRenewBefore).After(p.cert.NotAfter)
+}
+
+// getIPCCertFilepath returns the path of a file of the IPC certificates
+func getIPCCertFilepath(name string) string {
+	return filepath.Join(filepath.Dir(GetAuthTokenFilepath()), name)
+}
+
+// CreateOrRotateIPCCertificates gets the IPC certificates, creating the ones that don't exist yet and
+// rotating the ones close to their expiration. The server certificate is valid for the given hosts.
+// It is called by the process serving the IPC API.
+// Requires that the config has been set up before calling
+func CreateOrRotateIPCCertificates(hosts []string) (*IPCCertificates, error) {
+	now := time.Now()
+
+	ca, err := loadIPCKeyPair(ipcCACertName, ipcCAKeyName)
+	if err != nil || ca.expiresSoon(now) {
+		if ca, err = generateIPCKeyPair(nil, nil); err != nil {
+			return nil, fmt.Errorf("unable to generate the IPC CA: %v", err)
+		}
+		if err = saveIPCKeyPair(ca, ipcCACertName, ipcCAKeyName); err != nil {
+			return nil, err
+		}
+		log.Infof("Saved a new IPC CA to %s", getIPCCertFilepath(ipcCACertName))
+	}
+
+	server, err := createOrRotateIPCLeaf(ca, hosts, ipcServerCertName, ipcServerKeyName, now)
+	if err != nil {
+		return nil, err
+	}
+	client, err := createOrRotateIPCLeaf(ca, nil, ipcClientCertName, ipcClientKeyName, now)
+	if err != nil {
+		return nil, err
+	}
+
+	certs, err := buildIPCCertificates(ca, client)
+	if err != nil {
+		return nil, err
+	}
+	serverCert, err := server.tlsCertificate()
+	if err != nil {
+		return nil, err
+	}
+	certs.Server = &serverCert
+	return certs, nil
+}
+
+// FetchIPCCertificates gets the CA and the client certificate created by the process serving the IPC API
+// Requires that the config has been set up before calling
+func FetchIPCCertificates() (*IPCCertificates, error) {
+	ca, err := loadIPCKeyPair(ipcCACertName, "")
+	if err != nil {
+		return nil, err
+	}
+	client, err := loadIPCKeyPair(ipcClientCertName, ipcClientKeyName)
+	if err != nil {
+		return nil, err
+	}
+	return buildIPCCertificates(ca, client)
+}
+
+func buildIPCCertificates(ca, client *ipcKeyPair) (*IPCCertificates, error) {
+	clientCert, err := client.tlsCertificate()
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return &IPCCertificates{CAPool: pool, Client: clientCert}, nil
+}
+
+// createOrRotateIPCLeaf gets a certificate issued by the CA, generating a new one when it doesn't exist,
+// expires soon, was issued by another CA or doesn't cover all the hosts
+func createOrRotateIPCLeaf(ca *ipcKeyPair, hosts []string, certName, keyName string, now time.Time) (*ipcKeyPair, error) {
+	leaf, err := loadIPCKeyPair(certName, keyName)
+	if err == nil && !leaf.expiresSoon(now) && leaf.cert.CheckSignatureFrom(ca.cert) == nil && coversHosts(leaf.cert, hosts) {
+		return leaf, nil
+	}
+
+	if leaf, err = generateIPCKeyPair(ca, hosts); err != nil {
+		return nil, fmt.Errorf("unable to generate the IPC certificate %s: %v", certName, err)
+	}
+	if err = saveIPCKeyPair(leaf, certName, keyName); err != nil {
+		return nil, err
+	}
+	log.Infof("Saved a new IPC certificate to %s", getIPCCertFilepath(certName))
+	return leaf, nil
+}
+
+// coversHosts returns whether the certificate is valid for all the hosts
+func coversHosts(cert *x509.Certificate, hosts []string) bool {
+	for _, h := range hosts {
+		if cert.VerifyHostname(h) != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// generateIPCKeyPair generates the CA when ca is nil, or a certificate issued by ca. Certificates
+// issued with hosts are server certificates, the other ones are client certificates.
+func generateIPCKeyPair(ca *ipcKeyPair, hosts []string) (*ipcKeyPair, error) {
+	tmpl, err := CertTemplate()
+	if err != nil {
+		return nil, err
+	}
+	key, err := GenerateKeyPair(ipcKeyBits)
+	if err != nil {
+		return nil, err
+	}
+
+	parent, parentKey := tmpl, key
+	switch {
+	case ca == nil:
+		tmpl.Subject = pkix.Name{Organization: []string{"Datadog, Inc."}, CommonName: "Datadog Agent IPC CA"}
+		tmpl.IsCA = true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	case len(hosts) > 0:
+		tmpl.Subject.CommonName = "Datadog Agent IPC server"
+		tmpl.NotAfter = tmpl.NotBefore.Add(ipcCertLifetime)
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		for _, h := range hosts {
+			if ip := net.ParseIP(h); ip != nil {
+				tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+			} else {
+				tmpl.DNSNames = append(tmpl.DNSNames, h)
+			}
+		}
+		parent, parentKey = ca.cert, ca.key
+	default:
+		tmpl.Subject.CommonName = "Datadog Agent IPC client"
+		tmpl.NotAfter = tmpl.NotBefore.Add(ipcCertLifetime)
+		tmpl.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		parent, parentKey = ca.cert, ca.key
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(certDER)
+	if err != nil {
+		return nil, err
+	}
+	return &ipcKeyPair{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}),
+	}, nil
+}
+
+// loadIPCKeyPair reads a certificate and its private key, the key is not read when keyName is empty
+func loadIPCKeyPair(certName, keyName string) (*ipcKeyPair, error) {
+	pair := &ipcKeyPair{}
+
+	var err error
+	if pair.certPEM, err = ioutil.ReadFile(getIPCCertFilepath(certName)); err != nil {
+		return nil, fmt.Errorf("unable to read the IPC certificate: %v", err)
+	}
+	block, _ := pem.Decode(pair.certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("invalid IPC certificate %s", certName)
+	}
+	if pair.cert, err = x509.ParseCertificate(block.Bytes); err != nil {
+		return nil, fmt.Errorf("invalid IPC certificate %s: %v", certName, err)
+	}
+
+	if keyName == "" {
+		return pair, nil
+	}
+	if pair.keyPEM, err = ioutil.ReadFile(getIPCCertFilepath(keyName)); err != nil {
+		return nil, fmt.Errorf("unable to read the IPC key: %v", err)
+	}
+	block, _ = pem.Decode(pair.keyPEM)
+	if block == nil || block.Type != "RSA PRIVATE KEY" {
+		return nil, fmt.Errorf("invalid IPC key %s", keyName)
+	}
+	if pair.key, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("invalid IPC key %s: %v", keyName, err)
+	}
+	return pair, nil
+}
+
+// saveIPCKeyPair writes a certificate and its private key with the permissions of the auth token
+func saveIPCKeyPair(pair *ipcKeyPair, certName, keyName string) error {
+	if err := saveAuthToken(string(pair.keyPEM), getIPCCertFilepath(keyName)); err != nil {
+		return fmt.Errorf("error writing the IPC key on fs: %v", err)
+	}
+	if err := saveAuthToken(string(pair.certPEM), getIPCCertFilepath(certName)); err != nil {
+		return fmt.Errorf("error writing the IPC certificate on fs: %v", err)
+	}
+	return nil
+}
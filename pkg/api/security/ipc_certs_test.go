@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !windows
+
+package security
+
+import (
+	"crypto/x509"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrRotateIPCCertificates(t *testing.T) {
+	tokenPath := initMockConf(t)
+	defer cleanMockConf(tokenPath)
+
+	_, err := FetchIPCCertificates()
+	require.Error(t, err)
+
+	hosts := []string{"127.0.0.1", "localhost"}
+	certs, err := CreateOrRotateIPCCertificates(hosts)
+	require.NoError(t, err)
+	require.NotNil(t, certs.Server)
+
+	server, err := x509.ParseCertificate(certs.Server.Certificate[0])
+	require.NoError(t, err)
+	_, err = server.Verify(x509.VerifyOptions{DNSName: "localhost", Roots: certs.CAPool})
+	assert.NoError(t, err)
+
+	// the clients read the certificates created by the server
+	fetched, err := FetchIPCCertificates()
+	require.NoError(t, err)
+	assert.Nil(t, fetched.Server)
+	client, err := x509.ParseCertificate(fetched.Client.Certificate[0])
+	require.NoError(t, err)
+	_, err = client.Verify(x509.VerifyOptions{
+		Roots:     certs.CAPool,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err)
+
+	// the valid certificates are kept
+	again, err := CreateOrRotateIPCCertificates(hosts)
+	require.NoError(t, err)
+	assert.Equal(t, certs.Server.Certificate, again.Server.Certificate)
+	assert.Equal(t, certs.Client.Certificate, again.Client.Certificate)
+
+	// the server certificate is renewed when it doesn't cover a new host
+	again, err = CreateOrRotateIPCCertificates(append(hosts, "10.0.0.1"))
+	require.NoError(t, err)
+	assert.NotEqual(t, certs.Server.Certificate, again.Server.Certificate)
+	assert.Equal(t, certs.Client.Certificate, again.Client.Certificate)
+}
+
+func TestIPCCertificatesRotation(t *testing.T) {
+	tokenPath := initMockConf(t)
+	defer cleanMockConf(tokenPath)
+
+	_, err := CreateOrRotateIPCCertificates(nil)
+	require.NoError(t, err)
+
+	pair, err := loadIPCKeyPair(ipcClientCertName, ipcClientKeyName)
+	require.NoError(t, err)
+	assert.False(t, pair.expiresSoon(time.Now()))
+	assert.True(t, pair.expiresSoon(time.Now().Add(ipcCertLifetime-ipcCertRenewBefore/2)))
+}
@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package security
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Scope is a group of endpoints of the IPC API a token grants access to
+type Scope string
+
+// The scopes of the IPC API
+const (
+	// ScopeAdmin grants access to every endpoint, it is the scope of the auth token
+	ScopeAdmin Scope = "admin"
+	// ScopeStatusRead grants access to the version, status and health of the agent
+	ScopeStatusRead Scope = "status:read"
+	// ScopeHostnameRead grants access to the hostname of the agent
+	ScopeHostnameRead Scope = "hostname:read"
+	// ScopeTaggerRead grants access to the entities of the tagger
+	ScopeTaggerRead Scope = "tagger:read"
+	// ScopeConfigRead grants access to the full configuration, the loaded check configurations and the secrets
+	ScopeConfigRead Scope = "config:read"
+	// ScopeRuntimeSettings grants access to the settings changeable at runtime
+	ScopeRuntimeSettings Scope = "runtime_settings"
+	// ScopeChecksRun grants running and reloading checks
+	ScopeChecksRun Scope = "checks:run"
+	// ScopeFlare grants creating flares
+	ScopeFlare Scope = "flare"
+	// ScopeControl grants stopping the agent and streaming its logs
+	ScopeControl Scope = "control"
+)
+
+// The names of the scoped tokens
+const (
+	// GUITokenName is the token of the GUI, which reads the status and changes runtime settings
+	GUITokenName = "gui"
+	// ProcessAgentTokenName is the token of the Process Agent, which reads the hostname
+	ProcessAgentTokenName = "process_agent"
+)
+
+// scopedTokens are the scopes granted to each scoped token
+var scopedTokens = map[string][]Scope{
+	GUITokenName:          {ScopeStatusRead, ScopeHostnameRead, ScopeRuntimeSettings, ScopeChecksRun},
+	ProcessAgentTokenName: {ScopeHostnameRead},
+}
+
+// ScopedTokenNames returns the names of the scoped tokens
+func ScopedTokenNames() []string {
+	names := make([]string, 0, len(scopedTokens))
+	for name := range scopedTokens {
+		names = append(names, name)
+	}
+	return names
+}
+
+// TokenHasScope returns whether the scoped token with the given name grants the scope
+func TokenHasScope(name string, scope Scope) bool {
+	for _, s := range scopedTokens[name] {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// GetScopedTokenFilepath returns the path to the file of a scoped token, next to the auth_token file
+func GetScopedTokenFilepath(name string) string {
+	return filepath.Join(filepath.Dir(GetAuthTokenFilepath()), name+"."+authTokenName)
+}
+
+// FetchScopedToken gets a scoped token from its file
+// Requires that the config has been set up before calling
+func FetchScopedToken(name string) (string, error) {
+	return fetchScopedToken(name, false)
+}
+
+// CreateOrFetchScopedToken gets a scoped token from its file & creates one if it doesn't exist
+// Requires that the config has been set up before calling
+func CreateOrFetchScopedToken(name string) (string, error) {
+	return fetchScopedToken(name, true)
+}
+
+func fetchScopedToken(name string, tokenCreationAllowed bool) (string, error) {
+	if _, found := scopedTokens[name]; !found {
+		return "", fmt.Errorf("unknown scoped token %s", name)
+	}
+	tokenFile := GetScopedTokenFilepath(name)
+
+	if _, e := os.Stat(tokenFile); os.IsNotExist(e) && tokenCreationAllowed {
+		key := make([]byte, authTokenMinimalLen)
+		_, e = rand.Read(key)
+		if e != nil {
+			return "", fmt.Errorf("can't create the %s token value: %s", name, e)
+		}
+
+		e = saveAuthToken(hex.EncodeToString(key), tokenFile)
+		if e != nil {
+			return "", fmt.Errorf("error writing the %s token file on fs: %s", name, e)
+		}
+		log.Infof("Saved a new %s token to %s", name, tokenFile)
+	}
+
+	tokenRaw, e := ioutil.ReadFile(tokenFile)
+	if e != nil {
+		return "", fmt.Errorf("unable to read the %s token file: %s", name, e)
+	}
+	token := string(tokenRaw)
+	if len(token) < authTokenMinimalLen {
+		return "", fmt.Errorf("invalid %s token: must be at least %d characters in length", name, authTokenMinimalLen)
+	}
+	return token, nil
+}
@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !windows
+
+package security
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrFetchScopedToken(t *testing.T) {
+	tokenPath := initMockConf(t)
+	defer cleanMockConf(tokenPath)
+
+	_, err := FetchScopedToken(GUITokenName)
+	require.Error(t, err)
+
+	token, err := CreateOrFetchScopedToken(GUITokenName)
+	require.NoError(t, err)
+	assert.True(t, len(token) >= authTokenMinimalLen)
+
+	fetched, err := FetchScopedToken(GUITokenName)
+	require.NoError(t, err)
+	assert.Equal(t, token, fetched)
+
+	// each scoped token has its own value
+	other, err := CreateOrFetchScopedToken(ProcessAgentTokenName)
+	require.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	_, err = CreateOrFetchScopedToken("unknown")
+	assert.Error(t, err)
+}
+
+func TestTokenHasScope(t *testing.T) {
+	assert.True(t, TokenHasScope(GUITokenName, ScopeStatusRead))
+	assert.False(t, TokenHasScope(GUITokenName, ScopeConfigRead))
+	assert.False(t, TokenHasScope(GUITokenName, ScopeFlare))
+	assert.False(t, TokenHasScope(ProcessAgentTokenName, ScopeTaggerRead))
+	assert.True(t, TokenHasScope(ProcessAgentTokenName, ScopeHostnameRead))
+	assert.False(t, TokenHasScope(ProcessAgentTokenName, ScopeStatusRead))
+	assert.False(t, TokenHasScope("unknown", ScopeStatusRead))
+}
@@ -11,6 +11,10 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // GetClient is a convenience function returning an http client
@@ -26,14 +30,44 @@ func GetClient(verify bool) *http.Client {
 	return &http.Client{Transport: tr}
 }
 
+// GetIPCClient returns an http client for the IPC API of the agent. With `ipc_mtls.enabled`, it
+// presents the client certificate of the agent and verifies the server one against the IPC CA.
+// It falls back to GetClient(false) when the IPC certificates can't be read, like when the running
+// agent predates them, the requests are then authenticated with the auth token only.
+// Requires that the config has been set up before calling
+func GetIPCClient() *http.Client {
+	if !config.Datadog.GetBool("ipc_mtls.enabled") {
+		return GetClient(false)
+	}
+	certs, err := security.FetchIPCCertificates()
+	if err != nil {
+		log.Debugf("Unable to read the IPC certificates, using the auth token only: %v", err)
+		return GetClient(false)
+	}
+
+	tr := &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:      certs.CAPool,
+			Certificates: []tls.Certificate{certs.Client},
+		},
+	}
+
+	return &http.Client{Transport: tr}
+}
+
 // DoGet is a wrapper around performing HTTP GET requests
 func DoGet(c *http.Client, url string) (body []byte, e error) {
+	return DoGetWithToken(c, url, GetAuthToken())
+}
+
+// DoGetWithToken is DoGet authenticating with the given token instead of the session one
+func DoGetWithToken(c *http.Client, url string, token string) (body []byte, e error) {
 	req, e := http.NewRequest("GET", url, nil)
 	if e != nil {
 		return body, e
 	}
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	r, e := c.Do(req)
 	if e != nil {
@@ -53,11 +87,39 @@ func DoGet(c *http.Client, url string) (body []byte, e error) {
 
 // DoPost is a wrapper around performing HTTP POST requests
 func DoPost(c *http.Client, url string, contentType string, body io.Reader) (resp []byte, e error) {
+	return DoPostWithToken(c, url, contentType, body, GetAuthToken())
+}
+
+// DoPostWithToken is DoPost authenticating with the given token instead of the session one
+func DoPostWithToken(c *http.Client, url string, contentType string, body io.Reader, token string) (resp []byte, e error) {
 	req, e := http.NewRequest("POST", url, body)
 	if e != nil {
 		return resp, e
 	}
 	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	r, e := c.Do(req)
+	if e != nil {
+		return resp, e
+	}
+	resp, e = ioutil.ReadAll(r.Body)
+	r.Body.Close()
+	if e != nil {
+		return resp, e
+	}
+	if r.StatusCode >= 400 {
+		return resp, fmt.Errorf("%s", resp)
+	}
+	return resp, nil
+}
+
+// DoDelete is a wrapper around performing HTTP DELETE requests
+func DoDelete(c *http.Client, url string) (resp []byte, e error) {
+	req, e := http.NewRequest("DELETE", url, nil)
+	if e != nil {
+		return resp, e
+	}
 	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
 
 	r, e := c.Do(req)
@@ -74,3 +136,38 @@ func DoPost(c *http.Client, url string, contentType string, body io.Reader) (res
 	}
 	return resp, nil
 }
+
+// DoPostChunked is a wrapper around performing HTTP POST requests that stream chunked data,
+// onChunk is called with each chunk of the response body until the response ends.
+func DoPostChunked(c *http.Client, url string, contentType string, body io.Reader, onChunk func([]byte)) error {
+	req, e := http.NewRequest("POST", url, body)
+	if e != nil {
+		return e
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+GetAuthToken())
+
+	r, e := c.Do(req)
+	if e != nil {
+		return e
+	}
+	defer r.Body.Close()
+	if r.StatusCode >= 400 {
+		resp, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("%s", resp)
+	}
+
+	buf := make([]byte, 4096)
+	for {
+		n, e := r.Body.Read(buf)
+		if n > 0 {
+			onChunk(buf[:n])
+		}
+		if e == io.EOF {
+			return nil
+		}
+		if e != nil {
+			return e
+		}
+	}
+}
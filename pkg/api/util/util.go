@@ -16,6 +16,8 @@ import (
 var (
 	token    string
 	dcaToken string
+	// scopedTokens maps the values of the scoped tokens accepted by ValidateScope to their names
+	scopedTokens map[string]string
 )
 
 // SetAuthToken sets the session token
@@ -46,6 +48,27 @@ func CreateAndSetAuthToken() error {
 	return err
 }
 
+// CreateAndSetScopedAuthTokens creates the scoped tokens and sets them as accepted by ValidateScope
+// Requires that the config has been set up before calling
+func CreateAndSetScopedAuthTokens() error {
+	// Noop if the scoped tokens are already set
+	if scopedTokens != nil {
+		return nil
+	}
+
+	tokens := make(map[string]string)
+	for _, name := range security.ScopedTokenNames() {
+		t, err := security.CreateOrFetchScopedToken(name)
+		if err != nil {
+			return err
+		}
+		tokens[t] = name
+	}
+	// scoped tokens are only set once, no need to mutex protect
+	scopedTokens = tokens
+	return nil
+}
+
 // GetAuthToken gets the session token
 func GetAuthToken() string {
 	return token
@@ -72,24 +95,18 @@ func GetDCAAuthToken() string {
 
 // Validate validates an http request
 func Validate(w http.ResponseWriter, r *http.Request) error {
-	var err error
-	auth := r.Header.Get("Authorization")
-	if auth == "" {
-		w.Header().Set("WWW-Authenticate", `Bearer realm="Datadog Agent"`)
-		err = fmt.Errorf("no session token provided")
-		http.Error(w, err.Error(), 401)
-		return err
+	if hasVerifiedClientCert(r) {
+		return nil
 	}
 
-	tok := strings.Split(auth, " ")
-	if tok[0] != "Bearer" {
+	tok, err := bearerToken(r)
+	if err != nil {
 		w.Header().Set("WWW-Authenticate", `Bearer realm="Datadog Agent"`)
-		err = fmt.Errorf("unsupported authorization scheme: %s", tok[0])
 		http.Error(w, err.Error(), 401)
 		return err
 	}
 
-	if len(tok) < 2 || tok[1] != GetAuthToken() {
+	if tok != GetAuthToken() {
 		err = fmt.Errorf("invalid session token")
 		http.Error(w, err.Error(), 403)
 	}
@@ -97,6 +114,48 @@ func Validate(w http.ResponseWriter, r *http.Request) error {
 	return err
 }
 
+// ValidateScope validates an http request to an endpoint of the given scope. A request with a
+// scoped token is only granted the scopes of the token, even when authenticated by mTLS. The
+// other requests are validated like with Validate and are granted every scope.
+func ValidateScope(w http.ResponseWriter, r *http.Request, scope security.Scope) error {
+	tok, err := bearerToken(r)
+	if name, found := scopedTokens[tok]; err == nil && found {
+		if security.TokenHasScope(name, scope) {
+			return nil
+		}
+		err = fmt.Errorf("the %s token doesn't grant the %s scope", name, scope)
+		http.Error(w, err.Error(), 403)
+		return err
+	}
+
+	return Validate(w, r)
+}
+
+// hasVerifiedClientCert returns whether the request is authenticated by mTLS: the client presented
+// a certificate issued by the IPC CA. The servers that don't verify the client certificates never
+// have verified chains.
+func hasVerifiedClientCert(r *http.Request) bool {
+	return r.TLS != nil && len(r.TLS.VerifiedChains) > 0
+}
+
+// bearerToken returns the token of the Authorization header of an http request
+func bearerToken(r *http.Request) (string, error) {
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return "", fmt.Errorf("no session token provided")
+	}
+
+	tok := strings.Split(auth, " ")
+	if tok[0] != "Bearer" {
+		return "", fmt.Errorf("unsupported authorization scheme: %s", tok[0])
+	}
+
+	if len(tok) < 2 {
+		return "", nil
+	}
+	return tok[1], nil
+}
+
 // ValidateDCARequest is used for the exposed endpoints of the DCA.
 // It is different from Validate as we want to have different validations.
 func ValidateDCARequest(w http.ResponseWriter, r *http.Request) error {
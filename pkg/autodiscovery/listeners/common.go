@@ -109,10 +109,15 @@ func standardTagsDigest(labels map[string]string) string {
 	return strconv.FormatUint(h.Sum64(), 16)
 }
 
-// isServiceAnnotated returns true if the Service has an annotation with a given key
-func isServiceAnnotated(ksvc *v1.Service, annotationKey string) bool {
-	if ksvc != nil {
-		if _, found := ksvc.GetAnnotations()[annotationKey]; found {
+// isServiceAnnotated returns true if the Service has a check annotation with a given prefix,
+// either in the instances format or in the checks JSON format
+func isServiceAnnotated(ksvc *v1.Service, annotationPrefix string) bool {
+	if ksvc == nil {
+		return false
+	}
+	annotations := ksvc.GetAnnotations()
+	for _, key := range []string{"instances", "checks"} {
+		if _, found := annotations[annotationPrefix+key]; found {
 			return true
 		}
 	}
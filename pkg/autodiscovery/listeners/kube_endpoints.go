@@ -26,7 +26,7 @@ import (
 )
 
 const (
-	kubeEndpointsAnnotationFormat = "ad.datadoghq.com/endpoints.instances"
+	kubeEndpointsAnnotationPrefix = "ad.datadoghq.com/endpoints."
 	leaderAnnotation              = "control-plane.alpha.kubernetes.io/leader"
 )
 
@@ -180,12 +180,12 @@ func (l *KubeEndpointsListener) serviceUpdated(old, obj interface{}) {
 	}
 
 	// Detect if new annotations are added
-	if !isServiceAnnotated(castedOld, kubeEndpointsAnnotationFormat) && isServiceAnnotated(castedObj, kubeEndpointsAnnotationFormat) {
+	if !isServiceAnnotated(castedOld, kubeEndpointsAnnotationPrefix) && isServiceAnnotated(castedObj, kubeEndpointsAnnotationPrefix) {
 		l.createService(l.endpointsForService(castedObj), true, false)
 	}
 
 	// Detect changes of AD labels for standard tags if the Service is annotated
-	if isServiceAnnotated(castedObj, kubeEndpointsAnnotationFormat) && (standardTagsDigest(castedOld.GetLabels()) != standardTagsDigest(castedObj.GetLabels())) {
+	if isServiceAnnotated(castedObj, kubeEndpointsAnnotationPrefix) && (standardTagsDigest(castedOld.GetLabels()) != standardTagsDigest(castedObj.GetLabels())) {
 		kep := l.endpointsForService(castedObj)
 		l.removeService(kep)
 		l.createService(kep, true, false)
@@ -224,7 +224,7 @@ func (l *KubeEndpointsListener) endpointsDiffer(first, second *v1.Endpoints) boo
 	}
 
 	// AD annotations on the corresponding services
-	if isServiceAnnotated(ksvcFirst, kubeEndpointsAnnotationFormat) != isServiceAnnotated(ksvcSecond, kubeEndpointsAnnotationFormat) {
+	if isServiceAnnotated(ksvcFirst, kubeEndpointsAnnotationPrefix) != isServiceAnnotated(ksvcSecond, kubeEndpointsAnnotationPrefix) {
 		return true
 	}
 
@@ -250,7 +250,7 @@ func (l *KubeEndpointsListener) isEndpointsAnnotated(kep *v1.Endpoints) bool {
 	if err != nil {
 		log.Tracef("Cannot get Kubernetes service: %s", err)
 	}
-	return isServiceAnnotated(ksvc, kubeEndpointsAnnotationFormat)
+	return isServiceAnnotated(ksvc, kubeEndpointsAnnotationPrefix)
 }
 
 func (l *KubeEndpointsListener) createService(kep *v1.Endpoints, alreadyExistingService, checkServiceAnnotations bool) {
@@ -26,7 +26,7 @@ import (
 )
 
 const (
-	kubeServiceAnnotationFormat = "ad.datadoghq.com/service.instances"
+	kubeServiceAnnotationPrefix = "ad.datadoghq.com/service."
 )
 
 // KubeServiceListener listens to kubernetes service creation
@@ -144,7 +144,7 @@ func servicesDiffer(first, second *v1.Service) bool {
 		return false
 	}
 	// AD annotations - check templates
-	if isServiceAnnotated(first, kubeServiceAnnotationFormat) != isServiceAnnotated(second, kubeServiceAnnotationFormat) {
+	if isServiceAnnotated(first, kubeServiceAnnotationPrefix) != isServiceAnnotated(second, kubeServiceAnnotationPrefix) {
 		return true
 	}
 	// AD labels - standard tags
@@ -175,7 +175,7 @@ func (l *KubeServiceListener) createService(ksvc *v1.Service, firstRun bool) {
 	if ksvc == nil {
 		return
 	}
-	if !isServiceAnnotated(ksvc, kubeServiceAnnotationFormat) {
+	if !isServiceAnnotated(ksvc, kubeServiceAnnotationPrefix) {
 		// Ignore services with no AD annotation
 		return
 	}
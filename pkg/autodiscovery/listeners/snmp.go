@@ -276,6 +276,7 @@ func (l *SNMPListener) createService(entityID string, subnet *snmpSubnet, device
 	}
 	l.services[entityID] = svc
 	subnet.devices[entityID] = deviceIP
+	snmp.SetDeviceTags(deviceIP, []string{"snmp_device:" + deviceIP, "autodiscovery_subnet:" + subnet.config.Network})
 	subnet.deviceFailures[entityID] = 0
 	if writeCache {
 		l.writeCache(subnet)
@@ -298,6 +299,7 @@ func (l *SNMPListener) deleteService(entityID string, subnet *snmpSubnet) {
 
 		if l.config.AllowedFailures != -1 && failure >= l.config.AllowedFailures {
 			l.delService <- svc
+			snmp.DeleteDeviceTags(subnet.devices[entityID])
 			delete(l.services, entityID)
 			delete(subnet.devices, entityID)
 			l.writeCache(subnet)
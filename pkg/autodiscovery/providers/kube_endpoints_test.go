@@ -63,6 +63,32 @@ func TestParseKubeServiceAnnotationsForEndpoints(t *testing.T) {
 				},
 			},
 		},
+		{
+			service: &v1.Service{
+				ObjectMeta: metav1.ObjectMeta{
+					UID: types.UID("test"),
+					Annotations: map[string]string{
+						"ad.datadoghq.com/endpoints.checks": `{"http_check": {"init_config": {}, "instances": [{"name": "My endpoint", "url": "http://%%host%%", "timeout": 1}]}}`,
+					},
+					Name:      "myservice",
+					Namespace: "default",
+				},
+			},
+			expectedOut: []configInfo{
+				{
+					tpl: integration.Config{
+						Name:          "http_check",
+						ADIdentifiers: []string{"kube_endpoint_uid://default/myservice/"},
+						InitConfig:    integration.Data("{}"),
+						Instances:     []integration.Data{integration.Data("{\"name\":\"My endpoint\",\"timeout\":1,\"url\":\"http://%%host%%\"}")},
+						ClusterCheck:  false,
+						Source:        "kube_endpoints:kube_endpoint_uid://default/myservice/",
+					},
+					namespace: "default",
+					name:      "myservice",
+				},
+			},
+		},
 	} {
 		t.Run(fmt.Sprintf(""), func(t *testing.T) {
 			cfgs := parseServiceAnnotationsForEndpoints([]*v1.Service{tc.service})
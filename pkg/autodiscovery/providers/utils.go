@@ -10,6 +10,7 @@ import (
 	"errors"
 	"fmt"
 	"path"
+	"sort"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -22,6 +23,7 @@ const (
 	checkNamePath  string = "check_names"
 	initConfigPath string = "init_configs"
 	logsConfigPath string = "logs"
+	checksPath     string = "checks"
 )
 
 func init() {
@@ -151,7 +153,16 @@ func extractTemplatesFromMap(key string, input map[string]string, prefix string)
 }
 
 // extractCheckTemplatesFromMap returns all the check configurations from a given map.
+// The checks JSON format takes precedence over the check_names, init_configs and instances format.
 func extractCheckTemplatesFromMap(key string, input map[string]string, prefix string) ([]integration.Config, error) {
+	if value, found := input[prefix+checksPath]; found {
+		configs, err := parseChecksJSON(key, value)
+		if err != nil {
+			return []integration.Config{}, fmt.Errorf("in %s: %s", checksPath, err)
+		}
+		return configs, nil
+	}
+
 	value, found := input[prefix+checkNamePath]
 	if !found {
 		return []integration.Config{}, nil
@@ -182,6 +193,54 @@ func extractCheckTemplatesFromMap(key string, input map[string]string, prefix st
 	return buildTemplates(key, checkNames, initConfigs, instances), nil
 }
 
+// parseChecksJSON parses the checks JSON format, an object mapping the check names to their
+// init_config and instances, e.g.
+// {"http_check": {"init_config": {}, "instances": [{"url": "http://%%host%%"}]}}
+// It returns a template per instance, like the other annotation formats.
+func parseChecksJSON(key string, value string) ([]integration.Config, error) {
+	var checks map[string]struct {
+		InitConfig interface{}   `json:"init_config"`
+		Instances  []interface{} `json:"instances"`
+	}
+	if err := json.Unmarshal([]byte(value), &checks); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal JSON: %s", err)
+	}
+
+	checkNames := make([]string, 0, len(checks))
+	for name := range checks {
+		checkNames = append(checkNames, name)
+	}
+	sort.Strings(checkNames)
+
+	templates := make([]integration.Config, 0)
+	for _, name := range checkNames {
+		check := checks[name]
+		initConfig := integration.Data("{}")
+		if check.InitConfig != nil {
+			var err error
+			if initConfig, err = parseJSONObjToData(check.InitConfig); err != nil {
+				return nil, fmt.Errorf("invalid init_config of check %s: %s", name, err)
+			}
+		}
+		if len(check.Instances) == 0 {
+			return nil, fmt.Errorf("missing instances for check %s", name)
+		}
+		for _, i := range check.Instances {
+			instance, err := parseJSONObjToData(i)
+			if err != nil {
+				return nil, fmt.Errorf("invalid instance of check %s: %s", name, err)
+			}
+			templates = append(templates, integration.Config{
+				Name:          name,
+				InitConfig:    initConfig,
+				Instances:     []integration.Data{instance},
+				ADIdentifiers: []string{key},
+			})
+		}
+	}
+	return templates, nil
+}
+
 // extractLogsTemplatesFromMap returns the logs configuration from a given map,
 // if none are found return an empty list.
 func extractLogsTemplatesFromMap(key string, input map[string]string, prefix string) ([]integration.Config, error) {
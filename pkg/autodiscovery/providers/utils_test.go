@@ -294,6 +294,63 @@ func TestExtractTemplatesFromMap(t *testing.T) {
 			prefix:       "prefix.",
 			output:       nil,
 		},
+		{
+			// Checks JSON format, with a default init_config
+			source: map[string]string{
+				"prefix.checks": `{"http_check": {"instances": [{"name": "first", "url": "http://%%host%%"}, {"name": "second", "url": "http://%%host%%:8080"}]}, "apache": {"init_config": {"foo": "bar"}, "instances": [{"apache_status_url": "http://%%host%%/server-status?auto"}]}}`,
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output: []integration.Config{
+				{
+					Name:          "apache",
+					Instances:     []integration.Data{integration.Data(`{"apache_status_url":"http://%%host%%/server-status?auto"}`)},
+					InitConfig:    integration.Data(`{"foo":"bar"}`),
+					ADIdentifiers: []string{"id"},
+				},
+				{
+					Name:          "http_check",
+					Instances:     []integration.Data{integration.Data(`{"name":"first","url":"http://%%host%%"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{"id"},
+				},
+				{
+					Name:          "http_check",
+					Instances:     []integration.Data{integration.Data(`{"name":"second","url":"http://%%host%%:8080"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{"id"},
+				},
+			},
+		},
+		{
+			// Checks JSON format takes precedence over the other format
+			source: map[string]string{
+				"prefix.checks":       `{"http_check": {"instances": [{"url": "http://%%host%%"}]}}`,
+				"prefix.check_names":  "[\"apache\"]",
+				"prefix.init_configs": "[{}]",
+				"prefix.instances":    "[{\"apache_status_url\":\"http://%%host%%/server-status?auto\"}]",
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output: []integration.Config{
+				{
+					Name:          "http_check",
+					Instances:     []integration.Data{integration.Data(`{"url":"http://%%host%%"}`)},
+					InitConfig:    integration.Data("{}"),
+					ADIdentifiers: []string{"id"},
+				},
+			},
+		},
+		{
+			// Checks JSON format without instances, error out
+			source: map[string]string{
+				"prefix.checks": `{"http_check": {"init_config": {}}}`,
+			},
+			adIdentifier: "id",
+			prefix:       "prefix.",
+			output:       nil,
+			errs:         []error{errors.New("could not extract checks config: in checks: missing instances for check http_check")},
+		},
 		{
 			// Missing init_configs, error out
 			source: map[string]string{
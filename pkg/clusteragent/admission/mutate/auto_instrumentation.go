@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission/metrics"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	admiv1beta1 "k8s.io/api/admission/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// libVersionAnnotationKeyFormat is the format of the annotation
+	// requesting the injection of a given tracing library version
+	libVersionAnnotationKeyFormat = "admission.datadoghq.com/%s-lib.version"
+
+	volumeName = "datadog-auto-instrumentation"
+	mountPath  = "/datadog-lib"
+)
+
+// libInfo describes how a tracing library is injected for a given language
+type libInfo struct {
+	// image is the name of the init container image shipping the library
+	image string
+	// envVarName is the env var used by the language runtime to load the library
+	envVarName string
+	// envVarValue is the value of envVarName, pointing to the library in the shared volume
+	envVarValue string
+}
+
+var supportedLibs = map[string]libInfo{
+	"java": {
+		image:       "dd-lib-java-init",
+		envVarName:  "JAVA_TOOL_OPTIONS",
+		envVarValue: " -javaagent:" + mountPath + "/dd-java-agent.jar",
+	},
+	"js": {
+		image:       "dd-lib-js-init",
+		envVarName:  "NODE_OPTIONS",
+		envVarValue: " --require=" + mountPath + "/node_modules/dd-trace/init",
+	},
+	"python": {
+		image:       "dd-lib-python-init",
+		envVarName:  "PYTHONPATH",
+		envVarValue: mountPath + "/",
+	},
+}
+
+// InjectAutoInstrumentation injects the APM tracing libraries requested
+// through pod annotations and the corresponding configuration
+func InjectAutoInstrumentation(req *admiv1beta1.AdmissionRequest, dc dynamic.Interface) (*admiv1beta1.AdmissionResponse, error) {
+	return mutate(req, injectAutoInstrumentation, dc)
+}
+
+// injectAutoInstrumentation adds an init container copying the requested tracing
+// libraries into a shared volume and configures the application containers to load them
+func injectAutoInstrumentation(pod *corev1.Pod, _ string, _ dynamic.Interface) error {
+	var injected bool
+	defer func() {
+		metrics.MutationAttempts.Inc(metrics.LibInjectionMutationType, strconv.FormatBool(injected))
+	}()
+
+	if pod == nil {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "nil pod")
+		return errors.New("cannot inject lib into nil pod")
+	}
+
+	if !shouldInjectConf(pod) {
+		return nil
+	}
+
+	libs := extractLibInfo(pod)
+	if len(libs) == 0 {
+		return nil
+	}
+
+	if len(libs) > 1 {
+		metrics.MutationErrors.Inc(metrics.LibInjectionMutationType, "too many libs")
+		return fmt.Errorf("cannot inject more than one tracing library into pod %s", podString(pod))
+	}
+
+	if injected = injectLib(pod, libs[0]); injected {
+		// Make sure the tracer can reach the agent and report the right entity
+		injectEnv(pod, agentHostEnvVar)
+		injectEnv(pod, ddEntityIDEnvVar)
+	}
+
+	return nil
+}
+
+// injectedLib is a tracing library requested for a pod
+type injectedLib struct {
+	lang    string
+	image   string
+	libInfo libInfo
+}
+
+// extractLibInfo returns the tracing libraries requested in the pod annotations,
+// sorted by language for deterministic results
+func extractLibInfo(pod *corev1.Pod) []injectedLib {
+	libs := []injectedLib{}
+	registry := config.Datadog.GetString("admission_controller.auto_instrumentation.container_registry")
+	for lang, info := range supportedLibs {
+		version, found := pod.GetAnnotations()[fmt.Sprintf(libVersionAnnotationKeyFormat, lang)]
+		if !found {
+			continue
+		}
+		if version == "" {
+			log.Warnf("Ignoring empty %s library version annotation on pod %s", lang, podString(pod))
+			continue
+		}
+		libs = append(libs, injectedLib{
+			lang:    lang,
+			image:   fmt.Sprintf("%s/%s:%s", registry, info.image, version),
+			libInfo: info,
+		})
+	}
+
+	sort.Slice(libs, func(i, j int) bool { return libs[i].lang < libs[j].lang })
+
+	return libs
+}
+
+// injectLib adds the init container, the shared volume and the
+// library loading env var to the pod, returns false if already injected
+func injectLib(pod *corev1.Pod, lib injectedLib) bool {
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == volumeName {
+			log.Debugf("Volume '%s' already exists in pod %s, skipping library injection", volumeName, podString(pod))
+			return false
+		}
+	}
+
+	log.Debugf("Injecting %s library image '%s' into pod %s", lib.lang, lib.image, podString(pod))
+
+	volumeMount := corev1.VolumeMount{
+		Name:      volumeName,
+		MountPath: mountPath,
+	}
+
+	pod.Spec.Volumes = append(pod.Spec.Volumes, corev1.Volume{
+		Name: volumeName,
+		VolumeSource: corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		},
+	})
+
+	pod.Spec.InitContainers = append([]corev1.Container{
+		{
+			Name:         fmt.Sprintf("datadog-lib-%s-init", lib.lang),
+			Image:        lib.image,
+			Command:      []string{"sh", "copy-lib.sh", mountPath},
+			VolumeMounts: []corev1.VolumeMount{volumeMount},
+		},
+	}, pod.Spec.InitContainers...)
+
+	for i, ctr := range pod.Spec.Containers {
+		pod.Spec.Containers[i].VolumeMounts = append(ctr.VolumeMounts, volumeMount)
+		pod.Spec.Containers[i].Env = appendOrPrependEnv(ctr.Env, lib.libInfo.envVarName, lib.libInfo.envVarValue)
+	}
+
+	return true
+}
+
+// appendOrPrependEnv sets the env var to the given value or, if the container
+// already defines it, concatenates the given value to the existing one
+func appendOrPrependEnv(envs []corev1.EnvVar, name, value string) []corev1.EnvVar {
+	for i, env := range envs {
+		if env.Name != name {
+			continue
+		}
+		if env.ValueFrom != nil {
+			log.Debugf("Ignoring env var '%s' defined from a source, cannot concatenate the library path", name)
+			return envs
+		}
+		if name == "PYTHONPATH" {
+			envs[i].Value = value + ":" + env.Value
+		} else {
+			envs[i].Value = env.Value + value
+		}
+		return envs
+	}
+
+	return append(envs, corev1.EnvVar{Name: name, Value: strings.TrimLeft(value, " ")})
+}
@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package mutate
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func fakePodWithAnnotation(k, v string) *corev1.Pod {
+	pod := fakePodWithContainer("foo-pod", corev1.Container{Name: "foo-container"})
+	pod.Annotations = map[string]string{k: v}
+	pod.Labels = map[string]string{"admission.datadoghq.com/enabled": "true"}
+	return pod
+}
+
+func Test_extractLibInfo(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.container_registry", "registry")
+	tests := []struct {
+		name string
+		pod  *corev1.Pod
+		want []string
+	}{
+		{
+			name: "java",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1"),
+			want: []string{"registry/dd-lib-java-init:v1"},
+		},
+		{
+			name: "unknown language",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/cobol-lib.version", "v1"),
+			want: []string{},
+		},
+		{
+			name: "empty version",
+			pod:  fakePodWithAnnotation("admission.datadoghq.com/python-lib.version", ""),
+			want: []string{},
+		},
+		{
+			name: "no annotation",
+			pod:  fakePod("foo-pod"),
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			images := []string{}
+			for _, lib := range extractLibInfo(tt.pod) {
+				images = append(images, lib.image)
+			}
+			if !reflect.DeepEqual(images, tt.want) {
+				t.Errorf("extractLibInfo() = %v, want %v", images, tt.want)
+			}
+		})
+	}
+}
+
+func Test_injectAutoInstrumentation(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.auto_instrumentation.container_registry", "registry")
+	mockConfig.Set("admission_controller.mutate_unlabelled", false)
+
+	pod := fakePodWithAnnotation("admission.datadoghq.com/java-lib.version", "v1")
+	pod.Spec.Containers[0].Env = []corev1.EnvVar{fakeEnvWithValue("JAVA_TOOL_OPTIONS", "-Xmx1g")}
+	if err := injectAutoInstrumentation(pod, "", nil); err != nil {
+		t.Fatalf("injectAutoInstrumentation() error = %v", err)
+	}
+
+	if len(pod.Spec.InitContainers) != 1 || pod.Spec.InitContainers[0].Image != "registry/dd-lib-java-init:v1" {
+		t.Errorf("injectAutoInstrumentation() init containers = %v", pod.Spec.InitContainers)
+	}
+	if len(pod.Spec.Volumes) != 1 || pod.Spec.Volumes[0].Name != volumeName {
+		t.Errorf("injectAutoInstrumentation() volumes = %v", pod.Spec.Volumes)
+	}
+
+	ctr := pod.Spec.Containers[0]
+	if len(ctr.VolumeMounts) != 1 || ctr.VolumeMounts[0].MountPath != mountPath {
+		t.Errorf("injectAutoInstrumentation() volume mounts = %v", ctr.VolumeMounts)
+	}
+	if ctr.Env[0].Value != "-Xmx1g -javaagent:/datadog-lib/dd-java-agent.jar" {
+		t.Errorf("injectAutoInstrumentation() JAVA_TOOL_OPTIONS = %q", ctr.Env[0].Value)
+	}
+	if !contains(ctr.Env, agentHostEnvVarName) || !contains(ctr.Env, ddEntityIDEnvVarName) {
+		t.Errorf("injectAutoInstrumentation() should inject the agent config, got env %v", ctr.Env)
+	}
+
+	// A second mutation must be a no-op
+	if err := injectAutoInstrumentation(pod, "", nil); err != nil {
+		t.Fatalf("injectAutoInstrumentation() error = %v", err)
+	}
+	if len(pod.Spec.InitContainers) != 1 || len(pod.Spec.Volumes) != 1 {
+		t.Errorf("injectAutoInstrumentation() should not inject twice, got %v", pod.Spec)
+	}
+}
+
+func Test_injectAutoInstrumentationDisabledPod(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("admission_controller.mutate_unlabelled", true)
+
+	pod := fakePodWithAnnotation("admission.datadoghq.com/js-lib.version", "v1")
+	pod.Labels["admission.datadoghq.com/enabled"] = "false"
+	if err := injectAutoInstrumentation(pod, "", nil); err != nil {
+		t.Fatalf("injectAutoInstrumentation() error = %v", err)
+	}
+	if len(pod.Spec.InitContainers) != 0 {
+		t.Errorf("injectAutoInstrumentation() should ignore disabled pods, got %v", pod.Spec.InitContainers)
+	}
+}
+
+func Test_appendOrPrependEnv(t *testing.T) {
+	tests := []struct {
+		name  string
+		envs  []corev1.EnvVar
+		key   string
+		value string
+		want  []corev1.EnvVar
+	}{
+		{
+			name:  "new env var",
+			envs:  []corev1.EnvVar{},
+			key:   "NODE_OPTIONS",
+			value: " --require=/datadog-lib/node_modules/dd-trace/init",
+			want:  []corev1.EnvVar{fakeEnvWithValue("NODE_OPTIONS", "--require=/datadog-lib/node_modules/dd-trace/init")},
+		},
+		{
+			name:  "prepend python path",
+			envs:  []corev1.EnvVar{fakeEnvWithValue("PYTHONPATH", "/app")},
+			key:   "PYTHONPATH",
+			value: "/datadog-lib/",
+			want:  []corev1.EnvVar{fakeEnvWithValue("PYTHONPATH", "/datadog-lib/:/app")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := appendOrPrependEnv(tt.envs, tt.key, tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appendOrPrependEnv() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	admiv1beta1 "k8s.io/api/admissionregistration/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,25 +26,7 @@ func generateWebhooks() []admiv1beta1.MutatingWebhook {
 	// DD_AGENT_HOST injection
 	if config.Datadog.GetBool("admission_controller.inject_config.enabled") {
 		webhook := getWebhookSkeleton("config", config.Datadog.GetString("admission_controller.inject_config.endpoint"))
-		if config.Datadog.GetBool("admission_controller.mutate_unlabelled") {
-			// Accept all, ignore pods if they're explicitly filtered-out
-			webhook.ObjectSelector = &metav1.LabelSelector{
-				MatchExpressions: []metav1.LabelSelectorRequirement{
-					{
-						Key:      EnabledLabelKey,
-						Operator: metav1.LabelSelectorOpNotIn,
-						Values:   []string{"false"},
-					},
-				},
-			}
-		} else {
-			// Ignore all, accept pods if they're explicitly whitelisted
-			webhook.ObjectSelector = &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					EnabledLabelKey: "true",
-				},
-			}
-		}
+		webhook.ObjectSelector = getLabelledObjectSelector()
 		webhooks = append(webhooks, webhook)
 	}
 
@@ -63,11 +46,68 @@ func generateWebhooks() []admiv1beta1.MutatingWebhook {
 		webhooks = append(webhooks, webhook)
 	}
 
+	// APM tracing libraries injection
+	if config.Datadog.GetBool("admission_controller.auto_instrumentation.enabled") {
+		webhook := getWebhookSkeleton("auto.instru", config.Datadog.GetString("admission_controller.auto_instrumentation.endpoint"))
+		webhook.ObjectSelector = getLabelledObjectSelector()
+		webhooks = append(webhooks, webhook)
+	}
+
 	return webhooks
 }
 
+// getLabelledObjectSelector returns the object selector of the webhooks
+// that mutate pods depending on the admission controller label
+func getLabelledObjectSelector() *metav1.LabelSelector {
+	if config.Datadog.GetBool("admission_controller.mutate_unlabelled") {
+		// Accept all, ignore pods if they're explicitly filtered-out
+		return &metav1.LabelSelector{
+			MatchExpressions: []metav1.LabelSelectorRequirement{
+				{
+					Key:      EnabledLabelKey,
+					Operator: metav1.LabelSelectorOpNotIn,
+					Values:   []string{"false"},
+				},
+			},
+		}
+	}
+
+	// Ignore all, accept pods if they're explicitly whitelisted
+	return &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			EnabledLabelKey: "true",
+		},
+	}
+}
+
+// getNamespaceSelector returns the namespace selector of the webhooks
+// based on the cluster agent config, nil means all namespaces are accepted
+func getNamespaceSelector() *metav1.LabelSelector {
+	matchLabels := config.Datadog.GetStringMapString("admission_controller.namespace_selector")
+	if len(matchLabels) == 0 {
+		return nil
+	}
+
+	return &metav1.LabelSelector{
+		MatchLabels: matchLabels,
+	}
+}
+
+// getFailurePolicy returns the failure policy of the webhooks based on the
+// cluster agent config, it falls back to Ignore on invalid values
+func getFailurePolicy() admiv1beta1.FailurePolicyType {
+	policy := admiv1beta1.FailurePolicyType(config.Datadog.GetString("admission_controller.failure_policy"))
+	switch policy {
+	case admiv1beta1.Ignore, admiv1beta1.Fail:
+		return policy
+	default:
+		log.Warnf("Invalid admission_controller.failure_policy value %q, should be either %q or %q, falling back to %q", policy, admiv1beta1.Ignore, admiv1beta1.Fail, admiv1beta1.Ignore)
+		return admiv1beta1.Ignore
+	}
+}
+
 func getWebhookSkeleton(nameSuffix, path string) admiv1beta1.MutatingWebhook {
-	failurePolicy := admiv1beta1.Ignore
+	failurePolicy := getFailurePolicy()
 	sideEffects := admiv1beta1.SideEffectClassNone
 	servicePort := int32(443)
 	return admiv1beta1.MutatingWebhook{
@@ -92,7 +132,8 @@ func getWebhookSkeleton(nameSuffix, path string) admiv1beta1.MutatingWebhook {
 				},
 			},
 		},
-		FailurePolicy: &failurePolicy,
-		SideEffects:   &sideEffects,
+		FailurePolicy:     &failurePolicy,
+		SideEffects:       &sideEffects,
+		NamespaceSelector: getNamespaceSelector(),
 	}
 }
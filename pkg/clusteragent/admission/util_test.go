@@ -163,6 +163,24 @@ func Test_generateWebhooks(t *testing.T) {
 				return []admiv1beta1.MutatingWebhook{webhookConfig, webhookTags}
 			},
 		},
+		{
+			name: "auto instrumentation, mutate labelled",
+			setupConfig: func() {
+				mockConfig.Set("admission_controller.inject_config.enabled", false)
+				mockConfig.Set("admission_controller.inject_tags.enabled", false)
+				mockConfig.Set("admission_controller.auto_instrumentation.enabled", true)
+				mockConfig.Set("admission_controller.mutate_unlabelled", false)
+			},
+			want: func() []admiv1beta1.MutatingWebhook {
+				webhook := getWebhookSkeleton("auto.instru", "/injectlib")
+				webhook.ObjectSelector = &metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"admission.datadoghq.com/enabled": "true",
+					},
+				}
+				return []admiv1beta1.MutatingWebhook{webhook}
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -173,3 +191,66 @@ func Test_generateWebhooks(t *testing.T) {
 		})
 	}
 }
+
+func Test_getFailurePolicy(t *testing.T) {
+	mockConfig := config.Mock()
+	tests := []struct {
+		name   string
+		policy string
+		want   admiv1beta1.FailurePolicyType
+	}{
+		{
+			name:   "ignore",
+			policy: "Ignore",
+			want:   admiv1beta1.Ignore,
+		},
+		{
+			name:   "fail",
+			policy: "Fail",
+			want:   admiv1beta1.Fail,
+		},
+		{
+			name:   "invalid value",
+			policy: "foo",
+			want:   admiv1beta1.Ignore,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig.Set("admission_controller.failure_policy", tt.policy)
+			if got := getFailurePolicy(); got != tt.want {
+				t.Errorf("getFailurePolicy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getNamespaceSelector(t *testing.T) {
+	mockConfig := config.Mock()
+	tests := []struct {
+		name     string
+		selector map[string]string
+		want     *metav1.LabelSelector
+	}{
+		{
+			name:     "no selector",
+			selector: map[string]string{},
+			want:     nil,
+		},
+		{
+			name:     "match labels",
+			selector: map[string]string{"team": "foo"},
+			want: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"team": "foo"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConfig.Set("admission_controller.namespace_selector", tt.selector)
+			if got := getNamespaceSelector(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getNamespaceSelector() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -50,6 +50,27 @@ func (h *Handler) GetState() (types.StateResponse, error) {
 	}
 }
 
+// Rebalance triggers a rebalancing of the cluster checks, for the clusterchecks cmd.
+// It requires advanced dispatching to be enabled as moves are based on the runner stats.
+func (h *Handler) Rebalance() (types.RebalanceResponse, error) {
+	// the runner stats are collected over HTTP, don't hold the lock meanwhile
+	h.m.RLock()
+	state := h.state
+	h.m.RUnlock()
+
+	switch state {
+	case leader:
+		if !h.dispatcher.advancedDispatching {
+			return types.RebalanceResponse{NotRunning: "advanced dispatching is not enabled"}, nil
+		}
+		return types.RebalanceResponse{Moves: h.dispatcher.updateStatsAndRebalance()}, nil
+	case follower:
+		return types.RebalanceResponse{NotRunning: "currently follower"}, nil
+	default:
+		return types.RebalanceResponse{NotRunning: notReadyReason}, nil
+	}
+}
+
 // GetConfigs returns configurations dispatched to a given node
 func (h *Handler) GetConfigs(nodeName string) (types.ConfigResponse, error) {
 	configs, lastChange, err := h.dispatcher.getNodeConfigs(nodeName)
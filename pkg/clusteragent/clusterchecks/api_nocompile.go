@@ -29,6 +29,11 @@ func (h *Handler) GetState() (types.StateResponse, error) {
 	return types.StateResponse{}, ErrNotCompiled
 }
 
+// Rebalance not implemented
+func (h *Handler) Rebalance() (types.RebalanceResponse, error) {
+	return types.RebalanceResponse{}, ErrNotCompiled
+}
+
 // NewHandler not implemented
 func NewHandler(_ *autodiscovery.AutoConfig) (*Handler, error) {
 	return nil, ErrNotCompiled
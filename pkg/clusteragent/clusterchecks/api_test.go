@@ -9,9 +9,17 @@ package clusterchecks
 
 import (
 	"net/http"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/version"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestShouldHandle(t *testing.T) {
@@ -37,3 +45,133 @@ func TestShouldHandle(t *testing.T) {
 	assert.Equal(t, http.StatusFound, code)
 	assert.Equal(t, "1.2.3.4:5005", reason)
 }
+
+func TestHandlerRebalance(t *testing.T) {
+	dispatcher := newDispatcher()
+	h := &Handler{
+		port:       5005,
+		dispatcher: dispatcher,
+	}
+
+	// Not ready
+	resp, err := h.Rebalance()
+	require.NoError(t, err)
+	assert.Equal(t, notReadyReason, resp.NotRunning)
+
+	// Follower
+	h.state = follower
+	resp, err = h.Rebalance()
+	require.NoError(t, err)
+	assert.Equal(t, "currently follower", resp.NotRunning)
+
+	// Leader without advanced dispatching
+	h.state = leader
+	dispatcher.advancedDispatching = false
+	resp, err = h.Rebalance()
+	require.NoError(t, err)
+	assert.Equal(t, "advanced dispatching is not enabled", resp.NotRunning)
+
+	// Leader with advanced dispatching, the runner stats are set up by hand
+	dispatcher.advancedDispatching = true
+	dispatcher.store.active = true
+	dispatcher.store.nodes["busyNode"] = newNodeStore("busyNode", "") // no need to setup the clientIP in this test
+	dispatcher.store.nodes["idleNode"] = newNodeStore("idleNode", "")
+	stats := types.CLCRunnersStats{}
+	for _, name := range []string{"check1", "check2"} {
+		config := integration.Config{
+			Name: name,
+			Instances: []integration.Data{
+				integration.Data(""),
+			},
+			InitConfig: integration.Data(""),
+		}
+		id := check.BuildID(config.Name, config.Instances[0], config.InitConfig)
+		dispatcher.addConfig(config, "busyNode")
+		stats[string(id)] = types.CLCRunnerStats{
+			AverageExecutionTime: 500,
+			MetricSamples:        10,
+			IsClusterCheck:       true,
+		}
+	}
+	dispatcher.store.nodes["busyNode"].clcRunnerStats = stats
+
+	// Concurrent rebalancing must not move the same check twice
+	var wg sync.WaitGroup
+	var m sync.Mutex
+	var moves []types.CheckMove
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := h.Rebalance()
+			assert.NoError(t, err)
+			assert.Empty(t, resp.NotRunning)
+			m.Lock()
+			moves = append(moves, resp.Moves...)
+			m.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	require.Len(t, moves, 1)
+	assert.Equal(t, "busyNode", moves[0].SourceNode)
+	assert.Equal(t, "idleNode", moves[0].DestNode)
+	assert.Len(t, dispatcher.store.nodes["busyNode"].clcRunnerStats, 1)
+	assert.Len(t, dispatcher.store.nodes["idleNode"].clcRunnerStats, 1)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
+// blockingClcRunnerClient blocks the runner stats calls until it's released
+type blockingClcRunnerClient struct {
+	called  chan struct{}
+	release chan struct{}
+}
+
+func (c *blockingClcRunnerClient) GetVersion(IP string) (version.Version, error) {
+	return version.Version{}, nil
+}
+
+func (c *blockingClcRunnerClient) GetRunnerStats(IP string) (types.CLCRunnersStats, error) {
+	c.called <- struct{}{}
+	<-c.release
+	return types.CLCRunnersStats{}, nil
+}
+
+func TestHandlerRebalanceReleasesLock(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.advancedDispatching = true
+	dispatcher.store.active = true
+	dispatcher.store.nodes["node"] = newNodeStore("node", "10.0.0.1")
+	client := &blockingClcRunnerClient{called: make(chan struct{}, 1), release: make(chan struct{})}
+	dispatcher.clcRunnersClient = client
+	h := &Handler{
+		port:       5005,
+		dispatcher: dispatcher,
+		state:      leader,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := h.Rebalance()
+		assert.NoError(t, err)
+	}()
+	<-client.called
+
+	// the leadership can change while the runner stats are collected
+	locked := make(chan struct{})
+	go func() {
+		h.m.Lock()
+		h.m.Unlock()
+		close(locked)
+	}()
+	select {
+	case <-locked:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the handler is locked during the runner stats calls")
+	}
+
+	close(client.release)
+	<-done
+}
@@ -31,10 +31,13 @@ func (d *dispatcher) getState() (types.StateResponse, error) {
 		Dangling: makeConfigArray(d.store.danglingConfigs),
 	}
 	for _, node := range d.store.nodes {
+		node.RLock()
 		n := types.StateNodeResponse{
-			Name:    node.name,
-			Configs: makeConfigArray(node.digestToConfig),
+			Name:     node.name,
+			Configs:  makeConfigArray(node.digestToConfig),
+			Busyness: node.busyness,
 		}
+		node.RUnlock()
 		response.Nodes = append(response.Nodes, n)
 	}
 
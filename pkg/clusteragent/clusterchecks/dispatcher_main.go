@@ -10,6 +10,7 @@ package clusterchecks
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
@@ -31,6 +32,7 @@ type dispatcher struct {
 	extraTags             []string
 	clcRunnersClient      clusteragent.CLCRunnerClientInterface
 	advancedDispatching   bool
+	rebalanceMutex        sync.Mutex // serializes the periodic and the on-demand rebalancing
 }
 
 func newDispatcher() *dispatcher {
@@ -196,10 +198,7 @@ func (d *dispatcher) run(ctx context.Context) {
 
 			// Update runner stats and rebalance if needed
 			if d.advancedDispatching {
-				// Collect CLC runners stats and update cache
-				d.updateRunnersStats()
-				// Rebalance checks distribution
-				d.rebalance()
+				d.updateStatsAndRebalance()
 			}
 		}
 	}
@@ -12,6 +12,7 @@ import (
 	"sort"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks/types"
 	le "github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection/metrics"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -161,19 +162,32 @@ func (d *dispatcher) moveCheck(src, dest, checkID string) error {
 	return nil
 }
 
+// updateStatsAndRebalance collects the CLC runners stats and rebalances the checks distribution.
+// The periodic and the on-demand rebalancing are serialized so that they do not compute
+// their moves from the same stats and move the same checks twice.
+func (d *dispatcher) updateStatsAndRebalance() []types.CheckMove {
+	d.rebalanceMutex.Lock()
+	defer d.rebalanceMutex.Unlock()
+
+	d.updateRunnersStats()
+	return d.rebalance()
+}
+
 // rebalance tries to optimize the checks repartition on cluster level check
 // runners with less possible check moves based on the runner stats.
-func (d *dispatcher) rebalance() {
+// It returns the list of the checks that were moved.
+func (d *dispatcher) rebalance() []types.CheckMove {
 	start := time.Now()
 	defer func() {
 		rebalancingDuration.Set(time.Since(start).Seconds(), le.JoinLeaderValue)
 	}()
 
 	log.Trace("Trying to rebalance cluster checks distribution if needed")
+	moves := []types.CheckMove{}
 	totalAvg, err := d.calculateAvg()
 	if err != nil {
 		log.Debugf("Cannot rebalance checks: %v", err)
-		return
+		return moves
 	}
 	diffMap, weights := d.getDiffAndWeights(totalAvg)
 	sort.Sort(weights)
@@ -200,6 +214,12 @@ func (d *dispatcher) rebalance() {
 				}
 
 				successfulRebalancing.Inc(le.JoinLeaderValue)
+				moves = append(moves, types.CheckMove{
+					CheckID:     checkID,
+					CheckWeight: checkWeight,
+					SourceNode:  sourceNodeName,
+					DestNode:    pickedNodeName,
+				})
 				log.Tracef("Check %s with weight %d moved, total avg: %d, source diff: %d, dest diff: %d", checkID, checkWeight, totalAvg, diffMap[sourceNodeName], diffMap[pickedNodeName])
 
 				// diffMap needs to be updated on every check moved
@@ -209,4 +229,6 @@ func (d *dispatcher) rebalance() {
 			}
 		}
 	}
+
+	return moves
 }
@@ -261,6 +261,34 @@ func TestGetLeastBusyNode(t *testing.T) {
 	requireNotLocked(t, dispatcher.store)
 }
 
+func TestGetState(t *testing.T) {
+	dispatcher := newDispatcher()
+	dispatcher.store.active = true
+
+	dispatcher.addConfig(generateIntegration("A"), "node1")
+	dispatcher.addConfig(generateIntegration("B"), "node2")
+	dispatcher.addConfig(generateIntegration("C"), "node2")
+	dispatcher.addConfig(generateIntegration("D"), "")
+	dispatcher.store.nodes["node1"].busyness = 42
+
+	state, err := dispatcher.getState()
+	require.NoError(t, err)
+	assert.False(t, state.Warmup)
+	assert.Equal(t, []string{"D"}, extractCheckNames(state.Dangling))
+
+	nodes := make(map[string]types.StateNodeResponse)
+	for _, node := range state.Nodes {
+		nodes[node.Name] = node
+	}
+	require.Len(t, nodes, 2)
+	assert.Equal(t, []string{"A"}, extractCheckNames(nodes["node1"].Configs))
+	assert.Equal(t, 42, nodes["node1"].Busyness)
+	assert.Equal(t, []string{"B", "C"}, extractCheckNames(nodes["node2"].Configs))
+	assert.Equal(t, defaultBusynessValue, nodes["node2"].Busyness)
+
+	requireNotLocked(t, dispatcher.store)
+}
+
 func TestExpireNodes(t *testing.T) {
 	dispatcher := newDispatcher()
 
@@ -35,8 +35,23 @@ type StateResponse struct {
 
 // StateNodeResponse is a chunk of StateResponse
 type StateNodeResponse struct {
-	Name    string               `json:"name"`
-	Configs []integration.Config `json:"configs"`
+	Name     string               `json:"name"`
+	Configs  []integration.Config `json:"configs"`
+	Busyness int                  `json:"busyness"` // Negative if no runner stats were collected yet
+}
+
+// RebalanceResponse holds the DCA response for a rebalancing request
+type RebalanceResponse struct {
+	NotRunning string      `json:"not_running"` // Reason why not running, empty if leading
+	Moves      []CheckMove `json:"moves"`
+}
+
+// CheckMove describes a check moved from a node to another during rebalancing
+type CheckMove struct {
+	CheckID     string `json:"check_id"`
+	CheckWeight int    `json:"check_weight"`
+	SourceNode  string `json:"source_node"`
+	DestNode    string `json:"dest_node"`
 }
 
 // Stats holds statistics for the agent status command
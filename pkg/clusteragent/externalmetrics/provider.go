@@ -19,6 +19,7 @@ import (
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/common"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver/leaderelection"
@@ -60,6 +61,9 @@ func NewDatadogMetricProvider(ctx context.Context, apiCl *apiserver.APIClient) (
 		autogenNamespace: autogenNamespace,
 	}
 
+	// Cache a pointer to the store for the cluster agent status command
+	cache.Cache.Set(cache.BuildAgentKey(storeCacheKey), &provider.store, cache.NoExpiration)
+
 	// Start MetricsRetriever, only leader will do refresh metrics
 	dogCl, err := autoscalers.NewDatadogClient()
 	if err != nil {
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics/model"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+)
+
+const storeCacheKey = "external_metrics_datadogmetric_store"
+
+// GetStatus returns status info for the DatadogMetric based external metrics provider.
+// It is used for the cluster agent status command.
+func GetStatus() map[string]interface{} {
+	status := make(map[string]interface{})
+
+	key := cache.BuildAgentKey(storeCacheKey)
+	x, found := cache.Cache.Get(key)
+	if !found {
+		status["Error"] = "DatadogMetric provider not running"
+		return status
+	}
+
+	store, ok := x.(*DatadogMetricsInternalStore)
+	if !ok {
+		status["Error"] = "Cache entry is not a valid DatadogMetric store"
+		return status
+	}
+
+	return store.getStatus()
+}
+
+func (ds *DatadogMetricsInternalStore) getStatus() map[string]interface{} {
+	datadogMetrics := ds.GetAll()
+	sort.Slice(datadogMetrics, func(i, j int) bool { return datadogMetrics[i].ID < datadogMetrics[j].ID })
+
+	valid, active, errored := 0, 0, 0
+	metrics := make([]map[string]interface{}, 0, len(datadogMetrics))
+	for _, datadogMetric := range datadogMetrics {
+		if datadogMetric.Valid {
+			valid++
+		}
+		if datadogMetric.Active {
+			active++
+		}
+		if datadogMetric.Error != nil {
+			errored++
+		}
+		metrics = append(metrics, datadogMetricStatus(datadogMetric))
+	}
+
+	return map[string]interface{}{
+		"Total":   len(datadogMetrics),
+		"Valid":   valid,
+		"Active":  active,
+		"Errored": errored,
+		"Metrics": metrics,
+	}
+}
+
+func datadogMetricStatus(datadogMetric model.DatadogMetricInternal) map[string]interface{} {
+	status := map[string]interface{}{
+		"id":         datadogMetric.ID,
+		"query":      datadogMetric.Query,
+		"valid":      datadogMetric.Valid,
+		"active":     datadogMetric.Active,
+		"value":      datadogMetric.Value,
+		"updateTime": datadogMetric.UpdateTime.Format(time.RFC3339),
+	}
+
+	if datadogMetric.Autogen {
+		status["externalMetricName"] = datadogMetric.ExternalMetricName
+	}
+	if datadogMetric.Error != nil {
+		status["error"] = datadogMetric.Error.Error()
+	}
+
+	return status
+}
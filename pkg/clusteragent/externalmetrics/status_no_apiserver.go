@@ -0,0 +1,15 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !kubeapiserver
+
+package externalmetrics
+
+// GetStatus returns status info for the DatadogMetric based external metrics provider.
+func GetStatus() map[string]interface{} {
+	status := make(map[string]interface{})
+	status["Error"] = "The DatadogMetric provider is not compiled-in"
+	return status
+}
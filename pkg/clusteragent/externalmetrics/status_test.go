@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package externalmetrics
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics/model"
+	"github.com/DataDog/datadog-agent/pkg/util/cache"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatus(t *testing.T) {
+	key := cache.BuildAgentKey(storeCacheKey)
+	cache.Cache.Delete(key)
+	defer cache.Cache.Delete(key)
+
+	status := GetStatus()
+	assert.Equal(t, "DatadogMetric provider not running", status["Error"])
+
+	updateTime := time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)
+	store := NewDatadogMetricsInternalStore()
+	store.Set("default/dd-metric-1", model.DatadogMetricInternal{
+		ID:         "default/dd-metric-1",
+		Query:      "avg:nginx.net.request_per_s{app:foo}",
+		Valid:      true,
+		Active:     true,
+		Value:      42,
+		UpdateTime: updateTime,
+	}, "utest")
+	store.Set("default/dd-metric-0", model.DatadogMetricInternal{
+		ID:                 "default/dd-metric-0",
+		Query:              "avg:docker.cpu.usage{app:bar}",
+		Valid:              false,
+		Active:             true,
+		Autogen:            true,
+		ExternalMetricName: "docker.cpu.usage",
+		UpdateTime:         updateTime,
+		Error:              fmt.Errorf(invalidMetricNoDataErrorMessage, "avg:docker.cpu.usage{app:bar}"),
+	}, "utest")
+	cache.Cache.Set(key, &store, cache.NoExpiration)
+
+	status = GetStatus()
+	require.NotContains(t, status, "Error")
+	assert.Equal(t, 2, status["Total"])
+	assert.Equal(t, 1, status["Valid"])
+	assert.Equal(t, 2, status["Active"])
+	assert.Equal(t, 1, status["Errored"])
+
+	metrics, ok := status["Metrics"].([]map[string]interface{})
+	require.True(t, ok)
+	require.Len(t, metrics, 2)
+	assert.Equal(t, map[string]interface{}{
+		"id":                 "default/dd-metric-0",
+		"query":              "avg:docker.cpu.usage{app:bar}",
+		"valid":              false,
+		"active":             true,
+		"value":              0.0,
+		"updateTime":         "2020-06-01T10:00:00Z",
+		"externalMetricName": "docker.cpu.usage",
+		"error":              "No data from backend, query: avg:docker.cpu.usage{app:bar}",
+	}, metrics[0])
+	assert.Equal(t, map[string]interface{}{
+		"id":         "default/dd-metric-1",
+		"query":      "avg:nginx.net.request_per_s{app:foo}",
+		"valid":      true,
+		"active":     true,
+		"value":      42.0,
+		"updateTime": "2020-06-01T10:00:00Z",
+	}, metrics[1])
+}
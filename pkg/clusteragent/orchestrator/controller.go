@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
 	corelisters "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 )
@@ -37,6 +38,7 @@ import (
 type ControllerContext struct {
 	IsLeaderFunc                 func() bool
 	UnassignedPodInformerFactory informers.SharedInformerFactory
+	InformerFactory              informers.SharedInformerFactory
 	Client                       kubernetes.Interface
 	StopCh                       chan struct{}
 	Hostname                     string
@@ -48,6 +50,8 @@ type ControllerContext struct {
 type Controller struct {
 	unassignedPodLister     corelisters.PodLister
 	unassignedPodListerSync cache.InformerSynced
+	deployLister            appslisters.DeploymentLister
+	deployListerSync        cache.InformerSynced
 	groupID                 int32
 	hostName                string
 	clusterName             string
@@ -55,6 +59,8 @@ type Controller struct {
 	forwarder               forwarder.Forwarder
 	processConfig           *processcfg.AgentConfig
 	isLeaderFunc            func() bool
+	// resourceCache keeps the messages extracted from the resources which did not change
+	resourceCache *orchestrator.KubernetesResourceCache
 }
 
 // StartController starts the orchestrator controller
@@ -76,14 +82,17 @@ func StartController(ctx ControllerContext) error {
 	go orchestratorController.Run(ctx.StopCh)
 
 	ctx.UnassignedPodInformerFactory.Start(ctx.StopCh)
+	ctx.InformerFactory.Start(ctx.StopCh)
 
 	return apiserver.SyncInformers(map[apiserver.InformerName]cache.SharedInformer{
-		apiserver.PodsInformer: ctx.UnassignedPodInformerFactory.Core().V1().Pods().Informer(),
+		apiserver.PodsInformer:    ctx.UnassignedPodInformerFactory.Core().V1().Pods().Informer(),
+		apiserver.DeploysInformer: ctx.InformerFactory.Apps().V1().Deployments().Informer(),
 	})
 }
 
 func newController(ctx ControllerContext) (*Controller, error) {
 	podInformer := ctx.UnassignedPodInformerFactory.Core().V1().Pods()
+	deployInformer := ctx.InformerFactory.Apps().V1().Deployments()
 	clusterID, err := clustername.GetClusterID()
 	if err != nil {
 		return nil, err
@@ -105,6 +114,8 @@ func newController(ctx ControllerContext) (*Controller, error) {
 	oc := &Controller{
 		unassignedPodLister:     podInformer.Lister(),
 		unassignedPodListerSync: podInformer.Informer().HasSynced,
+		deployLister:            deployInformer.Lister(),
+		deployListerSync:        deployInformer.Informer().HasSynced,
 		groupID:                 rand.Int31(),
 		hostName:                ctx.Hostname,
 		clusterName:             ctx.ClusterName,
@@ -112,6 +123,7 @@ func newController(ctx ControllerContext) (*Controller, error) {
 		processConfig:           cfg,
 		forwarder:               forwarder.NewDefaultForwarder(podForwarderOpts),
 		isLeaderFunc:            ctx.IsLeaderFunc,
+		resourceCache:           orchestrator.NewKubernetesResourceCache(),
 	}
 
 	oc.processConfig = cfg
@@ -128,11 +140,12 @@ func (o *Controller) Run(stopCh <-chan struct{}) {
 		return
 	}
 
-	if !cache.WaitForCacheSync(stopCh, o.unassignedPodListerSync) {
+	if !cache.WaitForCacheSync(stopCh, o.unassignedPodListerSync, o.deployListerSync) {
 		return
 	}
 
 	go wait.Until(o.processPods, 10*time.Second, stopCh)
+	go wait.Until(o.processDeploys, 10*time.Second, stopCh)
 
 	<-stopCh
 
@@ -157,6 +170,29 @@ func (o *Controller) processPods() {
 		return
 	}
 
+	o.sendMessages(msg, o.forwarder.SubmitPodChecks)
+}
+
+func (o *Controller) processDeploys() {
+	if !o.isLeaderFunc() {
+		return
+	}
+	deployList, err := o.deployLister.List(labels.Everything())
+	if err != nil {
+		log.Errorf("Unable to list deployments: %s", err)
+		return
+	}
+
+	msg, err := orchestrator.ProcessDeploymentList(deployList, atomic.AddInt32(&o.groupID, 1), o.processConfig, o.clusterName, o.clusterID, o.resourceCache)
+	if err != nil {
+		log.Errorf("Unable to process deployment list: %v", err)
+		return
+	}
+
+	o.sendMessages(msg, o.forwarder.SubmitDeploymentChecks)
+}
+
+func (o *Controller) sendMessages(msg []model.MessageBody, submit func(forwarder.Payloads, http.Header) (chan forwarder.Response, error)) {
 	for _, m := range msg {
 		extraHeaders := make(http.Header)
 		extraHeaders.Set(api.HostHeader, o.hostName)
@@ -170,7 +206,7 @@ func (o *Controller) processPods() {
 		}
 
 		payloads := forwarder.Payloads{&body}
-		responses, err := o.forwarder.SubmitPodChecks(payloads, extraHeaders)
+		responses, err := submit(payloads, extraHeaders)
 		if err != nil {
 			log.Errorf("Unable to submit payload: %s", err)
 			continue
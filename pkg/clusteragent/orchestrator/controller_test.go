@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver,orchestrator
+
+package orchestrator
+
+import (
+	"net/http"
+	"testing"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	processcfg "github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util/orchestrator"
+)
+
+// deploymentForwarder records the deployments of the payloads it is given
+type deploymentForwarder struct {
+	forwarder.MockedForwarder
+	sent [][]string
+}
+
+func (f *deploymentForwarder) SubmitDeploymentChecks(payloads forwarder.Payloads, extra http.Header) (chan forwarder.Response, error) {
+	var uids []string
+	for _, payload := range payloads {
+		msg, err := model.DecodeMessage(*payload)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range msg.Body.(*model.CollectorDeployment).Deployments {
+			uids = append(uids, d.Metadata.Uid)
+		}
+	}
+	f.sent = append(f.sent, uids)
+
+	responses := make(chan forwarder.Response, 1)
+	responses <- forwarder.Response{StatusCode: http.StatusAccepted}
+	close(responses)
+	return responses, nil
+}
+
+func TestProcessDeploysSendsSnapshots(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, uid := range []string{"1", "2"} {
+		require.NoError(t, indexer.Add(&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "deploy-" + uid,
+				Namespace:       "default",
+				UID:             types.UID(uid),
+				ResourceVersion: "1000",
+			},
+		}))
+	}
+
+	fwd := &deploymentForwarder{}
+	o := &Controller{
+		deployLister:  appslisters.NewDeploymentLister(indexer),
+		hostName:      "host",
+		clusterName:   "cluster",
+		clusterID:     "cluster-id",
+		forwarder:     fwd,
+		processConfig: processcfg.NewDefaultAgentConfig(true),
+		isLeaderFunc:  func() bool { return true },
+		resourceCache: orchestrator.NewKubernetesResourceCache(),
+	}
+
+	// the unchanged deployments are part of every collection
+	o.processDeploys()
+	o.processDeploys()
+	require.Len(t, fwd.sent, 2)
+	for _, uids := range fwd.sent {
+		assert.ElementsMatch(t, []string{"1", "2"}, uids)
+	}
+}
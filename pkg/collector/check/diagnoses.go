@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package check
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+// DiagnosisSeverity is how much a finding of a check impacts its collection
+type DiagnosisSeverity string
+
+const (
+	// DiagnosisError is a finding preventing the check from collecting some of its data
+	DiagnosisError DiagnosisSeverity = "error"
+	// DiagnosisWarning is a finding which may degrade the data collected by the check
+	DiagnosisWarning DiagnosisSeverity = "warning"
+	// DiagnosisInfo is a finding reported for information only
+	DiagnosisInfo DiagnosisSeverity = "info"
+)
+
+// Diagnosis is a structured finding of a check instance about its environment, e.g. a missing
+// permission or an unreachable endpoint, shown in the agent status and the flare
+type Diagnosis struct {
+	Name        string
+	Severity    DiagnosisSeverity
+	Message     string
+	Remediation string
+	Updated     time.Time
+}
+
+var (
+	diagnosesLock sync.RWMutex
+	diagnoses     = map[ID][]Diagnosis{}
+)
+
+func init() {
+	expvar.Publish("checkDiagnoses", expvar.Func(expvarDiagnoses))
+}
+
+func expvarDiagnoses() interface{} {
+	diagnosesLock.RLock()
+	defer diagnosesLock.RUnlock()
+
+	stats := make(map[string][]Diagnosis, len(diagnoses))
+	for id, d := range diagnoses {
+		stats[string(id)] = d
+	}
+	return stats
+}
+
+// SetDiagnoses replaces the diagnoses of a check instance, an empty list clears them
+func SetDiagnoses(id ID, checkDiagnoses []Diagnosis) {
+	if len(checkDiagnoses) == 0 {
+		RemoveDiagnoses(id)
+		return
+	}
+
+	now := time.Now()
+	d := make([]Diagnosis, len(checkDiagnoses))
+	for i, diagnosis := range checkDiagnoses {
+		switch diagnosis.Severity {
+		case DiagnosisError, DiagnosisWarning, DiagnosisInfo:
+		default:
+			diagnosis.Severity = DiagnosisWarning
+		}
+		diagnosis.Updated = now
+		d[i] = diagnosis
+	}
+
+	diagnosesLock.Lock()
+	defer diagnosesLock.Unlock()
+	diagnoses[id] = d
+}
+
+// GetDiagnoses returns the diagnoses of a check instance
+func GetDiagnoses(id ID) []Diagnosis {
+	diagnosesLock.RLock()
+	defer diagnosesLock.RUnlock()
+	return diagnoses[id]
+}
+
+// RemoveDiagnoses removes the diagnoses of a check instance, when it's unscheduled
+func RemoveDiagnoses(id ID) {
+	diagnosesLock.Lock()
+	defer diagnosesLock.Unlock()
+	delete(diagnoses, id)
+}
@@ -0,0 +1,43 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package check
+
+import (
+	"encoding/json"
+	"expvar"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiagnoses(t *testing.T) {
+	defer RemoveDiagnoses("foo:1")
+
+	SetDiagnoses("foo:1", []Diagnosis{
+		{Name: "permission", Severity: DiagnosisError, Message: "cannot read /proc/1/io", Remediation: "run the agent as root"},
+		{Name: "endpoint", Message: "http://localhost:8080 is unreachable"},
+	})
+	diagnoses := GetDiagnoses("foo:1")
+	require.Len(t, diagnoses, 2)
+	assert.Equal(t, DiagnosisError, diagnoses[0].Severity)
+	// the severity defaults to warning
+	assert.Equal(t, DiagnosisWarning, diagnoses[1].Severity)
+	assert.False(t, diagnoses[1].Updated.IsZero())
+
+	stats := make(map[string][]Diagnosis)
+	require.NoError(t, json.Unmarshal([]byte(expvar.Get("checkDiagnoses").String()), &stats))
+	assert.Equal(t, "cannot read /proc/1/io", stats["foo:1"][0].Message)
+
+	// the diagnoses are replaced
+	SetDiagnoses("foo:1", []Diagnosis{{Name: "endpoint", Severity: DiagnosisInfo, Message: "reachable again"}})
+	diagnoses = GetDiagnoses("foo:1")
+	require.Len(t, diagnoses, 1)
+	assert.Equal(t, "reachable again", diagnoses[0].Message)
+
+	SetDiagnoses("foo:1", nil)
+	assert.Empty(t, GetDiagnoses("foo:1"))
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/runner"
 	"github.com/DataDog/datadog-agent/pkg/collector/scheduler"
+	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -119,6 +120,9 @@ func (c *Collector) RunCheck(ch check.Check) (check.ID, error) {
 	}
 
 	c.checks[ch.ID()] = ch
+	if version := ch.Version(); version != "" {
+		inventories.SetCheckMetadata(string(ch.ID()), "check.version", version)
+	}
 	return ch.ID(), nil
 }
 
@@ -187,6 +191,12 @@ func (c *Collector) StopCheck(id check.ID) error {
 	// remove the check from the stats map
 	runner.RemoveCheckStats(id)
 
+	// stop reporting the check in the inventory metadata
+	inventories.RemoveCheckMetadata(string(id))
+
+	// stop reporting the diagnoses of the check
+	check.RemoveDiagnoses(id)
+
 	// vaporize the check
 	c.delete(id)
 
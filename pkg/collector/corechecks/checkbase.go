@@ -15,6 +15,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/defaults"
+	"github.com/DataDog/datadog-agent/pkg/persistentcache"
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -37,6 +38,10 @@ import (
 //
 // If custom tags are set in the instance configuration, they will
 // be automatically appended to each send done by this check.
+//
+// State that must survive restarts, e.g. the last seen timestamp or the
+// cursor of an API, is stored with the ReadPersistentCache and
+// WritePersistentCache methods, which are scoped to the check instance.
 type CheckBase struct {
 	checkName      string
 	checkID        check.ID
@@ -219,6 +224,24 @@ func (c *CheckBase) GetWarnings() []error {
 	return w
 }
 
+// ReadPersistentCache returns a value stored by the check instance with
+// WritePersistentCache, or the empty string if there is none.
+func (c *CheckBase) ReadPersistentCache(key string) (string, error) {
+	return persistentcache.Read(persistentcache.CheckKey(string(c.ID()), key))
+}
+
+// WritePersistentCache stores a value for the check instance in the run
+// directory, the value survives the restarts of the agent.
+func (c *CheckBase) WritePersistentCache(key, value string) error {
+	return persistentcache.Write(persistentcache.CheckKey(string(c.ID()), key), value)
+}
+
+// SetDiagnoses replaces the diagnoses of the check instance shown in the
+// agent status and the flare, an empty list clears them.
+func (c *CheckBase) SetDiagnoses(diagnoses []check.Diagnosis) {
+	check.SetDiagnoses(c.ID(), diagnoses)
+}
+
 // GetMetricStats returns the stats from the last run of the check.
 func (c *CheckBase) GetMetricStats() (map[string]int64, error) {
 	sender, err := aggregator.GetSender(c.ID())
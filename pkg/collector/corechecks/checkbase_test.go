@@ -6,13 +6,17 @@
 package corechecks
 
 import (
+	"io/ioutil"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
 	"github.com/DataDog/datadog-agent/pkg/collector/check/defaults"
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 var (
@@ -68,3 +72,27 @@ func TestCommonConfigureCustomID(t *testing.T) {
 	assert.Equal(t, string(mycheck.ID()), "test:foobar:bd63a7031add5db9")
 	mockSender.AssertExpectations(t)
 }
+
+func TestPersistentCache(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", testDir)
+
+	first := &dummyCheck{CheckBase: NewCheckBase("test")}
+	first.BuildID([]byte("foo: bar"), []byte(initConfig))
+	second := &dummyCheck{CheckBase: NewCheckBase("test")}
+	second.BuildID([]byte("foo: baz"), []byte(initConfig))
+
+	require.NoError(t, first.WritePersistentCache("cursor", "42"))
+
+	value, err := first.ReadPersistentCache("cursor")
+	assert.NoError(t, err)
+	assert.Equal(t, "42", value)
+
+	// the values are scoped to the check instance
+	value, err = second.ReadPersistentCache("cursor")
+	assert.NoError(t, err)
+	assert.Equal(t, "", value)
+}
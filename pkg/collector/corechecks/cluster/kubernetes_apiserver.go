@@ -44,13 +44,26 @@ const (
 
 // KubeASConfig is the config of the API server.
 type KubeASConfig struct {
-	CollectEvent             bool     `yaml:"collect_events"`
-	CollectOShiftQuotas      bool     `yaml:"collect_openshift_clusterquotas"`
-	FilteredEventTypes       []string `yaml:"filtered_event_types"`
-	EventCollectionTimeoutMs int      `yaml:"kubernetes_event_read_timeout_ms"`
-	MaxEventCollection       int      `yaml:"max_events_per_run"`
-	LeaderSkip               bool     `yaml:"skip_leader_election"`
-	ResyncPeriodEvents       int      `yaml:"kubernetes_event_resync_period_s"`
+	CollectEvent             bool             `yaml:"collect_events"`
+	CollectOShiftQuotas      bool             `yaml:"collect_openshift_clusterquotas"`
+	FilteredEventTypes       []string         `yaml:"filtered_event_types"`
+	FilteredEventKinds       []string         `yaml:"filtered_event_kinds"`
+	FilteredEventNamespaces  []string         `yaml:"filtered_event_namespaces"`
+	EventAlerts              []EventAlertRule `yaml:"event_alerts"`
+	EventCollectionTimeoutMs int              `yaml:"kubernetes_event_read_timeout_ms"`
+	MaxEventCollection       int              `yaml:"max_events_per_run"`
+	LeaderSkip               bool             `yaml:"skip_leader_election"`
+	ResyncPeriodEvents       int              `yaml:"kubernetes_event_resync_period_s"`
+}
+
+// EventAlertRule maps the Kubernetes events matching a kind and a reason
+// to Datadog events with a given alert type.
+type EventAlertRule struct {
+	Kind      string `yaml:"kind"`
+	Reason    string `yaml:"reason"`
+	AlertType string `yaml:"alert_type"`
+
+	alertType metrics.EventAlertType
 }
 
 // EventC holds the information pertaining to which event we collected last and when we last re-synced.
@@ -112,11 +125,35 @@ func (k *KubeASCheck) Configure(config, initConfig integration.Data, source stri
 	if k.instance.MaxEventCollection == 0 {
 		k.instance.MaxEventCollection = maxEventCardinality
 	}
-	k.ignoredEvents = convertFilter(k.instance.FilteredEventTypes)
+	k.ignoredEvents = convertFilter(buildEventFilters(k.instance))
+
+	for i := range k.instance.EventAlerts {
+		rule := &k.instance.EventAlerts[i]
+		if rule.Reason == "" {
+			return fmt.Errorf("event alert rule %d has no reason", i)
+		}
+		rule.alertType, err = metrics.GetAlertTypeFromString(rule.AlertType)
+		if err != nil {
+			return fmt.Errorf("event alert rule %d: %v", i, err)
+		}
+	}
 
 	return nil
 }
 
+// buildEventFilters returns the field selector filters excluding the
+// configured event types, involved object kinds and namespaces
+func buildEventFilters(instance *KubeASConfig) []string {
+	filters := append([]string{}, instance.FilteredEventTypes...)
+	for _, kind := range instance.FilteredEventKinds {
+		filters = append(filters, fmt.Sprintf("involvedObject.kind!=%s", kind))
+	}
+	for _, namespace := range instance.FilteredEventNamespaces {
+		filters = append(filters, fmt.Sprintf("involvedObject.namespace!=%s", namespace))
+	}
+	return filters
+}
+
 func convertFilter(conf []string) string {
 	var formatedFilters []string
 	for _, filter := range conf {
@@ -308,9 +345,17 @@ func (k *KubeASCheck) processEvents(sender aggregator.Sender, events []*v1.Event
 
 	for _, event := range events {
 		id := bundleID(event)
+		rule := k.matchAlertRule(event)
+		if rule != nil {
+			// events mapped to an alert are bundled separately per reason
+			id = fmt.Sprintf("%s/%s", id, event.Reason)
+		}
 		bundle, found := eventsByObject[id]
 		if found == false {
 			bundle = newKubernetesEventBundler(event)
+			if rule != nil {
+				bundle.alertType = rule.alertType
+			}
 			eventsByObject[id] = bundle
 		}
 		err := bundle.addEvent(event)
@@ -330,6 +375,21 @@ func (k *KubeASCheck) processEvents(sender aggregator.Sender, events []*v1.Event
 	return nil
 }
 
+// matchAlertRule returns the first alert rule matching the event, if any
+func (k *KubeASCheck) matchAlertRule(e *v1.Event) *EventAlertRule {
+	for i := range k.instance.EventAlerts {
+		rule := &k.instance.EventAlerts[i]
+		if rule.Reason != e.Reason {
+			continue
+		}
+		if rule.Kind != "" && rule.Kind != e.InvolvedObject.Kind {
+			continue
+		}
+		return rule
+	}
+	return nil
+}
+
 // bundleID generates a unique ID to separate k8s events
 // based on their InvolvedObject UIDs and event Types
 func bundleID(e *v1.Event) string {
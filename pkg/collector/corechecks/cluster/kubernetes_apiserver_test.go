@@ -333,3 +333,60 @@ func TestProcessEventsType(t *testing.T) {
 	mocked.AssertNumberOfCalls(t, "Event", 2)
 	mocked.AssertExpectations(t)
 }
+
+func TestBuildEventFilters(t *testing.T) {
+	instance := &KubeASConfig{
+		FilteredEventTypes:      []string{"OOM", "type==Normal"},
+		FilteredEventKinds:      []string{"HorizontalPodAutoscaler"},
+		FilteredEventNamespaces: []string{"kube-system"},
+	}
+	output := convertFilter(buildEventFilters(instance))
+	assert.Equal(t, "reason!=OOM,type==Normal,involvedObject.kind!=HorizontalPodAutoscaler,involvedObject.namespace!=kube-system", output)
+	// the instance filters are not modified
+	assert.Equal(t, []string{"OOM", "type==Normal"}, instance.FilteredEventTypes)
+}
+
+func TestConfigureEventAlerts(t *testing.T) {
+	kubeASCheck := NewKubeASCheck(core.NewCheckBase(kubernetesAPIServerCheckName), &KubeASConfig{})
+	err := kubeASCheck.Configure([]byte("event_alerts:\n  - reason: OOMKilling\n    alert_type: error\n"), nil, "test")
+	assert.NoError(t, err)
+	assert.Equal(t, metrics.EventAlertTypeError, kubeASCheck.instance.EventAlerts[0].alertType)
+
+	kubeASCheck = NewKubeASCheck(core.NewCheckBase(kubernetesAPIServerCheckName), &KubeASConfig{})
+	err = kubeASCheck.Configure([]byte("event_alerts:\n  - reason: OOMKilling\n    alert_type: critical\n"), nil, "test")
+	assert.Error(t, err)
+
+	kubeASCheck = NewKubeASCheck(core.NewCheckBase(kubernetesAPIServerCheckName), &KubeASConfig{})
+	err = kubeASCheck.Configure([]byte("event_alerts:\n  - kind: Pod\n    alert_type: error\n"), nil, "test")
+	assert.Error(t, err)
+}
+
+func TestProcessEventsAlertRules(t *testing.T) {
+	ev1 := createEvent(2, "default", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "default-scheduler", "machine-blue", "FailedScheduling", "0/3 nodes are available", "Warning", 709662600)
+	ev2 := createEvent(3, "default", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "kubelet", "machine-blue", "BackOff", "Back-off restarting failed container", "Warning", 709662600)
+	ev3 := createEvent(1, "default", "dca-789976f5d7-2ljx6", "Pod", "e6417a7f-f566-11e7-9749-0e4863e1cbf4", "default-scheduler", "machine-blue", "FailedScheduling", "0/3 nodes are available", "Warning", 709662660)
+
+	kubeASCheck := NewKubeASCheck(core.NewCheckBase(kubernetesAPIServerCheckName), &KubeASConfig{
+		EventAlerts: []EventAlertRule{
+			{Kind: "Pod", Reason: "FailedScheduling", alertType: metrics.EventAlertTypeError},
+			{Kind: "Node", Reason: "BackOff", alertType: metrics.EventAlertTypeError},
+		},
+	})
+	mocked := mocksender.NewMockSender(kubeASCheck.ID())
+	mocked.On("Event", mock.AnythingOfType("metrics.Event"))
+
+	kubeASCheck.processEvents(mocked, []*v1.Event{ev1, ev2, ev3})
+
+	// The FailedScheduling events are bundled together as an error,
+	// the BackOff event does not match the kind of the second rule.
+	mocked.AssertNumberOfCalls(t, "Event", 2)
+	alertTypes := map[metrics.EventAlertType]string{}
+	for _, call := range mocked.Calls {
+		ev := call.Arguments.Get(0).(metrics.Event)
+		alertTypes[ev.AlertType] = ev.Text
+	}
+	assert.Contains(t, alertTypes[metrics.EventAlertTypeError], "3 **FailedScheduling**")
+	assert.NotContains(t, alertTypes[metrics.EventAlertTypeError], "BackOff")
+	assert.Contains(t, alertTypes[metrics.EventAlertTypeWarning], "3 **BackOff**")
+	mocked.AssertExpectations(t)
+}
@@ -0,0 +1,201 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package containers
+
+import (
+	"math"
+	"time"
+
+	yaml "gopkg.in/yaml.v2"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	ccollectors "github.com/DataDog/datadog-agent/pkg/util/containers/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	containerCheckName = "container"
+)
+
+// ContainerConfig holds the config of the check
+type ContainerConfig struct {
+	// ContainerSource forces the source of the containers (docker, kubelet, ecs_fargate, cloudfoundry),
+	// the best available one is detected when empty
+	ContainerSource string `yaml:"container_source"`
+}
+
+// ContainerCheck reports the metrics of the containers with the same names whatever their runtime.
+// The containers are listed by the collectors of pkg/util/containers, their metrics being read from
+// their cgroups, v1 or v2.
+type ContainerCheck struct {
+	core.CheckBase
+	instance *ContainerConfig
+	detector *ccollectors.Detector
+}
+
+func init() {
+	core.RegisterCheck(containerCheckName, ContainerFactory)
+}
+
+// ContainerFactory is exported for integration testing
+func ContainerFactory() check.Check {
+	return &ContainerCheck{
+		CheckBase: core.NewCheckBase(containerCheckName),
+		instance:  &ContainerConfig{},
+	}
+}
+
+// Parse parses the ContainerCheck config
+func (c *ContainerConfig) Parse(data []byte) error {
+	return yaml.Unmarshal(data, c)
+}
+
+// Configure parses the check configuration and init the check
+func (c *ContainerCheck) Configure(config, initConfig integration.Data, source string) error {
+	err := c.CommonConfigure(config, source)
+	if err != nil {
+		return err
+	}
+	if err := c.instance.Parse(config); err != nil {
+		return err
+	}
+	c.detector = ccollectors.NewDetector(c.instance.ContainerSource)
+	return nil
+}
+
+// Run executes the check
+func (c *ContainerCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+
+	collector, name, err := c.detector.GetPreferred()
+	if err != nil {
+		c.Warnf("No container source available: %s", err) //nolint:errcheck
+		return err
+	}
+	cList, err := collector.List()
+	if err != nil {
+		c.Warnf("Error listing the containers with %s: %s", name, err) //nolint:errcheck
+		return err
+	}
+
+	runningByRuntime := make(map[string]int)
+	for _, ctr := range cList {
+		if ctr.State != containers.ContainerRunningState || ctr.Excluded {
+			continue
+		}
+		runningByRuntime[ctr.Type]++
+
+		tags, err := tagger.Tag(ctr.EntityID, collectors.HighCardinality)
+		if err != nil {
+			log.Errorf("Could not collect tags for container %s: %s", ctr.ID[:12], err)
+		}
+		tags = append(tags, "runtime:"+ctr.Type)
+		c.reportContainerMetrics(sender, ctr, tags, time.Now().Unix())
+	}
+	for runtime, count := range runningByRuntime {
+		sender.Gauge("container.running", float64(count), "", []string{"runtime:" + runtime})
+	}
+
+	sender.Commit()
+	return nil
+}
+
+// reportContainerMetrics reports the metrics of a running container, the CPU times
+// are in USER_HZ like the ones of the docker check
+func (c *ContainerCheck) reportContainerMetrics(sender aggregator.Sender, ctr *containers.Container, tags []string, currentUnixTime int64) {
+	if ctr.StartedAt != 0 && currentUnixTime-ctr.StartedAt > 0 {
+		sender.Gauge("container.uptime", float64(currentUnixTime-ctr.StartedAt), "", tags)
+	}
+
+	if cpu := ctr.CPU; cpu != nil {
+		sender.Rate("container.cpu.usage", cpu.UsageTotal, "", tags)
+		sender.Rate("container.cpu.user", float64(cpu.User), "", tags)
+		sender.Rate("container.cpu.system", float64(cpu.System), "", tags)
+		sender.Gauge("container.cpu.shares", float64(cpu.Shares), "", tags)
+		sender.Rate("container.cpu.throttled", float64(cpu.NrThrottled), "", tags)
+		sender.Rate("container.cpu.throttled.time", cpu.ThrottledTime, "", tags)
+		if cpu.ThreadCount != 0 {
+			sender.Gauge("container.pid.thread_count", float64(cpu.ThreadCount), "", tags)
+		}
+		// limits.CPULimit is a percentage (i.e. 100.0%, not 1.0)
+		timeDiff := cpu.Timestsamp.Unix() - ctr.StartedAt
+		if ctr.Limits.CPULimit > 0 && timeDiff > 0 {
+			availableCPUTimeHz := 100 * float64(timeDiff) // Converted to Hz to be consistent with UsageTotal
+			sender.Rate("container.cpu.limit", ctr.Limits.CPULimit/100*availableCPUTimeHz, "", tags)
+		}
+	}
+
+	if mem := ctr.Memory; mem != nil {
+		if mem.MemUsageInBytes > 0 {
+			sender.Gauge("container.memory.usage", float64(mem.MemUsageInBytes), "", tags)
+		}
+		sender.Gauge("container.memory.rss", float64(mem.RSS), "", tags)
+		sender.Gauge("container.memory.cache", float64(mem.Cache), "", tags)
+		if mem.SwapPresent {
+			sender.Gauge("container.memory.swap", float64(mem.Swap), "", tags)
+		}
+		sender.Gauge("container.memory.kernel", float64(mem.KernMemUsage), "", tags)
+		sender.Gauge("container.memory.failed_count", float64(mem.MemFailCnt), "", tags)
+		if limit := containerMemLimit(ctr); limit > 0 {
+			sender.Gauge("container.memory.limit", float64(limit), "", tags)
+		}
+		if mem.SoftMemLimit > 0 && mem.SoftMemLimit < uint64(math.Pow(2, 60)) {
+			sender.Gauge("container.memory.soft_limit", float64(mem.SoftMemLimit), "", tags)
+		}
+	}
+
+	if io := ctr.IO; io != nil {
+		if len(io.DeviceReadBytes) > 0 {
+			for dev, value := range io.DeviceReadBytes {
+				sender.Rate("container.io.read", float64(value), "", append(tags, "device:"+dev, "device_name:"+dev))
+			}
+		} else {
+			sender.Rate("container.io.read", float64(io.ReadBytes), "", tags)
+		}
+		if len(io.DeviceWriteBytes) > 0 {
+			for dev, value := range io.DeviceWriteBytes {
+				sender.Rate("container.io.write", float64(value), "", append(tags, "device:"+dev, "device_name:"+dev))
+			}
+		} else {
+			sender.Rate("container.io.write", float64(io.WriteBytes), "", tags)
+		}
+		sender.Gauge("container.pid.open_files", float64(io.OpenFiles), "", tags)
+	}
+
+	if ctr.Limits.ThreadLimit != 0 {
+		sender.Gauge("container.pid.thread_limit", float64(ctr.Limits.ThreadLimit), "", tags)
+	}
+
+	for _, netStat := range ctr.Network {
+		if netStat.NetworkName == "" {
+			continue
+		}
+		ifaceTags := append(tags, "network:"+netStat.NetworkName)
+		sender.Rate("container.net.sent", float64(netStat.BytesSent), "", ifaceTags)
+		sender.Rate("container.net.rcvd", float64(netStat.BytesRcvd), "", ifaceTags)
+		sender.Rate("container.net.sent.packets", float64(netStat.PacketsSent), "", ifaceTags)
+		sender.Rate("container.net.rcvd.packets", float64(netStat.PacketsRcvd), "", ifaceTags)
+	}
+}
+
+// containerMemLimit returns the memory limit of a container, 0 when it has none
+func containerMemLimit(ctr *containers.Container) uint64 {
+	if limit := ctr.Memory.HierarchicalMemoryLimit; limit > 0 && limit < uint64(math.Pow(2, 60)) {
+		return limit
+	}
+	return ctr.Limits.MemLimit
+}
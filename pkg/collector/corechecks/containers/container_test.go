@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package containers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator/mocksender"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	cmetrics "github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+)
+
+func TestReportContainerMetrics(t *testing.T) {
+	containerCheck := &ContainerCheck{
+		CheckBase: core.NewCheckBase(containerCheckName),
+		instance:  &ContainerConfig{},
+	}
+	mockSender := mocksender.NewMockSender(containerCheck.ID())
+	mockSender.SetupAcceptAll()
+
+	now := time.Now()
+	ctr := &containers.Container{
+		ID:        "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e",
+		Type:      containers.RuntimeNameContainerd,
+		State:     containers.ContainerRunningState,
+		StartedAt: now.Unix() - 42,
+		ContainerMetrics: cmetrics.ContainerMetrics{
+			CPU: &cmetrics.ContainerCPUStats{
+				Timestsamp:  now,
+				User:        100,
+				System:      50,
+				UsageTotal:  150,
+				Shares:      1024,
+				ThreadCount: 12,
+			},
+			Memory: &cmetrics.ContainerMemStats{
+				RSS:                     2048,
+				Cache:                   1024,
+				MemUsageInBytes:         4096,
+				HierarchicalMemoryLimit: 8192,
+			},
+			IO: &cmetrics.ContainerIOStats{
+				ReadBytes:        10,
+				WriteBytes:       20,
+				DeviceReadBytes:  map[string]uint64{},
+				DeviceWriteBytes: map[string]uint64{"sda": 20},
+				OpenFiles:        7,
+			},
+			Network: cmetrics.ContainerNetStats{
+				{NetworkName: "eth0", BytesSent: 100, BytesRcvd: 200},
+			},
+		},
+		Limits: cmetrics.ContainerLimits{CPULimit: 50, ThreadLimit: 100},
+	}
+	tags := []string{"container_id:47fc31db38b4", "runtime:containerd"}
+
+	containerCheck.reportContainerMetrics(mockSender, ctr, tags, now.Unix())
+
+	mockSender.AssertMetric(t, "Gauge", "container.uptime", 42, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.cpu.usage", 150, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.cpu.user", 100, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.cpu.system", 50, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.cpu.shares", 1024, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.cpu.limit", 0.5*100*42, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.pid.thread_count", 12, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.pid.thread_limit", 100, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.memory.usage", 4096, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.memory.rss", 2048, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.memory.cache", 1024, "", tags)
+	mockSender.AssertMetric(t, "Gauge", "container.memory.limit", 8192, "", tags)
+	mockSender.AssertNotCalled(t, "Gauge", "container.memory.swap", mock.Anything, "", mock.Anything)
+	mockSender.AssertMetric(t, "Rate", "container.io.read", 10, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.io.write", 20, "", append(tags, "device:sda", "device_name:sda"))
+	mockSender.AssertMetric(t, "Gauge", "container.pid.open_files", 7, "", tags)
+	mockSender.AssertMetric(t, "Rate", "container.net.sent", 100, "", append(tags, "network:eth0"))
+	mockSender.AssertMetric(t, "Rate", "container.net.rcvd", 200, "", append(tags, "network:eth0"))
+}
+
+func TestContainerMemLimit(t *testing.T) {
+	ctr := &containers.Container{
+		ContainerMetrics: cmetrics.ContainerMetrics{
+			Memory: &cmetrics.ContainerMemStats{HierarchicalMemoryLimit: 1 << 62},
+		},
+		Limits: cmetrics.ContainerLimits{MemLimit: 1024},
+	}
+	if limit := containerMemLimit(ctr); limit != 1024 {
+		t.Errorf("expected the limit of the container limits, got %d", limit)
+	}
+}
@@ -40,6 +40,8 @@ const (
 type TCPQueueLengthConfig struct {
 	CollectTCPQueueLength bool `yaml:"collect_tcp_queue_length"`
 	OnlyCountNbContexts   bool `yaml:"only_count_nb_contexts"` // For impact analysis only. To be removed after
+	// MinFillRatio skips the sockets whose read and write queues stay below this ratio of their buffer size
+	MinFillRatio float64 `yaml:"min_fill_ratio"`
 }
 
 // TCPQueueLengthCheck grabs TCP queue length metrics
@@ -113,6 +115,10 @@ func (t *TCPQueueLengthCheck) Run() error {
 		if !ok {
 			continue
 		}
+		rqueueFillRatio, wqueueFillRatio := line.Rqueue.FillRatio(), line.Wqueue.FillRatio()
+		if rqueueFillRatio < t.instance.MinFillRatio && wqueueFillRatio < t.instance.MinFillRatio {
+			continue
+		}
 		entityID := containers.BuildTaggerEntityName(line.ContainerID)
 		tags, err := tagger.Tag(entityID, collectors.OrchestratorCardinality)
 		if err != nil {
@@ -133,9 +139,11 @@ func (t *TCPQueueLengthCheck) Run() error {
 			sender.Gauge("tcp_queue.rqueue.size", float64(line.Rqueue.Size), "", tags)
 			sender.Gauge("tcp_queue.rqueue.min", float64(line.Rqueue.Min), "", tags)
 			sender.Gauge("tcp_queue.rqueue.max", float64(line.Rqueue.Max), "", tags)
+			sender.Gauge("tcp_queue.rqueue.fill_ratio", rqueueFillRatio, "", tags)
 			sender.Gauge("tcp_queue.wqueue.size", float64(line.Wqueue.Size), "", tags)
 			sender.Gauge("tcp_queue.wqueue.min", float64(line.Wqueue.Min), "", tags)
 			sender.Gauge("tcp_queue.wqueue.max", float64(line.Wqueue.Max), "", tags)
+			sender.Gauge("tcp_queue.wqueue.fill_ratio", wqueueFillRatio, "", tags)
 		}
 	}
 
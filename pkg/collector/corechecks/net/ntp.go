@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/beevik/ntp"
@@ -183,11 +184,36 @@ func (c *NTPCheck) Run() error {
 	return nil
 }
 
+type ntpQueryResult struct {
+	host     string
+	response *ntp.Response
+	err      error
+}
+
+// queryHosts queries all the configured ntp hosts concurrently
+func (c *NTPCheck) queryHosts() []ntpQueryResult {
+	hosts := c.cfg.instance.Hosts
+	options := ntp.QueryOptions{Version: c.cfg.instance.Version, Port: c.cfg.instance.Port, Timeout: time.Duration(c.cfg.instance.Timeout) * time.Second}
+
+	results := make([]ntpQueryResult, len(hosts))
+	var wg sync.WaitGroup
+	for i, host := range hosts {
+		wg.Add(1)
+		go func(i int, host string) {
+			defer wg.Done()
+			response, err := ntpQuery(host, options)
+			results[i] = ntpQueryResult{host: host, response: response, err: err}
+		}(i, host)
+	}
+	wg.Wait()
+	return results
+}
+
 func (c *NTPCheck) queryOffset() (float64, error) {
 	offsets := []float64{}
 
-	for _, host := range c.cfg.instance.Hosts {
-		response, err := ntpQuery(host, ntp.QueryOptions{Version: c.cfg.instance.Version, Port: c.cfg.instance.Port, Timeout: time.Duration(c.cfg.instance.Timeout) * time.Second})
+	for _, result := range c.queryHosts() {
+		host, response, err := result.host, result.response, result.err
 		if err != nil {
 			if c.errCount >= 10 {
 				c.errCount = 0
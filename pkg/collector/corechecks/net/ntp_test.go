@@ -8,6 +8,7 @@ package net
 import (
 	"fmt"
 	"strconv"
+	"sync"
 	"testing"
 	"time"
 
@@ -346,9 +347,12 @@ func TestDefaultHostConfig(t *testing.T) {
 
 func TestNTPPortConfig(t *testing.T) {
 	var detectedPorts []int
+	var m sync.Mutex
 
 	ntpQuery = func(host string, opt ntp.QueryOptions) (*ntp.Response, error) {
+		m.Lock()
 		detectedPorts = append(detectedPorts, opt.Port)
+		m.Unlock()
 		return testNTPQuery(host, opt)
 	}
 	defer func() { ntpQuery = ntp.QueryWithOptions }()
@@ -372,6 +376,57 @@ port: %d
 	}
 }
 
+func TestNTPConcurrentQueries(t *testing.T) {
+	var ntpCfg = []byte(`
+hosts:
+  - 1
+  - 2
+  - 3
+`)
+	var ntpInitCfg = []byte("")
+
+	// every query waits for the other ones to start, the check would time out with sequential queries
+	var started sync.WaitGroup
+	started.Add(3)
+	ntpQuery = func(host string, opt ntp.QueryOptions) (*ntp.Response, error) {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("timeout")
+		}
+		o, _ := strconv.Atoi(host)
+		return &ntp.Response{
+			ClockOffset: time.Duration(o) * time.Second,
+			Stratum:     15,
+		}, nil
+	}
+	defer func() { ntpQuery = ntp.QueryWithOptions }()
+
+	ntpCheck := new(NTPCheck)
+	ntpCheck.Configure(ntpCfg, ntpInitCfg, "test")
+
+	mockSender := mocksender.NewMockSender(ntpCheck.ID())
+
+	mockSender.On("Gauge", "ntp.offset", float64(2), "", []string(nil)).Return().Times(1)
+	mockSender.On("ServiceCheck",
+		"ntp.in_sync",
+		metrics.ServiceCheckOK,
+		"",
+		[]string(nil),
+		"").Return().Times(1)
+
+	mockSender.On("Commit").Return().Times(1)
+	ntpCheck.Run()
+
+	mockSender.AssertExpectations(t)
+}
+
 func TestNTPPortNotInt(t *testing.T) {
 	ntpCheck := new(NTPCheck)
 	ntpCfg := []byte(`
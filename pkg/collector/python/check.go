@@ -71,7 +71,25 @@ func (c *PythonCheck) runCheck(commitMetrics bool) error {
 
 	log.Debugf("Running python check %s %s", c.ModuleName, c.id)
 
+	var memoryBefore []*PythonStats
+	trackMemory := memoryTrackerEnabled()
+	if trackMemory {
+		var err error
+		if memoryBefore, err = getInterpreterMemoryUsage(); err != nil {
+			log.Warnf("Unable to track the memory of the python check %s: %s", c.id, err)
+			trackMemory = false
+		}
+	}
+
 	cResult := C.run_check(rtloader, c.instance)
+	if trackMemory {
+		// the objects allocated by the other checks while this one released the GIL are also counted
+		if memoryAfter, err := getInterpreterMemoryUsage(); err == nil {
+			recordCheckMemory(c.id, memoryBefore, memoryAfter)
+		} else {
+			log.Warnf("Unable to track the memory of the python check %s: %s", c.id, err)
+		}
+	}
 	if cResult == nil {
 		if err := getRtLoaderError(); err != nil {
 			return err
@@ -108,8 +126,10 @@ func (c *PythonCheck) RunSimple() error {
 	return c.runCheck(false)
 }
 
-// Stop does nothing
-func (c *PythonCheck) Stop() {}
+// Stop forgets the memory tracked for the check
+func (c *PythonCheck) Stop() {
+	forgetCheckMemory(c.id)
+}
 
 // String representation (for debug and logging)
 func (c *PythonCheck) String() string {
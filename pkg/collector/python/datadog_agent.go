@@ -8,11 +8,13 @@
 package python
 
 import (
+	"encoding/json"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	yaml "gopkg.in/yaml.v2"
 
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metadata/externalhost"
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
@@ -165,13 +167,29 @@ func SetCheckMetadata(checkID, name, value *C.char) {
 	inventories.SetCheckMetadata(cid, key, val)
 }
 
+// SetCheckDiagnoses replaces the diagnoses of one check instance shown in the agent status and the flare.
+// Indirectly used by the C function `set_check_diagnoses` that's mapped to `datadog_agent.set_check_diagnoses`.
+//export SetCheckDiagnoses
+func SetCheckDiagnoses(checkID, diagnoses *C.char) {
+	cid := C.GoString(checkID)
+
+	var d []check.Diagnosis
+	if err := json.Unmarshal([]byte(C.GoString(diagnoses)), &d); err != nil {
+		log.Errorf("Invalid diagnoses for the check %s: %v", cid, err)
+		return
+	}
+	check.SetDiagnoses(check.ID(cid), d)
+}
+
 // WritePersistentCache stores a value for one check instance
 // Indirectly used by the C function `write_persistent_cache` that's mapped to `datadog_agent.write_persistent_cache`.
 //export WritePersistentCache
 func WritePersistentCache(key, value *C.char) {
 	keyName := C.GoString(key)
 	val := C.GoString(value)
-	persistentcache.Write(keyName, val) //nolint:errcheck
+	if err := persistentcache.Write(keyName, val); err != nil {
+		log.Errorf("Failed to write cache %s: %s", keyName, err)
+	}
 }
 
 // ReadPersistentCache retrieves a value for one check instance
@@ -203,3 +221,17 @@ func ObfuscateSQL(rawQuery *C.char, errResult **C.char) *C.char {
 	// memory will be freed by caller
 	return TrackedCString(obfuscatedQuery.Query)
 }
+
+// ObfuscateSQLExecPlan obfuscates the provided JSON SQL execution plan, removing the costs and the rows
+// estimates when normalize is true, writing the error into errResult if the operation fails
+//export ObfuscateSQLExecPlan
+func ObfuscateSQLExecPlan(rawPlan *C.char, normalize C.bool, errResult **C.char) *C.char {
+	obfuscatedPlan, err := obfuscator.ObfuscateSQLExecPlan(C.GoString(rawPlan), bool(normalize))
+	if err != nil {
+		// memory will be freed by caller
+		*errResult = TrackedCString(err.Error())
+		return nil
+	}
+	// memory will be freed by caller
+	return TrackedCString(obfuscatedPlan)
+}
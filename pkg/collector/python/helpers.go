@@ -158,6 +158,11 @@ func GetPythonInterpreterMemoryUsage() ([]*PythonStats, error) {
 	glock := newStickyLock()
 	defer glock.unlock()
 
+	return getInterpreterMemoryUsage()
+}
+
+// getInterpreterMemoryUsage collects a python interpreter memory usage snapshot, the GIL must be locked
+func getInterpreterMemoryUsage() ([]*PythonStats, error) {
 	usage := C.get_interpreter_memory_usage(rtloader)
 	if usage == nil {
 		return nil, fmt.Errorf("Could not collect interpreter memory snapshot: %s", getRtLoaderError())
@@ -165,7 +170,7 @@ func GetPythonInterpreterMemoryUsage() ([]*PythonStats, error) {
 	defer C.rtloader_free(rtloader, unsafe.Pointer(usage))
 	payload := C.GoString(usage)
 
-	log.Infof("Interpreter stats received: %v", payload)
+	log.Debugf("Interpreter stats received: %v", payload)
 
 	stats := map[interface{}]interface{}{}
 	if err := yaml.Unmarshal([]byte(payload), &stats); err != nil {
@@ -83,10 +83,12 @@ void GetVersion(char **);
 void Headers(char **);
 void ReadPersistentCache(char *);
 void SetCheckMetadata(char *, char *, char *);
+void SetCheckDiagnoses(char *, char *);
 void SetExternalTags(char *, char *, char **);
 void WritePersistentCache(char *, char *);
 bool TracemallocEnabled();
 char* ObfuscateSQL(char *, char **);
+char* ObfuscateSQLExecPlan(char *, bool, char **);
 
 void initDatadogAgentModule(rtloader_t *rtloader) {
 	set_get_clustername_cb(rtloader, GetClusterName);
@@ -95,11 +97,13 @@ void initDatadogAgentModule(rtloader_t *rtloader) {
 	set_get_version_cb(rtloader, GetVersion);
 	set_headers_cb(rtloader, Headers);
 	set_set_check_metadata_cb(rtloader, SetCheckMetadata);
+	set_set_check_diagnoses_cb(rtloader, SetCheckDiagnoses);
 	set_set_external_tags_cb(rtloader, SetExternalTags);
 	set_write_persistent_cache_cb(rtloader, WritePersistentCache);
 	set_read_persistent_cache_cb(rtloader, ReadPersistentCache);
 	set_tracemalloc_enabled_cb(rtloader, TracemallocEnabled);
 	set_obfuscate_sql_cb(rtloader, ObfuscateSQL);
+	set_obfuscate_sql_exec_plan_cb(rtloader, ObfuscateSQLExecPlan);
 }
 
 //
@@ -110,12 +114,14 @@ void SubmitMetric(char *, metric_type_t, char *, float, char **, int, char *);
 void SubmitServiceCheck(char *, char *, int, char **, int, char *, char *);
 void SubmitEvent(char *, event_t *, int);
 void SubmitHistogramBucket(char *, char *, long long, float, float, int, char *, char **);
+void SubmitEventPlatformEvent(char *, char *, char *);
 
 void initAggregatorModule(rtloader_t *rtloader) {
 	set_submit_metric_cb(rtloader, SubmitMetric);
 	set_submit_service_check_cb(rtloader, SubmitServiceCheck);
 	set_submit_event_cb(rtloader, SubmitEvent);
 	set_submit_histogram_bucket_cb(rtloader, SubmitHistogramBucket);
+	set_submit_event_platform_event_cb(rtloader, SubmitEventPlatformEvent);
 }
 
 //
@@ -0,0 +1,142 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build python
+
+package python
+
+import (
+	"expvar"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// maxTrackedTypes is the number of object types reported for the last run of a check
+const maxTrackedTypes = 10
+
+// TypeGrowth is the growth of the objects of a Python type during a check run
+type TypeGrowth struct {
+	Type     string
+	NObjects int
+	Size     int
+}
+
+// CheckMemoryStats is the growth of the Python objects across the runs of a check, measured with
+// snapshots of the interpreter taken before and after every run. The first run is the baseline:
+// the objects it creates, e.g. caches and connections, aren't counted in the total growth.
+type CheckMemoryStats struct {
+	Runs        int
+	LastGrowth  int64
+	TotalGrowth int64
+	GrowingRuns int // number of consecutive runs that grew the memory
+	TopTypes    []*TypeGrowth
+	Warning     string
+}
+
+var (
+	checkMemoryLock  sync.RWMutex
+	checkMemoryStats = map[check.ID]*CheckMemoryStats{}
+)
+
+func init() {
+	expvar.Publish("pythonMemory", expvar.Func(expvarCheckMemoryStats))
+}
+
+func expvarCheckMemoryStats() interface{} {
+	checkMemoryLock.RLock()
+	defer checkMemoryLock.RUnlock()
+
+	stats := make(map[string]CheckMemoryStats, len(checkMemoryStats))
+	for id, s := range checkMemoryStats {
+		stats[string(id)] = *s
+	}
+	return stats
+}
+
+// memoryTrackerEnabled returns whether the memory of the Python checks is tracked on every run
+func memoryTrackerEnabled() bool {
+	return config.Datadog.GetBool("python_memory_tracker")
+}
+
+// diffMemorySnapshots returns the growth of the size of the Python objects between two snapshots,
+// and the types that grew the most
+func diffMemorySnapshots(before, after []*PythonStats) (int64, []*TypeGrowth) {
+	previous := make(map[string]*PythonStats, len(before))
+	for _, s := range before {
+		previous[s.Type] = s
+	}
+
+	var growth int64
+	var types []*TypeGrowth
+	for _, s := range after {
+		g := &TypeGrowth{Type: s.Type, NObjects: s.NObjects, Size: s.Size}
+		if p, ok := previous[s.Type]; ok {
+			g.NObjects -= p.NObjects
+			g.Size -= p.Size
+			delete(previous, s.Type)
+		}
+		growth += int64(g.Size)
+		if g.Size > 0 {
+			types = append(types, g)
+		}
+	}
+	// the types that were freed
+	for _, p := range previous {
+		growth -= int64(p.Size)
+	}
+
+	sort.Slice(types, func(i, j int) bool {
+		if types[i].Size != types[j].Size {
+			return types[i].Size > types[j].Size
+		}
+		return types[i].Type < types[j].Type
+	})
+	if len(types) > maxTrackedTypes {
+		types = types[:maxTrackedTypes]
+	}
+	return growth, types
+}
+
+// recordCheckMemory records the growth of the memory during a run of the check
+func recordCheckMemory(id check.ID, before, after []*PythonStats) {
+	growth, types := diffMemorySnapshots(before, after)
+	threshold := config.Datadog.GetInt64("python_memory_tracker_growth_threshold")
+
+	checkMemoryLock.Lock()
+	defer checkMemoryLock.Unlock()
+
+	stats, ok := checkMemoryStats[id]
+	if !ok {
+		stats = &CheckMemoryStats{}
+		checkMemoryStats[id] = stats
+	}
+	stats.Runs++
+	stats.LastGrowth = growth
+	stats.TopTypes = types
+	if stats.Runs > 1 {
+		stats.TotalGrowth += growth
+	}
+	if growth > 0 {
+		stats.GrowingRuns++
+	} else {
+		stats.GrowingRuns = 0
+	}
+
+	stats.Warning = ""
+	if threshold > 0 && stats.TotalGrowth > threshold {
+		stats.Warning = fmt.Sprintf("the Python objects grew by %d bytes over the last %d runs, the check may be leaking memory", stats.TotalGrowth, stats.Runs-1)
+	}
+}
+
+// forgetCheckMemory removes the memory stats of an unscheduled check
+func forgetCheckMemory(id check.ID) {
+	checkMemoryLock.Lock()
+	defer checkMemoryLock.Unlock()
+	delete(checkMemoryStats, id)
+}
@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build python,test
+
+package python
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestDiffMemorySnapshots(t *testing.T) {
+	before := []*PythonStats{
+		{Type: "dict", NObjects: 10, Size: 1000},
+		{Type: "list", NObjects: 5, Size: 500},
+		{Type: "tuple", NObjects: 2, Size: 100},
+	}
+	after := []*PythonStats{
+		{Type: "dict", NObjects: 12, Size: 1300},
+		{Type: "list", NObjects: 4, Size: 400},
+		{Type: "str", NObjects: 20, Size: 2000},
+	}
+
+	growth, types := diffMemorySnapshots(before, after)
+	// +300 dict, -100 list, +2000 str, -100 tuple
+	assert.Equal(t, int64(2100), growth)
+	require.Len(t, types, 2)
+	assert.Equal(t, TypeGrowth{Type: "str", NObjects: 20, Size: 2000}, *types[0])
+	assert.Equal(t, TypeGrowth{Type: "dict", NObjects: 2, Size: 300}, *types[1])
+}
+
+func TestRecordCheckMemory(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("python_memory_tracker_growth_threshold", 1500)
+
+	id := check.ID("leaky:123")
+	defer forgetCheckMemory(id)
+
+	snapshot := func(size int) []*PythonStats {
+		return []*PythonStats{{Type: "dict", NObjects: size / 100, Size: size}}
+	}
+
+	// the first run is the baseline
+	recordCheckMemory(id, snapshot(0), snapshot(5000))
+	stats := expvarCheckMemoryStats().(map[string]CheckMemoryStats)[string(id)]
+	assert.Equal(t, 1, stats.Runs)
+	assert.Equal(t, int64(5000), stats.LastGrowth)
+	assert.Equal(t, int64(0), stats.TotalGrowth)
+	assert.Empty(t, stats.Warning)
+
+	recordCheckMemory(id, snapshot(5000), snapshot(6000))
+	stats = expvarCheckMemoryStats().(map[string]CheckMemoryStats)[string(id)]
+	assert.Equal(t, int64(1000), stats.TotalGrowth)
+	assert.Equal(t, 2, stats.GrowingRuns)
+	assert.Empty(t, stats.Warning)
+
+	recordCheckMemory(id, snapshot(6000), snapshot(7000))
+	stats = expvarCheckMemoryStats().(map[string]CheckMemoryStats)[string(id)]
+	assert.Equal(t, int64(2000), stats.TotalGrowth)
+	assert.NotEmpty(t, stats.Warning)
+
+	recordCheckMemory(id, snapshot(7000), snapshot(6500))
+	stats = expvarCheckMemoryStats().(map[string]CheckMemoryStats)[string(id)]
+	assert.Equal(t, int64(1500), stats.TotalGrowth)
+	assert.Equal(t, 0, stats.GrowingRuns)
+	assert.Empty(t, stats.TopTypes)
+	assert.Empty(t, stats.Warning)
+
+	forgetCheckMemory(id)
+	_, ok := expvarCheckMemoryStats().(map[string]CheckMemoryStats)[string(id)]
+	assert.False(t, ok)
+}
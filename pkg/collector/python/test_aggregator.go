@@ -201,3 +201,16 @@ func testSubmitHistogramBucket(t *testing.T) {
 
 	sender.AssertHistogramBucket(t, "HistogramBucket", "test_histogram", 42, 1.0, 2.0, true, "my_hostname", []string{"tag1", "tag2"})
 }
+
+func testSubmitEventPlatformEvent(t *testing.T) {
+	sender := mocksender.NewMockSender(check.ID("testID"))
+	sender.SetupAcceptAll()
+
+	SubmitEventPlatformEvent(
+		C.CString("testID"),
+		C.CString(`{"key":"value"}`),
+		C.CString("dbm-samples"),
+	)
+
+	sender.AssertCalled(t, "EventPlatformEvent", `{"key":"value"}`, "dbm-samples")
+}
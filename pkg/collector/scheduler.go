@@ -179,6 +179,14 @@ func GetChecksByNameForConfigs(checkName string, configs []integration.Config) [
 	return checks
 }
 
+// GetChecksForConfigs returns all the check instances of the given configurations
+func GetChecksForConfigs(configs []integration.Config) []check.Check {
+	if checkScheduler == nil {
+		return nil
+	}
+	return checkScheduler.GetChecksFromConfigs(configs, false)
+}
+
 // GetChecksFromConfigs gets all the check instances for given configurations
 // optionally can populate the configToChecks cache
 func (s *CheckScheduler) GetChecksFromConfigs(configs []integration.Config, populateCache bool) []check.Check {
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package snapshot
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FixtureServer serves the recorded responses of an API, e.g. the kubelet or the docker API,
+// from the files of a directory: the request to /containers/json is answered with the file
+// containers/json.json, or containers/json. The requests without a fixture are answered with
+// a 404 and listed by Missing, to spot the fixtures to record.
+type FixtureServer struct {
+	*httptest.Server
+	dir string
+
+	mu      sync.Mutex
+	missing map[string]struct{}
+}
+
+// NewFixtureServer starts a server serving the fixtures of dir, it must be closed
+func NewFixtureServer(dir string) *FixtureServer {
+	s := &FixtureServer{
+		dir:     dir,
+		missing: make(map[string]struct{}),
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *FixtureServer) serve(w http.ResponseWriter, r *http.Request) {
+	file := filepath.Join(s.dir, filepath.FromSlash(path.Clean("/"+r.URL.Path)))
+	for _, candidate := range []string{file + ".json", file} {
+		if info, err := os.Stat(candidate); err != nil || info.IsDir() {
+			continue
+		}
+		content, err := ioutil.ReadFile(candidate)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if filepath.Ext(candidate) == ".json" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.Write(content) //nolint:errcheck
+		return
+	}
+
+	s.mu.Lock()
+	s.missing[r.URL.Path] = struct{}{}
+	s.mu.Unlock()
+	http.NotFound(w, r)
+}
+
+// Missing returns the paths requested without a fixture
+func (s *FixtureServer) Missing() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	paths := make([]string, 0, len(s.missing))
+	for p := range s.missing {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
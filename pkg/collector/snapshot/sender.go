@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package snapshot
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// RecordingSender is a sender recording what a check sends, to snapshot it
+type RecordingSender struct {
+	mu sync.Mutex
+	// lines are the recorded metrics, service checks and events, in the format of the snapshots
+	lines []string
+}
+
+var _ aggregator.Sender = (*RecordingSender)(nil)
+
+// NewRecordingSender returns an empty RecordingSender
+func NewRecordingSender() *RecordingSender {
+	return &RecordingSender{}
+}
+
+// Snapshot returns what the check sent since the last reset, one line per metric sample,
+// service check or event, sorted so that it doesn't depend on the order of the calls
+func (s *RecordingSender) Snapshot() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lines := make([]string, len(s.lines))
+	copy(lines, s.lines)
+	sort.Strings(lines)
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// Reset forgets what the check sent
+func (s *RecordingSender) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = nil
+}
+
+func (s *RecordingSender) record(format string, params ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lines = append(s.lines, fmt.Sprintf(format, params...))
+}
+
+func (s *RecordingSender) recordSample(metricType, metric string, value float64, hostname string, tags []string) {
+	s.record("%s %s %s hostname:%q tags:%s", metricType, metric, formatValue(value), hostname, formatTags(tags))
+}
+
+// formatValue formats the values with 10 significant digits, so that the snapshots don't
+// change with the floating point rounding
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', 10, 64)
+}
+
+// formatTags sorts the tags, their order doesn't matter
+func formatTags(tags []string) string {
+	sorted := make([]string, len(tags))
+	copy(sorted, tags)
+	sort.Strings(sorted)
+	return "[" + strings.Join(sorted, " ") + "]"
+}
+
+// Gauge implements aggregator.Sender
+func (s *RecordingSender) Gauge(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("gauge", metric, value, hostname, tags)
+}
+
+// Rate implements aggregator.Sender
+func (s *RecordingSender) Rate(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("rate", metric, value, hostname, tags)
+}
+
+// Count implements aggregator.Sender
+func (s *RecordingSender) Count(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("count", metric, value, hostname, tags)
+}
+
+// MonotonicCount implements aggregator.Sender
+func (s *RecordingSender) MonotonicCount(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("monotonic_count", metric, value, hostname, tags)
+}
+
+// MonotonicCountWithResets implements aggregator.Sender
+func (s *RecordingSender) MonotonicCountWithResets(metric string, value float64, hostname string, tags []string, resetsPossible bool) {
+	s.recordSample("monotonic_count", metric, value, hostname, tags)
+}
+
+// Counter implements aggregator.Sender
+func (s *RecordingSender) Counter(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("counter", metric, value, hostname, tags)
+}
+
+// Histogram implements aggregator.Sender
+func (s *RecordingSender) Histogram(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("histogram", metric, value, hostname, tags)
+}
+
+// Historate implements aggregator.Sender
+func (s *RecordingSender) Historate(metric string, value float64, hostname string, tags []string) {
+	s.recordSample("historate", metric, value, hostname, tags)
+}
+
+// HistogramBucket implements aggregator.Sender
+func (s *RecordingSender) HistogramBucket(metric string, value int64, lowerBound, upperBound float64, monotonic bool, hostname string, tags []string) {
+	s.record("histogram_bucket %s %d bounds:[%s,%s] monotonic:%t hostname:%q tags:%s", metric, value, formatValue(lowerBound), formatValue(upperBound), monotonic, hostname, formatTags(tags))
+}
+
+// ServiceCheck implements aggregator.Sender
+func (s *RecordingSender) ServiceCheck(checkName string, status metrics.ServiceCheckStatus, hostname string, tags []string, message string) {
+	s.record("service_check %s %s hostname:%q tags:%s message:%q", checkName, status, hostname, formatTags(tags), message)
+}
+
+// Event implements aggregator.Sender, the timestamp of the events isn't recorded
+func (s *RecordingSender) Event(e metrics.Event) {
+	s.record("event %q text:%q priority:%s alert_type:%s aggregation_key:%q source_type_name:%q event_type:%q hostname:%q tags:%s",
+		e.Title, e.Text, e.Priority, e.AlertType, e.AggregationKey, e.SourceTypeName, e.EventType, e.Host, formatTags(e.Tags))
+}
+
+// EventPlatformEvent implements aggregator.Sender
+func (s *RecordingSender) EventPlatformEvent(rawEvent string, eventType string) {
+	s.record("event_platform_event %s %q", eventType, rawEvent)
+}
+
+// SetExternalTags implements aggregator.Sender
+func (s *RecordingSender) SetExternalTags(hostname, sourceType string, tags []string) {
+	s.record("external_tags %s hostname:%q tags:%s", sourceType, hostname, formatTags(tags))
+}
+
+// Commit implements aggregator.Sender
+func (s *RecordingSender) Commit() {}
+
+// GetMetricStats implements aggregator.Sender
+func (s *RecordingSender) GetMetricStats() map[string]int64 {
+	return map[string]int64{}
+}
+
+// DisableDefaultHostname implements aggregator.Sender
+func (s *RecordingSender) DisableDefaultHostname(disable bool) {}
+
+// SetCheckCustomTags implements aggregator.Sender
+func (s *RecordingSender) SetCheckCustomTags(tags []string) {}
+
+// SetCheckService implements aggregator.Sender
+func (s *RecordingSender) SetCheckService(service string) {}
+
+// FinalizeCheckServiceTag implements aggregator.Sender
+func (s *RecordingSender) FinalizeCheckServiceTag() {}
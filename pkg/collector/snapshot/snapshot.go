@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package snapshot tests the corechecks against recorded fixtures, e.g. the responses of the
+// kubelet or the docker API, by comparing what they send with golden files. This validates the
+// refactors of the checks without the infrastructure they monitor:
+//
+//	func TestCheckSnapshot(t *testing.T) {
+//		server := snapshot.NewFixtureServer("testdata/fixtures")
+//		defer server.Close()
+//
+//		c := newCheck()
+//		c.Configure([]byte("url: "+server.URL), nil, "test")
+//		snapshot.AssertGolden(t, "testdata/check.golden", snapshot.Run(t, c, 2))
+//		assert.Empty(t, server.Missing())
+//	}
+//
+// The golden files are written by running the tests with -update-snapshots, and reviewed
+// like the code.
+package snapshot
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/collector/check"
+)
+
+var update = flag.Bool("update-snapshots", false, "write the golden files of the check snapshots instead of comparing them")
+
+// Run runs the check the given number of times, e.g. twice for the checks computing rates,
+// and returns the snapshot of what it sent in the last run
+func Run(t testing.TB, c check.Check, runs int) string {
+	t.Helper()
+
+	sender := NewRecordingSender()
+	aggregator.InitAggregatorWithFlushInterval(nil, "", time.Hour)
+	if err := aggregator.SetSender(sender, c.ID()); err != nil {
+		t.Fatalf("could not set the sender of the check: %v", err)
+	}
+	for i := 0; i < runs; i++ {
+		sender.Reset()
+		if err := c.Run(); err != nil {
+			t.Fatalf("run %d of the check failed: %v", i+1, err)
+		}
+	}
+	return sender.Snapshot()
+}
+
+// AssertGolden compares the snapshot with the golden file, or writes it with -update-snapshots
+func AssertGolden(t testing.TB, golden, snapshot string) bool {
+	t.Helper()
+
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(golden), 0755); err != nil {
+			t.Fatalf("could not create the directory of the golden file: %v", err)
+		}
+		if err := ioutil.WriteFile(golden, []byte(snapshot), 0644); err != nil {
+			t.Fatalf("could not write the golden file: %v", err)
+		}
+		return true
+	}
+
+	expected, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Errorf("could not read the golden file, run the test with -update-snapshots to write it: %v", err)
+		return false
+	}
+	if diff := diffLines(string(expected), snapshot); diff != "" {
+		t.Errorf("the snapshot differs from %s, run the test with -update-snapshots if the change is expected:\n%s", golden, diff)
+		return false
+	}
+	return true
+}
+
+// diffLines returns the lines of the golden file missing from the snapshot, prefixed with -,
+// and the unexpected lines of the snapshot, prefixed with +. The lines are sorted.
+func diffLines(expected, actual string) string {
+	counts := make(map[string]int)
+	for _, line := range splitLines(expected) {
+		counts[line]++
+	}
+	var added []string
+	for _, line := range splitLines(actual) {
+		if counts[line] > 0 {
+			counts[line]--
+			continue
+		}
+		added = append(added, "+ "+line)
+	}
+
+	var diff []string
+	for _, line := range splitLines(expected) {
+		if counts[line] > 0 {
+			counts[line]--
+			diff = append(diff, "- "+line)
+		}
+	}
+	diff = append(diff, added...)
+	if len(diff) == 0 {
+		return ""
+	}
+	return fmt.Sprintln(strings.Join(diff, "\n"))
+}
+
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(s, "\n") {
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
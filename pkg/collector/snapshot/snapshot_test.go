@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package snapshot
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	core "github.com/DataDog/datadog-agent/pkg/collector/corechecks"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// containersCheck reports the containers listed by an API, like the container checks
+type containersCheck struct {
+	core.CheckBase
+	url  string
+	runs int
+}
+
+func (c *containersCheck) Run() error {
+	sender, err := aggregator.GetSender(c.ID())
+	if err != nil {
+		return err
+	}
+	resp, err := http.Get(c.url + "/api/v1/containers")
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var containers []struct {
+		Name    string
+		Image   string
+		CPU     float64
+		Running bool
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&containers); err != nil {
+		return err
+	}
+
+	c.runs++
+	for _, container := range containers {
+		tags := []string{"image_name:" + container.Image, "container_name:" + container.Name}
+		sender.Gauge("containers.cpu", container.CPU, "", tags)
+		sender.MonotonicCount("containers.runs", float64(c.runs), "", tags)
+		status := metrics.ServiceCheckOK
+		if !container.Running {
+			status = metrics.ServiceCheckCritical
+		}
+		sender.ServiceCheck("containers.running", status, "", tags, "")
+	}
+	// the missing fixtures are reported by the server
+	http.Get(c.url + "/api/v1/images") //nolint:errcheck
+	sender.Commit()
+	return nil
+}
+
+func TestSnapshot(t *testing.T) {
+	server := NewFixtureServer("testdata/fixtures")
+	defer server.Close()
+
+	c := &containersCheck{CheckBase: core.NewCheckBase("containers"), url: server.URL}
+	AssertGolden(t, "testdata/containers.golden", Run(t, c, 2))
+	assert.Equal(t, 2, c.runs)
+	assert.Equal(t, []string{"/api/v1/images"}, server.Missing())
+}
+
+func TestRecordingSender(t *testing.T) {
+	s := NewRecordingSender()
+	s.Gauge("foo", 1.0/3, "host", []string{"b", "a"})
+	s.Rate("bar", 2, "", nil)
+	assert.Equal(t, "gauge foo 0.3333333333 hostname:\"host\" tags:[a b]\nrate bar 2 hostname:\"\" tags:[]\n", s.Snapshot())
+
+	s.Reset()
+	assert.Equal(t, "", s.Snapshot())
+}
+
+func TestDiffLines(t *testing.T) {
+	assert.Equal(t, "", diffLines("a\nb\n", "a\nb\n"))
+	assert.Equal(t, "- b\n+ c\n+ a\n", diffLines("a\nb\n", "a\nc\na\n"))
+}
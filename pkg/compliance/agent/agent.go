@@ -35,8 +35,9 @@ type Agent struct {
 
 // New creates a new instance of Agent
 func New(reporter event.Reporter, scheduler Scheduler, configDir string, options ...checks.BuilderOption) (*Agent, error) {
+	// the results of the scheduled checks are counted per rule for the status
 	builder, err := checks.NewBuilder(
-		reporter,
+		&statusReporter{reporter: reporter, tracker: rulesStatus},
 		options...,
 	)
 	if err != nil {
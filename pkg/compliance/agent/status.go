@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+)
+
+var rulesStatus = newRuleStatusTracker()
+
+func init() {
+	expvar.Publish("compliance", expvar.Func(func() interface{} {
+		return rulesStatus.get()
+	}))
+}
+
+// RuleStatus holds the counts of the results of the evaluations of a rule
+type RuleStatus struct {
+	Passed     int64  `json:"passed"`
+	Failed     int64  `json:"failed"`
+	Error      int64  `json:"error"`
+	LastResult string `json:"lastResult"`
+}
+
+// ruleStatusTracker counts the results reported for every rule
+type ruleStatusTracker struct {
+	sync.RWMutex
+	rules map[string]*RuleStatus
+}
+
+func newRuleStatusTracker() *ruleStatusTracker {
+	return &ruleStatusTracker{
+		rules: make(map[string]*RuleStatus),
+	}
+}
+
+func (t *ruleStatusTracker) record(e *event.Event) {
+	t.Lock()
+	defer t.Unlock()
+
+	status, ok := t.rules[e.AgentRuleID]
+	if !ok {
+		status = &RuleStatus{}
+		t.rules[e.AgentRuleID] = status
+	}
+
+	switch e.Result {
+	case event.Passed:
+		status.Passed++
+	case event.Failed:
+		status.Failed++
+	case event.Error:
+		status.Error++
+	}
+	status.LastResult = e.Result
+}
+
+func (t *ruleStatusTracker) get() map[string]RuleStatus {
+	t.RLock()
+	defer t.RUnlock()
+
+	rules := make(map[string]RuleStatus, len(t.rules))
+	for id, status := range t.rules {
+		rules[id] = *status
+	}
+	return rules
+}
+
+// statusReporter records the results of the reported events before forwarding them
+type statusReporter struct {
+	reporter event.Reporter
+	tracker  *ruleStatusTracker
+}
+
+func (r *statusReporter) Report(e *event.Event) {
+	r.tracker.record(e)
+	r.reporter.Report(e)
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/compliance/event"
+	"github.com/DataDog/datadog-agent/pkg/compliance/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStatusReporter(t *testing.T) {
+	assert := assert.New(t)
+
+	inner := &mocks.Reporter{}
+	defer inner.AssertExpectations(t)
+	inner.On("Report", mock.AnythingOfType("*event.Event")).Times(4)
+
+	tracker := newRuleStatusTracker()
+	reporter := &statusReporter{reporter: inner, tracker: tracker}
+
+	reporter.Report(&event.Event{AgentRuleID: "cis-docker-1", Result: event.Passed})
+	reporter.Report(&event.Event{AgentRuleID: "cis-docker-1", Result: event.Failed})
+	reporter.Report(&event.Event{AgentRuleID: "cis-docker-1", Result: event.Failed})
+	reporter.Report(&event.Event{AgentRuleID: "cis-kubernetes-2", Result: event.Error})
+
+	assert.Equal(map[string]RuleStatus{
+		"cis-docker-1":     {Passed: 1, Failed: 2, LastResult: event.Failed},
+		"cis-kubernetes-2": {Error: 1, LastResult: event.Error},
+	}, tracker.get())
+}
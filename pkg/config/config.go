@@ -46,6 +46,16 @@ const (
 	// DefaultBatchWait is the default HTTP batch wait in second for logs
 	DefaultBatchWait = 5
 
+	// DefaultBatchMaxConcurrentSend is the default HTTP batch max concurrent send for logs
+	DefaultBatchMaxConcurrentSend = 1
+
+	// DefaultBatchMaxSize is the default HTTP batch max size (maximum number of events in a single batch) for logs
+	DefaultBatchMaxSize = 200
+
+	// DefaultBatchMaxContentSize is the default HTTP batch max content size (before compression) for logs
+	// It is also the maximum possible size of a single event. Events exceeding this limit are dropped.
+	DefaultBatchMaxContentSize = 1000000
+
 	// ClusterIDCacheKey is the key name for the orchestrator cluster id in the agent in-mem cache
 	ClusterIDCacheKey = "orchestratorClusterID"
 )
@@ -101,6 +111,36 @@ type Proxy struct {
 	HTTP    string   `mapstructure:"http"`
 	HTTPS   string   `mapstructure:"https"`
 	NoProxy []string `mapstructure:"no_proxy"`
+	// Logs and APM override the HTTP and HTTPS proxies for the logs and APM intakes
+	Logs string `mapstructure:"logs"`
+	APM  string `mapstructure:"apm"`
+}
+
+const (
+	// ProxyIntakeLogs is the intake of the logs proxy override
+	ProxyIntakeLogs = "logs"
+	// ProxyIntakeAPM is the intake of the APM proxy override
+	ProxyIntakeAPM = "apm"
+)
+
+// ForIntake returns the proxy settings of an intake: its proxy override, if set,
+// replaces both the HTTP and HTTPS proxies.
+func (p *Proxy) ForIntake(intake string) *Proxy {
+	var override string
+	switch intake {
+	case ProxyIntakeLogs:
+		override = p.Logs
+	case ProxyIntakeAPM:
+		override = p.APM
+	}
+	if override == "" {
+		return p
+	}
+	return &Proxy{
+		HTTP:    override,
+		HTTPS:   override,
+		NoProxy: p.NoProxy,
+	}
 }
 
 // MappingProfile represent a group of mappings
@@ -141,10 +181,25 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cloud_provider_metadata", []string{"aws", "gcp", "azure", "alibaba"})
 	config.SetDefault("proxy", nil)
 	config.BindEnvAndSetDefault("skip_ssl_validation", false)
+
+	// FIPS mode: the intake traffic goes through a local FIPS proxy
+	config.BindEnvAndSetDefault("fips.enabled", false)
+	config.BindEnvAndSetDefault("fips.local_address", "localhost")
+	config.BindEnvAndSetDefault("fips.port_range_start", 9803)
+	config.BindEnvAndSetDefault("fips.https", true)
+	config.BindEnvAndSetDefault("fips.tls_verify", true)
+
 	config.BindEnvAndSetDefault("hostname", "")
 	config.BindEnvAndSetDefault("tags", []string{})
 	config.BindEnv("env") //nolint:errcheck
 	config.BindEnvAndSetDefault("tag_value_split_separator", map[string]string{})
+	// Host tags loaded from a file and from the output of a command, refreshed every tags_refresh_interval seconds
+	config.BindEnvAndSetDefault("tags_file", "")
+	config.BindEnvAndSetDefault("tags_command", "")
+	config.BindEnvAndSetDefault("tags_command_timeout", 10)
+	config.BindEnvAndSetDefault("tags_refresh_interval", 300)
+	// Tags of the hosts discovered by the checks, e.g. the VMs of a hypervisor, sent with the host metadata
+	config.BindEnvAndSetDefault("external_host_tags_ttl", 0)
 	config.BindEnvAndSetDefault("conf_path", ".")
 	config.BindEnvAndSetDefault("confd_path", defaultConfdPath)
 	config.BindEnvAndSetDefault("additional_checksd", defaultAdditionalChecksPath)
@@ -152,6 +207,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("log_file", "")
 	config.BindEnvAndSetDefault("log_file_max_size", "10Mb")
 	config.BindEnvAndSetDefault("log_file_max_rolls", 1)
+	config.BindEnvAndSetDefault("log_file_max_age", "0s")
 	config.BindEnvAndSetDefault("log_level", "info")
 	config.BindEnvAndSetDefault("log_to_syslog", false)
 	config.BindEnvAndSetDefault("log_to_console", true)
@@ -170,9 +226,13 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_gohai", true)
 	config.BindEnvAndSetDefault("check_runners", int64(4))
 	config.BindEnvAndSetDefault("auth_token_file_path", "")
+	config.BindEnvAndSetDefault("ipc_mtls.enabled", true)
+	config.BindEnvAndSetDefault("ipc_mtls.allow_token_auth", true)
 	config.BindEnvAndSetDefault("bind_host", "localhost")
 	config.BindEnvAndSetDefault("ipc_address", "localhost")
 	config.BindEnvAndSetDefault("health_port", int64(0))
+	config.BindEnvAndSetDefault("public_status_port", 0)
+	config.BindEnvAndSetDefault("stop_timeout", 30)
 	config.BindEnvAndSetDefault("disable_py3_validation", false)
 	config.BindEnvAndSetDefault("python_version", DefaultPython)
 
@@ -186,6 +246,8 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("tracemalloc_debug", false)
 	config.BindEnvAndSetDefault("tracemalloc_whitelist", "")
 	config.BindEnvAndSetDefault("tracemalloc_blacklist", "")
+	config.BindEnvAndSetDefault("python_memory_tracker", false)
+	config.BindEnvAndSetDefault("python_memory_tracker_growth_threshold", 10*1024*1024)
 	config.BindEnvAndSetDefault("run_path", defaultRunPath)
 
 	// Python 3 linter timeout, in seconds
@@ -201,6 +263,9 @@ func InitConfig(config Config) {
 	// compatibility with Agent5 behavior/win
 	config.BindEnvAndSetDefault("hostname_fqdn", false)
 
+	// Restricts the hostname resolution to the given providers, their precedence is fixed
+	config.BindEnvAndSetDefault("hostname_providers", []string{})
+
 	// When enabled, hostname defined in the configuration (datadog.yaml) and starting with `ip-` or `domu` on EC2 is used as
 	// canonical hostname, otherwise the instance-id is used as canonical hostname.
 	config.BindEnvAndSetDefault("hostname_force_config_as_canonical", false)
@@ -291,6 +356,10 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("enable_payloads.service_checks", true)
 	config.BindEnvAndSetDefault("enable_payloads.sketches", true)
 	config.BindEnvAndSetDefault("enable_payloads.json_to_v1_intake", true)
+	// Serializer: namespace and tag all the series and sketches
+	config.BindEnvAndSetDefault("metric_namespace", "")
+	config.BindEnvAndSetDefault("metric_namespace_blacklist", StandardStatsdPrefixes)
+	config.BindEnvAndSetDefault("extra_tags_by_prefix", map[string][]string{})
 
 	// Forwarder
 	config.BindEnvAndSetDefault("additional_endpoints", map[string][]string{})
@@ -329,10 +398,17 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("dogstatsd_expiry_seconds", 300)
 	config.BindEnvAndSetDefault("dogstatsd_origin_detection", false) // Only supported for socket traffic
 	config.BindEnvAndSetDefault("dogstatsd_so_rcvbuf", 0)
+	config.BindEnvAndSetDefault("dogstatsd_so_rcvbuf_autotune_max", 0)
+	config.BindEnvAndSetDefault("dogstatsd_udp_sockets", 1)
 	config.BindEnvAndSetDefault("dogstatsd_metrics_stats_enable", false)
 	config.BindEnvAndSetDefault("dogstatsd_tags", []string{})
 	config.BindEnvAndSetDefault("dogstatsd_mapper_cache_size", 1000)
 	config.BindEnvAndSetDefault("dogstatsd_string_interner_size", 4096)
+	// Rejects the malformed events and service checks instead of ignoring their invalid fields
+	config.BindEnvAndSetDefault("dogstatsd_strict_parsing", false)
+	config.BindEnvAndSetDefault("dogstatsd_event_max_title_length", 100)
+	config.BindEnvAndSetDefault("dogstatsd_event_max_text_length", 4000)
+	config.BindEnvAndSetDefault("dogstatsd_service_check_max_message_length", 4000)
 	// Enable check for Entity-ID presence when enriching Dogstatsd metrics with tags
 	config.BindEnvAndSetDefault("dogstatsd_entity_id_precedence", false)
 	// Sends Dogstatsd parse errors to the Debug level instead of the Error level
@@ -341,8 +417,15 @@ func InitConfig(config Config) {
 
 	config.BindEnvAndSetDefault("statsd_forward_host", "")
 	config.BindEnvAndSetDefault("statsd_forward_port", 0)
+	config.BindEnvAndSetDefault("statsd_forward_buffer_size", 1024) // in packets
 	config.BindEnvAndSetDefault("statsd_metric_namespace", "")
 	config.BindEnvAndSetDefault("statsd_metric_namespace_blacklist", StandardStatsdPrefixes)
+
+	// OTLP receiver, an empty endpoint disables the protocol
+	config.BindEnvAndSetDefault("otlp_config.metrics.enabled", false)
+	config.BindEnvAndSetDefault("otlp_config.logs.enabled", false)
+	config.BindEnvAndSetDefault("otlp_config.receiver.protocols.grpc.endpoint", "localhost:4317")
+	config.BindEnvAndSetDefault("otlp_config.receiver.protocols.http.endpoint", "localhost:4318")
 	// Autoconfig
 	config.BindEnvAndSetDefault("autoconf_template_dir", "/datadog/check_configs")
 	config.BindEnvAndSetDefault("exclude_pause_container", true)
@@ -411,6 +494,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("kubernetes_kubeconfig_path", "")
 	config.BindEnvAndSetDefault("leader_lease_duration", "60")
 	config.BindEnvAndSetDefault("leader_election", false)
+	config.BindEnvAndSetDefault("leader_election_resource", "configmap")
 	config.BindEnvAndSetDefault("kube_resources_namespace", "")
 	config.BindEnvAndSetDefault("cache_sync_timeout", 2) // in seconds
 
@@ -420,6 +504,7 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("cluster_agent.url", "")
 	config.BindEnvAndSetDefault("cluster_agent.kubernetes_service_name", "datadog-cluster-agent")
 	config.BindEnvAndSetDefault("cluster_agent.tagging_fallback", false)
+	config.BindEnvAndSetDefault("cluster_agent.metadata_replication.enabled", false)
 	config.BindEnvAndSetDefault("metrics_port", "5000")
 
 	// Metadata endpoints
@@ -430,6 +515,8 @@ func InitConfig(config Config) {
 
 	// EC2
 	config.BindEnvAndSetDefault("ec2_use_windows_prefix_detection", false)
+	config.BindEnvAndSetDefault("ec2_prefer_imdsv2", false)
+	config.BindEnvAndSetDefault("ec2_metadata_token_lifetime", 21600) // value in seconds
 
 	// ECS
 	config.BindEnvAndSetDefault("ecs_agent_url", "") // Will be autodetected
@@ -472,10 +559,12 @@ func InitConfig(config Config) {
 
 	// Go_expvar server port
 	config.BindEnvAndSetDefault("expvar_port", "5000")
+	config.BindEnvAndSetDefault("debug_port_fallback", true)
 
-	// Profiling
-	config.BindEnvAndSetDefault("profiling.enabled", false)
-	config.BindEnv("profiling.profile_dd_url", "") //nolint:errcheck
+	// Internal profiling
+	config.BindEnvAndSetDefault("internal_profiling.enabled", false)
+	config.BindEnvAndSetDefault("internal_profiling.period", time.Minute)
+	config.BindEnv("internal_profiling.profile_dd_url", "") //nolint:errcheck
 
 	// Trace agent
 	// Note that trace-agent environment variables are parsed in pkg/trace/config/env.go
@@ -500,6 +589,13 @@ func InitConfig(config Config) {
 
 	config.BindEnv("process_config.process_dd_url", "")      //nolint:errcheck
 	config.BindEnv("process_config.orchestrator_dd_url", "") //nolint:errcheck
+	// Network path collection, the paths are traced by the system-probe
+	config.BindEnvAndSetDefault("network_path.enabled", false)
+	config.BindEnvAndSetDefault("network_path.collect_interval", time.Minute)
+
+	// Process discovery, run by the core agent when the live processes are not collected
+	config.BindEnvAndSetDefault("process_config.process_discovery.enabled", true)
+	config.BindEnvAndSetDefault("process_config.process_discovery.interval", 4*time.Hour)
 
 	// Logs Agent
 
@@ -526,9 +622,17 @@ func InitConfig(config Config) {
 	config.BindEnv("logs_config.processing_rules") //nolint:errcheck
 	// enforce the agent to use files to collect container logs on kubernetes environment
 	config.BindEnvAndSetDefault("logs_config.k8s_container_use_file", false)
+	// stream the container logs through the API server when the pod log files are not available
+	config.BindEnvAndSetDefault("logs_config.k8s_container_use_api", false)
 	// additional config to ensure initial logs are tagged with kubelet tags
 	// wait (seconds) for tagger before start fetching tags of new AD services
 	config.BindEnvAndSetDefault("logs_config.tagger_warmup_duration", 0) // Disabled by default (0 seconds)
+
+	// Detect the multiline patterns of the sources without multiline processing rule
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_detection", false)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_sample_size", 500)
+	config.BindEnvAndSetDefault("logs_config.auto_multi_line_default_match_threshold", 0.48)
+
 	// Configurable docker client timeout while communicating with the docker daemon.
 	// It could happen that the docker daemon takes a lot of time gathering timestamps
 	// before starting to send any data when it has stored several large log files.
@@ -543,12 +647,41 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("logs_config.use_compression", true)
 	config.BindEnvAndSetDefault("logs_config.compression_level", 6) // Default level for the gzip/deflate algorithm
 	config.BindEnvAndSetDefault("logs_config.batch_wait", DefaultBatchWait)
+	config.BindEnvAndSetDefault("logs_config.batch_max_concurrent_send", DefaultBatchMaxConcurrentSend)
+	config.BindEnvAndSetDefault("logs_config.batch_max_size", DefaultBatchMaxSize)
+	config.BindEnvAndSetDefault("logs_config.batch_max_content_size", DefaultBatchMaxContentSize)
 	config.BindEnvAndSetDefault("logs_config.connection_reset_interval", 0) // in seconds, 0 means disabled
 	config.BindEnvAndSetDefault("logs_config.dd_port", 10516)
 	config.BindEnvAndSetDefault("logs_config.dev_mode_use_proto", true)
 	config.BindEnvAndSetDefault("logs_config.dd_url_443", "agent-443-intake.logs.datadoghq.com")
 	config.BindEnvAndSetDefault("logs_config.stop_grace_period", 30)
-	config.BindEnv("logs_config.additional_endpoints") //nolint:errcheck
+	// buffer on disk the logs that can not be sent when the intake is unreachable
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.enabled", false)
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.path", "")
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.max_size", 512*1024*1024)
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.max_size_per_source", 0) // 0 means no quota per source
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.segment_size", 10*1024*1024)
+	config.BindEnvAndSetDefault("logs_config.disk_buffer.spill_delay", 5) // in seconds
+	config.BindEnv("logs_config.additional_endpoints")                    //nolint:errcheck
+
+	// Event platform forwarder: the endpoints of the intake tracks are configured with the same keys
+	// as the logs ones, under the prefix of each track (e.g. network_devices.metadata.logs_dd_url)
+	config.SetKnown("event_platform_forwarder.additional_tracks")
+
+	// Network devices flows
+	config.BindEnvAndSetDefault("network_devices.netflow.enabled", false)
+	config.BindEnvAndSetDefault("network_devices.netflow.stop_timeout", 5)                // in seconds
+	config.BindEnvAndSetDefault("network_devices.netflow.aggregator_buffer_size", 10000)  // in flows
+	config.BindEnvAndSetDefault("network_devices.netflow.aggregator_flush_interval", 300) // in seconds
+	config.SetKnown("network_devices.netflow.listeners")
+
+	// SNMP traps
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.enabled", false)
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.port", 162)
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.bind_host", "0.0.0.0")
+	config.BindEnvAndSetDefault("network_devices.snmp_traps.stop_timeout", 5) // in seconds
+	config.SetKnown("network_devices.snmp_traps.community_strings")
+	config.SetKnown("network_devices.snmp_traps.users")
 
 	// The cardinality of tags to send for checks and dogstatsd respectively.
 	// Choices are: low, orchestrator, high.
@@ -560,6 +693,9 @@ func InitConfig(config Config) {
 
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution", false)
 	config.BindEnvAndSetDefault("histogram_copy_to_distribution_prefix", "")
+	config.BindEnvAndSetDefault("histogram_as_distribution", false)
+	config.BindEnvAndSetDefault("histogram_as_distribution_migration", false)
+	config.BindEnvAndSetDefault("histogram_as_distribution_migration_prefix", "dist.")
 
 	config.BindEnv("api_key") //nolint:errcheck
 
@@ -596,6 +732,8 @@ func InitConfig(config Config) {
 	// Admission controller
 	config.BindEnvAndSetDefault("admission_controller.enabled", false)
 	config.BindEnvAndSetDefault("admission_controller.mutate_unlabelled", false)
+	config.BindEnvAndSetDefault("admission_controller.failure_policy", "Ignore")
+	config.BindEnvAndSetDefault("admission_controller.namespace_selector", map[string]string{})
 	config.BindEnvAndSetDefault("admission_controller.port", 8000)
 	config.BindEnvAndSetDefault("admission_controller.service_name", "datadog-admission-controller")
 	config.BindEnvAndSetDefault("admission_controller.certificate.validity_bound", 365*24)             // validity bound of the certificate created by the controller (in hours, default 1 year)
@@ -606,6 +744,9 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("admission_controller.inject_config.endpoint", "/injectconfig")
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.enabled", true)
 	config.BindEnvAndSetDefault("admission_controller.inject_tags.endpoint", "/injecttags")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.enabled", false)
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.endpoint", "/injectlib")
+	config.BindEnvAndSetDefault("admission_controller.auto_instrumentation.container_registry", "gcr.io/datadoghq")
 	config.BindEnvAndSetDefault("admission_controller.pod_owners_cache_validity", 10) // in minutes
 
 	// Telemetry
@@ -617,6 +758,7 @@ func InitConfig(config Config) {
 	// Declare other keys that don't have a default/env var.
 	// Mostly, keys we use IsSet() on, because IsSet always returns true if a key has a default.
 	config.SetKnown("metadata_providers")
+	config.SetKnown("metrics_exclude")
 	config.SetKnown("config_providers")
 	config.SetKnown("cluster_name")
 	config.SetKnown("listeners")
@@ -639,13 +781,17 @@ func InitConfig(config Config) {
 	config.SetKnown("process_config.intervals.container_realtime")
 	config.SetKnown("process_config.dd_agent_bin")
 	config.SetKnown("process_config.custom_sensitive_words")
+	// the regular expressions may contain commas, they are separated by spaces in the environment variable
+	config.BindEnv("process_config.custom_sensitive_regexps", "DD_CUSTOM_SENSITIVE_REGEXPS") //nolint:errcheck
 	config.SetKnown("process_config.scrub_args")
 	config.SetKnown("process_config.strip_proc_arguments")
 	config.SetKnown("process_config.windows.args_refresh_interval")
 	config.SetKnown("process_config.windows.add_new_args")
+	config.SetKnown("process_config.windows.use_legacy_process_collection")
 	config.SetKnown("process_config.additional_endpoints.*")
 	config.SetKnown("process_config.orchestrator_additional_endpoints.*")
 	config.SetKnown("process_config.container_source")
+	config.SetKnown("process_config.exclude_pause_container")
 	config.SetKnown("process_config.intervals.connections")
 	config.SetKnown("process_config.expvar_port")
 
@@ -673,9 +819,16 @@ func InitConfig(config Config) {
 	config.SetKnown("system_probe_config.closed_channel_size")
 	config.SetKnown("system_probe_config.dns_timeout_in_s")
 	config.SetKnown("system_probe_config.collect_dns_stats")
+	config.SetKnown("system_probe_config.enable_protocol_classification")
+	config.SetKnown("system_probe_config.max_service_stats_buffered")
 	config.SetKnown("system_probe_config.offset_guess_threshold")
 	config.SetKnown("system_probe_config.enable_tcp_queue_length")
 	config.SetKnown("system_probe_config.enable_oom_kill")
+	config.SetKnown("system_probe_config.traceroute.enabled")
+	config.SetKnown("system_probe_config.traceroute.destinations")
+	config.SetKnown("system_probe_config.traceroute.interval")
+	config.SetKnown("system_probe_config.traceroute.max_ttl")
+	config.SetKnown("system_probe_config.traceroute.timeout")
 
 	// Network
 	config.BindEnv("network.id") //nolint:errcheck
@@ -695,6 +848,10 @@ func InitConfig(config Config) {
 	config.SetKnown("apm_config.connection_limit")
 	config.SetKnown("apm_config.ignore_resources")
 	config.SetKnown("apm_config.replace_tags")
+	config.SetKnown("apm_config.normalization_rules")
+	config.SetKnown("apm_config.meta_allowlist")
+	config.SetKnown("apm_config.meta_blocklist")
+	config.SetKnown("apm_config.container_metadata_tags")
 	config.SetKnown("apm_config.obfuscation.elasticsearch.enabled")
 	config.SetKnown("apm_config.obfuscation.elasticsearch.keep_values")
 	config.SetKnown("apm_config.obfuscation.mongodb.enabled")
@@ -704,9 +861,13 @@ func InitConfig(config Config) {
 	config.SetKnown("apm_config.obfuscation.remove_stack_traces")
 	config.SetKnown("apm_config.obfuscation.redis.enabled")
 	config.SetKnown("apm_config.obfuscation.memcached.enabled")
+	config.SetKnown("apm_config.obfuscation.graphql.enabled")
+	config.SetKnown("apm_config.obfuscation.grpc.enabled")
 	config.SetKnown("apm_config.extra_sample_rate")
 	config.SetKnown("apm_config.dd_agent_bin")
 	config.SetKnown("apm_config.max_events_per_second")
+	config.SetKnown("apm_config.max_events_per_second_by_service.*")
+	config.SetKnown("apm_config.min_tracer_versions.*")
 	config.SetKnown("apm_config.trace_writer.connection_limit")
 	config.SetKnown("apm_config.trace_writer.queue_size")
 	config.SetKnown("apm_config.service_writer.connection_limit")
@@ -718,14 +879,21 @@ func InitConfig(config Config) {
 	config.SetKnown("apm_config.analyzed_spans.*")
 	config.SetKnown("apm_config.log_throttling")
 	config.SetKnown("apm_config.bucket_size_seconds")
+	config.SetKnown("apm_config.bucket_window_count")
 	config.SetKnown("apm_config.receiver_timeout")
 	config.SetKnown("apm_config.watchdog_check_delay")
+	config.SetKnown("apm_config.watchdog_shedding")
 	config.SetKnown("apm_config.max_payload_size")
+	config.SetKnown("apm_config.max_spooled_payload_size")
+	config.SetKnown("apm_config.spool_dir")
+	config.SetKnown("apm_config.decoders")
+	config.SetKnown("apm_config.decoder_queue_size")
 
 	// inventories
 	config.BindEnvAndSetDefault("inventories_enabled", true)
 	config.BindEnvAndSetDefault("inventories_max_interval", 600) // 10min
 	config.BindEnvAndSetDefault("inventories_min_interval", 300) // 5min
+	config.BindEnvAndSetDefault("inventories_configuration_enabled", true)
 
 	// Datadog security agent (compliance)
 	config.BindEnvAndSetDefault("compliance_config.enabled", false)
@@ -733,6 +901,14 @@ func InitConfig(config Config) {
 	config.BindEnvAndSetDefault("compliance_config.dir", "/etc/datadog-agent/compliance.d")
 	config.BindEnvAndSetDefault("compliance_config.cmd_port", 5010)
 
+	// SBOM collection
+	config.BindEnvAndSetDefault("sbom.enabled", false)
+	config.BindEnvAndSetDefault("sbom.host.enabled", true)
+	config.BindEnvAndSetDefault("sbom.container_image.enabled", true)
+	config.BindEnvAndSetDefault("sbom.scan_interval", 10*time.Minute)
+	config.BindEnvAndSetDefault("sbom.send_interval", 10*time.Second)
+	config.BindEnvAndSetDefault("sbom.cache_ttl", 24*time.Hour)
+
 	// command line options
 	config.SetKnown("cmd.check.fullsketches")
 
@@ -753,6 +929,15 @@ func GetProxies() *Proxy {
 	return proxies
 }
 
+// GetProxiesForIntake returns the proxy settings from the configuration for an intake,
+// see ProxyIntakeLogs and ProxyIntakeAPM
+func GetProxiesForIntake(intake string) *Proxy {
+	if proxies == nil {
+		return nil
+	}
+	return proxies.ForIntake(intake)
+}
+
 // loadProxyFromEnv overrides the proxy settings with environment variables
 func loadProxyFromEnv(config Config) {
 	// Viper doesn't handle mixing nested variables from files and set
@@ -814,12 +999,28 @@ func loadProxyFromEnv(config Config) {
 		p.NoProxy = strings.Split(noProxy, ",") // comma-separated list, consistent with other tools that use the NO_PROXY env var
 	}
 
+	if logs, found := lookupEnv("DD_PROXY_LOGS"); found {
+		isSet = true
+		p.Logs = logs
+	}
+
+	if apm, found := lookupEnv("DD_PROXY_APM"); found {
+		isSet = true
+		p.APM = apm
+	}
+
 	// We have to set each value individually so both config.Get("proxy")
 	// and config.Get("proxy.http") work
 	if isSet {
 		config.Set("proxy.http", p.HTTP)
 		config.Set("proxy.https", p.HTTPS)
 		config.Set("proxy.no_proxy", p.NoProxy)
+		if p.Logs != "" {
+			config.Set("proxy.logs", p.Logs)
+		}
+		if p.APM != "" {
+			config.Set("proxy.apm", p.APM)
+		}
 		proxies = p
 	}
 }
@@ -892,6 +1093,9 @@ func load(config Config, origin string, loadSecret bool) (*Warnings, error) {
 	loadProxyFromEnv(config)
 	SanitizeAPIKeyConfig(config, "api_key")
 	applyOverrides(config)
+	if err := setupFipsEndpoints(config); err != nil {
+		return &warnings, err
+	}
 	// setTracemallocEnabled *must* be called before setNumWorkers
 	warnings.TraceMallocEnabledWithPy2 = setTracemallocEnabled(config)
 	setNumWorkers(config)
@@ -123,6 +123,15 @@ func TestSiteEnvVar(t *testing.T) {
 	assert.Equal(t, "https://external-agent.datadoghq.eu", externalAgentURL)
 }
 
+func TestCustomSensitiveRegexpsEnvVar(t *testing.T) {
+	os.Setenv("DD_CUSTOM_SENSITIVE_REGEXPS", "(db|cache)_pass(word)? token_[0-9]{1,3}")
+	defer os.Unsetenv("DD_CUSTOM_SENSITIVE_REGEXPS")
+	testConfig := setupConfFromYAML("")
+
+	assert.True(t, testConfig.IsSet("process_config.custom_sensitive_regexps"))
+	assert.Equal(t, []string{"(db|cache)_pass(word)?", "token_[0-9]{1,3}"}, testConfig.GetStringSlice("process_config.custom_sensitive_regexps"))
+}
+
 func TestDDURLEnvVar(t *testing.T) {
 	os.Setenv("DD_API_KEY", "fakeapikey")
 	os.Setenv("DD_DD_URL", "https://app.datadoghq.eu")
@@ -839,3 +848,51 @@ dogstatsd_mapper_profiles:
 	assert.Contains(t, err.Error(), expectedErrorMsg)
 	assert.Empty(t, profiles)
 }
+
+func TestSetupFipsEndpoints(t *testing.T) {
+	datadogYaml := `
+dd_url: https://somehost:1234
+fips:
+  enabled: true
+  port_range_start: 5000
+  https: false
+`
+	config := setupConfFromYAML(datadogYaml)
+	require.NoError(t, setupFipsEndpoints(config))
+
+	assert.Equal(t, "http://localhost:5001", config.GetString("dd_url"))
+	assert.Equal(t, "http://localhost:5002", config.GetString("apm_config.apm_dd_url"))
+	assert.Equal(t, "http://localhost:5003/v1/input", config.GetString("apm_config.profiling_dd_url"))
+	assert.Equal(t, "http://localhost:5004", config.GetString("process_config.process_dd_url"))
+	assert.Equal(t, "localhost:5005", config.GetString("logs_config.logs_dd_url"))
+	assert.True(t, config.GetBool("logs_config.use_http"))
+	assert.True(t, config.GetBool("logs_config.logs_no_ssl"))
+	assert.Equal(t, "http://localhost:5006", config.GetString("process_config.orchestrator_dd_url"))
+	assert.False(t, config.GetBool("skip_ssl_validation"))
+}
+
+func TestSetupFipsEndpointsDisabled(t *testing.T) {
+	config := setupConfFromYAML("dd_url: https://somehost:1234")
+	require.NoError(t, setupFipsEndpoints(config))
+	assert.Equal(t, "https://somehost:1234", config.GetString("dd_url"))
+}
+
+func TestLoadProxyIntakeOverridesFromEnv(t *testing.T) {
+	config := setupConf()
+
+	os.Setenv("DD_PROXY_HTTPS", "https_url")
+	os.Setenv("DD_PROXY_LOGS", "logs_url")
+	os.Setenv("DD_PROXY_APM", "apm_url")
+	defer os.Unsetenv("DD_PROXY_HTTPS")
+	defer os.Unsetenv("DD_PROXY_LOGS")
+	defer os.Unsetenv("DD_PROXY_APM")
+
+	loadProxyFromEnv(config)
+
+	proxies := GetProxies()
+	assert.Equal(t, &Proxy{HTTPS: "https_url", Logs: "logs_url", APM: "apm_url"}, proxies)
+	assert.Equal(t, "logs_url", config.GetString("proxy.logs"))
+	assert.Equal(t, &Proxy{HTTP: "logs_url", HTTPS: "logs_url"}, GetProxiesForIntake(ProxyIntakeLogs))
+	assert.Equal(t, &Proxy{HTTP: "apm_url", HTTPS: "apm_url"}, GetProxiesForIntake(ProxyIntakeAPM))
+	assert.Equal(t, proxies, GetProxiesForIntake(""))
+}
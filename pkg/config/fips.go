@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// FipsProxyEndpoint is the port of the local FIPS proxy forwarding the traffic of a product
+type FipsProxyEndpoint struct {
+	Product string
+	// Offset is the offset of the port of the product from `fips.port_range_start`
+	Offset int
+}
+
+// FipsProxyEndpoints lists the ports of the local FIPS proxy, the first port of the range is
+// reserved for the status of the proxy
+var FipsProxyEndpoints = []FipsProxyEndpoint{
+	{Product: "metrics", Offset: 1},
+	{Product: "traces", Offset: 2},
+	{Product: "profiles", Offset: 3},
+	{Product: "processes", Offset: 4},
+	{Product: "logs", Offset: 5},
+	{Product: "orchestrator", Offset: 6},
+}
+
+// FipsProxyAddress returns the address of the local FIPS proxy port forwarding the traffic of a product
+func FipsProxyAddress(config Config, e FipsProxyEndpoint) string {
+	port := config.GetInt("fips.port_range_start") + e.Offset
+	return net.JoinHostPort(config.GetString("fips.local_address"), strconv.Itoa(port))
+}
+
+// setupFipsEndpoints routes the intake traffic of every product through the local FIPS proxy
+// when `fips.enabled` is set, overriding the configured endpoints
+func setupFipsEndpoints(config Config) error {
+	if !config.GetBool("fips.enabled") {
+		return nil
+	}
+
+	if config.GetInt("fips.port_range_start") <= 0 {
+		return fmt.Errorf("`fips.port_range_start` must be a valid port number, got %d", config.GetInt("fips.port_range_start"))
+	}
+
+	scheme := "https://"
+	if !config.GetBool("fips.https") {
+		scheme = "http://"
+	}
+
+	addresses := make(map[string]string, len(FipsProxyEndpoints))
+	for _, e := range FipsProxyEndpoints {
+		addresses[e.Product] = FipsProxyAddress(config, e)
+	}
+	log.Infof("FIPS mode is enabled, the intake traffic is sent through the local FIPS proxy on %s", config.GetString("fips.local_address"))
+
+	config.Set("dd_url", scheme+addresses["metrics"])
+	config.Set("apm_config.apm_dd_url", scheme+addresses["traces"])
+	config.Set("apm_config.profiling_dd_url", scheme+addresses["profiles"]+"/v1/input")
+	config.Set("internal_profiling.profile_dd_url", scheme+addresses["profiles"]+"/v1/input")
+	config.Set("process_config.process_dd_url", scheme+addresses["processes"])
+	config.Set("process_config.orchestrator_dd_url", scheme+addresses["orchestrator"])
+
+	// the logs are sent over HTTP to the proxy
+	config.Set("logs_config.use_http", true)
+	config.Set("logs_config.logs_dd_url", addresses["logs"])
+	config.Set("logs_config.logs_no_ssl", !config.GetBool("fips.https"))
+
+	if !config.GetBool("fips.tls_verify") {
+		config.Set("skip_ssl_validation", true)
+	}
+
+	if len(config.GetStringMapStringSlice("additional_endpoints")) > 0 {
+		log.Warnf("FIPS mode is enabled but `additional_endpoints` are configured, their traffic does not go through the FIPS proxy")
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -49,10 +50,53 @@ func createQuoteMsgFormatter(params string) seelog.FormatterFunc {
 	}
 }
 
+// pythonLogRegexp matches the messages logged from Python, formatted by the checks base as
+// "<check_id> | (<file>:<line>) | <message>", the check ID being "-" outside of the checks
+var pythonLogRegexp = regexp.MustCompile(`^(-|\w+(?::[0-9a-f]+)?) \| \(([^():]+:\d+)\) \| `)
+
+// createStructuredMsgFormatter returns the formatter of the structured fields of the JSON logs:
+// the component, from the package of the caller, and for the messages logged from Python, the
+// source file and line and the check, the message itself being stripped of them.
+func createStructuredMsgFormatter(params string) seelog.FormatterFunc {
+	return func(message string, level seelog.LogLevel, context seelog.LogContextInterface) interface{} {
+		return structuredFields(extractShortPathFromFullPath(context.FullPath()), message)
+	}
+}
+
+func structuredFields(shortPath, message string) string {
+	var b strings.Builder
+	field := func(key, value string) {
+		b.WriteString(strconv.Quote(key))
+		b.WriteByte(':')
+		b.WriteString(strconv.Quote(value))
+		b.WriteByte(',')
+	}
+
+	if dir := filepath.ToSlash(filepath.Dir(shortPath)); dir != "." {
+		field("component", strings.TrimPrefix(strings.TrimPrefix(dir, "pkg/"), "cmd/"))
+	}
+	if m := pythonLogRegexp.FindStringSubmatch(message); m != nil {
+		if checkID := m[1]; checkID != "-" {
+			check := checkID
+			if i := strings.IndexByte(check, ':'); i >= 0 {
+				check = check[:i]
+			}
+			field("check", check)
+			field("check_id", checkID)
+		}
+		field("source", m[2])
+		message = message[len(m[0]):]
+	}
+	b.WriteString(`"msg":`)
+	b.WriteString(strconv.Quote(message))
+	return b.String()
+}
+
 // buildJSONFormat returns the log JSON format seelog string
 func buildJSONFormat(loggerName LoggerName) string {
-	seelog.RegisterCustomFormatter("QuoteMsg", createQuoteMsgFormatter) //nolint:errcheck
-	return fmt.Sprintf(`{"agent":"%s","time":"%%Date(%s)","level":"%%LEVEL","file":"%%ShortFilePath","line":"%%Line","func":"%%FuncShort","msg":%%QuoteMsg}%%n`, strings.ToLower(string(loggerName)), getLogDateFormat())
+	seelog.RegisterCustomFormatter("QuoteMsg", createQuoteMsgFormatter)           //nolint:errcheck
+	seelog.RegisterCustomFormatter("StructuredMsg", createStructuredMsgFormatter) //nolint:errcheck
+	return fmt.Sprintf(`{"agent":"%s","time":"%%Date(%s)","level":"%%LEVEL","file":"%%ShortFilePath","line":"%%Line","func":"%%FuncShort",%%StructuredMsg}%%n`, strings.ToLower(string(loggerName)), getLogDateFormat())
 }
 
 func getSyslogTLSKeyPair() (*tls.Certificate, error) {
@@ -96,6 +140,9 @@ func SetupLogger(loggerName LoggerName, logLevel, logFile, syslogURI string, sys
 	seelogConfig = seelogCfg.NewSeelogConfig(string(loggerName), seelogLogLevel, formatID, buildJSONFormat(loggerName), buildCommonFormat(loggerName), syslogRFC)
 	seelogConfig.EnableConsoleLog(logToConsole)
 	seelogConfig.EnableFileLogging(logFile, Datadog.GetSizeInBytes("log_file_max_size"), uint(Datadog.GetInt("log_file_max_rolls")))
+	if maxAge := Datadog.GetDuration("log_file_max_age"); maxAge > 0 {
+		seelogConfig.SetFileMaxAge(uint(maxAge / time.Second))
+	}
 
 	if syslogURI != "" { // non-blank uri enables syslog
 		syslogTLSKeyPair, err := getSyslogTLSKeyPair()
@@ -362,4 +409,5 @@ func init() {
 	seelog.RegisterCustomFormatter("CustomSyslogHeader", createSyslogHeaderFormatter) //nolint:errcheck
 	seelog.RegisterCustomFormatter("ShortFilePath", parseShortFilePath)               //nolint:errcheck
 	seelog.RegisterReceiver("syslog", &SyslogReceiver{})
+	seelog.RegisterReceiver("rollingfile", &RollingFileReceiver{})
 }
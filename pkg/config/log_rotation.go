@@ -0,0 +1,170 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cihub/seelog"
+)
+
+// RollingFileReceiver implements seelog.CustomReceiver, writing the logs to a file which is
+// rolled when it reaches a maximum size or a maximum age, whichever comes first. The rolled
+// files are named after the log file with a numeric suffix, the most recent being ".1", and
+// only the given number of them are kept.
+type RollingFileReceiver struct {
+	mu sync.Mutex
+
+	filename string
+	maxSize  int64
+	maxRolls int // 0 to keep all the rolled files
+	maxAge   time.Duration
+
+	file     *os.File
+	size     int64
+	openedAt time.Time // the age of the file is counted from when the agent opened it
+
+	// now is replaced by the tests
+	now func() time.Time
+}
+
+// ReceiveMessage writes the log message to the file, rolling it first if needed
+func (r *RollingFileReceiver) ReceiveMessage(message string, level seelog.LogLevel, context seelog.LogContextInterface) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file != nil && r.shouldRoll(int64(len(message))) {
+		if err := r.roll(); err != nil {
+			return err
+		}
+	}
+	if r.file == nil {
+		if err := r.open(); err != nil {
+			return err
+		}
+	}
+	n, err := r.file.WriteString(message)
+	r.size += int64(n)
+	return err
+}
+
+func (r *RollingFileReceiver) shouldRoll(messageSize int64) bool {
+	if r.maxSize > 0 && r.size > 0 && r.size+messageSize > r.maxSize {
+		return true
+	}
+	return r.maxAge > 0 && r.now().Sub(r.openedAt) >= r.maxAge
+}
+
+func (r *RollingFileReceiver) open() error {
+	if err := os.MkdirAll(filepath.Dir(r.filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(r.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.file = f
+	r.size = info.Size()
+	r.openedAt = r.now()
+	return nil
+}
+
+// roll closes the log file and shifts the rolled files, removing the ones above maxRolls
+func (r *RollingFileReceiver) roll() error {
+	r.file.Close()
+	r.file = nil
+
+	rolls := r.rolledFiles()
+	for i := len(rolls) - 1; i >= 0; i-- {
+		n := rolls[i]
+		if r.maxRolls > 0 && n >= r.maxRolls {
+			os.Remove(r.rolledFilename(n)) //nolint:errcheck
+			continue
+		}
+		if err := os.Rename(r.rolledFilename(n), r.rolledFilename(n+1)); err != nil {
+			return err
+		}
+	}
+	return os.Rename(r.filename, r.rolledFilename(1))
+}
+
+// rolledFiles returns the sorted numbers of the existing rolled files
+func (r *RollingFileReceiver) rolledFiles() []int {
+	matches, _ := filepath.Glob(r.filename + ".*")
+	rolls := make([]int, 0, len(matches))
+	for _, m := range matches {
+		if n, err := strconv.Atoi(strings.TrimPrefix(m, r.filename+".")); err == nil && n > 0 {
+			rolls = append(rolls, n)
+		}
+	}
+	sort.Ints(rolls)
+	return rolls
+}
+
+func (r *RollingFileReceiver) rolledFilename(n int) string {
+	return fmt.Sprintf("%s.%d", r.filename, n)
+}
+
+// AfterParse parses the receiver configuration
+func (r *RollingFileReceiver) AfterParse(initArgs seelog.CustomReceiverInitArgs) error {
+	r.now = time.Now
+	r.filename = initArgs.XmlCustomAttrs["filename"]
+	if r.filename == "" {
+		return errors.New("bad rolling file receiver configuration: missing filename")
+	}
+	for attr, v := range initArgs.XmlCustomAttrs {
+		if attr == "filename" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("bad rolling file receiver configuration: invalid %s: %s", attr, err)
+		}
+		switch attr {
+		case "maxsize":
+			r.maxSize = n
+		case "maxrolls":
+			r.maxRolls = int(n)
+		case "maxage":
+			r.maxAge = time.Duration(n) * time.Second
+		}
+	}
+	return nil
+}
+
+// Flush syncs the log file to the disk
+func (r *RollingFileReceiver) Flush() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file != nil {
+		r.file.Sync() //nolint:errcheck
+	}
+}
+
+// Close closes the log file
+func (r *RollingFileReceiver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
@@ -0,0 +1,95 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRollingFileReceiver(t *testing.T, attrs map[string]string) (*RollingFileReceiver, *time.Time) {
+	r := &RollingFileReceiver{}
+	require.NoError(t, r.AfterParse(seelog.CustomReceiverInitArgs{XmlCustomAttrs: attrs}))
+	now := time.Now()
+	r.now = func() time.Time { return now }
+	return r, &now
+}
+
+func readLogFiles(t *testing.T, dir string) map[string]string {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	contents := make(map[string]string)
+	for _, f := range files {
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		require.NoError(t, err)
+		contents[f.Name()] = string(b)
+	}
+	return contents
+}
+
+func TestRollingFileReceiverSize(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rolling")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, _ := newTestRollingFileReceiver(t, map[string]string{
+		"filename": filepath.Join(dir, "agent.log"),
+		"maxsize":  "10",
+		"maxrolls": "2",
+	})
+	defer r.Close()
+
+	for _, msg := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		require.NoError(t, r.ReceiveMessage(msg, seelog.InfoLvl, nil))
+	}
+	assert.Equal(t, map[string]string{
+		"agent.log":   "fourth\n",
+		"agent.log.1": "third\n",
+		"agent.log.2": "second\n",
+	}, readLogFiles(t, dir))
+}
+
+func TestRollingFileReceiverAge(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rolling")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	r, now := newTestRollingFileReceiver(t, map[string]string{
+		"filename": filepath.Join(dir, "agent.log"),
+		"maxsize":  "1000",
+		"maxrolls": "0",
+		"maxage":   "3600",
+	})
+	defer r.Close()
+
+	require.NoError(t, r.ReceiveMessage("first\n", seelog.InfoLvl, nil))
+	*now = now.Add(30 * time.Minute)
+	require.NoError(t, r.ReceiveMessage("second\n", seelog.InfoLvl, nil))
+	*now = now.Add(30 * time.Minute)
+	require.NoError(t, r.ReceiveMessage("third\n", seelog.InfoLvl, nil))
+	*now = now.Add(2 * time.Hour)
+	require.NoError(t, r.ReceiveMessage("fourth\n", seelog.InfoLvl, nil))
+
+	// all the rolled files are kept without maxrolls
+	assert.Equal(t, map[string]string{
+		"agent.log":   "fourth\n",
+		"agent.log.1": "third\n",
+		"agent.log.2": "first\nsecond\n",
+	}, readLogFiles(t, dir))
+}
+
+func TestRollingFileReceiverConfig(t *testing.T) {
+	r := &RollingFileReceiver{}
+	assert.Error(t, r.AfterParse(seelog.CustomReceiverInitArgs{XmlCustomAttrs: map[string]string{}}))
+	assert.Error(t, r.AfterParse(seelog.CustomReceiverInitArgs{XmlCustomAttrs: map[string]string{"filename": "agent.log", "maxage": "1d"}}))
+}
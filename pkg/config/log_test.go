@@ -8,6 +8,7 @@ package config
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"testing"
 
 	seelogCfg "github.com/DataDog/datadog-agent/pkg/config/seelog"
@@ -28,6 +29,49 @@ func TestExtractShortPathFromFullPath(t *testing.T) {
 	assert.Equal(t, "cmd/agent/collector.go", extractShortPathFromFullPath("/home/jenkins/workspace/process-agent-build-ddagent/go/src/github.com/DataDog/datadog-process-agent/cmd/agent/collector.go"))
 }
 
+func TestStructuredFields(t *testing.T) {
+	for _, tc := range []struct {
+		shortPath, message string
+		fields             map[string]string
+	}{
+		{
+			shortPath: "pkg/collector/scheduler.go",
+			message:   "Scheduling check disk",
+			fields:    map[string]string{"component": "collector", "msg": "Scheduling check disk"},
+		},
+		{
+			shortPath: "cmd/agent/app/start.go",
+			message:   `a "quoted" | message`,
+			fields:    map[string]string{"component": "agent/app", "msg": `a "quoted" | message`},
+		},
+		{
+			shortPath: "pkg/collector/python/datadog_agent.go",
+			message:   "disk:e5dffb8bef24336f | (disk.py:47) | Unable to get disk metrics",
+			fields: map[string]string{
+				"component": "collector/python",
+				"check":     "disk",
+				"check_id":  "disk:e5dffb8bef24336f",
+				"source":    "disk.py:47",
+				"msg":       "Unable to get disk metrics",
+			},
+		},
+		{
+			shortPath: "pkg/collector/python/datadog_agent.go",
+			message:   "- | (base.py:120) | Not in a check",
+			fields:    map[string]string{"component": "collector/python", "source": "base.py:120", "msg": "Not in a check"},
+		},
+		{
+			shortPath: "main.go",
+			message:   "disk | not a python log",
+			fields:    map[string]string{"msg": "disk | not a python log"},
+		},
+	} {
+		var fields map[string]string
+		assert.NoError(t, json.Unmarshal([]byte("{"+structuredFields(tc.shortPath, tc.message)+"}"), &fields), tc.message)
+		assert.Equal(t, tc.fields, fields, tc.message)
+	}
+}
+
 func TestSeelogConfig(t *testing.T) {
 	cfg := seelogCfg.NewSeelogConfig("TEST", "off", "common", "", "", false)
 	cfg.EnableConsoleLog(true)
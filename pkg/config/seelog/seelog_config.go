@@ -22,7 +22,7 @@ const seelogConfigurationTemplate = `
 <seelog minlevel="{{.logLevel}}">
 	<outputs formatid="{{.format}}">
 		{{if .consoleLoggingEnabled}}<console />{{end}}
-		{{if .logfile              }}<rollingfile type="size" filename="{{.logfile}}" maxsize="{{.maxsize}}" maxrolls="{{.maxrolls}}" />{{end}}
+		{{if .logfile              }}{{if .maxage}}<custom name="rollingfile" data-filename="{{.logfile}}" data-maxsize="{{.maxsize}}" data-maxrolls="{{.maxrolls}}" data-maxage="{{.maxage}}" />{{else}}<rollingfile type="size" filename="{{.logfile}}" maxsize="{{.maxsize}}" maxrolls="{{.maxrolls}}" />{{end}}{{end}}
 		{{if .syslogURI            }}<custom name="syslog" formatid="syslog-{{.format}}" data-uri="{{.syslogURI}}" data-tls="{{.syslogUseTLS}}" />{{end}}
 	</outputs>
 	<formats>
@@ -77,6 +77,12 @@ func (c *Config) EnableFileLogging(f string, maxsize, maxrolls uint) {
 	c.settings["maxrolls"] = maxrolls
 }
 
+// SetFileMaxAge makes the log file also roll when it's older than the given number of seconds,
+// the log file only rolls by size when it's 0
+func (c *Config) SetFileMaxAge(maxage uint) {
+	c.setValue("maxage", maxage)
+}
+
 // ConfigureSyslog enables and configures syslog if the syslogURI it not an empty string
 func (c *Config) ConfigureSyslog(syslogURI string, usetTLS bool) {
 	c.Lock()
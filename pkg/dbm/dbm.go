@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package dbm sends the payloads of the database monitoring: the query samples and execution plans,
+// and the normalized query metrics collected by the postgres, mysql and sqlserver checks.
+// These payloads are large and already aggregated by the checks, so they bypass the aggregator
+// and go straight to their event platform tracks.
+package dbm
+
+import (
+	"expvar"
+	"fmt"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
+)
+
+var (
+	forwarder      epforwarder.EventPlatformForwarder
+	forwarderMutex sync.RWMutex
+
+	// obfuscator is shared by the checks, it keeps no state between two queries
+	obfuscator = obfuscate.NewObfuscator(nil)
+
+	dbmExpvars       = expvar.NewMap("dbm")
+	submittedPayload = expvar.Map{}
+	droppedPayloads  = expvar.Map{}
+
+	tlmSubmitted = telemetry.NewCounter("dbm", "submitted_payloads",
+		[]string{"event_type"}, "Count of the database monitoring payloads submitted by the checks")
+	tlmDropped = telemetry.NewCounter("dbm", "dropped_payloads",
+		[]string{"event_type"}, "Count of the database monitoring payloads dropped before being sent")
+)
+
+func init() {
+	submittedPayload.Init()
+	droppedPayloads.Init()
+	dbmExpvars.Set("SubmittedPayloads", &submittedPayload)
+	dbmExpvars.Set("DroppedPayloads", &droppedPayloads)
+}
+
+// IsEventType returns whether the event type is one of the database monitoring payloads
+func IsEventType(eventType string) bool {
+	return eventType == epforwarder.EventTypeDBMSamples || eventType == epforwarder.EventTypeDBMMetrics
+}
+
+// SetForwarder sets the forwarder sending the payloads, the payloads submitted before are dropped
+func SetForwarder(f epforwarder.EventPlatformForwarder) {
+	forwarderMutex.Lock()
+	defer forwarderMutex.Unlock()
+	forwarder = f
+}
+
+// Submit queues a payload to be sent to the track of its type. It never blocks the check: the
+// payload is dropped and an error returned when no forwarder is set or when the track can't keep up.
+func Submit(rawEvent string, eventType string) error {
+	if !IsEventType(eventType) {
+		return fmt.Errorf("%s is not a database monitoring event type", eventType)
+	}
+	submittedPayload.Add(eventType, 1)
+	tlmSubmitted.Inc(eventType)
+
+	forwarderMutex.RLock()
+	defer forwarderMutex.RUnlock()
+	if forwarder == nil {
+		droppedPayloads.Add(eventType, 1)
+		tlmDropped.Inc(eventType)
+		return fmt.Errorf("no forwarder to send the %s payloads", eventType)
+	}
+	if err := forwarder.SendEventPlatformEvent(message.NewMessage([]byte(rawEvent), nil, ""), eventType); err != nil {
+		droppedPayloads.Add(eventType, 1)
+		tlmDropped.Inc(eventType)
+		return err
+	}
+	return nil
+}
+
+// ObfuscateQuery obfuscates and normalizes a query, so that the samples and the metrics of the
+// queries only differing by their literals are grouped together
+func ObfuscateQuery(query string) (string, error) {
+	oq, err := obfuscator.ObfuscateSQLString(query)
+	if err != nil {
+		return "", err
+	}
+	return oq.Query, nil
+}
+
+// ObfuscateExecPlan obfuscates a JSON execution plan, see obfuscate.Obfuscator.ObfuscateSQLExecPlan
+func ObfuscateExecPlan(plan string, normalize bool) (string, error) {
+	return obfuscator.ObfuscateSQLExecPlan(plan, normalize)
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package dbm
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type recordingForwarder struct {
+	events map[string][]string
+	err    error
+}
+
+func (f *recordingForwarder) SendEventPlatformEvent(e *message.Message, eventType string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.events[eventType] = append(f.events[eventType], string(e.Content))
+	return nil
+}
+func (f *recordingForwarder) Start() {}
+func (f *recordingForwarder) Stop()  {}
+
+func TestSubmit(t *testing.T) {
+	f := &recordingForwarder{events: make(map[string][]string)}
+	SetForwarder(f)
+	defer SetForwarder(nil)
+
+	require.NoError(t, Submit(`{"query":"SELECT ?"}`, epforwarder.EventTypeDBMSamples))
+	require.NoError(t, Submit(`{"rows":1}`, epforwarder.EventTypeDBMMetrics))
+	assert.Equal(t, []string{`{"query":"SELECT ?"}`}, f.events[epforwarder.EventTypeDBMSamples])
+	assert.Equal(t, []string{`{"rows":1}`}, f.events[epforwarder.EventTypeDBMMetrics])
+
+	assert.Error(t, Submit(`{}`, epforwarder.EventTypeNetworkDevicesMetadata))
+	assert.Len(t, f.events[epforwarder.EventTypeNetworkDevicesMetadata], 0)
+}
+
+func TestSubmitDropped(t *testing.T) {
+	SetForwarder(nil)
+	assert.Error(t, Submit(`{}`, epforwarder.EventTypeDBMSamples))
+
+	SetForwarder(&recordingForwarder{err: errors.New("full")})
+	defer SetForwarder(nil)
+	assert.Error(t, Submit(`{}`, epforwarder.EventTypeDBMSamples))
+}
+
+func TestObfuscateQuery(t *testing.T) {
+	query, err := ObfuscateQuery("SELECT * FROM users WHERE id = 42")
+	require.NoError(t, err)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", query)
+}
+
+func TestObfuscateExecPlan(t *testing.T) {
+	plan, err := ObfuscateExecPlan(`{"Plan":{"Node Type":"Seq Scan","Filter":"(id = 42)","Total Cost":12.5}}`, true)
+	require.NoError(t, err)
+	assert.Equal(t, `{"Plan":{"Filter":"( id = ? )","Node Type":"Seq Scan"}}`, plan)
+}
@@ -0,0 +1,52 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnosis
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MissingCredentialsError is returned by the diagnosis failing because the agent lacks the
+// credentials or the permissions to query a provider
+type MissingCredentialsError struct {
+	// Credentials describes what is missing
+	Credentials string
+	Err         error
+}
+
+// NewMissingCredentialsError returns a MissingCredentialsError
+func NewMissingCredentialsError(credentials string, err error) *MissingCredentialsError {
+	return &MissingCredentialsError{Credentials: credentials, Err: err}
+}
+
+func (e *MissingCredentialsError) Error() string {
+	return fmt.Sprintf("missing %s: %v", e.Credentials, e.Err)
+}
+
+// Unwrap returns the error of the provider
+func (e *MissingCredentialsError) Unwrap() error {
+	return e.Err
+}
+
+// IsPermissionDenied returns whether an error is caused by a lack of permission on a file or socket
+func IsPermissionDenied(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "permission denied")
+}
+
+// IsUnauthorized returns whether an error is caused by an HTTP API rejecting the credentials
+func IsUnauthorized(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"401", "403", "unauthorized", "forbidden"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
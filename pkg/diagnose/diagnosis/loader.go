@@ -13,12 +13,26 @@ type Catalog map[string]Diagnosis
 // DefaultCatalog holds every compiled-in diagnosis
 var DefaultCatalog = make(Catalog)
 
+// MetadataAvailabilityCatalog holds the diagnosis of the host metadata providers: the cloud provider
+// metadata endpoints, the kubelet and the container runtimes
+var MetadataAvailabilityCatalog = make(Catalog)
+
 // Register a diagnosis that will be called on diagnose
 func Register(name string, d Diagnosis) {
-	if _, ok := DefaultCatalog[name]; ok {
+	DefaultCatalog.register(name, d)
+}
+
+// RegisterMetadataAvailability registers the diagnosis of a host metadata provider, it will be
+// called on diagnose and on diagnose metadata-availability
+func RegisterMetadataAvailability(name string, d Diagnosis) {
+	MetadataAvailabilityCatalog.register(name, d)
+}
+
+func (c Catalog) register(name string, d Diagnosis) {
+	if _, ok := c[name]; ok {
 		log.Warnf("Diagnosis %s already registered, overriding it", name)
 	}
-	DefaultCatalog[name] = d
+	c[name] = d
 }
 
 // Diagnosis should return an error to report its health
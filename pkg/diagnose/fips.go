@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnose
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/diagnosis"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const fipsProxyDialTimeout = 2 * time.Second
+
+func init() {
+	diagnosis.Register("FIPS proxy availability", CheckFipsProxy)
+}
+
+// CheckFipsProxy checks that every port of the local FIPS proxy is reachable, when the FIPS mode is enabled
+func CheckFipsProxy() error {
+	if !config.Datadog.GetBool("fips.enabled") {
+		log.Info("FIPS mode is disabled")
+		return nil
+	}
+
+	var unreachable []string
+	for _, e := range config.FipsProxyEndpoints {
+		address := config.FipsProxyAddress(config.Datadog, e)
+		conn, err := net.DialTimeout("tcp", address, fipsProxyDialTimeout)
+		if err != nil {
+			log.Errorf("The FIPS proxy is unreachable for the %s on %s: %v", e.Product, address, err)
+			unreachable = append(unreachable, e.Product)
+			continue
+		}
+		conn.Close()
+		log.Infof("The FIPS proxy is reachable for the %s on %s", e.Product, address)
+	}
+
+	if len(unreachable) > 0 {
+		return fmt.Errorf("the FIPS proxy is unreachable for: %s", strings.Join(unreachable, ", "))
+	}
+	return nil
+}
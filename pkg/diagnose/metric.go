@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnose
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
+
+	"github.com/fatih/color"
+)
+
+// RunMetric asks the running agent to trace a metric through its pipeline for the given
+// duration, and writes the stage where the metric disappears in writer
+func RunMetric(w io.Writer, name string, duration time.Duration) error {
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	c := util.GetIPCClient()
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("name", name)
+	// the trace outlives the command a bit, in case it's interrupted before stopping it
+	query.Set("ttl", (duration + time.Minute).String())
+	urlstr := fmt.Sprintf("https://%v:%v/agent/diagnose/metric?%s", ipcAddress, config.Datadog.GetInt("cmd_port"), query.Encode())
+
+	if _, err := util.DoPost(c, urlstr, "application/json", nil); err != nil {
+		return fmt.Errorf("failed to start the trace of the metric (is the agent running?): %v", err)
+	}
+	defer util.DoDelete(c, urlstr) //nolint:errcheck
+
+	fmt.Fprintf(w, "Tracing %s for %s...\n", name, duration)
+	time.Sleep(duration)
+
+	r, err := util.DoGet(c, urlstr)
+	if err != nil {
+		return fmt.Errorf("failed to get the trace of the metric: %v", err)
+	}
+	var report metrictracer.Report
+	if err := json.Unmarshal(r, &report); err != nil {
+		return err
+	}
+	writeMetricTrace(w, report)
+	return nil
+}
+
+// writeMetricTrace writes the counts of each stage and the diagnosis
+func writeMetricTrace(w io.Writer, report metrictracer.Report) {
+	fmt.Fprintln(w, fmt.Sprintf("=== %s ===", color.BlueString("Trace of %s over %s", report.Name, report.End.Sub(report.Start).Round(time.Second))))
+	for _, stage := range metrictracer.Stages {
+		fmt.Fprintf(w, "  %-18s %d\n", stage, report.Counts[stage])
+	}
+	found, diagnosis := diagnoseMetricTrace(report)
+	if found {
+		fmt.Fprintln(w, color.GreenString(diagnosis))
+	} else {
+		fmt.Fprintln(w, color.YellowString(diagnosis))
+	}
+}
+
+// diagnoseMetricTrace returns whether the metric reached the forwarder, and otherwise where it
+// disappeared
+func diagnoseMetricTrace(report metrictracer.Report) (bool, string) {
+	counts := report.Counts
+	switch {
+	case counts[metrictracer.StageSerialized] > 0:
+		return true, "The metric was sent to the forwarder, check the forwarder errors of the agent status if it's still missing"
+	case counts[metrictracer.StageSerializerError] > 0:
+		return false, "The metric was lost by the serializer, which failed to send its payloads, see the agent logs"
+	case counts[metrictracer.StageFlushed] > 0 && counts[metrictracer.StageExcluded] >= counts[metrictracer.StageFlushed]:
+		return false, "The metric was dropped by the metrics_exclude rules"
+	case counts[metrictracer.StageFlushed] > 0:
+		return false, "The metric was flushed but not sent yet, trace it for longer"
+	case counts[metrictracer.StageAggregator] > 0:
+		return false, "The metric was aggregated but not flushed yet, trace it for longer than the flush interval of 15s"
+	case counts[metrictracer.StageDogStatsD] > 0:
+		return false, "The metric was received by DogStatsD but didn't reach the aggregator, check the dropped packets of the DogStatsD stats"
+	case counts[metrictracer.StageDogStatsDError] > 0:
+		return false, "The metric was received by DogStatsD but could not be parsed, check the parse errors in the agent logs"
+	}
+	return false, "The metric was never received, check its name and that the client or the check sends it to this agent"
+}
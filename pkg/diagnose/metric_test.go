@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnose
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnoseMetricTrace(t *testing.T) {
+	for _, tc := range []struct {
+		counts    map[metrictracer.Stage]int64
+		found     bool
+		diagnosis string
+	}{
+		{
+			counts:    map[metrictracer.Stage]int64{},
+			diagnosis: "never received",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageDogStatsDError: 4},
+			diagnosis: "could not be parsed",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageDogStatsD: 4},
+			diagnosis: "didn't reach the aggregator",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageDogStatsD: 4, metrictracer.StageAggregator: 4},
+			diagnosis: "not flushed yet",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageAggregator: 4, metrictracer.StageFlushed: 2, metrictracer.StageExcluded: 2},
+			diagnosis: "metrics_exclude",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageAggregator: 4, metrictracer.StageFlushed: 2, metrictracer.StageSerializerError: 2},
+			diagnosis: "serializer",
+		},
+		{
+			counts:    map[metrictracer.Stage]int64{metrictracer.StageAggregator: 4, metrictracer.StageFlushed: 2, metrictracer.StageExcluded: 1, metrictracer.StageSerialized: 1},
+			found:     true,
+			diagnosis: "sent to the forwarder",
+		},
+	} {
+		found, diagnosis := diagnoseMetricTrace(metrictracer.Report{Name: "my.metric", Counts: tc.counts})
+		assert.Equal(t, tc.found, found)
+		assert.Contains(t, diagnosis, tc.diagnosis)
+	}
+}
+
+func TestWriteMetricTrace(t *testing.T) {
+	start := time.Now()
+	w := &bytes.Buffer{}
+	writeMetricTrace(w, metrictracer.Report{
+		Name:   "my.metric",
+		Start:  start,
+		End:    start.Add(30 * time.Second),
+		Counts: map[metrictracer.Stage]int64{metrictracer.StageDogStatsD: 4},
+	})
+
+	result := w.String()
+	assert.Contains(t, result, "Trace of my.metric over 30s")
+	assert.Contains(t, result, "  dogstatsd          4\n")
+	assert.Contains(t, result, "  aggregator         0\n")
+	assert.Contains(t, result, "didn't reach the aggregator")
+}
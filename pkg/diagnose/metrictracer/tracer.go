@@ -0,0 +1,184 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package metrictracer follows a metric name through the stages of the metrics pipeline, from
+// DogStatsD to the serializer, to find out where a missing metric disappears.
+package metrictracer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stage is a stage of the metrics pipeline
+type Stage string
+
+const (
+	// StageDogStatsDError counts the DogStatsD messages of the metric which could not be parsed
+	StageDogStatsDError Stage = "dogstatsd_error"
+	// StageDogStatsD counts the samples parsed by DogStatsD
+	StageDogStatsD Stage = "dogstatsd"
+	// StageAggregator counts the samples added to the aggregator, by DogStatsD or the checks
+	StageAggregator Stage = "aggregator"
+	// StageFlushed counts the series and sketches flushed by the aggregator
+	StageFlushed Stage = "flushed"
+	// StageExcluded counts the series and sketches dropped by the metrics_exclude rules
+	StageExcluded Stage = "excluded"
+	// StageSerialized counts the series and sketches accepted by the serializer
+	StageSerialized Stage = "serialized"
+	// StageSerializerError counts the series and sketches the serializer failed to send
+	StageSerializerError Stage = "serializer_error"
+)
+
+// Stages are the stages in the order of the pipeline
+var Stages = []Stage{StageDogStatsDError, StageDogStatsD, StageAggregator, StageFlushed, StageExcluded, StageSerialized, StageSerializerError}
+
+// DefaultTTL is the duration after which a trace is stopped if it's not stopped explicitly
+const DefaultTTL = 2 * time.Minute
+
+// Report is what a trace observed for a metric name
+type Report struct {
+	Name    string          `json:"name"`
+	Start   time.Time       `json:"start"`
+	End     time.Time       `json:"end"`
+	Counts  map[Stage]int64 `json:"counts"`
+	Expired bool            `json:"expired"`
+}
+
+type trace struct {
+	start   time.Time
+	expires time.Time
+	counts  map[Stage]*int64
+	// expired and timer are protected by mu
+	expired bool
+	timer   *time.Timer
+}
+
+var (
+	// active is the number of traces which are not expired, it's checked without locking on the hot paths
+	active int32
+	mu     sync.RWMutex
+	traces = map[string]*trace{}
+	// now is replaced by the tests
+	now = time.Now
+	// expiredRetention is how long the report of an expired trace can still be read,
+	// it's replaced by the tests
+	expiredRetention = DefaultTTL
+)
+
+// Start starts tracing a metric name, resetting the counts if it was already traced. The trace
+// stops by itself after the given TTL, its report is kept for expiredRetention afterwards.
+func Start(name string, ttl time.Duration) {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	t := &trace{
+		start:   now(),
+		expires: now().Add(ttl),
+		counts:  make(map[Stage]*int64, len(Stages)),
+	}
+	for _, s := range Stages {
+		t.counts[s] = new(int64)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if previous, ok := traces[name]; ok {
+		previous.timer.Stop()
+	}
+	traces[name] = t
+	t.timer = time.AfterFunc(ttl, func() { expire(name, t) })
+	updateActive()
+}
+
+// expire stops counting the samples of an expired trace, its report is removed after expiredRetention
+func expire(name string, t *trace) {
+	mu.Lock()
+	defer mu.Unlock()
+	if traces[name] != t {
+		return
+	}
+	t.expired = true
+	t.timer = time.AfterFunc(expiredRetention, func() { remove(name, t) })
+	updateActive()
+}
+
+// remove removes the trace of a metric name, unless it was restarted since
+func remove(name string, t *trace) {
+	mu.Lock()
+	defer mu.Unlock()
+	if traces[name] == t {
+		delete(traces, name)
+	}
+}
+
+// updateActive counts the traces which are not expired, the caller must hold the lock
+func updateActive() {
+	var n int32
+	for _, t := range traces {
+		if !t.expired {
+			n++
+		}
+	}
+	atomic.StoreInt32(&active, n)
+}
+
+// Get returns the report of a metric name, and false if it isn't traced
+func Get(name string) (Report, bool) {
+	mu.RLock()
+	t, ok := traces[name]
+	expired := ok && (t.expired || now().After(t.expires))
+	mu.RUnlock()
+	if !ok {
+		return Report{}, false
+	}
+
+	r := Report{
+		Name:    name,
+		Start:   t.start,
+		End:     now(),
+		Counts:  make(map[Stage]int64, len(t.counts)),
+		Expired: expired,
+	}
+	if r.Expired {
+		r.End = t.expires
+	}
+	for s, c := range t.counts {
+		r.Counts[s] = atomic.LoadInt64(c)
+	}
+	return r, true
+}
+
+// Stop stops tracing a metric name
+func Stop(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if t, ok := traces[name]; ok {
+		t.timer.Stop()
+		delete(traces, name)
+	}
+	updateActive()
+}
+
+// Active returns whether any metric is traced, to skip the tracing on the hot paths
+func Active() bool {
+	return atomic.LoadInt32(&active) > 0
+}
+
+// Record counts n occurrences of the metric name at the given stage, if it's traced
+func Record(stage Stage, name string, n int64) {
+	if !Active() {
+		return
+	}
+	mu.RLock()
+	t, ok := traces[name]
+	expired := ok && (t.expired || now().After(t.expires))
+	mu.RUnlock()
+	if !ok || expired {
+		return
+	}
+	atomic.AddInt64(t.counts[stage], n)
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package metrictracer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer(t *testing.T) {
+	assert := assert.New(t)
+	assert.False(Active())
+	Record(StageDogStatsD, "my.metric", 1)
+	_, ok := Get("my.metric")
+	assert.False(ok)
+
+	Start("my.metric", time.Minute)
+	defer Stop("my.metric")
+	assert.True(Active())
+
+	Record(StageDogStatsD, "my.metric", 1)
+	Record(StageDogStatsD, "my.metric", 2)
+	Record(StageAggregator, "my.metric", 3)
+	Record(StageAggregator, "other.metric", 1)
+
+	report, ok := Get("my.metric")
+	assert.True(ok)
+	assert.Equal("my.metric", report.Name)
+	assert.False(report.Expired)
+	assert.EqualValues(3, report.Counts[StageDogStatsD])
+	assert.EqualValues(3, report.Counts[StageAggregator])
+	assert.EqualValues(0, report.Counts[StageFlushed])
+	assert.Len(report.Counts, len(Stages))
+
+	// restarting the trace resets the counts
+	Start("my.metric", time.Minute)
+	report, _ = Get("my.metric")
+	assert.EqualValues(0, report.Counts[StageDogStatsD])
+
+	Stop("my.metric")
+	assert.False(Active())
+	_, ok = Get("my.metric")
+	assert.False(ok)
+}
+
+func TestTracerExpiration(t *testing.T) {
+	assert := assert.New(t)
+	start := time.Now()
+	current := start
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	Start("my.metric", time.Minute)
+	defer Stop("my.metric")
+	Record(StageFlushed, "my.metric", 1)
+
+	current = start.Add(2 * time.Minute)
+	Record(StageFlushed, "my.metric", 1)
+
+	report, ok := Get("my.metric")
+	assert.True(ok)
+	assert.True(report.Expired)
+	assert.Equal(start.Add(time.Minute), report.End)
+	assert.EqualValues(1, report.Counts[StageFlushed])
+}
+
+func TestTracerExpiredRemoved(t *testing.T) {
+	assert := assert.New(t)
+	expiredRetention = 50 * time.Millisecond
+	defer func() { expiredRetention = DefaultTTL }()
+
+	Start("my.metric", 10*time.Millisecond)
+	defer Stop("my.metric")
+	assert.True(Active())
+
+	// the expired trace doesn't keep the tracing active
+	for i := 0; i < 100 && Active(); i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.False(Active())
+	report, ok := Get("my.metric")
+	assert.True(ok)
+	assert.True(report.Expired)
+
+	// it's removed after the retention
+	for i := 0; i < 100 && ok; i++ {
+		time.Sleep(5 * time.Millisecond)
+		_, ok = Get("my.metric")
+	}
+	assert.False(ok)
+}
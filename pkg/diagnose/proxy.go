@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnose
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	logsconfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
+
+	"github.com/fatih/color"
+)
+
+// proxyEndpoint is an intake endpoint of the agent
+type proxyEndpoint struct {
+	name   string
+	url    string
+	intake string
+}
+
+// RunProxy writes the proxy used by every intake endpoint of the agent, output it in writer
+func RunProxy(w io.Writer) error {
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	endpoints, err := getProxyEndpoints()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("=== %s ===", color.BlueString("Proxy used by each endpoint")))
+	for _, e := range endpoints {
+		fmt.Fprintf(w, "%s: %s\n  -> %s\n", e.name, e.url, describeProxy(e))
+	}
+	return nil
+}
+
+// getProxyEndpoints returns the metrics, logs and APM endpoints of the configuration
+func getProxyEndpoints() ([]proxyEndpoint, error) {
+	endpoints := []proxyEndpoint{
+		{name: "Metrics", url: config.GetMainInfraEndpoint()},
+	}
+
+	additionals, err := config.GetMultipleEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the additional endpoints: %v", err)
+	}
+	var additionalURLs []string
+	for url := range additionals {
+		if url != config.GetMainInfraEndpoint() {
+			additionalURLs = append(additionalURLs, url)
+		}
+	}
+	sort.Strings(additionalURLs)
+	for _, url := range additionalURLs {
+		endpoints = append(endpoints, proxyEndpoint{name: "Metrics (additional endpoint)", url: url})
+	}
+
+	logsEndpoints, err := logsconfig.BuildHTTPEndpoints()
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the logs endpoints: %v", err)
+	}
+	endpoints = append(endpoints, proxyEndpoint{
+		name:   "Logs",
+		url:    logsEndpointURL(logsEndpoints.Main),
+		intake: config.ProxyIntakeLogs,
+	})
+
+	endpoints = append(endpoints, proxyEndpoint{
+		name:   "APM",
+		url:    config.GetMainEndpoint("https://trace.agent.", "apm_config.apm_dd_url"),
+		intake: config.ProxyIntakeAPM,
+	})
+	return endpoints, nil
+}
+
+// logsEndpointURL returns the URL of a logs HTTP endpoint
+func logsEndpointURL(e logsconfig.Endpoint) string {
+	scheme := "http"
+	if e.UseSSL {
+		scheme = "https"
+	}
+	if e.Port != 0 {
+		return fmt.Sprintf("%s://%s:%d", scheme, e.Host, e.Port)
+	}
+	return fmt.Sprintf("%s://%s", scheme, e.Host)
+}
+
+// describeProxy returns the proxy the endpoint will use, and why
+func describeProxy(e proxyEndpoint) string {
+	proxies := config.GetProxiesForIntake(e.intake)
+	if proxies == nil {
+		return "no proxy: no proxy is configured"
+	}
+
+	req, err := http.NewRequest("GET", e.url, nil)
+	if err != nil {
+		return color.RedString(fmt.Sprintf("invalid endpoint URL: %v", err))
+	}
+	if entry, ok := httputils.NoProxyMatch(req.URL, proxies.NoProxy); ok {
+		return fmt.Sprintf("no proxy: matches the no_proxy entry %q", entry)
+	}
+
+	proxyURL, err := httputils.GetProxyTransportFunc(proxies)(req)
+	if err != nil {
+		return color.RedString(fmt.Sprintf("invalid proxy: %v", err))
+	}
+	if proxyURL == nil {
+		return fmt.Sprintf("no proxy: no proxy is configured for %s", req.URL.Scheme)
+	}
+
+	source := "proxy." + req.URL.Scheme
+	if proxies != config.GetProxies() {
+		source = "proxy." + e.intake
+	}
+	return fmt.Sprintf("%s (from %s)", color.GreenString(httputils.RedactProxyURL(proxyURL)), source)
+}
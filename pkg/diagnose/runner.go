@@ -6,6 +6,7 @@
 package diagnose
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"sort"
@@ -17,8 +18,29 @@ import (
 	"github.com/fatih/color"
 )
 
-// RunAll runs all registered connectivity checks, output it in writer
+// RunAll runs all registered connectivity checks, output it in writer.
+// The results of the metadata availability checks are summarized at the end.
 func RunAll(w io.Writer) error {
+	return runWithLogger(w, func() {
+		runCatalog(w, diagnosis.DefaultCatalog)
+		results := runCatalog(w, diagnosis.MetadataAvailabilityCatalog)
+		summarizeMetadataAvailability(w, results)
+	})
+}
+
+// RunMetadataAvailability runs the checks of the host metadata providers: the cloud provider
+// metadata endpoints, the kubelet and the container runtimes, and reports which providers
+// are reachable and which credentials are missing, output it in writer
+func RunMetadataAvailability(w io.Writer) error {
+	return runWithLogger(w, func() {
+		results := runCatalog(w, diagnosis.MetadataAvailabilityCatalog)
+		summarizeMetadataAvailability(w, results)
+	})
+}
+
+// runWithLogger runs the diagnosis with a custom logger writing to writer,
+// so that their logs are part of the output
+func runWithLogger(w io.Writer, run func()) error {
 	if w != color.Output {
 		color.NoColor = true
 	}
@@ -31,21 +53,69 @@ func RunAll(w io.Writer) error {
 	log.RegisterAdditionalLogger("diagnose", customLogger)
 	defer log.UnregisterAdditionalLogger("diagnose")
 
+	run()
+	return nil
+}
+
+// runCatalog runs the diagnosis of a catalog in name order and returns their results
+func runCatalog(w io.Writer, catalog diagnosis.Catalog) map[string]error {
 	var sortedDiagnosis []string
-	for name := range diagnosis.DefaultCatalog {
+	for name := range catalog {
 		sortedDiagnosis = append(sortedDiagnosis, name)
 	}
 	sort.Strings(sortedDiagnosis)
 
+	results := make(map[string]error, len(catalog))
 	for _, name := range sortedDiagnosis {
 		fmt.Fprintln(w, fmt.Sprintf("=== Running %s diagnosis ===", color.BlueString(name)))
-		err := diagnosis.DefaultCatalog[name]()
+		err := catalog[name]()
 		statusString := color.GreenString("PASS")
 		if err != nil {
 			statusString = color.RedString("FAIL")
 		}
 		fmt.Fprintln(w, fmt.Sprintf("===> %s\n", statusString))
+		results[name] = err
 	}
+	return results
+}
 
-	return nil
+// summarizeMetadataAvailability writes which metadata providers are reachable, and the
+// credentials missing to query the other ones
+func summarizeMetadataAvailability(w io.Writer, results map[string]error) {
+	if len(results) == 0 {
+		return
+	}
+
+	var reachable, unreachable, missingCredentials []string
+	for name, err := range results {
+		var credsErr *diagnosis.MissingCredentialsError
+		switch {
+		case err == nil:
+			reachable = append(reachable, name)
+		case errors.As(err, &credsErr):
+			missingCredentials = append(missingCredentials, fmt.Sprintf("%s: %s", name, credsErr.Credentials))
+		default:
+			unreachable = append(unreachable, name)
+		}
+	}
+	sort.Strings(reachable)
+	sort.Strings(unreachable)
+	sort.Strings(missingCredentials)
+
+	fmt.Fprintln(w, fmt.Sprintf("=== %s summary ===", color.BlueString("Metadata availability")))
+	writeList(w, "Reachable", reachable)
+	writeList(w, "Unreachable", unreachable)
+	writeList(w, "Missing credentials", missingCredentials)
+	fmt.Fprintln(w)
+}
+
+func writeList(w io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		fmt.Fprintf(w, "%s: none\n", title)
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(w, "  - %s\n", item)
+	}
 }
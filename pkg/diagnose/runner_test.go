@@ -27,3 +27,26 @@ func TestRunAll(t *testing.T) {
 	assert.Contains(t, result, "=== Running failing diagnosis ===\n===> FAIL")
 	assert.Contains(t, result, "=== Running succeeding diagnosis ===\n===> PASS")
 }
+
+func TestRunMetadataAvailability(t *testing.T) {
+	diagnosis.RegisterMetadataAvailability("reachable provider", func() error { return nil })
+	diagnosis.RegisterMetadataAvailability("unreachable provider", func() error { return errors.New("timeout") })
+	diagnosis.RegisterMetadataAvailability("protected provider", func() error {
+		return diagnosis.NewMissingCredentialsError("a token", errors.New("401"))
+	})
+	defer func() {
+		delete(diagnosis.MetadataAvailabilityCatalog, "reachable provider")
+		delete(diagnosis.MetadataAvailabilityCatalog, "unreachable provider")
+		delete(diagnosis.MetadataAvailabilityCatalog, "protected provider")
+	}()
+
+	w := &bytes.Buffer{}
+	RunMetadataAvailability(w)
+
+	result := w.String()
+	assert.Contains(t, result, "=== Running reachable provider diagnosis ===\n===> PASS")
+	assert.Contains(t, result, "=== Running protected provider diagnosis ===\n")
+	assert.Contains(t, result, "Reachable:\n  - reachable provider\n")
+	assert.Contains(t, result, "Unreachable:\n  - unreachable provider\n")
+	assert.Contains(t, result, "Missing credentials:\n  - protected provider: a token\n")
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"expvar"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// forwarderStopTimeout is the time given to the forwarder to send the packets still queued when stopping
+const forwarderStopTimeout = 2 * time.Second
+
+var (
+	dogstatsdForwardedPackets      = expvar.Int{}
+	dogstatsdForwardDroppedPackets = expvar.Int{}
+	dogstatsdForwardErrors         = expvar.Int{}
+
+	tlmForwardedPackets = telemetry.NewCounter("dogstatsd", "forwarded_packets",
+		[]string{"state"}, "Count of the packets relayed to the statsd forward host, by state (ok, dropped or error)")
+)
+
+func init() {
+	dogstatsdExpvars.Set("ForwardedPackets", &dogstatsdForwardedPackets)
+	dogstatsdExpvars.Set("ForwardDroppedPackets", &dogstatsdForwardDroppedPackets)
+	dogstatsdExpvars.Set("ForwardErrors", &dogstatsdForwardErrors)
+}
+
+// packetForwarder relays the raw packets received by the server to another statsd server. The
+// packets are copied to a bounded queue so that a slow or unreachable destination never delays
+// their processing, the packets received while the queue is full are dropped and counted.
+type packetForwarder struct {
+	conn     net.Conn
+	queue    chan []byte
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+func newPacketForwarder(conn net.Conn, bufferSize int) *packetForwarder {
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &packetForwarder{
+		conn:     conn,
+		queue:    make(chan []byte, bufferSize),
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (f *packetForwarder) start() {
+	go f.run()
+}
+
+// forward queues a copy of the packets, the packets being released to the pool once parsed
+func (f *packetForwarder) forward(packets listeners.Packets) {
+	for _, packet := range packets {
+		contents := make([]byte, len(packet.Contents))
+		copy(contents, packet.Contents)
+		select {
+		case f.queue <- contents:
+		default:
+			dogstatsdForwardDroppedPackets.Add(1)
+			tlmForwardedPackets.Inc("dropped")
+		}
+	}
+}
+
+func (f *packetForwarder) run() {
+	defer close(f.done)
+	for {
+		select {
+		case contents := <-f.queue:
+			f.send(contents)
+		case <-f.stopChan:
+			f.flush()
+			return
+		}
+	}
+}
+
+// flush sends the packets still queued, giving up after forwarderStopTimeout
+func (f *packetForwarder) flush() {
+	deadline := time.After(forwarderStopTimeout)
+	for {
+		select {
+		case contents := <-f.queue:
+			f.send(contents)
+		case <-deadline:
+			log.Warnf("Could not forward the %d packets still queued before the timeout", len(f.queue))
+			return
+		default:
+			return
+		}
+	}
+}
+
+func (f *packetForwarder) send(contents []byte) {
+	if _, err := f.conn.Write(contents); err != nil {
+		dogstatsdForwardErrors.Add(1)
+		tlmForwardedPackets.Inc("error")
+		log.Debugf("Forwarding packet failed: %s", err)
+		return
+	}
+	dogstatsdForwardedPackets.Add(1)
+	tlmForwardedPackets.Inc("ok")
+}
+
+// stop sends the packets still queued and closes the connection
+func (f *packetForwarder) stop() {
+	close(f.stopChan)
+	<-f.done
+	f.conn.Close()
+}
@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
+)
+
+func TestPacketForwarder(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+
+	forwarded := dogstatsdForwardedPackets.Value()
+	dropped := dogstatsdForwardDroppedPackets.Value()
+
+	// the packets received while the queue is full are dropped
+	f := newPacketForwarder(conn, 2)
+	packet := &listeners.Packet{Contents: []byte("daemon:666|g")}
+	f.forward(listeners.Packets{packet, {Contents: []byte("daemon:667|g")}, {Contents: []byte("daemon:668|g")}})
+	assert.Equal(t, dropped+1, dogstatsdForwardDroppedPackets.Value())
+
+	// the packets are copied, their contents being consumed by the parsing
+	packet.Contents = packet.Contents[:0]
+
+	f.start()
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buffer := make([]byte, 64)
+	for _, expected := range []string{"daemon:666|g", "daemon:667|g"} {
+		n, _, err := pc.ReadFrom(buffer)
+		require.NoError(t, err)
+		assert.Equal(t, expected, string(buffer[:n]))
+	}
+	f.stop()
+	assert.Equal(t, forwarded+2, dogstatsdForwardedPackets.Value())
+}
+
+func TestPacketForwarderFlushesOnStop(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+	conn, err := net.Dial("udp", pc.LocalAddr().String())
+	require.NoError(t, err)
+
+	f := newPacketForwarder(conn, 10)
+	f.forward(listeners.Packets{{Contents: []byte("daemon:666|g")}})
+	f.start()
+	f.stop()
+
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buffer := make([]byte, 64)
+	n, _, err := pc.ReadFrom(buffer)
+	require.NoError(t, err)
+	assert.Equal(t, "daemon:666|g", string(buffer[:n]))
+}
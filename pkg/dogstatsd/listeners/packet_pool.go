@@ -38,20 +38,20 @@ type PacketPool struct {
 
 // NewPacketPool creates a new pool with a specified buffer size
 func NewPacketPool(bufferSize int) *PacketPool {
-	return &PacketPool{
-		pool: sync.Pool{
-			New: func() interface{} {
-				packet := &Packet{
-					buffer: make([]byte, bufferSize),
-					Origin: NoOrigin,
-				}
-				packet.Contents = packet.buffer[0:0]
-				return packet
-			},
-		},
+	p := &PacketPool{
 		// telemetry
 		tlmEnabled: telemetry.IsEnabled(),
 	}
+	p.pool.New = func() interface{} {
+		packet := &Packet{
+			buffer: make([]byte, bufferSize),
+			Origin: NoOrigin,
+			pool:   p,
+		}
+		packet.Contents = packet.buffer[0:0]
+		return packet
+	}
+	return p
 }
 
 // Get gets a Packet object read for use.
@@ -63,8 +63,13 @@ func (p *PacketPool) Get() *Packet {
 	return p.pool.Get().(*Packet)
 }
 
-// Put resets the Packet origin and puts it back in the pool.
+// Put resets the Packet origin and puts it back in the pool it was taken from, which
+// is the pool of the socket which read it when the UDP sockets are sharded.
 func (p *PacketPool) Put(packet *Packet) {
+	if packet.pool != nil && packet.pool != p {
+		packet.pool.Put(packet)
+		return
+	}
 	if packet.Origin != NoOrigin {
 		packet.Origin = NoOrigin
 	}
@@ -12,9 +12,10 @@ package listeners
 // underlying buffer reference to avoid re-sizing the slice
 // before reading
 type Packet struct {
-	Contents []byte // Contents, might contain several messages
-	buffer   []byte // Underlying buffer for data read
-	Origin   string // Origin container if identified
+	Contents []byte      // Contents, might contain several messages
+	buffer   []byte      // Underlying buffer for data read
+	Origin   string      // Origin container if identified
+	pool     *PacketPool // Pool the packet was taken from
 }
 
 // Packets is a slice of packet pointers
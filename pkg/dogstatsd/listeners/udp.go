@@ -9,7 +9,9 @@ import (
 	"expvar"
 	"fmt"
 	"net"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -22,17 +24,55 @@ var (
 	udpPacketReadingErrors = expvar.Int{}
 	udpPackets             = expvar.Int{}
 	udpBytes               = expvar.Int{}
+	udpKernelDrops         = expvar.Int{}
+	udpReadBuffer          = expvar.Int{}
+	// udpSocketExpvars holds the stats of each socket, keyed by the ID of the socket
+	udpSocketExpvars = expvar.Map{}
 
 	tlmUDPPackets = telemetry.NewCounter("dogstatsd", "udp_packets",
 		[]string{"state"}, "Dogstatsd UDP packets count")
 	tlmUDPPacketsBytes = telemetry.NewCounter("dogstatsd", "udp_packets_bytes",
 		nil, "Dogstatsd UDP packets bytes count")
+	tlmUDPKernelDrops = telemetry.NewCounter("dogstatsd", "udp_kernel_drops",
+		nil, "Dogstatsd UDP packets dropped by the kernel because the socket receive buffer was full")
+	tlmUDPReadBuffer = telemetry.NewGauge("dogstatsd", "udp_read_buffer_bytes",
+		nil, "Size of the Dogstatsd UDP socket receive buffer")
+	tlmUDPSocketPackets = telemetry.NewCounter("dogstatsd", "udp_socket_packets",
+		[]string{"socket", "state"}, "Dogstatsd UDP packets count per socket")
+	tlmUDPSocketKernelDrops = telemetry.NewCounter("dogstatsd", "udp_socket_kernel_drops",
+		[]string{"socket"}, "Dogstatsd UDP packets dropped by the kernel per socket")
 )
 
+// udpDropsCheckInterval is the interval at which the kernel drops of the UDP socket are checked
+const udpDropsCheckInterval = 15 * time.Second
+
 func init() {
 	udpExpvars.Set("PacketReadingErrors", &udpPacketReadingErrors)
 	udpExpvars.Set("Packets", &udpPackets)
 	udpExpvars.Set("Bytes", &udpBytes)
+	udpExpvars.Set("KernelDrops", &udpKernelDrops)
+	udpExpvars.Set("ReadBuffer", &udpReadBuffer)
+	udpExpvars.Set("Sockets", &udpSocketExpvars)
+}
+
+// udpSocketStats are the stats of one of the UDP sockets, when they're sharded with SO_REUSEPORT
+type udpSocketStats struct {
+	id                  string
+	packets             expvar.Int
+	packetReadingErrors expvar.Int
+	bytes               expvar.Int
+	kernelDrops         expvar.Int
+}
+
+func newUDPSocketStats(id string) *udpSocketStats {
+	stats := &udpSocketStats{id: id}
+	m := new(expvar.Map).Init()
+	m.Set("Packets", &stats.packets)
+	m.Set("PacketReadingErrors", &stats.packetReadingErrors)
+	m.Set("Bytes", &stats.bytes)
+	m.Set("KernelDrops", &stats.kernelDrops)
+	udpSocketExpvars.Set(id, m)
+	return stats
 }
 
 // UDPListener implements the StatsdListener interface for UDP protocol.
@@ -44,49 +84,87 @@ type UDPListener struct {
 	packetsBuffer   *packetsBuffer
 	packetAssembler *packetAssembler
 	buffer          []byte
-}
 
-// NewUDPListener returns an idle UDP Statsd listener
-func NewUDPListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*UDPListener, error) {
-	var err error
-	var url string
+	// socketInode identifies the socket in the kernel drops counters, 0 when they can't be read
+	socketInode uint64
+	lastDrops   uint64
+	// readBufferMax is the ceiling of the receive buffer autotuning, 0 when it's disabled
+	readBufferMax int
+	stats         *udpSocketStats
+	stop          chan struct{}
+}
 
+// udpListenAddress returns the address the UDP sockets bind to
+func udpListenAddress() string {
 	if config.Datadog.GetBool("dogstatsd_non_local_traffic") == true {
 		// Listen to all network interfaces
-		url = fmt.Sprintf(":%d", config.Datadog.GetInt("dogstatsd_port"))
-	} else {
-		url = net.JoinHostPort(config.Datadog.GetString("bind_host"), config.Datadog.GetString("dogstatsd_port"))
+		return fmt.Sprintf(":%d", config.Datadog.GetInt("dogstatsd_port"))
 	}
+	return net.JoinHostPort(config.Datadog.GetString("bind_host"), config.Datadog.GetString("dogstatsd_port"))
+}
 
-	addr, err := net.ResolveUDPAddr("udp", url)
+// NewUDPListener returns an idle UDP Statsd listener
+func NewUDPListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*UDPListener, error) {
+	addr, err := net.ResolveUDPAddr("udp", udpListenAddress())
 	if err != nil {
 		return nil, fmt.Errorf("could not resolve udp addr: %s", err)
 	}
 	conn, err := net.ListenUDP("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("can't listen: %s", err)
+	}
+	return newUDPListener(conn, 0, packetOut, sharedPacketPool)
+}
 
+// NewReusePortUDPListener returns an idle UDP Statsd listener whose socket is bound with SO_REUSEPORT,
+// several listeners sharing the dogstatsd port, the kernel balancing the packets between their sockets.
+// Only supported on Linux.
+func NewReusePortUDPListener(socketID int, packetOut chan Packets, packetPool *PacketPool) (*UDPListener, error) {
+	conn, err := listenUDPReusePort(udpListenAddress())
+	if err != nil {
+		return nil, fmt.Errorf("can't listen with SO_REUSEPORT: %s", err)
+	}
+	return newUDPListener(conn, socketID, packetOut, packetPool)
+}
+
+func newUDPListener(conn *net.UDPConn, socketID int, packetOut chan Packets, packetPool *PacketPool) (*UDPListener, error) {
 	if rcvbuf := config.Datadog.GetInt("dogstatsd_so_rcvbuf"); rcvbuf != 0 {
 		if err := conn.SetReadBuffer(rcvbuf); err != nil {
+			conn.Close()
 			return nil, fmt.Errorf("could not set socket rcvbuf: %s", err)
 		}
 	}
 
-	if err != nil {
-		return nil, fmt.Errorf("can't listen: %s", err)
-	}
-
 	bufferSize := config.Datadog.GetInt("dogstatsd_buffer_size")
 	packetsBufferSize := config.Datadog.GetInt("dogstatsd_packet_buffer_size")
 	flushTimeout := config.Datadog.GetDuration("dogstatsd_packet_buffer_flush_timeout")
 
 	buffer := make([]byte, bufferSize)
 	packetsBuffer := newPacketsBuffer(uint(packetsBufferSize), flushTimeout, packetOut)
-	packetAssembler := newPacketAssembler(flushTimeout, packetsBuffer, sharedPacketPool)
+	packetAssembler := newPacketAssembler(flushTimeout, packetsBuffer, packetPool)
 
 	listener := &UDPListener{
 		conn:            conn,
 		packetsBuffer:   packetsBuffer,
 		packetAssembler: packetAssembler,
 		buffer:          buffer,
+		readBufferMax:   config.Datadog.GetInt("dogstatsd_so_rcvbuf_autotune_max"),
+		stats:           newUDPSocketStats(strconv.Itoa(socketID)),
+		stop:            make(chan struct{}),
+	}
+	if inode, err := getUDPSocketInode(conn); err == nil {
+		if drops, err := readUDPSocketDrops(inode); err == nil {
+			listener.socketInode = inode
+			listener.lastDrops = drops
+		} else {
+			log.Debugf("dogstatsd-udp: kernel drops can't be monitored: %s", err)
+		}
+	} else {
+		log.Debugf("dogstatsd-udp: kernel drops can't be monitored: %s", err)
+	}
+	if size, err := getUDPReadBuffer(conn); err == nil {
+		udpReadBuffer.Set(int64(size))
+		tlmUDPReadBuffer.Set(float64(size))
 	}
 	log.Debugf("dogstatsd-udp: %s successfully initialized", conn.LocalAddr())
 	return listener, nil
@@ -95,8 +173,12 @@ func NewUDPListener(packetOut chan Packets, sharedPacketPool *PacketPool) (*UDPL
 // Listen runs the intake loop. Should be called in its own goroutine
 func (l *UDPListener) Listen() {
 	log.Infof("dogstatsd-udp: starting to listen on %s", l.conn.LocalAddr())
+	if l.socketInode != 0 {
+		go l.monitorDrops()
+	}
 	for {
 		udpPackets.Add(1)
+		l.stats.packets.Add(1)
 		n, _, err := l.conn.ReadFrom(l.buffer)
 		if err != nil {
 			// connection has been closed
@@ -106,12 +188,16 @@ func (l *UDPListener) Listen() {
 
 			log.Errorf("dogstatsd-udp: error reading packet: %v", err)
 			udpPacketReadingErrors.Add(1)
+			l.stats.packetReadingErrors.Add(1)
 			tlmUDPPackets.Inc("error")
+			tlmUDPSocketPackets.Inc(l.stats.id, "error")
 			continue
 		}
 		tlmUDPPackets.Inc("ok")
+		tlmUDPSocketPackets.Inc(l.stats.id, "ok")
 
 		udpBytes.Add(int64(n))
+		l.stats.bytes.Add(int64(n))
 		tlmUDPPacketsBytes.Add(float64(n))
 
 		// packetAssembler merges multiple packets together and sends them when its buffer is full
@@ -119,8 +205,71 @@ func (l *UDPListener) Listen() {
 	}
 }
 
+// monitorDrops periodically checks the packets dropped by the kernel because the socket receive
+// buffer was full, which are otherwise silent, and grows the buffer when autotuning is enabled.
+func (l *UDPListener) monitorDrops() {
+	ticker := time.NewTicker(udpDropsCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+			drops, err := readUDPSocketDrops(l.socketInode)
+			if err != nil {
+				log.Debugf("dogstatsd-udp: could not read the kernel drops: %s", err)
+				continue
+			}
+			if drops <= l.lastDrops {
+				continue
+			}
+			newDrops := drops - l.lastDrops
+			l.lastDrops = drops
+			udpKernelDrops.Add(int64(newDrops))
+			l.stats.kernelDrops.Add(int64(newDrops))
+			tlmUDPKernelDrops.Add(float64(newDrops))
+			tlmUDPSocketKernelDrops.Add(float64(newDrops), l.stats.id)
+			log.Warnf("dogstatsd-udp: %d packets were dropped by the kernel because the socket receive buffer was full", newDrops)
+			l.growReadBuffer()
+		}
+	}
+}
+
+// growReadBuffer doubles the socket receive buffer, up to dogstatsd_so_rcvbuf_autotune_max.
+func (l *UDPListener) growReadBuffer() {
+	if l.readBufferMax <= 0 {
+		return
+	}
+	size, err := getUDPReadBuffer(l.conn)
+	if err != nil || size >= l.readBufferMax {
+		return
+	}
+	target := size * 2
+	if target > l.readBufferMax {
+		target = l.readBufferMax
+	}
+	if err := l.conn.SetReadBuffer(target); err != nil {
+		log.Warnf("dogstatsd-udp: could not grow the socket receive buffer to %d bytes: %s", target, err)
+		return
+	}
+	newSize, err := getUDPReadBuffer(l.conn)
+	if err != nil {
+		return
+	}
+	udpReadBuffer.Set(int64(newSize))
+	tlmUDPReadBuffer.Set(float64(newSize))
+	if newSize <= size {
+		// the kernel caps the buffers requested without privileges to net.core.rmem_max
+		log.Warnf("dogstatsd-udp: the socket receive buffer is capped to %d bytes, raise the net.core.rmem_max sysctl to grow it", size)
+		l.readBufferMax = 0
+		return
+	}
+	log.Infof("dogstatsd-udp: socket receive buffer grown from %d to %d bytes", size, newSize)
+}
+
 // Stop closes the UDP connection and stops listening
 func (l *UDPListener) Stop() {
+	close(l.stop)
 	l.packetAssembler.close()
 	l.packetsBuffer.close()
 	l.conn.Close()
@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listeners
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// procNetUDPFiles are the kernel tables of the UDP sockets, holding their drop counters
+var procNetUDPFiles = []string{"/proc/net/udp", "/proc/net/udp6"}
+
+// getUDPSocketInode returns the inode of the socket, identifying it in the /proc/net/udp tables.
+func getUDPSocketInode(conn *net.UDPConn) (uint64, error) {
+	rawconn, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var stat unix.Stat_t
+	var statErr error
+	err = rawconn.Control(func(fd uintptr) {
+		statErr = unix.Fstat(int(fd), &stat)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if statErr != nil {
+		return 0, statErr
+	}
+	return stat.Ino, nil
+}
+
+// getUDPReadBuffer returns the size of the receive buffer of the socket, as set by SetReadBuffer.
+func getUDPReadBuffer(conn *net.UDPConn) (int, error) {
+	rawconn, err := conn.SyscallConn()
+	if err != nil {
+		return 0, err
+	}
+	var size int
+	var sockErr error
+	err = rawconn.Control(func(fd uintptr) {
+		size, sockErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_RCVBUF)
+	})
+	if err != nil {
+		return 0, err
+	}
+	if sockErr != nil {
+		return 0, sockErr
+	}
+	// the kernel doubles the requested size to account for its bookkeeping overhead
+	return size / 2, nil
+}
+
+// readUDPSocketDrops returns the number of packets dropped by the kernel for the socket of the
+// given inode since its creation, because its receive buffer was full.
+func readUDPSocketDrops(inode uint64) (uint64, error) {
+	for _, path := range procNetUDPFiles {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		drops, found, err := parseProcNetUDPDrops(f, inode)
+		f.Close()
+		if err != nil {
+			return 0, fmt.Errorf("could not parse %s: %s", path, err)
+		}
+		if found {
+			return drops, nil
+		}
+	}
+	return 0, fmt.Errorf("socket inode %d not found in %s", inode, strings.Join(procNetUDPFiles, ", "))
+}
+
+// parseProcNetUDPDrops returns the drops counter of the socket of the given inode from a
+// /proc/net/udp table, whose lines are formatted as:
+//
+//	sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+//	 0: 00000000:1F7D 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 24581 2 0000000000000000 12
+func parseProcNetUDPDrops(r io.Reader, inode uint64) (uint64, bool, error) {
+	scanner := bufio.NewScanner(r)
+	// skip the header
+	scanner.Scan()
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 13 {
+			continue
+		}
+		lineInode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || lineInode != inode {
+			continue
+		}
+		drops, err := strconv.ParseUint(fields[12], 10, 64)
+		if err != nil {
+			return 0, false, err
+		}
+		return drops, true, nil
+	}
+	return 0, false, scanner.Err()
+}
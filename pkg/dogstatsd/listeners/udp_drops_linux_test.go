@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listeners
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const procNetUDPSample = `   sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops
+  365: 00000000:1F7D 00000000:0000 07 00000000:00000000 00:00000000 00000000     0        0 24581 2 0000000000000000 12
+ 1058: 3500007F:0035 00000000:0000 07 00000000:00000000 00:00000000 00000000   101        0 17316 2 0000000000000000 0
+`
+
+func TestParseProcNetUDPDrops(t *testing.T) {
+	drops, found, err := parseProcNetUDPDrops(strings.NewReader(procNetUDPSample), 24581)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, 12, drops)
+
+	drops, found, err = parseProcNetUDPDrops(strings.NewReader(procNetUDPSample), 17316)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.EqualValues(t, 0, drops)
+
+	_, found, err = parseProcNetUDPDrops(strings.NewReader(procNetUDPSample), 1)
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestReadUDPSocketDrops(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	inode, err := getUDPSocketInode(conn)
+	require.NoError(t, err)
+	drops, err := readUDPSocketDrops(inode)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, drops)
+
+	require.NoError(t, conn.SetReadBuffer(65536))
+	size, err := getUDPReadBuffer(conn)
+	assert.NoError(t, err)
+	assert.True(t, size >= 65536)
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !linux
+
+package listeners
+
+import (
+	"net"
+)
+
+// getUDPSocketInode returns a "not implemented" error on non-linux hosts
+func getUDPSocketInode(conn *net.UDPConn) (uint64, error) {
+	return 0, ErrLinuxOnly
+}
+
+// getUDPReadBuffer returns a "not implemented" error on non-linux hosts
+func getUDPReadBuffer(conn *net.UDPConn) (int, error) {
+	return 0, ErrLinuxOnly
+}
+
+// readUDPSocketDrops returns a "not implemented" error on non-linux hosts
+func readUDPSocketDrops(inode uint64) (uint64, error) {
+	return 0, ErrLinuxOnly
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listeners
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestReusePortUDPListeners(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	config.Datadog.SetDefault("dogstatsd_non_local_traffic", false)
+
+	// the sockets share the port, each reading the packets in its own pool
+	var listeners []*UDPListener
+	var channels []chan Packets
+	pools := []*PacketPool{NewPacketPool(512), NewPacketPool(512)}
+	for i, pool := range pools {
+		packetsIn := make(chan Packets, 100)
+		l, err := NewReusePortUDPListener(i, packetsIn, pool)
+		require.NoError(t, err)
+		go l.Listen()
+		defer l.Stop()
+		listeners = append(listeners, l)
+		channels = append(channels, packetsIn)
+	}
+
+	// the sockets not bound with SO_REUSEPORT can't share the port
+	address, _ := net.ResolveUDPAddr("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	_, err = net.ListenUDP("udp", address)
+	assert.Error(t, err)
+
+	// the kernel balances the packets by source address and port
+	for i := 0; i < 20; i++ {
+		conn, err := net.Dial("udp", address.String())
+		require.NoError(t, err)
+		_, err = conn.Write([]byte("daemon:666|g"))
+		require.NoError(t, err)
+		conn.Close()
+	}
+
+	// the packet assembler merges the datagrams in packets, one message per line
+	received := 0
+	timeout := time.After(2 * time.Second)
+	for received < 20 {
+		select {
+		case packets := <-channels[0]:
+			received += countMessages(t, packets, pools[0])
+		case packets := <-channels[1]:
+			received += countMessages(t, packets, pools[1])
+		case <-timeout:
+			require.FailNow(t, "timeout", "received %d messages out of 20", received)
+		}
+	}
+	assert.EqualValues(t, 20*len("daemon:666|g"), listeners[0].stats.bytes.Value()+listeners[1].stats.bytes.Value())
+}
+
+func countMessages(t *testing.T, packets Packets, pool *PacketPool) int {
+	count := 0
+	for _, packet := range packets {
+		assert.Equal(t, pool, packet.pool)
+		count += bytes.Count(packet.Contents, []byte("\n")) + 1
+	}
+	return count
+}
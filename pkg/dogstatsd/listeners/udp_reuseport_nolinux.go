@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !linux
+
+package listeners
+
+import (
+	"net"
+)
+
+// listenUDPReusePort returns a "not implemented" error on non-linux hosts
+func listenUDPReusePort(address string) (*net.UDPConn, error) {
+	return nil, ErrLinuxOnly
+}
@@ -2,7 +2,9 @@ package dogstatsd
 
 import (
 	"bytes"
+	"fmt"
 	"strconv"
+	"unicode/utf8"
 	"unsafe"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -29,14 +31,63 @@ var (
 // not safe for concurent use
 type parser struct {
 	interner *stringInterner
+
+	// strict rejects the malformed events and service checks instead of ignoring
+	// their invalid fields and truncating their oversized texts
+	strict                    bool
+	maxEventTitleLength       int
+	maxEventTextLength        int
+	maxServiceCheckMessageLen int
 }
 
 func newParser() *parser {
 	stringInternerCacheSize := config.Datadog.GetInt("dogstatsd_string_interner_size")
 
 	return &parser{
-		interner: newStringInterner(stringInternerCacheSize),
+		interner:                  newStringInterner(stringInternerCacheSize),
+		strict:                    config.Datadog.GetBool("dogstatsd_strict_parsing"),
+		maxEventTitleLength:       config.Datadog.GetInt("dogstatsd_event_max_title_length"),
+		maxEventTextLength:        config.Datadog.GetInt("dogstatsd_event_max_text_length"),
+		maxServiceCheckMessageLen: config.Datadog.GetInt("dogstatsd_service_check_max_message_length"),
+	}
+}
+
+// parseError is an error of the parsing of a message, its reason identifies the
+// error in the parse errors telemetry.
+type parseError struct {
+	reason string
+	msg    string
+}
+
+func (e *parseError) Error() string {
+	return e.msg
+}
+
+func newParseError(reason string, format string, args ...interface{}) error {
+	return &parseError{reason: reason, msg: fmt.Sprintf(format, args...)}
+}
+
+// parseErrorReason returns the reason of a parsing error, "unknown" if it has none.
+func parseErrorReason(err error) string {
+	if e, ok := err.(*parseError); ok {
+		return e.reason
+	}
+	return "unknown"
+}
+
+// limitLength returns the field truncated to maxLength bytes, on a rune boundary. It returns
+// an error instead in strict mode. A maxLength of 0 disables the limit.
+func (p *parser) limitLength(field []byte, maxLength int, name string) ([]byte, error) {
+	if maxLength <= 0 || len(field) <= maxLength {
+		return field, nil
+	}
+	if p.strict {
+		return nil, newParseError(name+"_too_long", "invalid %s: %d bytes, the maximum is %d", name, len(field), maxLength)
+	}
+	for maxLength > 0 && !utf8.RuneStart(field[maxLength]) {
+		maxLength--
 	}
+	return field[:maxLength], nil
 }
 
 func findMessageType(message []byte) messageType {
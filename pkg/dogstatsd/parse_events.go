@@ -62,29 +62,29 @@ var (
 func splitHeaderEvent(message []byte) ([]byte, []byte, error) {
 	sepIndex := bytes.Index(message, colonSeparator)
 	if sepIndex == -1 {
-		return nil, nil, fmt.Errorf("invalid event: %q", message)
+		return nil, nil, newParseError("format", "invalid event: %q", message)
 	}
 	return message[:sepIndex], message[sepIndex+1:], nil
 }
 
 func parseHeader(rawHeader []byte) (eventHeader, error) {
 	if len(rawHeader) < 7 {
-		return eventHeader{}, fmt.Errorf("invalid event header: %q", rawHeader)
+		return eventHeader{}, newParseError("header", "invalid event header: %q", rawHeader)
 	}
 	rawLengths := rawHeader[3 : len(rawHeader)-1]
 	sepIndex := bytes.Index(rawLengths, commaSeparator)
 	if sepIndex == -1 {
-		return eventHeader{}, fmt.Errorf("invalid event header: %q", rawHeader)
+		return eventHeader{}, newParseError("header", "invalid event header: %q", rawHeader)
 	}
 	rawTitleLength := rawLengths[:sepIndex]
 	rawTextLength := rawLengths[sepIndex+1:]
 	titleLength, err := parseInt64(rawTitleLength)
 	if err != nil {
-		return eventHeader{}, fmt.Errorf("invalid event header: %q", rawHeader)
+		return eventHeader{}, newParseError("header", "invalid event header: %q", rawHeader)
 	}
 	textLength, err := parseInt64(rawTextLength)
 	if err != nil {
-		return eventHeader{}, fmt.Errorf("invalid event header: %q", rawHeader)
+		return eventHeader{}, newParseError("header", "invalid event header: %q", rawHeader)
 	}
 	return eventHeader{
 		titleLength: int(titleLength),
@@ -141,7 +141,11 @@ func (p *parser) applyEventOptionalField(event dogstatsdEvent, optionalField []b
 	case bytes.HasPrefix(optionalField, eventAlertTypePrefix):
 		newEvent.alertType, err = parseEventAlertType(optionalField[len(eventAlertTypePrefix):])
 	case bytes.HasPrefix(optionalField, eventTagsPrefix):
-		newEvent.tags = p.parseTags(optionalField[len(eventTagsPrefix):])
+		newEvent.tags = append(newEvent.tags, p.parseTags(optionalField[len(eventTagsPrefix):])...)
+	default:
+		if p.strict {
+			err = fmt.Errorf("unknown event field: %q", optionalField)
+		}
 	}
 	if err != nil {
 		return event, err
@@ -159,13 +163,26 @@ func (p *parser) parseEvent(message []byte) (dogstatsdEvent, error) {
 		return dogstatsdEvent{}, err
 	}
 	if len(rawEvent) < header.textLength+header.titleLength+1 {
-		return dogstatsdEvent{}, fmt.Errorf("invalid event")
+		return dogstatsdEvent{}, newParseError("length_mismatch", "invalid event: the title and text are shorter than their lengths")
 	}
 	if header.titleLength == 0 || header.textLength == 0 {
-		return dogstatsdEvent{}, fmt.Errorf("invalid event: empty title or text")
+		return dogstatsdEvent{}, newParseError("empty_title_or_text", "invalid event: empty title or text")
+	}
+	textEnd := header.titleLength + 1 + header.textLength
+	// in strict mode, the title and the text must be followed by separators, otherwise their
+	// lengths don't match the payload
+	if p.strict && (rawEvent[header.titleLength] != fieldSeparator[0] ||
+		(len(rawEvent) > textEnd && rawEvent[textEnd] != fieldSeparator[0])) {
+		return dogstatsdEvent{}, newParseError("length_mismatch", "invalid event: the title and text lengths don't match the payload")
+	}
+	title, err := p.limitLength(cleanEventText(rawEvent[:header.titleLength]), p.maxEventTitleLength, "title")
+	if err != nil {
+		return dogstatsdEvent{}, err
+	}
+	text, err := p.limitLength(cleanEventText(rawEvent[header.titleLength+1:textEnd]), p.maxEventTextLength, "text")
+	if err != nil {
+		return dogstatsdEvent{}, err
 	}
-	title := cleanEventText(rawEvent[:header.titleLength])
-	text := cleanEventText(rawEvent[header.titleLength+1 : header.titleLength+1+header.textLength])
 
 	event := dogstatsdEvent{
 		title:     string(title),
@@ -174,16 +191,19 @@ func (p *parser) parseEvent(message []byte) (dogstatsdEvent, error) {
 		alertType: alertTypeInfo,
 	}
 
-	if len(rawEvent) == header.textLength+header.titleLength+1 {
+	if len(rawEvent) == textEnd {
 		return event, nil
 	}
 
-	optionalFields := rawEvent[header.titleLength+1+header.textLength+1:]
+	optionalFields := rawEvent[textEnd+1:]
 	var optionalField []byte
 	for optionalFields != nil {
 		optionalField, optionalFields = nextField(optionalFields)
 		event, err = p.applyEventOptionalField(event, optionalField)
 		if err != nil {
+			if p.strict {
+				return dogstatsdEvent{}, newParseError("invalid_field", "invalid event optional field: %v", err)
+			}
 			log.Warnf("invalid event optional field: %v", err)
 		}
 	}
@@ -230,3 +230,55 @@ func TestEventMetadataMultiple(t *testing.T) {
 	assert.Equal(t, string("aggKey"), e.aggregationKey)
 	assert.Equal(t, string("source test"), e.sourceType)
 }
+
+func TestEventMultipleTags(t *testing.T) {
+	e, err := parseEvent([]byte("_e{10,9}:test title|test text|#tag1,tag2:test|#tag3"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1", "tag2:test", "tag3"}, e.tags)
+}
+
+func TestEventTruncation(t *testing.T) {
+	parser := newParser()
+	parser.maxEventTitleLength = 4
+	parser.maxEventTextLength = 5
+
+	// the title and the text are truncated on a rune boundary
+	e, err := parser.parseEvent([]byte("_e{10,9}:test title|tést text"))
+	require.Nil(t, err)
+	assert.Equal(t, "test", e.title)
+	assert.Equal(t, "tést", e.text)
+}
+
+func TestEventStrict(t *testing.T) {
+	parser := newParser()
+	parser.strict = true
+
+	_, err := parser.parseEvent([]byte("_e{5,4}:title|text|#tag1|s:source"))
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		event  string
+		reason string
+	}{
+		{"_e|text", "format"},
+		{"_e:title|text", "header"},
+		{"_e{a,1}:title|text", "header"},
+		{"_e{10,10}:title|text", "length_mismatch"},
+		{"_e{0,0}:a|a", "empty_title_or_text"},
+		{"_e{4,5}:title|text", "length_mismatch"},
+		{"_e{5,2}:title|text", "length_mismatch"},
+		{"_e{5,4}:title|text|d:abc", "invalid_field"},
+		{"_e{5,4}:title|text|p:urgent", "invalid_field"},
+		{"_e{5,4}:title|text|t:test", "invalid_field"},
+		{"_e{5,4}:title|text|x:1234", "invalid_field"},
+	} {
+		_, err := parser.parseEvent([]byte(tt.event))
+		assert.Error(t, err, tt.event)
+		assert.Equal(t, tt.reason, parseErrorReason(err), tt.event)
+	}
+
+	parser.maxEventTitleLength = 4
+	_, err = parser.parseEvent([]byte("_e{5,4}:title|text"))
+	assert.Equal(t, "title_too_long", parseErrorReason(err))
+}
@@ -36,6 +36,12 @@ var (
 	serviceCheckHostnamePrefix  = []byte("h:")
 	serviceCheckMessagePrefix   = []byte("m:")
 	serviceCheckTagsPrefix      = []byte("#")
+
+	// the clients escape the new lines and the "m:" of the messages
+	serviceCheckMessageEscapes = [][2][]byte{
+		{[]byte("\\n"), []byte("\n")},
+		{[]byte("m\\:"), []byte("m:")},
+	}
 )
 
 // sanity checks a given message against the metric sample format
@@ -55,7 +61,7 @@ func hasServiceCheckFormat(message []byte) bool {
 
 func parseServiceCheckName(rawName []byte) ([]byte, error) {
 	if len(rawName) == 0 {
-		return nil, fmt.Errorf("invalid dogstatsd service check name: empty name")
+		return nil, newParseError("name", "invalid dogstatsd service check name: empty name")
 	}
 	return rawName, nil
 }
@@ -71,13 +77,21 @@ func parseServiceCheckStatus(rawStatus []byte) (serviceCheckStatus, error) {
 	case bytes.Equal(rawStatus, rawServiceCheckStatusUnknown):
 		return serviceCheckStatusUnknown, nil
 	}
-	return serviceCheckStatusUnknown, fmt.Errorf("invalid dogstatsd service check status: %q", rawStatus)
+	return serviceCheckStatusUnknown, newParseError("status", "invalid dogstatsd service check status: %q", rawStatus)
 }
 
 func parseServiceCheckTimestamp(rawTimestamp []byte) (int64, error) {
 	return strconv.ParseInt(string(rawTimestamp), 10, 64)
 }
 
+// cleanServiceCheckMessage unescapes the new lines and the "m:" of a service check message.
+func cleanServiceCheckMessage(message []byte) []byte {
+	for _, escape := range serviceCheckMessageEscapes {
+		message = bytes.Replace(message, escape[0], escape[1], -1)
+	}
+	return message
+}
+
 func (p *parser) applyServiceCheckOptionalField(serviceCheck dogstatsdServiceCheck, optionalField []byte) (dogstatsdServiceCheck, error) {
 	newServiceCheck := serviceCheck
 	var err error
@@ -87,9 +101,15 @@ func (p *parser) applyServiceCheckOptionalField(serviceCheck dogstatsdServiceChe
 	case bytes.HasPrefix(optionalField, serviceCheckHostnamePrefix):
 		newServiceCheck.hostname = string(optionalField[len(serviceCheckHostnamePrefix):])
 	case bytes.HasPrefix(optionalField, serviceCheckTagsPrefix):
-		newServiceCheck.tags = p.parseTags(optionalField[len(serviceCheckTagsPrefix):])
+		newServiceCheck.tags = append(newServiceCheck.tags, p.parseTags(optionalField[len(serviceCheckTagsPrefix):])...)
 	case bytes.HasPrefix(optionalField, serviceCheckMessagePrefix):
-		newServiceCheck.message = string(optionalField[len(serviceCheckMessagePrefix):])
+		var message []byte
+		message, err = p.limitLength(cleanServiceCheckMessage(optionalField[len(serviceCheckMessagePrefix):]), p.maxServiceCheckMessageLen, "message")
+		newServiceCheck.message = string(message)
+	default:
+		if p.strict {
+			err = fmt.Errorf("unknown service check field: %q", optionalField)
+		}
 	}
 	if err != nil {
 		return serviceCheck, err
@@ -99,7 +119,7 @@ func (p *parser) applyServiceCheckOptionalField(serviceCheck dogstatsdServiceChe
 
 func (p *parser) parseServiceCheck(message []byte) (dogstatsdServiceCheck, error) {
 	if !hasServiceCheckFormat(message) {
-		return dogstatsdServiceCheck{}, fmt.Errorf("invalid dogstatsd service check format")
+		return dogstatsdServiceCheck{}, newParseError("format", "invalid dogstatsd service check format")
 	}
 	// pop the _sc| header
 	message = message[4:]
@@ -123,9 +143,20 @@ func (p *parser) parseServiceCheck(message []byte) (dogstatsdServiceCheck, error
 
 	var optionalField []byte
 	for message != nil {
-		optionalField, message = nextField(message)
+		if bytes.HasPrefix(message, serviceCheckMessagePrefix) {
+			// the message is the last field, it may contain separators
+			optionalField, message = message, nil
+		} else {
+			optionalField, message = nextField(message)
+		}
 		serviceCheck, err = p.applyServiceCheckOptionalField(serviceCheck, optionalField)
 		if err != nil {
+			if p.strict {
+				if _, ok := err.(*parseError); !ok {
+					err = newParseError("invalid_field", "invalid service check optional field: %v", err)
+				}
+				return dogstatsdServiceCheck{}, err
+			}
 			log.Warnf("invalid service check optional field: %v", err)
 		}
 	}
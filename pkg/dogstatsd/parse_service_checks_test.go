@@ -119,3 +119,54 @@ func TestServiceCheckMetadataMultiple(t *testing.T) {
 	assert.Equal(t, "", sc.message)
 	assert.Equal(t, []string(nil), sc.tags)
 }
+
+func TestServiceCheckMetadataMultipleTags(t *testing.T) {
+	sc, err := parseServiceCheck([]byte("_sc|agent.up|0|#tag1,tag2:test|#tag3"))
+
+	require.Nil(t, err)
+	assert.Equal(t, []string{"tag1", "tag2:test", "tag3"}, sc.tags)
+}
+
+func TestServiceCheckMessageEscapes(t *testing.T) {
+	sc, err := parseServiceCheck([]byte("_sc|agent.up|0|#tag1|m:first line\\nsecond | line m\\:"))
+
+	require.Nil(t, err)
+	assert.Equal(t, "first line\nsecond | line m:", sc.message)
+	assert.Equal(t, []string{"tag1"}, sc.tags)
+}
+
+func TestServiceCheckMessageTruncation(t *testing.T) {
+	parser := newParser()
+	parser.maxServiceCheckMessageLen = 4
+
+	sc, err := parser.parseServiceCheck([]byte("_sc|agent.up|0|m:this is fine"))
+	require.Nil(t, err)
+	assert.Equal(t, "this", sc.message)
+
+	parser.strict = true
+	_, err = parser.parseServiceCheck([]byte("_sc|agent.up|0|m:this is fine"))
+	assert.Equal(t, "message_too_long", parseErrorReason(err))
+}
+
+func TestServiceCheckStrict(t *testing.T) {
+	parser := newParser()
+	parser.strict = true
+
+	_, err := parser.parseServiceCheck([]byte("_sc|agent.up|0|d:21|h:localhost|#tag1|m:this is fine"))
+	assert.NoError(t, err)
+
+	for _, tt := range []struct {
+		serviceCheck string
+		reason       string
+	}{
+		{"_sc|agent.up", "format"},
+		{"_sc||0", "name"},
+		{"_sc|agent.up|21", "status"},
+		{"_sc|agent.up|0|d:some_time", "invalid_field"},
+		{"_sc|agent.up|0|u:unknown", "invalid_field"},
+	} {
+		_, err := parser.parseServiceCheck([]byte(tt.serviceCheck))
+		assert.Error(t, err, tt.serviceCheck)
+		assert.Equal(t, tt.reason, parseErrorReason(err), tt.serviceCheck)
+	}
+}
@@ -22,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator"
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/diagnose/metrictracer"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/listeners"
 	"github.com/DataDog/datadog-agent/pkg/dogstatsd/mapper"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
@@ -47,8 +48,14 @@ var (
 		[]string{"message_type", "state"}, "Count of service checks/events/metrics processed by dogstatsd")
 	tlmProcessedErrorTags = map[string]string{"message_type": "metrics", "state": "error"}
 	tlmProcessedOkTags    = map[string]string{"message_type": "metrics", "state": "ok"}
+	tlmParseErrors        = telemetry.NewCounter("dogstatsd", "parse_errors",
+		[]string{"message_type", "reason", "origin"}, "Count of metrics/service checks/events parse errors by reason and origin")
 )
 
+// maxParseErrorsOrigins is the number of distinct origins tagging the parse errors telemetry,
+// the parse errors of the other origins are tagged with "other"
+const maxParseErrorsOrigins = 100
+
 func init() {
 	dogstatsdExpvars.Set("ServiceCheckParseErrors", &dogstatsdServiceCheckParseErrors)
 	dogstatsdExpvars.Set("ServiceCheckPackets", &dogstatsdServiceCheckPackets)
@@ -66,22 +73,35 @@ type Server struct {
 	// will send the metrics samples, events and service checks to.
 	aggregator *aggregator.BufferedAggregator
 
-	packetsIn                 chan listeners.Packets
+	packetsIn chan listeners.Packets
+	// udpSocketsPacketsIn are the channels of the UDP sockets sharded with SO_REUSEPORT,
+	// each of them being processed by its own worker
+	udpSocketsPacketsIn       []chan listeners.Packets
+	forwarder                 *packetForwarder
 	sharedPacketPool          *listeners.PacketPool
 	Statistics                *util.Stats
 	Started                   bool
 	stopChan                  chan bool
+	workersWg                 sync.WaitGroup
 	health                    *health.Handle
 	metricPrefix              string
 	metricPrefixBlacklist     []string
 	defaultHostname           string
 	histToDist                bool
 	histToDistPrefix          string
+	histAsDist                bool
+	histAsDistMigration       bool
+	histAsDistPrefix          string
 	extraTags                 []string
 	Debug                     *dsdServerDebug
 	mapper                    *mapper.MetricMapper
 	telemetryEnabled          bool
 	entityIDPrecedenceEnabled bool
+	originDetection           bool
+	// parseErrorsOrigins are the origins tagging the parse errors telemetry,
+	// bounded by maxParseErrorsOrigins
+	parseErrorsOrigins      map[string]struct{}
+	parseErrorsOriginsMutex sync.Mutex
 	// disableVerboseLogs is a feature flag to disable the logs capable
 	// of flooding the logger output (e.g. parsing messages error).
 	// NOTE(remy): this should probably be dropped and use a throttler logger, see
@@ -154,12 +174,22 @@ func NewServer(aggregator *aggregator.BufferedAggregator) (*Server, error) {
 			tmpListeners = append(tmpListeners, unixListener)
 		}
 	}
+	var udpSocketsPacketsIn []chan listeners.Packets
 	if config.Datadog.GetInt("dogstatsd_port") > 0 {
-		udpListener, err := listeners.NewUDPListener(packetsChannel, sharedPacketPool)
-		if err != nil {
-			log.Errorf(err.Error())
-		} else {
-			tmpListeners = append(tmpListeners, udpListener)
+		if sockets := config.Datadog.GetInt("dogstatsd_udp_sockets"); sockets > 1 {
+			udpListeners, channels := newReusePortUDPListeners(sockets)
+			for _, l := range udpListeners {
+				tmpListeners = append(tmpListeners, l)
+			}
+			udpSocketsPacketsIn = channels
+		}
+		if len(udpSocketsPacketsIn) == 0 {
+			udpListener, err := listeners.NewUDPListener(packetsChannel, sharedPacketPool)
+			if err != nil {
+				log.Errorf(err.Error())
+			} else {
+				tmpListeners = append(tmpListeners, udpListener)
+			}
 		}
 	}
 
@@ -182,6 +212,12 @@ func NewServer(aggregator *aggregator.BufferedAggregator) (*Server, error) {
 	histToDist := config.Datadog.GetBool("histogram_copy_to_distribution")
 	histToDistPrefix := config.Datadog.GetString("histogram_copy_to_distribution_prefix")
 
+	histAsDist := config.Datadog.GetBool("histogram_as_distribution")
+	if histAsDist && histToDist {
+		log.Warn("Dogstatsd: histogram_as_distribution is enabled, histogram_copy_to_distribution is ignored")
+		histToDist = false
+	}
+
 	extraTags := config.Datadog.GetStringSlice("dogstatsd_tags")
 
 	entityIDPrecedenceEnabled := config.Datadog.GetBool("dogstatsd_entity_id_precedence")
@@ -190,6 +226,7 @@ func NewServer(aggregator *aggregator.BufferedAggregator) (*Server, error) {
 		Started:                   true,
 		Statistics:                stats,
 		packetsIn:                 packetsChannel,
+		udpSocketsPacketsIn:       udpSocketsPacketsIn,
 		sharedPacketPool:          sharedPacketPool,
 		aggregator:                aggregator,
 		listeners:                 tmpListeners,
@@ -200,9 +237,14 @@ func NewServer(aggregator *aggregator.BufferedAggregator) (*Server, error) {
 		defaultHostname:           defaultHostname,
 		histToDist:                histToDist,
 		histToDistPrefix:          histToDistPrefix,
+		histAsDist:                histAsDist,
+		histAsDistMigration:       config.Datadog.GetBool("histogram_as_distribution_migration"),
+		histAsDistPrefix:          config.Datadog.GetString("histogram_as_distribution_migration_prefix"),
 		extraTags:                 extraTags,
 		telemetryEnabled:          telemetry.IsEnabled(),
 		entityIDPrecedenceEnabled: entityIDPrecedenceEnabled,
+		originDetection:           config.Datadog.GetBool("dogstatsd_origin_detection"),
+		parseErrorsOrigins:        make(map[string]struct{}),
 		disableVerboseLogs:        config.Datadog.GetBool("dogstatsd_disable_verbose_logs"),
 		Debug: &dsdServerDebug{
 			Stats: make(map[ckey.ContextKey]metricStat),
@@ -226,8 +268,8 @@ func NewServer(aggregator *aggregator.BufferedAggregator) (*Server, error) {
 		if err != nil {
 			log.Warnf("Could not connect to statsd forward host : %s", err)
 		} else {
-			s.packetsIn = make(chan listeners.Packets, config.Datadog.GetInt("dogstatsd_queue_size"))
-			go s.forwarder(con, packetsChannel)
+			s.forwarder = newPacketForwarder(con, config.Datadog.GetInt("statsd_forward_buffer_size"))
+			s.forwarder.start()
 		}
 	}
 
@@ -280,46 +322,72 @@ func (s *Server) handleMessages() {
 	}
 
 	for i := 0; i < workers; i++ {
-		go s.worker()
+		s.workersWg.Add(1)
+		go s.worker(s.packetsIn)
+	}
+	for _, packetsIn := range s.udpSocketsPacketsIn {
+		s.workersWg.Add(1)
+		go s.worker(packetsIn)
 	}
 }
 
-func (s *Server) forwarder(fcon net.Conn, packetsChannel chan listeners.Packets) {
-	for {
-		select {
-		case <-s.stopChan:
-			return
-		case packets := <-packetsChannel:
-			for _, packet := range packets {
-				_, err := fcon.Write(packet.Contents)
-
-				if err != nil {
-					log.Warnf("Forwarding packet failed : %s", err)
-				}
+// newReusePortUDPListeners binds the UDP sockets sharing the dogstatsd port with SO_REUSEPORT,
+// each of them reading the packets in its own pool and sending them to its own channel. It returns
+// no listeners if the first socket can't be bound, e.g. on the platforms not supporting SO_REUSEPORT.
+func newReusePortUDPListeners(sockets int) ([]*listeners.UDPListener, []chan listeners.Packets) {
+	var udpListeners []*listeners.UDPListener
+	var channels []chan listeners.Packets
+	for i := 0; i < sockets; i++ {
+		packetsIn := make(chan listeners.Packets, config.Datadog.GetInt("dogstatsd_queue_size"))
+		packetPool := listeners.NewPacketPool(config.Datadog.GetInt("dogstatsd_buffer_size"))
+		l, err := listeners.NewReusePortUDPListener(i, packetsIn, packetPool)
+		if err != nil {
+			if i == 0 {
+				log.Warnf("Dogstatsd: could not shard the UDP sockets, using a single socket: %s", err)
+			} else {
+				log.Errorf("Dogstatsd: could only bind %d of the %d UDP sockets: %s", i, sockets, err)
 			}
-			s.packetsIn <- packets
+			break
 		}
+		udpListeners = append(udpListeners, l)
+		channels = append(channels, packetsIn)
 	}
+	return udpListeners, channels
 }
 
-func (s *Server) worker() {
+func (s *Server) worker(packetsIn chan listeners.Packets) {
 	// the batcher will be responsible of batching a few samples / events / service
 	// checks and it will automatically forward them to the aggregator, meaning that
 	// the flushing logic to the aggregator is actually in the batcher.
 	batcher := newBatcher(s.aggregator)
+	defer s.workersWg.Done()
 
 	parser := newParser()
 	for {
 		select {
 		case <-s.stopChan:
+			s.drainPackets(batcher, parser, packetsIn)
 			return
 		case <-s.health.C:
-		case packets := <-s.packetsIn:
+		case packets := <-packetsIn:
 			s.parsePackets(batcher, parser, packets)
 		}
 	}
 }
 
+// drainPackets parses the packets already received, without waiting for more,
+// so that they reach the aggregator before it's stopped
+func (s *Server) drainPackets(batcher *batcher, parser *parser, packetsIn chan listeners.Packets) {
+	for {
+		select {
+		case packets := <-packetsIn:
+			s.parsePackets(batcher, parser, packets)
+		default:
+			return
+		}
+	}
+}
+
 func nextMessage(packet *[]byte) (message []byte) {
 	if len(*packet) == 0 {
 		return nil
@@ -335,6 +403,10 @@ func nextMessage(packet *[]byte) (message []byte) {
 }
 
 func (s *Server) parsePackets(batcher *batcher, parser *parser, packets []*listeners.Packet) {
+	// the packets are relayed before being parsed, the parsing consuming their contents
+	if s.forwarder != nil {
+		s.forwarder.forward(packets)
+	}
 	for _, packet := range packets {
 		originTagger := originTags{origin: packet.Origin}
 		log.Tracef("Dogstatsd receive: %q", packet.Contents)
@@ -355,41 +427,30 @@ func (s *Server) parsePackets(batcher *batcher, parser *parser, packets []*liste
 			case serviceCheckType:
 				serviceCheck, err := s.parseServiceCheckMessage(parser, message, originTagger.getTags)
 				if err != nil {
-					originTags := originTagger.getTags()
-					if len(originTags) > 0 {
-						s.errLog("Dogstatsd: error parsing service check '%q' origin tags %v: %s", message, originTags, err)
-					} else {
-						s.errLog("Dogstatsd: error parsing service check '%q': %s", message, err)
-					}
+					s.reportParseError("service_checks", "service check", message, &originTagger, err)
 					continue
 				}
 				batcher.appendServiceCheck(serviceCheck)
 			case eventType:
 				event, err := s.parseEventMessage(parser, message, originTagger.getTags)
 				if err != nil {
-					originTags := originTagger.getTags()
-					if len(originTags) > 0 {
-						s.errLog("Dogstatsd: error parsing event '%q' origin tags %v: %s", message, originTags, err)
-					} else {
-						s.errLog("Dogstatsd: error parsing event '%q': %s", message, err)
-					}
+					s.reportParseError("events", "event", message, &originTagger, err)
 					continue
 				}
 				batcher.appendEvent(event)
 			case metricSampleType:
 				sample, err := s.parseMetricMessage(parser, message, originTagger.getTags)
 				if err != nil {
-					originTags := originTagger.getTags()
-					if len(originTags) > 0 {
-						s.errLog("Dogstatsd: error parsing metric message '%q' origin tags %v: %s", message, originTags, err)
-					} else {
-						s.errLog("Dogstatsd: error parsing metric message '%q': %s", message, err)
-					}
+					s.reportParseError("metrics", "metric message", message, &originTagger, err)
 					continue
 				}
 				if atomic.LoadUint64(&s.Debug.Enabled) == 1 {
 					s.storeMetricStats(sample)
 				}
+				if s.histAsDist && sample.Mtype == metrics.HistogramType {
+					s.appendHistogramAsDistribution(batcher, sample)
+					continue
+				}
 				batcher.appendSample(sample)
 				if s.histToDist && sample.Mtype == metrics.HistogramType {
 					distSample := sample.Copy()
@@ -404,6 +465,58 @@ func (s *Server) parsePackets(batcher *batcher, parser *parser, packets []*liste
 	batcher.flush()
 }
 
+// appendHistogramAsDistribution sends a histogram sample as a distribution, aggregated in a sketch
+// for globally accurate percentiles. During the migration, the histogram is still sent under its
+// name and the distribution is sent under the name prefixed with histogram_as_distribution_migration_prefix,
+// so that the dashboards and monitors can be moved before the histogram aggregates stop.
+func (s *Server) appendHistogramAsDistribution(b *batcher, sample metrics.MetricSample) {
+	if s.histAsDistMigration {
+		b.appendSample(sample)
+		distSample := sample.Copy()
+		distSample.Name = s.histAsDistPrefix + distSample.Name
+		distSample.Mtype = metrics.DistributionType
+		b.appendSample(*distSample)
+		return
+	}
+	sample.Mtype = metrics.DistributionType
+	b.appendSample(sample)
+}
+
+// reportParseError counts the parsing error by origin and logs it with the origin of the
+// message, so that the owners of the clients sending malformed messages can be identified
+func (s *Server) reportParseError(messageType, messageKind string, message []byte, originTagger *originTags, err error) {
+	from := ""
+	if originTagger.origin != listeners.NoOrigin {
+		from = " from " + originTagger.origin
+	}
+	tlmParseErrors.Inc(messageType, parseErrorReason(err), s.parseErrorsOrigin(originTagger.origin))
+
+	if originTags := originTagger.getTags(); len(originTags) > 0 {
+		s.errLog("Dogstatsd: error parsing %s '%q'%s with origin tags %v: %s", messageKind, message, from, originTags, err)
+	} else {
+		s.errLog("Dogstatsd: error parsing %s '%q'%s: %s", messageKind, message, from, err)
+	}
+}
+
+// parseErrorsOrigin returns the value of the origin tag of the parse errors telemetry. Origins
+// are only reported with origin detection enabled, and only the first maxParseErrorsOrigins
+// distinct ones: the origins are container IDs, the cardinality would grow with the containers.
+func (s *Server) parseErrorsOrigin(origin string) string {
+	if !s.originDetection || origin == listeners.NoOrigin {
+		return "unknown"
+	}
+	s.parseErrorsOriginsMutex.Lock()
+	defer s.parseErrorsOriginsMutex.Unlock()
+	if _, found := s.parseErrorsOrigins[origin]; found {
+		return origin
+	}
+	if len(s.parseErrorsOrigins) >= maxParseErrorsOrigins {
+		return "other"
+	}
+	s.parseErrorsOrigins[origin] = struct{}{}
+	return origin
+}
+
 func (s *Server) errLog(format string, params ...interface{}) {
 	if s.disableVerboseLogs {
 		log.Debugf(format, params...)
@@ -417,6 +530,12 @@ func (s *Server) parseMetricMessage(parser *parser, message []byte, originTagsFu
 	if err != nil {
 		dogstatsdMetricParseErrors.Add(1)
 		tlmProcessed.IncWithTags(tlmProcessedErrorTags)
+		if metrictracer.Active() {
+			// the name is the raw one here, before the namespace and the mapping
+			if sepIndex := bytes.Index(message, colonSeparator); sepIndex > 0 {
+				metrictracer.Record(metrictracer.StageDogStatsDError, string(message[:sepIndex]), 1)
+			}
+		}
 		return metrics.MetricSample{}, err
 	}
 	if s.mapper != nil {
@@ -429,6 +548,7 @@ func (s *Server) parseMetricMessage(parser *parser, message []byte, originTagsFu
 	}
 	metricSample := enrichMetricSample(sample, s.metricPrefix, s.metricPrefixBlacklist, s.defaultHostname, originTagsFunc, s.entityIDPrecedenceEnabled)
 	metricSample.Tags = append(metricSample.Tags, s.extraTags...)
+	metrictracer.Record(metrictracer.StageDogStatsD, metricSample.Name, 1)
 	dogstatsdMetricPackets.Add(1)
 	tlmProcessed.IncWithTags(tlmProcessedOkTags)
 	return metricSample, nil
@@ -464,10 +584,15 @@ func (s *Server) parseServiceCheckMessage(parser *parser, message []byte, origin
 
 // Stop stops a running Dogstatsd server
 func (s *Server) Stop() {
-	close(s.stopChan)
+	// stop receiving packets before draining the ones already received
 	for _, l := range s.listeners {
 		l.Stop()
 	}
+	close(s.stopChan)
+	s.workersWg.Wait()
+	if s.forwarder != nil {
+		s.forwarder.stop()
+	}
 	if s.Statistics != nil {
 		s.Statistics.Stop()
 	}
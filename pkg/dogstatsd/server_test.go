@@ -12,6 +12,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/aggregator/ckey"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 )
 
 // getAvailableUDPPort requests a random port number and makes sure it is available
@@ -261,6 +263,66 @@ func TestUDPReceive(t *testing.T) {
 	}
 }
 
+// tagsCounter records the tags values of the increments of a telemetry counter
+type tagsCounter struct {
+	telemetry.Counter
+	sync.Mutex
+	incs [][]string
+}
+
+func (c *tagsCounter) Inc(tagsValue ...string) {
+	c.Lock()
+	defer c.Unlock()
+	c.incs = append(c.incs, tagsValue)
+}
+
+func (c *tagsCounter) get() [][]string {
+	c.Lock()
+	defer c.Unlock()
+	return append([][]string(nil), c.incs...)
+}
+
+func TestUDPParseErrors(t *testing.T) {
+	counter := &tagsCounter{}
+	tlmParseErrors, counter.Counter = counter, tlmParseErrors
+	defer func() { tlmParseErrors = counter.Counter }()
+
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	config.Datadog.SetDefault("dogstatsd_port", port)
+
+	s, err := NewServer(mockAggregator())
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	conn, err := net.Dial("udp", fmt.Sprintf("127.0.0.1:%d", port))
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	conn.Write([]byte("_sc|agent.up|not_a_status"))
+	for i := 0; i < 100 && len(counter.get()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the UDP packets have no origin
+	assert.Equal(t, [][]string{{"service_checks", "status", "unknown"}}, counter.get())
+}
+
+func TestParseErrorsOrigin(t *testing.T) {
+	s := &Server{parseErrorsOrigins: make(map[string]struct{})}
+
+	// origins are only reported with origin detection
+	assert.Equal(t, "unknown", s.parseErrorsOrigin("container_id://abc"))
+
+	s.originDetection = true
+	assert.Equal(t, "unknown", s.parseErrorsOrigin(""))
+	for i := 0; i < maxParseErrorsOrigins; i++ {
+		origin := fmt.Sprintf("container_id://%d", i)
+		assert.Equal(t, origin, s.parseErrorsOrigin(origin))
+	}
+	assert.Equal(t, "other", s.parseErrorsOrigin("container_id://abc"))
+	assert.Equal(t, "container_id://0", s.parseErrorsOrigin("container_id://0"))
+}
+
 func TestUDPForward(t *testing.T) {
 	fport, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -347,6 +409,80 @@ func TestHistToDist(t *testing.T) {
 	}
 }
 
+func TestHistAsDist(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	defaultPort := config.Datadog.GetInt("dogstatsd_port")
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	defer config.Datadog.SetDefault("dogstatsd_port", defaultPort)
+	config.Datadog.SetDefault("histogram_as_distribution", true)
+	defer config.Datadog.SetDefault("histogram_as_distribution", false)
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	s, err := NewServer(agg)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	// Test metric
+	conn.Write([]byte("daemon:666|h|#sometag1:somevalue1\ndaemon.gauge:1|g"))
+	select {
+	case samples := <-metricOut:
+		require.Equal(t, 2, len(samples))
+		distMetric := samples[0]
+		assert.Equal(t, "daemon", distMetric.Name)
+		assert.EqualValues(t, 666.0, distMetric.Value)
+		assert.Equal(t, metrics.DistributionType, distMetric.Mtype)
+		assert.Equal(t, metrics.GaugeType, samples[1].Mtype)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
+func TestHistAsDistMigration(t *testing.T) {
+	port, err := getAvailableUDPPort()
+	require.NoError(t, err)
+	defaultPort := config.Datadog.GetInt("dogstatsd_port")
+	config.Datadog.SetDefault("dogstatsd_port", port)
+	defer config.Datadog.SetDefault("dogstatsd_port", defaultPort)
+	config.Datadog.SetDefault("histogram_as_distribution", true)
+	defer config.Datadog.SetDefault("histogram_as_distribution", false)
+	config.Datadog.SetDefault("histogram_as_distribution_migration", true)
+	defer config.Datadog.SetDefault("histogram_as_distribution_migration", false)
+
+	agg := mockAggregator()
+	metricOut, _, _ := agg.GetBufferedChannels()
+	s, err := NewServer(agg)
+	require.NoError(t, err, "cannot start DSD")
+	defer s.Stop()
+
+	url := fmt.Sprintf("127.0.0.1:%d", config.Datadog.GetInt("dogstatsd_port"))
+	conn, err := net.Dial("udp", url)
+	require.NoError(t, err, "cannot connect to DSD socket")
+	defer conn.Close()
+
+	// Test metric
+	conn.Write([]byte("daemon:666|ms|#sometag1:somevalue1"))
+	select {
+	case samples := <-metricOut:
+		require.Equal(t, 2, len(samples))
+		histMetric := samples[0]
+		distMetric := samples[1]
+		assert.Equal(t, "daemon", histMetric.Name)
+		assert.Equal(t, metrics.HistogramType, histMetric.Mtype)
+		assert.Equal(t, "dist.daemon", distMetric.Name)
+		assert.EqualValues(t, 666.0, distMetric.Value)
+		assert.Equal(t, metrics.DistributionType, distMetric.Mtype)
+	case <-time.After(2 * time.Second):
+		assert.FailNow(t, "Timeout on receive channel")
+	}
+}
+
 func TestExtraTags(t *testing.T) {
 	port, err := getAvailableUDPPort()
 	require.NoError(t, err)
@@ -26,6 +26,14 @@ type Config struct {
 	// It is relevant *only* when DNSInspection is enabled.
 	CollectDNSStats bool
 
+	// EnableProtocolClassification specifies whether the tracer should classify the application protocol
+	// of the TCP connections (HTTP, HTTP/2, Kafka, Postgres) and aggregate the requests stats per service
+	EnableProtocolClassification bool
+
+	// MaxServiceStatsBuffered represents the maximum number of services whose stats we'll buffer in memory.
+	// These stats get flushed on every client request
+	MaxServiceStatsBuffered int
+
 	// DNSTimeout determines the length of time to wait before considering a DNS Query to have timed out
 	DNSTimeout time.Duration
 
@@ -121,5 +129,8 @@ func NewDefaultConfig() *Config {
 		CollectDNSStats:      false,
 		DNSTimeout:           15 * time.Second,
 		OffsetGuessThreshold: 400,
+		// Protocol classification related configurations
+		EnableProtocolClassification: false,
+		MaxServiceStatsBuffered:      10000,
 	}
 }
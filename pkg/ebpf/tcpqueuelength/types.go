@@ -11,6 +11,15 @@ type QueueLength struct {
 	Max  uint32 `json:"max"`
 }
 
+// FillRatio returns the peak fullness of the queue relative to its buffer size,
+// a ratio close to 1 means the queue is about to reach its limit
+func (q QueueLength) FillRatio() float64 {
+	if q.Size <= 0 {
+		return 0
+	}
+	return float64(q.Max) / float64(q.Size)
+}
+
 // Conn contains a TCP connection quadruplet
 type Conn struct {
 	Saddr net.IP `json:"saddr"`
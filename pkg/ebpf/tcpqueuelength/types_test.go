@@ -0,0 +1,14 @@
+package tcpqueuelength
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillRatio(t *testing.T) {
+	assert.Equal(t, 0.5, QueueLength{Size: 1000, Min: 0, Max: 500}.FillRatio())
+	assert.Equal(t, 1.0, QueueLength{Size: 1000, Min: 0, Max: 1000}.FillRatio())
+	// the socket buffer size could not be read
+	assert.Equal(t, 0.0, QueueLength{Size: 0, Max: 500}.FillRatio())
+}
@@ -22,7 +22,7 @@ import (
 
 var (
 	expvarEndpoints map[string]*expvar.Map
-	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns"}
+	expvarTypes     = []string{"conntrack", "state", "tracer", "ebpf", "kprobes", "dns", "protocols"}
 )
 
 func init() {
@@ -45,6 +45,9 @@ type Tracer struct {
 
 	reverseDNS network.ReverseDNS
 
+	// protocolMonitor is nil when the protocol classification is disabled
+	protocolMonitor *network.ProtocolMonitor
+
 	perfMap      *bpflib.PerfMap
 	batchManager *PerfBatchManager
 
@@ -197,6 +200,18 @@ func NewTracer(config *Config) (*Tracer, error) {
 		}
 	}
 
+	var protocolMonitor *network.ProtocolMonitor
+	if config.EnableProtocolClassification {
+		if protocolMonitor, err = network.NewProtocolMonitor(
+			config.ProcRoot,
+			int(config.MaxTrackedConnections),
+			config.MaxServiceStatsBuffered,
+			config.TCPConnTimeout,
+		); err != nil {
+			return nil, fmt.Errorf("error enabling protocol classification: %s", err)
+		}
+	}
+
 	portMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	udpPortMapping := network.NewPortMapping(config.ProcRoot, config.CollectTCPConns, config.CollectIPv6Conns)
 	if err := portMapping.ReadInitialState(); err != nil {
@@ -224,17 +239,18 @@ func NewTracer(config *Config) (*Tracer, error) {
 	)
 
 	tr := &Tracer{
-		m:              m,
-		config:         config,
-		state:          state,
-		portMapping:    portMapping,
-		udpPortMapping: udpPortMapping,
-		reverseDNS:     reverseDNS,
-		buffer:         make([]network.ConnectionStats, 0, 512),
-		buf:            &bytes.Buffer{},
-		conntracker:    conntracker,
-		sourceExcludes: network.ParseConnectionFilters(config.ExcludedSourceConnections),
-		destExcludes:   network.ParseConnectionFilters(config.ExcludedDestinationConnections),
+		m:               m,
+		config:          config,
+		state:           state,
+		portMapping:     portMapping,
+		udpPortMapping:  udpPortMapping,
+		reverseDNS:      reverseDNS,
+		protocolMonitor: protocolMonitor,
+		buffer:          make([]network.ConnectionStats, 0, 512),
+		buf:             &bytes.Buffer{},
+		conntracker:     conntracker,
+		sourceExcludes:  network.ParseConnectionFilters(config.ExcludedSourceConnections),
+		destExcludes:    network.ParseConnectionFilters(config.ExcludedDestinationConnections),
 	}
 
 	tcpCloseMap, _ := tr.getMap(tcpCloseBatchMap)
@@ -361,6 +377,9 @@ func (t *Tracer) storeClosedConn(cs network.ConnectionStats) {
 
 func (t *Tracer) Stop() {
 	t.reverseDNS.Close()
+	if t.protocolMonitor != nil {
+		t.protocolMonitor.Close()
+	}
 	_ = t.m.Close()
 	t.perfMap.PollStop()
 	t.conntracker.Close()
@@ -383,6 +402,9 @@ func (t *Tracer) GetActiveConnections(clientID string) (*network.Connections, er
 	}
 
 	conns := t.state.Connections(clientID, latestTime, latestConns, t.reverseDNS.GetDNSStats())
+	if t.protocolMonitor != nil {
+		t.protocolMonitor.AnnotateConnections(conns)
+	}
 	names := t.reverseDNS.Resolve(conns)
 	tm := t.getConnTelemetry(len(latestConns))
 
@@ -662,7 +684,7 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	stateStats := t.state.GetStats()
 	conntrackStats := t.conntracker.GetStats()
 
-	return map[string]interface{}{
+	stats := map[string]interface{}{
 		"conntrack": conntrackStats,
 		"state":     stateStats,
 		"tracer": map[string]int64{
@@ -675,7 +697,29 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 		"ebpf":    t.getEbpfTelemetry(),
 		"kprobes": GetProbeStats(),
 		"dns":     t.reverseDNS.GetStats(),
-	}, nil
+	}
+	if t.protocolMonitor != nil {
+		stats["protocols"] = t.protocolMonitor.GetStats()
+	}
+	return stats, nil
+}
+
+// GetServiceStats returns the requests stats aggregated per service since the last call,
+// the services are classified from the payloads of the TCP connections
+func (t *Tracer) GetServiceStats() ([]network.Service, error) {
+	if t.protocolMonitor == nil {
+		return nil, fmt.Errorf("protocol classification is not enabled")
+	}
+	return network.FormatServices(t.protocolMonitor.GetServiceStats()), nil
+}
+
+// GetDNSDomainStats returns the DNS stats of the connections returned to the given client since the last call,
+// aggregated by process and queried domain
+func (t *Tracer) GetDNSDomainStats(clientID string) ([]network.DNSDomainStats, error) {
+	if !t.config.CollectDNSStats {
+		return nil, fmt.Errorf("DNS stats collection is not enabled")
+	}
+	return t.state.DNSDomainStats(clientID), nil
 }
 
 // DebugNetworkState returns a map with the current tracer's internal state, for debugging
@@ -695,6 +739,60 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return &network.Connections{Conns: latestConns}, nil
 }
 
+// DebugConntrack returns a summary of the state of the conntrack cache, for debugging
+func (t *Tracer) DebugConntrack() (map[string]int64, error) {
+	return t.conntracker.GetStats(), nil
+}
+
+// DebugEBPFMaps returns the number of entries of the BPF maps tracking the connections and the
+// port bindings, along with their maximum number of entries, for debugging
+func (t *Tracer) DebugEBPFMaps() (map[string]interface{}, error) {
+	maxEntries := int64(t.config.MaxTrackedConnections)
+	maps := make(map[string]interface{})
+	for _, m := range []struct {
+		name         bpfMapName
+		key, nextKey unsafe.Pointer
+		value        unsafe.Pointer
+	}{
+		{connMap, unsafe.Pointer(&ConnTuple{}), unsafe.Pointer(&ConnTuple{}), unsafe.Pointer(&ConnStatsWithTimestamp{})},
+		{tcpStatsMap, unsafe.Pointer(&ConnTuple{}), unsafe.Pointer(&ConnTuple{}), unsafe.Pointer(&TCPStats{})},
+		{portBindingsMap, unsafe.Pointer(new(uint16)), unsafe.Pointer(new(uint16)), unsafe.Pointer(new(uint8))},
+		{udpPortBindingsMap, unsafe.Pointer(new(uint16)), unsafe.Pointer(new(uint16)), unsafe.Pointer(new(uint8))},
+	} {
+		mp, err := t.getMap(m.name)
+		if err != nil {
+			return nil, fmt.Errorf("error retrieving the bpf %s map: %s", m.name, err)
+		}
+
+		// the key and the next key are swapped, the next key of an iteration being the key of the next one
+		var entries int64
+		key, nextKey := m.key, m.nextKey
+		for {
+			hasNext, _ := t.m.LookupNextElement(mp, key, nextKey, m.value)
+			if !hasNext {
+				break
+			}
+			entries++
+			key, nextKey = nextKey, key
+		}
+
+		maps[string(m.name)] = map[string]interface{}{
+			"entries":     entries,
+			"max_entries": maxEntries,
+			"usage":       float64(entries) / float64(maxEntries),
+		}
+	}
+	return maps, nil
+}
+
+// DebugKernelTelemetry returns the telemetry collected in the kernel and the kprobes stats, for debugging
+func (t *Tracer) DebugKernelTelemetry() (map[string]interface{}, error) {
+	return map[string]interface{}{
+		"ebpf":    t.getEbpfTelemetry(),
+		"kprobes": GetProbeStats(),
+	}, nil
+}
+
 // populatePortMapping reads an entire portBinding bpf map and populates the userspace  port map.  A list of
 // closed ports will be returned.
 // the map will be one of port_bindings  or udp_port_bindings, and the mapping will be one of tracer#portMapping
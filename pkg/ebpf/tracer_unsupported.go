@@ -25,6 +25,16 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	return nil, ErrNotImplemented
 }
 
+// GetServiceStats is not implemented on this OS for Tracer
+func (t *Tracer) GetServiceStats() ([]network.Service, error) {
+	return nil, ErrNotImplemented
+}
+
+// GetDNSDomainStats is not implemented on this OS for Tracer
+func (t *Tracer) GetDNSDomainStats(_ string) ([]network.DNSDomainStats, error) {
+	return nil, ErrNotImplemented
+}
+
 // DebugNetworkState is not implemented on this OS for Tracer
 func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, error) {
 	return nil, ErrNotImplemented
@@ -35,6 +45,21 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ErrNotImplemented
 }
 
+// DebugConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugConntrack() (map[string]int64, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugEBPFMaps is not implemented on this OS for Tracer
+func (t *Tracer) DebugEBPFMaps() (map[string]interface{}, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugKernelTelemetry is not implemented on this OS for Tracer
+func (t *Tracer) DebugKernelTelemetry() (map[string]interface{}, error) {
+	return nil, ErrNotImplemented
+}
+
 // CurrentKernelVersion is not implemented on this OS for Tracer
 func CurrentKernelVersion() (uint32, error) {
 	return 0, ErrNotImplemented
@@ -148,6 +148,17 @@ func (t *Tracer) GetStats() (map[string]interface{}, error) {
 	}, nil
 }
 
+// GetServiceStats is not implemented on Windows
+func (t *Tracer) GetServiceStats() ([]network.Service, error) {
+	return nil, ErrNotImplemented
+}
+
+// GetDNSDomainStats returns the DNS stats of the connections returned to the given client since the last call,
+// aggregated by process and queried domain
+func (t *Tracer) GetDNSDomainStats(clientID string) ([]network.DNSDomainStats, error) {
+	return t.state.DNSDomainStats(clientID), nil
+}
+
 // DebugNetworkState returns a map with the current tracer's internal state, for debugging
 func (t *Tracer) DebugNetworkState(clientID string) (map[string]interface{}, error) {
 	return nil, ErrNotImplemented
@@ -158,6 +169,21 @@ func (t *Tracer) DebugNetworkMaps() (*network.Connections, error) {
 	return nil, ErrNotImplemented
 }
 
+// DebugConntrack is not implemented on this OS for Tracer
+func (t *Tracer) DebugConntrack() (map[string]int64, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugEBPFMaps is not implemented on this OS for Tracer
+func (t *Tracer) DebugEBPFMaps() (map[string]interface{}, error) {
+	return nil, ErrNotImplemented
+}
+
+// DebugKernelTelemetry is not implemented on this OS for Tracer
+func (t *Tracer) DebugKernelTelemetry() (map[string]interface{}, error) {
+	return nil, ErrNotImplemented
+}
+
 // CurrentKernelVersion is not implemented on this OS for Tracer
 func CurrentKernelVersion() (uint32, error) {
 	return 0, ErrNotImplemented
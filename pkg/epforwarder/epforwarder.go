@@ -0,0 +1,284 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package epforwarder
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/client"
+	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The event types of the built-in intake tracks
+const (
+	// EventTypeNetworkDevicesMetadata are the metadata of the network devices
+	EventTypeNetworkDevicesMetadata = "network-devices-metadata"
+	// EventTypeNetworkDevicesNetFlow are the flows exported by the network devices
+	EventTypeNetworkDevicesNetFlow = "network-devices-netflow"
+	// EventTypeSnmpTraps are the traps sent by the network devices
+	EventTypeSnmpTraps = "network-devices-snmp-traps"
+	// EventTypeDBMSamples are the query samples of the database monitoring
+	EventTypeDBMSamples = "dbm-samples"
+	// EventTypeDBMMetrics are the query metrics of the database monitoring
+	EventTypeDBMMetrics = "dbm-metrics"
+)
+
+// stopTimeout is how long the pipelines are given to flush their events when stopping
+const stopTimeout = 5 * time.Second
+
+// EventPlatformForwarder forwards the events submitted by the checks to the intake tracks of the event platform
+type EventPlatformForwarder interface {
+	SendEventPlatformEvent(e *message.Message, eventType string) error
+	Start()
+	Stop()
+}
+
+// trackDesc describes an intake track of the event platform and how its endpoints are configured
+type trackDesc struct {
+	eventType string
+	// configPrefix is the prefix of the configuration keys of the endpoints of the track
+	configPrefix string
+	// hostnameEndpointPrefix is the prefix of the host of the intake, the site being appended to it
+	hostnameEndpointPrefix string
+	trackType              config.IntakeTrackType
+
+	defaultBatchMaxConcurrentSend int
+	defaultBatchMaxSize           int
+	defaultBatchMaxContentSize    int
+}
+
+var builtinTracks = []trackDesc{
+	{
+		eventType:                     EventTypeNetworkDevicesMetadata,
+		configPrefix:                  "network_devices.metadata.",
+		hostnameEndpointPrefix:        "ndm-intake.",
+		trackType:                     "ndm",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxSize:           coreConfig.DefaultBatchMaxSize,
+		defaultBatchMaxContentSize:    coreConfig.DefaultBatchMaxContentSize,
+	},
+	{
+		eventType:                     EventTypeNetworkDevicesNetFlow,
+		configPrefix:                  "network_devices.netflow.forwarder.",
+		hostnameEndpointPrefix:        "ndmflow-intake.",
+		trackType:                     "ndmflow",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxSize:           10000,
+		defaultBatchMaxContentSize:    coreConfig.DefaultBatchMaxContentSize,
+	},
+	{
+		eventType:                     EventTypeSnmpTraps,
+		configPrefix:                  "network_devices.snmp_traps.forwarder.",
+		hostnameEndpointPrefix:        "snmp-traps-intake.",
+		trackType:                     "ndmtraps",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxSize:           coreConfig.DefaultBatchMaxSize,
+		defaultBatchMaxContentSize:    coreConfig.DefaultBatchMaxContentSize,
+	},
+	{
+		eventType:                     EventTypeDBMSamples,
+		configPrefix:                  "database_monitoring.samples.",
+		hostnameEndpointPrefix:        "dbquery-intake.",
+		trackType:                     "databasequery",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxSize:           coreConfig.DefaultBatchMaxSize,
+		defaultBatchMaxContentSize:    coreConfig.DefaultBatchMaxContentSize,
+	},
+	{
+		eventType:                     EventTypeDBMMetrics,
+		configPrefix:                  "database_monitoring.metrics.",
+		hostnameEndpointPrefix:        "dbm-metrics-intake.",
+		trackType:                     "dbmmetrics",
+		defaultBatchMaxConcurrentSend: 10,
+		defaultBatchMaxSize:           coreConfig.DefaultBatchMaxSize,
+		defaultBatchMaxContentSize:    20e6,
+	},
+}
+
+// additionalTrack is a track configured in event_platform_forwarder.additional_tracks
+type additionalTrack struct {
+	EventType      string `mapstructure:"event_type"`
+	TrackType      string `mapstructure:"track_type"`
+	EndpointPrefix string `mapstructure:"endpoint_prefix"`
+}
+
+// getTracks returns the built-in tracks and the additional ones, whose endpoints are configured
+// under event_platform_forwarder.<event_type>.
+func getTracks() []trackDesc {
+	tracks := append([]trackDesc{}, builtinTracks...)
+
+	var additionals []additionalTrack
+	if err := coreConfig.Datadog.UnmarshalKey("event_platform_forwarder.additional_tracks", &additionals); err != nil {
+		log.Warnf("Could not parse event_platform_forwarder.additional_tracks: %v", err)
+		return tracks
+	}
+	for _, t := range additionals {
+		if t.EventType == "" || t.TrackType == "" {
+			log.Warnf("Ignoring the event platform track %+v: the event_type and the track_type are required", t)
+			continue
+		}
+		prefix := t.EndpointPrefix
+		if prefix == "" {
+			prefix = "event-platform-intake."
+		}
+		tracks = append(tracks, trackDesc{
+			eventType:                     t.EventType,
+			configPrefix:                  "event_platform_forwarder." + t.EventType + ".",
+			hostnameEndpointPrefix:        prefix,
+			trackType:                     config.IntakeTrackType(t.TrackType),
+			defaultBatchMaxConcurrentSend: coreConfig.DefaultBatchMaxConcurrentSend,
+			defaultBatchMaxSize:           coreConfig.DefaultBatchMaxSize,
+			defaultBatchMaxContentSize:    coreConfig.DefaultBatchMaxContentSize,
+		})
+	}
+	return tracks
+}
+
+// passthroughPipeline batches the events of a track and sends them as they are, without processing them
+type passthroughPipeline struct {
+	in     chan *message.Message
+	out    chan *message.Message
+	sender *sender.Sender
+}
+
+func newPassthroughPipeline(desc trackDesc, destinationsContext *client.DestinationsContext) (*passthroughPipeline, error) {
+	endpoints, err := config.BuildHTTPEndpointsWithConfig(desc.configPrefix, desc.hostnameEndpointPrefix, desc.trackType,
+		desc.defaultBatchMaxConcurrentSend, desc.defaultBatchMaxSize, desc.defaultBatchMaxContentSize)
+	if err != nil {
+		return nil, err
+	}
+
+	main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
+	additionals := []client.Destination{}
+	for _, endpoint := range endpoints.Additionals {
+		additionals = append(additionals, http.NewDestination(endpoint, http.JSONContentType, destinationsContext))
+	}
+	destinations := client.NewDestinations(main, additionals)
+
+	in := make(chan *message.Message, config.ChanSize)
+	out := make(chan *message.Message, config.ChanSize)
+	strategy := sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait, endpoints.BatchMaxConcurrentSend, endpoints.BatchMaxSize, endpoints.BatchMaxContentSize)
+	log.Debugf("Initialized the event platform track %s sending %s events to %s", desc.trackType, desc.eventType, endpoints.Main.Host)
+
+	return &passthroughPipeline{
+		in:     in,
+		out:    out,
+		sender: sender.NewSender(in, out, destinations, strategy),
+	}, nil
+}
+
+func (p *passthroughPipeline) start() {
+	// nothing keeps track of the events once they are sent
+	go func() {
+		for range p.out {
+		}
+	}()
+	p.sender.Start()
+}
+
+func (p *passthroughPipeline) stop() {
+	p.sender.Stop()
+	close(p.out)
+}
+
+type defaultEventPlatformForwarder struct {
+	pipelines           map[string]*passthroughPipeline
+	destinationsContext *client.DestinationsContext
+	started             bool
+	stopped             bool
+	mutex               sync.Mutex
+}
+
+// NewEventPlatformForwarder returns a forwarder with a pipeline for each intake track
+func NewEventPlatformForwarder() EventPlatformForwarder {
+	f := &defaultEventPlatformForwarder{
+		pipelines:           make(map[string]*passthroughPipeline),
+		destinationsContext: client.NewDestinationsContext(),
+	}
+	for _, desc := range getTracks() {
+		p, err := newPassthroughPipeline(desc, f.destinationsContext)
+		if err != nil {
+			log.Errorf("Could not set up the event platform track %s, the %s events will be dropped: %v", desc.trackType, desc.eventType, err)
+			continue
+		}
+		f.pipelines[desc.eventType] = p
+	}
+	return f
+}
+
+// SendEventPlatformEvent queues an event to be sent to the track of its type, the event is dropped
+// when the type is unknown or when the pipeline of the track can't keep up
+func (f *defaultEventPlatformForwarder) SendEventPlatformEvent(e *message.Message, eventType string) error {
+	p, found := f.pipelines[eventType]
+	if !found {
+		return fmt.Errorf("unknown event platform event type %s", eventType)
+	}
+	select {
+	case p.in <- e:
+		return nil
+	default:
+		return fmt.Errorf("the event platform pipeline of the %s events is full, consider increasing its batch_max_concurrent_send", eventType)
+	}
+}
+
+// Start starts the pipelines of the tracks
+func (f *defaultEventPlatformForwarder) Start() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if f.started || f.stopped {
+		return
+	}
+	f.started = true
+	f.destinationsContext.Start()
+	for _, p := range f.pipelines {
+		p.start()
+	}
+}
+
+// Stop flushes the events queued in the pipelines, and gives up after a timeout if the intake
+// doesn't accept them
+func (f *defaultEventPlatformForwarder) Stop() {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if !f.started || f.stopped {
+		return
+	}
+	f.stopped = true
+
+	done := make(chan struct{})
+	go func() {
+		for _, p := range f.pipelines {
+			p.stop()
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(stopTimeout):
+		log.Warn("Could not flush all the event platform events before stopping")
+	}
+	f.destinationsContext.Stop()
+	<-done
+}
+
+type noopEventPlatformForwarder struct{}
+
+// NewNoopEventPlatformForwarder returns a forwarder dropping all the events, for the processes
+// which don't send them and for the tests
+func NewNoopEventPlatformForwarder() EventPlatformForwarder {
+	return noopEventPlatformForwarder{}
+}
+
+func (noopEventPlatformForwarder) SendEventPlatformEvent(*message.Message, string) error { return nil }
+func (noopEventPlatformForwarder) Start()                                                {}
+func (noopEventPlatformForwarder) Stop()                                                 {}
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package epforwarder
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type receivedPayload struct {
+	path   string
+	apiKey string
+	body   string
+}
+
+func newIntake(t *testing.T) (*httptest.Server, chan receivedPayload) {
+	payloads := make(chan receivedPayload, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+		payloads <- receivedPayload{path: r.URL.Path, apiKey: r.Header.Get("DD-API-KEY"), body: string(body)}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	return server, payloads
+}
+
+func TestSendEventPlatformEvent(t *testing.T) {
+	server, payloads := newIntake(t)
+	defer server.Close()
+
+	mockConfig := coreConfig.Mock()
+	mockConfig.Set("api_key", "123456789")
+	mockConfig.Set("network_devices.metadata.logs_dd_url", strings.TrimPrefix(server.URL, "http://"))
+	mockConfig.Set("network_devices.metadata.logs_no_ssl", true)
+	mockConfig.Set("network_devices.metadata.use_compression", false)
+	mockConfig.Set("network_devices.metadata.batch_wait", 1)
+	defer mockConfig.Set("network_devices.metadata.logs_dd_url", "")
+
+	forwarder := NewEventPlatformForwarder()
+	forwarder.Start()
+	defer forwarder.Stop()
+
+	err := forwarder.SendEventPlatformEvent(message.NewMessage([]byte(`{"device":"router"}`), nil, ""), EventTypeNetworkDevicesMetadata)
+	require.NoError(t, err)
+
+	select {
+	case payload := <-payloads:
+		assert.Equal(t, "/api/v2/ndm", payload.path)
+		assert.Equal(t, "123456789", payload.apiKey)
+		assert.Equal(t, `[{"device":"router"}]`, payload.body)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the event was not sent")
+	}
+}
+
+func TestSendEventPlatformEventUnknownType(t *testing.T) {
+	forwarder := NewEventPlatformForwarder()
+	err := forwarder.SendEventPlatformEvent(message.NewMessage([]byte(`{}`), nil, ""), "unknown")
+	assert.Error(t, err)
+}
+
+func TestAdditionalTracks(t *testing.T) {
+	mockConfig := coreConfig.Mock()
+	mockConfig.Set("event_platform_forwarder.additional_tracks", []map[string]string{
+		{"event_type": "custom-events", "track_type": "custom"},
+		{"event_type": "missing-track-type"},
+	})
+	defer mockConfig.Set("event_platform_forwarder.additional_tracks", nil)
+
+	tracks := getTracks()
+	require.Len(t, tracks, len(builtinTracks)+1)
+	custom := tracks[len(tracks)-1]
+	assert.Equal(t, "custom-events", custom.eventType)
+	assert.EqualValues(t, "custom", custom.trackType)
+	assert.Equal(t, "event_platform_forwarder.custom-events.", custom.configPrefix)
+	assert.Equal(t, "event-platform-intake.", custom.hostnameEndpointPrefix)
+}
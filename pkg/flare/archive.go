@@ -24,10 +24,12 @@ import (
 	"strings"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/api/security"
 	api_util "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/diagnose"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
 	"github.com/DataDog/datadog-agent/pkg/secrets"
 	"github.com/DataDog/datadog-agent/pkg/status"
 	"github.com/DataDog/datadog-agent/pkg/status/health"
@@ -46,10 +48,9 @@ const (
 )
 
 var (
-	pprofURL = fmt.Sprintf("http://127.0.0.1:%s/debug/pprof/goroutine?debug=2",
-		config.Datadog.GetString("expvar_port"))
-	telemetryURL = fmt.Sprintf("http://127.0.0.1:%s/telemetry",
-		config.Datadog.GetString("expvar_port"))
+	// pprofURL and telemetryURL override the URLs of the expvar server endpoints, in tests
+	pprofURL     string
+	telemetryURL string
 
 	// Match .yaml and .yml to ship configuration files in the flare.
 	cnfFileExtRx = regexp.MustCompile(`(?i)\.ya?ml`)
@@ -165,6 +166,11 @@ func createArchive(zipFilePath string, local bool, confSearchPaths SearchPaths,
 		if err != nil {
 			log.Errorf("Could not zip system probe exp var stats: %s", err)
 		}
+
+		err = zipSystemProbeDebugState(tempDir, hostname)
+		if err != nil {
+			log.Errorf("Could not zip system probe debug state: %s", err)
+		}
 	}
 
 	err = zipDiagnose(tempDir, hostname)
@@ -435,6 +441,61 @@ func zipSystemProbeStats(tempDir, hostname string) error {
 	return err
 }
 
+// systemProbeDebugStates are the debug endpoints of the system-probe dumped in the flare
+var systemProbeDebugStates = []string{"conntrack", "ebpf_maps", "kernel_telemetry"}
+
+// getSystemProbeDebugState queries a debug endpoint of the system-probe, overridden in tests
+var getSystemProbeDebugState = func(name string) (map[string]interface{}, error) {
+	process_net.SetSystemProbePath(config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+	probeUtil, err := process_net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return nil, err
+	}
+	return probeUtil.GetDebugState(name)
+}
+
+// zipSystemProbeDebugState writes the state of the conntrack cache, the occupancy of the eBPF maps
+// and the kernel telemetry of the system-probe in the system-probe directory of the flare
+func zipSystemProbeDebugState(tempDir, hostname string) error {
+	var errs []string
+	for _, name := range systemProbeDebugStates {
+		if err := zipSystemProbeDebugEndpoint(tempDir, hostname, name); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+func zipSystemProbeDebugEndpoint(tempDir, hostname, name string) error {
+	state, err := getSystemProbeDebugState(name)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	f := filepath.Join(tempDir, hostname, "system-probe", name+".json")
+	err = ensureParentDirsExist(f)
+	if err != nil {
+		return err
+	}
+
+	w, err := newRedactingWriter(f, os.ModePerm, true)
+	if err != nil {
+		return err
+	}
+	defer w.Close()
+
+	_, err = w.Write(data)
+	return err
+}
+
 func zipConfigFiles(tempDir, hostname string, confSearchPaths SearchPaths, permsInfos permissionsInfos) error {
 	c, err := yaml.Marshal(config.Datadog.AllSettings())
 	if err != nil {
@@ -631,7 +692,7 @@ func zipTaggerList(tempDir, hostname string) error {
 		taggerListURL = fmt.Sprintf("https://%v:%v/agent/tagger-list", ipcAddress, config.Datadog.GetInt("cmd_port"))
 	}
 
-	c := api_util.GetClient(false) // FIX: get certificates right then make this true
+	c := api_util.GetIPCClient()
 
 	r, err := api_util.DoGet(c, taggerListURL)
 	if err != nil {
@@ -702,12 +763,22 @@ func zipInstallInfo(tempDir, hostname string) error {
 	return err
 }
 
+// expvarURL returns the URL of an endpoint of the expvar server of the running agent. It uses
+// the port advertised by the agent, which differs from `expvar_port` when that one was in use.
+func expvarURL(override, path string) string {
+	if override != "" {
+		return override
+	}
+	port := debugport.Lookup(debugport.Agent, debugport.Expvar, config.Datadog.GetInt("expvar_port"))
+	return fmt.Sprintf("http://127.0.0.1:%d%s", port, path)
+}
+
 func zipTelemetry(tempDir, hostname string) error {
-	return zipHTTPCallContent(tempDir, hostname, "telemetry.log", telemetryURL)
+	return zipHTTPCallContent(tempDir, hostname, "telemetry.log", expvarURL(telemetryURL, "/telemetry"))
 }
 
 func zipStackTraces(tempDir, hostname string) error {
-	return zipHTTPCallContent(tempDir, hostname, routineDumpFilename, pprofURL)
+	return zipHTTPCallContent(tempDir, hostname, routineDumpFilename, expvarURL(pprofURL, "/debug/pprof/goroutine?debug=2"))
 }
 
 // zipHTTPCallContent does a GET HTTP call to the given url and
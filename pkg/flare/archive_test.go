@@ -281,3 +281,30 @@ func TestZipTaggerList(t *testing.T) {
 	assert.Contains(t, string(content), "docker_image:custom-agent:latest")
 	assert.Contains(t, string(content), "image_name:custom-agent")
 }
+
+func TestZipSystemProbeDebugState(t *testing.T) {
+	defer func(f func(string) (map[string]interface{}, error)) { getSystemProbeDebugState = f }(getSystemProbeDebugState)
+	getSystemProbeDebugState = func(name string) (map[string]interface{}, error) {
+		if name == "kernel_telemetry" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return map[string]interface{}{"endpoint": name}, nil
+	}
+
+	dir, err := ioutil.TempDir("", "TestZipSystemProbeDebugState")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = zipSystemProbeDebugState(dir, "")
+	assert.EqualError(t, err, "kernel_telemetry: connection refused")
+
+	for _, name := range []string{"conntrack", "ebpf_maps"} {
+		content, err := ioutil.ReadFile(filepath.Join(dir, "system-probe", name+".json"))
+		assert.NoError(t, err)
+		assert.Contains(t, string(content), name)
+	}
+	_, err = os.Stat(filepath.Join(dir, "system-probe", "kernel_telemetry.json"))
+	assert.True(t, os.IsNotExist(err))
+}
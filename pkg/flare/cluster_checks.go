@@ -6,6 +6,7 @@
 package flare
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -88,9 +89,13 @@ func GetClusterChecks(w io.Writer) error {
 	fmt.Fprintln(w, fmt.Sprintf("=== %d node-agents reporting ===", len(cr.Nodes)))
 	sort.Slice(cr.Nodes, func(i, j int) bool { return cr.Nodes[i].Name < cr.Nodes[j].Name })
 	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
-	fmt.Fprintln(table, "\nName\tRunning checks")
+	fmt.Fprintln(table, "\nName\tRunning checks\tBusyness")
 	for _, n := range cr.Nodes {
-		fmt.Fprintf(table, "%s\t%d\n", n.Name, len(n.Configs))
+		busyness := "unknown"
+		if n.Busyness >= 0 {
+			busyness = fmt.Sprintf("%d", n.Busyness)
+		}
+		fmt.Fprintf(table, "%s\t%d\t%s\n", n.Name, len(n.Configs), busyness)
 	}
 	table.Flush()
 
@@ -108,6 +113,60 @@ func GetClusterChecks(w io.Writer) error {
 	return nil
 }
 
+// RebalanceClusterChecks triggers a rebalancing of the cluster checks and dumps the moves to the writer
+func RebalanceClusterChecks(w io.Writer) error {
+	urlstr := fmt.Sprintf("https://localhost:%v/api/v1/clusterchecks/rebalance", config.Datadog.GetInt("cluster_agent.cmd_port"))
+
+	if w != color.Output {
+		color.NoColor = true
+	}
+
+	if !config.Datadog.GetBool("cluster_checks.enabled") {
+		fmt.Fprintln(w, "Cluster-checks are not enabled")
+		return nil
+	}
+
+	c := util.GetClient(false) // FIX: get certificates right then make this true
+
+	// Set session token
+	if err := util.SetAuthToken(); err != nil {
+		return err
+	}
+
+	r, err := util.DoPost(c, urlstr, "application/json", bytes.NewBuffer([]byte{}))
+	if err != nil {
+		if r != nil && string(r) != "" {
+			fmt.Fprintln(w, fmt.Sprintf("The agent ran into an error while rebalancing checks: %s", string(r)))
+		} else {
+			fmt.Fprintln(w, fmt.Sprintf("Failed to query the agent (running?): %s", err))
+		}
+		return err
+	}
+
+	var rr types.RebalanceResponse
+	if err = json.Unmarshal(r, &rr); err != nil {
+		return err
+	}
+
+	if len(rr.NotRunning) > 0 {
+		fmt.Fprintf(w, "Cluster-check rebalancing not running: %s\n", rr.NotRunning)
+		return nil
+	}
+
+	if len(rr.Moves) == 0 {
+		fmt.Fprintln(w, "Cluster checks are balanced, no check was moved")
+		return nil
+	}
+
+	fmt.Fprintln(w, fmt.Sprintf("=== %d checks moved ===", len(rr.Moves)))
+	table := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(table, "\nCheck\tWeight\tSource node\tDestination node")
+	for _, m := range rr.Moves {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\n", m.CheckID, m.CheckWeight, m.SourceNode, m.DestNode)
+	}
+	return table.Flush()
+}
+
 // GetEndpointsChecks dumps the endpointschecks dispatching state to the writer
 func GetEndpointsChecks(w io.Writer) error {
 	if !endpointschecksEnabled() {
@@ -28,7 +28,7 @@ func GetConfigCheck(w io.Writer, withDebug bool) error {
 		color.NoColor = true
 	}
 
-	c := util.GetClient(false) // FIX: get certificates right then make this true
+	c := util.GetIPCClient()
 
 	// Set session token
 	err := util.SetAuthToken()
@@ -72,11 +72,18 @@ func newDomainForwarder(domain string, numberOfWorkers int, retryQueueLimit int,
 	}
 }
 
-type byCreatedTime []Transaction
-
-func (v byCreatedTime) Len() int           { return len(v) }
-func (v byCreatedTime) Swap(i, j int)      { v[i], v[j] = v[j], v[i] }
-func (v byCreatedTime) Less(i, j int) bool { return v[i].GetCreatedAt().After(v[j].GetCreatedAt()) }
+// byPriorityAndCreatedTime sorts the transactions by decreasing priority, then from the newest
+// to the oldest, the last ones being dropped first when the retry queue is full
+type byPriorityAndCreatedTime []Transaction
+
+func (v byPriorityAndCreatedTime) Len() int      { return len(v) }
+func (v byPriorityAndCreatedTime) Swap(i, j int) { v[i], v[j] = v[j], v[i] }
+func (v byPriorityAndCreatedTime) Less(i, j int) bool {
+	if pi, pj := v[i].GetPriority(), v[j].GetPriority(); pi != pj {
+		return pi > pj
+	}
+	return v[i].GetCreatedAt().After(v[j].GetCreatedAt())
+}
 
 func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	// In case it takes more that flushInterval to sort and retry
@@ -91,7 +98,7 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	droppedRetryQueueFull := 0
 	droppedWorkerBusy := 0
 
-	sort.Sort(byCreatedTime(f.retryQueue))
+	sort.Sort(byPriorityAndCreatedTime(f.retryQueue))
 
 	for _, t := range f.retryQueue {
 		if !f.blockedList.isBlock(t.GetTarget()) {
@@ -101,8 +108,7 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 				tlmTxRetried.Inc(f.domain)
 			default:
 				droppedWorkerBusy++
-				transactionsDropped.Add(1)
-				tlmTxDropped.Inc(f.domain)
+				f.dropTransaction(t)
 			}
 		} else if len(newQueue) < f.retryQueueLimit {
 			newQueue = append(newQueue, t)
@@ -110,8 +116,7 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 			tlmTxRequeud.Inc(f.domain)
 		} else {
 			droppedRetryQueueFull++
-			transactionsDropped.Add(1)
-			tlmTxDropped.Inc(f.domain)
+			f.dropTransaction(t)
 		}
 	}
 
@@ -125,6 +130,14 @@ func (f *domainForwarder) retryTransactions(retryBefore time.Time) {
 	}
 }
 
+func (f *domainForwarder) dropTransaction(t Transaction) {
+	priority := t.GetPriority().String()
+	transactionsDropped.Add(1)
+	transactionsDroppedByPriority.Add(priority, 1)
+	tlmTxDropped.Inc(f.domain)
+	tlmTxDroppedByPriority.Inc(f.domain, priority)
+}
+
 func (f *domainForwarder) requeueTransaction(t Transaction) {
 	f.retryQueue = append(f.retryQueue, t)
 	transactionsRequeued.Add(1)
@@ -6,6 +6,7 @@
 package forwarder
 
 import (
+	"expvar"
 	"testing"
 	"time"
 
@@ -234,3 +235,39 @@ func TestForwarderRetryLimitQueue(t *testing.T) {
 	// assert that the oldest transaction was dropped
 	assert.Equal(t, transaction2, forwarder.retryQueue[0])
 }
+
+func TestForwarderRetryLimitQueuePriority(t *testing.T) {
+	forwarder := newDomainForwarder("test", 1, 10, 0)
+	forwarder.init()
+
+	forwarder.retryQueueLimit = 1
+	forwarder.blockedList.close("blocked")
+	forwarder.blockedList.errorPerEndpoint["blocked"].until = time.Now().Add(1 * time.Minute)
+
+	highPriority := newTestTransaction()
+	highPriority.priority = TransactionPriorityHigh
+	lowPriority := newTestTransaction()
+	lowPriority.priority = TransactionPriorityLow
+
+	forwarder.requeueTransaction(lowPriority)
+	forwarder.requeueTransaction(highPriority)
+
+	// the priorities differ, the creation times are not compared
+	highPriority.On("GetTarget").Return("blocked").Times(1)
+	lowPriority.On("GetTarget").Return("blocked").Times(1)
+
+	droppedLow := int64(0)
+	if v, ok := transactionsDroppedByPriority.Get("low").(*expvar.Int); ok {
+		droppedLow = v.Value()
+	}
+
+	forwarder.retryTransactions(time.Now())
+
+	highPriority.AssertExpectations(t)
+	lowPriority.AssertExpectations(t)
+	highPriority.AssertNumberOfCalls(t, "GetCreatedAt", 0)
+	require.Len(t, forwarder.retryQueue, 1)
+	// assert that the low priority transaction was dropped, even if it is the newest
+	assert.Equal(t, highPriority, forwarder.retryQueue[0])
+	assert.Equal(t, droppedLow+1, transactionsDroppedByPriority.Get("low").(*expvar.Int).Value())
+}
@@ -41,6 +41,10 @@ var (
 	transactionsIntakeRTContainer = expvar.Int{}
 	transactionsIntakeConnections = expvar.Int{}
 	transactionsIntakePod         = expvar.Int{}
+	transactionsIntakeDeployment  = expvar.Int{}
+	transactionsIntakeDiscovery   = expvar.Int{}
+	transactionsSBOM              = expvar.Int{}
+	transactionsNetworkPath       = expvar.Int{}
 
 	tlm = telemetry.NewCounter("forwarder", "transactions",
 		[]string{"endpoint", "route"}, "Forwarder telemetry")
@@ -57,13 +61,17 @@ var (
 	sketchSeriesEndpoint  = endpoint{"/api/beta/sketches", "sketches_v2"}
 	hostMetadataEndpoint  = endpoint{"/api/v2/host_metadata", "host_metadata_v2"}
 	metadataEndpoint      = endpoint{"/api/v2/metadata", "metadata_v2"}
-
-	processesEndpoint   = endpoint{"/api/v1/collector", "process"}
-	rtProcessesEndpoint = endpoint{"/api/v1/collector", "rtprocess"}
-	containerEndpoint   = endpoint{"/api/v1/container", "container"}
-	rtContainerEndpoint = endpoint{"/api/v1/container", "rtcontainer"}
-	connectionsEndpoint = endpoint{"/api/v1/collector", "connections"}
-	podEndpoint         = endpoint{"/api/v1/orchestrator", "pod"}
+	sbomEndpoint          = endpoint{"/api/v2/sbom", "sbom_v2"}
+	networkPathEndpoint   = endpoint{"/api/v2/netpath", "network_path_v2"}
+
+	processesEndpoint        = endpoint{"/api/v1/collector", "process"}
+	rtProcessesEndpoint      = endpoint{"/api/v1/collector", "rtprocess"}
+	containerEndpoint        = endpoint{"/api/v1/container", "container"}
+	rtContainerEndpoint      = endpoint{"/api/v1/container", "rtcontainer"}
+	connectionsEndpoint      = endpoint{"/api/v1/collector", "connections"}
+	podEndpoint              = endpoint{"/api/v1/orchestrator", "pod"}
+	deploymentEndpoint       = endpoint{"/api/v1/orchestrator", "deployment"}
+	processDiscoveryEndpoint = endpoint{"/api/v1/discovery", "process_discovery"}
 )
 
 func init() {
@@ -86,8 +94,13 @@ func init() {
 	transactionsExpvars.Set("RTContainers", &transactionsIntakeRTContainer)
 	transactionsExpvars.Set("Connections", &transactionsIntakeConnections)
 	transactionsExpvars.Set("Pods", &transactionsIntakePod)
+	transactionsExpvars.Set("Deployments", &transactionsIntakeDeployment)
+	transactionsExpvars.Set("ProcessDiscovery", &transactionsIntakeDiscovery)
+	transactionsExpvars.Set("SBOM", &transactionsSBOM)
+	transactionsExpvars.Set("NetworkPath", &transactionsNetworkPath)
 	initDomainForwarderExpvars()
 	initTransactionExpvars()
+	initTransactionErrorsExpvars()
 	initForwarderHealthExpvars()
 }
 
@@ -119,6 +132,19 @@ func (e endpoint) String() string {
 	return e.route
 }
 
+// priority returns the priority in the retry queue of the transactions sent to the endpoint
+func (e endpoint) priority() TransactionPriority {
+	switch e {
+	case seriesEndpoint, v1SeriesEndpoint:
+		return TransactionPriorityLow
+	case serviceChecksEndpoint, v1CheckRunsEndpoint, sketchSeriesEndpoint, v1SketchSeriesEndpoint,
+		hostMetadataEndpoint, metadataEndpoint, v1IntakeEndpoint:
+		return TransactionPriorityHigh
+	default:
+		return TransactionPriorityNormal
+	}
+}
+
 // Payloads is a slice of pointers to byte arrays, an alias for the slices of
 // payloads we pass into the forwarder
 type Payloads []*[]byte
@@ -144,12 +170,16 @@ type Forwarder interface {
 	SubmitSketchSeries(payload Payloads, extra http.Header) error
 	SubmitHostMetadata(payload Payloads, extra http.Header) error
 	SubmitMetadata(payload Payloads, extra http.Header) error
+	SubmitSBOM(payload Payloads, extra http.Header) error
+	SubmitNetworkPath(payload Payloads, extra http.Header) error
 	SubmitProcessChecks(payload Payloads, extra http.Header) (chan Response, error)
 	SubmitRTProcessChecks(payload Payloads, extra http.Header) (chan Response, error)
 	SubmitContainerChecks(payload Payloads, extra http.Header) (chan Response, error)
 	SubmitRTContainerChecks(payload Payloads, extra http.Header) (chan Response, error)
 	SubmitConnectionChecks(payload Payloads, extra http.Header) (chan Response, error)
 	SubmitPodChecks(payload Payloads, extra http.Header) (chan Response, error)
+	SubmitDeploymentChecks(payload Payloads, extra http.Header) (chan Response, error)
+	SubmitProcessDiscoveryChecks(payload Payloads, extra http.Header) (chan Response, error)
 }
 
 // Compile-time check to ensure that DefaultForwarder implements the Forwarder interface
@@ -326,6 +356,7 @@ func (f *DefaultForwarder) createHTTPTransactions(endpoint endpoint, payloads Pa
 				t.Domain = domain
 				t.Endpoint = transactionEndpoint
 				t.Payload = payload
+				t.Priority = endpoint.priority()
 				t.Headers.Set(apiHTTPHeaderKey, apiKey)
 				t.Headers.Set(versionHTTPHeaderKey, version.AgentVersion)
 				t.Headers.Set(useragentHTTPHeaderKey, fmt.Sprintf("datadog-agent/%s", version.AgentVersion))
@@ -397,6 +428,20 @@ func (f *DefaultForwarder) SubmitMetadata(payload Payloads, extra http.Header) e
 	return f.sendHTTPTransactions(transactions)
 }
 
+// SubmitSBOM will send a software bill of materials type payload to Datadog backend.
+func (f *DefaultForwarder) SubmitSBOM(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(sbomEndpoint, payload, false, extra)
+	transactionsSBOM.Add(1)
+	return f.sendHTTPTransactions(transactions)
+}
+
+// SubmitNetworkPath will send a network path type payload to Datadog backend.
+func (f *DefaultForwarder) SubmitNetworkPath(payload Payloads, extra http.Header) error {
+	transactions := f.createHTTPTransactions(networkPathEndpoint, payload, false, extra)
+	transactionsNetworkPath.Add(1)
+	return f.sendHTTPTransactions(transactions)
+}
+
 // SubmitV1Series will send timeserie to v1 endpoint (this will be remove once
 // the backend handles v2 endpoints).
 func (f *DefaultForwarder) SubmitV1Series(payload Payloads, extra http.Header) error {
@@ -468,6 +513,20 @@ func (f *DefaultForwarder) SubmitPodChecks(payload Payloads, extra http.Header)
 	return f.submitProcessLikePayload(podEndpoint, payload, extra, true)
 }
 
+// SubmitDeploymentChecks sends deployment checks
+func (f *DefaultForwarder) SubmitDeploymentChecks(payload Payloads, extra http.Header) (chan Response, error) {
+	transactionsIntakeDeployment.Add(1)
+
+	return f.submitProcessLikePayload(deploymentEndpoint, payload, extra, true)
+}
+
+// SubmitProcessDiscoveryChecks sends process discovery checks
+func (f *DefaultForwarder) SubmitProcessDiscoveryChecks(payload Payloads, extra http.Header) (chan Response, error) {
+	transactionsIntakeDiscovery.Add(1)
+
+	return f.submitProcessLikePayload(processDiscoveryEndpoint, payload, extra, true)
+}
+
 func (f *DefaultForwarder) submitProcessLikePayload(ep endpoint, payload Payloads, extra http.Header, retryable bool) (chan Response, error) {
 	transactions := f.createHTTPTransactions(ep, payload, false, extra)
 
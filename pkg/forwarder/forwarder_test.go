@@ -102,6 +102,8 @@ func TestSubmitIfStopped(t *testing.T) {
 	assert.NotNil(t, forwarder.SubmitSketchSeries(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitHostMetadata(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitMetadata(nil, make(http.Header)))
+	assert.NotNil(t, forwarder.SubmitSBOM(nil, make(http.Header)))
+	assert.NotNil(t, forwarder.SubmitNetworkPath(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitV1Series(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitV1Intake(nil, make(http.Header)))
 	assert.NotNil(t, forwarder.SubmitV1CheckRuns(nil, make(http.Header)))
@@ -135,6 +137,12 @@ func TestCreateHTTPTransactions(t *testing.T) {
 	assert.Equal(t, p1, *(transactions[1].Payload))
 	assert.Equal(t, p2, *(transactions[2].Payload))
 	assert.Equal(t, p2, *(transactions[3].Payload))
+	assert.Equal(t, TransactionPriorityNormal, transactions[0].Priority)
+
+	transactions = forwarder.createHTTPTransactions(seriesEndpoint, payloads, false, headers)
+	assert.Equal(t, TransactionPriorityLow, transactions[0].Priority)
+	transactions = forwarder.createHTTPTransactions(serviceChecksEndpoint, payloads, false, headers)
+	assert.Equal(t, TransactionPriorityHigh, transactions[0].Priority)
 
 	transactions = forwarder.createHTTPTransactions(endpoint, payloads, true, headers)
 	require.Len(t, transactions, 4)
@@ -230,15 +238,17 @@ func TestForwarderEndtoEnd(t *testing.T) {
 	assert.Nil(t, f.SubmitSketchSeries(payload, headers))
 	assert.Nil(t, f.SubmitHostMetadata(payload, headers))
 	assert.Nil(t, f.SubmitMetadata(payload, headers))
+	assert.Nil(t, f.SubmitSBOM(payload, headers))
+	assert.Nil(t, f.SubmitNetworkPath(payload, headers))
 
 	// let's wait a second for every channel communication to trigger
 	<-time.After(1 * time.Second)
 
-	// We should receive 38 requests:
-	// - 9 transactions * 2 payloads per transactions * 2 api_keys
+	// We should receive 46 requests:
+	// - 11 transactions * 2 payloads per transactions * 2 api_keys
 	// - 2 requests to check the validity of the two api_key
 	ts.Close()
-	assert.Equal(t, int64(38), requests)
+	assert.Equal(t, int64(46), requests)
 }
 
 func TestTransactionEventHandlers(t *testing.T) {
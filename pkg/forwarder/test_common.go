@@ -17,6 +17,7 @@ type testTransaction struct {
 	mock.Mock
 	assertClient bool
 	processed    chan bool
+	priority     TransactionPriority
 }
 
 func newTestTransaction() *testTransaction {
@@ -50,6 +51,11 @@ func (t *testTransaction) GetTarget() string {
 	return t.Called().Get(0).(string)
 }
 
+// GetPriority is not mocked, the priority is set on the transaction
+func (t *testTransaction) GetPriority() TransactionPriority {
+	return t.priority
+}
+
 // Compile-time checking to ensure that MockedForwarder implements Forwarder
 var _ Forwarder = &MockedForwarder{}
 
@@ -113,6 +119,16 @@ func (tf *MockedForwarder) SubmitMetadata(payload Payloads, extra http.Header) e
 	return tf.Called(payload, extra).Error(0)
 }
 
+// SubmitSBOM updates the internal mock struct
+func (tf *MockedForwarder) SubmitSBOM(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
+// SubmitNetworkPath updates the internal mock struct
+func (tf *MockedForwarder) SubmitNetworkPath(payload Payloads, extra http.Header) error {
+	return tf.Called(payload, extra).Error(0)
+}
+
 // SubmitProcessChecks mock
 func (tf *MockedForwarder) SubmitProcessChecks(payload Payloads, extra http.Header) (chan Response, error) {
 	return nil, tf.Called(payload, extra).Error(0)
@@ -142,3 +158,13 @@ func (tf *MockedForwarder) SubmitConnectionChecks(payload Payloads, extra http.H
 func (tf *MockedForwarder) SubmitPodChecks(payload Payloads, extra http.Header) (chan Response, error) {
 	return nil, tf.Called(payload, extra).Error(0)
 }
+
+// SubmitDeploymentChecks mock
+func (tf *MockedForwarder) SubmitDeploymentChecks(payload Payloads, extra http.Header) (chan Response, error) {
+	return nil, tf.Called(payload, extra).Error(0)
+}
+
+// SubmitProcessDiscoveryChecks mock
+func (tf *MockedForwarder) SubmitProcessDiscoveryChecks(payload Payloads, extra http.Header) (chan Response, error) {
+	return nil, tf.Called(payload, extra).Error(0)
+}
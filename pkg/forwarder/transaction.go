@@ -38,6 +38,7 @@ var (
 	transactionsSentRequestErrors  = expvar.Int{}
 	transactionsHTTPErrors         = expvar.Int{}
 	transactionsHTTPErrorsByCode   = expvar.Map{}
+	transactionsDroppedByPriority  = expvar.Map{}
 
 	tlmConnectEvents = telemetry.NewCounter("forwarder", "connection_events",
 		[]string{"connection_event_type"}, "Count of new connection events grouped by type of event")
@@ -51,6 +52,8 @@ var (
 		[]string{"domain", "error_type"}, "Count of transactions errored grouped by type of error")
 	tlmTxHTTPErrors = telemetry.NewCounter("transactions", "http_errors",
 		[]string{"domain", "code"}, "Count of transactions http errors per http code")
+	tlmTxDroppedByPriority = telemetry.NewCounter("transactions", "dropped_by_priority",
+		[]string{"domain", "priority"}, "Count of transactions dropped from the retry queue per priority")
 )
 
 var trace = &httptrace.ClientTrace{
@@ -107,6 +110,7 @@ var defaultCompletionHandler = func(transaction *HTTPTransaction, statusCode int
 func initTransactionExpvars() {
 	transactionsErrorsByType.Init()
 	transactionsHTTPErrorsByCode.Init()
+	transactionsDroppedByPriority.Init()
 	transactionsExpvars.Set("RetryQueueSize", &transactionsRetryQueueSize)
 	transactionsExpvars.Set("Success", &transactionsSuccessful)
 	transactionsExpvars.Set("DroppedOnInput", &transactionsDroppedOnInput)
@@ -114,6 +118,7 @@ func initTransactionExpvars() {
 	transactionsExpvars.Set("HTTPErrorsByCode", &transactionsHTTPErrorsByCode)
 	transactionsExpvars.Set("Errors", &transactionsErrors)
 	transactionsExpvars.Set("ErrorsByType", &transactionsErrorsByType)
+	transactionsExpvars.Set("DroppedByPriority", &transactionsDroppedByPriority)
 	connectionEvents.Set("DNSSuccess", &connectionDNSSuccess)
 	connectionEvents.Set("ConnectSuccess", &connectionConnectSuccess)
 	transactionsErrorsByType.Set("DNSErrors", &transactionsDNSErrors)
@@ -123,6 +128,55 @@ func initTransactionExpvars() {
 	transactionsErrorsByType.Set("SentRequestErrors", &transactionsSentRequestErrors)
 }
 
+// TransactionsStats summarizes the delivery of the transactions sent by the forwarders of the process
+type TransactionsStats struct {
+	Success        int64
+	Errors         int64
+	Dropped        int64
+	DroppedOnInput int64
+	RetryQueueSize int64
+}
+
+// Delivered returns whether every transaction was delivered: none was dropped or is waiting to be retried
+func (s TransactionsStats) Delivered() bool {
+	return s.Dropped == 0 && s.DroppedOnInput == 0 && s.RetryQueueSize == 0
+}
+
+// GetTransactionsStats returns the delivery stats of the transactions since the start of the process
+func GetTransactionsStats() TransactionsStats {
+	return TransactionsStats{
+		Success:        transactionsSuccessful.Value(),
+		Errors:         transactionsErrors.Value(),
+		Dropped:        transactionsDropped.Value(),
+		DroppedOnInput: transactionsDroppedOnInput.Value(),
+		RetryQueueSize: transactionsRetryQueueSize.Value(),
+	}
+}
+
+// TransactionPriority is the priority of a transaction in the retry queue: when it is full, the
+// transactions with the lowest priority are dropped first, the oldest ones first.
+type TransactionPriority int
+
+const (
+	// TransactionPriorityLow is the priority of the series, the most voluminous payloads
+	TransactionPriorityLow TransactionPriority = iota
+	// TransactionPriorityNormal is the default priority of the transactions
+	TransactionPriorityNormal
+	// TransactionPriorityHigh is the priority of the service checks, metadata and sketches
+	TransactionPriorityHigh
+)
+
+func (p TransactionPriority) String() string {
+	switch p {
+	case TransactionPriorityLow:
+		return "low"
+	case TransactionPriorityHigh:
+		return "high"
+	default:
+		return "normal"
+	}
+}
+
 // HTTPTransaction represents one Payload for one Endpoint on one Domain.
 type HTTPTransaction struct {
 	// Domain represents the domain target by the HTTPTransaction.
@@ -135,6 +189,8 @@ type HTTPTransaction struct {
 	Payload *[]byte
 	// ErrorCount is the number of times this HTTPTransaction failed to be processed.
 	ErrorCount int
+	// Priority is the priority of the HTTPTransaction in the retry queue.
+	Priority TransactionPriority
 
 	createdAt time.Time
 	// retryable indicates whether this transaction can be retried
@@ -151,6 +207,7 @@ type Transaction interface {
 	Process(ctx context.Context, client *http.Client) error
 	GetCreatedAt() time.Time
 	GetTarget() string
+	GetPriority() TransactionPriority
 }
 
 // NewHTTPTransaction returns a new HTTPTransaction.
@@ -158,6 +215,7 @@ func NewHTTPTransaction() *HTTPTransaction {
 	return &HTTPTransaction{
 		createdAt:         time.Now(),
 		ErrorCount:        0,
+		Priority:          TransactionPriorityNormal,
 		retryable:         true,
 		Headers:           make(http.Header),
 		attemptHandler:    defaultAttemptHandler,
@@ -170,6 +228,11 @@ func (t *HTTPTransaction) GetCreatedAt() time.Time {
 	return t.createdAt
 }
 
+// GetPriority returns the priority of the HTTPTransaction in the retry queue.
+func (t *HTTPTransaction) GetPriority() TransactionPriority {
+	return t.Priority
+}
+
 // GetTarget return the url used by the transaction
 func (t *HTTPTransaction) GetTarget() string {
 	url := t.Domain + t.Endpoint
@@ -222,6 +285,10 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, client *http.Clie
 		t.ErrorCount++
 		transactionsErrors.Add(1)
 		tlmTxErrors.Inc(t.Domain, "cant_send")
+		if class := classifySendError(err); class != "" {
+			hint := recordErrorClass(t.Domain, class)
+			return 0, nil, fmt.Errorf("error while sending transaction, rescheduling it: %s (%s: %s)", httputils.SanitizeURL(err.Error()), class, hint)
+		}
 		return 0, nil, fmt.Errorf("error while sending transaction, rescheduling it: %s", httputils.SanitizeURL(err.Error()))
 	}
 	defer func() { _ = resp.Body.Close() }()
@@ -246,13 +313,24 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, client *http.Clie
 		tlmTxHTTPErrors.Inc(t.Domain, statusCode)
 	}
 
+	var class, hint string
+	if resp.StatusCode >= 400 {
+		if class = classifyResponseError(resp); class != "" {
+			hint = recordErrorClass(t.Domain, class)
+		}
+	}
+
 	if resp.StatusCode == 400 || resp.StatusCode == 404 || resp.StatusCode == 413 {
-		log.Errorf("Error code %q received while sending transaction to %q: %s, dropping it", resp.Status, logURL, string(body))
+		if class != "" {
+			log.Errorf("Error code %q received while sending transaction to %q, dropping it: %s: %s", resp.Status, logURL, class, hint)
+		} else {
+			log.Errorf("Error code %q received while sending transaction to %q: %s, dropping it", resp.Status, logURL, string(body))
+		}
 		transactionsDropped.Add(1)
 		tlmTxDropped.Inc(t.Domain)
 		return resp.StatusCode, body, nil
 	} else if resp.StatusCode == 403 {
-		log.Errorf("API Key invalid, dropping transaction for %s", logURL)
+		log.Errorf("Error code %q received while sending transaction to %q, dropping it: %s: %s", resp.Status, logURL, class, hint)
 		transactionsDropped.Add(1)
 		tlmTxDropped.Inc(t.Domain)
 		return resp.StatusCode, body, nil
@@ -260,6 +338,9 @@ func (t *HTTPTransaction) internalProcess(ctx context.Context, client *http.Clie
 		t.ErrorCount++
 		transactionsErrors.Add(1)
 		tlmTxErrors.Inc(t.Domain, "gt_400")
+		if class != "" {
+			return resp.StatusCode, body, fmt.Errorf("error %q while sending transaction to %q, rescheduling it: %s: %s", resp.Status, logURL, class, hint)
+		}
 		return resp.StatusCode, body, fmt.Errorf("error %q while sending transaction to %q, rescheduling it", resp.Status, logURL)
 	}
 
@@ -0,0 +1,126 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package forwarder
+
+import (
+	"crypto/x509"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+)
+
+// The classes of the transaction errors having a known cause and remediation
+const (
+	errorClassInvalidAPIKey       = "invalid_api_key"
+	errorClassClockSkew           = "clock_skew"
+	errorClassPayloadTooLarge     = "payload_too_large"
+	errorClassProxyConnectFailure = "proxy_connect_failure"
+	errorClassTLSInterception     = "tls_interception"
+)
+
+// maxClockSkew is the difference between the local clock and the date of the intake
+// responses above which a rejected transaction is attributed to the clock skew
+const maxClockSkew = 10 * time.Minute
+
+// errorClassHints are the remediation hints of the error classes, shown on the status page
+// and logged with the errors
+var errorClassHints = map[string]string{
+	errorClassInvalidAPIKey:       "the API key is invalid or was revoked, check the api_key and the site settings",
+	errorClassClockSkew:           "the system clock is out of sync with the Datadog intake, synchronize it with NTP",
+	errorClassPayloadTooLarge:     "the payload exceeds the intake size limit, lower the serializer_max_payload_size setting",
+	errorClassProxyConnectFailure: "the proxy refused or failed to open the connection, check the proxy settings and its credentials",
+	errorClassTLSInterception:     "the intake certificate is not trusted, a proxy is probably intercepting TLS; trust its CA or bypass it for Datadog",
+}
+
+var (
+	transactionsErrorsByClass = expvar.Map{}
+	transactionsErrorHints    = expvar.Map{}
+	errorClassMutex           sync.Mutex
+
+	tlmTxByError = telemetry.NewCounter("forwarder", "transactions_by_error",
+		[]string{"domain", "error"}, "Count of transactions errors grouped by their classification")
+)
+
+func initTransactionErrorsExpvars() {
+	transactionsErrorsByClass.Init()
+	transactionsErrorHints.Init()
+	transactionsExpvars.Set("ErrorsByClass", &transactionsErrorsByClass)
+	transactionsExpvars.Set("ErrorHints", &transactionsErrorHints)
+}
+
+// classifyResponseError returns the class of an error response of the intake, or an empty
+// string when it has no known cause
+func classifyResponseError(resp *http.Response) string {
+	switch resp.StatusCode {
+	case http.StatusForbidden:
+		if isClockSkewed(resp.Header.Get("Date")) {
+			return errorClassClockSkew
+		}
+		return errorClassInvalidAPIKey
+	case http.StatusRequestEntityTooLarge:
+		return errorClassPayloadTooLarge
+	case http.StatusProxyAuthRequired:
+		return errorClassProxyConnectFailure
+	}
+	return ""
+}
+
+// classifySendError returns the class of an error returned by the HTTP client, or an empty
+// string when it has no known cause
+func classifySendError(err error) string {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &hostname) {
+		return errorClassTLSInterception
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+		return errorClassProxyConnectFailure
+	}
+	// the transport reports the status of a refused CONNECT request as the error message
+	if strings.Contains(err.Error(), "Proxy Authentication Required") {
+		return errorClassProxyConnectFailure
+	}
+	return ""
+}
+
+// isClockSkewed returns whether the date of a response differs from the local clock by more than maxClockSkew
+func isClockSkewed(date string) bool {
+	if date == "" {
+		return false
+	}
+	serverTime, err := http.ParseTime(date)
+	if err != nil {
+		return false
+	}
+	skew := time.Since(serverTime)
+	return skew > maxClockSkew || skew < -maxClockSkew
+}
+
+// recordErrorClass counts an error of a given class and returns its remediation hint
+func recordErrorClass(domain, class string) string {
+	hint := errorClassHints[class]
+	errorClassMutex.Lock()
+	defer errorClassMutex.Unlock()
+	if count, ok := transactionsErrorsByClass.Get(class).(*expvar.Int); ok {
+		count.Add(1)
+	} else {
+		count = &expvar.Int{}
+		count.Add(1)
+		transactionsErrorsByClass.Set(class, count)
+		h := &expvar.String{}
+		h.Set(hint)
+		transactionsErrorHints.Set(class, h)
+	}
+	tlmTxByError.Inc(domain, class)
+	return hint
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package forwarder
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyResponseError(t *testing.T) {
+	skewedDate := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+	currentDate := time.Now().UTC().Format(http.TimeFormat)
+
+	for _, tc := range []struct {
+		code  int
+		date  string
+		class string
+	}{
+		{http.StatusForbidden, "", errorClassInvalidAPIKey},
+		{http.StatusForbidden, currentDate, errorClassInvalidAPIKey},
+		{http.StatusForbidden, skewedDate, errorClassClockSkew},
+		{http.StatusForbidden, "not a date", errorClassInvalidAPIKey},
+		{http.StatusRequestEntityTooLarge, "", errorClassPayloadTooLarge},
+		{http.StatusProxyAuthRequired, "", errorClassProxyConnectFailure},
+		{http.StatusBadRequest, "", ""},
+		{http.StatusServiceUnavailable, skewedDate, ""},
+	} {
+		resp := &http.Response{StatusCode: tc.code, Header: http.Header{}}
+		if tc.date != "" {
+			resp.Header.Set("Date", tc.date)
+		}
+		assert.Equal(t, tc.class, classifyResponseError(resp), "code %d, date %q", tc.code, tc.date)
+	}
+}
+
+func TestClassifySendError(t *testing.T) {
+	urlError := func(err error) error {
+		return &url.Error{Op: "Post", URL: "https://app.datadoghq.com", Err: err}
+	}
+
+	assert.Equal(t, errorClassTLSInterception, classifySendError(urlError(x509.UnknownAuthorityError{})))
+	assert.Equal(t, errorClassTLSInterception, classifySendError(urlError(x509.HostnameError{Host: "app.datadoghq.com"})))
+	assert.Equal(t, errorClassProxyConnectFailure, classifySendError(urlError(&net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")})))
+	assert.Equal(t, errorClassProxyConnectFailure, classifySendError(urlError(errors.New("Proxy Authentication Required"))))
+	assert.Equal(t, "", classifySendError(urlError(&net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")})))
+}
+
+func TestProcessClassifiedError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer ts.Close()
+
+	before := int64(0)
+	if count, ok := transactionsErrorsByClass.Get(errorClassPayloadTooLarge).(*expvar.Int); ok {
+		before = count.Value()
+	}
+
+	transaction := NewHTTPTransaction()
+	transaction.Domain = ts.URL
+	transaction.Endpoint = "/endpoint/test"
+	payload := []byte("test payload")
+	transaction.Payload = &payload
+
+	err := transaction.Process(context.Background(), &http.Client{})
+	assert.Nil(t, err)
+
+	count, ok := transactionsErrorsByClass.Get(errorClassPayloadTooLarge).(*expvar.Int)
+	if assert.True(t, ok) {
+		assert.Equal(t, before+1, count.Value())
+	}
+	assert.Equal(t, errorClassHints[errorClassPayloadTooLarge], transactionsErrorHints.Get(errorClassPayloadTooLarge).(*expvar.String).Value())
+}
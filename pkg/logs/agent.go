@@ -16,6 +16,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/container"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/file"
 	"github.com/DataDog/datadog-agent/pkg/logs/input/journald"
@@ -34,11 +35,12 @@ import (
 // |                                                        |
 // + ------------------------------------------------------ +
 type Agent struct {
-	auditor          *auditor.Auditor
-	destinationsCtx  *client.DestinationsContext
-	pipelineProvider pipeline.Provider
-	inputs           []restart.Restartable
-	health           *health.Handle
+	auditor                   *auditor.Auditor
+	destinationsCtx           *client.DestinationsContext
+	pipelineProvider          pipeline.Provider
+	inputs                    []restart.Restartable
+	health                    *health.Handle
+	diagnosticMessageReceiver *diagnostic.BufferedMessageReceiver
 }
 
 // NewAgent returns a new Logs Agent
@@ -50,9 +52,10 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 	// critical part. Arguably it could also be plugged to the destination.
 	auditor := auditor.New(coreConfig.Datadog.GetString("logs_config.run_path"), health)
 	destinationsCtx := client.NewDestinationsContext()
+	diagnosticMessageReceiver := diagnostic.NewBufferedMessageReceiver()
 
 	// setup the pipeline provider that provides pairs of processor and sender
-	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, processingRules, endpoints, destinationsCtx)
+	pipelineProvider := pipeline.NewProvider(config.NumberOfPipelines, auditor, processingRules, endpoints, destinationsCtx, sources, config.BuildDiskBufferConfig(), diagnosticMessageReceiver)
 
 	// setup the inputs
 	inputs := []restart.Restartable{
@@ -60,19 +63,21 @@ func NewAgent(sources *config.LogSources, services *service.Services, processing
 		container.NewLauncher(
 			coreConfig.Datadog.GetBool("logs_config.container_collect_all"),
 			coreConfig.Datadog.GetBool("logs_config.k8s_container_use_file"),
+			coreConfig.Datadog.GetBool("logs_config.k8s_container_use_api"),
 			time.Duration(coreConfig.Datadog.GetInt("logs_config.docker_client_read_timeout"))*time.Second,
 			sources, services, pipelineProvider, auditor),
 		listener.NewLauncher(sources, coreConfig.Datadog.GetInt("logs_config.frame_size"), pipelineProvider),
 		journald.NewLauncher(sources, pipelineProvider, auditor),
-		windowsevent.NewLauncher(sources, pipelineProvider),
+		windowsevent.NewLauncher(sources, pipelineProvider, auditor),
 	}
 
 	return &Agent{
-		auditor:          auditor,
-		destinationsCtx:  destinationsCtx,
-		pipelineProvider: pipelineProvider,
-		inputs:           inputs,
-		health:           health,
+		auditor:                   auditor,
+		destinationsCtx:           destinationsCtx,
+		pipelineProvider:          pipelineProvider,
+		inputs:                    inputs,
+		health:                    health,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
 	}
 }
 
@@ -4,18 +4,22 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"io/ioutil"
+	"math/rand"
 	"net/http"
 	"sync"
 	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/status"
 )
 
 // ContentType options,
@@ -33,15 +37,33 @@ var (
 // emptyPayload is an empty payload used to check HTTP connectivity without sending logs.
 var emptyPayload []byte
 
+const (
+	// backoffBase is the delay applied after the first failed attempt
+	backoffBase = 1 * time.Second
+	// backoffMax caps the delay between two attempts
+	backoffMax = 2 * time.Minute
+	// warningPeriod is the number of payloads dropped between two warnings
+	warningPeriod = 1000
+	// droppedPayloadsWarningType is the key of the status warning of an additional destination dropping payloads
+	droppedPayloadsWarningType = "additional_destination_dropped_payloads"
+)
+
 // Destination sends a payload over HTTP.
 type Destination struct {
+	host                string
 	url                 string
+	apiKey              string
 	contentType         string
 	contentEncoding     ContentEncoding
 	client              *httputils.ResetClient
 	destinationsContext *client.DestinationsContext
 	once                sync.Once
 	payloadChan         chan []byte
+
+	// backoff state, shared by the payloads sent concurrently
+	backoffMutex sync.Mutex
+	nbErrors     int
+	blockedUntil time.Time
 }
 
 // NewDestination returns a new Destination.
@@ -51,8 +73,15 @@ func NewDestination(endpoint config.Endpoint, contentType string, destinationsCo
 }
 
 func newDestination(endpoint config.Endpoint, contentType string, destinationsContext *client.DestinationsContext, timeout time.Duration) *Destination {
+	var apiKey string
+	if endpoint.TrackType != "" {
+		// the event platform intake expects the api key in a header instead of the url
+		apiKey = endpoint.APIKey
+	}
 	return &Destination{
+		host:                endpoint.Host,
 		url:                 buildURL(endpoint),
+		apiKey:              apiKey,
 		contentType:         contentType,
 		contentEncoding:     buildContentEncoding(endpoint),
 		client:              httputils.NewResetClient(endpoint.ConnectionResetInterval, httpClientFactory(timeout)),
@@ -65,6 +94,16 @@ func newDestination(endpoint config.Endpoint, contentType string, destinationsCo
 func (d *Destination) Send(payload []byte) error {
 	ctx := d.destinationsContext.Context()
 
+	if err := d.waitBackoff(ctx); err != nil {
+		return err
+	}
+
+	err := d.send(ctx, payload)
+	d.updateBackoff(err)
+	return err
+}
+
+func (d *Destination) send(ctx context.Context, payload []byte) error {
 	encodedPayload, err := d.contentEncoding.encode(payload)
 	if err != nil {
 		return err
@@ -80,6 +119,9 @@ func (d *Destination) Send(payload []byte) error {
 	}
 	req.Header.Set("Content-Type", d.contentType)
 	req.Header.Set("Content-Encoding", d.contentEncoding.name())
+	if d.apiKey != "" {
+		req.Header.Set("DD-API-KEY", d.apiKey)
+	}
 	req = req.WithContext(ctx)
 
 	resp, err := d.client.Do(req)
@@ -99,9 +141,9 @@ func (d *Destination) Send(payload []byte) error {
 		return err
 	}
 
-	if resp.StatusCode >= 500 {
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusRequestTimeout {
 		// the server could not serve the request,
-		// most likely because of an internal error
+		// most likely because of an internal error or because it is overloaded
 		return client.NewRetryableError(errServer)
 	} else if resp.StatusCode >= 400 {
 		// the logs-agent is likely to be misconfigured,
@@ -112,14 +154,79 @@ func (d *Destination) Send(payload []byte) error {
 	}
 }
 
-// SendAsync sends a payload in background.
+// waitBackoff blocks until the delay following the last failed attempts has elapsed,
+// returns an error if the destination is stopped in the meantime.
+func (d *Destination) waitBackoff(ctx context.Context) error {
+	d.backoffMutex.Lock()
+	delay := time.Until(d.blockedUntil)
+	d.backoffMutex.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// updateBackoff increases the delay before the next attempt exponentially when
+// the intake asks to retry, and resets it when a payload is successfully sent.
+func (d *Destination) updateBackoff(err error) {
+	d.backoffMutex.Lock()
+	defer d.backoffMutex.Unlock()
+	if _, ok := err.(*client.RetryableError); !ok {
+		if err == nil {
+			d.nbErrors = 0
+			d.blockedUntil = time.Time{}
+		}
+		return
+	}
+	d.nbErrors++
+	d.blockedUntil = time.Now().Add(backoffDelay(d.nbErrors))
+}
+
+// backoffDelay returns the delay to wait after nbErrors consecutive failures,
+// a random jitter avoids retrying at the same time as the other agents.
+func backoffDelay(nbErrors int) time.Duration {
+	if nbErrors <= 0 {
+		return 0
+	}
+	delay := backoffMax
+	if nbErrors < 8 {
+		delay = backoffBase << uint(nbErrors-1)
+		if delay > backoffMax {
+			delay = backoffMax
+		}
+	}
+	// pick a delay in [delay/2, delay]
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+// SendAsync sends a payload in background without blocking. If the channel is full,
+// because the destination is unreachable or backing off, the incoming payloads are dropped.
 func (d *Destination) SendAsync(payload []byte) {
 	d.once.Do(func() {
 		payloadChan := make(chan []byte, config.ChanSize)
+		metrics.DestinationPayloadsDropped.Set(d.host, &expvar.Int{})
 		d.sendInBackground(payloadChan)
 		d.payloadChan = payloadChan
 	})
-	d.payloadChan <- payload
+
+	select {
+	case d.payloadChan <- payload:
+	default:
+		// a payload holds a batch of logs, the drops are counted by payload
+		if metrics.DestinationPayloadsDropped.Get(d.host).(*expvar.Int).Value()%warningPeriod == 0 {
+			log.Warnf("Some logs sent to additional destination %v were dropped", d.host)
+			status.AddGlobalWarning(droppedPayloadsWarningType+":"+d.host, fmt.Sprintf("Some logs sent to the additional destination %v were dropped because it could not keep up.", d.host))
+		}
+		metrics.DestinationPayloadsDropped.Add(d.host, 1)
+		metrics.TlmPayloadsDropped.Inc(d.host)
+	}
 }
 
 // sendInBackground sends all payloads from payloadChan in background.
@@ -142,7 +249,7 @@ func httpClientFactory(timeout time.Duration) func() *http.Client {
 		return &http.Client{
 			Timeout: timeout,
 			// reusing core agent HTTP transport to benefit from proxy settings.
-			Transport: httputils.CreateHTTPTransport(),
+			Transport: httputils.CreateHTTPTransportForIntake(coreConfig.ProxyIntakeLogs),
 		}
 	}
 }
@@ -161,6 +268,9 @@ func buildURL(endpoint config.Endpoint) string {
 	} else {
 		address = endpoint.Host
 	}
+	if endpoint.TrackType != "" {
+		return fmt.Sprintf("%v://%v/api/v2/%v", scheme, address, endpoint.TrackType)
+	}
 	return fmt.Sprintf("%v://%v/v1/input/%v", scheme, address, endpoint.APIKey)
 }
 
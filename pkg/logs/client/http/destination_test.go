@@ -6,16 +6,20 @@
 package http
 
 import (
+	"expvar"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/logs/status"
 )
 
 type HTTPServerTest struct {
@@ -81,6 +85,16 @@ func TestBuildURLShouldReturnAddressWithPortWhenDefined(t *testing.T) {
 	assert.Equal(t, "http://foo:1234/v1/input/bar", url)
 }
 
+func TestBuildURLShouldReturnTrackURLWithTrackType(t *testing.T) {
+	url := buildURL(config.Endpoint{
+		APIKey:    "bar",
+		Host:      "foo",
+		UseSSL:    true,
+		TrackType: "ndm",
+	})
+	assert.Equal(t, "https://foo/api/v2/ndm", url)
+}
+
 func TestDestinationSend200(t *testing.T) {
 	server := NewHTTPServerTest(200)
 	err := server.destination.Send([]byte("yo"))
@@ -98,6 +112,92 @@ func TestDestinationSend500(t *testing.T) {
 	server.stop()
 }
 
+func TestDestinationSend429(t *testing.T) {
+	server := NewHTTPServerTest(429)
+	err := server.destination.Send([]byte("yo"))
+	assert.NotNil(t, err)
+	_, ok := err.(*client.RetryableError)
+	assert.True(t, ok)
+	server.stop()
+}
+
+func TestDestinationBackoffOnRetryableError(t *testing.T) {
+	server := NewHTTPServerTest(500)
+	server.destination.Send([]byte("yo")) //nolint:errcheck
+	assert.Equal(t, 1, server.destination.nbErrors)
+	assert.True(t, server.destination.blockedUntil.After(time.Now()))
+	server.stop()
+
+	// a successful send resets the backoff
+	server = NewHTTPServerTest(200)
+	server.destination.nbErrors = 3
+	err := server.destination.Send([]byte("yo"))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, server.destination.nbErrors)
+	assert.True(t, server.destination.blockedUntil.IsZero())
+	server.stop()
+}
+
+func TestDestinationWaitBackoffStopsWithContext(t *testing.T) {
+	server := NewHTTPServerTest(200)
+	server.destination.blockedUntil = time.Now().Add(time.Hour)
+	server.destCtx.Stop()
+	err := server.destination.Send([]byte("yo"))
+	assert.NotNil(t, err)
+	server.httpServer.Close()
+}
+
+func TestBackoffDelay(t *testing.T) {
+	assert.Equal(t, time.Duration(0), backoffDelay(0))
+	for nbErrors := 1; nbErrors < 20; nbErrors++ {
+		delay := backoffDelay(nbErrors)
+		assert.True(t, delay >= backoffBase/2)
+		assert.True(t, delay <= backoffMax)
+	}
+	assert.True(t, backoffDelay(20) >= backoffMax/2)
+}
+
+func TestDestinationSendAsyncDropsWhenTheEndpointHangs(t *testing.T) {
+	status.InitStatus(config.NewLogSources())
+	defer status.Clear()
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	url := strings.Split(ts.URL, ":")
+	port, _ := strconv.Atoi(url[2])
+	destCtx := client.NewDestinationsContext()
+	destCtx.Start()
+	defer destCtx.Stop()
+	endpoint := config.Endpoint{
+		APIKey: "test",
+		Host:   strings.Replace(url[1], "/", "", -1),
+		Port:   port,
+	}
+	dest := NewDestination(endpoint, JSONContentType, destCtx)
+
+	// the first payload hangs, the channel fills up and the following payloads are dropped
+	// without blocking the caller
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < config.ChanSize+10; i++ {
+			dest.SendAsync([]byte("yo"))
+		}
+		close(sent)
+	}()
+	select {
+	case <-sent:
+	case <-time.After(5 * time.Second):
+		assert.FailNow(t, "SendAsync should not block when the destination hangs")
+	}
+	dropped := metrics.DestinationPayloadsDropped.Get(endpoint.Host).(*expvar.Int).Value()
+	assert.True(t, dropped >= 9 && dropped <= 10, "unexpected number of dropped payloads: %d", dropped)
+	assert.Len(t, status.Get().Warnings, 1)
+}
+
 func TestDestinationSend400(t *testing.T) {
 	server := NewHTTPServerTest(400)
 	err := server.destination.Send([]byte("yo"))
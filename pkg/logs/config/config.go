@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
+	"path/filepath"
 	"strconv"
 	"time"
 
@@ -93,7 +94,7 @@ func BuildEndpoints(httpConnectivity HTTPConnectivity) (*Endpoints, error) {
 	if coreConfig.Datadog.GetBool("logs_config.dev_mode_no_ssl") {
 		log.Warnf("Use of illegal configuration parameter, if you need to send your logs to a proxy, please use 'logs_config.logs_dd_url' and 'logs_config.logs_no_ssl' instead")
 	}
-	if isForceHTTPUse() || (bool(httpConnectivity) && !(isForceTCPUse() || isSocks5ProxySet() || hasAdditionalEndpoints())) {
+	if isForceHTTPUse() || (bool(httpConnectivity) && !(isForceTCPUse() || isSocks5ProxySet())) {
 		return BuildHTTPEndpoints()
 	}
 	log.Warn("You are currently sending Logs to Datadog through TCP (either because logs_config.use_tcp or logs_config.socks5_proxy_address is set or the HTTP connectivity test has failed) " +
@@ -114,10 +115,6 @@ func isForceHTTPUse() bool {
 	return coreConfig.Datadog.GetBool("logs_config.use_http")
 }
 
-func hasAdditionalEndpoints() bool {
-	return len(getAdditionalEndpoints()) > 0
-}
-
 func buildTCPEndpoints() (*Endpoints, error) {
 	useProto := coreConfig.Datadog.GetBool("logs_config.dev_mode_use_proto")
 	proxyAddress := coreConfig.Datadog.GetString("logs_config.socks5_proxy_address")
@@ -153,7 +150,7 @@ func buildTCPEndpoints() (*Endpoints, error) {
 		main.UseSSL = !coreConfig.Datadog.GetBool("logs_config.dev_mode_no_ssl")
 	}
 
-	additionals := getAdditionalEndpoints()
+	additionals := getAdditionalEndpoints("logs_config.additional_endpoints")
 	for i := 0; i < len(additionals); i++ {
 		additionals[i].UseSSL = main.UseSSL
 		additionals[i].ProxyAddress = proxyAddress
@@ -185,31 +182,97 @@ func BuildHTTPEndpoints() (*Endpoints, error) {
 		main.UseSSL = !coreConfig.Datadog.GetBool("logs_config.dev_mode_no_ssl")
 	}
 
-	additionals := getAdditionalEndpoints()
+	additionals := getAdditionalEndpoints("logs_config.additional_endpoints")
 	for i := 0; i < len(additionals); i++ {
 		additionals[i].UseSSL = main.UseSSL
 		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
 	}
 
 	batchWait := batchWait(coreConfig.Datadog)
+	batchMaxConcurrentSend := batchMaxConcurrentSend(coreConfig.Datadog)
+	batchMaxSize := batchMaxSize(coreConfig.Datadog)
+	batchMaxContentSize := batchMaxContentSize(coreConfig.Datadog)
 
-	return NewEndpoints(main, additionals, false, true, batchWait), nil
+	return NewEndpointsWithBatchSettings(main, additionals, false, true, batchWait, batchMaxConcurrentSend, batchMaxSize, batchMaxContentSize), nil
 }
 
-func getAdditionalEndpoints() []Endpoint {
+// BuildHTTPEndpointsWithConfig returns the HTTP endpoints of an intake track of the event platform.
+// They are configured with the same keys as the logs endpoints, under configPrefix
+// (e.g. "network_devices.metadata."), the keys not set taking the given defaults.
+func BuildHTTPEndpointsWithConfig(configPrefix string, endpointPrefix string, trackType IntakeTrackType, defaultBatchMaxConcurrentSend int, defaultBatchMaxSize int, defaultBatchMaxContentSize int) (*Endpoints, error) {
+	main := Endpoint{
+		APIKey:                  coreConfig.Datadog.GetString("api_key"),
+		UseCompression:          true,
+		CompressionLevel:        coreConfig.Datadog.GetInt("logs_config.compression_level"),
+		ConnectionResetInterval: time.Duration(coreConfig.Datadog.GetInt(configPrefix+"connection_reset_interval")) * time.Second,
+		TrackType:               trackType,
+	}
+	if isSetAndNotEmpty(coreConfig.Datadog, configPrefix+"api_key") {
+		main.APIKey = coreConfig.Datadog.GetString(configPrefix + "api_key")
+	}
+	main.APIKey = coreConfig.SanitizeAPIKey(main.APIKey)
+	if coreConfig.Datadog.IsSet(configPrefix + "use_compression") {
+		main.UseCompression = coreConfig.Datadog.GetBool(configPrefix + "use_compression")
+	}
+	if coreConfig.Datadog.IsSet(configPrefix + "compression_level") {
+		main.CompressionLevel = coreConfig.Datadog.GetInt(configPrefix + "compression_level")
+	}
+
+	switch {
+	case isSetAndNotEmpty(coreConfig.Datadog, configPrefix+"logs_dd_url"):
+		host, port, err := parseAddress(coreConfig.Datadog.GetString(configPrefix + "logs_dd_url"))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %slogs_dd_url: %v", configPrefix, err)
+		}
+		main.Host = host
+		main.Port = port
+		main.UseSSL = !coreConfig.Datadog.GetBool(configPrefix + "logs_no_ssl")
+	default:
+		main.Host = coreConfig.GetMainEndpoint(endpointPrefix, configPrefix+"dd_url")
+		main.UseSSL = !coreConfig.Datadog.GetBool("logs_config.dev_mode_no_ssl")
+	}
+
+	additionals := getAdditionalEndpoints(configPrefix + "additional_endpoints")
+	for i := 0; i < len(additionals); i++ {
+		additionals[i].UseSSL = main.UseSSL
+		additionals[i].APIKey = coreConfig.SanitizeAPIKey(additionals[i].APIKey)
+		additionals[i].TrackType = trackType
+	}
+
+	batchWait := coreConfig.DefaultBatchWait * time.Second
+	if coreConfig.Datadog.IsSet(configPrefix + "batch_wait") {
+		batchWait = batchWaitWithKey(configPrefix + "batch_wait")
+	}
+	batchMaxConcurrentSend := defaultBatchMaxConcurrentSend
+	if coreConfig.Datadog.IsSet(configPrefix + "batch_max_concurrent_send") {
+		batchMaxConcurrentSend = positiveIntWithKey(configPrefix+"batch_max_concurrent_send", defaultBatchMaxConcurrentSend)
+	}
+	batchMaxSize := defaultBatchMaxSize
+	if coreConfig.Datadog.IsSet(configPrefix + "batch_max_size") {
+		batchMaxSize = positiveIntWithKey(configPrefix+"batch_max_size", defaultBatchMaxSize)
+	}
+	batchMaxContentSize := defaultBatchMaxContentSize
+	if coreConfig.Datadog.IsSet(configPrefix + "batch_max_content_size") {
+		batchMaxContentSize = positiveIntWithKey(configPrefix+"batch_max_content_size", defaultBatchMaxContentSize)
+	}
+
+	return NewEndpointsWithBatchSettings(main, additionals, false, true, batchWait, batchMaxConcurrentSend, batchMaxSize, batchMaxContentSize), nil
+}
+
+func getAdditionalEndpoints(key string) []Endpoint {
 	var endpoints []Endpoint
 	var err error
-	raw := coreConfig.Datadog.Get("logs_config.additional_endpoints")
+	raw := coreConfig.Datadog.Get(key)
 	if raw == nil {
 		return endpoints
 	}
 	if s, ok := raw.(string); ok && s != "" {
 		err = json.Unmarshal([]byte(s), &endpoints)
 	} else {
-		err = coreConfig.Datadog.UnmarshalKey("logs_config.additional_endpoints", &endpoints)
+		err = coreConfig.Datadog.UnmarshalKey(key, &endpoints)
 	}
 	if err != nil {
-		log.Warnf("Could not parse additional_endpoints for logs: %v", err)
+		log.Warnf("Could not parse %s: %v", key, err)
 	}
 	return endpoints
 }
@@ -248,7 +311,86 @@ func batchWait(config coreConfig.Config) time.Duration {
 	return (time.Duration(batchWait) * time.Second)
 }
 
+func batchMaxConcurrentSend(config coreConfig.Config) int {
+	batchMaxConcurrentSend := coreConfig.Datadog.GetInt("logs_config.batch_max_concurrent_send")
+	if batchMaxConcurrentSend < 1 {
+		log.Warnf("Invalid batch_max_concurrent_send: %v should be >= 1, fallback on %v", batchMaxConcurrentSend, coreConfig.DefaultBatchMaxConcurrentSend)
+		return coreConfig.DefaultBatchMaxConcurrentSend
+	}
+	return batchMaxConcurrentSend
+}
+
+func batchMaxSize(config coreConfig.Config) int {
+	batchMaxSize := coreConfig.Datadog.GetInt("logs_config.batch_max_size")
+	if batchMaxSize <= 0 {
+		log.Warnf("Invalid batch_max_size: %v should be > 0, fallback on %v", batchMaxSize, coreConfig.DefaultBatchMaxSize)
+		return coreConfig.DefaultBatchMaxSize
+	}
+	return batchMaxSize
+}
+
+func batchMaxContentSize(config coreConfig.Config) int {
+	batchMaxContentSize := coreConfig.Datadog.GetInt("logs_config.batch_max_content_size")
+	if batchMaxContentSize <= 0 {
+		log.Warnf("Invalid batch_max_content_size: %v should be > 0, fallback on %v", batchMaxContentSize, coreConfig.DefaultBatchMaxContentSize)
+		return coreConfig.DefaultBatchMaxContentSize
+	}
+	return batchMaxContentSize
+}
+
+func batchWaitWithKey(key string) time.Duration {
+	batchWait := coreConfig.Datadog.GetInt(key)
+	if batchWait < 1 || 10 < batchWait {
+		log.Warnf("Invalid %s: %v should be in [1, 10], fallback on %v", key, batchWait, coreConfig.DefaultBatchWait)
+		return coreConfig.DefaultBatchWait * time.Second
+	}
+	return time.Duration(batchWait) * time.Second
+}
+
+func positiveIntWithKey(key string, defaultValue int) int {
+	value := coreConfig.Datadog.GetInt(key)
+	if value <= 0 {
+		log.Warnf("Invalid %s: %v should be > 0, fallback on %v", key, value, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
 // TaggerWarmupDuration is used to configure the tag providers
 func TaggerWarmupDuration() time.Duration {
 	return coreConfig.Datadog.GetDuration("logs_config.tagger_warmup_duration") * time.Second
 }
+
+// DiskBufferConfig holds the configuration of the on-disk buffers of the pipelines.
+type DiskBufferConfig struct {
+	Path             string
+	MaxSize          int64
+	MaxSizePerSource int64
+	SegmentSize      int64
+	// SpillDelay is how long a log waits for the sender before being written to disk
+	SpillDelay time.Duration
+}
+
+// BuildDiskBufferConfig returns the configuration of the on-disk buffers,
+// returns nil if the buffers are disabled or misconfigured.
+func BuildDiskBufferConfig() *DiskBufferConfig {
+	if !coreConfig.Datadog.GetBool("logs_config.disk_buffer.enabled") {
+		return nil
+	}
+	path := coreConfig.Datadog.GetString("logs_config.disk_buffer.path")
+	if path == "" {
+		path = filepath.Join(coreConfig.Datadog.GetString("logs_config.run_path"), "disk_buffer")
+	}
+	cfg := &DiskBufferConfig{
+		Path:             path,
+		MaxSize:          coreConfig.Datadog.GetInt64("logs_config.disk_buffer.max_size"),
+		MaxSizePerSource: coreConfig.Datadog.GetInt64("logs_config.disk_buffer.max_size_per_source"),
+		SegmentSize:      coreConfig.Datadog.GetInt64("logs_config.disk_buffer.segment_size"),
+		SpillDelay:       time.Duration(coreConfig.Datadog.GetInt("logs_config.disk_buffer.spill_delay")) * time.Second,
+	}
+	if cfg.MaxSize <= 0 || cfg.SegmentSize <= 0 {
+		log.Warnf("Invalid disk buffer configuration, max_size and segment_size must be positive, disabling the disk buffer")
+		return nil
+	}
+	return cfg
+}
@@ -7,6 +7,8 @@ package config
 
 import (
 	"time"
+
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 )
 
 // Endpoint holds all the organization and network parameters to send logs to Datadog.
@@ -19,24 +21,49 @@ type Endpoint struct {
 	CompressionLevel        int  `mapstructure:"compression_level" json:"compression_level"`
 	ProxyAddress            string
 	ConnectionResetInterval time.Duration
+	// TrackType is the intake track of the event platform the endpoint sends to, empty for the logs intake
+	TrackType IntakeTrackType
 }
 
+// IntakeTrackType is the type of the events an event platform intake track receives.
+type IntakeTrackType string
+
 // Endpoints holds the main endpoint and additional ones to dualship logs.
 type Endpoints struct {
-	Main        Endpoint
-	Additionals []Endpoint
-	UseProto    bool
-	UseHTTP     bool
-	BatchWait   time.Duration
+	Main                   Endpoint
+	Additionals            []Endpoint
+	UseProto               bool
+	UseHTTP                bool
+	BatchWait              time.Duration
+	BatchMaxConcurrentSend int
+	BatchMaxSize           int
+	BatchMaxContentSize    int
 }
 
-// NewEndpoints returns a new endpoints composite.
+// NewEndpoints returns a new endpoints composite with default batching settings.
 func NewEndpoints(main Endpoint, additionals []Endpoint, useProto bool, useHTTP bool, batchWait time.Duration) *Endpoints {
+	return NewEndpointsWithBatchSettings(
+		main,
+		additionals,
+		useProto,
+		useHTTP,
+		batchWait,
+		coreConfig.DefaultBatchMaxConcurrentSend,
+		coreConfig.DefaultBatchMaxSize,
+		coreConfig.DefaultBatchMaxContentSize,
+	)
+}
+
+// NewEndpointsWithBatchSettings returns a new endpoints composite with non-default batching settings specified.
+func NewEndpointsWithBatchSettings(main Endpoint, additionals []Endpoint, useProto bool, useHTTP bool, batchWait time.Duration, batchMaxConcurrentSend int, batchMaxSize int, batchMaxContentSize int) *Endpoints {
 	return &Endpoints{
-		Main:        main,
-		Additionals: additionals,
-		UseProto:    useProto,
-		UseHTTP:     useHTTP,
-		BatchWait:   batchWait,
+		Main:                   main,
+		Additionals:            additionals,
+		UseProto:               useProto,
+		UseHTTP:                useHTTP,
+		BatchWait:              batchWait,
+		BatchMaxConcurrentSend: batchMaxConcurrentSend,
+		BatchMaxSize:           batchMaxSize,
+		BatchMaxContentSize:    batchMaxContentSize,
 	}
 }
@@ -225,6 +225,30 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldFailWithInvalidOverride
 	}
 }
 
+func (suite *EndpointsTestSuite) TestBuildEndpointsWithBatchSettings() {
+	suite.config.Set("logs_config.batch_max_concurrent_send", 3)
+	suite.config.Set("logs_config.batch_max_size", 100)
+	suite.config.Set("logs_config.batch_max_content_size", 500000)
+
+	endpoints, err := BuildHTTPEndpoints()
+	suite.Nil(err)
+	suite.Equal(3, endpoints.BatchMaxConcurrentSend)
+	suite.Equal(100, endpoints.BatchMaxSize)
+	suite.Equal(500000, endpoints.BatchMaxContentSize)
+}
+
+func (suite *EndpointsTestSuite) TestBuildEndpointsShouldFallbackOnDefaultWithInvalidBatchSettings() {
+	suite.config.Set("logs_config.batch_max_concurrent_send", 0)
+	suite.config.Set("logs_config.batch_max_size", -1)
+	suite.config.Set("logs_config.batch_max_content_size", 0)
+
+	endpoints, err := BuildHTTPEndpoints()
+	suite.Nil(err)
+	suite.Equal(coreConfig.DefaultBatchMaxConcurrentSend, endpoints.BatchMaxConcurrentSend)
+	suite.Equal(coreConfig.DefaultBatchMaxSize, endpoints.BatchMaxSize)
+	suite.Equal(coreConfig.DefaultBatchMaxContentSize, endpoints.BatchMaxContentSize)
+}
+
 func (suite *EndpointsTestSuite) TestBuildEndpointsShouldFallbackOnDefaultWithInvalidBatchWait() {
 	suite.config.Set("logs_config.use_http", true)
 
@@ -289,7 +313,7 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldTakeIntoAccountHTTPConn
 	suite.False(endpoints.UseHTTP)
 	suite.config.Set("logs_config.socks5_proxy_address", "")
 
-	// When additional_endpoints is not empty always create TCP endpoints
+	// When additional_endpoints is not empty create HTTP endpoints if the connectivity test succeeds
 	suite.config.Set("logs_config.use_http", "false")
 	suite.config.Set("logs_config.use_tcp", "false")
 	suite.config.Set("logs_config.additional_endpoints", []map[string]interface{}{
@@ -302,7 +326,8 @@ func (suite *EndpointsTestSuite) TestBuildEndpointsShouldTakeIntoAccountHTTPConn
 	})
 	endpoints, err = BuildEndpoints(HTTPConnectivitySuccess)
 	suite.Nil(err)
-	suite.False(endpoints.UseHTTP)
+	suite.True(endpoints.UseHTTP)
+	suite.Len(endpoints.Additionals, 1)
 	endpoints, err = BuildEndpoints(HTTPConnectivityFailure)
 	suite.Nil(err)
 	suite.False(endpoints.UseHTTP)
@@ -7,17 +7,20 @@ package config
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
 // Logs source types
 const (
-	TCPType          = "tcp"
-	UDPType          = "udp"
-	FileType         = "file"
-	DockerType       = "docker"
-	JournaldType     = "journald"
-	WindowsEventType = "windows_event"
+	TCPType           = "tcp"
+	UDPType           = "udp"
+	FileType          = "file"
+	DockerType        = "docker"
+	KubernetesAPIType = "kubernetes_api"
+	JournaldType      = "journald"
+	WindowsEventType  = "windows_event"
+	SyslogType        = "syslog"
 )
 
 // LogsConfig represents a log source config, which can be for instance
@@ -25,15 +28,25 @@ const (
 type LogsConfig struct {
 	Type string
 
-	Port int    // Network
+	Port        int    // Network
+	Protocol    string // Syslog
+	TLSCertFile string `mapstructure:"tls_cert_file" json:"tls_cert_file"` // Syslog
+	TLSKeyFile  string `mapstructure:"tls_key_file" json:"tls_key_file"`   // Syslog
+
 	Path string // File, Journald
 
-	ExcludePaths []string `mapstructure:"exclude_paths" json:"exclude_paths"`   // File
-	TailingMode  string   `mapstructure:"start_position" json:"start_position"` // File
+	ExcludePaths        []string `mapstructure:"exclude_paths" json:"exclude_paths"`                 // File
+	TailingMode         string   `mapstructure:"start_position" json:"start_position"`               // File
+	OpenFilesLimit      int      `mapstructure:"open_files_limit" json:"open_files_limit"`           // File
+	FileSelectionPolicy string   `mapstructure:"file_selection_policy" json:"file_selection_policy"` // File
 
-	IncludeUnits  []string `mapstructure:"include_units" json:"include_units"`   // Journald
-	ExcludeUnits  []string `mapstructure:"exclude_units" json:"exclude_units"`   // Journald
-	ContainerMode bool     `mapstructure:"container_mode" json:"container_mode"` // Journald
+	IncludeUnits      []string `mapstructure:"include_units" json:"include_units"`           // Journald
+	ExcludeUnits      []string `mapstructure:"exclude_units" json:"exclude_units"`           // Journald
+	IncludeMatches    []string `mapstructure:"include_matches" json:"include_matches"`       // Journald
+	ExcludeMatches    []string `mapstructure:"exclude_matches" json:"exclude_matches"`       // Journald
+	PriorityThreshold string   `mapstructure:"priority_threshold" json:"priority_threshold"` // Journald
+	ContainerMode     bool     `mapstructure:"container_mode" json:"container_mode"`         // Journald
+	ConfigID          string   `mapstructure:"config_id" json:"config_id"`                   // Journald
 
 	Image      string // Docker
 	Label      string // Docker
@@ -48,8 +61,27 @@ type LogsConfig struct {
 	SourceCategory  string
 	Tags            []string
 	ProcessingRules []*ProcessingRule `mapstructure:"log_processing_rules" json:"log_processing_rules"`
+	AutoMultiLine   *bool             `mapstructure:"auto_multi_line_detection" json:"auto_multi_line_detection"`
 }
 
+// File selection policies, define which files are tailed first when a wildcard path
+// matches more files than the open files limit.
+const (
+	// SelectByName selects the files in reverse lexicographical order
+	SelectByName = "by_name"
+	// SelectNewest selects the most recently modified files first
+	SelectNewest = "newest"
+	// SelectOldest selects the least recently modified files first
+	SelectOldest = "oldest"
+)
+
+// Syslog transport protocols
+const (
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+	SyslogTLS = "tls"
+)
+
 // TailingMode type
 type TailingMode uint8
 
@@ -107,10 +139,24 @@ func (c *LogsConfig) Validate() error {
 		if err != nil {
 			return err
 		}
+		err = c.validateFileSelection()
+		if err != nil {
+			return err
+		}
 	case c.Type == TCPType && c.Port == 0:
 		return fmt.Errorf("tcp source must have a port")
 	case c.Type == UDPType && c.Port == 0:
 		return fmt.Errorf("udp source must have a port")
+	case c.Type == SyslogType:
+		err := c.validateSyslog()
+		if err != nil {
+			return err
+		}
+	case c.Type == JournaldType:
+		err := c.validateJournaldMatches()
+		if err != nil {
+			return err
+		}
 	}
 	err := ValidateProcessingRules(c.ProcessingRules)
 	if err != nil {
@@ -130,6 +176,90 @@ func (c *LogsConfig) validateTailingMode() error {
 	return nil
 }
 
+func (c *LogsConfig) validateFileSelection() error {
+	if c.OpenFilesLimit < 0 {
+		return fmt.Errorf("invalid open_files_limit %d for %v", c.OpenFilesLimit, c.Path)
+	}
+	switch c.FileSelectionPolicy {
+	case "", SelectByName, SelectNewest, SelectOldest:
+		return nil
+	default:
+		return fmt.Errorf("invalid file_selection_policy '%v' for %v, must be one of %s, %s or %s", c.FileSelectionPolicy, c.Path, SelectByName, SelectNewest, SelectOldest)
+	}
+}
+
+func (c *LogsConfig) validateSyslog() error {
+	if c.Port == 0 {
+		return fmt.Errorf("syslog source must have a port")
+	}
+	switch c.Protocol {
+	case "", SyslogUDP, SyslogTCP:
+		return nil
+	case SyslogTLS:
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return fmt.Errorf("syslog source over tls must have a tls_cert_file and a tls_key_file")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid syslog protocol '%v', must be one of %s, %s or %s", c.Protocol, SyslogUDP, SyslogTCP, SyslogTLS)
+	}
+}
+
+// GetSyslogProtocol returns the transport protocol of a syslog source, defaults to udp.
+func (c *LogsConfig) GetSyslogProtocol() string {
+	if c.Protocol == "" {
+		return SyslogUDP
+	}
+	return c.Protocol
+}
+
+func (c *LogsConfig) validateJournaldMatches() error {
+	for _, match := range append(c.IncludeMatches, c.ExcludeMatches...) {
+		if _, _, ok := ParseJournaldMatch(match); !ok {
+			return fmt.Errorf("invalid journald match '%v', expected FIELD=value", match)
+		}
+	}
+	if _, found := JournaldPriorityFromString(c.PriorityThreshold); !found && c.PriorityThreshold != "" {
+		return fmt.Errorf("invalid journald priority threshold '%v'", c.PriorityThreshold)
+	}
+	return nil
+}
+
+// ParseJournaldMatch splits a journald match of the form FIELD=value,
+// returns false if the match is malformed.
+func ParseJournaldMatch(match string) (string, string, bool) {
+	parts := strings.SplitN(match, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+// journaldPriorities maps the syslog severity names to the journald priorities.
+var journaldPriorities = map[string]int{
+	"emerg":   0,
+	"alert":   1,
+	"crit":    2,
+	"err":     3,
+	"warning": 4,
+	"notice":  5,
+	"info":    6,
+	"debug":   7,
+}
+
+// JournaldPriorityFromString returns the journald priority matching a severity name
+// or a numeric value between 0 and 7, returns false if the value is invalid.
+func JournaldPriorityFromString(value string) (int, bool) {
+	if priority, found := journaldPriorities[strings.ToLower(value)]; found {
+		return priority, true
+	}
+	priority, err := strconv.Atoi(value)
+	if err != nil || priority < 0 || priority > 7 {
+		return 0, false
+	}
+	return priority, true
+}
+
 // ContainsWildcard returns true if the path contains any wildcard character
 func ContainsWildcard(path string) bool {
 	return strings.ContainsAny(path, "*?[")
@@ -16,10 +16,16 @@ import (
 func TestValidateShouldSucceedWithValidConfigs(t *testing.T) {
 	validConfigs := []*LogsConfig{
 		{Type: FileType, Path: "/var/log/foo.log"},
+		{Type: FileType, Path: "/var/log/**/*.log", OpenFilesLimit: 10, FileSelectionPolicy: SelectNewest},
 		{Type: TCPType, Port: 1234},
 		{Type: UDPType, Port: 5678},
+		{Type: SyslogType, Port: 514},
+		{Type: SyslogType, Port: 514, Protocol: SyslogTCP},
+		{Type: SyslogType, Port: 6514, Protocol: SyslogTLS, TLSCertFile: "/etc/cert.pem", TLSKeyFile: "/etc/key.pem"},
 		{Type: DockerType},
 		{Type: JournaldType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch, Pattern: ".*"}}},
+		{Type: JournaldType, IncludeMatches: []string{"_TRANSPORT=kernel"}, ExcludeMatches: []string{"SYSLOG_IDENTIFIER="}, PriorityThreshold: "warning"},
+		{Type: JournaldType, PriorityThreshold: "3"},
 	}
 
 	for _, config := range validConfigs {
@@ -32,8 +38,13 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 	invalidConfigs := []*LogsConfig{
 		{},
 		{Type: FileType},
+		{Type: FileType, Path: "/var/log/*.log", OpenFilesLimit: -1},
+		{Type: FileType, Path: "/var/log/*.log", FileSelectionPolicy: "foo"},
 		{Type: TCPType},
 		{Type: UDPType},
+		{Type: SyslogType},
+		{Type: SyslogType, Port: 514, Protocol: "foo"},
+		{Type: SyslogType, Port: 6514, Protocol: SyslogTLS},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: "bar"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Name: "foo", Type: ExcludeAtMatch}}},
@@ -41,6 +52,10 @@ func TestValidateShouldFailWithInvalidConfigs(t *testing.T) {
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch, Pattern: ".*"}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Type: ExcludeAtMatch}}},
 		{Type: DockerType, ProcessingRules: []*ProcessingRule{{Pattern: ".*"}}},
+		{Type: JournaldType, IncludeMatches: []string{"_TRANSPORT"}},
+		{Type: JournaldType, ExcludeMatches: []string{"=kernel"}},
+		{Type: JournaldType, PriorityThreshold: "8"},
+		{Type: JournaldType, PriorityThreshold: "foo"},
 	}
 
 	for _, config := range invalidConfigs {
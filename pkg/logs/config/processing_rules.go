@@ -12,10 +12,12 @@ import (
 
 // Processing rule types
 const (
-	ExcludeAtMatch = "exclude_at_match"
-	IncludeAtMatch = "include_at_match"
-	MaskSequences  = "mask_sequences"
-	MultiLine      = "multi_line"
+	ExcludeAtMatch  = "exclude_at_match"
+	IncludeAtMatch  = "include_at_match"
+	MaskSequences   = "mask_sequences"
+	MultiLine       = "multi_line"
+	AggregateLines  = "aggregate_lines"
+	ParseAndExtract = "parse_and_extract"
 )
 
 // ProcessingRule defines an exclusion or a masking rule to
@@ -25,8 +27,13 @@ type ProcessingRule struct {
 	Name               string
 	ReplacePlaceholder string `mapstructure:"replace_placeholder" json:"replace_placeholder"`
 	Pattern            string
+	// StartPattern, EndPattern and FlushTimeout (in milliseconds) are only used by aggregate_lines rules
+	StartPattern string `mapstructure:"start_pattern" json:"start_pattern"`
+	EndPattern   string `mapstructure:"end_pattern" json:"end_pattern"`
+	FlushTimeout int    `mapstructure:"flush_timeout" json:"flush_timeout"`
 	// TODO: should be moved out
 	Regex       *regexp.Regexp
+	EndRegex    *regexp.Regexp
 	Placeholder []byte
 }
 
@@ -35,6 +42,8 @@ type ProcessingRule struct {
 // - a valid name
 // - a valid type
 // - a valid pattern that compiles
+// Aggregate lines rules must have a start pattern, an end pattern or both instead of a pattern.
+// Parse and extract rules must have a pattern with at least one named capture group.
 func ValidateProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
 		if rule.Name == "" {
@@ -42,8 +51,14 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		}
 
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine:
+		case ExcludeAtMatch, IncludeAtMatch, MaskSequences, MultiLine, ParseAndExtract:
 			break
+		case AggregateLines:
+			err := validateAggregateLinesRule(rule)
+			if err != nil {
+				return err
+			}
+			continue
 		case "":
 			return fmt.Errorf("type must be set for processing rule `%s`", rule.Name)
 		default:
@@ -53,10 +68,43 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 		if rule.Pattern == "" {
 			return fmt.Errorf("no pattern provided for processing rule: %s", rule.Name)
 		}
-		_, err := regexp.Compile(rule.Pattern)
+		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return fmt.Errorf("invalid pattern %s for processing rule: %s", rule.Pattern, rule.Name)
 		}
+		if rule.Type == ParseAndExtract && !hasNamedGroup(re) {
+			return fmt.Errorf("pattern %s of processing rule %s must have at least one named capture group", rule.Pattern, rule.Name)
+		}
+	}
+	return nil
+}
+
+// hasNamedGroup returns true if the regex has at least one named capture group.
+func hasNamedGroup(re *regexp.Regexp) bool {
+	for _, name := range re.SubexpNames() {
+		if name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAggregateLinesRule validates the patterns and the timeout of an aggregate lines rule.
+func validateAggregateLinesRule(rule *ProcessingRule) error {
+	if rule.StartPattern == "" && rule.EndPattern == "" {
+		return fmt.Errorf("no start_pattern or end_pattern provided for processing rule: %s", rule.Name)
+	}
+	for _, pattern := range []string{rule.StartPattern, rule.EndPattern} {
+		if pattern == "" {
+			continue
+		}
+		_, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %s for processing rule: %s", pattern, rule.Name)
+		}
+	}
+	if rule.FlushTimeout < 0 {
+		return fmt.Errorf("invalid flush_timeout %d for processing rule: %s", rule.FlushTimeout, rule.Name)
 	}
 	return nil
 }
@@ -64,12 +112,19 @@ func ValidateProcessingRules(rules []*ProcessingRule) error {
 // CompileProcessingRules compiles all processing rule regular expressions.
 func CompileProcessingRules(rules []*ProcessingRule) error {
 	for _, rule := range rules {
+		if rule.Type == AggregateLines {
+			err := compileAggregateLinesRule(rule)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		re, err := regexp.Compile(rule.Pattern)
 		if err != nil {
 			return err
 		}
 		switch rule.Type {
-		case ExcludeAtMatch, IncludeAtMatch:
+		case ExcludeAtMatch, IncludeAtMatch, ParseAndExtract:
 			rule.Regex = re
 		case MaskSequences:
 			rule.Regex = re
@@ -83,3 +138,22 @@ func CompileProcessingRules(rules []*ProcessingRule) error {
 	}
 	return nil
 }
+
+// compileAggregateLinesRule compiles the start and end patterns of an aggregate lines rule,
+// the start pattern must match at the beginning of a line while the end pattern must match at its end.
+func compileAggregateLinesRule(rule *ProcessingRule) error {
+	var err error
+	if rule.StartPattern != "" {
+		rule.Regex, err = regexp.Compile("^" + rule.StartPattern)
+		if err != nil {
+			return err
+		}
+	}
+	if rule.EndPattern != "" {
+		rule.EndRegex, err = regexp.Compile(rule.EndPattern + "$")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -33,3 +33,57 @@ func TestCompileShouldFailWithInvalidRules(t *testing.T) {
 		assert.Nil(t, rule.Regex)
 	}
 }
+
+func TestCompileAggregateLinesRules(t *testing.T) {
+	rules := []*ProcessingRule{
+		{Type: AggregateLines, StartPattern: `\d{4}-\d{2}-\d{2}`},
+		{Type: AggregateLines, EndPattern: `;`},
+		{Type: AggregateLines, StartPattern: `BEGIN`, EndPattern: `END`},
+	}
+	err := CompileProcessingRules(rules)
+	assert.Nil(t, err)
+
+	assert.True(t, rules[0].Regex.MatchString("2020-06-01 foo"))
+	assert.False(t, rules[0].Regex.MatchString("foo 2020-06-01"))
+	assert.Nil(t, rules[0].EndRegex)
+
+	assert.Nil(t, rules[1].Regex)
+	assert.True(t, rules[1].EndRegex.MatchString("SELECT 1;"))
+	assert.False(t, rules[1].EndRegex.MatchString("SELECT 1; -- foo"))
+
+	assert.True(t, rules[2].Regex.MatchString("BEGIN foo"))
+	assert.True(t, rules[2].EndRegex.MatchString("foo END"))
+}
+
+func TestValidateParseAndExtractRules(t *testing.T) {
+	assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{{Name: "foo", Type: ParseAndExtract, Pattern: `user=(?P<user>\w+)`}}))
+
+	invalidRules := []*ProcessingRule{
+		{Name: "foo", Type: ParseAndExtract},
+		{Name: "foo", Type: ParseAndExtract, Pattern: `user=(\w+)`},
+		{Name: "foo", Type: ParseAndExtract, Pattern: `(?P<user>`},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}))
+	}
+}
+
+func TestValidateAggregateLinesRules(t *testing.T) {
+	validRules := []*ProcessingRule{
+		{Name: "foo", Type: AggregateLines, StartPattern: "foo"},
+		{Name: "foo", Type: AggregateLines, EndPattern: "bar", FlushTimeout: 500},
+	}
+	for _, rule := range validRules {
+		assert.Nil(t, ValidateProcessingRules([]*ProcessingRule{rule}))
+	}
+
+	invalidRules := []*ProcessingRule{
+		{Name: "foo", Type: AggregateLines},
+		{Name: "foo", Type: AggregateLines, Pattern: "foo"},
+		{Name: "foo", Type: AggregateLines, StartPattern: "(?=abf)"},
+		{Name: "foo", Type: AggregateLines, EndPattern: "bar", FlushTimeout: -1},
+	}
+	for _, rule := range invalidRules {
+		assert.NotNil(t, ValidateProcessingRules([]*ProcessingRule{rule}))
+	}
+}
@@ -7,6 +7,7 @@ package config
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
 // SourceType used for log line parsing logic.
@@ -30,6 +31,7 @@ type LogSource struct {
 	inputs   map[string]bool
 	lock     *sync.Mutex
 	Messages *Messages
+	Metrics  *SourceMetrics
 	// sourceType is the type of the source that we are tailing whereas Config.Type is the type of the tailer
 	// that reads log lines for this source. E.g, a sourceType == containerd and Config.Type == file means that
 	// the agent is tailing a file to read logs of a containerd container
@@ -45,6 +47,7 @@ func NewLogSource(name string, config *LogsConfig) *LogSource {
 		inputs:   make(map[string]bool),
 		lock:     &sync.Mutex{},
 		Messages: NewMessages(),
+		Metrics:  &SourceMetrics{},
 	}
 }
 
@@ -86,3 +89,53 @@ func (s *LogSource) GetSourceType() SourceType {
 	defer s.lock.Unlock()
 	return s.sourceType
 }
+
+// SourceMetrics counts the logs of a source handled by the pipelines since the agent started,
+// a nil SourceMetrics records nothing.
+type SourceMetrics struct {
+	logsSent    int64
+	bytesSent   int64
+	logsDropped int64
+}
+
+// AddSent records a log sent to the intake.
+func (m *SourceMetrics) AddSent(bytes int) {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.logsSent, 1)
+	atomic.AddInt64(&m.bytesSent, int64(bytes))
+}
+
+// AddDropped records a log that will never be sent, either because it was
+// excluded by a processing rule or because it could not be encoded or buffered.
+func (m *SourceMetrics) AddDropped() {
+	if m == nil {
+		return
+	}
+	atomic.AddInt64(&m.logsDropped, 1)
+}
+
+// LogsSent returns the number of logs sent.
+func (m *SourceMetrics) LogsSent() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.logsSent)
+}
+
+// BytesSent returns the number of bytes sent after encoding.
+func (m *SourceMetrics) BytesSent() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.bytesSent)
+}
+
+// LogsDropped returns the number of logs dropped.
+func (m *SourceMetrics) LogsDropped() int64 {
+	if m == nil {
+		return 0
+	}
+	return atomic.LoadInt64(&m.logsDropped)
+}
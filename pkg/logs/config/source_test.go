@@ -28,6 +28,21 @@ func (s *LogSourceSuite) TestInputs() {
 
 }
 
+func (s *LogSourceSuite) TestMetrics() {
+	s.source = NewLogSource("", nil)
+	s.source.Metrics.AddSent(5)
+	s.source.Metrics.AddSent(3)
+	s.source.Metrics.AddDropped()
+	s.Equal(int64(2), s.source.Metrics.LogsSent())
+	s.Equal(int64(8), s.source.Metrics.BytesSent())
+	s.Equal(int64(1), s.source.Metrics.LogsDropped())
+
+	var metrics *SourceMetrics
+	metrics.AddSent(5)
+	metrics.AddDropped()
+	s.Equal(int64(0), metrics.LogsSent())
+}
+
 func TestTrackerSuite(t *testing.T) {
 	suite.Run(t, new(LogSourceSuite))
 }
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package decoder
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// knownNewContentPatterns represents the formats of the timestamps usually found
+// at the beginning of a log line, the lines following a line starting with one of them
+// and not matching it (stack traces, dumps, ...) are considered part of the same message.
+var knownNewContentPatterns = []*regexp.Regexp{
+	// 2020-06-01 10:00:00, 2020-06-01T10:00:00.000Z, [2020-06-01 10:00:00]
+	regexp.MustCompile(`^\[?\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}`),
+	// 2020/06/01 10:00:00
+	regexp.MustCompile(`^\[?\d{4}/\d{2}/\d{2} \d{2}:\d{2}:\d{2}`),
+	// Jun  1 10:00:00, [Jun 01 10:00:00]
+	regexp.MustCompile(`^\[?[A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2}`),
+	// Mon Jun 01 10:00:00 2020, [Mon Jun 01 10:00:00.000000 2020]
+	regexp.MustCompile(`^\[?[A-Z][a-z]{2} [A-Z][a-z]{2} +\d{1,2} \d{2}:\d{2}:\d{2}`),
+	// 01/Jun/2020:10:00:00, [01/Jun/2020:10:00:00 +0000]
+	regexp.MustCompile(`^\[?\d{2}/[A-Z][a-z]{2}/\d{4}:\d{2}:\d{2}:\d{2}`),
+	// 10:00:00.000, 10:00:00,000
+	regexp.MustCompile(`^\[?\d{2}:\d{2}:\d{2}[.,]\d{3}`),
+	// I0601 10:00:00.000000 (glog)
+	regexp.MustCompile(`^[IWEF]\d{4} \d{2}:\d{2}:\d{2}`),
+}
+
+// AutoMultilineHandler samples the first lines of a source to detect whether its messages
+// span over multiple lines, the lines are sent as single lines while sampling.
+// When a known timestamp format starts enough lines of the sample, it switches to a
+// MultiLineHandler using this format as the new content pattern.
+type AutoMultilineHandler struct {
+	lineChan          chan []byte
+	outputChan        chan *Output
+	singleLineHandler *SingleLineHandler
+	multiLineHandler  *MultiLineHandler
+	parser            parser.Parser
+	flushTimeout      time.Duration
+	lineLimit         int
+	sampleSize        int
+	matchThreshold    float64
+	linesTested       int
+	matches           []int
+}
+
+// NewAutoMultilineHandler returns a new AutoMultilineHandler.
+func NewAutoMultilineHandler(outputChan chan *Output, parser parser.Parser, lineLimit int, sampleSize int, matchThreshold float64, flushTimeout time.Duration) *AutoMultilineHandler {
+	return &AutoMultilineHandler{
+		lineChan:          make(chan []byte),
+		outputChan:        outputChan,
+		singleLineHandler: NewSingleLineHandler(outputChan, parser, lineLimit),
+		parser:            parser,
+		flushTimeout:      flushTimeout,
+		lineLimit:         lineLimit,
+		sampleSize:        sampleSize,
+		matchThreshold:    matchThreshold,
+		matches:           make([]int, len(knownNewContentPatterns)),
+	}
+}
+
+// Handle puts all new lines into a channel for later processing.
+func (h *AutoMultilineHandler) Handle(content []byte) {
+	h.lineChan <- content
+}
+
+// Stop stops the handler.
+func (h *AutoMultilineHandler) Stop() {
+	close(h.lineChan)
+}
+
+// Start starts the handler.
+func (h *AutoMultilineHandler) Start() {
+	go h.run()
+}
+
+// run processes the lines while sampling, then forwards them to the
+// multiline handler if a pattern was detected.
+func (h *AutoMultilineHandler) run() {
+	for line := range h.lineChan {
+		if h.multiLineHandler != nil {
+			h.multiLineHandler.Handle(line)
+			continue
+		}
+		if h.linesTested < h.sampleSize {
+			h.sample(line)
+		}
+		h.singleLineHandler.process(line)
+		if h.linesTested == h.sampleSize {
+			h.linesTested++
+			h.detect()
+		}
+	}
+	if h.multiLineHandler != nil {
+		// the multiline handler flushes its buffer and closes the output channel
+		h.multiLineHandler.Stop()
+		return
+	}
+	close(h.outputChan)
+}
+
+// sample records the known patterns matching the line.
+func (h *AutoMultilineHandler) sample(line []byte) {
+	h.linesTested++
+	content, _, _, err := h.parser.Parse(line)
+	if err != nil {
+		log.Debug(err)
+	}
+	for i, re := range knownNewContentPatterns {
+		if re.Match(content) {
+			h.matches[i]++
+		}
+	}
+}
+
+// detect switches to a multiline handler if a known pattern matched
+// enough lines of the sample, and stays with single lines otherwise.
+func (h *AutoMultilineHandler) detect() {
+	best := -1
+	for i, count := range h.matches {
+		if best < 0 || count > h.matches[best] {
+			best = i
+		}
+	}
+	if best < 0 || h.matches[best] == 0 {
+		log.Debugf("No multiline pattern detected in the %d first lines", h.sampleSize)
+		return
+	}
+	ratio := float64(h.matches[best]) / float64(h.sampleSize)
+	if ratio < h.matchThreshold {
+		log.Debugf("No multiline pattern detected in the %d first lines, best match ratio %.2f for pattern %s", h.sampleSize, ratio, knownNewContentPatterns[best])
+		return
+	}
+	log.Debugf("Multiline pattern %s detected with match ratio %.2f", knownNewContentPatterns[best], ratio)
+	h.multiLineHandler = NewMultiLineHandler(h.outputChan, knownNewContentPatterns[best], h.flushTimeout, h.parser, h.lineLimit)
+	h.multiLineHandler.Start()
+}
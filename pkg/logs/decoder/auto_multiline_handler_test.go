@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package decoder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/parser"
+)
+
+func TestAutoMultilineHandlerDetectsTimestampPattern(t *testing.T) {
+	outputChan := make(chan *Output, 10)
+	h := NewAutoMultilineHandler(outputChan, parser.NoopParser, 100, 3, 0.5, 10*time.Millisecond)
+	h.Start()
+
+	var output *Output
+
+	// the sampled lines are sent as single lines
+	h.Handle([]byte("2020-06-01 10:00:00 ERROR foo"))
+	h.Handle([]byte("  at com.example.Foo"))
+	h.Handle([]byte("2020-06-01 10:00:01 INFO bar"))
+	for _, expected := range []string{"2020-06-01 10:00:00 ERROR foo", "at com.example.Foo", "2020-06-01 10:00:01 INFO bar"} {
+		output = <-outputChan
+		assert.Equal(t, expected, string(output.Content))
+	}
+
+	// the following lines are aggregated
+	h.Handle([]byte("2020-06-01 10:00:02 ERROR baz"))
+	h.Handle([]byte("  at com.example.Baz"))
+	h.Handle([]byte("  at com.example.Main"))
+	h.Handle([]byte("2020-06-01 10:00:03 INFO qux"))
+
+	output = <-outputChan
+	assert.Equal(t, "2020-06-01 10:00:02 ERROR baz\\n  at com.example.Baz\\n  at com.example.Main", string(output.Content))
+
+	h.Stop()
+
+	output = <-outputChan
+	assert.Equal(t, "2020-06-01 10:00:03 INFO qux", string(output.Content))
+
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+}
+
+func TestAutoMultilineHandlerKeepsSingleLinesWithoutPattern(t *testing.T) {
+	outputChan := make(chan *Output, 10)
+	h := NewAutoMultilineHandler(outputChan, parser.NoopParser, 100, 2, 0.5, 10*time.Millisecond)
+	h.Start()
+
+	lines := []string{"foo", "bar", "2020-06-01 10:00:00 baz", "qux"}
+	for _, line := range lines {
+		h.Handle([]byte(line))
+	}
+	for _, expected := range lines {
+		output := <-outputChan
+		assert.Equal(t, expected, string(output.Content))
+	}
+
+	h.Stop()
+
+	_, isOpen := <-outputChan
+	assert.False(t, isOpen)
+}
@@ -7,7 +7,9 @@ package decoder
 
 import (
 	"bytes"
+	"time"
 
+	coreConfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/parser"
 )
@@ -68,10 +70,22 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 	lineLimit := defaultContentLenLimit
 	var lineHandler LineHandler
 	for _, rule := range source.Config.ProcessingRules {
-		if rule.Type == config.MultiLine {
+		switch rule.Type {
+		case config.MultiLine:
 			lineHandler = NewMultiLineHandler(outputChan, rule.Regex, defaultFlushTimeout, parser, lineLimit)
+		case config.AggregateLines:
+			flushTimeout := defaultFlushTimeout
+			if rule.FlushTimeout > 0 {
+				flushTimeout = time.Duration(rule.FlushTimeout) * time.Millisecond
+			}
+			lineHandler = NewAggregateLinesHandler(outputChan, rule.Regex, rule.EndRegex, flushTimeout, parser, lineLimit)
 		}
 	}
+	if lineHandler == nil && shouldDetectMultiLine(source) {
+		sampleSize := coreConfig.Datadog.GetInt("logs_config.auto_multi_line_default_sample_size")
+		matchThreshold := coreConfig.Datadog.GetFloat64("logs_config.auto_multi_line_default_match_threshold")
+		lineHandler = NewAutoMultilineHandler(outputChan, parser, lineLimit, sampleSize, matchThreshold, defaultFlushTimeout)
+	}
 	if lineHandler == nil {
 		lineHandler = NewSingleLineHandler(outputChan, parser, lineLimit)
 	}
@@ -79,6 +93,15 @@ func NewDecoderWithEndLineMatcher(source *config.LogSource, parser parser.Parser
 	return New(inputChan, outputChan, lineHandler, lineLimit, matcher)
 }
 
+// shouldDetectMultiLine returns true if the multiline patterns of the source should be
+// detected automatically, the source configuration takes precedence over the agent one.
+func shouldDetectMultiLine(source *config.LogSource) bool {
+	if source.Config.AutoMultiLine != nil {
+		return *source.Config.AutoMultiLine
+	}
+	return coreConfig.Datadog.GetBool("logs_config.auto_multi_line_detection")
+}
+
 // New returns an initialized Decoder
 func New(InputChan chan *Input, OutputChan chan *Output, lineHandler LineHandler, contentLenLimit int, matcher EndLineMatcher) *Decoder {
 	var lineBuffer bytes.Buffer
@@ -129,6 +129,7 @@ type MultiLineHandler struct {
 	outputChan     chan *Output
 	parser         parser.Parser
 	newContentRe   *regexp.Regexp
+	endContentRe   *regexp.Regexp
 	buffer         *bytes.Buffer
 	flushTimeout   time.Duration
 	lineLimit      int
@@ -151,6 +152,14 @@ func NewMultiLineHandler(outputChan chan *Output, newContentRe *regexp.Regexp, f
 	}
 }
 
+// NewAggregateLinesHandler returns a new MultiLineHandler aggregating lines between
+// a line matching startRe and a line matching endRe, one of them can be nil.
+func NewAggregateLinesHandler(outputChan chan *Output, startRe *regexp.Regexp, endRe *regexp.Regexp, flushTimeout time.Duration, parser parser.Parser, lineLimit int) *MultiLineHandler {
+	h := NewMultiLineHandler(outputChan, startRe, flushTimeout, parser, lineLimit)
+	h.endContentRe = endRe
+	return h
+}
+
 // Handle forward lines to lineChan to process them.
 func (h *MultiLineHandler) Handle(content []byte) {
 	h.lineChan <- content
@@ -205,7 +214,8 @@ func (h *MultiLineHandler) run() {
 }
 
 // process aggregates multiple lines to form a full multiline message,
-// it stops when a line matches with the new content regular expression.
+// it stops when a line matches with the new content regular expression
+// or right after a line matching with the end content regular expression.
 // It also makes sure that the content will never exceed the limit
 // and that the length of the lines is properly tracked
 // so that the agent restarts tailing from the right place.
@@ -215,7 +225,7 @@ func (h *MultiLineHandler) process(line []byte) {
 		log.Debug(err)
 	}
 
-	if h.newContentRe.Match(content) {
+	if h.newContentRe != nil && h.newContentRe.Match(content) {
 		// the current line is part of a new message,
 		// send the buffer
 		h.sendBuffer()
@@ -258,6 +268,9 @@ func (h *MultiLineHandler) process(line []byte) {
 		h.buffer.Write(truncatedFlag)
 		h.sendBuffer()
 		h.shouldTruncate = true
+	} else if h.endContentRe != nil && h.endContentRe.Match(bytes.TrimSpace(content)) {
+		// the current line is the last line of the message
+		h.sendBuffer()
 	}
 }
 
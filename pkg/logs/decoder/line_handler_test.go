@@ -272,3 +272,56 @@ func TestMultiLineHandlerSendsRawInvalidMessages(t *testing.T) {
 	output = <-outputChan
 	assert.Equal(t, "1.third line\\nfourth line", string(output.Content))
 }
+
+func TestAggregateLinesHandlerWithEndPattern(t *testing.T) {
+	re := regexp.MustCompile(";$")
+	outputChan := make(chan *Output, 10)
+	h := NewAggregateLinesHandler(outputChan, nil, re, 100*time.Millisecond, parser.NoopParser, 100)
+	h.Start()
+
+	var output *Output
+
+	// the message is sent as soon as the end pattern matches
+	h.Handle([]byte("SELECT *"))
+	h.Handle([]byte("FROM foo"))
+	h.Handle([]byte("WHERE bar = 1;  "))
+	h.Handle([]byte("SELECT 1;"))
+
+	output = <-outputChan
+	assert.Equal(t, "SELECT *\\nFROM foo\\nWHERE bar = 1;", string(output.Content))
+	assert.Equal(t, len("SELECT *")+1+len("FROM foo")+1+len("WHERE bar = 1;  ")+1, output.RawDataLen)
+
+	output = <-outputChan
+	assert.Equal(t, "SELECT 1;", string(output.Content))
+
+	// the message is flushed after the timeout when the end pattern never matches
+	h.Handle([]byte("SELECT"))
+	output = <-outputChan
+	assert.Equal(t, "SELECT", string(output.Content))
+
+	h.Stop()
+}
+
+func TestAggregateLinesHandlerWithStartAndEndPatterns(t *testing.T) {
+	startRe := regexp.MustCompile("^BEGIN")
+	endRe := regexp.MustCompile("END$")
+	outputChan := make(chan *Output, 10)
+	h := NewAggregateLinesHandler(outputChan, startRe, endRe, 100*time.Millisecond, parser.NoopParser, 100)
+	h.Start()
+
+	var output *Output
+
+	h.Handle([]byte("BEGIN"))
+	h.Handle([]byte("foo"))
+	h.Handle([]byte("BEGIN"))
+	h.Handle([]byte("bar"))
+	h.Handle([]byte("END"))
+
+	output = <-outputChan
+	assert.Equal(t, "BEGIN\\nfoo", string(output.Content))
+
+	output = <-outputChan
+	assert.Equal(t, "BEGIN\\nbar\\nEND", string(output.Content))
+
+	h.Stop()
+}
@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnostic
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// subscriberBufferSize is the number of formatted messages a subscriber can lag behind,
+// the messages are dropped for this subscriber when its buffer is full.
+const subscriberBufferSize = 100
+
+// MessageReceiver receives the processed messages for diagnostic purposes.
+type MessageReceiver interface {
+	HandleMessage(msg *message.Message, redactedContent []byte)
+}
+
+// Filters restricts the messages streamed to a subscriber, empty filters match all the messages.
+type Filters struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"`
+	Source  string `json:"source"`
+	Service string `json:"service"`
+}
+
+// BufferedMessageReceiver formats the messages and forwards them to the subscribers matching them,
+// it does nothing while nobody is subscribed so that it has no impact on the pipelines.
+type BufferedMessageReceiver struct {
+	mutex       sync.RWMutex
+	subscribers map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	filters *Filters
+	lines   chan string
+}
+
+// NewBufferedMessageReceiver returns a new BufferedMessageReceiver.
+func NewBufferedMessageReceiver() *BufferedMessageReceiver {
+	return &BufferedMessageReceiver{
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the formatted messages matching the filters,
+// the returned function must be called to unsubscribe.
+func (b *BufferedMessageReceiver) Subscribe(filters *Filters) (<-chan string, func()) {
+	s := &subscriber{
+		filters: filters,
+		lines:   make(chan string, subscriberBufferSize),
+	}
+	b.mutex.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mutex.Unlock()
+	return s.lines, func() {
+		b.mutex.Lock()
+		delete(b.subscribers, s)
+		b.mutex.Unlock()
+	}
+}
+
+// IsEnabled returns true if at least one subscriber is streaming messages.
+func (b *BufferedMessageReceiver) IsEnabled() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscribers) > 0
+}
+
+// HandleMessage forwards the message to the subscribers matching it without blocking.
+func (b *BufferedMessageReceiver) HandleMessage(msg *message.Message, redactedContent []byte) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	if len(b.subscribers) == 0 {
+		return
+	}
+	var line string
+	for s := range b.subscribers {
+		if !s.filters.match(msg) {
+			continue
+		}
+		if line == "" {
+			line = formatMessage(msg, redactedContent)
+		}
+		select {
+		case s.lines <- line:
+		default:
+			// the subscriber is falling behind, the message is not streamed
+		}
+	}
+}
+
+// match returns true if the message matches all the filters set.
+func (f *Filters) match(msg *message.Message) bool {
+	if f == nil {
+		return true
+	}
+	if msg.Origin == nil || msg.Origin.LogSource == nil {
+		return f.Name == "" && f.Type == "" && f.Source == "" && f.Service == ""
+	}
+	origin := msg.Origin
+	switch {
+	case f.Name != "" && f.Name != origin.LogSource.Name:
+		return false
+	case f.Type != "" && f.Type != origin.LogSource.Config.Type:
+		return false
+	case f.Source != "" && f.Source != origin.Source():
+		return false
+	case f.Service != "" && f.Service != origin.Service():
+		return false
+	}
+	return true
+}
+
+// formatMessage returns a human readable representation of a processed message.
+func formatMessage(msg *message.Message, redactedContent []byte) string {
+	var name, sourceType, source, service, tags string
+	if msg.Origin != nil && msg.Origin.LogSource != nil {
+		name = msg.Origin.LogSource.Name
+		sourceType = msg.Origin.LogSource.Config.Type
+		source = msg.Origin.Source()
+		service = msg.Origin.Service()
+		tags = strings.Join(msg.Origin.Tags(), ",")
+	}
+	return fmt.Sprintf("Integration Name: %s | Type: %s | Status: %s | Timestamp: %s | Service: %s | Source: %s | Tags: %s | Message: %s",
+		name, sourceType, msg.GetStatus(), time.Now().UTC().Format(time.RFC3339), service, source, tags, string(redactedContent))
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diagnostic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+func newMessage(name, sourceType, source, service, content string) *message.Message {
+	logSource := config.NewLogSource(name, &config.LogsConfig{Type: sourceType, Source: source, Service: service, Tags: []string{"env:prod"}})
+	return message.NewMessageWithSource([]byte(content), message.StatusInfo, logSource)
+}
+
+func TestHandleMessageShouldForwardFormattedMessages(t *testing.T) {
+	receiver := NewBufferedMessageReceiver()
+	assert.False(t, receiver.IsEnabled())
+
+	lines, unsubscribe := receiver.Subscribe(&Filters{})
+	assert.True(t, receiver.IsEnabled())
+
+	msg := newMessage("nginx", config.FileType, "nginx", "web", "raw")
+	receiver.HandleMessage(msg, []byte("hello [masked]"))
+	line := <-lines
+	assert.Contains(t, line, "Integration Name: nginx")
+	assert.Contains(t, line, "Type: file")
+	assert.Contains(t, line, "Status: info")
+	assert.Contains(t, line, "Service: web")
+	assert.Contains(t, line, "Source: nginx")
+	assert.Contains(t, line, "Tags: env:prod")
+	assert.Contains(t, line, "Message: hello [masked]")
+
+	unsubscribe()
+	assert.False(t, receiver.IsEnabled())
+	receiver.HandleMessage(msg, []byte("hello"))
+	assert.Len(t, lines, 0)
+}
+
+func TestHandleMessageShouldApplyFilters(t *testing.T) {
+	receiver := NewBufferedMessageReceiver()
+	bySource, unsubscribe := receiver.Subscribe(&Filters{Source: "nginx"})
+	defer unsubscribe()
+	byService, unsubscribe := receiver.Subscribe(&Filters{Service: "db", Type: config.TCPType})
+	defer unsubscribe()
+
+	receiver.HandleMessage(newMessage("nginx", config.FileType, "nginx", "web", ""), []byte("1"))
+	receiver.HandleMessage(newMessage("postgres", config.FileType, "postgres", "db", ""), []byte("2"))
+	receiver.HandleMessage(newMessage("postgres", config.TCPType, "postgres", "db", ""), []byte("3"))
+
+	assert.Len(t, bySource, 1)
+	assert.Contains(t, <-bySource, "Message: 1")
+	assert.Len(t, byService, 1)
+	assert.Contains(t, <-byService, "Message: 3")
+}
+
+func TestHandleMessageShouldNotBlockOnSlowSubscribers(t *testing.T) {
+	receiver := NewBufferedMessageReceiver()
+	lines, unsubscribe := receiver.Subscribe(nil)
+	defer unsubscribe()
+
+	msg := newMessage("", config.FileType, "", "", "")
+	for i := 0; i < subscriberBufferSize+10; i++ {
+		receiver.HandleMessage(msg, []byte("hello"))
+	}
+	assert.Len(t, lines, subscriberBufferSize)
+}
@@ -0,0 +1,266 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diskqueue
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Queue buffers on disk the messages the next stage of the pipeline can not accept,
+// this happens when the intake is unreachable for longer than the in-memory buffers allow.
+// Messages are forwarded from memory as long as the next stage accepts them within the spill
+// delay, once a message has been written to disk, all the following ones are written to disk
+// as well until the disk is drained so that messages are always forwarded in the order they
+// were received.
+// The offsets of the messages written to disk are committed once they are sent, like the
+// ones forwarded from memory. When the disk buffer is full, the messages of the sources which
+// track their offsets wait for some space to be freed and the other ones are dropped.
+// At the next start, the messages left on disk by the sources which track their offsets are
+// discarded as their tailers read them again from the last committed offsets, the other ones
+// are forwarded.
+type Queue struct {
+	inputChan  chan *message.Message
+	outputChan chan *message.Message
+	sources    *config.LogSources
+	store      *store
+	spillDelay time.Duration
+	mutex      sync.Mutex
+	spilling   bool
+	notify     chan struct{}
+	freed      chan struct{}
+	stopping   chan struct{}
+	stop       chan struct{}
+	done       chan struct{}
+	replayDone chan struct{}
+	dropOnce   sync.Once
+}
+
+// New returns a new Queue storing its segments under the path of the configuration,
+// the messages read back from disk are attached to the source with the same name in sources.
+func New(inputChan, outputChan chan *message.Message, sources *config.LogSources, cfg *config.DiskBufferConfig) (*Queue, error) {
+	s, err := newStore(cfg.Path, cfg.MaxSize, cfg.MaxSizePerSource, cfg.SegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	return &Queue{
+		inputChan:  inputChan,
+		outputChan: outputChan,
+		sources:    sources,
+		store:      s,
+		spillDelay: cfg.SpillDelay,
+		notify:     make(chan struct{}, 1),
+		freed:      make(chan struct{}, 1),
+		stopping:   make(chan struct{}),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+		replayDone: make(chan struct{}),
+	}, nil
+}
+
+// Start starts the queue, the messages left on disk are forwarded first.
+func (q *Queue) Start() {
+	if !q.store.isEmpty() {
+		q.spilling = true
+		q.notifyReplay()
+	}
+	go q.run()
+	go q.replay()
+}
+
+// Stop stops the queue, the messages not forwarded yet are kept on disk,
+// this call blocks until inputChan is flushed.
+func (q *Queue) Stop() {
+	close(q.stopping)
+	close(q.inputChan)
+	<-q.done
+	close(q.stop)
+	<-q.replayDone
+	q.store.close()
+}
+
+// run forwards the messages to the next stage or to the disk.
+func (q *Queue) run() {
+	defer close(q.done)
+	for msg := range q.inputChan {
+		q.mutex.Lock()
+		spilling := q.spilling
+		q.mutex.Unlock()
+		// only run writes to the disk, which stays empty as long as the queue is not spilling
+		if !spilling && q.forward(msg) {
+			continue
+		}
+		q.spill(msg)
+		q.notifyReplay()
+	}
+}
+
+// forward sends a message to the next stage, returns false if the next stage
+// did not accept it within the spill delay.
+func (q *Queue) forward(msg *message.Message) bool {
+	select {
+	case q.outputChan <- msg:
+		return true
+	default:
+	}
+	timer := time.NewTimer(q.spillDelay)
+	defer timer.Stop()
+	select {
+	case q.outputChan <- msg:
+		return true
+	case <-timer.C:
+		// the next stage is falling behind
+		return false
+	}
+}
+
+// spill writes a message to disk. When the quotas are exceeded, the message waits for the
+// replay to free some space if its source tracks its offset, so that the tailer is slowed
+// down as if there was no disk buffer, it is dropped otherwise.
+func (q *Queue) spill(msg *message.Message) {
+	r := toRecord(msg)
+	for {
+		q.mutex.Lock()
+		q.spilling = true
+		err := q.store.write(r)
+		q.mutex.Unlock()
+		if err == nil {
+			metrics.DiskBufferSpilled.Add(1)
+			metrics.TlmDiskBufferSpilled.Inc()
+			return
+		}
+		if !isTracked(r) || (err != errFull && err != errQuotaExceeded) {
+			q.drop(msg, err)
+			return
+		}
+		q.notifyReplay()
+		select {
+		case <-q.freed:
+		case <-q.stopping:
+			// the offset of the message has not been committed, it is read again at the next start
+			return
+		}
+	}
+}
+
+func (q *Queue) drop(msg *message.Message, err error) {
+	metrics.DiskBufferDropped.Add(1)
+	metrics.TlmDiskBufferDropped.Inc()
+	msg.SourceMetrics().AddDropped()
+	q.dropOnce.Do(func() {
+		log.Warnf("Dropping logs, could not write them to the disk buffer: %v", err)
+	})
+}
+
+// replay forwards the messages stored on disk to the next stage.
+func (q *Queue) replay() {
+	defer close(q.replayDone)
+	for {
+		select {
+		case <-q.stop:
+			return
+		case <-q.notify:
+		}
+		for {
+			q.mutex.Lock()
+			r, ok := q.store.next()
+			if !ok {
+				// the disk has been drained, new messages can be forwarded from memory
+				q.spilling = false
+				q.mutex.Unlock()
+				break
+			}
+			recovered := q.store.isRecovered()
+			q.mutex.Unlock()
+
+			if recovered && isTracked(r) {
+				// the tailer of the source reads this message again from its last committed offset
+				q.ack()
+				continue
+			}
+
+			select {
+			case q.outputChan <- q.toMessage(r):
+			case <-q.stop:
+				return
+			}
+			q.ack()
+		}
+	}
+}
+
+// ack removes the message forwarded from the disk and wakes up the spill waiting for space.
+func (q *Queue) ack() {
+	q.mutex.Lock()
+	q.store.ack()
+	q.mutex.Unlock()
+	select {
+	case q.freed <- struct{}{}:
+	default:
+	}
+}
+
+func (q *Queue) notifyReplay() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// isTracked returns true if the source of the record commits its offsets,
+// the sources which do not, such as the network listeners, can not read their logs again.
+func isTracked(r *record) bool {
+	return r.Identifier != ""
+}
+
+// toRecord converts a message into a record, only the information required
+// to send the message and to commit its offset is kept.
+func toRecord(msg *message.Message) *record {
+	r := &record{
+		Content: msg.Content,
+		Status:  msg.GetStatus(),
+	}
+	if msg.Origin != nil {
+		r.Identifier = msg.Origin.Identifier
+		r.Offset = msg.Origin.Offset
+		if msg.Origin.LogSource != nil {
+			r.Source = msg.Origin.LogSource.Name
+			r.TailingMode = msg.Origin.LogSource.Config.TailingMode
+		}
+	}
+	return r
+}
+
+// toMessage converts a record back into a message, attached to the live source with
+// the same name so that it is accounted in the metrics of the source.
+func (q *Queue) toMessage(r *record) *message.Message {
+	source := q.findSource(r.Source)
+	if source == nil {
+		source = config.NewLogSource(r.Source, &config.LogsConfig{TailingMode: r.TailingMode})
+	}
+	origin := message.NewOrigin(source)
+	origin.Identifier = r.Identifier
+	origin.Offset = r.Offset
+	return message.NewMessage(r.Content, origin, r.Status)
+}
+
+// findSource returns the live source with the name, nil if there is none.
+func (q *Queue) findSource(name string) *config.LogSource {
+	if q.sources == nil || name == "" {
+		return nil
+	}
+	for _, source := range q.sources.GetSources() {
+		if source.Name == name {
+			return source
+		}
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diskqueue
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
+)
+
+func newTestMessage(content string, source *config.LogSource) *message.Message {
+	origin := message.NewOrigin(source)
+	origin.Identifier = "file:/var/log/foo.log"
+	origin.Offset = content
+	return message.NewMessage([]byte(content), origin, message.StatusError)
+}
+
+// newUntrackedTestMessage returns a message of a source which does not commit its offsets
+func newUntrackedTestMessage(content string, source *config.LogSource) *message.Message {
+	return message.NewMessage([]byte(content), message.NewOrigin(source), message.StatusInfo)
+}
+
+func TestQueueSpillsAndReplaysInOrder(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	cfg := &config.DiskBufferConfig{Path: path, MaxSize: 1024 * 1024, SegmentSize: 1024, SpillDelay: 10 * time.Millisecond}
+	source := config.NewLogSource("foo", &config.LogsConfig{TailingMode: "end"})
+
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message, 2)
+	q, err := New(inputChan, outputChan, nil, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	// the output is not consumed, the messages that do not fit in it are written to disk
+	for i := 0; i < 10; i++ {
+		inputChan <- newTestMessage(fmt.Sprintf("%d", i), source)
+	}
+
+	for i := 0; i < 10; i++ {
+		msg := <-outputChan
+		assert.Equal(t, fmt.Sprintf("%d", i), string(msg.Content))
+		assert.Equal(t, fmt.Sprintf("%d", i), msg.Origin.Offset)
+		assert.Equal(t, "file:/var/log/foo.log", msg.Origin.Identifier)
+		assert.Equal(t, "end", msg.Origin.LogSource.Config.TailingMode)
+		assert.Equal(t, message.StatusError, msg.GetStatus())
+	}
+
+	q.Stop()
+}
+
+func TestQueueDoesNotSpillWhenTheNextStageCatchesUp(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	cfg := &config.DiskBufferConfig{Path: path, MaxSize: 1024 * 1024, SegmentSize: 1024, SpillDelay: time.Minute}
+	source := config.NewLogSource("foo", &config.LogsConfig{})
+
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message)
+	q, err := New(inputChan, outputChan, nil, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	spilled := metrics.DiskBufferSpilled.Value()
+	go func() {
+		for i := 0; i < 3; i++ {
+			inputChan <- newTestMessage(fmt.Sprintf("%d", i), source)
+		}
+	}()
+	for i := 0; i < 3; i++ {
+		// the next stage is slow but accepts the messages within the spill delay
+		time.Sleep(10 * time.Millisecond)
+		msg := <-outputChan
+		assert.Equal(t, fmt.Sprintf("%d", i), string(msg.Content))
+	}
+	assert.Equal(t, spilled, metrics.DiskBufferSpilled.Value())
+
+	q.Stop()
+}
+
+func TestQueueAppliesBackpressureWhenFullForTrackedSources(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	source := config.NewLogSource("foo", &config.LogsConfig{})
+	recordSize, err := recordSizeOf(newTestMessage("0", source))
+	require.NoError(t, err)
+	// only two records fit on disk
+	cfg := &config.DiskBufferConfig{Path: path, MaxSize: 2 * recordSize, SegmentSize: 1024, SpillDelay: time.Millisecond}
+
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message)
+	q, err := New(inputChan, outputChan, nil, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	dropped := metrics.DiskBufferDropped.Value()
+	sent := make(chan struct{})
+	go func() {
+		for i := 0; i < 5; i++ {
+			inputChan <- newTestMessage(fmt.Sprintf("%d", i), source)
+		}
+		close(sent)
+	}()
+
+	// the queue blocks instead of dropping the messages which do not fit on disk
+	select {
+	case <-sent:
+		assert.FailNow(t, "the queue should block when the disk buffer is full")
+	case <-time.After(100 * time.Millisecond):
+	}
+	for i := 0; i < 5; i++ {
+		msg := <-outputChan
+		assert.Equal(t, fmt.Sprintf("%d", i), string(msg.Content))
+	}
+	<-sent
+	assert.Equal(t, dropped, metrics.DiskBufferDropped.Value())
+
+	q.Stop()
+}
+
+func TestQueueDropsUntrackedMessagesWhenFull(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	source := config.NewLogSource("foo", &config.LogsConfig{})
+	recordSize, err := recordSizeOf(newUntrackedTestMessage("0", source))
+	require.NoError(t, err)
+	cfg := &config.DiskBufferConfig{Path: path, MaxSize: 2 * recordSize, SegmentSize: 1024, SpillDelay: time.Millisecond}
+
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message)
+	q, err := New(inputChan, outputChan, nil, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	dropped := metrics.DiskBufferDropped.Value()
+	for i := 0; i < 5; i++ {
+		inputChan <- newUntrackedTestMessage(fmt.Sprintf("%d", i), source)
+	}
+	q.Stop()
+
+	// the first two messages fill the disk, the following ones are dropped
+	assert.Equal(t, dropped+3, metrics.DiskBufferDropped.Value())
+	assert.Equal(t, int64(3), source.Metrics.LogsDropped())
+}
+
+func recordSizeOf(msg *message.Message) (int64, error) {
+	payload, err := json.Marshal(toRecord(msg))
+	return int64(frameHeaderSize + len(payload)), err
+}
+
+func TestQueueKeepsUntrackedMessagesOnDiskAcrossRestarts(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	cfg := &config.DiskBufferConfig{Path: path, MaxSize: 1024 * 1024, SegmentSize: 1024, SpillDelay: time.Millisecond}
+	source := config.NewLogSource("foo", &config.LogsConfig{})
+
+	inputChan := make(chan *message.Message)
+	outputChan := make(chan *message.Message)
+	q, err := New(inputChan, outputChan, nil, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	// nothing consumes the output, all the messages go to disk
+	for i := 0; i < 5; i++ {
+		if i%2 == 0 {
+			inputChan <- newUntrackedTestMessage(fmt.Sprintf("%d", i), source)
+		} else {
+			inputChan <- newTestMessage(fmt.Sprintf("%d", i), source)
+		}
+	}
+	q.Stop()
+
+	sources := config.NewLogSources()
+	liveSource := config.NewLogSource("foo", &config.LogsConfig{})
+	sources.AddSource(liveSource)
+	inputChan = make(chan *message.Message)
+	outputChan = make(chan *message.Message)
+	q, err = New(inputChan, outputChan, sources, cfg)
+	require.NoError(t, err)
+	q.Start()
+
+	// the untracked messages left on disk are forwarded before the new ones, the tracked
+	// ones are discarded as their tailer reads them again from their last committed offset
+	go func() {
+		inputChan <- newTestMessage("5", liveSource)
+	}()
+	for _, expected := range []string{"0", "2", "4", "5"} {
+		msg := <-outputChan
+		assert.Equal(t, expected, string(msg.Content))
+		assert.Equal(t, liveSource, msg.Origin.LogSource)
+	}
+
+	q.Stop()
+}
@@ -0,0 +1,358 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diskqueue
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	// segmentExtension is the extension of the segment files
+	segmentExtension = ".seg"
+	// frameHeaderSize is the size of the header of a record, made of its length and its checksum
+	frameHeaderSize = 8
+	// maxRecordSize bounds the size of a record to detect corrupted lengths
+	maxRecordSize = 16 * 1024 * 1024
+)
+
+var (
+	errFull          = errors.New("disk buffer is full")
+	errQuotaExceeded = errors.New("disk buffer quota of the source is exceeded")
+	errCorrupted     = errors.New("corrupted record")
+)
+
+// record is the representation of a log stored on disk.
+type record struct {
+	Content     []byte `json:"content"`
+	Status      string `json:"status"`
+	Source      string `json:"source"`
+	Identifier  string `json:"identifier"`
+	Offset      string `json:"offset"`
+	TailingMode string `json:"tailing_mode"`
+}
+
+// segment is a file holding a sequence of records.
+type segment struct {
+	id      uint64
+	size    int64
+	sources map[string]int64
+	// recovered is true for the segments written before the store was opened
+	recovered bool
+}
+
+// store persists records in segment files and reads them back in the order they were written.
+// Each record is framed with its length and a CRC32 checksum so that a corrupted or partially
+// written segment is detected, the records following a corruption in a segment are discarded.
+// The store is not thread-safe.
+type store struct {
+	path             string
+	maxSize          int64
+	maxSizePerSource int64
+	segmentSize      int64
+
+	segments      []*segment
+	size          int64
+	sizePerSource map[string]int64
+
+	writer *os.File
+	reader *os.File
+
+	pending     *record
+	pendingSize int64
+}
+
+// newStore opens the store located at path, creating it if needed,
+// the records already present on disk are read back first.
+func newStore(path string, maxSize, maxSizePerSource, segmentSize int64) (*store, error) {
+	if err := os.MkdirAll(path, 0700); err != nil {
+		return nil, err
+	}
+	s := &store{
+		path:             path,
+		maxSize:          maxSize,
+		maxSizePerSource: maxSizePerSource,
+		segmentSize:      segmentSize,
+		sizePerSource:    make(map[string]int64),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// load scans the existing segments to rebuild the size accounting.
+func (s *store) load() error {
+	files, err := ioutil.ReadDir(s.path)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || !strings.HasSuffix(file.Name(), segmentExtension) {
+			continue
+		}
+		id, err := strconv.ParseUint(strings.TrimSuffix(file.Name(), segmentExtension), 10, 64)
+		if err != nil {
+			continue
+		}
+		seg, err := s.scan(id)
+		if err != nil {
+			log.Warnf("Could not load disk buffer segment %s, discarding it: %v", file.Name(), err)
+			os.Remove(s.segmentPath(id)) //nolint:errcheck
+			continue
+		}
+		if seg.size == 0 {
+			os.Remove(s.segmentPath(id)) //nolint:errcheck
+			continue
+		}
+		s.segments = append(s.segments, seg)
+		s.size += seg.size
+		for source, size := range seg.sources {
+			s.sizePerSource[source] += size
+		}
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].id < s.segments[j].id })
+	return nil
+}
+
+// scan reads all the valid records of a segment, the segment is truncated
+// after the last valid record so that new records are never written after a corruption.
+func (s *store) scan(id uint64) (*segment, error) {
+	f, err := os.OpenFile(s.segmentPath(id), os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	seg := &segment{id: id, sources: make(map[string]int64), recovered: true}
+	for {
+		r, size, err := readRecord(f)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Warnf("Disk buffer segment %s is corrupted after %d bytes: %v", s.segmentPath(id), seg.size, err)
+			if err := f.Truncate(seg.size); err != nil {
+				return nil, err
+			}
+			break
+		}
+		seg.size += size
+		seg.sources[r.Source] += size
+	}
+	return seg, nil
+}
+
+// write appends a record to the last segment, creating a new segment when it is full,
+// returns an error if the record does not fit in the quotas.
+func (s *store) write(r *record) error {
+	payload, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	size := int64(frameHeaderSize + len(payload))
+	if s.size+size > s.maxSize {
+		return errFull
+	}
+	if s.maxSizePerSource > 0 && s.sizePerSource[r.Source]+size > s.maxSizePerSource {
+		return errQuotaExceeded
+	}
+
+	last := s.lastSegment()
+	if s.writer == nil || last == nil || (last.size > 0 && last.size+size > s.segmentSize) {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		last = s.lastSegment()
+	}
+
+	frame := make([]byte, size)
+	binary.BigEndian.PutUint32(frame[0:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(frame[4:8], crc32.ChecksumIEEE(payload))
+	copy(frame[frameHeaderSize:], payload)
+	if _, err := s.writer.Write(frame); err != nil {
+		return err
+	}
+
+	last.size += size
+	last.sources[r.Source] += size
+	s.size += size
+	s.sizePerSource[r.Source] += size
+	return nil
+}
+
+// rotate closes the current segment and creates a new one.
+func (s *store) rotate() error {
+	if s.writer != nil {
+		s.writer.Close()
+		s.writer = nil
+	}
+	var id uint64
+	if last := s.lastSegment(); last != nil {
+		id = last.id + 1
+	}
+	f, err := os.OpenFile(s.segmentPath(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	s.writer = f
+	s.segments = append(s.segments, &segment{id: id, sources: make(map[string]int64)})
+	return nil
+}
+
+// next returns the oldest record not acknowledged yet, the same record is returned
+// until it is acknowledged. Returns false if there is no record to read.
+func (s *store) next() (*record, bool) {
+	if s.pending != nil {
+		return s.pending, true
+	}
+	for len(s.segments) > 0 {
+		first := s.segments[0]
+		if s.reader == nil {
+			f, err := os.Open(s.segmentPath(first.id))
+			if err != nil {
+				log.Warnf("Could not open disk buffer segment %s, discarding it: %v", s.segmentPath(first.id), err)
+				s.dropFirstSegment()
+				continue
+			}
+			s.reader = f
+		}
+		r, size, err := readRecord(s.reader)
+		if err == nil {
+			s.pending = r
+			s.pendingSize = size
+			return r, true
+		}
+		if err == io.EOF && s.isWriting(first) {
+			// all the records written so far have been read
+			return nil, false
+		}
+		if err != io.EOF {
+			log.Warnf("Disk buffer segment %s is corrupted, discarding the remaining records: %v", s.segmentPath(first.id), err)
+		}
+		s.dropFirstSegment()
+	}
+	return nil, false
+}
+
+// isRecovered returns true if the record returned by next was written before the store was opened.
+func (s *store) isRecovered() bool {
+	return s.pending != nil && len(s.segments) > 0 && s.segments[0].recovered
+}
+
+// ack removes the record returned by next from the store.
+func (s *store) ack() {
+	if s.pending == nil || len(s.segments) == 0 {
+		return
+	}
+	first := s.segments[0]
+	first.size -= s.pendingSize
+	first.sources[s.pending.Source] -= s.pendingSize
+	s.size -= s.pendingSize
+	s.sizePerSource[s.pending.Source] -= s.pendingSize
+	if s.sizePerSource[s.pending.Source] <= 0 {
+		delete(s.sizePerSource, s.pending.Source)
+	}
+	s.pending = nil
+	s.pendingSize = 0
+	if first.size <= 0 {
+		// the segment has been fully read, no need to keep it on disk
+		s.dropFirstSegment()
+	}
+}
+
+// dropFirstSegment closes and removes the oldest segment along with its remaining records.
+func (s *store) dropFirstSegment() {
+	first := s.segments[0]
+	if s.reader != nil {
+		s.reader.Close()
+		s.reader = nil
+	}
+	if s.isWriting(first) {
+		s.writer.Close()
+		s.writer = nil
+	}
+	s.size -= first.size
+	for source, size := range first.sources {
+		if s.sizePerSource[source] -= size; s.sizePerSource[source] <= 0 {
+			delete(s.sizePerSource, source)
+		}
+	}
+	s.segments = s.segments[1:]
+	if err := os.Remove(s.segmentPath(first.id)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("Could not remove disk buffer segment %s: %v", s.segmentPath(first.id), err)
+	}
+}
+
+// isEmpty returns true if all the records have been acknowledged.
+func (s *store) isEmpty() bool {
+	return s.size <= 0
+}
+
+// close closes the open segments, the records are kept on disk.
+func (s *store) close() {
+	if s.reader != nil {
+		s.reader.Close()
+		s.reader = nil
+	}
+	if s.writer != nil {
+		s.writer.Close()
+		s.writer = nil
+	}
+}
+
+func (s *store) lastSegment() *segment {
+	if len(s.segments) == 0 {
+		return nil
+	}
+	return s.segments[len(s.segments)-1]
+}
+
+func (s *store) isWriting(seg *segment) bool {
+	return s.writer != nil && seg == s.lastSegment()
+}
+
+func (s *store) segmentPath(id uint64) string {
+	return filepath.Join(s.path, fmt.Sprintf("%020d%s", id, segmentExtension))
+}
+
+// readRecord reads a record and returns its size on disk.
+func readRecord(reader io.Reader) (*record, int64, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, 0, errCorrupted
+		}
+		return nil, 0, err
+	}
+	length := binary.BigEndian.Uint32(header[0:4])
+	if length > maxRecordSize {
+		return nil, 0, errCorrupted
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		return nil, 0, errCorrupted
+	}
+	if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:8]) {
+		return nil, 0, errCorrupted
+	}
+	r := &record{}
+	if err := json.Unmarshal(payload, r); err != nil {
+		return nil, 0, errCorrupted
+	}
+	return r, int64(frameHeaderSize + length), nil
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package diskqueue
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestStore(t *testing.T, path string, maxSize, maxSizePerSource, segmentSize int64) *store {
+	s, err := newStore(path, maxSize, maxSizePerSource, segmentSize)
+	require.NoError(t, err)
+	return s
+}
+
+func readAll(s *store) []string {
+	var contents []string
+	for {
+		r, ok := s.next()
+		if !ok {
+			return contents
+		}
+		contents = append(contents, string(r.Content))
+		s.ack()
+	}
+}
+
+func TestStoreReadsRecordsInOrderAcrossSegments(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	s := newTestStore(t, path, 1024*1024, 0, 100)
+	for _, content := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, s.write(&record{Content: []byte(content), Source: "foo"}))
+	}
+	assert.True(t, len(s.segments) > 1)
+	assert.False(t, s.isEmpty())
+
+	// the same record is returned until it is acknowledged
+	r, ok := s.next()
+	require.True(t, ok)
+	assert.Equal(t, "a", string(r.Content))
+	r, ok = s.next()
+	require.True(t, ok)
+	assert.Equal(t, "a", string(r.Content))
+	s.ack()
+
+	assert.Equal(t, []string{"b", "c", "d"}, readAll(s))
+	assert.True(t, s.isEmpty())
+
+	// the segments fully read are removed
+	files, err := ioutil.ReadDir(path)
+	require.NoError(t, err)
+	assert.Len(t, files, 0)
+
+	// records written after the store was drained are read as well
+	require.NoError(t, s.write(&record{Content: []byte("e"), Source: "foo"}))
+	assert.Equal(t, []string{"e"}, readAll(s))
+	s.close()
+}
+
+func TestStoreQuotas(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	s := newTestStore(t, path, 250, 150, 1024)
+	defer s.close()
+
+	require.NoError(t, s.write(&record{Content: []byte("a"), Source: "foo"}))
+	assert.Equal(t, errQuotaExceeded, s.write(&record{Content: []byte("b"), Source: "foo"}))
+	require.NoError(t, s.write(&record{Content: []byte("c"), Source: "bar"}))
+	assert.Equal(t, errFull, s.write(&record{Content: []byte("d"), Source: "baz"}))
+
+	// the quotas are released once the records are read
+	assert.Equal(t, []string{"a", "c"}, readAll(s))
+	require.NoError(t, s.write(&record{Content: []byte("b"), Source: "foo"}))
+}
+
+func TestStoreReloadsRecordsAndDiscardsCorruptedOnes(t *testing.T) {
+	path, err := ioutil.TempDir("", "diskqueue")
+	require.NoError(t, err)
+	defer os.RemoveAll(path)
+
+	s := newTestStore(t, path, 1024*1024, 0, 1024*1024)
+	for _, content := range []string{"a", "b", "c"} {
+		require.NoError(t, s.write(&record{Content: []byte(content), Source: "foo"}))
+	}
+	s.close()
+
+	// simulate a partial write of the last record
+	segmentPath := filepath.Join(path, "00000000000000000000.seg")
+	info, err := os.Stat(segmentPath)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(segmentPath, info.Size()-3))
+
+	s = newTestStore(t, path, 1024*1024, 0, 1024*1024)
+	require.NoError(t, s.write(&record{Content: []byte("d"), Source: "foo"}))
+	assert.Equal(t, []string{"a", "b", "d"}, readAll(s))
+	s.close()
+
+	// a corrupted checksum discards the rest of the segment
+	s = newTestStore(t, path, 1024*1024, 0, 1024*1024)
+	for _, content := range []string{"e", "f"} {
+		require.NoError(t, s.write(&record{Content: []byte(content), Source: "foo"}))
+	}
+	segmentPath = s.segmentPath(s.lastSegment().id)
+	s.close()
+	data, err := ioutil.ReadFile(segmentPath)
+	require.NoError(t, err)
+	data[5] ^= 0xff
+	require.NoError(t, ioutil.WriteFile(segmentPath, data, 0600))
+
+	s = newTestStore(t, path, 1024*1024, 0, 1024*1024)
+	assert.True(t, s.isEmpty())
+	assert.Empty(t, readAll(s))
+	s.close()
+}
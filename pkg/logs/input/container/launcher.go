@@ -26,14 +26,16 @@ import (
 // If none of those volumes are mounted, returns a lazy docker launcher with a retrier to handle the cases
 // where docker is started after the agent.
 // dockerReadTimeout is a configurable read timeout for the docker client.
-func NewLauncher(collectAll bool, collectFromFiles bool, dockerReadTimeout time.Duration, sources *config.LogSources, services *service.Services, pipelineProvider pipeline.Provider, registry auditor.Registry) restart.Restartable {
+// collectFromAPI lets the kubernetes launcher stream the logs through the API server
+// when '/var/log/pods' is not mounted.
+func NewLauncher(collectAll bool, collectFromFiles bool, collectFromAPI bool, dockerReadTimeout time.Duration, sources *config.LogSources, services *service.Services, pipelineProvider pipeline.Provider, registry auditor.Registry) restart.Restartable {
 	var (
 		launcher restart.Restartable
 		err      error
 	)
 
 	if collectFromFiles {
-		launcher, err = kubernetes.NewLauncher(sources, services, collectAll)
+		launcher, err = kubernetes.NewLauncher(sources, services, collectAll, collectFromAPI, pipelineProvider, registry)
 		if err == nil {
 			log.Info("Kubernetes launcher initialized")
 			return launcher
@@ -54,7 +56,7 @@ func NewLauncher(collectAll bool, collectFromFiles bool, dockerReadTimeout time.
 		}
 		log.Infof("Could not setup the docker launcher: %v", err)
 
-		launcher, err = kubernetes.NewLauncher(sources, services, collectAll)
+		launcher, err = kubernetes.NewLauncher(sources, services, collectAll, collectFromAPI, pipelineProvider, registry)
 		if err == nil {
 			log.Info("Kubernetes launcher initialized")
 			return launcher
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/status"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -21,6 +22,9 @@ import (
 // files are tailed
 const openFilesLimitWarningType = "open_files_limit_warning"
 
+// recursiveWildcard matches any number of directories in a path
+const recursiveWildcard = "**"
+
 // File represents a file to tail
 type File struct {
 	Path string
@@ -54,9 +58,9 @@ func NewProvider(filesLimit int) *Provider {
 }
 
 // FilesToTail returns all the Files matching paths in sources,
-// it cannot return more than filesLimit Files.
-// For now, there is no way to prioritize specific Files over others,
-// they are just returned in reverse lexicographical order, see `searchFiles`
+// it cannot return more than filesLimit Files, nor more than the open files limit of a source.
+// The Files of a source are prioritized according to its file selection policy,
+// by default they are returned in reverse lexicographical order, see `searchFiles`
 func (p *Provider) FilesToTail(sources []*config.LogSource) []*File {
 	var filesToTail []*File
 	shouldLogErrors := p.shouldLogErrors
@@ -77,7 +81,8 @@ func (p *Provider) FilesToTail(sources []*config.LogSource) []*File {
 			}
 			continue
 		}
-		for j := 0; j < len(files) && len(filesToTail) < p.filesLimit; j++ {
+		sourceLimit := source.Config.OpenFilesLimit
+		for j := 0; j < len(files) && len(filesToTail) < p.filesLimit && (sourceLimit == 0 || tailedFileCounter < sourceLimit); j++ {
 			file := files[j]
 			filesToTail = append(filesToTail, file)
 			tailedFileCounter++
@@ -125,9 +130,10 @@ func (p *Provider) CollectFiles(source *config.LogSource) ([]*File, error) {
 	}
 }
 
-// searchFiles returns all the files matching the source path pattern.
+// searchFiles returns all the files matching the source path pattern,
+// the files resolving to the same file through symlinks are returned only once.
 func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*File, error) {
-	paths, err := filepath.Glob(pattern)
+	paths, err := glob(pattern)
 	if err != nil {
 		return nil, fmt.Errorf("malformed pattern, could not find any file: %s", pattern)
 	}
@@ -151,11 +157,12 @@ func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*Fil
 	sort.SliceStable(paths, func(i, j int) bool {
 		return filepath.Base(paths[i]) > filepath.Base(paths[j])
 	})
+	sortByModTime(paths, source.Config.FileSelectionPolicy)
 
 	// Resolve excluded path(s)
 	excludedPaths := make(map[string]int)
 	for _, excludePattern := range source.Config.ExcludePaths {
-		excludedGlob, err := filepath.Glob(excludePattern)
+		excludedGlob, err := glob(excludePattern)
 		if err != nil {
 			return nil, fmt.Errorf("malformed exclusion pattern: %s, %s", excludePattern, err)
 		}
@@ -168,14 +175,115 @@ func (p *Provider) searchFiles(pattern string, source *config.LogSource) ([]*Fil
 		}
 	}
 
+	resolvedPaths := make(map[string]bool)
 	for _, path := range paths {
-		if excludedPaths[path] == 0 {
-			files = append(files, NewFile(path, source, true))
+		if excludedPaths[path] != 0 {
+			continue
 		}
+		if resolvedPath, err := filepath.EvalSymlinks(path); err == nil {
+			if resolvedPaths[resolvedPath] {
+				log.Debugf("Skipping %s, it resolves to an already collected file: %s", path, resolvedPath)
+				continue
+			}
+			resolvedPaths[resolvedPath] = true
+		}
+		files = append(files, NewFile(path, source, true))
 	}
 	return files, nil
 }
 
+// sortByModTime sorts the paths by modification time according to the policy,
+// the order is left unchanged for the other policies.
+func sortByModTime(paths []string, policy string) {
+	if policy != config.SelectNewest && policy != config.SelectOldest {
+		return
+	}
+	modTimes := make(map[string]int64, len(paths))
+	for _, path := range paths {
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime().UnixNano()
+		}
+	}
+	sort.SliceStable(paths, func(i, j int) bool {
+		if policy == config.SelectNewest {
+			return modTimes[paths[i]] > modTimes[paths[j]]
+		}
+		return modTimes[paths[i]] < modTimes[paths[j]]
+	})
+}
+
+// glob returns the files matching the pattern, in addition to the syntax of filepath.Match
+// the pattern supports '**' to match any number of directories.
+func glob(pattern string) ([]string, error) {
+	if !strings.Contains(pattern, recursiveWildcard) {
+		return filepath.Glob(pattern)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+
+	// walk from the directories matching the part of the pattern before the first '**'
+	segments := strings.Split(pattern, string(filepath.Separator))
+	i := 0
+	for i < len(segments) && segments[i] != recursiveWildcard {
+		i++
+	}
+	root := strings.Join(segments[:i], string(filepath.Separator))
+	if root == "" && i > 0 {
+		root = string(filepath.Separator)
+	} else if root == "" {
+		root = "."
+	}
+	roots, err := filepath.Glob(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, root := range roots {
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error { //nolint:errcheck
+			if err != nil || info.IsDir() {
+				// skip the directories that can not be read
+				return nil
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				// only follow the symlinks to regular files
+				if info, err = os.Stat(path); err != nil || info.IsDir() {
+					return nil
+				}
+			}
+			if matchSegments(segments, strings.Split(path, string(filepath.Separator))) {
+				paths = append(paths, path)
+			}
+			return nil
+		})
+	}
+	return paths, nil
+}
+
+// matchSegments returns true if the path segments match the pattern segments,
+// a '**' pattern segment matches zero or more path segments.
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == recursiveWildcard {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(path) == 0 {
+			return false
+		}
+		if matched, _ := filepath.Match(pattern[0], path[0]); !matched {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
+
 // exists returns true if the file at path filePath exists
 // Note: we can't rely on os.IsNotExist for windows, so we check error nullity.
 // As we're tailing with *, the error is related to the path being malformed.
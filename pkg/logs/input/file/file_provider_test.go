@@ -11,7 +11,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -272,6 +274,97 @@ func (suite *ProviderTestSuite) TestExcludePath() {
 	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[2].Path)
 }
 
+func (suite *ProviderTestSuite) TestRecursiveWildcard() {
+	path := fmt.Sprintf("%s/3/4", suite.testDir)
+	err := os.MkdirAll(path, os.ModePerm)
+	suite.Nil(err)
+	_, err = os.Create(fmt.Sprintf("%s/3/4/5.log", suite.testDir))
+	suite.Nil(err)
+
+	fileProvider := NewProvider(10)
+	logSources := suite.newLogSources(fmt.Sprintf("%s/**/*.log", suite.testDir))
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(6, len(files))
+	suite.Equal(fmt.Sprintf("%s/3/4/5.log", suite.testDir), files[0].Path)
+
+	logSources = suite.newLogSources(fmt.Sprintf("%s/3/**/5.log", suite.testDir))
+	files = fileProvider.FilesToTail(logSources)
+	suite.Equal(1, len(files))
+	suite.Equal(fmt.Sprintf("%s/3/4/5.log", suite.testDir), files[0].Path)
+}
+
+func (suite *ProviderTestSuite) TestSymlinksAreResolved() {
+	err := os.Symlink(fmt.Sprintf("%s/1/3.log", suite.testDir), fmt.Sprintf("%s/1/4.log", suite.testDir))
+	suite.Nil(err)
+
+	fileProvider := NewProvider(10)
+	logSources := suite.newLogSources(fmt.Sprintf("%s/1/*.log", suite.testDir))
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(3, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/4.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/1/2.log", suite.testDir), files[1].Path)
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[2].Path)
+}
+
+func (suite *ProviderTestSuite) TestOpenFilesLimitPerSource() {
+	fileProvider := NewProvider(10)
+	logSources := []*config.LogSource{
+		config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/1/*.log", suite.testDir), OpenFilesLimit: 1}),
+		config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/2/*.log", suite.testDir)}),
+	}
+	status.InitStatus(config.CreateSources(logSources))
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(3, len(files))
+	suite.Equal([]string{"1 files tailed out of 3 files matching"}, logSources[0].Messages.GetMessages())
+	suite.Equal([]string{"2 files tailed out of 2 files matching"}, logSources[1].Messages.GetMessages())
+}
+
+func (suite *ProviderTestSuite) TestFileSelectionPolicy() {
+	now := time.Now()
+	for i, name := range []string{"1.log", "2.log", "3.log"} {
+		modTime := now.Add(time.Duration(i) * time.Hour)
+		if name == "2.log" {
+			modTime = now.Add(-time.Hour)
+		}
+		err := os.Chtimes(fmt.Sprintf("%s/1/%s", suite.testDir, name), modTime, modTime)
+		suite.Nil(err)
+	}
+
+	fileProvider := NewProvider(10)
+	logSources := []*config.LogSource{config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/1/*.log", suite.testDir), FileSelectionPolicy: config.SelectOldest})}
+	files := fileProvider.FilesToTail(logSources)
+	suite.Equal(3, len(files))
+	suite.Equal(fmt.Sprintf("%s/1/2.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[1].Path)
+	suite.Equal(fmt.Sprintf("%s/1/3.log", suite.testDir), files[2].Path)
+
+	logSources[0].Config.FileSelectionPolicy = config.SelectNewest
+	files = fileProvider.FilesToTail(logSources)
+	suite.Equal(fmt.Sprintf("%s/1/3.log", suite.testDir), files[0].Path)
+	suite.Equal(fmt.Sprintf("%s/1/1.log", suite.testDir), files[1].Path)
+	suite.Equal(fmt.Sprintf("%s/1/2.log", suite.testDir), files[2].Path)
+}
+
+func TestMatchSegments(t *testing.T) {
+	for _, tc := range []struct {
+		pattern string
+		path    string
+		match   bool
+	}{
+		{"/var/log/**/*.log", "/var/log/foo.log", true},
+		{"/var/log/**/*.log", "/var/log/a/b/foo.log", true},
+		{"/var/log/**/*.log", "/var/log/a/b/foo.txt", false},
+		{"/var/log/**", "/var/log/a/foo.txt", true},
+		{"/var/**/app/*.log", "/var/log/app/foo.log", true},
+		{"/var/**/app/*.log", "/var/log/other/foo.log", false},
+	} {
+		match := matchSegments(strings.Split(tc.pattern, "/"), strings.Split(tc.path, "/"))
+		if match != tc.match {
+			t.Errorf("expected %v for pattern %s and path %s", tc.match, tc.pattern, tc.path)
+		}
+	}
+}
+
 func TestProviderTestSuite(t *testing.T) {
 	suite.Run(t, new(ProviderTestSuite))
 }
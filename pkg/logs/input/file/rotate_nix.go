@@ -8,6 +8,7 @@
 package file
 
 import (
+	"bytes"
 	"os"
 )
 
@@ -16,7 +17,9 @@ import (
 // - renamed and recreated
 // - removed and recreated
 // - truncated
-func DidRotate(file *os.File, lastReadOffset int64) (bool, error) {
+// - truncated and written again past the last read offset,
+//   in which case its first bytes do not match the signature anymore
+func DidRotate(file *os.File, lastReadOffset int64, signature []byte) (bool, error) {
 	f, err := openFile(file.Name())
 	defer f.Close()
 	if err != nil {
@@ -35,6 +38,51 @@ func DidRotate(file *os.File, lastReadOffset int64) (bool, error) {
 
 	recreated := !os.SameFile(fi1, fi2)
 	truncated := fi1.Size() < lastReadOffset
+	if recreated || truncated {
+		return true, nil
+	}
+
+	return !hasSignature(f, signature), nil
+}
+
+// DidRename returns true if the file at path is the file that was tailed before a rotation,
+// either because it has been renamed or because it is a copy made before a truncation.
+func DidRename(file *os.File, signature []byte, path string) bool {
+	if file == nil {
+		return false
+	}
+	f, err := openFile(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
 
-	return recreated || truncated, nil
+	fi1, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	if fi2, err := file.Stat(); err == nil && os.SameFile(fi1, fi2) {
+		return true
+	}
+	return len(signature) > 0 && hasSignature(f, signature)
+}
+
+// hasSignature returns true if the file starts with the signature.
+func hasSignature(f *os.File, signature []byte) bool {
+	if len(signature) == 0 {
+		return true
+	}
+	buf := make([]byte, len(signature))
+	n, _ := f.ReadAt(buf, 0)
+	return bytes.Equal(buf[:n], signature)
+}
+
+// readSignature returns the first bytes of the file up to offset.
+func readSignature(f *os.File, offset int64) []byte {
+	if offset > signatureSize {
+		offset = signatureSize
+	}
+	buf := make([]byte, offset)
+	n, _ := f.ReadAt(buf, 0)
+	return buf[:n]
 }
@@ -13,6 +13,12 @@ import (
 
 // DidRotate is not implemented on windows, log rotations are handled by the
 // tailer for now.
-func DidRotate(file *os.File, lastReadOffset int64) (bool, error) {
+func DidRotate(file *os.File, lastReadOffset int64, signature []byte) (bool, error) {
 	return false, nil
 }
+
+// DidRename is not implemented on windows, log rotations are handled by the
+// tailer for now.
+func DidRename(file *os.File, signature []byte, path string) bool {
+	return false
+}
@@ -6,6 +6,7 @@
 package file
 
 import (
+	"io"
 	"sync/atomic"
 	"time"
 
@@ -31,6 +32,7 @@ type Scanner struct {
 	tailingLimit        int
 	fileProvider        *Provider
 	tailers             map[string]*Tailer
+	rotatedTailers      []*Tailer
 	registry            auditor.Registry
 	tailerSleepDuration time.Duration
 	stop                chan struct{}
@@ -100,47 +102,65 @@ func (s *Scanner) cleanup() {
 // its tailer will keep tailing the rotated file.
 // The Scanner needs to stop that previous tailer,
 // and start a new one for the new file.
+// When the rotated file matches the path of a source as well,
+// it is tailed from where the previous tailer left it.
 func (s *Scanner) scan() {
 	files := s.fileProvider.FilesToTail(s.activeSources)
 	filesTailed := make(map[string]bool)
-	tailersLen := len(s.tailers)
+	s.pruneRotatedTailers()
 
+	// look for the rotated files first so that a file renamed by a rotation
+	// is recognized whatever its position in the list
+	rotatedTailers := make(map[string]*Tailer)
 	for _, file := range files {
 		tailer, isTailed := s.tailers[file.Path]
-		if isTailed && atomic.LoadInt32(&tailer.shouldStop) != 0 {
+		if !isTailed || atomic.LoadInt32(&tailer.shouldStop) != 0 {
 			// skip this tailer as it must be stopped
 			continue
 		}
-		if !isTailed && tailersLen >= s.tailingLimit {
-			// can't create new tailer because tailingLimit is reached
+		didRotate, err := DidRotate(tailer.file, tailer.GetReadOffset(), tailer.getSignature())
+		if err != nil {
 			continue
 		}
-
-		if !isTailed && tailersLen < s.tailingLimit {
-			// create a new tailer tailing from the beginning of the file if no offset has been recorded
-			succeeded := s.startNewTailer(file, config.Beginning)
-			if !succeeded {
-				// the setup failed, let's try to tail this file in the next scan
-				continue
-			}
-			tailersLen++
-			filesTailed[file.Path] = true
+		if didRotate {
+			log.Info("Log rotation happened to ", tailer.path)
+			tailer.StopAfterFileRotation()
+			s.rotatedTailers = append(s.rotatedTailers, tailer)
+			rotatedTailers[file.Path] = tailer
+			delete(s.tailers, file.Path)
 			continue
 		}
+		filesTailed[file.Path] = true
+	}
 
-		didRotate, err := DidRotate(tailer.file, tailer.GetReadOffset())
-		if err != nil {
+	tailersLen := len(s.tailers) + len(rotatedTailers)
+	for _, file := range files {
+		if _, isTailed := s.tailers[file.Path]; isTailed {
 			continue
 		}
-		if didRotate {
-			// restart tailer because of file-rotation on file
-			succeeded := s.restartTailerAfterFileRotation(tailer, file)
-			if !succeeded {
-				// the setup failed, let's try to tail this file in the next scan
-				continue
-			}
+		rotatedTailer, didRotate := rotatedTailers[file.Path]
+		if !didRotate && tailersLen >= s.tailingLimit {
+			// can't create new tailer because tailingLimit is reached
+			continue
 		}
 
+		var succeeded bool
+		if renamedTailer := s.renamedTailer(file); renamedTailer != nil {
+			succeeded = s.resumeTailerAfterRename(renamedTailer, file)
+		} else if didRotate {
+			// restart tailer because of file-rotation on file
+			succeeded = s.restartTailerAfterFileRotation(rotatedTailer, file)
+		} else {
+			// create a new tailer tailing from the beginning of the file if no offset has been recorded
+			succeeded = s.startNewTailer(file, config.Beginning)
+		}
+		if !succeeded {
+			// the setup failed, let's try to tail this file in the next scan
+			continue
+		}
+		if !didRotate {
+			tailersLen++
+		}
 		filesTailed[file.Path] = true
 	}
 
@@ -178,11 +198,16 @@ func (s *Scanner) launchTailers(source *config.LogSource) {
 		log.Warnf("Could not collect files: %v", err)
 		return
 	}
+	tailed := 0
 	for _, file := range files {
 		if len(s.tailers) >= s.tailingLimit {
 			return
 		}
+		if source.Config.OpenFilesLimit > 0 && tailed >= source.Config.OpenFilesLimit {
+			return
+		}
 		if _, isTailed := s.tailers[file.Path]; isTailed {
+			tailed++
 			continue
 		}
 
@@ -194,7 +219,9 @@ func (s *Scanner) launchTailers(source *config.LogSource) {
 			// FIXME: better detect a source that has been generated from a service discovery.
 			mode = config.Beginning
 		}
-		s.startNewTailer(file, mode)
+		if s.startNewTailer(file, mode) {
+			tailed++
+		}
 	}
 }
 
@@ -250,11 +277,9 @@ func (s *Scanner) stopTailer(tailer *Tailer) {
 	delete(s.tailers, tailer.path)
 }
 
-// restartTailer safely stops tailer and starts a new one
+// restartTailerAfterFileRotation starts a new tailer for the file created after a rotation,
 // returns true if the new tailer is up and running, false if an error occurred
 func (s *Scanner) restartTailerAfterFileRotation(tailer *Tailer, file *File) bool {
-	log.Info("Log rotation happened to ", tailer.path)
-	tailer.StopAfterFileRotation()
 	tailer = s.createTailer(file, tailer.outputChan)
 	// force reading file from beginning since it has been log-rotated
 	err := tailer.StartFromBeginning()
@@ -266,6 +291,56 @@ func (s *Scanner) restartTailerAfterFileRotation(tailer *Tailer, file *File) boo
 	return true
 }
 
+// renamedTailer returns the tailer of a rotated file found at the path of file, if any.
+func (s *Scanner) renamedTailer(file *File) *Tailer {
+	for _, tailer := range s.rotatedTailers {
+		if tailer.path == file.Path || atomic.LoadInt32(&tailer.shouldStop) != 0 {
+			continue
+		}
+		if DidRename(tailer.file, tailer.getSignature(), file.Path) {
+			return tailer
+		}
+	}
+	return nil
+}
+
+// resumeTailerAfterRename stops the tailer of a rotated file and starts a new one
+// tailing the file at its new path from where the previous tailer left it,
+// returns true if the new tailer is up and running, false if an error occurred
+func (s *Scanner) resumeTailerAfterRename(renamedTailer *Tailer, file *File) bool {
+	log.Infof("%s has been renamed to %s, resuming tailing", renamedTailer.path, file.Path)
+	renamedTailer.StopAfterRename()
+	s.removeRotatedTailer(renamedTailer)
+	tailer := s.createTailer(file, renamedTailer.outputChan)
+	err := tailer.Start(renamedTailer.GetReadOffset(), io.SeekStart)
+	if err != nil {
+		log.Warn(err)
+		return false
+	}
+	s.tailers[file.Path] = tailer
+	return true
+}
+
+// pruneRotatedTailers forgets the tailers of the rotated files that are stopped.
+func (s *Scanner) pruneRotatedTailers() {
+	rotatedTailers := s.rotatedTailers[:0]
+	for _, tailer := range s.rotatedTailers {
+		if atomic.LoadInt32(&tailer.shouldStop) == 0 {
+			rotatedTailers = append(rotatedTailers, tailer)
+		}
+	}
+	s.rotatedTailers = rotatedTailers
+}
+
+func (s *Scanner) removeRotatedTailer(tailer *Tailer) {
+	for i, rotatedTailer := range s.rotatedTailers {
+		if rotatedTailer == tailer {
+			s.rotatedTailers = append(s.rotatedTailers[:i], s.rotatedTailers[i+1:]...)
+			return
+		}
+	}
+}
+
 // createTailer returns a new initialized tailer
 func (s *Scanner) createTailer(file *File, outputChan chan *message.Message) *Tailer {
 	return NewTailer(outputChan, file.Source, file.Path, s.tailerSleepDuration, file.IsWildcardPath)
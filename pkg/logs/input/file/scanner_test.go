@@ -165,6 +165,43 @@ func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncate() {
 	suite.Equal("third", string(msg.Content))
 }
 
+func (suite *ScannerTestSuite) TestScannerScanWithLogRotationCopyTruncateAndRewrite() {
+	s := suite.s
+	source := suite.source
+
+	var err error
+	var msg *message.Message
+
+	tailer := s.tailers[source.Config.Path]
+	_, err = suite.testFile.WriteString("hello world\n")
+	suite.Nil(err)
+	msg = <-suite.outputChan
+	suite.Equal("hello world", string(msg.Content))
+
+	// the file is truncated and written again past the last read offset
+	suite.testFile.Truncate(0)
+	suite.testFile.Seek(0, 0)
+	_, err = suite.testFile.WriteString("a brand new line\n")
+	suite.Nil(err)
+
+	s.scan()
+	newTailer := s.tailers[source.Config.Path]
+	suite.True(tailer != newTailer)
+
+	// the previous tailer may still forward the end of the line until it is stopped
+	for {
+		select {
+		case msg = <-suite.outputChan:
+			if string(msg.Content) == "a brand new line" {
+				return
+			}
+		case <-time.After(time.Second):
+			suite.Fail("the file was not tailed again from the beginning")
+			return
+		}
+	}
+}
+
 func (suite *ScannerTestSuite) TestScannerScanWithFileRemovedAndCreated() {
 	s := suite.s
 	tailerLen := len(s.tailers)
@@ -337,3 +374,55 @@ func TestScannerScanWithTooManyFiles(t *testing.T) {
 	scanner.scan()
 	assert.Equal(t, 2, len(scanner.tailers))
 }
+
+func TestScannerResumesRenamedFileAfterRotation(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "log-scanner-test-")
+	assert.Nil(t, err)
+	defer os.RemoveAll(testDir)
+
+	path := fmt.Sprintf("%s/app.log", testDir)
+	rotatedPath := fmt.Sprintf("%s/app.log.1", testDir)
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	pipelineProvider := mock.NewMockProvider()
+	outputChan := pipelineProvider.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.FileType, Path: fmt.Sprintf("%s/app.log*", testDir)})
+	scanner := NewScanner(config.NewLogSources(), 10, pipelineProvider, auditor.NewRegistry(), 10*time.Millisecond)
+	scanner.activeSources = append(scanner.activeSources, source)
+	status.InitStatus(config.CreateSources([]*config.LogSource{source}))
+	defer status.Clear()
+	defer scanner.cleanup()
+
+	scanner.scan()
+	_, err = file.WriteString("hello world\n")
+	assert.Nil(t, err)
+	msg := <-outputChan
+	assert.Equal(t, "hello world", string(msg.Content))
+
+	// rotate the file, the rotated file matches the source path as well
+	err = os.Rename(path, rotatedPath)
+	assert.Nil(t, err)
+	_, err = file.WriteString("hello again\n")
+	assert.Nil(t, err)
+	newFile, err := os.Create(path)
+	assert.Nil(t, err)
+	defer newFile.Close()
+
+	scanner.scan()
+	assert.Equal(t, 2, len(scanner.tailers))
+	assert.Equal(t, 0, len(scanner.rotatedTailers))
+
+	_, err = newFile.WriteString("new file\n")
+	assert.Nil(t, err)
+
+	// the renamed file is not tailed from the beginning again
+	contents := []string{string((<-outputChan).Content), string((<-outputChan).Content)}
+	assert.ElementsMatch(t, []string{"hello again", "new file"}, contents)
+	select {
+	case msg := <-outputChan:
+		assert.Fail(t, "unexpected message", string(msg.Content))
+	case <-time.After(100 * time.Millisecond):
+	}
+}
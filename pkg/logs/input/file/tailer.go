@@ -12,6 +12,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -31,6 +32,9 @@ const DefaultSleepDuration = 1 * time.Second
 
 const defaultCloseTimeout = 60 * time.Second
 
+// signatureSize is the number of bytes at the beginning of a file used to recognize it after a rotation
+const signatureSize = 1024
+
 // Tailer tails one file and sends messages to an output channel
 type Tailer struct {
 	readOffset    int64
@@ -57,6 +61,10 @@ type Tailer struct {
 
 	forwardContext context.Context
 	stopForward    context.CancelFunc
+
+	// signature holds the first bytes read from the file
+	signature      []byte
+	signatureMutex sync.Mutex
 }
 
 // NewTailer returns an initialized Tailer
@@ -171,6 +179,13 @@ func (t *Tailer) StopAfterFileRotation() {
 	t.source.RemoveInput(t.path)
 }
 
+// StopAfterRename stops a tailer tailing a rotated file that has been found at another path,
+// this call returns only when the decoder is flushed so that the new tailer can resume from its offset.
+func (t *Tailer) StopAfterRename() {
+	t.stop <- struct{}{}
+	<-t.done
+}
+
 // startStopTimer initialises and starts a timer to stop the tailor after the timeout
 func (t *Tailer) startStopTimer() {
 	stopTimer := time.NewTimer(t.closeTimeout)
@@ -246,6 +261,26 @@ func (t *Tailer) shouldTrackOffset() bool {
 	return true
 }
 
+// updateSignature appends the data read at offset to the signature until it is complete.
+func (t *Tailer) updateSignature(offset int64, data []byte) {
+	t.signatureMutex.Lock()
+	defer t.signatureMutex.Unlock()
+	if offset >= signatureSize || offset != int64(len(t.signature)) {
+		return
+	}
+	if n := signatureSize - offset; int64(len(data)) > n {
+		data = data[:n]
+	}
+	t.signature = append(t.signature, data...)
+}
+
+// getSignature returns the first bytes read from the file.
+func (t *Tailer) getSignature() []byte {
+	t.signatureMutex.Lock()
+	defer t.signatureMutex.Unlock()
+	return t.signature
+}
+
 // wait lets the tailer sleep for a bit
 func (t *Tailer) wait() {
 	time.Sleep(t.sleepDuration)
@@ -36,6 +36,10 @@ func (t *Tailer) setup(offset int64, whence int) error {
 	ret, _ := f.Seek(offset, whence)
 	t.readOffset = ret
 	t.decodedOffset = ret
+	if ret > 0 {
+		// the content before the offset is expected to be the one read before
+		t.signature = readSignature(f, ret)
+	}
 
 	return nil
 }
@@ -54,6 +58,7 @@ func (t *Tailer) read() (int, error) {
 	if n == 0 {
 		return 0, nil
 	}
+	t.updateSignature(t.GetReadOffset(), inBuf[:n])
 	t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
 	t.incrementReadOffset(n)
 	return n, nil
@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
 	"time"
 
 	"github.com/coreos/go-systemd/sdjournal"
@@ -26,12 +27,21 @@ const (
 	defaultApplicationName = "docker"
 )
 
+// lagUpdatePeriod represents the minimum delay between two updates of the lag displayed in the status
+const lagUpdatePeriod = 10 * time.Second
+
+// lagMessageKey is the key of the lag message of the source
+const lagMessageKey = "journald_lag"
+
 // Tailer collects logs from a journal.
 type Tailer struct {
 	source     *config.LogSource
 	outputChan chan *message.Message
 	journal    *sdjournal.Journal
 	blacklist  map[string]bool
+	exclusions map[string]map[string]bool
+	lastLagAt  time.Time
+	lastLag    time.Duration
 	stop       chan struct{}
 	done       chan struct{}
 }
@@ -58,7 +68,7 @@ func (t *Tailer) Start(cursor string) error {
 	}
 	t.source.Status.Success()
 	t.source.AddInput(t.journalPath())
-	log.Info("Start tailing journal ", t.journalPath())
+	log.Infof("Start tailing journal %s with cursor %s", t.journalPath(), t.Identifier())
 	go t.tail()
 	return nil
 }
@@ -68,6 +78,7 @@ func (t *Tailer) Stop() {
 	log.Info("Stop tailing journal ", t.journalPath())
 	t.stop <- struct{}{}
 	t.source.RemoveInput(t.journalPath())
+	t.source.Messages.RemoveMessage(lagMessageKey)
 	<-t.done
 }
 
@@ -98,12 +109,67 @@ func (t *Tailer) setup() error {
 		}
 	}
 
+	for _, match := range config.IncludeMatches {
+		// matches on the same field are combined with a logical OR,
+		// matches on different fields are combined with a logical AND.
+		err := t.journal.AddMatch(match)
+		if err != nil {
+			return fmt.Errorf("could not add filter %s: %s", match, err)
+		}
+	}
+
+	priorityMatches, err := t.priorityMatches()
+	if err != nil {
+		return err
+	}
+	for _, match := range priorityMatches {
+		err := t.journal.AddMatch(match)
+		if err != nil {
+			return fmt.Errorf("could not add filter %s: %s", match, err)
+		}
+	}
+
+	return t.setupExclusions()
+}
+
+// priorityMatches returns the filters to collect only the entries with a priority
+// lower or equal to the threshold, the lower the priority, the more severe the entry.
+func (t *Tailer) priorityMatches() ([]string, error) {
+	var matches []string
+	if t.source.Config.PriorityThreshold == "" {
+		return matches, nil
+	}
+	threshold, found := config.JournaldPriorityFromString(t.source.Config.PriorityThreshold)
+	if !found {
+		return nil, fmt.Errorf("invalid priority threshold %s", t.source.Config.PriorityThreshold)
+	}
+	for priority := 0; priority <= threshold; priority++ {
+		matches = append(matches, sdjournal.SD_JOURNAL_FIELD_PRIORITY+"="+strconv.Itoa(priority))
+	}
+	return matches, nil
+}
+
+// setupExclusions builds the filters used to drop the entries
+// matching the units and the fields to exclude.
+func (t *Tailer) setupExclusions() error {
 	t.blacklist = make(map[string]bool)
-	for _, unit := range config.ExcludeUnits {
+	for _, unit := range t.source.Config.ExcludeUnits {
 		// add filters to drop all the logs related to units to exclude.
 		t.blacklist[unit] = true
 	}
 
+	t.exclusions = make(map[string]map[string]bool)
+	for _, match := range t.source.Config.ExcludeMatches {
+		field, value, ok := config.ParseJournaldMatch(match)
+		if !ok {
+			return fmt.Errorf("invalid exclude match %s", match)
+		}
+		if _, exists := t.exclusions[field]; !exists {
+			t.exclusions[field] = make(map[string]bool)
+		}
+		t.exclusions[field][value] = true
+	}
+
 	return nil
 }
 
@@ -142,7 +208,8 @@ func (t *Tailer) tail() {
 				return
 			}
 			if n < 1 {
-				// no new entry
+				// no new entry, the tailer caught up with the journal
+				t.updateLag(0, time.Now())
 				t.journal.Wait(defaultWaitDuration)
 				continue
 			}
@@ -151,6 +218,8 @@ func (t *Tailer) tail() {
 				log.Warnf("Could not retrieve journal entry: %s", err)
 				continue
 			}
+			now := time.Now()
+			t.updateLag(computeLag(entry, now), now)
 			if t.shouldDrop(entry) {
 				continue
 			}
@@ -162,17 +231,44 @@ func (t *Tailer) tail() {
 // shouldDrop returns true if the entry should be dropped,
 // returns false otherwise.
 func (t *Tailer) shouldDrop(entry *sdjournal.JournalEntry) bool {
-	unit, exists := entry.Fields[sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT]
-	if !exists {
-		return false
+	if unit, exists := entry.Fields[sdjournal.SD_JOURNAL_FIELD_SYSTEMD_UNIT]; exists {
+		if _, blacklisted := t.blacklist[unit]; blacklisted {
+			// drop the entry
+			return true
+		}
 	}
-	if _, blacklisted := t.blacklist[unit]; blacklisted {
-		// drop the entry
-		return true
+	for field, values := range t.exclusions {
+		if value, exists := entry.Fields[field]; exists && values[value] {
+			// drop the entry
+			return true
+		}
 	}
 	return false
 }
 
+// updateLag periodically reports in the status the time elapsed between the entry
+// at the current cursor position and now, the lag is reset as soon as the tailer
+// has no new entry to read.
+func (t *Tailer) updateLag(lag time.Duration, now time.Time) {
+	caughtUp := lag == 0 && t.lastLag != 0
+	if now.Sub(t.lastLagAt) < lagUpdatePeriod && !caughtUp {
+		return
+	}
+	t.lastLagAt = now
+	t.lastLag = lag
+	t.source.Messages.AddMessage(lagMessageKey, fmt.Sprintf("Journal %s lag: %s", t.journalPath(), lag))
+}
+
+// computeLag returns the time elapsed since the entry was written to the journal.
+func computeLag(entry *sdjournal.JournalEntry, now time.Time) time.Duration {
+	timestamp := time.Unix(0, int64(entry.RealtimeTimestamp)*int64(time.Microsecond))
+	lag := now.Sub(timestamp)
+	if lag < 0 {
+		return 0
+	}
+	return lag.Truncate(time.Second)
+}
+
 // toMessage transforms a journal entry into a message.
 // A journal entry has different fields that may vary depending on its nature,
 // for more information, see https://www.freedesktop.org/software/systemd/man/systemd.journal-fields.html.
@@ -297,8 +393,12 @@ func (t *Tailer) getStatus(entry *sdjournal.JournalEntry) string {
 // it's used to override the source of the message and as a fingerprint to store the journal cursor.
 const journaldIntegration = "journald"
 
-// Identifier returns the unique identifier of the current journal being tailed.
+// Identifier returns the unique identifier of the current journal being tailed,
+// sources defining a config_id get their own cursor in the registry.
 func (t *Tailer) Identifier() string {
+	if t.source.Config.ConfigID != "" {
+		return journaldIntegration + ":" + t.source.Config.ConfigID
+	}
 	return journaldIntegration + ":" + t.journalPath()
 }
 
@@ -32,6 +32,11 @@ func TestIdentifier(t *testing.T) {
 	source = config.NewLogSource("", &config.LogsConfig{Path: "any_path"})
 	tailer = NewTailer(source, nil)
 	assert.Equal(t, "journald:any_path", tailer.Identifier())
+
+	// expect each config_id to get its own cursor
+	source = config.NewLogSource("", &config.LogsConfig{Path: "any_path", ConfigID: "kernel"})
+	tailer = NewTailer(source, nil)
+	assert.Equal(t, "journald:kernel", tailer.Identifier())
 }
 
 func TestShouldDropEntry(t *testing.T) {
@@ -62,6 +67,96 @@ func TestShouldDropEntry(t *testing.T) {
 		}))
 }
 
+func TestShouldDropEntryMatchingExcludeMatches(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{ExcludeMatches: []string{"_TRANSPORT=kernel", "_TRANSPORT=audit", "SYSLOG_IDENTIFIER=foo"}})
+	tailer := NewTailer(source, nil)
+	err := tailer.setupExclusions()
+	assert.Nil(t, err)
+
+	assert.True(t, tailer.shouldDrop(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_TRANSPORT: "kernel",
+			},
+		}))
+
+	assert.True(t, tailer.shouldDrop(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_TRANSPORT:         "journal",
+				sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER: "foo",
+			},
+		}))
+
+	assert.False(t, tailer.shouldDrop(
+		&sdjournal.JournalEntry{
+			Fields: map[string]string{
+				sdjournal.SD_JOURNAL_FIELD_TRANSPORT:         "journal",
+				sdjournal.SD_JOURNAL_FIELD_SYSLOG_IDENTIFIER: "bar",
+			},
+		}))
+
+	source = config.NewLogSource("", &config.LogsConfig{ExcludeMatches: []string{"_TRANSPORT"}})
+	tailer = NewTailer(source, nil)
+	assert.NotNil(t, tailer.setupExclusions())
+}
+
+func TestPriorityMatches(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, nil)
+	matches, err := tailer.priorityMatches()
+	assert.Nil(t, err)
+	assert.Empty(t, matches)
+
+	source = config.NewLogSource("", &config.LogsConfig{PriorityThreshold: "err"})
+	tailer = NewTailer(source, nil)
+	matches, err = tailer.priorityMatches()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"PRIORITY=0", "PRIORITY=1", "PRIORITY=2", "PRIORITY=3"}, matches)
+
+	source = config.NewLogSource("", &config.LogsConfig{PriorityThreshold: "1"})
+	tailer = NewTailer(source, nil)
+	matches, err = tailer.priorityMatches()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"PRIORITY=0", "PRIORITY=1"}, matches)
+
+	source = config.NewLogSource("", &config.LogsConfig{PriorityThreshold: "foo"})
+	tailer = NewTailer(source, nil)
+	_, err = tailer.priorityMatches()
+	assert.NotNil(t, err)
+}
+
+func TestComputeLag(t *testing.T) {
+	now := time.Now()
+	entry := &sdjournal.JournalEntry{RealtimeTimestamp: uint64(now.Add(-90*time.Second).UnixNano() / int64(time.Microsecond))}
+	assert.Equal(t, 90*time.Second, computeLag(entry, now))
+
+	entry = &sdjournal.JournalEntry{RealtimeTimestamp: uint64(now.Add(time.Minute).UnixNano() / int64(time.Microsecond))}
+	assert.Equal(t, time.Duration(0), computeLag(entry, now))
+}
+
+func TestUpdateLag(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, nil)
+	now := time.Now()
+
+	tailer.updateLag(90*time.Second, now)
+	assert.Equal(t, []string{"Journal default lag: 1m30s"}, source.Messages.GetMessages())
+
+	// the lag is only updated periodically
+	tailer.updateLag(80*time.Second, now.Add(time.Second))
+	assert.Equal(t, []string{"Journal default lag: 1m30s"}, source.Messages.GetMessages())
+
+	// unless the tailer caught up with the journal
+	tailer.updateLag(0, now.Add(2*time.Second))
+	assert.Equal(t, []string{"Journal default lag: 0s"}, source.Messages.GetMessages())
+
+	tailer.updateLag(5*time.Second, now.Add(3*time.Second))
+	assert.Equal(t, []string{"Journal default lag: 0s"}, source.Messages.GetMessages())
+	tailer.updateLag(5*time.Second, now.Add(lagUpdatePeriod+2*time.Second))
+	assert.Equal(t, []string{"Journal default lag: 5s"}, source.Messages.GetMessages())
+}
+
 func TestApplicationName(t *testing.T) {
 	source := config.NewLogSource("", &config.LogsConfig{})
 	tailer := NewTailer(source, nil)
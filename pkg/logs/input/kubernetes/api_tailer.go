@@ -0,0 +1,323 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubelet,kubeapiserver
+
+package kubernetes
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/decoder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/service"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
+	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// apiTailingCompiled is true when the API server client is compiled-in
+const apiTailingCompiled = true
+
+const (
+	apiReadBufferSize         = 4096
+	apiRestartDelay           = 1 * time.Second
+	apiBackoffInitialDuration = 1 * time.Second
+	apiBackoffMaxDuration     = 60 * time.Second
+)
+
+// podLogsFunc opens a stream on the logs of a container
+type podLogsFunc func(namespace, podName string, options *v1.PodLogOptions) (io.ReadCloser, error)
+
+// apiserverPodLogs streams the logs of a container through the pods/log subresource of the API server
+func apiserverPodLogs(namespace, podName string, options *v1.PodLogOptions) (io.ReadCloser, error) {
+	cl, err := apiserver.GetAPIClient()
+	if err != nil {
+		return nil, err
+	}
+	return cl.Cl.CoreV1().Pods(namespace).GetLogs(podName, options).Stream()
+}
+
+// apiParser parses the log lines returned by the API server with timestamps enabled.
+// Those lines follow the pattern '<timestamp> <content>', stdout and stderr are merged.
+var apiParser *apiLineParser
+
+type apiLineParser struct{}
+
+// Parse splits the timestamp from the content of the line
+func (p *apiLineParser) Parse(msg []byte) ([]byte, string, string, error) {
+	components := bytes.SplitN(msg, delimiter, 2)
+	if len(components) < 2 {
+		return msg, message.StatusInfo, "", errors.New("cannot parse the log line")
+	}
+	return components[1], message.StatusInfo, string(components[0]), nil
+}
+
+// apiTailer tails the logs of a container through the Kubernetes API,
+// it is used when the pod log files are not available on the host.
+type apiTailer struct {
+	namespace     string
+	podName       string
+	containerName string
+	source        *config.LogSource
+	outputChan    chan *message.Message
+	decoder       *decoder.Decoder
+	tagProvider   tag.Provider
+	podLogs       podLogsFunc
+
+	sleepDuration time.Duration
+	stop          chan struct{}
+	stopped       chan struct{}
+	done          chan struct{}
+
+	mutex     sync.Mutex
+	reader    io.ReadCloser
+	lastSince time.Time
+	// lastSinceHashes holds the hashes of the contents forwarded with the lastSince timestamp,
+	// it is nil when those contents are unknown
+	lastSinceHashes map[uint64]struct{}
+}
+
+func newAPITailer(pod, container, identifier string, namespace string, source *config.LogSource, outputChan chan *message.Message) *apiTailer {
+	return &apiTailer{
+		namespace:     namespace,
+		podName:       pod,
+		containerName: container,
+		source:        source,
+		outputChan:    outputChan,
+		decoder:       decoder.InitializeDecoder(source, apiParser),
+		tagProvider:   tag.NewProvider(identifier),
+		podLogs:       apiserverPodLogs,
+		sleepDuration: apiRestartDelay,
+		stop:          make(chan struct{}),
+		stopped:       make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Identifier returns a string that uniquely identifies the container in the registry
+func (t *apiTailer) Identifier() string {
+	return fmt.Sprintf("kubernetes_api:%s/%s/%s", t.namespace, t.podName, t.containerName)
+}
+
+// Start starts tailing the container logs from since
+func (t *apiTailer) Start(since time.Time) {
+	log.Debugf("Start tailing container %s/%s/%s through the API server", t.namespace, t.podName, t.containerName)
+	t.setLastSince(since)
+	t.source.AddInput(t.Identifier())
+	go t.forwardMessages()
+	t.decoder.Start()
+	go t.run()
+}
+
+// Stop stops the tailer, this call blocks until the decoder is completely flushed
+func (t *apiTailer) Stop() {
+	log.Infof("Stop tailing container %s/%s/%s", t.namespace, t.podName, t.containerName)
+	close(t.stop)
+	t.closeReader()
+	<-t.stopped
+	t.source.RemoveInput(t.Identifier())
+	// wait for the decoder to be flushed
+	<-t.done
+}
+
+// run opens the log stream and reads it until the tailer is stopped,
+// the stream is reopened from the last forwarded log when it ends.
+func (t *apiTailer) run() {
+	defer func() {
+		t.decoder.Stop()
+		close(t.stopped)
+	}()
+
+	backoff := apiBackoffInitialDuration
+	for {
+		reader, err := t.podLogs(t.namespace, t.podName, t.getLogOptions())
+		if err != nil {
+			t.source.Status.Error(err)
+			delay := backoff
+			if seconds, ok := apierrors.SuggestsClientDelay(err); ok && time.Duration(seconds)*time.Second > delay {
+				delay = time.Duration(seconds) * time.Second
+			}
+			log.Warnf("Could not stream the logs of container %s/%s/%s, retrying in %s: %v", t.namespace, t.podName, t.containerName, delay, err)
+			if !t.wait(delay) {
+				return
+			}
+			if backoff *= 2; backoff > apiBackoffMaxDuration {
+				backoff = apiBackoffMaxDuration
+			}
+			continue
+		}
+		backoff = apiBackoffInitialDuration
+		t.source.Status.Success()
+
+		if !t.setReader(reader) {
+			reader.Close()
+			return
+		}
+		t.read(reader)
+		t.closeReader()
+
+		// the stream ends when the container stops or the connection is closed by the API server
+		if !t.wait(t.sleepDuration) {
+			return
+		}
+	}
+}
+
+// read forwards the content of the stream to the decoder until it ends
+func (t *apiTailer) read(reader io.Reader) {
+	for {
+		inBuf := make([]byte, apiReadBufferSize)
+		n, err := reader.Read(inBuf)
+		if n > 0 {
+			t.decoder.InputChan <- decoder.NewInput(inBuf[:n])
+		}
+		if err != nil {
+			if err != io.EOF {
+				log.Debugf("Log stream of container %s/%s/%s interrupted: %v", t.namespace, t.podName, t.containerName, err)
+			}
+			return
+		}
+	}
+}
+
+// getLogOptions returns the options to stream the logs following the last forwarded one
+func (t *apiTailer) getLogOptions() *v1.PodLogOptions {
+	options := &v1.PodLogOptions{
+		Container:  t.containerName,
+		Follow:     true,
+		Timestamps: true,
+	}
+	if since := t.getLastSince(); !since.IsZero() {
+		// sinceTime has a precision of one second, logs already
+		// forwarded are filtered out in forwardMessages
+		sinceTime := metav1.NewTime(since)
+		options.SinceTime = &sinceTime
+	}
+	return options
+}
+
+// forwardMessages forwards decoded messages to the next pipeline,
+// skipping the ones that were already sent before the stream was reopened
+func (t *apiTailer) forwardMessages() {
+	defer func() {
+		// the decoder has successfully been flushed
+		close(t.done)
+	}()
+	for output := range t.decoder.OutputChan {
+		if len(output.Content) == 0 {
+			continue
+		}
+		timestamp, err := time.Parse(time.RFC3339Nano, output.Timestamp)
+		if err == nil && !t.markForwarded(timestamp, output.Content) {
+			continue
+		}
+		origin := message.NewOrigin(t.source)
+		origin.Identifier = t.Identifier()
+		origin.Offset = output.Timestamp
+		origin.SetTags(t.tagProvider.GetTags())
+		t.outputChan <- message.NewMessage(output.Content, origin, output.Status)
+	}
+}
+
+// wait sleeps for the given duration, returns false if the tailer was stopped in the meantime
+func (t *apiTailer) wait(duration time.Duration) bool {
+	select {
+	case <-t.stop:
+		return false
+	case <-time.After(duration):
+		return true
+	}
+}
+
+// setReader keeps track of the current stream to close it on stop,
+// returns false if the tailer is already stopped
+func (t *apiTailer) setReader(reader io.ReadCloser) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	select {
+	case <-t.stop:
+		return false
+	default:
+	}
+	t.reader = reader
+	return true
+}
+
+func (t *apiTailer) closeReader() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.reader != nil {
+		t.reader.Close()
+		t.reader = nil
+	}
+}
+
+func (t *apiTailer) getLastSince() time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.lastSince
+}
+
+func (t *apiTailer) setLastSince(since time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.lastSince = since
+	t.lastSinceHashes = nil
+}
+
+// markForwarded returns false if a log with the same timestamp and content was already forwarded,
+// several logs can share the same timestamp so the contents forwarded with the last one are kept.
+// When the stream was started from an offset, the logs with the offset timestamp were all forwarded.
+func (t *apiTailer) markForwarded(timestamp time.Time, content []byte) bool {
+	h := fnv.New64a()
+	_, _ = h.Write(content)
+	hash := h.Sum64()
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	switch {
+	case timestamp.After(t.lastSince):
+		t.lastSince = timestamp
+		t.lastSinceHashes = map[uint64]struct{}{hash: {}}
+		return true
+	case timestamp.Equal(t.lastSince) && t.lastSinceHashes != nil:
+		if _, found := t.lastSinceHashes[hash]; found {
+			return false
+		}
+		t.lastSinceHashes[hash] = struct{}{}
+		return true
+	default:
+		return false
+	}
+}
+
+// apiSince returns the date from when the logs of a container should be collected
+func apiSince(registry auditor.Registry, identifier string, creationTime service.CreationTime) time.Time {
+	if offset := registry.GetOffset(identifier); offset != "" {
+		// an offset was registered, tail from the offset
+		if since, err := time.Parse(time.RFC3339Nano, offset); err == nil {
+			return since
+		}
+		return time.Now().UTC()
+	}
+	if creationTime == service.After {
+		// the container was started after the agent, tail from the beginning
+		return time.Time{}
+	}
+	return time.Now().UTC()
+}
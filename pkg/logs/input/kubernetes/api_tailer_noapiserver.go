@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubelet,!kubeapiserver
+
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/service"
+)
+
+// apiTailingCompiled is false when the API server client is not compiled-in
+const apiTailingCompiled = false
+
+// apiTailer is not supported without the API server client
+type apiTailer struct{}
+
+func newAPITailer(pod, container, identifier string, namespace string, source *config.LogSource, outputChan chan *message.Message) *apiTailer {
+	return &apiTailer{}
+}
+
+// Identifier returns an empty string
+func (t *apiTailer) Identifier() string { return "" }
+
+// Start does nothing
+func (t *apiTailer) Start(since time.Time) {}
+
+// Stop does nothing
+func (t *apiTailer) Stop() {}
+
+func apiSince(registry auditor.Registry, identifier string, creationTime service.CreationTime) time.Time {
+	return time.Now().UTC()
+}
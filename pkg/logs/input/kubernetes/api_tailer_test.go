@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubelet,kubeapiserver
+
+package kubernetes
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	auditor "github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/service"
+	"github.com/DataDog/datadog-agent/pkg/logs/tag"
+)
+
+func TestAPIParserParse(t *testing.T) {
+	content, status, timestamp, err := apiParser.Parse([]byte("2020-07-01T10:00:00.123456789Z hello world"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), content)
+	assert.Equal(t, message.StatusInfo, status)
+	assert.Equal(t, "2020-07-01T10:00:00.123456789Z", timestamp)
+
+	_, _, _, err = apiParser.Parse([]byte("hello"))
+	assert.Error(t, err)
+}
+
+func TestAPITailerReopensStreamFromLastLog(t *testing.T) {
+	streams := []string{
+		"2020-07-01T10:00:00.1Z first\n2020-07-01T10:00:00.2Z second\n",
+		// the stream is reopened from the second, the logs already sent are skipped
+		// but not the new ones sharing the timestamp of the last one sent
+		"2020-07-01T10:00:00.1Z first\n2020-07-01T10:00:00.2Z second\n2020-07-01T10:00:00.2Z second bis\n2020-07-01T10:00:01Z third\n",
+	}
+	var options []*v1.PodLogOptions
+
+	source := config.NewLogSource("default/pod/ctr", &config.LogsConfig{Type: config.KubernetesAPIType})
+	outputChan := make(chan *message.Message, 10)
+	tailer := newAPITailer("pod", "ctr", "container_id://abc", "default", source, outputChan)
+	tailer.tagProvider = tag.NoopProvider
+	tailer.sleepDuration = 10 * time.Millisecond
+	tailer.podLogs = func(namespace, podName string, opts *v1.PodLogOptions) (io.ReadCloser, error) {
+		assert.Equal(t, "default", namespace)
+		assert.Equal(t, "pod", podName)
+		options = append(options, opts)
+		if len(options) > len(streams) {
+			return nil, errors.New("too many requests")
+		}
+		return ioutil.NopCloser(strings.NewReader(streams[len(options)-1])), nil
+	}
+
+	tailer.Start(time.Time{})
+	var contents []string
+	for i := 0; i < 4; i++ {
+		select {
+		case msg := <-outputChan:
+			contents = append(contents, string(msg.Content))
+			assert.Equal(t, "kubernetes_api:default/pod/ctr", msg.Origin.Identifier)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout waiting for messages")
+		}
+	}
+	tailer.Stop()
+
+	assert.Equal(t, []string{"first", "second", "second bis", "third"}, contents)
+	require.True(t, len(options) >= 2)
+	assert.Equal(t, "ctr", options[0].Container)
+	assert.True(t, options[0].Follow)
+	assert.True(t, options[0].Timestamps)
+	assert.Nil(t, options[0].SinceTime)
+	require.NotNil(t, options[1].SinceTime)
+	assert.Equal(t, time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC), options[1].SinceTime.Time.UTC().Truncate(time.Second))
+}
+
+func TestAPITailerMarkForwarded(t *testing.T) {
+	source := config.NewLogSource("default/pod/ctr", &config.LogsConfig{Type: config.KubernetesAPIType})
+	tailer := newAPITailer("pod", "ctr", "container_id://abc", "default", source, nil)
+	offset := time.Date(2020, 7, 1, 10, 0, 0, 0, time.UTC)
+	tailer.setLastSince(offset)
+
+	// the logs with the offset timestamp were forwarded before the restart
+	assert.False(t, tailer.markForwarded(offset.Add(-time.Second), []byte("older")))
+	assert.False(t, tailer.markForwarded(offset, []byte("offset")))
+
+	next := offset.Add(time.Millisecond)
+	assert.True(t, tailer.markForwarded(next, []byte("a")))
+	assert.True(t, tailer.markForwarded(next, []byte("b")))
+	assert.False(t, tailer.markForwarded(next, []byte("a")))
+	assert.False(t, tailer.markForwarded(next, []byte("b")))
+	assert.Equal(t, next, tailer.getLastSince())
+
+	assert.True(t, tailer.markForwarded(next.Add(time.Millisecond), []byte("a")))
+	assert.False(t, tailer.markForwarded(next, []byte("c")))
+}
+
+func TestAPISince(t *testing.T) {
+	registry := auditor.NewRegistry()
+
+	assert.True(t, apiSince(registry, "kubernetes_api:default/pod/ctr", service.After).IsZero())
+	assert.WithinDuration(t, time.Now(), apiSince(registry, "kubernetes_api:default/pod/ctr", service.Before), time.Minute)
+
+	registry.SetOffset("2020-07-01T10:00:00.123456789Z")
+	assert.Equal(t, time.Date(2020, 7, 1, 10, 0, 0, 123456789, time.UTC), apiSince(registry, "kubernetes_api:default/pod/ctr", service.After))
+}
@@ -12,8 +12,11 @@ import (
 	"os"
 	"path/filepath"
 
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 	"github.com/DataDog/datadog-agent/pkg/logs/input"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
 	"github.com/DataDog/datadog-agent/pkg/util/containers"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
@@ -38,12 +41,28 @@ type Launcher struct {
 	removedServices    chan *service.Service
 	collectAll         bool
 	serviceNameFunc    func(string, string) string // serviceNameFunc gets the service name from the tagger, it is in a separate field for testing purpose
+
+	// useAPI is true when the logs are streamed through the API server instead of read from the pod log files
+	useAPI           bool
+	pipelineProvider pipeline.Provider
+	registry         auditor.Registry
+	apiTailers       map[string]*apiTailer
 }
 
 // NewLauncher returns a new launcher.
-func NewLauncher(sources *config.LogSources, services *service.Services, collectAll bool) (*Launcher, error) {
+// When the pod log files are not available and useAPI is enabled, the container
+// logs are streamed through the API server.
+func NewLauncher(sources *config.LogSources, services *service.Services, collectAll bool, useAPI bool, pipelineProvider pipeline.Provider, registry auditor.Registry) (*Launcher, error) {
 	if !isIntegrationAvailable() {
-		return nil, fmt.Errorf("%s not found", basePath)
+		if !useAPI {
+			return nil, fmt.Errorf("%s not found", basePath)
+		}
+		if !apiTailingCompiled {
+			return nil, fmt.Errorf("%s not found and the API server client is not compiled-in", basePath)
+		}
+		log.Infof("%s not found, container logs will be streamed through the API server", basePath)
+	} else {
+		useAPI = false
 	}
 	kubeutil, err := kubelet.GetKubeUtil()
 	if err != nil {
@@ -56,6 +75,10 @@ func NewLauncher(sources *config.LogSources, services *service.Services, collect
 		kubeutil:           kubeutil,
 		collectAll:         collectAll,
 		serviceNameFunc:    input.ServiceNameFromTags,
+		useAPI:             useAPI,
+		pipelineProvider:   pipelineProvider,
+		registry:           registry,
+		apiTailers:         make(map[string]*apiTailer),
 	}
 	launcher.addedServices = services.GetAllAddedServices()
 	launcher.removedServices = services.GetAllRemovedServices()
@@ -76,10 +99,16 @@ func (l *Launcher) Start() {
 	go l.run()
 }
 
-// Stop stops the launcher
+// Stop stops the launcher and its API tailers
 func (l *Launcher) Stop() {
 	log.Info("Stopping Kubernetes launcher")
 	l.stopped <- struct{}{}
+	stopper := restart.NewParallelStopper()
+	for entityID, tailer := range l.apiTailers {
+		stopper.Add(tailer)
+		delete(l.apiTailers, entityID)
+	}
+	stopper.Stop()
 }
 
 // run handles new and deleted pods,
@@ -135,11 +164,21 @@ func (l *Launcher) addSource(svc *service.Service) {
 
 	l.sourcesByContainer[svc.GetEntityID()] = source
 	l.sources.AddSource(source)
+
+	if l.useAPI {
+		tailer := newAPITailer(pod.Metadata.Name, container.Name, source.Config.Identifier, pod.Metadata.Namespace, source, l.pipelineProvider.NextPipelineChan())
+		tailer.Start(apiSince(l.registry, tailer.Identifier(), svc.CreationTime))
+		l.apiTailers[svc.GetEntityID()] = tailer
+	}
 }
 
 // removeSource removes a new log-source from a service
 func (l *Launcher) removeSource(service *service.Service) {
 	containerID := service.GetEntityID()
+	if tailer, exists := l.apiTailers[containerID]; exists {
+		delete(l.apiTailers, containerID)
+		go tailer.Stop()
+	}
 	if source, exists := l.sourcesByContainer[containerID]; exists {
 		delete(l.sourcesByContainer, containerID)
 		l.sources.RemoveSource(source)
@@ -186,8 +225,12 @@ func (l *Launcher) getSource(pod *kubelet.Pod, container kubelet.ContainerStatus
 	if cfg.Service == "" && standardService != "" {
 		cfg.Service = standardService
 	}
-	cfg.Type = config.FileType
-	cfg.Path = l.getPath(basePath, pod, container)
+	if l.useAPI {
+		cfg.Type = config.KubernetesAPIType
+	} else {
+		cfg.Type = config.FileType
+		cfg.Path = l.getPath(basePath, pod, container)
+	}
 	cfg.Identifier = getTaggerEntityID(container.ID)
 	if err := cfg.Validate(); err != nil {
 		return nil, fmt.Errorf("invalid kubernetes annotation: %v", err)
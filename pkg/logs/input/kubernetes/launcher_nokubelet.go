@@ -8,7 +8,9 @@
 package kubernetes
 
 import (
+	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
 )
 
@@ -16,7 +18,7 @@ import (
 type Launcher struct{}
 
 // NewLauncher returns a new launcher
-func NewLauncher(sources *config.LogSources, services *service.Services, collectAll bool) (*Launcher, error) {
+func NewLauncher(sources *config.LogSources, services *service.Services, collectAll bool, useAPI bool, pipelineProvider pipeline.Provider, registry auditor.Registry) (*Launcher, error) {
 	return &Launcher{}, nil
 }
 
@@ -17,6 +17,7 @@ type Launcher struct {
 	frameSize        int
 	tcpSources       chan *config.LogSource
 	udpSources       chan *config.LogSource
+	syslogSources    chan *config.LogSource
 	listeners        []restart.Restartable
 	stop             chan struct{}
 }
@@ -28,6 +29,7 @@ func NewLauncher(sources *config.LogSources, frameSize int, pipelineProvider pip
 		frameSize:        frameSize,
 		tcpSources:       sources.GetAddedForType(config.TCPType),
 		udpSources:       sources.GetAddedForType(config.UDPType),
+		syslogSources:    sources.GetAddedForType(config.SyslogType),
 		stop:             make(chan struct{}),
 	}
 }
@@ -49,12 +51,27 @@ func (l *Launcher) run() {
 			listener := NewUDPListener(l.pipelineProvider, source, l.frameSize)
 			listener.Start()
 			l.listeners = append(l.listeners, listener)
+		case source := <-l.syslogSources:
+			listener := l.newSyslogListener(source)
+			listener.Start()
+			l.listeners = append(l.listeners, listener)
 		case <-l.stop:
 			return
 		}
 	}
 }
 
+// newSyslogListener returns a listener receiving syslog messages over the protocol of the source,
+// the messages are parsed by the tailers of the listener.
+func (l *Launcher) newSyslogListener(source *config.LogSource) restart.Restartable {
+	switch source.Config.GetSyslogProtocol() {
+	case config.SyslogTCP, config.SyslogTLS:
+		return NewTCPListener(l.pipelineProvider, source, l.frameSize)
+	default:
+		return NewUDPListener(l.pipelineProvider, source, l.frameSize)
+	}
+}
+
 // Stop stops all listeners
 func (l *Launcher) Stop() {
 	l.stop <- struct{}{}
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listener
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+// Attributes extracted from the header of a syslog message
+const (
+	syslogSeverity = "syslog.severity"
+	syslogFacility = "syslog.facility"
+	syslogHostname = "syslog.hostname"
+	syslogAppname  = "syslog.appname"
+	syslogProcID   = "syslog.procid"
+	syslogMsgID    = "syslog.msgid"
+)
+
+// syslogMaxPriority is the highest valid priority, facility 23 and severity 7
+const syslogMaxPriority = 191
+
+// syslogStatuses maps the syslog severities to the message statuses.
+var syslogStatuses = []string{
+	message.StatusEmergency,
+	message.StatusAlert,
+	message.StatusCritical,
+	message.StatusError,
+	message.StatusWarning,
+	message.StatusNotice,
+	message.StatusInfo,
+	message.StatusDebug,
+}
+
+// rfc3164Timestamp matches the timestamp of a BSD syslog message, e.g. 'Jun  1 10:00:00 '
+var rfc3164Timestamp = regexp.MustCompile(`^[A-Z][a-z]{2} [ \d]\d \d{2}:\d{2}:\d{2} `)
+
+// rfc3164Tag matches the tag of a BSD syslog message, e.g. 'sshd[1234]: '
+var rfc3164Tag = regexp.MustCompile(`^([^\s\[:]+)(?:\[([^\]]*)\])?: ?`)
+
+// utf8BOM may start the content of a RFC5424 message
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// syslogMessage holds the content and the header fields of a syslog message.
+type syslogMessage struct {
+	content    []byte
+	status     string
+	attributes map[string]string
+}
+
+// newSyslogMessage parses a syslog line and returns a message carrying the header fields as attributes,
+// the line is forwarded as is when it does not start with a valid priority.
+func newSyslogMessage(line []byte, source *config.LogSource) *message.Message {
+	parsed, ok := parseSyslog(line)
+	if !ok {
+		return message.NewMessageWithSource(line, message.StatusInfo, source)
+	}
+	msg := message.NewMessageWithSource(parsed.content, parsed.status, source)
+	for key, value := range parsed.attributes {
+		msg.SetAttribute(key, value)
+	}
+	return msg
+}
+
+// parseSyslog parses a syslog message following either RFC5424 or RFC3164,
+// returns false if the message does not start with a valid priority.
+func parseSyslog(line []byte) (*syslogMessage, bool) {
+	priority, rest, ok := parsePriority(line)
+	if !ok {
+		return nil, false
+	}
+	msg := &syslogMessage{
+		status: syslogStatuses[priority%8],
+		attributes: map[string]string{
+			syslogSeverity: strconv.Itoa(priority % 8),
+			syslogFacility: strconv.Itoa(priority / 8),
+		},
+	}
+	if bytes.HasPrefix(rest, []byte("1 ")) {
+		parseRFC5424(msg, rest[2:])
+	} else {
+		parseRFC3164(msg, rest)
+	}
+	return msg, true
+}
+
+// parsePriority parses the '<PRI>' header of a syslog message.
+func parsePriority(line []byte) (int, []byte, bool) {
+	if len(line) < 3 || line[0] != '<' {
+		return 0, nil, false
+	}
+	end := bytes.IndexByte(line, '>')
+	if end < 2 || end > 4 {
+		return 0, nil, false
+	}
+	priority, err := strconv.Atoi(string(line[1:end]))
+	if err != nil || priority < 0 || priority > syslogMaxPriority {
+		return 0, nil, false
+	}
+	return priority, line[end+1:], true
+}
+
+// parseRFC5424 parses the header following the version of a RFC5424 message:
+// 'TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG'.
+func parseRFC5424(msg *syslogMessage, rest []byte) {
+	keys := []string{"", syslogHostname, syslogAppname, syslogProcID, syslogMsgID}
+	for _, key := range keys {
+		var field []byte
+		field, rest = nextField(rest)
+		if key != "" && len(field) > 0 && !bytes.Equal(field, []byte("-")) {
+			msg.attributes[key] = string(field)
+		}
+	}
+	rest = skipStructuredData(rest)
+	msg.content = bytes.TrimPrefix(bytes.TrimPrefix(rest, []byte(" ")), utf8BOM)
+}
+
+// parseRFC3164 parses the header of a BSD syslog message: 'TIMESTAMP HOSTNAME TAG: MSG',
+// all the fields are optional, the remaining of the line is used as the content.
+func parseRFC3164(msg *syslogMessage, rest []byte) {
+	if loc := rfc3164Timestamp.FindIndex(rest); loc != nil {
+		rest = rest[loc[1]:]
+		// the hostname always follows the timestamp, unless it is directly followed by the tag
+		if field, next := nextField(rest); len(field) > 0 && !rfc3164Tag.Match(rest) {
+			msg.attributes[syslogHostname] = string(field)
+			rest = next
+		}
+	}
+	if match := rfc3164Tag.FindSubmatchIndex(rest); match != nil {
+		msg.attributes[syslogAppname] = string(rest[match[2]:match[3]])
+		if match[4] >= 0 {
+			msg.attributes[syslogProcID] = string(rest[match[4]:match[5]])
+		}
+		rest = rest[match[1]:]
+	}
+	msg.content = rest
+}
+
+// nextField returns the content up to the next space and the remaining of the line.
+func nextField(line []byte) ([]byte, []byte) {
+	i := bytes.IndexByte(line, ' ')
+	if i < 0 {
+		return line, nil
+	}
+	return line[:i], line[i+1:]
+}
+
+// skipStructuredData returns the content following the structured data of a RFC5424 message,
+// which is either a nil value '-' or a list of elements '[id key="value"]'.
+func skipStructuredData(line []byte) []byte {
+	if bytes.HasPrefix(line, []byte("-")) {
+		return line[1:]
+	}
+	for len(line) > 0 && line[0] == '[' {
+		end := structuredDataElementEnd(line)
+		if end < 0 {
+			// the element is not terminated
+			return nil
+		}
+		line = line[end+1:]
+	}
+	return line
+}
+
+// structuredDataElementEnd returns the index of the bracket closing the element
+// starting the line, the brackets and quotes escaped in the parameter values are ignored.
+func structuredDataElementEnd(line []byte) int {
+	inValue := false
+	for i := 1; i < len(line); i++ {
+		switch {
+		case line[i] == '\\' && inValue:
+			i++
+		case line[i] == '"':
+			inValue = !inValue
+		case line[i] == ']' && !inValue:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listener
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// syslogMaxFrameSize represents the max size of a syslog message read from a stream,
+// bigger messages are truncated like the lines of the other sources.
+const syslogMaxFrameSize = 256 * 1000
+
+// syslogMaxLengthDigits is the max number of digits of the length prefixing an octet counted message.
+const syslogMaxLengthDigits = 10
+
+// syslogFrameReader splits a syslog stream into messages following RFC6587,
+// a message is either prefixed by its length in octets and a space (octet counting)
+// or terminated by a newline (non-transparent framing).
+// The framing is detected for each message: a syslog message always starts with '<',
+// so a message starting with a digit is octet counted.
+type syslogFrameReader struct {
+	reader *bufio.Reader
+}
+
+// newSyslogFrameReader returns a new syslogFrameReader reading from r.
+func newSyslogFrameReader(r io.Reader) *syslogFrameReader {
+	return &syslogFrameReader{
+		reader: bufio.NewReaderSize(r, syslogMaxFrameSize),
+	}
+}
+
+// next returns the next message of the stream, the returned slice is not reused.
+func (r *syslogFrameReader) next() ([]byte, error) {
+	for {
+		first, err := r.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if first[0] >= '0' && first[0] <= '9' {
+			return r.nextOctetCounted()
+		}
+		frame, err := r.nextNonTransparent()
+		if err != nil || len(frame) > 0 {
+			return frame, err
+		}
+		// skip the empty lines, some senders terminate octet counted messages with a newline
+	}
+}
+
+// nextOctetCounted reads a message prefixed by its length: 'MSG-LEN SP SYSLOG-MSG',
+// the content exceeding syslogMaxFrameSize is discarded.
+func (r *syslogFrameReader) nextOctetCounted() ([]byte, error) {
+	prefix, err := r.reader.ReadSlice(' ')
+	if err != nil && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	if err == bufio.ErrBufferFull || len(prefix) > syslogMaxLengthDigits+1 {
+		return nil, fmt.Errorf("invalid syslog message length: %q", truncatePrefix(prefix))
+	}
+	length, err := strconv.Atoi(string(prefix[:len(prefix)-1]))
+	if err != nil || length <= 0 {
+		return nil, fmt.Errorf("invalid syslog message length: %q", prefix[:len(prefix)-1])
+	}
+	size := length
+	if size > syslogMaxFrameSize {
+		size = syslogMaxFrameSize
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(r.reader, frame); err != nil {
+		return nil, err
+	}
+	if _, err := r.reader.Discard(length - size); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// nextNonTransparent reads a message terminated by a newline,
+// a message exceeding syslogMaxFrameSize is split.
+func (r *syslogFrameReader) nextNonTransparent() ([]byte, error) {
+	line, err := r.reader.ReadSlice('\n')
+	if err != nil && err != bufio.ErrBufferFull {
+		return nil, err
+	}
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	frame := make([]byte, len(line))
+	copy(frame, line)
+	return frame, nil
+}
+
+// truncatePrefix returns the beginning of an invalid length prefix to report it.
+func truncatePrefix(prefix []byte) []byte {
+	if len(prefix) > syslogMaxLengthDigits+1 {
+		return prefix[:syslogMaxLengthDigits+1]
+	}
+	return prefix
+}
@@ -0,0 +1,248 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package listener
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline/mock"
+)
+
+func TestParseSyslogRFC5424(t *testing.T) {
+	msg, ok := parseSyslog([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application\] x"] BOMAn application event`))
+	assert.True(t, ok)
+	assert.Equal(t, "BOMAn application event", string(msg.content))
+	assert.Equal(t, message.StatusNotice, msg.status)
+	assert.Equal(t, map[string]string{
+		syslogSeverity: "5",
+		syslogFacility: "20",
+		syslogHostname: "mymachine.example.com",
+		syslogAppname:  "evntslog",
+		syslogProcID:   "1234",
+		syslogMsgID:    "ID47",
+	}, msg.attributes)
+
+	msg, ok = parseSyslog([]byte("<34>1 2003-10-11T22:14:15.003Z mymachine.example.com su - - - \xef\xbb\xbf'su root' failed for lonvick on /dev/pts/8"))
+	assert.True(t, ok)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(msg.content))
+	assert.Equal(t, message.StatusCritical, msg.status)
+	assert.Equal(t, map[string]string{
+		syslogSeverity: "2",
+		syslogFacility: "4",
+		syslogHostname: "mymachine.example.com",
+		syslogAppname:  "su",
+	}, msg.attributes)
+
+	msg, ok = parseSyslog([]byte(`<14>1 - - - - - [a b="1"][c d="2"] two elements`))
+	assert.True(t, ok)
+	assert.Equal(t, "two elements", string(msg.content))
+}
+
+func TestParseSyslogRFC3164(t *testing.T) {
+	msg, ok := parseSyslog([]byte("<34>Oct 11 22:14:15 mymachine su[123]: 'su root' failed for lonvick on /dev/pts/8"))
+	assert.True(t, ok)
+	assert.Equal(t, "'su root' failed for lonvick on /dev/pts/8", string(msg.content))
+	assert.Equal(t, message.StatusCritical, msg.status)
+	assert.Equal(t, map[string]string{
+		syslogSeverity: "2",
+		syslogFacility: "4",
+		syslogHostname: "mymachine",
+		syslogAppname:  "su",
+		syslogProcID:   "123",
+	}, msg.attributes)
+
+	msg, ok = parseSyslog([]byte("<13>Feb  5 17:32:18 sshd: Accepted publickey"))
+	assert.True(t, ok)
+	assert.Equal(t, "Accepted publickey", string(msg.content))
+	assert.Equal(t, "sshd", msg.attributes[syslogAppname])
+	assert.NotContains(t, msg.attributes, syslogHostname)
+
+	msg, ok = parseSyslog([]byte("<12>link down on port 3"))
+	assert.True(t, ok)
+	assert.Equal(t, "link down on port 3", string(msg.content))
+	assert.Equal(t, message.StatusWarning, msg.status)
+	assert.NotContains(t, msg.attributes, syslogAppname)
+}
+
+func TestParseSyslogShouldFailWithInvalidPriority(t *testing.T) {
+	for _, line := range []string{"", "hello world", "<>hello", "<192>hello", "<abc>hello", "<1234>hello", "<13 hello"} {
+		_, ok := parseSyslog([]byte(line))
+		assert.False(t, ok, line)
+	}
+}
+
+func TestNewSyslogMessageShouldForwardInvalidLinesAsIs(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType})
+	msg := newSyslogMessage([]byte("hello world"), source)
+	assert.Equal(t, "hello world", string(msg.Content))
+	assert.Equal(t, message.StatusInfo, msg.GetStatus())
+	assert.Empty(t, msg.Attributes)
+}
+
+func TestSyslogOverTCPShouldReceiveParsedMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.SyslogTCP, Port: tcpTestPort})
+	listener := NewTCPListener(pp, source, 9000)
+	listener.Start()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("%s", listener.listener.Addr()))
+	assert.Nil(t, err)
+
+	fmt.Fprintf(conn, "<11>Oct 11 22:14:15 router kernel: link down\n")
+	msg := <-msgChan
+	assert.Equal(t, "link down", string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "router", msg.Attributes[syslogHostname])
+	assert.Equal(t, "kernel", msg.Attributes[syslogAppname])
+	assert.Equal(t, "1", msg.Attributes[syslogFacility])
+
+	listener.Stop()
+}
+
+func TestSyslogFrameReaderOctetCounting(t *testing.T) {
+	reader := newSyslogFrameReader(strings.NewReader("11 <13>1 hello17 <13>1 multi\nline\n"))
+
+	frame, err := reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>1 hello", string(frame))
+
+	frame, err = reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>1 multi\nline\n", string(frame))
+
+	_, err = reader.next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSyslogFrameReaderNonTransparentFraming(t *testing.T) {
+	reader := newSyslogFrameReader(strings.NewReader("<13>hello\n\n<13>world\n"))
+
+	frame, err := reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>hello", string(frame))
+
+	frame, err = reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>world", string(frame))
+
+	_, err = reader.next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSyslogFrameReaderMixedFraming(t *testing.T) {
+	reader := newSyslogFrameReader(strings.NewReader("9 <13>hello\n<13>world\n"))
+
+	frame, err := reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>hello", string(frame))
+
+	frame, err = reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>world", string(frame))
+}
+
+func TestSyslogFrameReaderTruncatesLargeMessages(t *testing.T) {
+	content := strings.Repeat("a", syslogMaxFrameSize+10)
+	reader := newSyslogFrameReader(strings.NewReader(fmt.Sprintf("%d %s9 <13>hello", len(content), content)))
+
+	frame, err := reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, content[:syslogMaxFrameSize], string(frame))
+
+	frame, err = reader.next()
+	assert.Nil(t, err)
+	assert.Equal(t, "<13>hello", string(frame))
+}
+
+func TestSyslogFrameReaderInvalidLength(t *testing.T) {
+	_, err := newSyslogFrameReader(strings.NewReader("12a <13>hello")).next()
+	assert.NotNil(t, err)
+
+	_, err = newSyslogFrameReader(strings.NewReader("0 <13>hello")).next()
+	assert.NotNil(t, err)
+
+	_, err = newSyslogFrameReader(strings.NewReader("123456789012 <13>hello")).next()
+	assert.NotNil(t, err)
+}
+
+func TestSyslogOverTCPShouldReceiveOctetCountedMessages(t *testing.T) {
+	pp := mock.NewMockProvider()
+	msgChan := pp.NextPipelineChan()
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.SyslogTCP, Port: tcpTestPort})
+	listener := NewTCPListener(pp, source, 9000)
+	listener.Start()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("%s", listener.listener.Addr()))
+	assert.Nil(t, err)
+
+	line := "<11>Oct 11 22:14:15 router kernel: link down\nstack trace"
+	fmt.Fprintf(conn, "%d %s", len(line), line)
+	msg := <-msgChan
+	assert.Equal(t, "link down\nstack trace", string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "router", msg.Attributes[syslogHostname])
+	assert.Equal(t, "kernel", msg.Attributes[syslogAppname])
+
+	fmt.Fprintf(conn, "<11>Oct 11 22:14:16 router kernel: link up\n")
+	msg = <-msgChan
+	assert.Equal(t, "link up", string(msg.Content))
+
+	listener.Stop()
+}
+
+func TestSyslogStreamTailerOverPipe(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Type: config.SyslogType, Protocol: config.SyslogTCP})
+	listener := &TCPListener{source: source}
+	outputChan := make(chan *message.Message, 10)
+	server, client := net.Pipe()
+	tailer := newSyslogStreamTailer(source, server, outputChan, listener.readSyslogFrame)
+	listener.tailers = append(listener.tailers, tailer)
+	tailer.Start()
+
+	first := "<11>Oct 11 22:14:15 router kernel: link down\nstack trace"
+	second := "<14>1 2020-07-01T10:00:00Z host app - - - hello"
+	go func() {
+		fmt.Fprintf(client, "%d %s%d %s", len(first), first, len(second), second)
+		fmt.Fprintf(client, "<11>Oct 11 22:14:16 router kernel: link up\n")
+		client.Close()
+	}()
+
+	var contents []string
+	for i := 0; i < 3; i++ {
+		select {
+		case msg := <-outputChan:
+			contents = append(contents, string(msg.Content))
+		case <-time.After(5 * time.Second):
+			assert.FailNow(t, "timeout waiting for messages")
+		}
+	}
+	assert.Equal(t, []string{"link down\nstack trace", "hello", "link up"}, contents)
+
+	// the connection closed by the client stops the tailer without reporting an error
+	for i := 0; i < 100; i++ {
+		listener.mu.Lock()
+		n := len(listener.tailers)
+		listener.mu.Unlock()
+		if n == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	listener.mu.Lock()
+	assert.Empty(t, listener.tailers)
+	listener.mu.Unlock()
+	assert.False(t, source.Status.IsError())
+}
@@ -24,6 +24,7 @@ type Tailer struct {
 	outputChan chan *message.Message
 	read       func(*Tailer) ([]byte, error)
 	decoder    *decoder.Decoder
+	frames     *syslogFrameReader // set when the connection carries framed syslog messages
 	stop       chan struct{}
 	done       chan struct{}
 }
@@ -41,6 +42,14 @@ func NewTailer(source *config.LogSource, conn net.Conn, outputChan chan *message
 	}
 }
 
+// newSyslogStreamTailer returns a new Tailer for a connection carrying syslog messages framed following RFC6587,
+// the messages are read with the frames reader and are forwarded without being split on newlines.
+func newSyslogStreamTailer(source *config.LogSource, conn net.Conn, outputChan chan *message.Message, read func(*Tailer) ([]byte, error)) *Tailer {
+	tailer := NewTailer(source, conn, outputChan, read)
+	tailer.frames = newSyslogFrameReader(conn)
+	return tailer
+}
+
 // Start prepares the tailer to read and decode data from the connection
 func (t *Tailer) Start() {
 	go t.forwardMessages()
@@ -62,6 +71,10 @@ func (t *Tailer) forwardMessages() {
 		t.done <- struct{}{}
 	}()
 	for output := range t.decoder.OutputChan {
+		if t.source.Config.Type == config.SyslogType {
+			t.outputChan <- newSyslogMessage(output.Content, t.source)
+			continue
+		}
 		t.outputChan <- message.NewMessageWithSource(output.Content, message.StatusInfo, t.source)
 	}
 }
@@ -88,6 +101,11 @@ func (t *Tailer) readForever() {
 				log.Warnf("Couldn't read message from connection: %v", err)
 				return
 			}
+			if t.frames != nil {
+				// the data is a complete syslog message
+				t.outputChan <- newSyslogMessage(data, t.source)
+				continue
+			}
 			t.decoder.InputChan <- decoder.NewInput(data)
 		}
 	}
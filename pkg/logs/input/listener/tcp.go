@@ -6,7 +6,9 @@
 package listener
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
 	"net"
 	"sync"
 	"time"
@@ -105,7 +107,28 @@ func (l *TCPListener) run() {
 
 // startListener starts a new listener, returns an error if it failed.
 func (l *TCPListener) startListener() error {
-	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", l.source.Config.Port))
+	address := fmt.Sprintf(":%d", l.source.Config.Port)
+	if l.source.Config.Type == config.SyslogType && l.source.Config.Protocol == config.SyslogTLS {
+		return l.startTLSListener(address)
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	l.listener = listener
+	return nil
+}
+
+// startTLSListener starts a new listener accepting TLS connections with the certificate of the source.
+func (l *TCPListener) startTLSListener(address string) error {
+	cert, err := tls.LoadX509KeyPair(l.source.Config.TLSCertFile, l.source.Config.TLSKeyFile)
+	if err != nil {
+		return err
+	}
+	listener, err := tls.Listen("tcp", address, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
 	if err != nil {
 		return err
 	}
@@ -126,11 +149,33 @@ func (l *TCPListener) read(tailer *Tailer) ([]byte, error) {
 	return frame[:n], nil
 }
 
+// readSyslogFrame reads a syslog message framed following RFC6587 from the connection,
+// returns an error if it failed and stop the tailer.
+func (l *TCPListener) readSyslogFrame(tailer *Tailer) ([]byte, error) {
+	tailer.conn.SetReadDeadline(time.Now().Add(defaultTimeout)) //nolint:errcheck
+	frame, err := tailer.frames.next()
+	if err != nil {
+		// the connection closed by the client is not an error of the source
+		if err != io.EOF {
+			l.source.Status.Error(err)
+		}
+		go l.stopTailer(tailer)
+		return nil, err
+	}
+	return frame, nil
+}
+
 // startTailer creates and starts a new tailer that reads from the connection.
 func (l *TCPListener) startTailer(conn net.Conn) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	tailer := NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	var tailer *Tailer
+	if l.source.Config.Type == config.SyslogType {
+		// syslog messages sent over TCP may be octet counted and contain newlines
+		tailer = newSyslogStreamTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.readSyslogFrame)
+	} else {
+		tailer = NewTailer(l.source, conn, l.pipelineProvider.NextPipelineChan(), l.read)
+	}
 	l.tailers = append(l.tailers, tailer)
 	tailer.Start()
 }
@@ -34,10 +34,15 @@ ULONGLONG startEventSubscribe(char *channel, char* query, ULONGLONG ullBookmark,
     }
 
 
-	// Subscribe to events beginning with the oldest event in the channel. The subscription
-	// will return all current events in the channel and any future events that are raised
-	// while the application is active.
-	hSubscription = EvtSubscribe(NULL, NULL, pwsChannel, pwsQuery, NULL, ctx,
+	// The bookmark is only valid along with the EvtSubscribeStartAfterBookmark flag,
+	// EvtSubscribe fails with an invalid parameter otherwise.
+	if (flags != EvtSubscribeStartAfterBookmark) {
+		hBookmark = NULL;
+	}
+
+	// Subscribe to the events after the bookmark, or to the future events only. The subscription
+	// will return the matching events of the channel raised while the application is active.
+	hSubscription = EvtSubscribe(NULL, NULL, pwsChannel, pwsQuery, hBookmark, ctx,
 		(EVT_SUBSCRIBE_CALLBACK)SubscriptionCallback, flags);
 	if (NULL == hSubscription)
 	{
@@ -10,10 +10,11 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	auditor "github.com/DataDog/datadog-agent/pkg/logs/auditor/mock"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
 )
 
 func TestShouldSanitizeConfig(t *testing.T) {
-	launcher := NewLauncher(config.NewLogSources(), nil)
+	launcher := NewLauncher(config.NewLogSources(), nil, auditor.NewRegistry())
 	assert.Equal(t, "*", launcher.sanitizedConfig(&config.LogsConfig{ChannelPath: "System", Query: ""}).Query)
 }
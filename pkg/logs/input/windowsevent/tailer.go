@@ -55,6 +55,11 @@ type Tailer struct {
 	done       chan struct{}
 
 	context *eventContext
+	// bookmark is the position in the channel of the last event forwarded before the tailer started
+	bookmark string
+	// windows handles of the subscription and of the bookmark updated after each event
+	subscriptionHandle uintptr
+	bookmarkHandle     uintptr
 }
 
 // NewTailer returns a new tailer.
@@ -78,8 +83,9 @@ func (t *Tailer) Identifier() string {
 	return Identifier(t.config.ChannelPath, t.config.Query)
 }
 
-// toMessage converts an XML message into json
-func (t *Tailer) toMessage(re *richEvent) (*message.Message, error) { //nolint:unused
+// toMessage converts an XML message into json,
+// the bookmark is used as the offset of the message to resume after it on restart.
+func (t *Tailer) toMessage(re *richEvent, bookmark string) (*message.Message, error) { //nolint:unused
 	event := re.xmlEvent
 	log.Debug("Rendered XML:", event)
 	mxj.PrependAttrWithHyphen(false)
@@ -131,7 +137,10 @@ func (t *Tailer) toMessage(re *richEvent) (*message.Message, error) { //nolint:u
 	}
 	jsonEvent = replaceTextKeyToValue(jsonEvent)
 	log.Debug("Sending JSON:", string(jsonEvent))
-	return message.NewMessageWithSource(jsonEvent, message.StatusInfo, t.source), nil
+	origin := message.NewOrigin(t.source)
+	origin.Identifier = t.Identifier()
+	origin.Offset = bookmark
+	return message.NewMessage(jsonEvent, origin, message.StatusInfo), nil
 }
 
 // extractDataField transforms the fields parsed from <Data Name='NAME1'>VALUE1</Data><Data Name='NAME2'>VALUE2</Data> to
@@ -14,6 +14,7 @@ package windowsevent
 import "C"
 
 import (
+	"fmt"
 	"syscall"
 	"unicode/utf16"
 	"unsafe"
@@ -22,9 +23,11 @@ import (
 	"golang.org/x/sys/windows"
 )
 
-// Start starts tailing the event log.
-func (t *Tailer) Start() {
+// Start starts tailing the event log after the bookmark,
+// only the future events are tailed if the bookmark is empty or invalid.
+func (t *Tailer) Start(bookmark string) {
 	log.Infof("Starting windows event log tailing for channel %s query %s", t.config.ChannelPath, t.config.Query)
+	t.bookmark = bookmark
 	go t.tail()
 }
 
@@ -40,21 +43,67 @@ func (t *Tailer) tail() {
 	t.context = &eventContext{
 		id: indexForTailer(t),
 	}
-	C.startEventSubscribe(
-		C.CString(t.config.ChannelPath),
-		C.CString(t.config.Query),
-		C.ULONGLONG(0),
-		C.int(EvtSubscribeToFutureEvents),
+	flags := EvtSubscribeToFutureEvents
+	if t.bookmark != "" {
+		handle, err := evtCreateBookmark(t.bookmark)
+		if err != nil {
+			log.Warnf("Could not restore the bookmark of channel %s, tailing new events only: %v", t.config.ChannelPath, err)
+		} else {
+			t.bookmarkHandle = handle
+			flags = EvtSubscribeStartAfterBookmark
+		}
+	}
+	if t.bookmarkHandle == 0 {
+		handle, err := evtCreateBookmark("")
+		if err != nil {
+			log.Warnf("Could not create a bookmark for channel %s, events will not be committed: %v", t.config.ChannelPath, err)
+		}
+		t.bookmarkHandle = handle
+	}
+
+	channelPath := C.CString(t.config.ChannelPath)
+	query := C.CString(t.config.Query)
+	t.subscriptionHandle = uintptr(C.startEventSubscribe(
+		channelPath,
+		query,
+		C.ULONGLONG(t.bookmarkHandle),
+		C.int(flags),
 		C.PVOID(uintptr(unsafe.Pointer(t.context))),
-	)
-	t.source.Status.Success()
+	))
+	C.free(unsafe.Pointer(channelPath))
+	C.free(unsafe.Pointer(query))
+	if t.subscriptionHandle == 0 {
+		t.source.Status.Error(fmt.Errorf("could not subscribe to channel %s with query %s", t.config.ChannelPath, t.config.Query))
+	} else {
+		t.source.Status.Success()
+	}
 
 	// wait for stop signal
 	<-t.stop
+	evtClose(t.subscriptionHandle)
+	evtClose(t.bookmarkHandle)
 	t.done <- struct{}{}
 	return
 }
 
+// updateBookmark moves the bookmark to the event and returns its XML representation.
+func (t *Tailer) updateBookmark(handle C.ULONGLONG) string {
+	if t.bookmarkHandle == 0 {
+		return ""
+	}
+	ret, _, err := procEvtUpdateBookmark.Call(t.bookmarkHandle, uintptr(handle))
+	if ret == 0 {
+		log.Debugf("Could not update the bookmark of channel %s: %v", t.config.ChannelPath, err)
+		return ""
+	}
+	bookmark, err := evtRenderXML(t.bookmarkHandle, EvtRenderBookmark)
+	if err != nil {
+		log.Debugf("Could not render the bookmark of channel %s: %v", t.config.ChannelPath, err)
+		return ""
+	}
+	return bookmark
+}
+
 /*
 	Windows related methods
 */
@@ -87,7 +136,7 @@ func goNotificationCallback(handle C.ULONGLONG, ctx C.PVOID) {
 		log.Warnf("Got invalid eventContext id %d when map is %v", goctx.id, eventContextToTailerMap)
 		return
 	}
-	msg, err := t.toMessage(richEvt)
+	msg, err := t.toMessage(richEvt, t.updateBookmark(handle))
 	if err != nil {
 		log.Warnf("Couldn't convert xml to json: %s for event %s", err, richEvt.xmlEvent)
 		return
@@ -105,8 +154,53 @@ var (
 	procEvtOpenChannelEnum = modWinEvtAPI.NewProc("EvtOpenChannelEnum")
 	procEvtNextChannelPath = modWinEvtAPI.NewProc("EvtNextChannelPath")
 	procEvtNext            = modWinEvtAPI.NewProc("EvtNext")
+	procEvtCreateBookmark  = modWinEvtAPI.NewProc("EvtCreateBookmark")
+	procEvtUpdateBookmark  = modWinEvtAPI.NewProc("EvtUpdateBookmark")
 )
 
+// evtCreateBookmark creates a bookmark from its XML representation,
+// an empty bookmark is created if the XML is empty.
+func evtCreateBookmark(bookmarkXML string) (uintptr, error) {
+	var xml uintptr
+	if bookmarkXML != "" {
+		p, err := windows.UTF16PtrFromString(bookmarkXML)
+		if err != nil {
+			return 0, err
+		}
+		xml = uintptr(unsafe.Pointer(p))
+	}
+	ret, _, err := procEvtCreateBookmark.Call(xml)
+	if ret == 0 {
+		return 0, err
+	}
+	return ret, nil
+}
+
+// evtRenderXML renders a handle to its XML representation.
+func evtRenderXML(h uintptr, flags uintptr) (string, error) {
+	var bufUsed uint32
+	var propCount uint32
+	_, _, err := procEvtRender.Call(uintptr(0), h, flags, uintptr(0), uintptr(0),
+		uintptr(unsafe.Pointer(&bufUsed)), uintptr(unsafe.Pointer(&propCount)))
+	if err != error(windows.ERROR_INSUFFICIENT_BUFFER) {
+		return "", err
+	}
+	buf := make([]uint8, bufUsed)
+	ret, _, err := procEvtRender.Call(uintptr(0), h, flags, uintptr(bufUsed),
+		uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&bufUsed)), uintptr(unsafe.Pointer(&propCount)))
+	if ret == 0 {
+		return "", err
+	}
+	return ConvertWindowsString(buf), nil
+}
+
+// evtClose closes a handle if it is set.
+func evtClose(h uintptr) {
+	if h != 0 {
+		procEvtClose.Call(h) //nolint:errcheck
+	}
+}
+
 // EvtRender takes an event handle and renders it to XML
 func EvtRender(h C.ULONGLONG) (richEvt *richEvent, err error) {
 	var bufSize uint32
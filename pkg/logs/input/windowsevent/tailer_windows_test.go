@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build windows
+
+package windowsevent
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/svc/eventlog"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+const testEventSource = "datadog-agent-tailer-test"
+
+func startTestTailer(t *testing.T, bookmark string) (*Tailer, chan *message.Message) {
+	outputChan := make(chan *message.Message, 10)
+	source := config.NewLogSource("", &config.LogsConfig{})
+	tailer := NewTailer(source, &Config{
+		ChannelPath: "Application",
+		Query:       fmt.Sprintf("*[System[Provider[@Name='%s']]]", testEventSource),
+	}, outputChan)
+	tailer.Start(bookmark)
+	require.Eventually(t, func() bool { return !source.Status.IsPending() }, 5*time.Second, 10*time.Millisecond)
+	require.True(t, source.Status.IsSuccess(), source.Status.GetError())
+	return tailer, outputChan
+}
+
+func receiveEvent(t *testing.T, outputChan chan *message.Message) *message.Message {
+	select {
+	case msg := <-outputChan:
+		return msg
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "no event received")
+	}
+	return nil
+}
+
+func TestTailerRestartsAfterBookmark(t *testing.T) {
+	if err := eventlog.InstallAsEventCreate(testEventSource, eventlog.Info); err != nil {
+		t.Skipf("could not register the event source, the test must run as an administrator: %v", err)
+	}
+	defer eventlog.Remove(testEventSource) //nolint:errcheck
+	evtLog, err := eventlog.Open(testEventSource)
+	require.NoError(t, err)
+	defer evtLog.Close()
+
+	tailer, outputChan := startTestTailer(t, "")
+	require.NoError(t, evtLog.Info(1, "first event"))
+	msg := receiveEvent(t, outputChan)
+	assert.Contains(t, string(msg.Content), "first event")
+	bookmark := msg.Origin.Offset
+	assert.NotEmpty(t, bookmark)
+	tailer.Stop()
+
+	// the event raised while the tailer is stopped is collected after the restart
+	require.NoError(t, evtLog.Info(1, "second event"))
+	tailer, outputChan = startTestTailer(t, bookmark)
+	defer tailer.Stop()
+	msg = receiveEvent(t, outputChan)
+	assert.Contains(t, string(msg.Content), "second event")
+	assert.NotEqual(t, bookmark, msg.Origin.Offset)
+}
@@ -16,6 +16,8 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
 	"github.com/DataDog/datadog-agent/pkg/logs/scheduler"
 	"github.com/DataDog/datadog-agent/pkg/logs/service"
 	"github.com/DataDog/datadog-agent/pkg/logs/status"
@@ -128,11 +130,28 @@ func IsAgentRunning() bool {
 	return status.Get().IsRunning
 }
 
+// GetPipelineProvider returns the pipelines of the logs-agent, nil when it isn't running
+func GetPipelineProvider() pipeline.Provider {
+	if agent == nil {
+		return nil
+	}
+	return agent.pipelineProvider
+}
+
 // GetStatus returns logs-agent status
 func GetStatus() status.Status {
 	return status.Get()
 }
 
+// GetMessageReceiver returns the receiver of the processed logs,
+// nil if the logs-agent is not running.
+func GetMessageReceiver() *diagnostic.BufferedMessageReceiver {
+	if agent == nil {
+		return nil
+	}
+	return agent.diagnosticMessageReceiver
+}
+
 // GetScheduler returns the logs-config scheduler if set.
 func GetScheduler() *scheduler.Scheduler {
 	return adScheduler
@@ -12,6 +12,8 @@ type Message struct {
 	Content []byte
 	Origin  *Origin
 	status  string
+	// Attributes holds the structured attributes extracted from the content by the processing rules
+	Attributes map[string]string
 }
 
 // NewMessageWithSource constructs message with content, status and log source.
@@ -28,6 +30,14 @@ func NewMessage(content []byte, origin *Origin, status string) *Message {
 	}
 }
 
+// SetAttribute sets a structured attribute of the message.
+func (m *Message) SetAttribute(key, value string) {
+	if m.Attributes == nil {
+		m.Attributes = make(map[string]string)
+	}
+	m.Attributes[key] = value
+}
+
 // GetStatus gets the status of the message.
 // if status is not set, StatusInfo will be returned.
 func (m *Message) GetStatus() string {
@@ -36,3 +46,11 @@ func (m *Message) GetStatus() string {
 	}
 	return m.status
 }
+
+// SourceMetrics returns the metrics of the source of the message, nil if the source is unknown.
+func (m *Message) SourceMetrics() *config.SourceMetrics {
+	if m.Origin == nil || m.Origin.LogSource == nil {
+		return nil
+	}
+	return m.Origin.LogSource.Metrics
+}
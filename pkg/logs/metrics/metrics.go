@@ -25,6 +25,12 @@ var (
 	TlmLogsProcessed = telemetry.NewCounter("logs", "processed",
 		nil, "Total number of processed logs")
 
+	// LogsExcluded is the total number of logs excluded by the processing rules.
+	LogsExcluded = expvar.Int{}
+	// TlmLogsExcluded is the total number of logs excluded by the processing rules.
+	TlmLogsExcluded = telemetry.NewCounter("logs", "excluded",
+		nil, "Total number of logs excluded by the processing rules")
+
 	// LogsSent is the total number of sent logs.
 	LogsSent = expvar.Int{}
 	// TlmLogsSent is the total number of sent logs.
@@ -40,6 +46,12 @@ var (
 	// TlmLogsDropped is the total number of logs dropped per Destination
 	TlmLogsDropped = telemetry.NewCounter("logs", "dropped",
 		[]string{"destination"}, "Total number of logs dropped per Destination")
+	// DestinationPayloadsDropped is the total number of payloads dropped per HTTP Destination,
+	// a payload holds a batch of logs
+	DestinationPayloadsDropped = expvar.Map{}
+	// TlmPayloadsDropped is the total number of payloads dropped per HTTP Destination
+	TlmPayloadsDropped = telemetry.NewCounter("logs", "payloads_dropped",
+		[]string{"destination"}, "Total number of payloads dropped per HTTP Destination")
 	// BytesSent is the total number of sent bytes before encoding if any
 	BytesSent = expvar.Int{}
 	// TlmBytesSent is the total number of sent bytes before encoding if any
@@ -51,6 +63,17 @@ var (
 	// TlmEncodedBytesSent is the total number of sent bytes after encoding if any
 	TlmEncodedBytesSent = telemetry.NewCounter("logs", "encoded_bytes_sent",
 		nil, "Total number of sent bytes after encoding if any")
+
+	// DiskBufferSpilled is the total number of logs written to the disk buffers
+	DiskBufferSpilled = expvar.Int{}
+	// TlmDiskBufferSpilled is the total number of logs written to the disk buffers
+	TlmDiskBufferSpilled = telemetry.NewCounter("logs", "disk_buffer_spilled",
+		nil, "Total number of logs written to the disk buffers")
+	// DiskBufferDropped is the total number of logs dropped because the disk buffers were full
+	DiskBufferDropped = expvar.Int{}
+	// TlmDiskBufferDropped is the total number of logs dropped because the disk buffers were full
+	TlmDiskBufferDropped = telemetry.NewCounter("logs", "disk_buffer_dropped",
+		nil, "Total number of logs dropped because the disk buffers were full")
 	// TODO: Add LogsCollected for the total number of collected logs.
 
 )
@@ -59,9 +82,13 @@ func init() {
 	LogsExpvars = expvar.NewMap("logs-agent")
 	LogsExpvars.Set("LogsDecoded", &LogsDecoded)
 	LogsExpvars.Set("LogsProcessed", &LogsProcessed)
+	LogsExpvars.Set("LogsExcluded", &LogsExcluded)
 	LogsExpvars.Set("LogsSent", &LogsSent)
 	LogsExpvars.Set("DestinationErrors", &DestinationErrors)
 	LogsExpvars.Set("DestinationLogsDropped", &DestinationLogsDropped)
+	LogsExpvars.Set("DestinationPayloadsDropped", &DestinationPayloadsDropped)
 	LogsExpvars.Set("BytesSent", &BytesSent)
 	LogsExpvars.Set("EncodedBytesSent", &EncodedBytesSent)
+	LogsExpvars.Set("DiskBufferSpilled", &DiskBufferSpilled)
+	LogsExpvars.Set("DiskBufferDropped", &DiskBufferDropped)
 }
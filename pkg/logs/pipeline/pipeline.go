@@ -6,24 +6,32 @@
 package pipeline
 
 import (
+	"fmt"
+	"path/filepath"
+
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/http"
 	"github.com/DataDog/datadog-agent/pkg/logs/client/tcp"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
+	"github.com/DataDog/datadog-agent/pkg/logs/diskqueue"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/processor"
 	"github.com/DataDog/datadog-agent/pkg/logs/sender"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
 // Pipeline processes and sends messages to the backend
 type Pipeline struct {
 	InputChan chan *message.Message
 	processor *processor.Processor
+	diskQueue *diskqueue.Queue
 	sender    *sender.Sender
 }
 
-// NewPipeline returns a new Pipeline
-func NewPipeline(outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext) *Pipeline {
+// NewPipeline returns a new Pipeline, the logs the sender can not keep up with are buffered
+// on disk when diskBuffer is not nil.
+func NewPipeline(pipelineID int, outputChan chan *message.Message, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, sources *config.LogSources, diskBuffer *config.DiskBufferConfig, diagnosticMessageReceiver diagnostic.MessageReceiver) *Pipeline {
 	var destinations *client.Destinations
 	if endpoints.UseHTTP {
 		main := http.NewDestination(endpoints.Main, http.JSONContentType, destinationsContext)
@@ -45,7 +53,7 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 
 	var strategy sender.Strategy
 	if endpoints.UseHTTP {
-		strategy = sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait)
+		strategy = sender.NewBatchStrategy(sender.ArraySerializer, endpoints.BatchWait, endpoints.BatchMaxConcurrentSend, endpoints.BatchMaxSize, endpoints.BatchMaxContentSize)
 	} else {
 		strategy = sender.StreamStrategy
 	}
@@ -60,12 +68,28 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 		encoder = processor.RawEncoder
 	}
 
+	processorChan := senderChan
+	var queue *diskqueue.Queue
+	if diskBuffer != nil {
+		queueConfig := *diskBuffer
+		queueConfig.Path = filepath.Join(diskBuffer.Path, fmt.Sprintf("pipeline_%d", pipelineID))
+		queueChan := make(chan *message.Message, config.ChanSize)
+		var err error
+		queue, err = diskqueue.New(queueChan, senderChan, sources, &queueConfig)
+		if err != nil {
+			log.Warnf("Could not set up the disk buffer in %s, logs will only be buffered in memory: %v", queueConfig.Path, err)
+		} else {
+			processorChan = queueChan
+		}
+	}
+
 	inputChan := make(chan *message.Message, config.ChanSize)
-	processor := processor.New(inputChan, senderChan, processingRules, encoder)
+	processor := processor.New(inputChan, processorChan, processingRules, encoder, diagnosticMessageReceiver)
 
 	return &Pipeline{
 		InputChan: inputChan,
 		processor: processor,
+		diskQueue: queue,
 		sender:    sender,
 	}
 }
@@ -73,11 +97,17 @@ func NewPipeline(outputChan chan *message.Message, processingRules []*config.Pro
 // Start launches the pipeline
 func (p *Pipeline) Start() {
 	p.sender.Start()
+	if p.diskQueue != nil {
+		p.diskQueue.Start()
+	}
 	p.processor.Start()
 }
 
 // Stop stops the pipeline
 func (p *Pipeline) Stop() {
 	p.processor.Stop()
+	if p.diskQueue != nil {
+		p.diskQueue.Stop()
+	}
 	p.sender.Stop()
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/auditor"
 	"github.com/DataDog/datadog-agent/pkg/logs/client"
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/restart"
 )
@@ -29,21 +30,30 @@ type provider struct {
 	outputChan        chan *message.Message
 	processingRules   []*config.ProcessingRule
 	endpoints         *config.Endpoints
+	sources           *config.LogSources
+	diskBuffer        *config.DiskBufferConfig
+
+	diagnosticMessageReceiver diagnostic.MessageReceiver
 
 	pipelines            []*Pipeline
 	currentPipelineIndex int32
 	destinationsContext  *client.DestinationsContext
 }
 
-// NewProvider returns a new Provider
-func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext) Provider {
+// NewProvider returns a new Provider, diskBuffer can be nil to only buffer logs in memory,
+// sources are used to attach the logs read back from the disk buffer to their live source,
+// and diagnosticMessageReceiver can be nil when the processed logs do not need to be streamed.
+func NewProvider(numberOfPipelines int, auditor *auditor.Auditor, processingRules []*config.ProcessingRule, endpoints *config.Endpoints, destinationsContext *client.DestinationsContext, sources *config.LogSources, diskBuffer *config.DiskBufferConfig, diagnosticMessageReceiver diagnostic.MessageReceiver) Provider {
 	return &provider{
-		numberOfPipelines:   numberOfPipelines,
-		auditor:             auditor,
-		processingRules:     processingRules,
-		endpoints:           endpoints,
-		pipelines:           []*Pipeline{},
-		destinationsContext: destinationsContext,
+		numberOfPipelines:         numberOfPipelines,
+		auditor:                   auditor,
+		processingRules:           processingRules,
+		endpoints:                 endpoints,
+		sources:                   sources,
+		diskBuffer:                diskBuffer,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
+		pipelines:                 []*Pipeline{},
+		destinationsContext:       destinationsContext,
 	}
 }
 
@@ -53,7 +63,7 @@ func (p *provider) Start() {
 	p.outputChan = p.auditor.Channel()
 
 	for i := 0; i < p.numberOfPipelines; i++ {
-		pipeline := NewPipeline(p.outputChan, p.processingRules, p.endpoints, p.destinationsContext)
+		pipeline := NewPipeline(i, p.outputChan, p.processingRules, p.endpoints, p.destinationsContext, p.sources, p.diskBuffer, p.diagnosticMessageReceiver)
 		pipeline.Start()
 		p.pipelines = append(p.pipelines, pipeline)
 	}
@@ -227,3 +227,23 @@ func TestEncoderToValidUTF8(t *testing.T) {
 	assert.Equal(t, "a���z", toValidUtf8([]byte("a\xed\xa0\x80z")))
 	assert.Equal(t, "a����z", toValidUtf8([]byte("a\xf0\x8f\xbf\xbfz")))
 }
+
+func TestJsonEncoderWithAttributes(t *testing.T) {
+	source := config.NewLogSource("", &config.LogsConfig{Service: "Service"})
+
+	msg := newMessage([]byte("message"), source, message.StatusInfo)
+	msg.SetAttribute("user", "foo")
+	msg.SetAttribute("service", "bar")
+
+	jsonMessage, err := JSONEncoder.Encode(msg, []byte("redacted"))
+	assert.Nil(t, err)
+
+	fields := make(map[string]interface{})
+	err = json.Unmarshal(jsonMessage, &fields)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "foo", fields["user"])
+	assert.Equal(t, "redacted", fields["message"])
+	// reserved fields can not be overridden
+	assert.Equal(t, "Service", fields["service"])
+}
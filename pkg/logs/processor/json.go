@@ -33,7 +33,7 @@ type jsonPayload struct {
 
 // Encode encodes a message into a JSON byte array.
 func (j *jsonEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte, error) {
-	return json.Marshal(jsonPayload{
+	payload := jsonPayload{
 		Message:   toValidUtf8(redactedMsg),
 		Status:    msg.GetStatus(),
 		Timestamp: time.Now().UTC().UnixNano() / nanoToMillis,
@@ -41,5 +41,26 @@ func (j *jsonEncoder) Encode(msg *message.Message, redactedMsg []byte) ([]byte,
 		Service:   msg.Origin.Service(),
 		Source:    msg.Origin.Source(),
 		Tags:      msg.Origin.TagsToString(),
-	})
+	}
+	if len(msg.Attributes) == 0 {
+		return json.Marshal(payload)
+	}
+	return json.Marshal(withAttributes(payload, msg.Attributes))
+}
+
+// withAttributes returns the payload with the attributes of the message as top-level fields,
+// the attributes can not override the reserved fields of the payload.
+func withAttributes(payload jsonPayload, attributes map[string]string) map[string]interface{} {
+	fields := make(map[string]interface{}, len(attributes)+7)
+	for key, value := range attributes {
+		fields[key] = value
+	}
+	fields["message"] = payload.Message
+	fields["status"] = payload.Status
+	fields["timestamp"] = payload.Timestamp
+	fields["hostname"] = payload.Hostname
+	fields["service"] = payload.Service
+	fields["ddsource"] = payload.Source
+	fields["ddtags"] = payload.Tags
+	return fields
 }
@@ -9,6 +9,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 )
@@ -16,21 +17,24 @@ import (
 // A Processor updates messages from an inputChan and pushes
 // in an outputChan.
 type Processor struct {
-	inputChan       chan *message.Message
-	outputChan      chan *message.Message
-	processingRules []*config.ProcessingRule
-	encoder         Encoder
-	done            chan struct{}
+	inputChan                 chan *message.Message
+	outputChan                chan *message.Message
+	processingRules           []*config.ProcessingRule
+	encoder                   Encoder
+	diagnosticMessageReceiver diagnostic.MessageReceiver
+	done                      chan struct{}
 }
 
-// New returns an initialized Processor.
-func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder) *Processor {
+// New returns an initialized Processor, the processed messages are also
+// handed to diagnosticMessageReceiver when it is not nil.
+func New(inputChan, outputChan chan *message.Message, processingRules []*config.ProcessingRule, encoder Encoder, diagnosticMessageReceiver diagnostic.MessageReceiver) *Processor {
 	return &Processor{
-		inputChan:       inputChan,
-		outputChan:      outputChan,
-		processingRules: processingRules,
-		encoder:         encoder,
-		done:            make(chan struct{}),
+		inputChan:                 inputChan,
+		outputChan:                outputChan,
+		processingRules:           processingRules,
+		encoder:                   encoder,
+		diagnosticMessageReceiver: diagnosticMessageReceiver,
+		done:                      make(chan struct{}),
 	}
 }
 
@@ -58,14 +62,23 @@ func (p *Processor) run() {
 			metrics.LogsProcessed.Add(1)
 			metrics.TlmLogsProcessed.Inc()
 
+			if p.diagnosticMessageReceiver != nil {
+				p.diagnosticMessageReceiver.HandleMessage(msg, redactedMsg)
+			}
+
 			// Encode the message to its final format
 			content, err := p.encoder.Encode(msg, redactedMsg)
 			if err != nil {
 				log.Error("unable to encode msg ", err)
+				msg.SourceMetrics().AddDropped()
 				continue
 			}
 			msg.Content = content
 			p.outputChan <- msg
+		} else {
+			metrics.LogsExcluded.Add(1)
+			metrics.TlmLogsExcluded.Inc()
+			msg.SourceMetrics().AddDropped()
 		}
 	}
 }
@@ -74,20 +87,46 @@ func (p *Processor) run() {
 // and a copy of the message with some fields redacted, depending on config
 func (p *Processor) applyRedactingRules(msg *message.Message) (bool, []byte) {
 	content := msg.Content
-	rules := append(p.processingRules, msg.Origin.LogSource.Config.ProcessingRules...)
-	for _, rule := range rules {
-		switch rule.Type {
-		case config.ExcludeAtMatch:
-			if rule.Regex.Match(content) {
-				return false, nil
+	ruleSets := [][]*config.ProcessingRule{p.processingRules, msg.Origin.LogSource.Config.ProcessingRules}
+	for _, rules := range ruleSets {
+		for _, rule := range rules {
+			switch rule.Type {
+			case config.ExcludeAtMatch:
+				if rule.Regex.Match(content) {
+					return false, nil
+				}
+			case config.IncludeAtMatch:
+				if !rule.Regex.Match(content) {
+					return false, nil
+				}
+			case config.MaskSequences:
+				content = rule.Regex.ReplaceAll(content, rule.Placeholder)
 			}
-		case config.IncludeAtMatch:
-			if !rule.Regex.Match(content) {
-				return false, nil
+		}
+	}
+	// the attributes are extracted once all the sequences are masked,
+	// whatever the order of the rules, so that they never hold an unmasked value
+	for _, rules := range ruleSets {
+		for _, rule := range rules {
+			if rule.Type == config.ParseAndExtract {
+				extractAttributes(msg, rule, content)
 			}
-		case config.MaskSequences:
-			content = rule.Regex.ReplaceAll(content, rule.Placeholder)
 		}
 	}
 	return true, content
 }
+
+// extractAttributes sets the values of the named capture groups of the rule
+// as attributes of the message, groups that did not participate in the match are ignored.
+func extractAttributes(msg *message.Message, rule *config.ProcessingRule, content []byte) {
+	matches := rule.Regex.FindSubmatchIndex(content)
+	if matches == nil {
+		return
+	}
+	for i, name := range rule.Regex.SubexpNames() {
+		if name == "" || matches[2*i] < 0 {
+			continue
+		}
+		msg.SetAttribute(name, string(content[matches[2*i]:matches[2*i+1]]))
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/diagnostic"
 	"github.com/DataDog/datadog-agent/pkg/logs/message"
 	"github.com/stretchr/testify/assert"
 )
@@ -128,6 +129,38 @@ func TestMask(t *testing.T) {
 	assert.Equal(t, []byte("New data added to data_values= on prod"), redactedMessage)
 }
 
+func TestParseAndExtract(t *testing.T) {
+	p := &Processor{}
+
+	source := newSource("parse_and_extract", "", `(?P<method>GET|POST) (?P<path>\S+) (?P<code>\d{3})(?: (?P<duration>\d+)ms)?`)
+	msg := newMessage([]byte("127.0.0.1 GET /api/v1/logs 200"), &source, "")
+	shouldProcess, redactedMessage := p.applyRedactingRules(msg)
+	assert.Equal(t, true, shouldProcess)
+	assert.Equal(t, []byte("127.0.0.1 GET /api/v1/logs 200"), redactedMessage)
+	assert.Equal(t, map[string]string{"method": "GET", "path": "/api/v1/logs", "code": "200"}, msg.Attributes)
+
+	msg = newMessage([]byte("hello world"), &source, "")
+	shouldProcess, _ = p.applyRedactingRules(msg)
+	assert.Equal(t, true, shouldProcess)
+	assert.Nil(t, msg.Attributes)
+
+	// the attributes are extracted from the masked content
+	p = &Processor{processingRules: []*config.ProcessingRule{newProcessingRule("mask_sequences", "[masked]", `password=\S+`)}}
+	source = newSource("parse_and_extract", "", `(?P<credentials>password=\S+)`)
+	msg = newMessage([]byte("login password=secret"), &source, "")
+	_, redactedMessage = p.applyRedactingRules(msg)
+	assert.Equal(t, []byte("login [masked]"), redactedMessage)
+	assert.Nil(t, msg.Attributes)
+
+	// a global extraction rule runs after the masking rules of the source
+	p = &Processor{processingRules: []*config.ProcessingRule{newProcessingRule("parse_and_extract", "", `user=(?P<user>\S+) password=(?P<password>\S+)`)}}
+	source = newSource("mask_sequences", "[masked]", `secret`)
+	msg = newMessage([]byte("login user=foo password=secret"), &source, "")
+	_, redactedMessage = p.applyRedactingRules(msg)
+	assert.Equal(t, []byte("login user=foo password=[masked]"), redactedMessage)
+	assert.Equal(t, map[string]string{"user": "foo", "password": "[masked]"}, msg.Attributes)
+}
+
 func TestTruncate(t *testing.T) {
 	p := &Processor{}
 
@@ -138,6 +171,29 @@ func TestTruncate(t *testing.T) {
 	assert.Equal(t, []byte("hello"), redactedMessage)
 }
 
+func TestRunShouldStreamProcessedMessagesAndCountDroppedOnes(t *testing.T) {
+	inputChan := make(chan *message.Message, 2)
+	outputChan := make(chan *message.Message, 2)
+	receiver := diagnostic.NewBufferedMessageReceiver()
+	lines, unsubscribe := receiver.Subscribe(&diagnostic.Filters{})
+	defer unsubscribe()
+
+	source := newSource("mask_sequences", "[masked]", `password=\S+`)
+	source.Config.ProcessingRules = append(source.Config.ProcessingRules, newProcessingRule("exclude_at_match", "", "debug"))
+	source.Metrics = &config.SourceMetrics{}
+	p := New(inputChan, outputChan, nil, RawEncoder, receiver)
+	p.Start()
+
+	inputChan <- newMessage([]byte("password=foo"), &source, "")
+	inputChan <- newMessage([]byte("debug"), &source, "")
+	p.Stop()
+
+	assert.Len(t, outputChan, 1)
+	assert.Contains(t, <-lines, "Message: [masked]")
+	assert.Len(t, lines, 0)
+	assert.Equal(t, int64(1), source.Metrics.LogsDropped())
+}
+
 func newProcessingRule(ruleType, replacePlaceholder, pattern string) *config.ProcessingRule {
 	return &config.ProcessingRule{
 		Type:               ruleType,
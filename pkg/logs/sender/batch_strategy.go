@@ -6,6 +6,7 @@
 package sender
 
 import (
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -14,24 +15,30 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs/metrics"
 )
 
-const (
-	maxBatchSize   = 200
-	maxContentSize = 1000000
-)
-
 // batchStrategy contains all the logic to send logs in batch.
 type batchStrategy struct {
 	buffer     *MessageBuffer
 	serializer Serializer
 	batchWait  time.Duration
+	// climit limits the number of payloads sent concurrently
+	climit chan struct{}
+	// lastSent is closed once the messages of the last payload have been forwarded,
+	// it makes sure that messages are forwarded in order when payloads are sent concurrently
+	lastSent     chan struct{}
+	pendingSends sync.WaitGroup
 }
 
-// NewBatchStrategy returns a new batchStrategy.
-func NewBatchStrategy(serializer Serializer, batchWait time.Duration) Strategy {
+// NewBatchStrategy returns a new batchStrategy sending up to maxConcurrentSend payloads at the same time,
+// a payload is sent when it holds maxBatchSize messages, maxContentSize bytes or after batchWait.
+func NewBatchStrategy(serializer Serializer, batchWait time.Duration, maxConcurrentSend int, maxBatchSize int, maxContentSize int) Strategy {
+	if maxConcurrentSend < 1 {
+		maxConcurrentSend = 1
+	}
 	return &batchStrategy{
 		buffer:     NewMessageBuffer(maxBatchSize, maxContentSize),
 		serializer: serializer,
 		batchWait:  batchWait,
+		climit:     make(chan struct{}, maxConcurrentSend),
 	}
 }
 
@@ -48,6 +55,8 @@ func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *m
 			if !isOpen {
 				// inputChan has been closed, no more payload are expected
 				s.sendBuffer(outputChan, send)
+				// wait for the payloads in flight
+				s.pendingSends.Wait()
 				return
 			}
 			added := s.buffer.AddMessage(message)
@@ -78,28 +87,51 @@ func (s *batchStrategy) Send(inputChan chan *message.Message, outputChan chan *m
 	}
 }
 
-// sendBuffer sends all the messages that are stored in the buffer and forwards them
-// to the next stage of the pipeline.
+// sendBuffer sends all the messages that are stored in the buffer in the background and forwards them
+// to the next stage of the pipeline, this call blocks while too many payloads are in flight.
 func (s *batchStrategy) sendBuffer(outputChan chan *message.Message, send func([]byte) error) {
 	if s.buffer.IsEmpty() {
 		return
 	}
 
-	messages := s.buffer.GetMessages()
-	defer s.buffer.Clear()
+	messages := make([]*message.Message, len(s.buffer.GetMessages()))
+	copy(messages, s.buffer.GetMessages())
+	s.buffer.Clear()
+
+	payload := s.serializer.Serialize(messages)
+
+	s.climit <- struct{}{}
+	previousSent := s.lastSent
+	sent := make(chan struct{})
+	s.lastSent = sent
+	s.pendingSends.Add(1)
+
+	go func() {
+		defer func() {
+			close(sent)
+			s.pendingSends.Done()
+		}()
+
+		err := send(payload)
+		<-s.climit
 
-	err := send(s.serializer.Serialize(messages))
-	if err != nil {
-		if shouldStopSending(err) {
-			return
+		if previousSent != nil {
+			// forward the messages in the order they were received
+			<-previousSent
+		}
+		if err != nil {
+			if shouldStopSending(err) {
+				return
+			}
+			log.Warnf("Could not send payload: %v", err)
 		}
-		log.Warnf("Could not send payload: %v", err)
-	}
 
-	metrics.LogsSent.Add(int64(len(messages)))
-	metrics.TlmLogsSent.Add(float64(len(messages)))
+		metrics.LogsSent.Add(int64(len(messages)))
+		metrics.TlmLogsSent.Add(float64(len(messages)))
 
-	for _, message := range messages {
-		outputChan <- message
-	}
+		for _, message := range messages {
+			message.SourceMetrics().AddSent(len(message.Content))
+			outputChan <- message
+		}
+	}()
 }
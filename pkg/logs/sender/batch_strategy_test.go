@@ -17,11 +17,7 @@ import (
 
 // newBatchStrategyWithLimits returns a new batchStrategy.
 func newBatchStrategyWithLimits(serializer Serializer, batchSize int, contentSize int, batchWait time.Duration) Strategy {
-	return &batchStrategy{
-		buffer:     NewMessageBuffer(batchSize, contentSize),
-		serializer: serializer,
-		batchWait:  batchWait,
-	}
+	return NewBatchStrategy(serializer, batchWait, 1, batchSize, contentSize)
 }
 
 func TestBatchStrategySendsPayloadWhenBufferIsFull(t *testing.T) {
@@ -149,3 +145,30 @@ func TestBatchStrategyShouldNotBlockWhenStoppingGracefully(t *testing.T) {
 
 	newBatchStrategyWithLimits(LineSerializer, 2, 2, 100*time.Millisecond).Send(input, output, success)
 }
+
+func TestBatchStrategyForwardsMessagesInOrderWhenSendingConcurrently(t *testing.T) {
+	input := make(chan *message.Message)
+	output := make(chan *message.Message)
+
+	secondSent := make(chan struct{})
+	send := func(payload []byte) error {
+		if string(payload) == "a" {
+			// the first payload is acknowledged after the second one
+			<-secondSent
+		} else {
+			close(secondSent)
+		}
+		return nil
+	}
+
+	go NewBatchStrategy(LineSerializer, 100*time.Millisecond, 2, 1, 10).Send(input, output, send)
+
+	message1 := message.NewMessage([]byte("a"), nil, "")
+	message2 := message.NewMessage([]byte("b"), nil, "")
+	input <- message1
+	input <- message2
+
+	assert.Equal(t, message1, <-output)
+	assert.Equal(t, message2, <-output)
+	close(input)
+}
@@ -30,6 +30,7 @@ func (s *streamStrategy) Send(inputChan chan *message.Message, outputChan chan *
 		}
 		metrics.LogsSent.Add(1)
 		metrics.TlmLogsSent.Inc()
+		message.SourceMetrics().AddSent(len(message.Content))
 		outputChan <- message
 	}
 }
@@ -128,6 +128,7 @@ func (b *Builder) getIntegrations() []Integration {
 				Status:        b.toString(source.Status),
 				Inputs:        source.GetInputs(),
 				Messages:      source.Messages.GetMessages(),
+				Metrics:       b.getSourceMetrics(source.Metrics),
 			})
 		}
 		integrations = append(integrations, Integration{
@@ -138,6 +139,15 @@ func (b *Builder) getIntegrations() []Integration {
 	return integrations
 }
 
+// getSourceMetrics returns the number of logs and bytes handled by the pipelines for a source.
+func (b *Builder) getSourceMetrics(metrics *config.SourceMetrics) map[string]int64 {
+	return map[string]int64{
+		"LogsSent":    metrics.LogsSent(),
+		"BytesSent":   metrics.BytesSent(),
+		"LogsDropped": metrics.LogsDropped(),
+	}
+}
+
 // groupSourcesByName groups all logs sources by name so that they get properly displayed
 // on the agent status.
 func (b *Builder) groupSourcesByName() map[string][]*config.LogSource {
@@ -170,6 +180,9 @@ func (b *Builder) toDictionary(c *config.LogsConfig) map[string]interface{} {
 	switch c.Type {
 	case config.TCPType, config.UDPType:
 		dictionary["Port"] = c.Port
+	case config.SyslogType:
+		dictionary["Port"] = c.Port
+		dictionary["Protocol"] = c.GetSyslogProtocol()
 	case config.FileType:
 		dictionary["Path"] = c.Path
 		dictionary["TailingMode"] = c.TailingMode
@@ -26,6 +26,7 @@ type Source struct {
 	Status        string                 `json:"status"`
 	Inputs        []string               `json:"inputs"`
 	Messages      []string               `json:"messages"`
+	Metrics       map[string]int64       `json:"metrics"`
 }
 
 // Integration provides some information about a logs integration.
@@ -14,9 +14,10 @@ the check "pushing" to the Agent. See the RFC at docs/proposal/metadata/external
 for more details.
 
 The collector keeps a cache of hostnames mapped to a list of tags called `externalHostCache`
-and exports the function `AddExternalTags` so that entries can be added from
-other packages. This metadata provider is different from the others because it
-doesn't actually collect any info, it only sends whatever it finds stored in the
-cache. The cache is cleared at every collection.
+and exports the function `SetExternalTags` so that entries can be added from
+other packages, e.g. by the checks through their sender. This metadata provider is
+different from the others because it doesn't actually collect any info, it only sends
+whatever it finds stored in the cache. The entries are removed from the cache once
+sent, or once they expire when `external_host_tags_ttl` is set.
 */
 package externalhost
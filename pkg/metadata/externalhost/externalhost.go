@@ -5,20 +5,48 @@
 
 package externalhost
 
-import "sync"
+import (
+	"sort"
+	"sync"
+	"time"
 
-// hostname -> ExternalTags
-type externalHost map[string]ExternalTags
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+// externalHostEntry is the tags of a host for a source type, sent until they expire
+type externalHostEntry struct {
+	tags []string
+	// zero when the tags are only sent once
+	expires time.Time
+}
+
+// hostname -> externalHostEntry
+type externalHost map[string]*externalHostEntry
 
 var (
 	// externalHostCache maps source_type -> externalHost
 	externalHostCache = make(map[string]externalHost)
 	cacheMutex        = &sync.Mutex{}
+
+	// For testing purposes
+	timeNow = time.Now
 )
 
 // SetExternalTags adds external tags for a specific host and source type
-// to the cache.
+// to the cache, replacing the tags previously set for them. The duplicate
+// tags are removed. The tags are sent in every metadata payload until
+// `external_host_tags_ttl` seconds have elapsed, or only in the next one
+// when it's 0.
 func SetExternalTags(hostname, sourceType string, tags []string) {
+	if hostname == "" {
+		return
+	}
+
+	entry := &externalHostEntry{tags: dedupTags(tags)}
+	if ttl := config.Datadog.GetInt("external_host_tags_ttl"); ttl > 0 {
+		entry.expires = timeNow().Add(time.Duration(ttl) * time.Second)
+	}
+
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
@@ -27,23 +55,59 @@ func SetExternalTags(hostname, sourceType string, tags []string) {
 		externalHostCache[sourceType] = make(externalHost)
 	}
 
-	externalHostCache[sourceType][hostname] = ExternalTags{sourceType: tags}
+	externalHostCache[sourceType][hostname] = entry
+}
+
+// dedupTags removes the duplicate tags, keeping the order of their first occurrence
+func dedupTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	deduped := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		deduped = append(deduped, tag)
+	}
+	return deduped
 }
 
-// GetPayload fills and return the external host tags metadata payload
+// GetPayload fills and return the external host tags metadata payload. The
+// tags of a host for all the source types are batched in the same entry. The
+// tags without a TTL and the expired ones are removed from the cache.
 func GetPayload() *Payload {
 	cacheMutex.Lock()
 	defer cacheMutex.Unlock()
 
-	payload := Payload{}
-	for _, extHost := range externalHostCache {
-		for hostname, tags := range extHost {
-			ht := hostTags{hostname, tags}
-			payload = append(payload, ht)
+	now := timeNow()
+	hosts := make(map[string]ExternalTags)
+	for sourceType, extHost := range externalHostCache {
+		for hostname, entry := range extHost {
+			expired := !entry.expires.IsZero() && now.After(entry.expires)
+			if !expired {
+				if _, ok := hosts[hostname]; !ok {
+					hosts[hostname] = ExternalTags{}
+				}
+				hosts[hostname][sourceType] = entry.tags
+			}
+			if expired || entry.expires.IsZero() {
+				delete(extHost, hostname)
+			}
+		}
+		if len(extHost) == 0 {
+			delete(externalHostCache, sourceType)
 		}
 	}
 
-	// clear the cache
-	externalHostCache = make(map[string]externalHost)
+	hostnames := make([]string, 0, len(hosts))
+	for hostname := range hosts {
+		hostnames = append(hostnames, hostname)
+	}
+	sort.Strings(hostnames)
+
+	payload := Payload{}
+	for _, hostname := range hostnames {
+		payload = append(payload, hostTags{hostname, hosts[hostname]})
+	}
 	return &payload
 }
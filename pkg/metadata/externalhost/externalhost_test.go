@@ -7,8 +7,12 @@ package externalhost
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
 func TestGetPayload(t *testing.T) {
@@ -32,3 +36,46 @@ func TestGetPayload(t *testing.T) {
 	// GetPayload is supposed to empty the cache
 	assert.Len(t, externalHostCache, 0)
 }
+
+func TestGetPayloadDedupAndBatch(t *testing.T) {
+	defer GetPayload()
+
+	SetExternalTags("vm2", "vsphere", []string{"foo", "bar", "foo"})
+	SetExternalTags("vm1", "vsphere", []string{"baz"})
+	SetExternalTags("vm1", "openstack", []string{"qux"})
+	// ignored, no hostname
+	SetExternalTags("", "vsphere", []string{"foo"})
+
+	p := *GetPayload()
+	require.Len(t, p, 2)
+	assert.Equal(t, hostTags{"vm1", ExternalTags{"vsphere": {"baz"}, "openstack": {"qux"}}}, p[0])
+	assert.Equal(t, hostTags{"vm2", ExternalTags{"vsphere": {"foo", "bar"}}}, p[1])
+}
+
+func TestGetPayloadTTL(t *testing.T) {
+	mockConfig := config.Mock()
+	mockConfig.Set("external_host_tags_ttl", 60)
+	defer mockConfig.Set("external_host_tags_ttl", 0)
+
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	SetExternalTags("vm1", "vsphere", []string{"foo"})
+
+	// sent until they expire
+	assert.Len(t, *GetPayload(), 1)
+	now = now.Add(30 * time.Second)
+	assert.Len(t, *GetPayload(), 1)
+
+	// replaced by the latest tags
+	SetExternalTags("vm1", "vsphere", []string{"bar"})
+	now = now.Add(45 * time.Second)
+	p := *GetPayload()
+	require.Len(t, p, 1)
+	assert.Equal(t, hostTags{"vm1", ExternalTags{"vsphere": {"bar"}}}, p[0])
+
+	now = now.Add(30 * time.Second)
+	assert.Len(t, *GetPayload(), 0)
+	assert.Len(t, externalHostCache, 0)
+}
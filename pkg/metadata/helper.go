@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -127,5 +128,9 @@ func SetupMetadataCollection(sch *Scheduler, additionalCollectors []string) erro
 			return err
 		}
 	}
+
+	if sch.IsScheduled("host") {
+		host.StartExternalTagsRefresh(sch)
+	}
 	return nil
 }
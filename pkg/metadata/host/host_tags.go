@@ -52,6 +52,7 @@ func getHostTags() *tags {
 	rawHostTags := config.Datadog.GetStringSlice("tags")
 	hostTags := make([]string, 0, len(rawHostTags))
 	hostTags = appendToHostTags(hostTags, rawHostTags)
+	hostTags = appendToHostTags(hostTags, getExternalTags())
 
 	env := config.Datadog.GetString("env")
 	if env != "" {
@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package host
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os/exec"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+type schedulerInterface interface {
+	TriggerAndResetCollectorTimer(name string, delay time.Duration)
+}
+
+var (
+	externalTags       []string
+	externalTagsLoaded bool
+	externalTagsMutex  = &sync.Mutex{}
+)
+
+// parseExternalTags parses the tags of a tags file or of the output of a tags command:
+// one or several comma-separated tags per line, the empty lines and the lines starting
+// with a # being ignored.
+func parseExternalTags(content []byte) []string {
+	tags := []string{}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		for _, tag := range strings.Split(line, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// readTagsFile returns the tags of the tags file
+func readTagsFile(path string) ([]string, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the tags file %q: %s", path, err)
+	}
+	return parseExternalTags(content), nil
+}
+
+// runTagsCommand returns the tags printed by the tags command on its standard output
+func runTagsCommand(command string, timeout time.Duration) ([]string, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty tags command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("unable to run the tags command %q: %s, stderr: %q", command, err, stderr.String())
+	}
+	return parseExternalTags(output), nil
+}
+
+// loadExternalTags returns the sorted and deduplicated tags of the tags file and of the tags command.
+// A source failing to load doesn't prevent the tags of the other one from being returned.
+func loadExternalTags() []string {
+	var tags []string
+
+	if path := config.Datadog.GetString("tags_file"); path != "" {
+		fileTags, err := readTagsFile(path)
+		if err != nil {
+			log.Warnf("No host tags from the tags file: %s", err)
+		} else {
+			tags = append(tags, fileTags...)
+		}
+	}
+
+	if command := config.Datadog.GetString("tags_command"); command != "" {
+		timeout := config.Datadog.GetDuration("tags_command_timeout") * time.Second
+		commandTags, err := runTagsCommand(command, timeout)
+		if err != nil {
+			log.Warnf("No host tags from the tags command: %s", err)
+		} else {
+			tags = append(tags, commandTags...)
+		}
+	}
+
+	sort.Strings(tags)
+	deduped := make([]string, 0, len(tags))
+	for i, tag := range tags {
+		if i == 0 || tag != tags[i-1] {
+			deduped = append(deduped, tag)
+		}
+	}
+	return deduped
+}
+
+// refreshExternalTags reloads the external host tags and returns whether they changed
+func refreshExternalTags() bool {
+	tags := loadExternalTags()
+
+	externalTagsMutex.Lock()
+	defer externalTagsMutex.Unlock()
+	changed := externalTagsLoaded && !reflect.DeepEqual(tags, externalTags)
+	externalTags = tags
+	externalTagsLoaded = true
+	return changed
+}
+
+// getExternalTags returns the host tags loaded from the tags file and the tags command,
+// loading them if they haven't been loaded yet.
+func getExternalTags() []string {
+	externalTagsMutex.Lock()
+	loaded := externalTagsLoaded
+	externalTagsMutex.Unlock()
+	if !loaded {
+		refreshExternalTags()
+	}
+
+	externalTagsMutex.Lock()
+	defer externalTagsMutex.Unlock()
+	return externalTags
+}
+
+// StartExternalTagsRefresh reloads the host tags of the tags file and of the tags command
+// every tags_refresh_interval, and runs the host metadata collector when they change.
+// It does nothing when neither tags_file nor tags_command is set.
+func StartExternalTagsRefresh(sc schedulerInterface) {
+	if config.Datadog.GetString("tags_file") == "" && config.Datadog.GetString("tags_command") == "" {
+		return
+	}
+
+	interval := config.Datadog.GetDuration("tags_refresh_interval") * time.Second
+	if interval <= 0 {
+		log.Infof("tags_refresh_interval is not positive, the external host tags won't be refreshed")
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			if refreshExternalTags() {
+				log.Infof("The external host tags changed, sending the host metadata")
+				sc.TriggerAndResetCollectorTimer("host", 0)
+			}
+		}
+	}()
+}
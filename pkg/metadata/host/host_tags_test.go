@@ -1,10 +1,16 @@
 package host
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestGetHostTags(t *testing.T) {
@@ -57,3 +63,64 @@ func TestGetHostTagsWithEnv(t *testing.T) {
 	assert.NotNil(t, hostTags.System)
 	assert.Equal(t, []string{"tag1:value1", "tag2", "tag3", "env:prod", "env:preprod"}, hostTags.System)
 }
+
+func resetExternalTags() {
+	externalTagsMutex.Lock()
+	defer externalTagsMutex.Unlock()
+	externalTags = nil
+	externalTagsLoaded = false
+}
+
+func TestParseExternalTags(t *testing.T) {
+	content := []byte("# exported from the CMDB\nrack:r12, team:infra\n\n  owner:alice  \n,\n")
+	assert.Equal(t, []string{"rack:r12", "team:infra", "owner:alice"}, parseExternalTags(content))
+	assert.Equal(t, []string{}, parseExternalTags(nil))
+}
+
+func TestGetHostTagsWithTagsFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "host-tags")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "tags")
+	require.NoError(t, ioutil.WriteFile(path, []byte("team:infra\nrack:r12,tag2\n"), 0644))
+
+	mockConfig := config.Mock()
+	mockConfig.Set("tags", []string{"tag1:value1", "tag2"})
+	mockConfig.Set("tags_file", path)
+	defer mockConfig.Set("tags", nil)
+	defer mockConfig.Set("tags_file", "")
+	resetExternalTags()
+	defer resetExternalTags()
+
+	hostTags := getHostTags()
+	assert.Equal(t, []string{"tag1:value1", "tag2", "rack:r12", "tag2", "team:infra"}, hostTags.System)
+
+	// the tags are only reloaded on refresh
+	require.NoError(t, ioutil.WriteFile(path, []byte("team:infra\n"), 0644))
+	assert.Equal(t, []string{"rack:r12", "tag2", "team:infra"}, getExternalTags())
+	assert.True(t, refreshExternalTags())
+	assert.Equal(t, []string{"team:infra"}, getExternalTags())
+	assert.False(t, refreshExternalTags())
+
+	// a missing file doesn't prevent the host tags from being collected
+	require.NoError(t, os.Remove(path))
+	assert.True(t, refreshExternalTags())
+	assert.Equal(t, []string{"tag1:value1", "tag2"}, getHostTags().System)
+}
+
+func TestGetHostTagsWithTagsCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the tags command test relies on echo")
+	}
+
+	mockConfig := config.Mock()
+	mockConfig.Set("tags_command", "echo team:infra,rack:r12")
+	defer mockConfig.Set("tags_command", "")
+	resetExternalTags()
+	defer resetExternalTags()
+
+	assert.Equal(t, []string{"rack:r12", "team:infra"}, getExternalTags())
+
+	_, err := runTagsCommand("false", time.Second)
+	assert.Error(t, err)
+}
@@ -98,6 +98,14 @@ func SetCheckMetadata(checkID, key string, value interface{}) {
 	}
 }
 
+// RemoveCheckMetadata removes the metadata of a check instance from the cache, e.g. when the check is unscheduled.
+func RemoveCheckMetadata(checkID string) {
+	checkCacheMutex.Lock()
+	defer checkCacheMutex.Unlock()
+
+	delete(checkMetadataCache, checkID)
+}
+
 func createCheckInstanceMetadata(checkID, configProvider string) *CheckInstanceMetadata {
 
 	var checkInstanceMetadata CheckInstanceMetadata
@@ -214,6 +214,21 @@ func TestGetPayload(t *testing.T) {
 
 }
 
+func TestRemoveCheckMetadata(t *testing.T) {
+	defer func() { clearMetadata() }()
+
+	SetCheckMetadata("check1_instance1", "check.version", "1.2.3")
+	SetCheckMetadata("stopped_checkid", "check.version", "4.5.6")
+	RemoveCheckMetadata("stopped_checkid")
+
+	p := GetPayload("testHostname", &mockAutoConfig{}, &mockCollector{})
+
+	checkMetadata := *p.CheckMetadata
+	assert.Len(t, checkMetadata, 2)
+	assert.NotContains(t, checkMetadata, "stopped_checkid")
+	assert.Equal(t, "1.2.3", (*checkMetadata["check1"][0])["check.version"])
+}
+
 func TestSetup(t *testing.T) {
 	defer func() { clearMetadata() }()
 
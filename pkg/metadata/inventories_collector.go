@@ -11,6 +11,9 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/serializer"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/capabilities"
+	"github.com/DataDog/datadog-agent/pkg/util/flavor"
+	"github.com/DataDog/datadog-agent/pkg/version"
 )
 
 type inventoriesCollector struct {
@@ -76,6 +79,55 @@ func SetupInventories(sc *Scheduler, ac inventories.AutoConfigInterface, coll in
 		return err
 	}
 
+	inventories.SetAgentMetadata("agent_version", version.AgentVersion)
+	inventories.SetAgentMetadata("flavor", flavor.GetFlavor())
+	if config.Datadog.GetBool("inventories_configuration_enabled") {
+		setAgentConfigurationMetadata()
+	}
+
 	SetupInventoriesExpvar(ac, coll)
 	return nil
 }
+
+// setAgentConfigurationMetadata adds the enabled features and the scrubbed values of some
+// configuration options to the agent metadata
+func setAgentConfigurationMetadata() {
+	features := map[string]bool{
+		"feature_apm_enabled":        config.Datadog.GetBool("apm_config.enabled"),
+		"feature_logs_enabled":       config.Datadog.GetBool("logs_enabled"),
+		"feature_networks_enabled":   config.Datadog.GetBool("network_config.enabled"),
+		"feature_compliance_enabled": config.Datadog.GetBool("compliance_config.enabled"),
+	}
+	for name, enabled := range features {
+		inventories.SetAgentMetadata(name, enabled)
+	}
+	// process_config.enabled is "true", "false" or "disabled"
+	inventories.SetAgentMetadata("feature_process_enabled", config.Datadog.GetString("process_config.enabled"))
+
+	values := map[string]string{
+		"config_site":        config.Datadog.GetString("site"),
+		"config_dd_url":      config.Datadog.GetString("dd_url"),
+		"config_apm_dd_url":  config.Datadog.GetString("apm_config.apm_dd_url"),
+		"config_logs_dd_url": config.Datadog.GetString("logs_config.logs_dd_url"),
+	}
+	if proxies := config.GetProxies(); proxies != nil {
+		values["config_proxy_http"] = proxies.HTTP
+		values["config_proxy_https"] = proxies.HTTPS
+	}
+	for name, value := range values {
+		inventories.SetAgentMetadata(name, scrubConfigValue(value))
+	}
+
+	for _, c := range capabilities.Get().Capabilities {
+		inventories.SetAgentMetadata("capability_"+c.Name, c.Available)
+	}
+}
+
+// scrubConfigValue removes the credentials from a configuration value, e.g. a proxy URL with a password
+func scrubConfigValue(value string) string {
+	scrubbed, err := log.CredentialsCleanerBytes([]byte(value))
+	if err != nil {
+		return ""
+	}
+	return string(scrubbed)
+}
@@ -70,6 +70,8 @@ type MetricSample struct {
 	Host       string
 	SampleRate float64
 	Timestamp  float64
+	// CounterResets is how a decrease of a MonotonicCountType sample is handled
+	CounterResets CounterResets
 }
 
 // Implement the MetricSampleContext interface
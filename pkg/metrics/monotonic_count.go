@@ -5,12 +5,29 @@
 
 package metrics
 
+// CounterResets is how a monotonic count handles a decrease of the raw counter
+type CounterResets int
+
+const (
+	// CounterResetsIgnored ignores the sample following a decrease, the next samples are counted from its value
+	CounterResetsIgnored CounterResets = iota
+	// CounterResetsPossible considers a decrease as a reset of the raw counter to zero, the value of
+	// the sample following it is counted as the increase since the reset
+	CounterResetsPossible
+	// CounterResetsImpossible considers a decrease as a glitch of the raw counter, the sample is dropped
+	// and the next samples are counted from the value before it, so that no increase is counted twice
+	CounterResetsImpossible
+)
+
 // MonotonicCount tracks a raw counter, based on increasing counter values.
-// Samples that have a lower value than the previous sample are ignored (since it usually
-// means that the underlying raw counter has been reset).
+// Samples that have a lower value than the previous sample are handled according to
+// their CounterResets, by default they are ignored (since it usually means that the
+// underlying raw counter has been reset).
 // Example:
 //  submitting samples 2, 3, 6, 7 returns 5 (i.e. 7-2) on flush ;
 //  then submitting samples 10, 11 on the same MonotonicCount returns 4 (i.e. 11-7) on flush
+//  then submitting samples 12, 2, 5 returns 4 (i.e. 12-11 + 5-2) by default, 6 (i.e. 12-11 + 2 + 5-2)
+//  when resets are possible and 1 (i.e. 12-11) when they are not
 type MonotonicCount struct {
 	previousSample        float64
 	currentSample         float64
@@ -31,8 +48,18 @@ func (mc *MonotonicCount) addSample(sample *MetricSample, timestamp float64) {
 	// To handle cases where the samples are not monotonically increasing, we always add the difference
 	// between 2 consecutive samples to the value that'll be flushed (if the difference is >0).
 	diff := mc.currentSample - mc.previousSample
-	if mc.sampledSinceLastFlush && mc.hasPreviousSample && diff > 0. {
+	if !mc.sampledSinceLastFlush || !mc.hasPreviousSample {
+		return
+	}
+	switch {
+	case diff > 0.:
 		mc.value += diff
+	case diff < 0. && sample.CounterResets == CounterResetsPossible && mc.currentSample > 0.:
+		// the raw counter was reset to zero and has increased by its current value since
+		mc.value += mc.currentSample
+	case diff < 0. && sample.CounterResets == CounterResetsImpossible:
+		// drop the sample, the next ones are compared to the previous one
+		mc.currentSample = mc.previousSample
 	}
 }
 
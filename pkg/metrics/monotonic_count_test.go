@@ -66,3 +66,34 @@ func TestMonotonicCountSampling(t *testing.T) {
 		assert.EqualValues(t, 90, series[0].Points[0].Ts)
 	}
 }
+
+func TestMonotonicCountResets(t *testing.T) {
+	for name, tc := range map[string]struct {
+		resets   CounterResets
+		expected float64
+	}{
+		// 4 = (12 - 11) + (5 - 2)
+		"ignored": {CounterResetsIgnored, 4},
+		// 6 = (12 - 11) + 2 + (5 - 2), the counter increased by 2 since its reset
+		"possible": {CounterResetsPossible, 6},
+		// 1 = (12 - 11), the samples lower than 12 are dropped
+		"impossible": {CounterResetsImpossible, 1},
+	} {
+		t.Run(name, func(t *testing.T) {
+			monotonicCount := MonotonicCount{}
+			monotonicCount.addSample(&MetricSample{Value: 7, CounterResets: tc.resets}, 45)
+			monotonicCount.addSample(&MetricSample{Value: 11, CounterResets: tc.resets}, 50)
+			_, err := monotonicCount.flush(60)
+			assert.Nil(t, err)
+
+			monotonicCount.addSample(&MetricSample{Value: 12, CounterResets: tc.resets}, 65)
+			monotonicCount.addSample(&MetricSample{Value: 2, CounterResets: tc.resets}, 70)
+			monotonicCount.addSample(&MetricSample{Value: 5, CounterResets: tc.resets}, 75)
+			series, err := monotonicCount.flush(80)
+			assert.Nil(t, err)
+			if assert.Len(t, series, 1) && assert.Len(t, series[0].Points, 1) {
+				assert.InEpsilon(t, tc.expected, series[0].Points[0].Value, epsilon)
+			}
+		})
+	}
+}
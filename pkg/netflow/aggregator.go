@@ -0,0 +1,186 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// flowPayload is the payload of an aggregated flow sent to the intake
+type flowPayload struct {
+	FlowType     FlowType         `json:"type"`
+	SamplingRate uint64           `json:"sampling_rate"`
+	Start        uint64           `json:"start"`
+	End          uint64           `json:"end"`
+	Bytes        uint64           `json:"bytes"`
+	Packets      uint64           `json:"packets"`
+	EtherType    string           `json:"ether_type,omitempty"`
+	IPProtocol   string           `json:"ip_protocol"`
+	Exporter     exporterPayload  `json:"exporter"`
+	Source       endpointPayload  `json:"source"`
+	Destination  endpointPayload  `json:"destination"`
+	Ingress      interfacePayload `json:"ingress"`
+	Egress       interfacePayload `json:"egress"`
+	Tos          uint8            `json:"tos"`
+	TCPFlags     []string         `json:"tcp_flags,omitempty"`
+	Host         string           `json:"host"`
+}
+
+type exporterPayload struct {
+	IP   string   `json:"ip"`
+	Tags []string `json:"tags,omitempty"`
+}
+
+type endpointPayload struct {
+	IP   string `json:"ip"`
+	Port uint16 `json:"port"`
+}
+
+type interfacePayload struct {
+	Index uint32 `json:"index"`
+}
+
+var ipProtocolNames = map[uint32]string{
+	1:  "ICMP",
+	6:  "TCP",
+	17: "UDP",
+	58: "ICMPv6",
+}
+
+var etherTypeNames = map[uint32]string{
+	etherTypeIPv4: "IPv4",
+	etherTypeIPv6: "IPv6",
+}
+
+var tcpFlagNames = []string{"FIN", "SYN", "RST", "PSH", "ACK", "URG", "ECE", "CWR"}
+
+func buildPayload(f *Flow, hostname string) flowPayload {
+	ipProtocol, found := ipProtocolNames[f.IPProtocol]
+	if !found {
+		ipProtocol = strconv.Itoa(int(f.IPProtocol))
+	}
+	var tcpFlags []string
+	for i, name := range tcpFlagNames {
+		if f.TCPFlags&(1<<uint(i)) != 0 {
+			tcpFlags = append(tcpFlags, name)
+		}
+	}
+	exporter := f.ExporterAddr.String()
+
+	return flowPayload{
+		FlowType:     f.FlowType,
+		SamplingRate: f.SamplingRate,
+		Start:        f.StartTimestamp,
+		End:          f.EndTimestamp,
+		Bytes:        f.Bytes,
+		Packets:      f.Packets,
+		EtherType:    etherTypeNames[f.EtherType],
+		IPProtocol:   ipProtocol,
+		Exporter:     exporterPayload{IP: exporter, Tags: snmp.GetDeviceTags(exporter)},
+		Source:       endpointPayload{IP: f.SrcAddr.String(), Port: f.SrcPort},
+		Destination:  endpointPayload{IP: f.DstAddr.String(), Port: f.DstPort},
+		Ingress:      interfacePayload{Index: f.InputInterface},
+		Egress:       interfacePayload{Index: f.OutputInterface},
+		Tos:          f.Tos,
+		TCPFlags:     tcpFlags,
+		Host:         hostname,
+	}
+}
+
+// aggregator sums the flows with the same key received during a flush interval, and sends them
+// to the network devices intake at the end of the interval
+type aggregator struct {
+	flowIn        chan *Flow
+	flows         map[string]*Flow
+	flushInterval time.Duration
+	sender        epforwarder.EventPlatformForwarder
+	hostname      string
+	stopChan      chan struct{}
+	done          chan struct{}
+}
+
+func newAggregator(sender epforwarder.EventPlatformForwarder, bufferSize int, flushInterval time.Duration, hostname string) *aggregator {
+	return &aggregator{
+		flowIn:        make(chan *Flow, bufferSize),
+		flows:         make(map[string]*Flow),
+		flushInterval: flushInterval,
+		sender:        sender,
+		hostname:      hostname,
+		stopChan:      make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+func (a *aggregator) start() {
+	go a.run()
+}
+
+func (a *aggregator) run() {
+	defer close(a.done)
+	ticker := time.NewTicker(a.flushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case f := <-a.flowIn:
+			a.add(f)
+		case <-ticker.C:
+			a.flush()
+		case <-a.stopChan:
+			// the flows of the current interval are sent before stopping
+			for {
+				select {
+				case f := <-a.flowIn:
+					a.add(f)
+				default:
+					a.flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (a *aggregator) add(f *Flow) {
+	key := f.AggregationKey()
+	if existing, found := a.flows[key]; found {
+		existing.merge(f)
+		return
+	}
+	a.flows[key] = f
+}
+
+func (a *aggregator) flush() {
+	if len(a.flows) == 0 {
+		return
+	}
+	for _, f := range a.flows {
+		payload, err := json.Marshal(buildPayload(f, a.hostname))
+		if err != nil {
+			log.Errorf("Could not serialize a flow: %v", err)
+			continue
+		}
+		if err := a.sender.SendEventPlatformEvent(message.NewMessage(payload, nil, ""), epforwarder.EventTypeNetworkDevicesNetFlow); err != nil {
+			log.Debugf("Could not send a flow: %v", err)
+			continue
+		}
+		tlmFlowsFlushed.Inc()
+	}
+	log.Debugf("Flushed %d flows", len(a.flows))
+	a.flows = make(map[string]*Flow)
+}
+
+// stop flushes the flows received so far and stops the aggregator
+func (a *aggregator) stop() {
+	close(a.stopChan)
+	<-a.done
+}
@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+)
+
+type recordingForwarder struct {
+	events chan string
+}
+
+func (f *recordingForwarder) SendEventPlatformEvent(e *message.Message, eventType string) error {
+	if eventType == epforwarder.EventTypeNetworkDevicesNetFlow {
+		f.events <- string(e.Content)
+	}
+	return nil
+}
+func (f *recordingForwarder) Start() {}
+func (f *recordingForwarder) Stop()  {}
+
+func newTestFlow(bytes uint64, start uint64, tcpFlags uint8) *Flow {
+	return &Flow{
+		FlowType:       TypeNetFlow9,
+		ExporterAddr:   net.ParseIP("10.0.0.1").To4(),
+		StartTimestamp: start,
+		EndTimestamp:   start + 10,
+		Bytes:          bytes,
+		Packets:        1,
+		EtherType:      etherTypeIPv4,
+		IPProtocol:     6,
+		SrcAddr:        net.ParseIP("192.168.1.1").To4(),
+		DstAddr:        net.ParseIP("192.168.1.2").To4(),
+		SrcPort:        12345,
+		DstPort:        443,
+		TCPFlags:       tcpFlags,
+	}
+}
+
+func TestAggregator(t *testing.T) {
+	snmp.SetDeviceTags("10.0.0.1", []string{"snmp_device:10.0.0.1"})
+	defer snmp.DeleteDeviceTags("10.0.0.1")
+
+	forwarder := &recordingForwarder{events: make(chan string, 10)}
+	a := newAggregator(forwarder, 10, time.Hour, "my-host")
+	a.start()
+
+	a.flowIn <- newTestFlow(100, 1600000000, 0x02)
+	a.flowIn <- newTestFlow(200, 1599999990, 0x10)
+	a.stop()
+
+	require.Len(t, forwarder.events, 1)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(<-forwarder.events), &payload))
+	assert.Equal(t, map[string]interface{}{
+		"type":          "netflow9",
+		"sampling_rate": float64(0),
+		"start":         float64(1599999990),
+		"end":           float64(1600000010),
+		"bytes":         float64(300),
+		"packets":       float64(2),
+		"ether_type":    "IPv4",
+		"ip_protocol":   "TCP",
+		"exporter":      map[string]interface{}{"ip": "10.0.0.1", "tags": []interface{}{"snmp_device:10.0.0.1"}},
+		"source":        map[string]interface{}{"ip": "192.168.1.1", "port": float64(12345)},
+		"destination":   map[string]interface{}{"ip": "192.168.1.2", "port": float64(443)},
+		"ingress":       map[string]interface{}{"index": float64(0)},
+		"egress":        map[string]interface{}{"index": float64(0)},
+		"tos":           float64(0),
+		"tcp_flags":     []interface{}{"SYN", "ACK"},
+		"host":          "my-host",
+	}, payload)
+}
+
+func TestAggregatorDistinctFlows(t *testing.T) {
+	forwarder := &recordingForwarder{events: make(chan string, 10)}
+	a := newAggregator(forwarder, 10, time.Hour, "my-host")
+	a.start()
+
+	other := newTestFlow(100, 1600000000, 0)
+	other.DstPort = 80
+	a.flowIn <- newTestFlow(100, 1600000000, 0)
+	a.flowIn <- other
+	a.stop()
+
+	assert.Len(t, forwarder.events, 2)
+}
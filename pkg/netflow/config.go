@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	defaultBindHost = "0.0.0.0"
+	defaultWorkers  = 1
+)
+
+// Config holds the configuration of the flow collection
+type Config struct {
+	StopTimeout             int              `mapstructure:"stop_timeout"`
+	AggregatorBufferSize    int              `mapstructure:"aggregator_buffer_size"`
+	AggregatorFlushInterval int              `mapstructure:"aggregator_flush_interval"`
+	Listeners               []ListenerConfig `mapstructure:"listeners"`
+}
+
+// ListenerConfig holds the configuration of a listener receiving the flows of a type
+type ListenerConfig struct {
+	FlowType FlowType `mapstructure:"flow_type"`
+	BindHost string   `mapstructure:"bind_host"`
+	Port     uint16   `mapstructure:"port"`
+	Workers  int      `mapstructure:"workers"`
+}
+
+// Addr returns the address the listener binds to
+func (c *ListenerConfig) Addr() string {
+	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
+}
+
+// ReadConfig parses the network_devices.netflow configuration
+func ReadConfig() (*Config, error) {
+	var flowConfig Config
+	if err := config.Datadog.UnmarshalKey("network_devices.netflow", &flowConfig); err != nil {
+		return nil, err
+	}
+
+	// Set the default values, we can't otherwise on an array
+	for i := range flowConfig.Listeners {
+		listener := &flowConfig.Listeners[i]
+		defaultPort, found := defaultPorts[listener.FlowType]
+		if !found {
+			return nil, fmt.Errorf("unknown flow type %q, expected one of netflow5, netflow9, ipfix or sflow5", listener.FlowType)
+		}
+		if listener.BindHost == "" {
+			listener.BindHost = defaultBindHost
+		}
+		if listener.Port == 0 {
+			listener.Port = defaultPort
+		}
+		if listener.Workers <= 0 {
+			listener.Workers = defaultWorkers
+		}
+	}
+	return &flowConfig, nil
+}
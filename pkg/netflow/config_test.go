@@ -0,0 +1,54 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func TestReadConfig(t *testing.T) {
+	config.Datadog.SetConfigType("yaml")
+	err := config.Datadog.ReadConfig(strings.NewReader(`
+network_devices:
+  netflow:
+    listeners:
+      - flow_type: netflow9
+      - flow_type: sflow5
+        bind_host: 127.0.0.1
+        port: 1234
+        workers: 4
+`))
+	require.NoError(t, err)
+
+	flowConfig, err := ReadConfig()
+	require.NoError(t, err)
+	assert.Equal(t, 5, flowConfig.StopTimeout)
+	assert.Equal(t, 300, flowConfig.AggregatorFlushInterval)
+	assert.Equal(t, []ListenerConfig{
+		{FlowType: TypeNetFlow9, BindHost: "0.0.0.0", Port: 2055, Workers: 1},
+		{FlowType: TypeSFlow5, BindHost: "127.0.0.1", Port: 1234, Workers: 4},
+	}, flowConfig.Listeners)
+}
+
+func TestReadConfigUnknownFlowType(t *testing.T) {
+	config.Datadog.SetConfigType("yaml")
+	err := config.Datadog.ReadConfig(strings.NewReader(`
+network_devices:
+  netflow:
+    listeners:
+      - flow_type: netflow1
+`))
+	require.NoError(t, err)
+
+	_, err = ReadConfig()
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// decoder decodes the flows of the packets sent by the exporters
+type decoder interface {
+	decode(packet []byte, exporter net.IP) ([]*Flow, error)
+}
+
+func newDecoder(flowType FlowType) (decoder, error) {
+	switch flowType {
+	case TypeNetFlow5:
+		return &netflow5Decoder{}, nil
+	case TypeNetFlow9, TypeIPFIX:
+		// the exporters sending NetFlow v9 usually send IPFIX to the same port too
+		return newTemplateDecoder(), nil
+	case TypeSFlow5:
+		return &sflow5Decoder{}, nil
+	default:
+		return nil, fmt.Errorf("unknown flow type %q", flowType)
+	}
+}
+
+// timeNow is overridden by the tests
+var timeNow = time.Now
+
+// uptimeToTimestamp converts a system uptime of the exporter, in milliseconds, to a unix
+// timestamp in seconds, given the uptime and the time of the exporter when it sent the packet
+func uptimeToTimestamp(unixSecs, sysUptime, uptime uint32) uint64 {
+	elapsed := uint64(sysUptime-uptime) / 1000
+	if elapsed > uint64(unixSecs) {
+		return 0
+	}
+	return uint64(unixSecs) - elapsed
+}
+
+const (
+	netflow5HeaderLength = 24
+	netflow5RecordLength = 48
+)
+
+// netflow5Decoder decodes the NetFlow v5 packets, whose records have a fixed format
+type netflow5Decoder struct{}
+
+func (d *netflow5Decoder) decode(packet []byte, exporter net.IP) ([]*Flow, error) {
+	r := newPacketReader(packet)
+	if version := r.uint16(); version != 5 {
+		return nil, fmt.Errorf("unexpected NetFlow v5 packet version %d", version)
+	}
+	count := int(r.uint16())
+	sysUptime := r.uint32()
+	unixSecs := r.uint32()
+	r.skip(4 + 4 + 1 + 1) // unix nsecs, flow sequence, engine type and id
+	samplingRate := uint64(r.uint16() & 0x3FFF)
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.remaining() < count*netflow5RecordLength {
+		return nil, fmt.Errorf("NetFlow v5 packet announcing %d records too short: %d bytes", count, len(packet))
+	}
+
+	flows := make([]*Flow, 0, count)
+	for i := 0; i < count; i++ {
+		f := &Flow{
+			FlowType:     TypeNetFlow5,
+			ExporterAddr: exporter,
+			SamplingRate: samplingRate,
+			EtherType:    etherTypeIPv4,
+		}
+		f.SrcAddr = r.ip(4)
+		f.DstAddr = r.ip(4)
+		r.skip(4) // next hop
+		f.InputInterface = uint32(r.uint16())
+		f.OutputInterface = uint32(r.uint16())
+		f.Packets = uint64(r.uint32())
+		f.Bytes = uint64(r.uint32())
+		f.StartTimestamp = uptimeToTimestamp(unixSecs, sysUptime, r.uint32())
+		f.EndTimestamp = uptimeToTimestamp(unixSecs, sysUptime, r.uint32())
+		f.SrcPort = r.uint16()
+		f.DstPort = r.uint16()
+		r.skip(1) // padding
+		f.TCPFlags = r.uint8()
+		f.IPProtocol = uint32(r.uint8())
+		f.Tos = r.uint8()
+		r.skip(2 + 2 + 1 + 1 + 2) // AS numbers, masks and padding
+		flows = append(flows, f)
+	}
+	return flows, r.err
+}
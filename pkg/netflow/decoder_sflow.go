@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"fmt"
+	"net"
+)
+
+const (
+	sflowAddressIPv4 = 1
+	sflowAddressIPv6 = 2
+
+	sflowFlowSample         = 1
+	sflowExpandedFlowSample = 3
+	sflowRawPacketHeader    = 1
+
+	sflowHeaderProtocolEthernet = 1
+
+	etherTypeVLAN = 0x8100
+	ipProtocolTCP = 6
+	ipProtocolUDP = 17
+)
+
+// sflow5Decoder decodes the sFlow v5 packets. sFlow samples the packets instead of tracking flows,
+// a flow is decoded from the header of each sampled packet.
+type sflow5Decoder struct{}
+
+func (d *sflow5Decoder) decode(packet []byte, exporter net.IP) ([]*Flow, error) {
+	r := newPacketReader(packet)
+	if version := r.uint32(); version != 5 {
+		return nil, fmt.Errorf("unexpected sFlow packet version %d", version)
+	}
+	// the agent address identifies the device when the packets are relayed
+	switch addressType := r.uint32(); addressType {
+	case sflowAddressIPv4:
+		exporter = r.ip(net.IPv4len)
+	case sflowAddressIPv6:
+		exporter = r.ip(net.IPv6len)
+	default:
+		return nil, fmt.Errorf("unknown sFlow agent address type %d", addressType)
+	}
+	r.skip(4 + 4 + 4) // sub agent id, sequence and uptime
+	sampleCount := int(r.uint32())
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	now := uint64(timeNow().Unix())
+	var flows []*Flow
+	for i := 0; i < sampleCount; i++ {
+		format := r.uint32()
+		sample := r.sub(int(r.uint32()))
+		if r.err != nil {
+			return flows, r.err
+		}
+		// the samples of other enterprises than sFlow have a non zero enterprise in their 20 first bits
+		if format>>12 != 0 {
+			continue
+		}
+
+		f := &Flow{FlowType: TypeSFlow5, ExporterAddr: exporter, StartTimestamp: now, EndTimestamp: now, Packets: 1}
+		switch format & 0xFFF {
+		case sflowFlowSample:
+			sample.skip(4 + 4) // sequence and source id
+			f.SamplingRate = uint64(sample.uint32())
+			sample.skip(4 + 4) // sample pool and drops
+			f.InputInterface = sample.uint32()
+			f.OutputInterface = sample.uint32()
+		case sflowExpandedFlowSample:
+			sample.skip(4 + 4 + 4) // sequence, source id type and index
+			f.SamplingRate = uint64(sample.uint32())
+			sample.skip(4 + 4 + 4) // sample pool, drops and input format
+			f.InputInterface = sample.uint32()
+			sample.skip(4) // output format
+			f.OutputInterface = sample.uint32()
+		default:
+			// counter samples
+			continue
+		}
+
+		if decodeFlowRecords(sample, f) {
+			flows = append(flows, f)
+		}
+		if sample.err != nil {
+			return flows, sample.err
+		}
+	}
+	return flows, nil
+}
+
+// decodeFlowRecords decodes the raw packet header record of a flow sample, returns false if the
+// sample doesn't have one
+func decodeFlowRecords(sample *packetReader, f *Flow) bool {
+	found := false
+	recordCount := int(sample.uint32())
+	for i := 0; i < recordCount && sample.err == nil; i++ {
+		format := sample.uint32()
+		record := sample.sub(int(sample.uint32()))
+		if format != sflowRawPacketHeader {
+			continue
+		}
+		protocol := record.uint32()
+		f.Bytes = uint64(record.uint32())
+		record.skip(4) // stripped
+		header := record.bytes(int(record.uint32()))
+		if record.err != nil {
+			return false
+		}
+		if protocol == sflowHeaderProtocolEthernet {
+			decodeEthernetHeader(newPacketReader(header), f)
+		}
+		found = true
+	}
+	return found
+}
+
+// decodeEthernetHeader decodes the addresses, ports and flags of the sampled packet, the header
+// being truncated by the exporter the fields it doesn't include are left empty
+func decodeEthernetHeader(r *packetReader, f *Flow) {
+	r.skip(6 + 6) // MAC addresses
+	etherType := r.uint16()
+	if etherType == etherTypeVLAN {
+		r.skip(2)
+		etherType = r.uint16()
+	}
+	if r.err != nil {
+		return
+	}
+	f.EtherType = uint32(etherType)
+
+	switch etherType {
+	case etherTypeIPv4:
+		versionAndLength := r.uint8()
+		headerLength := int(versionAndLength&0x0F) * 4
+		f.Tos = r.uint8()
+		r.skip(2 + 2 + 2 + 1) // total length, identification, fragment offset and ttl
+		f.IPProtocol = uint32(r.uint8())
+		r.skip(2) // checksum
+		f.SrcAddr = r.ip(net.IPv4len)
+		f.DstAddr = r.ip(net.IPv4len)
+		r.skip(headerLength - 20) // options
+	case etherTypeIPv6:
+		versionAndClass := r.uint16()
+		f.Tos = uint8(versionAndClass >> 4)
+		r.skip(2 + 2) // flow label and payload length
+		f.IPProtocol = uint32(r.uint8())
+		r.skip(1) // hop limit
+		f.SrcAddr = r.ip(net.IPv6len)
+		f.DstAddr = r.ip(net.IPv6len)
+	default:
+		return
+	}
+
+	switch f.IPProtocol {
+	case ipProtocolTCP:
+		f.SrcPort = r.uint16()
+		f.DstPort = r.uint16()
+		r.skip(4 + 4 + 1) // sequence, acknowledgment and data offset
+		f.TCPFlags = r.uint8()
+	case ipProtocolUDP:
+		f.SrcPort = r.uint16()
+		f.DstPort = r.uint16()
+	}
+}
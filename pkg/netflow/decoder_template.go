@@ -0,0 +1,343 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+// The information elements of the templates used to decode the flows, NetFlow v9 and IPFIX share
+// the same numbers for them
+const (
+	fieldInBytes                = 1
+	fieldInPackets              = 2
+	fieldProtocol               = 4
+	fieldTos                    = 5
+	fieldTCPFlags               = 6
+	fieldSrcPort                = 7
+	fieldSrcAddrIPv4            = 8
+	fieldInputInterface         = 10
+	fieldDstPort                = 11
+	fieldDstAddrIPv4            = 12
+	fieldOutputInterface        = 14
+	fieldLastSwitched           = 21
+	fieldFirstSwitched          = 22
+	fieldSrcAddrIPv6            = 27
+	fieldDstAddrIPv6            = 28
+	fieldSamplingInterval       = 34
+	fieldOctetTotalCount        = 85
+	fieldPacketTotalCount       = 86
+	fieldFlowStartSeconds       = 150
+	fieldFlowEndSeconds         = 151
+	fieldFlowStartMilliseconds  = 152
+	fieldFlowEndMilliseconds    = 153
+	fieldEthernetType           = 256
+	fieldSamplingPacketInterval = 305
+)
+
+const (
+	netflow9TemplateSetID        = 0
+	netflow9OptionsTemplateSetID = 1
+	ipfixTemplateSetID           = 2
+	ipfixOptionsTemplateSetID    = 3
+	minDataSetID                 = 256
+
+	// variableLength is the length of the IPFIX fields whose length is encoded in the records
+	variableLength = 0xFFFF
+)
+
+type templateField struct {
+	id     uint16
+	length uint16
+	// enterprise fields are specific to a vendor, they are skipped
+	enterprise bool
+}
+
+type template struct {
+	fields []templateField
+	// options templates describe the exporter, e.g. its sampling interval, and not flows
+	options bool
+}
+
+// minRecordLength returns the smallest length of a record of the template, the records of
+// a set being followed by padding
+func (t *template) minRecordLength() int {
+	length := 0
+	for _, f := range t.fields {
+		if f.length == variableLength {
+			length++
+		} else {
+			length += int(f.length)
+		}
+	}
+	return length
+}
+
+// templateKey identifies a template: the template IDs are only unique for an observation domain,
+// the source ID of NetFlow v9, of an exporter
+type templateKey struct {
+	exporter string
+	version  uint16
+	domain   uint32
+	id       uint16
+}
+
+type domainKey struct {
+	exporter string
+	version  uint16
+	domain   uint32
+}
+
+// templateDecoder decodes the NetFlow v9 and IPFIX packets, whose records are described by the
+// templates the exporters send periodically. The data records received before their template
+// are dropped.
+type templateDecoder struct {
+	sync.Mutex
+	templates     map[templateKey]*template
+	samplingRates map[domainKey]uint64
+}
+
+func newTemplateDecoder() *templateDecoder {
+	return &templateDecoder{
+		templates:     make(map[templateKey]*template),
+		samplingRates: make(map[domainKey]uint64),
+	}
+}
+
+// packetContext holds the header fields of a packet needed to decode its records
+type packetContext struct {
+	flowType  FlowType
+	exporter  net.IP
+	domain    domainKey
+	unixSecs  uint32
+	sysUptime uint32
+}
+
+func (d *templateDecoder) decode(packet []byte, exporter net.IP) ([]*Flow, error) {
+	r := newPacketReader(packet)
+	version := r.uint16()
+	ctx := packetContext{exporter: exporter}
+	switch version {
+	case 9:
+		ctx.flowType = TypeNetFlow9
+		r.skip(2) // count
+		ctx.sysUptime = r.uint32()
+		ctx.unixSecs = r.uint32()
+		r.skip(4) // sequence
+	case 10:
+		ctx.flowType = TypeIPFIX
+		r.skip(2) // length
+		ctx.unixSecs = r.uint32()
+		r.skip(4) // sequence
+	default:
+		return nil, fmt.Errorf("unexpected NetFlow v9 or IPFIX packet version %d", version)
+	}
+	ctx.domain = domainKey{exporter: exporter.String(), version: version, domain: r.uint32()}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	d.Lock()
+	defer d.Unlock()
+
+	var flows []*Flow
+	for r.remaining() >= 4 {
+		setID := r.uint16()
+		length := int(r.uint16())
+		if length < 4 {
+			return flows, fmt.Errorf("invalid set length %d", length)
+		}
+		set := r.sub(length - 4)
+		if r.err != nil {
+			return flows, r.err
+		}
+
+		switch {
+		case setID == netflow9TemplateSetID && version == 9, setID == ipfixTemplateSetID && version == 10:
+			d.decodeTemplates(set, ctx, false)
+		case setID == netflow9OptionsTemplateSetID && version == 9, setID == ipfixOptionsTemplateSetID && version == 10:
+			d.decodeTemplates(set, ctx, true)
+		case setID >= minDataSetID:
+			flows = append(flows, d.decodeDataSet(set, setID, ctx)...)
+		}
+		if set.err != nil {
+			return flows, set.err
+		}
+	}
+	return flows, nil
+}
+
+func (d *templateDecoder) decodeTemplates(set *packetReader, ctx packetContext, options bool) {
+	for set.remaining() >= 4 {
+		key := templateKey{exporter: ctx.domain.exporter, version: ctx.domain.version, domain: ctx.domain.domain, id: set.uint16()}
+		var fieldCount int
+		switch {
+		case ctx.flowType == TypeNetFlow9 && options:
+			// the lengths of the scope and option fields are in bytes
+			scopeLength := int(set.uint16())
+			optionLength := int(set.uint16())
+			fieldCount = (scopeLength + optionLength) / 4
+		case options:
+			fieldCount = int(set.uint16())
+			set.skip(2) // scope field count
+		default:
+			fieldCount = int(set.uint16())
+		}
+		if set.err != nil {
+			return
+		}
+		if key.id < minDataSetID {
+			// padding at the end of the set
+			return
+		}
+		if fieldCount == 0 {
+			// withdrawal of the template
+			delete(d.templates, key)
+			continue
+		}
+
+		t := &template{options: options, fields: make([]templateField, 0, fieldCount)}
+		for i := 0; i < fieldCount; i++ {
+			f := templateField{id: set.uint16(), length: set.uint16()}
+			if ctx.flowType == TypeIPFIX && f.id&0x8000 != 0 {
+				f.id &= 0x7FFF
+				f.enterprise = true
+				set.skip(4) // enterprise number
+			}
+			t.fields = append(t.fields, f)
+		}
+		if set.err != nil {
+			return
+		}
+		d.templates[key] = t
+	}
+}
+
+func (d *templateDecoder) decodeDataSet(set *packetReader, setID uint16, ctx packetContext) []*Flow {
+	key := templateKey{exporter: ctx.domain.exporter, version: ctx.domain.version, domain: ctx.domain.domain, id: setID}
+	t, found := d.templates[key]
+	if !found {
+		tlmDecodingErrors.Inc(string(ctx.flowType), "unknown_template")
+		return nil
+	}
+	minLength := t.minRecordLength()
+	if minLength == 0 {
+		return nil
+	}
+
+	var flows []*Flow
+	for set.remaining() >= minLength {
+		if t.options {
+			d.decodeOptionsRecord(set, t, ctx)
+			continue
+		}
+		if f := d.decodeRecord(set, t, ctx); f != nil {
+			flows = append(flows, f)
+		}
+	}
+	return flows
+}
+
+// readField reads the value of a field of a record
+func readField(set *packetReader, f templateField) []byte {
+	length := int(f.length)
+	if f.length == variableLength {
+		length = int(set.uint8())
+		if length == 0xFF {
+			length = int(set.uint16())
+		}
+	}
+	return set.bytes(length)
+}
+
+func (d *templateDecoder) decodeOptionsRecord(set *packetReader, t *template, ctx packetContext) {
+	for _, f := range t.fields {
+		value := readField(set, f)
+		if set.err != nil {
+			return
+		}
+		if !f.enterprise && (f.id == fieldSamplingInterval || f.id == fieldSamplingPacketInterval) {
+			d.samplingRates[ctx.domain] = decodeUint(value)
+		}
+	}
+}
+
+func (d *templateDecoder) decodeRecord(set *packetReader, t *template, ctx packetContext) *Flow {
+	f := &Flow{
+		FlowType:       ctx.flowType,
+		ExporterAddr:   ctx.exporter,
+		SamplingRate:   d.samplingRates[ctx.domain],
+		StartTimestamp: uint64(ctx.unixSecs),
+		EndTimestamp:   uint64(ctx.unixSecs),
+	}
+	for _, field := range t.fields {
+		value := readField(set, field)
+		if set.err != nil {
+			return nil
+		}
+		if field.enterprise {
+			continue
+		}
+
+		switch field.id {
+		case fieldInBytes, fieldOctetTotalCount:
+			f.Bytes = decodeUint(value)
+		case fieldInPackets, fieldPacketTotalCount:
+			f.Packets = decodeUint(value)
+		case fieldProtocol:
+			f.IPProtocol = uint32(decodeUint(value))
+		case fieldTos:
+			f.Tos = uint8(decodeUint(value))
+		case fieldTCPFlags:
+			// the flags are in the last byte, NetFlow v9 and IPFIX encode them on one or two bytes
+			f.TCPFlags = uint8(decodeUint(value))
+		case fieldSrcPort:
+			f.SrcPort = uint16(decodeUint(value))
+		case fieldDstPort:
+			f.DstPort = uint16(decodeUint(value))
+		case fieldSrcAddrIPv4, fieldSrcAddrIPv6:
+			f.SrcAddr = net.IP(append([]byte{}, value...))
+		case fieldDstAddrIPv4, fieldDstAddrIPv6:
+			f.DstAddr = net.IP(append([]byte{}, value...))
+		case fieldInputInterface:
+			f.InputInterface = uint32(decodeUint(value))
+		case fieldOutputInterface:
+			f.OutputInterface = uint32(decodeUint(value))
+		case fieldEthernetType:
+			f.EtherType = uint32(decodeUint(value))
+		case fieldSamplingInterval, fieldSamplingPacketInterval:
+			f.SamplingRate = decodeUint(value)
+		case fieldFirstSwitched:
+			if ctx.flowType == TypeNetFlow9 {
+				f.StartTimestamp = uptimeToTimestamp(ctx.unixSecs, ctx.sysUptime, uint32(decodeUint(value)))
+			}
+		case fieldLastSwitched:
+			if ctx.flowType == TypeNetFlow9 {
+				f.EndTimestamp = uptimeToTimestamp(ctx.unixSecs, ctx.sysUptime, uint32(decodeUint(value)))
+			}
+		case fieldFlowStartSeconds:
+			f.StartTimestamp = decodeUint(value)
+		case fieldFlowEndSeconds:
+			f.EndTimestamp = decodeUint(value)
+		case fieldFlowStartMilliseconds:
+			f.StartTimestamp = decodeUint(value) / 1000
+		case fieldFlowEndMilliseconds:
+			f.EndTimestamp = decodeUint(value) / 1000
+		}
+	}
+
+	if f.EtherType == 0 {
+		switch len(f.SrcAddr) {
+		case net.IPv4len:
+			f.EtherType = etherTypeIPv4
+		case net.IPv6len:
+			f.EtherType = etherTypeIPv6
+		}
+	}
+	return f
+}
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var exporter = net.ParseIP("10.0.0.1").To4()
+
+// packetBuilder writes the big endian fields of a test packet
+type packetBuilder struct {
+	bytes.Buffer
+}
+
+func (b *packetBuilder) put(values ...interface{}) *packetBuilder {
+	for _, v := range values {
+		binary.Write(&b.Buffer, binary.BigEndian, v) //nolint:errcheck
+	}
+	return b
+}
+
+func TestDecodeNetFlow5(t *testing.T) {
+	b := &packetBuilder{}
+	// header: version, count, uptime, unix secs, unix nsecs, sequence, engine type and id, sampling
+	b.put(uint16(5), uint16(1), uint32(60000), uint32(1600000000), uint32(0), uint32(1), uint8(0), uint8(0), uint16(0x4000|100))
+	b.put(net.ParseIP("192.168.1.1").To4(), net.ParseIP("192.168.1.2").To4(), net.ParseIP("0.0.0.0").To4())
+	b.put(uint16(1), uint16(2), uint32(10), uint32(1500), uint32(50000), uint32(59000), uint16(12345), uint16(443))
+	b.put(uint8(0), uint8(0x12), uint8(6), uint8(0), uint16(0), uint16(0), uint8(24), uint8(24), uint16(0))
+
+	d, err := newDecoder(TypeNetFlow5)
+	require.NoError(t, err)
+	flows, err := d.decode(b.Bytes(), exporter)
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+
+	assert.Equal(t, &Flow{
+		FlowType:        TypeNetFlow5,
+		ExporterAddr:    exporter,
+		SamplingRate:    100,
+		StartTimestamp:  1599999990,
+		EndTimestamp:    1599999999,
+		Bytes:           1500,
+		Packets:         10,
+		EtherType:       etherTypeIPv4,
+		IPProtocol:      6,
+		SrcAddr:         net.ParseIP("192.168.1.1").To4(),
+		DstAddr:         net.ParseIP("192.168.1.2").To4(),
+		SrcPort:         12345,
+		DstPort:         443,
+		InputInterface:  1,
+		OutputInterface: 2,
+		TCPFlags:        0x12,
+	}, flows[0])
+}
+
+func TestDecodeNetFlow5TooShort(t *testing.T) {
+	b := &packetBuilder{}
+	b.put(uint16(5), uint16(2), uint32(0), uint32(0), uint32(0), uint32(0), uint8(0), uint8(0), uint16(0))
+
+	_, err := (&netflow5Decoder{}).decode(b.Bytes(), exporter)
+	assert.Error(t, err)
+}
+
+func TestDecodeNetFlow9(t *testing.T) {
+	d := newTemplateDecoder()
+
+	record := &packetBuilder{}
+	record.put(net.ParseIP("192.168.1.1").To4(), net.ParseIP("192.168.1.2").To4(), uint16(53), uint16(5353), uint8(17))
+	record.put(uint32(300), uint32(3), uint32(50000), uint32(59000))
+	data := &packetBuilder{}
+	// data flowset with its padding
+	data.put(uint16(256), uint16(4+record.Len()+3), record.Bytes(), []byte{0, 0, 0})
+
+	header := &packetBuilder{}
+	header.put(uint16(9), uint16(1), uint32(60000), uint32(1600000000), uint32(1), uint32(42))
+
+	// the data received before the template is dropped
+	flows, err := d.decode(append(header.Bytes(), data.Bytes()...), exporter)
+	require.NoError(t, err)
+	assert.Len(t, flows, 0)
+
+	fields := [][2]uint16{
+		{fieldSrcAddrIPv4, 4}, {fieldDstAddrIPv4, 4}, {fieldSrcPort, 2}, {fieldDstPort, 2}, {fieldProtocol, 1},
+		{fieldInBytes, 4}, {fieldInPackets, 4}, {fieldFirstSwitched, 4}, {fieldLastSwitched, 4},
+	}
+	templates := &packetBuilder{}
+	templates.put(uint16(netflow9TemplateSetID), uint16(4+4+4*len(fields)), uint16(256), uint16(len(fields)))
+	for _, f := range fields {
+		templates.put(f[0], f[1])
+	}
+
+	packet := append(header.Bytes(), templates.Bytes()...)
+	packet = append(packet, data.Bytes()...)
+	flows, err = d.decode(packet, exporter)
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, &Flow{
+		FlowType:       TypeNetFlow9,
+		ExporterAddr:   exporter,
+		StartTimestamp: 1599999990,
+		EndTimestamp:   1599999999,
+		Bytes:          300,
+		Packets:        3,
+		EtherType:      etherTypeIPv4,
+		IPProtocol:     17,
+		SrcAddr:        net.ParseIP("192.168.1.1").To4(),
+		DstAddr:        net.ParseIP("192.168.1.2").To4(),
+		SrcPort:        53,
+		DstPort:        5353,
+	}, flows[0])
+
+	// the templates of another source ID don't apply
+	otherHeader := &packetBuilder{}
+	otherHeader.put(uint16(9), uint16(1), uint32(60000), uint32(1600000000), uint32(2), uint32(43))
+	flows, err = d.decode(append(otherHeader.Bytes(), data.Bytes()...), exporter)
+	require.NoError(t, err)
+	assert.Len(t, flows, 0)
+}
+
+func TestDecodeIPFIX(t *testing.T) {
+	d := newTemplateDecoder()
+
+	// options template carrying the sampling interval of the exporter
+	options := &packetBuilder{}
+	options.put(uint16(ipfixOptionsTemplateSetID), uint16(4+6+8), uint16(257), uint16(2), uint16(1))
+	options.put(uint16(149), uint16(4), uint16(fieldSamplingPacketInterval), uint16(4))
+	optionsData := &packetBuilder{}
+	optionsData.put(uint16(257), uint16(4+8), uint32(42), uint32(1000))
+
+	// template with an enterprise field and a variable length field
+	templates := &packetBuilder{}
+	templates.put(uint16(ipfixTemplateSetID), uint16(4+4+4*7+4+4), uint16(256), uint16(8))
+	templates.put(uint16(fieldSrcAddrIPv6), uint16(16), uint16(fieldDstAddrIPv6), uint16(16))
+	templates.put(uint16(fieldOctetTotalCount), uint16(8), uint16(fieldPacketTotalCount), uint16(8))
+	templates.put(uint16(0x8000|1), uint16(variableLength), uint32(9))
+	templates.put(uint16(fieldFlowStartMilliseconds), uint16(8), uint16(fieldFlowEndMilliseconds), uint16(8))
+	templates.put(uint16(fieldProtocol), uint16(1))
+
+	record := &packetBuilder{}
+	record.put(net.ParseIP("2001:db8::1").To16(), net.ParseIP("2001:db8::2").To16(), uint64(1000), uint64(10))
+	record.put(uint8(3), []byte("abc"), uint64(1599999990000), uint64(1599999999500), uint8(58))
+	data := &packetBuilder{}
+	data.put(uint16(256), uint16(4+record.Len()), record.Bytes())
+
+	body := append(options.Bytes(), optionsData.Bytes()...)
+	body = append(body, templates.Bytes()...)
+	body = append(body, data.Bytes()...)
+	header := &packetBuilder{}
+	header.put(uint16(10), uint16(16+len(body)), uint32(1600000000), uint32(1), uint32(42))
+
+	flows, err := d.decode(append(header.Bytes(), body...), exporter)
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, &Flow{
+		FlowType:       TypeIPFIX,
+		ExporterAddr:   exporter,
+		SamplingRate:   1000,
+		StartTimestamp: 1599999990,
+		EndTimestamp:   1599999999,
+		Bytes:          1000,
+		Packets:        10,
+		EtherType:      etherTypeIPv6,
+		IPProtocol:     58,
+		SrcAddr:        net.ParseIP("2001:db8::1").To16(),
+		DstAddr:        net.ParseIP("2001:db8::2").To16(),
+	}, flows[0])
+}
+
+func TestDecodeSFlow5(t *testing.T) {
+	defer func() { timeNow = time.Now }()
+	timeNow = func() time.Time { return time.Unix(1600000000, 0) }
+
+	// ethernet, IPv4 and TCP headers of the sampled packet
+	header := &packetBuilder{}
+	header.put(make([]byte, 12), uint16(etherTypeIPv4))
+	header.put(uint8(0x45), uint8(0x10), uint16(1500), uint16(0), uint16(0), uint8(64), uint8(6), uint16(0))
+	header.put(net.ParseIP("192.168.1.1").To4(), net.ParseIP("192.168.1.2").To4())
+	header.put(uint16(12345), uint16(80), uint32(0), uint32(0), uint8(0x50), uint8(0x18))
+	header.put([]byte{0, 0}) // padding to 4 bytes
+
+	rawRecord := &packetBuilder{}
+	rawRecord.put(uint32(sflowHeaderProtocolEthernet), uint32(1514), uint32(4), uint32(header.Len()-2), header.Bytes())
+
+	sample := &packetBuilder{}
+	sample.put(uint32(1), uint32(3), uint32(512), uint32(0), uint32(0), uint32(7), uint32(8), uint32(1))
+	sample.put(uint32(sflowRawPacketHeader), uint32(rawRecord.Len()), rawRecord.Bytes())
+
+	b := &packetBuilder{}
+	b.put(uint32(5), uint32(sflowAddressIPv4), net.ParseIP("10.0.0.2").To4(), uint32(0), uint32(1), uint32(1000), uint32(2))
+	// a counter sample is skipped
+	b.put(uint32(2), uint32(4), uint32(0))
+	b.put(uint32(sflowFlowSample), uint32(sample.Len()), sample.Bytes())
+
+	flows, err := (&sflow5Decoder{}).decode(b.Bytes(), exporter)
+	require.NoError(t, err)
+	require.Len(t, flows, 1)
+	assert.Equal(t, &Flow{
+		FlowType:        TypeSFlow5,
+		ExporterAddr:    net.ParseIP("10.0.0.2").To4(),
+		SamplingRate:    512,
+		StartTimestamp:  1600000000,
+		EndTimestamp:    1600000000,
+		Bytes:           1514,
+		Packets:         1,
+		EtherType:       etherTypeIPv4,
+		IPProtocol:      6,
+		SrcAddr:         net.ParseIP("192.168.1.1").To4(),
+		DstAddr:         net.ParseIP("192.168.1.2").To4(),
+		SrcPort:         12345,
+		DstPort:         80,
+		InputInterface:  7,
+		OutputInterface: 8,
+		Tos:             0x10,
+		TCPFlags:        0x18,
+	}, flows[0])
+}
+
+func TestNewDecoderUnknownType(t *testing.T) {
+	_, err := newDecoder("netflow1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package netflow collects the flows exported by the network devices with NetFlow v5, NetFlow v9,
+// IPFIX and sFlow, tags them like the devices discovered by the SNMP listener, aggregates them and
+// sends them to the network devices intake.
+package netflow
+
+import (
+	"fmt"
+	"net"
+)
+
+// FlowType is the protocol a flow was exported with
+type FlowType string
+
+// The supported flow types
+const (
+	TypeNetFlow5 FlowType = "netflow5"
+	TypeNetFlow9 FlowType = "netflow9"
+	TypeIPFIX    FlowType = "ipfix"
+	TypeSFlow5   FlowType = "sflow5"
+)
+
+// defaultPorts are the ports the exporters send their flows to by default
+var defaultPorts = map[FlowType]uint16{
+	TypeNetFlow5: 2055,
+	TypeNetFlow9: 2055,
+	TypeIPFIX:    4739,
+	TypeSFlow5:   6343,
+}
+
+// The ether types of the flows
+const (
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86DD
+)
+
+// Flow is a flow decoded from the packets of an exporter, the bytes and the packets are the ones
+// of the samples when the exporter samples the traffic
+type Flow struct {
+	FlowType     FlowType
+	ExporterAddr net.IP
+	SamplingRate uint64
+
+	// StartTimestamp and EndTimestamp are unix timestamps in seconds
+	StartTimestamp uint64
+	EndTimestamp   uint64
+	Bytes          uint64
+	Packets        uint64
+
+	EtherType       uint32
+	IPProtocol      uint32
+	SrcAddr         net.IP
+	DstAddr         net.IP
+	SrcPort         uint16
+	DstPort         uint16
+	InputInterface  uint32
+	OutputInterface uint32
+	Tos             uint8
+	TCPFlags        uint8
+}
+
+// AggregationKey returns the key of the flow in the aggregator, the flows with the same key only
+// differ by their timestamps, bytes, packets and TCP flags
+func (f *Flow) AggregationKey() string {
+	return fmt.Sprintf("%s|%s|%s:%d|%s:%d|%d|%d|%d|%d|%d",
+		f.FlowType, f.ExporterAddr, f.SrcAddr, f.SrcPort, f.DstAddr, f.DstPort,
+		f.EtherType, f.IPProtocol, f.InputInterface, f.OutputInterface, f.Tos)
+}
+
+// merge adds the counts of another flow with the same key
+func (f *Flow) merge(other *Flow) {
+	f.Bytes += other.Bytes
+	f.Packets += other.Packets
+	f.TCPFlags |= other.TCPFlags
+	if other.StartTimestamp < f.StartTimestamp {
+		f.StartTimestamp = other.StartTimestamp
+	}
+	if other.EndTimestamp > f.EndTimestamp {
+		f.EndTimestamp = other.EndTimestamp
+	}
+	if other.SamplingRate > f.SamplingRate {
+		f.SamplingRate = other.SamplingRate
+	}
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"net"
+	"sync"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxPacketSize is the largest UDP payload
+const maxPacketSize = 65535
+
+// listener receives the packets of the exporters sending a type of flows on a port, and
+// decodes them in its workers
+type listener struct {
+	config  ListenerConfig
+	conn    net.PacketConn
+	decoder decoder
+	flowOut chan<- *Flow
+	wg      sync.WaitGroup
+}
+
+func startListener(config ListenerConfig, flowOut chan<- *Flow) (*listener, error) {
+	d, err := newDecoder(config.FlowType)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := net.ListenPacket("udp", config.Addr())
+	if err != nil {
+		return nil, err
+	}
+
+	l := &listener{
+		config:  config,
+		conn:    conn,
+		decoder: d,
+		flowOut: flowOut,
+	}
+	for i := 0; i < config.Workers; i++ {
+		l.wg.Add(1)
+		go l.run()
+	}
+	log.Infof("Listening to the %s flows on %s", config.FlowType, conn.LocalAddr())
+	return l, nil
+}
+
+func (l *listener) run() {
+	defer l.wg.Done()
+	buf := make([]byte, maxPacketSize)
+	flowType := string(l.config.FlowType)
+	for {
+		n, addr, err := l.conn.ReadFrom(buf)
+		if err != nil {
+			// the connection is closed when stopping
+			return
+		}
+		tlmPacketsReceived.Inc(flowType)
+
+		var exporter net.IP
+		if udpAddr, ok := addr.(*net.UDPAddr); ok {
+			exporter = udpAddr.IP
+		}
+		flows, err := l.decoder.decode(buf[:n], exporter)
+		if err != nil {
+			tlmDecodingErrors.Inc(flowType, "invalid_packet")
+			log.Debugf("Could not decode a %s packet from %s: %v", flowType, addr, err)
+		}
+		for _, f := range flows {
+			tlmFlowsReceived.Inc(flowType)
+			select {
+			case l.flowOut <- f:
+			default:
+				tlmFlowsDropped.Inc(flowType)
+			}
+		}
+	}
+}
+
+func (l *listener) stop() {
+	l.conn.Close()
+	l.wg.Wait()
+}
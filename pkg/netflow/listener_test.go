@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListener(t *testing.T) {
+	flows := make(chan *Flow, 10)
+	l, err := startListener(ListenerConfig{FlowType: TypeNetFlow5, BindHost: "127.0.0.1", Workers: 2}, flows)
+	require.NoError(t, err)
+	defer l.stop()
+
+	conn, err := net.Dial("udp", l.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	b := &packetBuilder{}
+	b.put(uint16(5), uint16(1), uint32(60000), uint32(1600000000), uint32(0), uint32(1), uint8(0), uint8(0), uint16(0))
+	b.put(make([]byte, netflow5RecordLength))
+	_, err = conn.Write(b.Bytes())
+	require.NoError(t, err)
+
+	select {
+	case f := <-flows:
+		assert.Equal(t, TypeNetFlow5, f.FlowType)
+		assert.Equal(t, "127.0.0.1", f.ExporterAddr.String())
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "the flow was not received")
+	}
+}
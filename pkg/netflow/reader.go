@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+)
+
+var errShortPacket = errors.New("packet too short")
+
+// packetReader reads the big endian fields of a packet, the first read past the end of the packet
+// sets err and all the following reads return zero values
+type packetReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func newPacketReader(buf []byte) *packetReader {
+	return &packetReader{buf: buf}
+}
+
+func (r *packetReader) remaining() int {
+	return len(r.buf) - r.pos
+}
+
+func (r *packetReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || r.remaining() < n {
+		r.err = errShortPacket
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+n]
+	r.pos += n
+	return b
+}
+
+func (r *packetReader) skip(n int) {
+	r.bytes(n)
+}
+
+func (r *packetReader) uint8() uint8 {
+	if b := r.bytes(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *packetReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *packetReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *packetReader) ip(n int) net.IP {
+	if b := r.bytes(n); b != nil {
+		return net.IP(append([]byte{}, b...))
+	}
+	return nil
+}
+
+// sub returns a reader of the next n bytes and skips them
+func (r *packetReader) sub(n int) *packetReader {
+	b := r.bytes(n)
+	if b == nil {
+		return &packetReader{err: r.err}
+	}
+	return newPacketReader(b)
+}
+
+// decodeUint decodes an unsigned integer of up to 8 bytes
+func decodeUint(b []byte) uint64 {
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// Server receives the flows of the exporters on the configured listeners and sends them,
+// aggregated, to the network devices intake
+type Server struct {
+	config     *Config
+	listeners  []*listener
+	aggregator *aggregator
+}
+
+// IsEnabled returns whether the flow collection is enabled
+func IsEnabled() bool {
+	return config.Datadog.GetBool("network_devices.netflow.enabled")
+}
+
+// NewServer starts the listeners and the aggregator of the flows
+func NewServer(sender epforwarder.EventPlatformForwarder, hostname string) (*Server, error) {
+	flowConfig, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config:     flowConfig,
+		aggregator: newAggregator(sender, flowConfig.AggregatorBufferSize, time.Duration(flowConfig.AggregatorFlushInterval)*time.Second, hostname),
+	}
+	s.aggregator.start()
+
+	for _, listenerConfig := range flowConfig.Listeners {
+		l, err := startListener(listenerConfig, s.aggregator.flowIn)
+		if err != nil {
+			s.Stop()
+			return nil, err
+		}
+		s.listeners = append(s.listeners, l)
+	}
+	return s, nil
+}
+
+// Stop stops the listeners and sends the flows aggregated so far, giving up after stop_timeout
+func (s *Server) Stop() {
+	done := make(chan struct{})
+	go func() {
+		for _, l := range s.listeners {
+			l.stop()
+		}
+		s.aggregator.stop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Duration(s.config.StopTimeout) * time.Second):
+		log.Warn("Could not stop the flow collection before the timeout")
+	}
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package netflow
+
+import "github.com/DataDog/datadog-agent/pkg/telemetry"
+
+var (
+	tlmPacketsReceived = telemetry.NewCounter("netflow", "packets_received",
+		[]string{"flow_type"}, "Count of the packets received from the exporters")
+	tlmDecodingErrors = telemetry.NewCounter("netflow", "decoding_errors",
+		[]string{"flow_type", "reason"}, "Count of the packets or records which couldn't be decoded")
+	tlmFlowsReceived = telemetry.NewCounter("netflow", "flows_received",
+		[]string{"flow_type"}, "Count of the flows decoded from the packets")
+	tlmFlowsDropped = telemetry.NewCounter("netflow", "flows_dropped",
+		[]string{"flow_type"}, "Count of the flows dropped because the aggregator couldn't keep up")
+	tlmFlowsFlushed = telemetry.NewCounter("netflow", "flows_flushed",
+		nil, "Count of the aggregated flows sent to the intake")
+)
@@ -118,6 +118,9 @@ func (p *dnsParser) ParseInto(data []byte, t *translation, pktInfo *dnsPacketInf
 	}
 
 	pktInfo.transactionID = p.dnsPayload.ID
+	pktInfo.rcode = uint8(p.dnsPayload.ResponseCode)
+	// the queries only reach this point with a single question
+	pktInfo.domain = string(p.dnsPayload.Questions[0].Name)
 	return nil
 }
 
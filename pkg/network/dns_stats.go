@@ -14,6 +14,65 @@ type dnsStats struct {
 	successLatencySum   uint64 // Stored in µs
 	failureLatencySum   uint64
 	timeouts            uint32
+
+	// byDomain breaks down the stats above by queried domain
+	byDomain map[string]DNSStats
+}
+
+// DNSStats holds the DNS stats of a queried domain
+type DNSStats struct {
+	SuccessfulResponses uint32 `json:"successful_responses"`
+	// FailedResponses includes the NXDOMAIN responses
+	FailedResponses   uint32 `json:"failed_responses"`
+	NXDomainResponses uint32 `json:"nxdomain_responses"`
+	Timeouts          uint32 `json:"timeouts"`
+	SuccessLatencySum uint64 `json:"success_latency_sum"` // Stored in µs
+	FailureLatencySum uint64 `json:"failure_latency_sum"`
+}
+
+// DNSDomainStats holds the DNS stats of the queries of a process for a domain
+type DNSDomainStats struct {
+	Pid    uint32 `json:"pid"`
+	Domain string `json:"domain"`
+	DNSStats
+}
+
+type dnsDomainKey struct {
+	pid    uint32
+	domain string
+}
+
+func (s *DNSStats) merge(other DNSStats) {
+	s.SuccessfulResponses += other.SuccessfulResponses
+	s.FailedResponses += other.FailedResponses
+	s.NXDomainResponses += other.NXDomainResponses
+	s.Timeouts += other.Timeouts
+	s.SuccessLatencySum += other.SuccessLatencySum
+	s.FailureLatencySum += other.FailureLatencySum
+}
+
+// mergeDomainStats returns the sum of the stats of two domain breakdowns, in a new map
+// since the breakdowns are shared by the clients of the network state
+func mergeDomainStats(a, b map[string]DNSStats, maxDomains int) map[string]DNSStats {
+	if len(b) == 0 {
+		return a
+	}
+	if len(a) == 0 {
+		return b
+	}
+	merged := make(map[string]DNSStats, len(a)+len(b))
+	for domain, stats := range a {
+		merged[domain] = stats
+	}
+	for domain, stats := range b {
+		prev, ok := merged[domain]
+		if !ok && len(merged) >= maxDomains {
+			continue
+		}
+		prev.merge(stats)
+		merged[domain] = prev
+	}
+	return merged
 }
 
 type dnsKey struct {
@@ -42,10 +101,24 @@ const (
 	MaxStateMapSize = 10000
 )
 
+// maxDomainsPerKey bounds the domains tracked per DNS key, a client port rarely queries more than a few domains
+const maxDomainsPerKey = 100
+
+// dnsRcodeNXDomain is the response code of the responses to queries of non-existent domains
+const dnsRcodeNXDomain = 3
+
 type dnsPacketInfo struct {
 	transactionID uint16
 	key           dnsKey
 	pktType       DNSPacketType
+	rcode         uint8
+	domain        string
+}
+
+// dnsQueryState is the state of a query waiting for its response
+type dnsQueryState struct {
+	start  uint64 // Stored in µs
+	domain string
 }
 
 type stateKey struct {
@@ -56,7 +129,7 @@ type stateKey struct {
 type dnsStatKeeper struct {
 	mux              sync.Mutex
 	stats            map[dnsKey]dnsStats
-	state            map[stateKey]uint64
+	state            map[stateKey]dnsQueryState
 	expirationPeriod time.Duration
 	exit             chan struct{}
 	maxSize          int // maximum size of the state map
@@ -66,7 +139,7 @@ type dnsStatKeeper struct {
 func newDNSStatkeeper(timeout time.Duration) *dnsStatKeeper {
 	statsKeeper := &dnsStatKeeper{
 		stats:            make(map[dnsKey]dnsStats),
-		state:            make(map[stateKey]uint64),
+		state:            make(map[stateKey]dnsQueryState),
 		expirationPeriod: timeout,
 		exit:             make(chan struct{}),
 		maxSize:          MaxStateMapSize,
@@ -102,13 +175,13 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 		}
 
 		if _, ok := d.state[sk]; !ok {
-			d.state[sk] = microSecs(ts)
+			d.state[sk] = dnsQueryState{start: microSecs(ts), domain: info.domain}
 		}
 		return
 	}
 
 	// If a response does not have a corresponding query entry, we discard it
-	start, ok := d.state[sk]
+	query, ok := d.state[sk]
 
 	if !ok {
 		return
@@ -117,24 +190,50 @@ func (d *dnsStatKeeper) ProcessPacketInfo(info dnsPacketInfo, ts time.Time) {
 	delete(d.state, sk)
 	d.deleteCount++
 
-	latency := microSecs(ts) - start
+	latency := microSecs(ts) - query.start
 
 	stats := d.stats[info.key]
+	var domainStats DNSStats
 
 	// Note: time.Duration in the agent version of go (1.12.9) does not have the Microseconds method.
 	if latency > uint64(d.expirationPeriod.Microseconds()) {
 		stats.timeouts++
+		domainStats.Timeouts++
 	} else {
 		if info.pktType == SuccessfulResponse {
 			stats.successfulResponses++
 			stats.successLatencySum += latency
+			domainStats.SuccessfulResponses++
+			domainStats.SuccessLatencySum += latency
 		} else if info.pktType == FailedResponse {
 			stats.failedResponses++
 			stats.failureLatencySum += latency
+			domainStats.FailedResponses++
+			domainStats.FailureLatencySum += latency
+			if info.rcode == dnsRcodeNXDomain {
+				domainStats.NXDomainResponses++
+			}
 		}
 	}
 
-	d.stats[info.key] = stats
+	d.stats[info.key] = addDomainStats(stats, query.domain, domainStats)
+}
+
+// addDomainStats adds the stats of a query to the breakdown by domain of the stats of its key
+func addDomainStats(stats dnsStats, domain string, domainStats DNSStats) dnsStats {
+	if domain == "" {
+		return stats
+	}
+	if stats.byDomain == nil {
+		stats.byDomain = make(map[string]DNSStats)
+	}
+	prev, ok := stats.byDomain[domain]
+	if !ok && len(stats.byDomain) >= maxDomainsPerKey {
+		return stats
+	}
+	prev.merge(domainStats)
+	stats.byDomain[domain] = prev
+	return stats
 }
 
 func (d *dnsStatKeeper) GetAndResetAllStats() map[dnsKey]dnsStats {
@@ -151,12 +250,12 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	defer d.mux.Unlock()
 	threshold := microSecs(earliestTs)
 	for k, v := range d.state {
-		if v < threshold {
+		if v.start < threshold {
 			delete(d.state, k)
 			d.deleteCount++
 			stats := d.stats[k.key]
 			stats.timeouts++
-			d.stats[k.key] = stats
+			d.stats[k.key] = addDomainStats(stats, v.domain, DNSStats{Timeouts: 1})
 		}
 	}
 
@@ -165,7 +264,7 @@ func (d *dnsStatKeeper) removeExpiredStates(earliestTs time.Time) {
 	}
 
 	// golang/go#20135 : maps do not shrink after elements removal (delete)
-	copied := make(map[stateKey]uint64, len(d.state))
+	copied := make(map[stateKey]dnsQueryState, len(d.state))
 	for k, v := range d.state {
 		copied[k] = v
 	}
@@ -64,6 +64,54 @@ func TestTimeout(t *testing.T) {
 	testLatency(t, SuccessfulResponse, delta, 0, 0, 1)
 }
 
+func TestStatsByDomain(t *testing.T) {
+	sk := newDNSStatkeeper(DNSTimeoutSecs * time.Second)
+	defer sk.Close()
+	key := dnsKey{
+		serverIP:   util.AddressFromString("8.8.8.8"),
+		clientIP:   util.AddressFromString("1.1.1.1"),
+		clientPort: 1000,
+		protocol:   UDP,
+	}
+	then := time.Now()
+
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: Query, key: key, domain: "golang.org"}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: Query, key: key, domain: "unknown.invalid"}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 3, pktType: Query, key: key, domain: "golang.org"}, then)
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 1, pktType: SuccessfulResponse, key: key}, then.Add(10*time.Microsecond))
+	sk.ProcessPacketInfo(dnsPacketInfo{transactionID: 2, pktType: FailedResponse, rcode: dnsRcodeNXDomain, key: key}, then.Add(20*time.Microsecond))
+	// the query 3 times out
+	sk.removeExpiredStates(then.Add(time.Microsecond))
+
+	stats := sk.GetAndResetAllStats()
+	require.Contains(t, stats, key)
+	assert.Equal(t, uint32(1), stats[key].successfulResponses)
+	assert.Equal(t, uint32(1), stats[key].failedResponses)
+	assert.Equal(t, uint32(1), stats[key].timeouts)
+	assert.Equal(t, map[string]DNSStats{
+		"golang.org":      {SuccessfulResponses: 1, SuccessLatencySum: 10, Timeouts: 1},
+		"unknown.invalid": {FailedResponses: 1, NXDomainResponses: 1, FailureLatencySum: 20},
+	}, stats[key].byDomain)
+}
+
+func TestMergeDomainStats(t *testing.T) {
+	a := map[string]DNSStats{"a.com": {SuccessfulResponses: 1}, "b.com": {Timeouts: 1}}
+	b := map[string]DNSStats{"b.com": {Timeouts: 2}, "c.com": {FailedResponses: 1}}
+
+	merged := mergeDomainStats(a, b, 10)
+	assert.Equal(t, map[string]DNSStats{
+		"a.com": {SuccessfulResponses: 1},
+		"b.com": {Timeouts: 3},
+		"c.com": {FailedResponses: 1},
+	}, merged)
+	// the merged maps are left untouched
+	assert.Equal(t, DNSStats{Timeouts: 1}, a["b.com"])
+	assert.Len(t, b, 2)
+
+	// the domains beyond the limit are dropped
+	assert.Len(t, mergeDomainStats(a, b, 2), 2)
+}
+
 func BenchmarkStats(b *testing.B) {
 	key := dnsKey{
 		serverIP:   util.AddressFromString("8.8.8.8"),
@@ -123,6 +123,12 @@ type ConnectionStats struct {
 	DNSTimeouts            uint32
 	DNSSuccessLatencySum   uint64
 	DNSFailureLatencySum   uint64
+
+	// DNSStatsByDomain breaks down the DNS stats above by queried domain
+	DNSStatsByDomain map[string]DNSStats
+
+	// Protocol is the application protocol classified from the payloads of the connection
+	Protocol ProtocolType
 }
 
 // IPTranslation can be associated with a connection to show the connection is NAT'd
@@ -233,6 +239,10 @@ func ConnectionSummary(c ConnectionStats, names map[util.Address][]string) strin
 		)
 	}
 
+	if c.Protocol != ProtocolUnknown {
+		str += fmt.Sprintf(", protocol %s", c.Protocol)
+	}
+
 	return str
 }
 
@@ -0,0 +1,232 @@
+// +build linux_bpf
+
+package network
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/afpacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+)
+
+// protocolSnapLen is the number of bytes captured per packet, enough to classify the payloads
+// and to find the end of most responses
+const protocolSnapLen = 4096
+
+// tcpSocketFilter only accepts the IPv4 and IPv6 TCP packets
+var tcpSocketFilter = []bpf.Instruction{
+	bpf.LoadAbsolute{Off: 12, Size: 2},                                 // ethertype
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x800, SkipFalse: 2},          // IPv4
+	bpf.LoadAbsolute{Off: 23, Size: 1},                                 // IPv4 protocol
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipTrue: 3, SkipFalse: 4}, // TCP
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 0x86dd, SkipFalse: 3},         // IPv6
+	bpf.LoadAbsolute{Off: 20, Size: 1},                                 // IPv6 next header
+	bpf.JumpIf{Cond: bpf.JumpEqual, Val: 6, SkipFalse: 1},              // TCP
+	bpf.RetConstant{Val: protocolSnapLen},                              // accept
+	bpf.RetConstant{Val: 0},                                            // drop
+}
+
+// ProtocolMonitor classifies the application protocol of the TCP connections from their payloads,
+// captured by a raw socket attached to a socket filter, and aggregates the latency and the errors
+// of the requests per service.
+type ProtocolMonitor struct {
+	source  *afpacket.TPacket
+	keeper  *protocolStatKeeper
+	decoder *gopacket.DecodingLayerParser
+	layers  []gopacket.LayerType
+	ipv4    *layers.IPv4
+	ipv6    *layers.IPv6
+	tcp     *layers.TCP
+	payload *gopacket.Payload
+	exit    chan struct{}
+	wg      sync.WaitGroup
+
+	// packet telemetry
+	processed      int64
+	decodingErrors int64
+}
+
+// NewProtocolMonitor returns a new ProtocolMonitor capturing the packets in the root network namespace
+func NewProtocolMonitor(rootPath string, maxConns, maxServices int, connTimeout time.Duration) (*ProtocolMonitor, error) {
+	var (
+		source *afpacket.TPacket
+		srcErr error
+	)
+
+	// Create the RAW_SOCKET inside the root network namespace
+	nsErr := util.WithRootNS(rootPath, func() {
+		source, srcErr = newProtocolPacketSource()
+	})
+	if nsErr != nil {
+		return nil, nsErr
+	}
+	if srcErr != nil {
+		return nil, srcErr
+	}
+
+	ipv4 := &layers.IPv4{}
+	ipv6 := &layers.IPv6{}
+	tcp := &layers.TCP{}
+	payload := &gopacket.Payload{}
+	decoder := gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &layers.Ethernet{}, ipv4, ipv6, tcp, payload)
+	decoder.IgnoreUnsupported = true
+
+	m := &ProtocolMonitor{
+		source:  source,
+		keeper:  newProtocolStatKeeper(maxConns, maxServices, connTimeout),
+		decoder: decoder,
+		ipv4:    ipv4,
+		ipv6:    ipv6,
+		tcp:     tcp,
+		payload: payload,
+		exit:    make(chan struct{}),
+	}
+
+	m.wg.Add(1)
+	go func() {
+		m.pollPackets()
+		m.wg.Done()
+	}()
+
+	m.wg.Add(1)
+	go func() {
+		m.expireConns(connTimeout)
+		m.wg.Done()
+	}()
+
+	return m, nil
+}
+
+func newProtocolPacketSource() (*afpacket.TPacket, error) {
+	rawSocket, err := afpacket.NewTPacket(
+		afpacket.OptPollTimeout(1*time.Second),
+		afpacket.OptFrameSize(protocolSnapLen),
+		afpacket.OptBlockSize(protocolSnapLen*128),
+		afpacket.OptNumBlocks(8),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating raw socket: %s", err)
+	}
+
+	filter, err := bpf.Assemble(tcpSocketFilter)
+	if err != nil {
+		rawSocket.Close()
+		return nil, fmt.Errorf("error assembling socket filter: %s", err)
+	}
+	if err := rawSocket.SetBPF(filter); err != nil {
+		rawSocket.Close()
+		return nil, fmt.Errorf("error attaching filter to socket: %s", err)
+	}
+	return rawSocket, nil
+}
+
+// AnnotateConnections sets the protocol of the given TCP connections
+func (m *ProtocolMonitor) AnnotateConnections(conns []ConnectionStats) {
+	for i := range conns {
+		conn := &conns[i]
+		if conn.Type != TCP {
+			continue
+		}
+		conn.Protocol = m.keeper.Protocol(conn.Source, conn.SPort, conn.Dest, conn.DPort)
+	}
+}
+
+// GetServiceStats returns the stats aggregated per service since the last call
+func (m *ProtocolMonitor) GetServiceStats() map[ServiceKey]ServiceStats {
+	return m.keeper.GetAndResetServiceStats()
+}
+
+// GetStats returns the telemetry of the monitor
+func (m *ProtocolMonitor) GetStats() map[string]int64 {
+	stats := m.keeper.GetStats()
+	stats["packets_processed"] = atomic.LoadInt64(&m.processed)
+	stats["decoding_errors"] = atomic.LoadInt64(&m.decodingErrors)
+	return stats
+}
+
+// Close stops the monitor and closes the underlying socket
+func (m *ProtocolMonitor) Close() {
+	close(m.exit)
+	m.wg.Wait()
+	m.source.Close()
+}
+
+// processPacket classifies the payload of a TCP packet. The underlying packet data can't be
+// referenced after this method call since the underlying memory content gets invalidated by `afpacket`.
+func (m *ProtocolMonitor) processPacket(data []byte, ts time.Time) {
+	if err := m.decoder.DecodeLayers(data, &m.layers); err != nil {
+		atomic.AddInt64(&m.decodingErrors, 1)
+		return
+	}
+	atomic.AddInt64(&m.processed, 1)
+
+	var srcIP, dstIP util.Address
+	hasTCP, hasPayload := false, false
+	for _, layer := range m.layers {
+		switch layer {
+		case layers.LayerTypeIPv4:
+			srcIP, dstIP = util.AddressFromNetIP(m.ipv4.SrcIP), util.AddressFromNetIP(m.ipv4.DstIP)
+		case layers.LayerTypeIPv6:
+			srcIP, dstIP = util.AddressFromNetIP(m.ipv6.SrcIP), util.AddressFromNetIP(m.ipv6.DstIP)
+		case layers.LayerTypeTCP:
+			hasTCP = true
+		case gopacket.LayerTypePayload:
+			hasPayload = true
+		}
+	}
+	if !hasTCP || !hasPayload || srcIP == nil {
+		return
+	}
+	m.keeper.ProcessPayload(srcIP, uint16(m.tcp.SrcPort), dstIP, uint16(m.tcp.DstPort), *m.payload, ts)
+}
+
+func (m *ProtocolMonitor) pollPackets() {
+	for {
+		data, ci, err := m.source.ZeroCopyReadPacketData()
+
+		// Properly synchronizes termination process
+		select {
+		case <-m.exit:
+			return
+		default:
+		}
+
+		if err == nil {
+			m.processPacket(data, ci.Timestamp)
+			continue
+		}
+
+		// Immediately retry for EAGAIN
+		if err == syscall.EAGAIN {
+			continue
+		}
+
+		if err != afpacket.ErrTimeout {
+			log.Tracef("error reading packet: %s", err)
+		}
+		// Sleep briefly and try again
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func (m *ProtocolMonitor) expireConns(connTimeout time.Duration) {
+	ticker := time.NewTicker(connTimeout / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			m.keeper.RemoveExpiredConns(now)
+		case <-m.exit:
+			return
+		}
+	}
+}
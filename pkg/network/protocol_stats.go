@@ -0,0 +1,230 @@
+package network
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+// connTuple identifies a TCP connection from the packets seen on the wire
+type connTuple struct {
+	clientIP   util.Address
+	serverIP   util.Address
+	clientPort uint16
+	serverPort uint16
+}
+
+// ServiceKey identifies a service: a server address and port speaking a given protocol
+type ServiceKey struct {
+	IP       util.Address
+	Port     uint16
+	Protocol ProtocolType
+}
+
+// ServiceStats holds the requests stats of a service, aggregated over all its clients
+type ServiceStats struct {
+	Requests   uint32
+	Errors     uint32
+	LatencySum uint64 // Stored in µs
+}
+
+// Service is the JSON representation of the stats of a service
+type Service struct {
+	IP         string `json:"ip"`
+	Port       uint16 `json:"port"`
+	Protocol   string `json:"protocol"`
+	Requests   uint32 `json:"requests"`
+	Errors     uint32 `json:"errors"`
+	LatencySum uint64 `json:"latency_sum"`
+}
+
+// FormatServices converts the stats aggregated per service to their JSON representation
+func FormatServices(stats map[ServiceKey]ServiceStats) []Service {
+	services := make([]Service, 0, len(stats))
+	for key, s := range stats {
+		services = append(services, Service{
+			IP:         key.IP.String(),
+			Port:       key.Port,
+			Protocol:   key.Protocol.String(),
+			Requests:   s.Requests,
+			Errors:     s.Errors,
+			LatencySum: s.LatencySum,
+		})
+	}
+	return services
+}
+
+// connProtocol holds the state of a classified connection
+type connProtocol struct {
+	protocol ProtocolType
+	lastSeen time.Time
+	pending  []pendingRequest
+}
+
+type pendingRequest struct {
+	start         time.Time
+	correlationID int32
+}
+
+// maxPendingRequests bounds the requests waiting for a response on a connection, e.g. pipelined requests
+const maxPendingRequests = 32
+
+// protocolStatKeeper classifies the connections from their payloads and aggregates
+// the latency and the errors of their requests per service
+type protocolStatKeeper struct {
+	mux      sync.Mutex
+	conns    map[connTuple]*connProtocol
+	services map[ServiceKey]ServiceStats
+
+	maxConns    int
+	maxServices int
+	connTimeout time.Duration
+
+	// telemetry
+	connsDropped    int64
+	servicesDropped int64
+}
+
+func newProtocolStatKeeper(maxConns, maxServices int, connTimeout time.Duration) *protocolStatKeeper {
+	return &protocolStatKeeper{
+		conns:       make(map[connTuple]*connProtocol),
+		services:    make(map[ServiceKey]ServiceStats),
+		maxConns:    maxConns,
+		maxServices: maxServices,
+		connTimeout: connTimeout,
+	}
+}
+
+// ProcessPayload processes a TCP payload sent from src to dst
+func (p *protocolStatKeeper) ProcessPayload(srcIP util.Address, srcPort uint16, dstIP util.Address, dstPort uint16, payload []byte, ts time.Time) {
+	if len(payload) == 0 {
+		return
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	// payload sent by the client of a known connection
+	tuple := connTuple{clientIP: srcIP, clientPort: srcPort, serverIP: dstIP, serverPort: dstPort}
+	if conn, ok := p.conns[tuple]; ok {
+		conn.lastSeen = ts
+		msg := parseMessage(conn.protocol, payload, true)
+		if msg.request {
+			conn.addPending(pendingRequest{start: ts, correlationID: msg.correlationID})
+		}
+		return
+	}
+
+	// payload sent by the server of a known connection
+	reversed := connTuple{clientIP: dstIP, clientPort: dstPort, serverIP: srcIP, serverPort: srcPort}
+	if conn, ok := p.conns[reversed]; ok {
+		conn.lastSeen = ts
+		msg := parseMessage(conn.protocol, payload, false)
+		if msg.response {
+			if req, ok := conn.popPending(conn.protocol, msg.correlationID); ok {
+				p.addResponse(ServiceKey{IP: srcIP, Port: srcPort, Protocol: conn.protocol}, ts.Sub(req.start), msg.error)
+			}
+		}
+		return
+	}
+
+	// new connection, the classification only relies on the requests so that the client is known
+	protocol := classifyRequest(payload)
+	if protocol == ProtocolUnknown {
+		return
+	}
+	if len(p.conns) >= p.maxConns {
+		p.connsDropped++
+		return
+	}
+	conn := &connProtocol{protocol: protocol, lastSeen: ts}
+	if msg := parseMessage(protocol, payload, true); msg.request {
+		conn.addPending(pendingRequest{start: ts, correlationID: msg.correlationID})
+	}
+	p.conns[tuple] = conn
+}
+
+func (p *protocolStatKeeper) addResponse(key ServiceKey, latency time.Duration, isError bool) {
+	stats, ok := p.services[key]
+	if !ok && len(p.services) >= p.maxServices {
+		p.servicesDropped++
+		return
+	}
+	stats.Requests++
+	if isError {
+		stats.Errors++
+	}
+	stats.LatencySum += uint64(latency.Microseconds())
+	p.services[key] = stats
+}
+
+// Protocol returns the protocol of the connection between the given addresses,
+// the client and the server can be given in any order
+func (p *protocolStatKeeper) Protocol(srcIP util.Address, srcPort uint16, dstIP util.Address, dstPort uint16) ProtocolType {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if conn, ok := p.conns[connTuple{clientIP: srcIP, clientPort: srcPort, serverIP: dstIP, serverPort: dstPort}]; ok {
+		return conn.protocol
+	}
+	if conn, ok := p.conns[connTuple{clientIP: dstIP, clientPort: dstPort, serverIP: srcIP, serverPort: srcPort}]; ok {
+		return conn.protocol
+	}
+	return ProtocolUnknown
+}
+
+// GetAndResetServiceStats returns the stats aggregated per service since the last call
+func (p *protocolStatKeeper) GetAndResetServiceStats() map[ServiceKey]ServiceStats {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	services := p.services
+	p.services = make(map[ServiceKey]ServiceStats)
+	return services
+}
+
+// RemoveExpiredConns forgets the connections without traffic since the timeout
+func (p *protocolStatKeeper) RemoveExpiredConns(now time.Time) {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	for tuple, conn := range p.conns {
+		if now.Sub(conn.lastSeen) > p.connTimeout {
+			delete(p.conns, tuple)
+		}
+	}
+}
+
+// GetStats returns the telemetry of the stat keeper
+func (p *protocolStatKeeper) GetStats() map[string]int64 {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return map[string]int64{
+		"tracked_conns":    int64(len(p.conns)),
+		"tracked_services": int64(len(p.services)),
+		"conns_dropped":    p.connsDropped,
+		"services_dropped": p.servicesDropped,
+	}
+}
+
+func (c *connProtocol) addPending(req pendingRequest) {
+	if len(c.pending) >= maxPendingRequests {
+		// the responses were missed, only keep the most recent requests
+		c.pending = c.pending[1:]
+	}
+	c.pending = append(c.pending, req)
+}
+
+// popPending returns the request answered by a response: the request with the same correlation ID
+// for Kafka, the oldest request for the protocols answering the requests in order
+func (c *connProtocol) popPending(protocol ProtocolType, correlationID int32) (pendingRequest, bool) {
+	for i, req := range c.pending {
+		if protocol != ProtocolKafka || req.correlationID == correlationID {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return req, true
+		}
+	}
+	return pendingRequest{}, false
+}
@@ -0,0 +1,97 @@
+package network
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/util"
+)
+
+var (
+	protocolClientIP = util.AddressFromString("10.0.0.1")
+	protocolServerIP = util.AddressFromString("10.0.0.2")
+)
+
+func TestProtocolStatsHTTP(t *testing.T) {
+	p := newProtocolStatKeeper(100, 100, time.Minute)
+	now := time.Now()
+
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 80, []byte("GET / HTTP/1.1\r\n"), now)
+	p.ProcessPayload(protocolServerIP, 80, protocolClientIP, 5000, []byte("HTTP/1.1 200 OK\r\n"), now.Add(2*time.Millisecond))
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 80, []byte("GET /fail HTTP/1.1\r\n"), now.Add(3*time.Millisecond))
+	p.ProcessPayload(protocolServerIP, 80, protocolClientIP, 5000, []byte("HTTP/1.1 500 Internal Server Error\r\n"), now.Add(7*time.Millisecond))
+
+	assert.Equal(t, ProtocolHTTP, p.Protocol(protocolClientIP, 5000, protocolServerIP, 80))
+	assert.Equal(t, ProtocolHTTP, p.Protocol(protocolServerIP, 80, protocolClientIP, 5000))
+	assert.Equal(t, ProtocolUnknown, p.Protocol(protocolClientIP, 5001, protocolServerIP, 80))
+
+	stats := p.GetAndResetServiceStats()
+	key := ServiceKey{IP: protocolServerIP, Port: 80, Protocol: ProtocolHTTP}
+	require.Contains(t, stats, key)
+	assert.Equal(t, ServiceStats{Requests: 2, Errors: 1, LatencySum: 6000}, stats[key])
+
+	assert.Empty(t, p.GetAndResetServiceStats())
+}
+
+func TestProtocolStatsKafkaCorrelation(t *testing.T) {
+	p := newProtocolStatKeeper(100, 100, time.Minute)
+	now := time.Now()
+
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 9092, kafkaRequest(1, 11, 1, "consumer"), now)
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 9092, kafkaRequest(0, 8, 2, "consumer"), now.Add(time.Millisecond))
+	// the responses are not in order
+	p.ProcessPayload(protocolServerIP, 9092, protocolClientIP, 5000, kafkaResponse(2), now.Add(3*time.Millisecond))
+	p.ProcessPayload(protocolServerIP, 9092, protocolClientIP, 5000, kafkaResponse(1), now.Add(10*time.Millisecond))
+	// unknown correlation ID
+	p.ProcessPayload(protocolServerIP, 9092, protocolClientIP, 5000, kafkaResponse(3), now.Add(11*time.Millisecond))
+
+	stats := p.GetAndResetServiceStats()
+	key := ServiceKey{IP: protocolServerIP, Port: 9092, Protocol: ProtocolKafka}
+	assert.Equal(t, ServiceStats{Requests: 2, LatencySum: 12000}, stats[key])
+}
+
+func TestProtocolStatsUnknownPayloads(t *testing.T) {
+	p := newProtocolStatKeeper(100, 100, time.Minute)
+	now := time.Now()
+
+	// the response of a connection established before the keeper started
+	p.ProcessPayload(protocolServerIP, 80, protocolClientIP, 5000, []byte("HTTP/1.1 200 OK\r\n"), now)
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 443, []byte{0x16, 0x03, 0x01}, now)
+
+	assert.Empty(t, p.GetAndResetServiceStats())
+	assert.Equal(t, int64(0), p.GetStats()["tracked_conns"])
+}
+
+func TestProtocolStatsLimits(t *testing.T) {
+	p := newProtocolStatKeeper(1, 1, time.Minute)
+	now := time.Now()
+
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 80, []byte("GET / HTTP/1.1\r\n"), now)
+	p.ProcessPayload(protocolClientIP, 5001, protocolServerIP, 8080, []byte("GET / HTTP/1.1\r\n"), now)
+
+	stats := p.GetStats()
+	assert.Equal(t, int64(1), stats["tracked_conns"])
+	assert.Equal(t, int64(1), stats["conns_dropped"])
+}
+
+func TestProtocolStatsExpiration(t *testing.T) {
+	p := newProtocolStatKeeper(100, 100, time.Minute)
+	now := time.Now()
+
+	p.ProcessPayload(protocolClientIP, 5000, protocolServerIP, 80, []byte("GET / HTTP/1.1\r\n"), now)
+	p.ProcessPayload(protocolClientIP, 5001, protocolServerIP, 80, []byte("GET / HTTP/1.1\r\n"), now.Add(50*time.Second))
+
+	p.RemoveExpiredConns(now.Add(90 * time.Second))
+	assert.Equal(t, ProtocolUnknown, p.Protocol(protocolClientIP, 5000, protocolServerIP, 80))
+	assert.Equal(t, ProtocolHTTP, p.Protocol(protocolClientIP, 5001, protocolServerIP, 80))
+}
+
+func TestFormatServices(t *testing.T) {
+	stats := map[ServiceKey]ServiceStats{
+		{IP: protocolServerIP, Port: 5432, Protocol: ProtocolPostgres}: {Requests: 3, Errors: 1, LatencySum: 1500},
+	}
+	assert.Equal(t, []Service{{IP: "10.0.0.2", Port: 5432, Protocol: "postgres", Requests: 3, Errors: 1, LatencySum: 1500}}, FormatServices(stats))
+}
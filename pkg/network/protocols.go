@@ -0,0 +1,229 @@
+package network
+
+import (
+	"bytes"
+	"encoding/binary"
+)
+
+// ProtocolType is the application protocol of a connection, as classified from its payloads
+type ProtocolType uint8
+
+const (
+	// ProtocolUnknown means the payloads of the connection did not match any supported protocol
+	ProtocolUnknown ProtocolType = iota
+	// ProtocolHTTP is HTTP/1.x
+	ProtocolHTTP
+	// ProtocolHTTP2 is HTTP/2 with prior knowledge, e.g. gRPC
+	ProtocolHTTP2
+	// ProtocolKafka is the Kafka wire protocol
+	ProtocolKafka
+	// ProtocolPostgres is the PostgreSQL frontend/backend protocol
+	ProtocolPostgres
+)
+
+func (p ProtocolType) String() string {
+	switch p {
+	case ProtocolHTTP:
+		return "http"
+	case ProtocolHTTP2:
+		return "http2"
+	case ProtocolKafka:
+		return "kafka"
+	case ProtocolPostgres:
+		return "postgres"
+	default:
+		return "unknown"
+	}
+}
+
+// protocolMessage describes a payload of a classified connection
+type protocolMessage struct {
+	// request is true when the payload is sent by the client
+	request bool
+	// response is true when the payload completes a response
+	response bool
+	// error is true when the response reports a server side error
+	error bool
+	// correlationID matches a response to its request, for the protocols supporting it
+	correlationID int32
+}
+
+var (
+	httpMethods = [][]byte{
+		[]byte("GET "), []byte("POST "), []byte("PUT "), []byte("DELETE "), []byte("HEAD "),
+		[]byte("OPTIONS "), []byte("PATCH "), []byte("CONNECT "), []byte("TRACE "),
+	}
+	httpResponsePrefix = []byte("HTTP/1.")
+	http2Preface       = []byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n")
+)
+
+const (
+	http2FrameHeaderSize = 9
+	http2HeadersFrame    = 0x1
+
+	// HPACK static table indexes of the :status pseudo-header with the first bit set (indexed field)
+	http2Status500 = 0x8e
+
+	kafkaMaxAPIKey     = 67
+	kafkaMaxAPIVersion = 12
+
+	postgresProtocolVersion = 196608   // 3.0
+	postgresSSLRequest      = 80877103 // magic number of the SSLRequest message
+)
+
+// classifyRequest returns the protocol of a payload sent by a client, ProtocolUnknown if the payload
+// is not the beginning of a supported request.
+func classifyRequest(payload []byte) ProtocolType {
+	switch {
+	case isHTTPRequest(payload):
+		return ProtocolHTTP
+	case bytes.HasPrefix(payload, http2Preface):
+		return ProtocolHTTP2
+	case isPostgresStartup(payload) || isPostgresQuery(payload):
+		return ProtocolPostgres
+	case isKafkaRequest(payload):
+		return ProtocolKafka
+	default:
+		return ProtocolUnknown
+	}
+}
+
+// parseMessage parses a payload of a connection already classified, fromClient tells
+// whether it is sent by the client of the connection.
+func parseMessage(protocol ProtocolType, payload []byte, fromClient bool) protocolMessage {
+	switch protocol {
+	case ProtocolHTTP:
+		return parseHTTP(payload, fromClient)
+	case ProtocolHTTP2:
+		return parseHTTP2(payload, fromClient)
+	case ProtocolKafka:
+		return parseKafka(payload, fromClient)
+	case ProtocolPostgres:
+		return parsePostgres(payload, fromClient)
+	default:
+		return protocolMessage{}
+	}
+}
+
+func isHTTPRequest(payload []byte) bool {
+	for _, method := range httpMethods {
+		if bytes.HasPrefix(payload, method) {
+			return true
+		}
+	}
+	return false
+}
+
+func parseHTTP(payload []byte, fromClient bool) protocolMessage {
+	if fromClient {
+		return protocolMessage{request: isHTTPRequest(payload)}
+	}
+	// 'HTTP/1.1 500 Internal Server Error'
+	if !bytes.HasPrefix(payload, httpResponsePrefix) || len(payload) < len(httpResponsePrefix)+5 {
+		return protocolMessage{}
+	}
+	status := payload[len(httpResponsePrefix)+2]
+	return protocolMessage{response: true, error: status == '5'}
+}
+
+// parseHTTP2 counts a HEADERS frame sent by the client as a request and a HEADERS frame sent by
+// the server as a response, the status of the response is read when it is an indexed header field.
+func parseHTTP2(payload []byte, fromClient bool) protocolMessage {
+	payload = bytes.TrimPrefix(payload, http2Preface)
+	var msg protocolMessage
+	for len(payload) >= http2FrameHeaderSize {
+		length := int(payload[0])<<16 | int(payload[1])<<8 | int(payload[2])
+		frameType := payload[3]
+		end := http2FrameHeaderSize + length
+		if frameType == http2HeadersFrame {
+			if fromClient {
+				msg.request = true
+			} else {
+				msg.response = true
+				if end > http2FrameHeaderSize && len(payload) > http2FrameHeaderSize && payload[http2FrameHeaderSize] == http2Status500 {
+					msg.error = true
+				}
+			}
+		}
+		if end > len(payload) {
+			break
+		}
+		payload = payload[end:]
+	}
+	return msg
+}
+
+// isKafkaRequest checks the header of a Kafka request:
+// 'size:int32 api_key:int16 api_version:int16 correlation_id:int32 client_id:nullable_string'
+func isKafkaRequest(payload []byte) bool {
+	if len(payload) < 14 {
+		return false
+	}
+	size := int32(binary.BigEndian.Uint32(payload))
+	apiKey := int16(binary.BigEndian.Uint16(payload[4:]))
+	apiVersion := int16(binary.BigEndian.Uint16(payload[6:]))
+	correlationID := int32(binary.BigEndian.Uint32(payload[8:]))
+	clientIDLength := int16(binary.BigEndian.Uint16(payload[12:]))
+	return size > 0 && int(size) >= len(payload)-4 &&
+		apiKey >= 0 && apiKey <= kafkaMaxAPIKey &&
+		apiVersion >= 0 && apiVersion <= kafkaMaxAPIVersion &&
+		correlationID >= 0 &&
+		clientIDLength >= -1 && int(clientIDLength) <= int(size)-10
+}
+
+// parseKafka reads the correlation ID of the requests and responses, the responses
+// start with 'size:int32 correlation_id:int32'.
+func parseKafka(payload []byte, fromClient bool) protocolMessage {
+	if fromClient {
+		if !isKafkaRequest(payload) {
+			return protocolMessage{}
+		}
+		return protocolMessage{request: true, correlationID: int32(binary.BigEndian.Uint32(payload[8:]))}
+	}
+	if len(payload) < 8 {
+		return protocolMessage{}
+	}
+	return protocolMessage{response: true, correlationID: int32(binary.BigEndian.Uint32(payload[4:]))}
+}
+
+// isPostgresStartup checks for a StartupMessage or a SSLRequest: 'length:int32 code:int32'
+func isPostgresStartup(payload []byte) bool {
+	if len(payload) < 8 {
+		return false
+	}
+	length := binary.BigEndian.Uint32(payload)
+	code := binary.BigEndian.Uint32(payload[4:])
+	return int(length) == len(payload) && (code == postgresProtocolVersion || code == postgresSSLRequest)
+}
+
+// isPostgresQuery checks for a simple Query or a Parse message: 'type:byte length:int32 query:string'
+func isPostgresQuery(payload []byte) bool {
+	if len(payload) < 6 || (payload[0] != 'Q' && payload[0] != 'P') {
+		return false
+	}
+	length := binary.BigEndian.Uint32(payload[1:])
+	return length >= 5 && int(length) <= len(payload)-1 && payload[length] == 0
+}
+
+// parsePostgres counts the queries as requests and the ReadyForQuery messages as the end of the responses,
+// an ErrorResponse message before the end of a response marks it as an error.
+func parsePostgres(payload []byte, fromClient bool) protocolMessage {
+	if fromClient {
+		return protocolMessage{request: isPostgresQuery(payload)}
+	}
+	var msg protocolMessage
+	for len(payload) >= 5 {
+		switch payload[0] {
+		case 'E':
+			msg.error = true
+		case 'Z':
+			msg.response = true
+		}
+		length := binary.BigEndian.Uint32(payload[1:])
+		if length < 4 || int(length)+1 > len(payload) {
+			break
+		}
+		payload = payload[length+1:]
+	}
+	return msg
+}
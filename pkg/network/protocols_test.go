@@ -0,0 +1,106 @@
+package network
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func kafkaRequest(apiKey, apiVersion int16, correlationID int32, clientID string) []byte {
+	payload := make([]byte, 14+len(clientID))
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+	binary.BigEndian.PutUint16(payload[4:], uint16(apiKey))
+	binary.BigEndian.PutUint16(payload[6:], uint16(apiVersion))
+	binary.BigEndian.PutUint32(payload[8:], uint32(correlationID))
+	binary.BigEndian.PutUint16(payload[12:], uint16(len(clientID)))
+	copy(payload[14:], clientID)
+	return payload
+}
+
+func kafkaResponse(correlationID int32) []byte {
+	payload := make([]byte, 12)
+	binary.BigEndian.PutUint32(payload, uint32(len(payload)-4))
+	binary.BigEndian.PutUint32(payload[4:], uint32(correlationID))
+	return payload
+}
+
+func postgresMessage(msgType byte, body string) []byte {
+	payload := make([]byte, 5+len(body))
+	payload[0] = msgType
+	binary.BigEndian.PutUint32(payload[1:], uint32(4+len(body)))
+	copy(payload[5:], body)
+	return payload
+}
+
+func http2Frame(frameType byte, payload ...byte) []byte {
+	frame := []byte{0, 0, byte(len(payload)), frameType, 0x4, 0, 0, 0, 1}
+	return append(frame, payload...)
+}
+
+func TestClassifyRequest(t *testing.T) {
+	startup := make([]byte, 8)
+	binary.BigEndian.PutUint32(startup, 8)
+	binary.BigEndian.PutUint32(startup[4:], postgresSSLRequest)
+
+	for _, tc := range []struct {
+		name     string
+		payload  []byte
+		protocol ProtocolType
+	}{
+		{"http get", []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\n\r\n"), ProtocolHTTP},
+		{"http post", []byte("POST /api HTTP/1.1\r\n"), ProtocolHTTP},
+		{"http2 preface", append(append([]byte{}, http2Preface...), http2Frame(0x4)...), ProtocolHTTP2},
+		{"kafka request", kafkaRequest(3, 9, 42, "consumer"), ProtocolKafka},
+		{"kafka null client id", kafkaRequest(0, 8, 1, "")[:14], ProtocolKafka},
+		{"postgres ssl request", startup, ProtocolPostgres},
+		{"postgres query", postgresMessage('Q', "SELECT 1\x00"), ProtocolPostgres},
+		{"http response", []byte("HTTP/1.1 200 OK\r\n"), ProtocolUnknown},
+		{"lowercase method", []byte("get / HTTP/1.1\r\n"), ProtocolUnknown},
+		{"tls client hello", []byte{0x16, 0x03, 0x01, 0x02, 0x00, 0x01, 0x00, 0x01, 0xfc, 0x03, 0x03, 0x00, 0x00, 0x00}, ProtocolUnknown},
+		{"kafka invalid api key", kafkaRequest(1000, 1, 1, "id"), ProtocolUnknown},
+		{"empty", nil, ProtocolUnknown},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.protocol, classifyRequest(tc.payload))
+		})
+	}
+}
+
+func TestParseHTTP(t *testing.T) {
+	assert.Equal(t, protocolMessage{request: true}, parseMessage(ProtocolHTTP, []byte("GET / HTTP/1.1\r\n"), true))
+	assert.Equal(t, protocolMessage{response: true}, parseMessage(ProtocolHTTP, []byte("HTTP/1.1 404 Not Found\r\n"), false))
+	assert.Equal(t, protocolMessage{response: true, error: true}, parseMessage(ProtocolHTTP, []byte("HTTP/1.1 503 Service Unavailable\r\n"), false))
+	// continuation of a response body
+	assert.Equal(t, protocolMessage{}, parseMessage(ProtocolHTTP, []byte("<html></html>"), false))
+}
+
+func TestParseHTTP2(t *testing.T) {
+	request := append(append([]byte{}, http2Preface...), http2Frame(0x4)...)
+	request = append(request, http2Frame(http2HeadersFrame, 0x82, 0x86)...)
+	assert.Equal(t, protocolMessage{request: true}, parseMessage(ProtocolHTTP2, request, true))
+
+	assert.Equal(t, protocolMessage{response: true}, parseMessage(ProtocolHTTP2, http2Frame(http2HeadersFrame, 0x88), false))
+	assert.Equal(t, protocolMessage{response: true, error: true}, parseMessage(ProtocolHTTP2, http2Frame(http2HeadersFrame, http2Status500), false))
+	// DATA frame only
+	assert.Equal(t, protocolMessage{}, parseMessage(ProtocolHTTP2, http2Frame(0x0, 'a', 'b'), false))
+}
+
+func TestParseKafka(t *testing.T) {
+	assert.Equal(t, protocolMessage{request: true, correlationID: 42}, parseMessage(ProtocolKafka, kafkaRequest(1, 11, 42, "consumer"), true))
+	assert.Equal(t, protocolMessage{response: true, correlationID: 42}, parseMessage(ProtocolKafka, kafkaResponse(42), false))
+}
+
+func TestParsePostgres(t *testing.T) {
+	assert.Equal(t, protocolMessage{request: true}, parseMessage(ProtocolPostgres, postgresMessage('Q', "SELECT 1\x00"), true))
+
+	response := append(postgresMessage('T', "row description"), postgresMessage('C', "SELECT 1\x00")...)
+	response = append(response, postgresMessage('Z', "I")...)
+	assert.Equal(t, protocolMessage{response: true}, parseMessage(ProtocolPostgres, response, false))
+
+	failure := append(postgresMessage('E', "SERROR\x00"), postgresMessage('Z', "I")...)
+	assert.Equal(t, protocolMessage{response: true, error: true}, parseMessage(ProtocolPostgres, failure, false))
+
+	// the response continues in the next packet
+	assert.Equal(t, protocolMessage{}, parseMessage(ProtocolPostgres, postgresMessage('D', "data row"), false))
+}
@@ -43,6 +43,10 @@ type State interface {
 	// RemoveConnections removes the given keys from the state
 	RemoveConnections(keys []string)
 
+	// DNSDomainStats returns the DNS stats of the connections returned to the given client since the last call,
+	// aggregated by process and queried domain
+	DNSDomainStats(clientID string) []DNSDomainStats
+
 	// GetStats returns a map of statistics about the current network state
 	GetStats() map[string]interface{}
 
@@ -72,6 +76,7 @@ type client struct {
 	closedConnections map[string]ConnectionStats
 	stats             map[string]*stats
 	dnsStats          map[dnsKey]dnsStats
+	dnsDomainStats    map[dnsDomainKey]DNSStats
 }
 
 type networkState struct {
@@ -202,6 +207,8 @@ func (ns *networkState) addDNSStats(id string, conns []ConnectionStats) {
 			conn.DNSTimeouts = dnsStats.timeouts
 			conn.DNSSuccessLatencySum = dnsStats.successLatencySum
 			conn.DNSFailureLatencySum = dnsStats.failureLatencySum
+			conn.DNSStatsByDomain = dnsStats.byDomain
+			ns.storeDNSDomainStats(ns.clients[id], conn.Pid, dnsStats.byDomain)
 		}
 		seen[key] = struct{}{}
 	}
@@ -271,6 +278,7 @@ func (ns *networkState) storeDNSStats(stats map[dnsKey]dnsStats) {
 				prev.timeouts += dns.timeouts
 				prev.successLatencySum += dns.successLatencySum
 				prev.failureLatencySum += dns.failureLatencySum
+				prev.byDomain = mergeDomainStats(prev.byDomain, dns.byDomain, maxDomainsPerKey)
 				client.dnsStats[key] = prev
 			} else if len(client.dnsStats) >= ns.maxDNSStats {
 				ns.telemetry.dnsStatsDropped++
@@ -282,6 +290,39 @@ func (ns *networkState) storeDNSStats(stats map[dnsKey]dnsStats) {
 	}
 }
 
+// storeDNSDomainStats aggregates the DNS stats of a connection by process and queried domain
+func (ns *networkState) storeDNSDomainStats(client *client, pid uint32, byDomain map[string]DNSStats) {
+	for domain, stats := range byDomain {
+		key := dnsDomainKey{pid: pid, domain: domain}
+		prev, ok := client.dnsDomainStats[key]
+		if !ok && len(client.dnsDomainStats) >= ns.maxDNSStats {
+			ns.telemetry.dnsStatsDropped++
+			continue
+		}
+		prev.merge(stats)
+		client.dnsDomainStats[key] = prev
+	}
+}
+
+// DNSDomainStats returns the DNS stats of the connections returned to the given client since the last call,
+// aggregated by process and queried domain
+func (ns *networkState) DNSDomainStats(id string) []DNSDomainStats {
+	ns.Lock()
+	defer ns.Unlock()
+
+	client, ok := ns.clients[id]
+	if !ok {
+		return nil
+	}
+
+	domainStats := make([]DNSDomainStats, 0, len(client.dnsDomainStats))
+	for key, stats := range client.dnsDomainStats {
+		domainStats = append(domainStats, DNSDomainStats{Pid: key.pid, Domain: key.domain, DNSStats: stats})
+	}
+	client.dnsDomainStats = map[dnsDomainKey]DNSStats{}
+	return domainStats
+}
+
 // newClient creates a new client and returns true if the given client already exists
 func (ns *networkState) newClient(clientID string) (*client, bool) {
 	if c, ok := ns.clients[clientID]; ok {
@@ -293,6 +334,7 @@ func (ns *networkState) newClient(clientID string) (*client, bool) {
 		stats:             map[string]*stats{},
 		closedConnections: map[string]ConnectionStats{},
 		dnsStats:          map[dnsKey]dnsStats{},
+		dnsDomainStats:    map[dnsDomainKey]DNSStats{},
 	}
 	ns.clients[clientID] = c
 	return c, false
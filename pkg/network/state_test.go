@@ -1209,6 +1209,50 @@ func TestDNSStatsPIDCollisions(t *testing.T) {
 	assert.Equal(t, int64(1), state.(*networkState).telemetry.dnsPidCollisions)
 }
 
+func TestDNSDomainStats(t *testing.T) {
+	c := ConnectionStats{
+		Pid:    123,
+		Type:   UDP,
+		Family: AFINET,
+		Source: util.AddressFromString("10.0.0.1"),
+		Dest:   util.AddressFromString("8.8.8.8"),
+		SPort:  1000,
+		DPort:  53,
+	}
+
+	dKey := dnsKey{clientIP: c.Source, clientPort: c.SPort, serverIP: c.Dest, protocol: c.Type}
+	stats := map[dnsKey]dnsStats{
+		dKey: {
+			successfulResponses: 2,
+			failedResponses:     1,
+			byDomain: map[string]DNSStats{
+				"golang.org":      {SuccessfulResponses: 2, SuccessLatencySum: 30},
+				"unknown.invalid": {FailedResponses: 1, NXDomainResponses: 1, FailureLatencySum: 5},
+			},
+		},
+	}
+
+	client := "client"
+	state := newDefaultState()
+
+	// Register the client
+	assert.Len(t, state.Connections(client, latestEpochTime(), nil, nil), 0)
+
+	conns := state.Connections(client, latestEpochTime(), []ConnectionStats{c}, stats)
+	require.Len(t, conns, 1)
+	assert.Equal(t, stats[dKey].byDomain, conns[0].DNSStatsByDomain)
+
+	domainStats := state.DNSDomainStats(client)
+	assert.ElementsMatch(t, []DNSDomainStats{
+		{Pid: 123, Domain: "golang.org", DNSStats: DNSStats{SuccessfulResponses: 2, SuccessLatencySum: 30}},
+		{Pid: 123, Domain: "unknown.invalid", DNSStats: DNSStats{FailedResponses: 1, NXDomainResponses: 1, FailureLatencySum: 5}},
+	}, domainStats)
+
+	// the stats are flushed
+	assert.Empty(t, state.DNSDomainStats(client))
+	assert.Empty(t, state.DNSDomainStats("unknown"))
+}
+
 func generateRandConnections(n int) []ConnectionStats {
 	cs := make([]ConnectionStats, 0, n)
 	for i := 0; i < n; i++ {
@@ -0,0 +1,128 @@
+package traceroute
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxBufferedPaths bounds the number of paths waiting to be collected
+const maxBufferedPaths = 1000
+
+// Monitor periodically runs traceroutes to a set of destinations, and on demand to any
+// destination, keeping the resulting paths until they are collected. A path is flagged
+// as changed when it differs from the previous path to the same destination.
+type Monitor struct {
+	destinations []Destination
+	interval     time.Duration
+	cfg          Config
+	run          func(Destination, Config) (*Path, error)
+
+	mu         sync.Mutex
+	paths      []*Path
+	signatures map[string]string
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMonitor returns a new Monitor, the invalid destinations are ignored.
+func NewMonitor(destinations []Destination, interval time.Duration, cfg Config) *Monitor {
+	valid := make([]Destination, 0, len(destinations))
+	for _, dest := range destinations {
+		if err := dest.Normalize(); err != nil {
+			log.Warnf("Ignoring traceroute destination: %s", err)
+			continue
+		}
+		valid = append(valid, dest)
+	}
+
+	return &Monitor{
+		destinations: valid,
+		interval:     interval,
+		cfg:          cfg,
+		run:          Run,
+		signatures:   make(map[string]string),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+}
+
+// Start starts the periodic traceroutes, if any destination is configured.
+func (m *Monitor) Start() {
+	if len(m.destinations) == 0 || m.interval <= 0 {
+		close(m.done)
+		return
+	}
+	go m.runPeriodically()
+}
+
+// Stop stops the periodic traceroutes.
+func (m *Monitor) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Monitor) runPeriodically() {
+	defer close(m.done)
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		for _, dest := range m.destinations {
+			if _, err := m.Trace(dest); err != nil {
+				log.Warnf("Could not run the traceroute to %s: %s", dest, err)
+			}
+		}
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Trace runs a traceroute to the destination and keeps the resulting path until it is collected.
+func (m *Monitor) Trace(dest Destination) (*Path, error) {
+	if err := dest.Normalize(); err != nil {
+		return nil, err
+	}
+	path, err := m.run(dest, m.cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// only the complete paths are compared, the unanswered probes are too common
+	if path.Reached {
+		key := dest.String()
+		signature := path.signature()
+		if previous, found := m.signatures[key]; found && previous != signature {
+			path.PathChanged = true
+			log.Infof("The path to %s changed: %s -> %s", dest, previous, signature)
+		}
+		m.signatures[key] = signature
+	}
+
+	if len(m.paths) >= maxBufferedPaths {
+		log.Debugf("Too many traceroute paths buffered, dropping the oldest one")
+		m.paths = m.paths[1:]
+	}
+	m.paths = append(m.paths, path)
+	return path, nil
+}
+
+// GetAndFlush returns the paths since the last call.
+func (m *Monitor) GetAndFlush() []*Path {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	paths := m.paths
+	m.paths = nil
+	if paths == nil {
+		paths = []*Path{}
+	}
+	return paths
+}
@@ -0,0 +1,60 @@
+package traceroute
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorDetectsPathChanges(t *testing.T) {
+	route := []string{"192.168.0.1", "10.0.0.1"}
+	reached := true
+	m := NewMonitor(nil, 0, Config{})
+	m.run = func(dest Destination, cfg Config) (*Path, error) {
+		path := &Path{Destination: dest, Reached: reached}
+		for i, ip := range route {
+			path.Hops = append(path.Hops, Hop{TTL: i + 1, IP: ip})
+		}
+		return path, nil
+	}
+
+	dest := Destination{Host: "10.0.0.1"}
+	path, err := m.Trace(dest)
+	require.NoError(t, err)
+	assert.False(t, path.PathChanged)
+	assert.Equal(t, ProtocolUDP, path.Protocol)
+
+	path, err = m.Trace(dest)
+	require.NoError(t, err)
+	assert.False(t, path.PathChanged)
+
+	route = []string{"192.168.0.1", "", "10.0.0.1"}
+	path, err = m.Trace(dest)
+	require.NoError(t, err)
+	assert.True(t, path.PathChanged)
+
+	// incomplete paths are not compared
+	reached = false
+	route = []string{"192.168.0.1"}
+	path, err = m.Trace(dest)
+	require.NoError(t, err)
+	assert.False(t, path.PathChanged)
+
+	// the paths are tracked per destination
+	reached = true
+	path, err = m.Trace(Destination{Host: "10.0.0.1", Protocol: ProtocolTCP})
+	require.NoError(t, err)
+	assert.False(t, path.PathChanged)
+
+	assert.Len(t, m.GetAndFlush(), 5)
+	assert.Len(t, m.GetAndFlush(), 0)
+}
+
+func TestMonitorIgnoresInvalidDestinations(t *testing.T) {
+	m := NewMonitor([]Destination{{Host: "10.0.0.1"}, {Host: "10.0.0.2", Protocol: "icmp"}}, 0, Config{})
+	assert.Equal(t, []Destination{{Host: "10.0.0.1", Port: DefaultUDPPort, Protocol: ProtocolUDP}}, m.destinations)
+
+	_, err := m.Trace(Destination{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,261 @@
+// Package traceroute discovers the network path to a destination by sending UDP or TCP
+// probes with increasing TTLs and listening for the ICMP replies of the hops.
+package traceroute
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/icmp"
+)
+
+// Protocols of the probes
+const (
+	ProtocolUDP = "udp"
+	ProtocolTCP = "tcp"
+)
+
+const (
+	// DefaultUDPPort is the destination port of the UDP probes, unlikely to be listened on
+	DefaultUDPPort = 33434
+	// DefaultTCPPort is the destination port of the TCP probes
+	DefaultTCPPort = 443
+	// DefaultMaxTTL is the maximum number of hops to the destination
+	DefaultMaxTTL = 30
+	// DefaultTimeout is the delay to wait for the replies to the probes
+	DefaultTimeout = 2 * time.Second
+
+	// ianaProtocolICMP is the IANA protocol number of ICMP
+	ianaProtocolICMP = 1
+	// ianaProtocolTCP and ianaProtocolUDP are the IANA protocol numbers of TCP and UDP
+	ianaProtocolTCP = 6
+	ianaProtocolUDP = 17
+)
+
+// ErrNotImplemented is returned on the platforms where the traceroute is not supported
+var ErrNotImplemented = errors.New("traceroute is only supported on Linux")
+
+// Destination is the destination of a traceroute.
+type Destination struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol"`
+}
+
+// Normalize sets the default protocol and port of the destination and validates it.
+func (d *Destination) Normalize() error {
+	if d.Host == "" {
+		return errors.New("the host of the destination is missing")
+	}
+	d.Protocol = strings.ToLower(d.Protocol)
+	switch d.Protocol {
+	case "", ProtocolUDP:
+		d.Protocol = ProtocolUDP
+		if d.Port == 0 {
+			d.Port = DefaultUDPPort
+		}
+	case ProtocolTCP:
+		if d.Port == 0 {
+			d.Port = DefaultTCPPort
+		}
+	default:
+		return fmt.Errorf("invalid protocol %s for %s, must be %s or %s", d.Protocol, d.Host, ProtocolUDP, ProtocolTCP)
+	}
+	if d.Port < 1 || d.Port > 65535 {
+		return fmt.Errorf("invalid port %d for %s", d.Port, d.Host)
+	}
+	return nil
+}
+
+// String returns a representation of the destination.
+func (d Destination) String() string {
+	return fmt.Sprintf("%s://%s", d.Protocol, net.JoinHostPort(d.Host, fmt.Sprint(d.Port)))
+}
+
+// Hop is a router on the path to the destination, its IP is empty if it did not reply.
+type Hop struct {
+	TTL     int     `json:"ttl"`
+	IP      string  `json:"ip,omitempty"`
+	RTT     float64 `json:"rtt_ms,omitempty"`
+	Reached bool    `json:"reached"`
+}
+
+// Path is the result of a traceroute.
+type Path struct {
+	Destination
+	IP          string `json:"ip"`
+	Timestamp   int64  `json:"timestamp"`
+	Hops        []Hop  `json:"hops"`
+	Reached     bool   `json:"reached"`
+	PathChanged bool   `json:"path_changed"`
+}
+
+// signature identifies the path through the IPs of the hops.
+func (p *Path) signature() string {
+	ips := make([]string, 0, len(p.Hops))
+	for _, hop := range p.Hops {
+		if hop.IP == "" {
+			ips = append(ips, "*")
+		} else {
+			ips = append(ips, hop.IP)
+		}
+	}
+	return strings.Join(ips, ",")
+}
+
+// Config configures the traceroutes.
+type Config struct {
+	MaxTTL  int
+	Timeout time.Duration
+}
+
+// icmpReply is an ICMP reply to a probe.
+type icmpReply struct {
+	// unreachable is true for a destination unreachable reply, false for a time exceeded one
+	unreachable bool
+	// protocol, dst, srcPort and dstPort are those of the probe quoted in the reply
+	protocol int
+	dst      net.IP
+	srcPort  int
+	dstPort  int
+}
+
+// parseICMPReply parses an ICMP time exceeded or destination unreachable message,
+// which quotes the IP header and the first 8 bytes of the probe.
+func parseICMPReply(b []byte) (*icmpReply, error) {
+	msg, err := icmp.ParseMessage(ianaProtocolICMP, b)
+	if err != nil {
+		return nil, err
+	}
+
+	reply := &icmpReply{}
+	var data []byte
+	switch body := msg.Body.(type) {
+	case *icmp.TimeExceeded:
+		data = body.Data
+	case *icmp.DstUnreach:
+		reply.unreachable = true
+		data = body.Data
+	default:
+		return nil, fmt.Errorf("unexpected ICMP message type %v", msg.Type)
+	}
+
+	if len(data) < 20 || data[0]>>4 != 4 {
+		return nil, errors.New("the quoted datagram is not an IPv4 one")
+	}
+	headerLen := int(data[0]&0x0f) * 4
+	if len(data) < headerLen+4 {
+		return nil, errors.New("the quoted datagram is truncated")
+	}
+	reply.protocol = int(data[9])
+	reply.dst = net.IP(data[16:20])
+	reply.srcPort = int(binary.BigEndian.Uint16(data[headerLen:]))
+	reply.dstPort = int(binary.BigEndian.Uint16(data[headerLen+2:]))
+	return reply, nil
+}
+
+// tcpSegment is the header of a TCP segment received in reply to a SYN probe.
+type tcpSegment struct {
+	srcPort int
+	dstPort int
+	flags   byte
+}
+
+// TCP flags
+const (
+	tcpFlagRST = 0x04
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+)
+
+// acceptsOrRefuses returns true for a SYN-ACK or a RST, the destination replied to the SYN.
+func (s *tcpSegment) acceptsOrRefuses() bool {
+	return s.flags&(tcpFlagSYN|tcpFlagACK) == tcpFlagSYN|tcpFlagACK || s.flags&tcpFlagRST != 0
+}
+
+// marshalTCPSyn returns a TCP SYN segment without options, its checksum computed over
+// the IPv4 pseudo header.
+func marshalTCPSyn(src, dst net.IP, srcPort, dstPort int, seq uint32) []byte {
+	b := make([]byte, 20)
+	binary.BigEndian.PutUint16(b[0:], uint16(srcPort))
+	binary.BigEndian.PutUint16(b[2:], uint16(dstPort))
+	binary.BigEndian.PutUint32(b[4:], seq)
+	b[12] = 5 << 4 // data offset of 5 words
+	b[13] = tcpFlagSYN
+	binary.BigEndian.PutUint16(b[14:], 1024) // window
+
+	pseudo := make([]byte, 0, 12+len(b))
+	pseudo = append(pseudo, src.To4()...)
+	pseudo = append(pseudo, dst.To4()...)
+	pseudo = append(pseudo, 0, ianaProtocolTCP, 0, byte(len(b)))
+	pseudo = append(pseudo, b...)
+	binary.BigEndian.PutUint16(b[16:], checksum(pseudo))
+	return b
+}
+
+// parseTCPSegment parses the ports and flags of a TCP segment.
+func parseTCPSegment(b []byte) (*tcpSegment, error) {
+	if len(b) < 20 {
+		return nil, errors.New("the TCP segment is truncated")
+	}
+	return &tcpSegment{
+		srcPort: int(binary.BigEndian.Uint16(b[0:])),
+		dstPort: int(binary.BigEndian.Uint16(b[2:])),
+		flags:   b[13],
+	}, nil
+}
+
+// checksum is the internet checksum of RFC 1071.
+func checksum(b []byte) uint16 {
+	var sum uint32
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(binary.BigEndian.Uint16(b[i:]))
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// resolve returns the IPv4 address of the host.
+func resolve(host string) (net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4, nil
+		}
+		return nil, fmt.Errorf("%s is not an IPv4 address", host)
+	}
+	ips, err := net.LookupIP(host)
+	if err != nil {
+		return nil, err
+	}
+	for _, ip := range ips {
+		if ip4 := ip.To4(); ip4 != nil {
+			return ip4, nil
+		}
+	}
+	return nil, fmt.Errorf("no IPv4 address found for %s", host)
+}
+
+// buildHops returns the hops up to the first one which is the destination, from the replies by TTL.
+func buildHops(replies []*Hop) ([]Hop, bool) {
+	hops := make([]Hop, 0, len(replies))
+	for i, reply := range replies {
+		hop := Hop{TTL: i + 1}
+		if reply != nil {
+			hop = *reply
+		}
+		hops = append(hops, hop)
+		if hop.Reached {
+			return hops, true
+		}
+	}
+	return hops, false
+}
@@ -0,0 +1,344 @@
+// +build linux
+
+package traceroute
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+// Supported is true if the traceroute is supported on this platform
+const Supported = true
+
+// pollInterval is the interval at which the TCP replies are checked
+const pollInterval = 50 * time.Millisecond
+
+var probePayload = []byte("datadog-agent traceroute")
+
+// probe is a packet sent with a given TTL, identified in the ICMP replies by its source port.
+type probe struct {
+	ttl    int
+	port   int
+	sentAt time.Time
+	reply  *Hop
+
+	// udpConn is the socket of a UDP probe
+	udpConn net.PacketConn
+	// tcpFd is the socket reserving the source port of a TCP probe, it is bound but neither
+	// connected nor listening so that the kernel resets the connection at the SYN-ACK
+	tcpFd int
+}
+
+func (p *probe) setReply(ip string, reached bool) {
+	p.reply = &Hop{
+		TTL:     p.ttl,
+		IP:      ip,
+		RTT:     float64(time.Since(p.sentAt)) / float64(time.Millisecond),
+		Reached: reached,
+	}
+}
+
+func (p *probe) close() {
+	if p.udpConn != nil {
+		p.udpConn.Close()
+	}
+	if p.tcpFd > 0 {
+		syscall.Close(p.tcpFd)
+	}
+}
+
+// Run runs a traceroute to the destination. The probes of all the TTLs are sent at once,
+// so that the traceroute lasts at most the timeout whatever the number of hops.
+// The TCP probes are half-open: a SYN is sent through a raw socket and the destination
+// is reached when it replies with a SYN-ACK or a RST, no connection is ever established.
+func Run(dest Destination, cfg Config) (*Path, error) {
+	if err := dest.Normalize(); err != nil {
+		return nil, err
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = DefaultMaxTTL
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+
+	ip, err := resolve(dest.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := icmp.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return nil, fmt.Errorf("could not listen for ICMP replies: %s", err)
+	}
+	defer conn.Close()
+
+	var tcp *tcpProber
+	if dest.Protocol == ProtocolTCP {
+		if tcp, err = newTCPProber(ip, cfg.MaxTTL); err != nil {
+			return nil, err
+		}
+		defer tcp.close()
+	}
+
+	start := time.Now()
+	probes := make([]*probe, 0, cfg.MaxTTL)
+	defer func() {
+		for _, p := range probes {
+			p.close()
+		}
+	}()
+	byPort := make(map[int]*probe, cfg.MaxTTL)
+	for ttl := 1; ttl <= cfg.MaxTTL; ttl++ {
+		var p *probe
+		if tcp != nil {
+			p, err = tcp.sendProbe(dest.Port, ttl)
+		} else {
+			p, err = sendUDPProbe(ip, dest.Port, ttl)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not send the probe with TTL %d: %s", ttl, err)
+		}
+		probes = append(probes, p)
+		byPort[p.port] = p
+	}
+
+	protocol := ianaProtocolUDP
+	if dest.Protocol == ProtocolTCP {
+		protocol = ianaProtocolTCP
+	}
+
+	deadline := start.Add(cfg.Timeout)
+	buf := make([]byte, 1500)
+	for time.Now().Before(deadline) && !isComplete(probes) {
+		tcp.checkReplies(byPort, dest.Port)
+
+		readDeadline := time.Now().Add(pollInterval)
+		if readDeadline.After(deadline) {
+			readDeadline = deadline
+		}
+		if err := conn.SetReadDeadline(readDeadline); err != nil {
+			return nil, err
+		}
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				continue
+			}
+			return nil, err
+		}
+
+		reply, err := parseICMPReply(buf[:n])
+		if err != nil || reply.protocol != protocol || !reply.dst.Equal(ip) || reply.dstPort != dest.Port {
+			// not a reply to one of our probes
+			continue
+		}
+		p, found := byPort[reply.srcPort]
+		if !found || p.reply != nil {
+			continue
+		}
+		peerIP := addrIP(peer)
+		p.setReply(peerIP.String(), reply.unreachable && peerIP.Equal(ip))
+	}
+	tcp.checkReplies(byPort, dest.Port)
+
+	replies := make([]*Hop, 0, len(probes))
+	for _, p := range probes {
+		replies = append(replies, p.reply)
+	}
+	hops, reached := buildHops(replies)
+	return &Path{
+		Destination: dest,
+		IP:          ip.String(),
+		Timestamp:   start.Unix(),
+		Hops:        hops,
+		Reached:     reached,
+	}, nil
+}
+
+// isComplete returns true when the destination replied and all the hops before it did too.
+func isComplete(probes []*probe) bool {
+	for _, p := range probes {
+		if p.reply == nil {
+			return false
+		}
+		if p.reply.Reached {
+			return true
+		}
+	}
+	return false
+}
+
+func sendUDPProbe(ip net.IP, port, ttl int) (*probe, error) {
+	conn, err := net.ListenPacket("udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	if err := ipv4.NewPacketConn(conn).SetTTL(ttl); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	p := &probe{
+		ttl:     ttl,
+		port:    conn.LocalAddr().(*net.UDPAddr).Port,
+		sentAt:  time.Now(),
+		udpConn: conn,
+	}
+	if _, err := conn.WriteTo(probePayload, &net.UDPAddr{IP: ip, Port: port}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// tcpProber sends the SYN probes through a raw socket and receives the TCP replies on it.
+type tcpProber struct {
+	conn    *ipv4.PacketConn
+	src     net.IP
+	dst     net.IP
+	replies chan *tcpSegment
+}
+
+func newTCPProber(dst net.IP, maxTTL int) (*tcpProber, error) {
+	src, err := sourceIP(dst)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the source address of the probes: %s", err)
+	}
+	conn, err := net.ListenPacket("ip4:tcp", src.String())
+	if err != nil {
+		return nil, fmt.Errorf("could not open a raw TCP socket: %s", err)
+	}
+	t := &tcpProber{
+		conn:    ipv4.NewPacketConn(conn),
+		src:     src,
+		dst:     dst,
+		replies: make(chan *tcpSegment, 2*maxTTL),
+	}
+	go t.receive(conn)
+	return t, nil
+}
+
+// receive forwards the TCP segments from the destination until the socket is closed.
+func (t *tcpProber) receive(conn net.PacketConn) {
+	buf := make([]byte, 1500)
+	for {
+		n, peer, err := conn.ReadFrom(buf)
+		if err != nil {
+			if netErr, ok := err.(net.Error); ok && netErr.Temporary() {
+				continue
+			}
+			return
+		}
+		if !addrIP(peer).Equal(t.dst) {
+			continue
+		}
+		segment, err := parseTCPSegment(buf[:n])
+		if err != nil {
+			continue
+		}
+		select {
+		case t.replies <- segment:
+		default:
+		}
+	}
+}
+
+// sendProbe sends a SYN with the TTL from a source port reserved by a bound socket.
+func (t *tcpProber) sendProbe(port, ttl int) (*probe, error) {
+	fd, srcPort, err := reservePort(t.src)
+	if err != nil {
+		return nil, err
+	}
+	p := &probe{
+		ttl:   ttl,
+		port:  srcPort,
+		tcpFd: fd,
+	}
+	if err := t.conn.SetTTL(ttl); err != nil {
+		p.close()
+		return nil, err
+	}
+	p.sentAt = time.Now()
+	segment := marshalTCPSyn(t.src, t.dst, srcPort, port, uint32(ttl))
+	if _, err := t.conn.WriteTo(segment, nil, &net.IPAddr{IP: t.dst}); err != nil {
+		p.close()
+		return nil, err
+	}
+	return p, nil
+}
+
+// checkReplies marks the probes to which the destination replied with a SYN-ACK or a RST.
+func (t *tcpProber) checkReplies(byPort map[int]*probe, port int) {
+	if t == nil {
+		return
+	}
+	for {
+		select {
+		case segment := <-t.replies:
+			if segment.srcPort != port || !segment.acceptsOrRefuses() {
+				continue
+			}
+			if p, found := byPort[segment.dstPort]; found && p.reply == nil {
+				p.setReply(t.dst.String(), true)
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (t *tcpProber) close() {
+	t.conn.Close()
+}
+
+// sourceIP returns the local address used to reach the destination.
+func sourceIP(dst net.IP) (net.IP, error) {
+	conn, err := net.DialUDP("udp4", nil, &net.UDPAddr{IP: dst, Port: DefaultUDPPort})
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).IP.To4(), nil
+}
+
+// reservePort binds a TCP socket to an ephemeral port of the source address.
+func reservePort(src net.IP) (int, int, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_STREAM, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+	addr := &syscall.SockaddrInet4{}
+	copy(addr.Addr[:], src.To4())
+	if err := syscall.Bind(fd, addr); err != nil {
+		syscall.Close(fd)
+		return 0, 0, err
+	}
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		syscall.Close(fd)
+		return 0, 0, err
+	}
+	bound, ok := sa.(*syscall.SockaddrInet4)
+	if !ok {
+		syscall.Close(fd)
+		return 0, 0, errors.New("unexpected socket address type")
+	}
+	return fd, bound.Port, nil
+}
+
+func addrIP(addr net.Addr) net.IP {
+	switch a := addr.(type) {
+	case *net.IPAddr:
+		return a.IP
+	case *net.UDPAddr:
+		return a.IP
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package traceroute
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+)
+
+func TestNormalize(t *testing.T) {
+	dest := Destination{Host: "example.com"}
+	require.NoError(t, dest.Normalize())
+	assert.Equal(t, Destination{Host: "example.com", Port: DefaultUDPPort, Protocol: ProtocolUDP}, dest)
+
+	dest = Destination{Host: "example.com", Protocol: "TCP"}
+	require.NoError(t, dest.Normalize())
+	assert.Equal(t, Destination{Host: "example.com", Port: DefaultTCPPort, Protocol: ProtocolTCP}, dest)
+	assert.Equal(t, "tcp://example.com:443", dest.String())
+
+	assert.Error(t, (&Destination{}).Normalize())
+	assert.Error(t, (&Destination{Host: "example.com", Protocol: "icmp"}).Normalize())
+	assert.Error(t, (&Destination{Host: "example.com", Port: 70000}).Normalize())
+}
+
+// quotedDatagram returns the IPv4 header and the first bytes of the transport header of a probe
+func quotedDatagram(protocol int, dst net.IP, srcPort, dstPort int) []byte {
+	data := make([]byte, 28)
+	data[0] = 0x45 // IPv4, 20 bytes header
+	data[9] = byte(protocol)
+	copy(data[16:20], dst.To4())
+	binary.BigEndian.PutUint16(data[20:], uint16(srcPort))
+	binary.BigEndian.PutUint16(data[22:], uint16(dstPort))
+	return data
+}
+
+func TestParseICMPReply(t *testing.T) {
+	dst := net.ParseIP("10.0.0.1")
+	msg := icmp.Message{
+		Type: ipv4.ICMPTypeTimeExceeded,
+		Body: &icmp.TimeExceeded{Data: quotedDatagram(ianaProtocolUDP, dst, 40000, DefaultUDPPort)},
+	}
+	b, err := msg.Marshal(nil)
+	require.NoError(t, err)
+
+	reply, err := parseICMPReply(b)
+	require.NoError(t, err)
+	assert.False(t, reply.unreachable)
+	assert.Equal(t, ianaProtocolUDP, reply.protocol)
+	assert.True(t, reply.dst.Equal(dst))
+	assert.Equal(t, 40000, reply.srcPort)
+	assert.Equal(t, DefaultUDPPort, reply.dstPort)
+
+	msg = icmp.Message{
+		Type: ipv4.ICMPTypeDestinationUnreachable,
+		Code: 3, // port unreachable
+		Body: &icmp.DstUnreach{Data: quotedDatagram(ianaProtocolTCP, dst, 40001, 443)},
+	}
+	b, err = msg.Marshal(nil)
+	require.NoError(t, err)
+
+	reply, err = parseICMPReply(b)
+	require.NoError(t, err)
+	assert.True(t, reply.unreachable)
+	assert.Equal(t, ianaProtocolTCP, reply.protocol)
+	assert.Equal(t, 40001, reply.srcPort)
+
+	msg = icmp.Message{
+		Type: ipv4.ICMPTypeEchoReply,
+		Body: &icmp.Echo{ID: 1, Seq: 1},
+	}
+	b, err = msg.Marshal(nil)
+	require.NoError(t, err)
+	_, err = parseICMPReply(b)
+	assert.Error(t, err)
+
+	msg = icmp.Message{
+		Type: ipv4.ICMPTypeTimeExceeded,
+		Body: &icmp.TimeExceeded{Data: []byte{0x45, 0}},
+	}
+	b, err = msg.Marshal(nil)
+	require.NoError(t, err)
+	_, err = parseICMPReply(b)
+	assert.Error(t, err)
+}
+
+func TestBuildHops(t *testing.T) {
+	hops, reached := buildHops([]*Hop{
+		{TTL: 1, IP: "192.168.0.1", RTT: 1},
+		nil,
+		{TTL: 3, IP: "10.0.0.1", RTT: 3, Reached: true},
+		{TTL: 4, IP: "10.0.0.1", RTT: 4, Reached: true},
+	})
+	assert.True(t, reached)
+	assert.Equal(t, []Hop{
+		{TTL: 1, IP: "192.168.0.1", RTT: 1},
+		{TTL: 2},
+		{TTL: 3, IP: "10.0.0.1", RTT: 3, Reached: true},
+	}, hops)
+
+	hops, reached = buildHops([]*Hop{nil, nil})
+	assert.False(t, reached)
+	assert.Equal(t, []Hop{{TTL: 1}, {TTL: 2}}, hops)
+}
+
+func TestTCPSyn(t *testing.T) {
+	src, dst := net.ParseIP("192.168.0.2"), net.ParseIP("10.0.0.1")
+	b := marshalTCPSyn(src, dst, 40000, 443, 1)
+
+	segment, err := parseTCPSegment(b)
+	require.NoError(t, err)
+	assert.Equal(t, &tcpSegment{srcPort: 40000, dstPort: 443, flags: tcpFlagSYN}, segment)
+	assert.False(t, segment.acceptsOrRefuses())
+
+	// the checksum of a segment including its checksum is zero
+	pseudo := append(append(append([]byte{}, src.To4()...), dst.To4()...), 0, ianaProtocolTCP, 0, 20)
+	assert.Equal(t, uint16(0), checksum(append(pseudo, b...)))
+
+	assert.True(t, (&tcpSegment{flags: tcpFlagSYN | tcpFlagACK}).acceptsOrRefuses())
+	assert.True(t, (&tcpSegment{flags: tcpFlagRST | tcpFlagACK}).acceptsOrRefuses())
+
+	_, err = parseTCPSegment(b[:10])
+	assert.Error(t, err)
+}
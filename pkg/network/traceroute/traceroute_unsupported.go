@@ -0,0 +1,11 @@
+// +build !linux
+
+package traceroute
+
+// Supported is true if the traceroute is supported on this platform
+const Supported = false
+
+// Run is not supported
+func Run(dest Destination, cfg Config) (*Path, error) {
+	return nil, ErrNotImplemented
+}
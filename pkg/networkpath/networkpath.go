@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package networkpath collects the network paths traced by the system-probe, periodically
+// or on demand, and sends them to the network path intake.
+package networkpath
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+	process_net "github.com/DataDog/datadog-agent/pkg/process/net"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+var collector *Collector
+
+// Payload is the payload sent to the network path intake.
+type Payload struct {
+	Host  string             `json:"host"`
+	Paths []*traceroute.Path `json:"paths"`
+}
+
+// sysProbeUtil is the part of the system-probe client running the traceroutes
+type sysProbeUtil interface {
+	GetTraceroutePaths() ([]*traceroute.Path, error)
+	RunTraceroute(dest traceroute.Destination) (*traceroute.Path, error)
+}
+
+var getSysProbeUtil = func() (sysProbeUtil, error) {
+	process_net.SetSystemProbePath(config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+	util, err := process_net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return nil, err
+	}
+	return util, nil
+}
+
+// Collector periodically collects the network paths traced by the system-probe.
+type Collector struct {
+	forwarder forwarder.Forwarder
+	hostname  string
+	interval  time.Duration
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewCollector returns a new Collector.
+func NewCollector(fwd forwarder.Forwarder, hostname string, interval time.Duration) *Collector {
+	return &Collector{
+		forwarder: fwd,
+		hostname:  hostname,
+		interval:  interval,
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// Start starts the collection of the network paths if enabled.
+func Start(fwd forwarder.Forwarder, hostname string) {
+	if !config.Datadog.GetBool("network_path.enabled") {
+		log.Debug("Network path collection disabled")
+		return
+	}
+	interval := config.Datadog.GetDuration("network_path.collect_interval")
+	if interval <= 0 {
+		log.Warnf("Invalid network_path.collect_interval %s, using the default %s", interval, time.Minute)
+		interval = time.Minute
+	}
+	collector = NewCollector(fwd, hostname, interval)
+	collector.Start()
+	log.Info("Network path collection started")
+}
+
+// Stop stops the collection of the network paths.
+func Stop() {
+	if collector != nil {
+		collector.Stop()
+		collector = nil
+	}
+}
+
+// Trace runs a traceroute to the destination from the system-probe. The path is sent
+// to the intake with the next collection, if enabled.
+func Trace(dest traceroute.Destination) (*traceroute.Path, error) {
+	if err := dest.Normalize(); err != nil {
+		return nil, err
+	}
+	util, err := getSysProbeUtil()
+	if err != nil {
+		return nil, err
+	}
+	return util.RunTraceroute(dest)
+}
+
+// Start starts the collector.
+func (c *Collector) Start() {
+	go c.run()
+}
+
+// Stop stops the collector.
+func (c *Collector) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.collect(); err != nil {
+				log.Debugf("Could not collect the network paths: %s", err)
+			}
+		}
+	}
+}
+
+// collect sends the paths traced since the last collection.
+func (c *Collector) collect() error {
+	util, err := getSysProbeUtil()
+	if err != nil {
+		return err
+	}
+	paths, err := util.GetTraceroutePaths()
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(&Payload{Host: c.hostname, Paths: paths})
+	if err != nil {
+		return err
+	}
+	extra := http.Header{}
+	extra.Set("Content-Type", "application/json")
+	if err := c.forwarder.SubmitNetworkPath(forwarder.Payloads{&payload}, extra); err != nil {
+		return err
+	}
+	log.Debugf("Sent %d network paths", len(paths))
+	return nil
+}
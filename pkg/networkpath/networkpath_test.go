@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package networkpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+)
+
+type fakeSysProbeUtil struct {
+	paths []*traceroute.Path
+	dest  traceroute.Destination
+}
+
+func (f *fakeSysProbeUtil) GetTraceroutePaths() ([]*traceroute.Path, error) {
+	paths := f.paths
+	f.paths = nil
+	return paths, nil
+}
+
+func (f *fakeSysProbeUtil) RunTraceroute(dest traceroute.Destination) (*traceroute.Path, error) {
+	f.dest = dest
+	return &traceroute.Path{Destination: dest, Reached: true}, nil
+}
+
+func withFakeSysProbeUtil(util *fakeSysProbeUtil) func() {
+	previous := getSysProbeUtil
+	getSysProbeUtil = func() (sysProbeUtil, error) {
+		return util, nil
+	}
+	return func() { getSysProbeUtil = previous }
+}
+
+func TestCollect(t *testing.T) {
+	util := &fakeSysProbeUtil{paths: []*traceroute.Path{
+		{Destination: traceroute.Destination{Host: "10.0.0.1"}, Hops: []traceroute.Hop{{TTL: 1, IP: "10.0.0.1", Reached: true}}, Reached: true},
+	}}
+	defer withFakeSysProbeUtil(util)()
+
+	var sent Payload
+	fwd := &forwarder.MockedForwarder{}
+	fwd.On("SubmitNetworkPath", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		payloads := args.Get(0).(forwarder.Payloads)
+		require.NoError(t, json.Unmarshal(*payloads[0], &sent))
+	})
+
+	c := NewCollector(fwd, "myhost", 0)
+	require.NoError(t, c.collect())
+	assert.Equal(t, "myhost", sent.Host)
+	require.Len(t, sent.Paths, 1)
+	assert.Equal(t, "10.0.0.1", sent.Paths[0].Host)
+	assert.True(t, sent.Paths[0].Reached)
+
+	// nothing is sent without new paths
+	require.NoError(t, c.collect())
+	fwd.AssertNumberOfCalls(t, "SubmitNetworkPath", 1)
+}
+
+func TestTrace(t *testing.T) {
+	util := &fakeSysProbeUtil{}
+	defer withFakeSysProbeUtil(util)()
+
+	path, err := Trace(traceroute.Destination{Host: "example.com", Protocol: "tcp"})
+	require.NoError(t, err)
+	assert.True(t, path.Reached)
+	assert.Equal(t, traceroute.Destination{Host: "example.com", Port: traceroute.DefaultTCPPort, Protocol: traceroute.ProtocolTCP}, util.dest)
+
+	_, err = Trace(traceroute.Destination{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,462 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// The decoder only reads the subset of the OTLP v1 metrics protobuf messages the receiver
+// translates, skipping the other fields, so that the agent doesn't depend on the generated
+// OpenTelemetry protobuf packages.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+type metricKind int
+
+const (
+	kindUnsupported metricKind = iota
+	kindGauge
+	kindSum
+	kindHistogram
+)
+
+type temporality uint64
+
+const (
+	temporalityUnspecified temporality = iota
+	temporalityDelta
+	temporalityCumulative
+)
+
+// exportRequest is an opentelemetry.proto.collector.metrics.v1.ExportMetricsServiceRequest
+type exportRequest struct {
+	resourceMetrics []resourceMetrics
+}
+
+// resourceMetrics holds the metrics of all the instrumentation scopes of a resource
+type resourceMetrics struct {
+	attributes []keyValue
+	metrics    []metric
+}
+
+// keyValue is an attribute whose value was rendered as a string, only the scalar values are kept
+type keyValue struct {
+	key   string
+	value string
+}
+
+type metric struct {
+	name            string
+	kind            metricKind
+	temporality     temporality
+	monotonic       bool
+	numberPoints    []numberDataPoint
+	histogramPoints []histogramDataPoint
+}
+
+type numberDataPoint struct {
+	attributes []keyValue
+	startTime  uint64
+	time       uint64
+	value      float64
+}
+
+type histogramDataPoint struct {
+	attributes     []keyValue
+	startTime      uint64
+	time           uint64
+	count          uint64
+	sum            float64
+	hasSum         bool
+	bucketCounts   []uint64
+	explicitBounds []float64
+}
+
+var errTruncated = errors.New("truncated message")
+
+// wireReader reads the fields of a protobuf message
+type wireReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+// next reads the key of the next field, returning false at the end of the message or on error
+func (r *wireReader) next() (int, int, bool) {
+	if r.err != nil || r.pos >= len(r.buf) {
+		return 0, 0, false
+	}
+	key := r.varint()
+	if r.err != nil {
+		return 0, 0, false
+	}
+	return int(key >> 3), int(key & 7), true
+}
+
+func (r *wireReader) varint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.err = errTruncated
+		return 0
+	}
+	r.pos += n
+	return v
+}
+
+func (r *wireReader) fixed64() uint64 {
+	if len(r.buf)-r.pos < 8 {
+		r.err = errTruncated
+		return 0
+	}
+	v := binary.LittleEndian.Uint64(r.buf[r.pos:])
+	r.pos += 8
+	return v
+}
+
+func (r *wireReader) double() float64 {
+	return math.Float64frombits(r.fixed64())
+}
+
+func (r *wireReader) bytes() []byte {
+	l := r.varint()
+	if r.err != nil {
+		return nil
+	}
+	if uint64(len(r.buf)-r.pos) < l {
+		r.err = errTruncated
+		return nil
+	}
+	b := r.buf[r.pos : r.pos+int(l)]
+	r.pos += int(l)
+	return b
+}
+
+func (r *wireReader) skip(wireType int) {
+	switch wireType {
+	case wireVarint:
+		r.varint()
+	case wireFixed64:
+		r.fixed64()
+	case wireBytes:
+		r.bytes()
+	case wireFixed32:
+		if len(r.buf)-r.pos < 4 {
+			r.err = errTruncated
+			return
+		}
+		r.pos += 4
+	default:
+		r.err = fmt.Errorf("unsupported wire type %d", wireType)
+	}
+}
+
+// expect checks the wire type of a known field
+func (r *wireReader) expect(wireType, expected int) bool {
+	if wireType != expected {
+		r.err = fmt.Errorf("unexpected wire type %d, expected %d", wireType, expected)
+		return false
+	}
+	return true
+}
+
+// fixed64s reads a repeated fixed64 field, packed or not
+func (r *wireReader) fixed64s(wireType int, values []uint64) []uint64 {
+	switch wireType {
+	case wireFixed64:
+		return append(values, r.fixed64())
+	case wireBytes:
+		packed := &wireReader{buf: r.bytes()}
+		for r.err == nil && packed.err == nil && packed.pos < len(packed.buf) {
+			values = append(values, packed.fixed64())
+		}
+		if packed.err != nil {
+			r.err = packed.err
+		}
+		return values
+	default:
+		r.expect(wireType, wireFixed64)
+		return values
+	}
+}
+
+// decodeExportRequest decodes an ExportMetricsServiceRequest
+func decodeExportRequest(buf []byte) (*exportRequest, error) {
+	req := &exportRequest{}
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		if field == 1 && r.expect(wireType, wireBytes) {
+			req.resourceMetrics = append(req.resourceMetrics, decodeResourceMetrics(r, r.bytes()))
+			continue
+		}
+		r.skip(wireType)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("could not decode the OTLP metrics request: %s", r.err)
+	}
+	return req, nil
+}
+
+// decodeResourceMetrics decodes a ResourceMetrics, flattening the metrics of its scopes
+func decodeResourceMetrics(parent *wireReader, buf []byte) resourceMetrics {
+	var rm resourceMetrics
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes): // resource
+			rm.attributes = decodeResource(r, r.bytes())
+		case field == 2 && r.expect(wireType, wireBytes): // scope_metrics
+			rm.metrics = decodeScopeMetrics(r, r.bytes(), rm.metrics)
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return rm
+}
+
+func decodeResource(parent *wireReader, buf []byte) []keyValue {
+	var attributes []keyValue
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		if field == 1 && r.expect(wireType, wireBytes) {
+			attributes = appendKeyValue(r, r.bytes(), attributes)
+			continue
+		}
+		r.skip(wireType)
+	}
+	propagate(parent, r)
+	return attributes
+}
+
+func decodeScopeMetrics(parent *wireReader, buf []byte, metrics []metric) []metric {
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		if field == 2 && r.expect(wireType, wireBytes) {
+			metrics = append(metrics, decodeMetric(r, r.bytes()))
+			continue
+		}
+		r.skip(wireType)
+	}
+	propagate(parent, r)
+	return metrics
+}
+
+func decodeMetric(parent *wireReader, buf []byte) metric {
+	var m metric
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes):
+			m.name = string(r.bytes())
+		case field == 5 && r.expect(wireType, wireBytes): // gauge
+			m.kind = kindGauge
+			decodeNumberData(r, r.bytes(), &m)
+		case field == 7 && r.expect(wireType, wireBytes): // sum
+			m.kind = kindSum
+			decodeNumberData(r, r.bytes(), &m)
+		case field == 9 && r.expect(wireType, wireBytes): // histogram
+			m.kind = kindHistogram
+			decodeHistogramData(r, r.bytes(), &m)
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return m
+}
+
+// decodeNumberData decodes a Gauge or a Sum, the gauges having no temporality
+func decodeNumberData(parent *wireReader, buf []byte, m *metric) {
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes):
+			m.numberPoints = append(m.numberPoints, decodeNumberDataPoint(r, r.bytes()))
+		case field == 2 && r.expect(wireType, wireVarint):
+			m.temporality = temporality(r.varint())
+		case field == 3 && r.expect(wireType, wireVarint):
+			m.monotonic = r.varint() != 0
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+}
+
+func decodeHistogramData(parent *wireReader, buf []byte, m *metric) {
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes):
+			m.histogramPoints = append(m.histogramPoints, decodeHistogramDataPoint(r, r.bytes()))
+		case field == 2 && r.expect(wireType, wireVarint):
+			m.temporality = temporality(r.varint())
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+}
+
+func decodeNumberDataPoint(parent *wireReader, buf []byte) numberDataPoint {
+	var p numberDataPoint
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 2 && r.expect(wireType, wireFixed64):
+			p.startTime = r.fixed64()
+		case field == 3 && r.expect(wireType, wireFixed64):
+			p.time = r.fixed64()
+		case field == 4 && r.expect(wireType, wireFixed64): // as_double
+			p.value = r.double()
+		case field == 6 && r.expect(wireType, wireFixed64): // as_int, an sfixed64
+			p.value = float64(int64(r.fixed64()))
+		case field == 7 && r.expect(wireType, wireBytes):
+			p.attributes = appendKeyValue(r, r.bytes(), p.attributes)
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return p
+}
+
+func decodeHistogramDataPoint(parent *wireReader, buf []byte) histogramDataPoint {
+	var p histogramDataPoint
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 2 && r.expect(wireType, wireFixed64):
+			p.startTime = r.fixed64()
+		case field == 3 && r.expect(wireType, wireFixed64):
+			p.time = r.fixed64()
+		case field == 4 && r.expect(wireType, wireFixed64):
+			p.count = r.fixed64()
+		case field == 5 && r.expect(wireType, wireFixed64):
+			p.sum = r.double()
+			p.hasSum = true
+		case field == 6:
+			p.bucketCounts = r.fixed64s(wireType, p.bucketCounts)
+		case field == 7:
+			for _, b := range r.fixed64s(wireType, nil) {
+				p.explicitBounds = append(p.explicitBounds, math.Float64frombits(b))
+			}
+		case field == 9 && r.expect(wireType, wireBytes):
+			p.attributes = appendKeyValue(r, r.bytes(), p.attributes)
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return p
+}
+
+// appendKeyValue decodes a KeyValue, dropping the attributes which don't have a scalar value
+func appendKeyValue(parent *wireReader, buf []byte, attributes []keyValue) []keyValue {
+	var kv keyValue
+	var hasValue bool
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes):
+			kv.key = string(r.bytes())
+		case field == 2 && r.expect(wireType, wireBytes):
+			kv.value, hasValue = decodeAnyValue(r, r.bytes())
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	if !hasValue || kv.key == "" {
+		return attributes
+	}
+	return append(attributes, kv)
+}
+
+// decodeAnyValue renders the scalar values as strings, the arrays, maps and bytes aren't supported
+func decodeAnyValue(parent *wireReader, buf []byte) (string, bool) {
+	var value string
+	var ok bool
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, more := r.next()
+		if !more {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes): // string_value
+			value, ok = string(r.bytes()), true
+		case field == 2 && r.expect(wireType, wireVarint): // bool_value
+			value, ok = strconv.FormatBool(r.varint() != 0), true
+		case field == 3 && r.expect(wireType, wireVarint): // int_value
+			value, ok = strconv.FormatInt(int64(r.varint()), 10), true
+		case field == 4 && r.expect(wireType, wireFixed64): // double_value
+			value, ok = strconv.FormatFloat(r.double(), 'g', -1, 64), true
+		default:
+			value, ok = "", false
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return value, ok
+}
+
+// propagate reports the error of a nested message to its parent
+func propagate(parent, r *wireReader) {
+	if r.err != nil && parent.err == nil {
+		parent.err = r.err
+	}
+}
@@ -0,0 +1,124 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import "fmt"
+
+// logsExportRequest is an opentelemetry.proto.collector.logs.v1.ExportLogsServiceRequest
+type logsExportRequest struct {
+	resourceLogs []resourceLogs
+}
+
+// resourceLogs holds the log records of all the instrumentation scopes of a resource
+type resourceLogs struct {
+	attributes []keyValue
+	records    []logRecord
+}
+
+type logRecord struct {
+	time           uint64
+	observedTime   uint64
+	severityNumber uint64
+	severityText   string
+	body           string
+	attributes     []keyValue
+	traceID        []byte
+	spanID         []byte
+}
+
+// decodeLogsExportRequest decodes an ExportLogsServiceRequest
+func decodeLogsExportRequest(buf []byte) (*logsExportRequest, error) {
+	req := &logsExportRequest{}
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		if field == 1 && r.expect(wireType, wireBytes) {
+			req.resourceLogs = append(req.resourceLogs, decodeResourceLogs(r, r.bytes()))
+			continue
+		}
+		r.skip(wireType)
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("could not decode the OTLP logs request: %s", r.err)
+	}
+	return req, nil
+}
+
+// decodeResourceLogs decodes a ResourceLogs, flattening the log records of its scopes
+func decodeResourceLogs(parent *wireReader, buf []byte) resourceLogs {
+	var rl resourceLogs
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireBytes): // resource
+			rl.attributes = decodeResource(r, r.bytes())
+		case field == 2 && r.expect(wireType, wireBytes): // scope_logs
+			rl.records = decodeScopeLogs(r, r.bytes(), rl.records)
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return rl
+}
+
+func decodeScopeLogs(parent *wireReader, buf []byte, records []logRecord) []logRecord {
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		if field == 2 && r.expect(wireType, wireBytes) {
+			records = append(records, decodeLogRecord(r, r.bytes()))
+			continue
+		}
+		r.skip(wireType)
+	}
+	propagate(parent, r)
+	return records
+}
+
+// decodeLogRecord decodes a LogRecord, whose body is only kept when it's a scalar value
+func decodeLogRecord(parent *wireReader, buf []byte) logRecord {
+	var l logRecord
+	r := &wireReader{buf: buf}
+	for {
+		field, wireType, ok := r.next()
+		if !ok {
+			break
+		}
+		switch {
+		case field == 1 && r.expect(wireType, wireFixed64):
+			l.time = r.fixed64()
+		case field == 2 && r.expect(wireType, wireVarint):
+			l.severityNumber = r.varint()
+		case field == 3 && r.expect(wireType, wireBytes):
+			l.severityText = string(r.bytes())
+		case field == 5 && r.expect(wireType, wireBytes):
+			l.body, _ = decodeAnyValue(r, r.bytes())
+		case field == 6 && r.expect(wireType, wireBytes):
+			l.attributes = appendKeyValue(r, r.bytes(), l.attributes)
+		case field == 9 && r.expect(wireType, wireBytes):
+			l.traceID = r.bytes()
+		case field == 10 && r.expect(wireType, wireBytes):
+			l.spanID = r.bytes()
+		case field == 11 && r.expect(wireType, wireFixed64):
+			l.observedTime = r.fixed64()
+		default:
+			r.skip(wireType)
+		}
+	}
+	propagate(parent, r)
+	return l
+}
@@ -0,0 +1,211 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pbMessage encodes the protobuf messages of the tests
+type pbMessage struct {
+	buf []byte
+}
+
+func (m *pbMessage) appendVarint(v uint64) {
+	var b [binary.MaxVarintLen64]byte
+	m.buf = append(m.buf, b[:binary.PutUvarint(b[:], v)]...)
+}
+
+func (m *pbMessage) appendFixed64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	m.buf = append(m.buf, b[:]...)
+}
+
+func (m *pbMessage) key(field, wireType int) {
+	m.appendVarint(uint64(field<<3 | wireType))
+}
+
+func (m *pbMessage) varint(field int, v uint64) *pbMessage {
+	m.key(field, wireVarint)
+	m.appendVarint(v)
+	return m
+}
+
+func (m *pbMessage) fixed64(field int, v uint64) *pbMessage {
+	m.key(field, wireFixed64)
+	m.appendFixed64(v)
+	return m
+}
+
+func (m *pbMessage) double(field int, v float64) *pbMessage {
+	return m.fixed64(field, math.Float64bits(v))
+}
+
+func (m *pbMessage) bytes(field int, v []byte) *pbMessage {
+	m.key(field, wireBytes)
+	m.appendVarint(uint64(len(v)))
+	m.buf = append(m.buf, v...)
+	return m
+}
+
+func (m *pbMessage) string(field int, v string) *pbMessage {
+	return m.bytes(field, []byte(v))
+}
+
+func (m *pbMessage) message(field int, v *pbMessage) *pbMessage {
+	return m.bytes(field, v.buf)
+}
+
+func (m *pbMessage) packedFixed64(field int, values ...uint64) *pbMessage {
+	packed := &pbMessage{}
+	for _, v := range values {
+		packed.appendFixed64(v)
+	}
+	return m.bytes(field, packed.buf)
+}
+
+func stringAttribute(key, value string) *pbMessage {
+	return (&pbMessage{}).string(1, key).message(2, (&pbMessage{}).string(1, value))
+}
+
+func intAttribute(key string, value int64) *pbMessage {
+	return (&pbMessage{}).string(1, key).message(2, (&pbMessage{}).varint(3, uint64(value)))
+}
+
+func numberPoint(startTime, time uint64, value float64, attributes ...*pbMessage) *pbMessage {
+	p := (&pbMessage{}).fixed64(2, startTime).fixed64(3, time).double(4, value)
+	for _, a := range attributes {
+		p.message(7, a)
+	}
+	return p
+}
+
+func gaugeMetric(name string, points ...*pbMessage) *pbMessage {
+	gauge := &pbMessage{}
+	for _, p := range points {
+		gauge.message(1, p)
+	}
+	return (&pbMessage{}).string(1, name).string(3, "By").message(5, gauge)
+}
+
+func sumMetric(name string, temporality temporality, monotonic bool, points ...*pbMessage) *pbMessage {
+	sum := &pbMessage{}
+	for _, p := range points {
+		sum.message(1, p)
+	}
+	sum.varint(2, uint64(temporality))
+	if monotonic {
+		sum.varint(3, 1)
+	}
+	return (&pbMessage{}).string(1, name).message(7, sum)
+}
+
+func histogramMetric(name string, temporality temporality, points ...*pbMessage) *pbMessage {
+	histogram := &pbMessage{}
+	for _, p := range points {
+		histogram.message(1, p)
+	}
+	histogram.varint(2, uint64(temporality))
+	return (&pbMessage{}).string(1, name).message(9, histogram)
+}
+
+func histogramPoint(startTime, time uint64, count uint64, sum float64, bounds []float64, buckets []uint64) *pbMessage {
+	p := (&pbMessage{}).fixed64(2, startTime).fixed64(3, time).fixed64(4, count).double(5, sum).packedFixed64(6, buckets...)
+	var b []uint64
+	for _, bound := range bounds {
+		b = append(b, math.Float64bits(bound))
+	}
+	return p.packedFixed64(7, b...)
+}
+
+func exportMessage(resourceAttributes []*pbMessage, metrics ...*pbMessage) *pbMessage {
+	resource := &pbMessage{}
+	for _, a := range resourceAttributes {
+		resource.message(1, a)
+	}
+	scope := (&pbMessage{}).message(1, (&pbMessage{}).string(1, "io.opentelemetry.test"))
+	for _, m := range metrics {
+		scope.message(2, m)
+	}
+	rm := (&pbMessage{}).message(1, resource).message(2, scope).string(3, "https://opentelemetry.io/schemas/1.6.1")
+	return (&pbMessage{}).message(1, rm)
+}
+
+func TestDecodeExportRequest(t *testing.T) {
+	req := exportMessage(
+		[]*pbMessage{stringAttribute("host.name", "web-1"), intAttribute("process.pid", 1234)},
+		gaugeMetric("queue.size", numberPoint(0, 2000, 12.5, stringAttribute("queue", "jobs"))),
+		sumMetric("requests", temporalityCumulative, true, numberPoint(1000, 2000, 42)),
+		histogramMetric("latency", temporalityDelta, histogramPoint(1000, 2000, 6, 1.5, []float64{0.1, 1}, []uint64{3, 2, 1})),
+		(&pbMessage{}).string(1, "summary").message(11, &pbMessage{}),
+	)
+
+	decoded, err := decodeExportRequest(req.buf)
+	require.NoError(t, err)
+	require.Len(t, decoded.resourceMetrics, 1)
+
+	rm := decoded.resourceMetrics[0]
+	assert.Equal(t, []keyValue{{"host.name", "web-1"}, {"process.pid", "1234"}}, rm.attributes)
+	require.Len(t, rm.metrics, 4)
+
+	assert.Equal(t, metric{
+		name:         "queue.size",
+		kind:         kindGauge,
+		numberPoints: []numberDataPoint{{attributes: []keyValue{{"queue", "jobs"}}, time: 2000, value: 12.5}},
+	}, rm.metrics[0])
+	assert.Equal(t, metric{
+		name:         "requests",
+		kind:         kindSum,
+		temporality:  temporalityCumulative,
+		monotonic:    true,
+		numberPoints: []numberDataPoint{{startTime: 1000, time: 2000, value: 42}},
+	}, rm.metrics[1])
+	assert.Equal(t, metric{
+		name:        "latency",
+		kind:        kindHistogram,
+		temporality: temporalityDelta,
+		histogramPoints: []histogramDataPoint{{
+			startTime:      1000,
+			time:           2000,
+			count:          6,
+			sum:            1.5,
+			hasSum:         true,
+			bucketCounts:   []uint64{3, 2, 1},
+			explicitBounds: []float64{0.1, 1},
+		}},
+	}, rm.metrics[2])
+	assert.Equal(t, metric{name: "summary", kind: kindUnsupported}, rm.metrics[3])
+}
+
+func TestDecodeIntValue(t *testing.T) {
+	value := int64(-5)
+	point := (&pbMessage{}).fixed64(3, 2000).fixed64(6, uint64(value))
+	decoded, err := decodeExportRequest(exportMessage(nil, gaugeMetric("temperature", point)).buf)
+	require.NoError(t, err)
+	assert.Equal(t, -5.0, decoded.resourceMetrics[0].metrics[0].numberPoints[0].value)
+}
+
+func TestDecodeInvalidRequest(t *testing.T) {
+	req := exportMessage(nil, gaugeMetric("queue.size", numberPoint(0, 2000, 12.5)))
+
+	_, err := decodeExportRequest(req.buf[:len(req.buf)-3])
+	assert.Error(t, err)
+
+	// the resource_metrics must be a message
+	_, err = decodeExportRequest((&pbMessage{}).varint(1, 3).buf)
+	assert.Error(t, err)
+
+	// the unknown fields are skipped
+	decoded, err := decodeExportRequest((&pbMessage{}).varint(42, 3).fixed64(43, 1).buf)
+	assert.NoError(t, err)
+	assert.Empty(t, decoded.resourceMetrics)
+}
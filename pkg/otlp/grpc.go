@@ -0,0 +1,167 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The gRPC service is declared by hand, the messages implementing the proto.Message and
+// proto.Unmarshaler interfaces the protobuf codec of gRPC relies on.
+
+// metricsServiceServer is the opentelemetry.proto.collector.metrics.v1.MetricsService
+type metricsServiceServer interface {
+	Export(context.Context, *exportRequest) (*exportResponse, error)
+}
+
+var metricsServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.metrics.v1.MetricsService",
+	HandlerType: (*metricsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    metricsExportHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/metrics/v1/metrics_service.proto",
+}
+
+func metricsExportHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	tlmRequests.Inc("grpc", "metrics")
+	in := new(exportRequest)
+	if err := dec(in); err != nil {
+		tlmRequestErrors.Inc("grpc", "metrics", "decoding")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if interceptor == nil {
+		return srv.(metricsServiceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(metricsServiceServer).Export(ctx, req.(*exportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// metricsService implements the MetricsService for the server
+type metricsService struct {
+	server *Server
+}
+
+func (m *metricsService) Export(_ context.Context, req *exportRequest) (*exportResponse, error) {
+	m.server.export(req)
+	return &exportResponse{}, nil
+}
+
+// logsServiceServer is the opentelemetry.proto.collector.logs.v1.LogsService
+type logsServiceServer interface {
+	Export(context.Context, *logsExportRequest) (*exportResponse, error)
+}
+
+var logsServiceDesc = grpc.ServiceDesc{
+	ServiceName: "opentelemetry.proto.collector.logs.v1.LogsService",
+	HandlerType: (*logsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Export",
+			Handler:    logsExportHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "opentelemetry/proto/collector/logs/v1/logs_service.proto",
+}
+
+func logsExportHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	tlmRequests.Inc("grpc", "logs")
+	in := new(logsExportRequest)
+	if err := dec(in); err != nil {
+		tlmRequestErrors.Inc("grpc", "logs", "decoding")
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if interceptor == nil {
+		return srv.(logsServiceServer).Export(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/opentelemetry.proto.collector.logs.v1.LogsService/Export",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(logsServiceServer).Export(ctx, req.(*logsExportRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// logsService implements the LogsService for the server
+type logsService struct {
+	server *Server
+}
+
+func (l *logsService) Export(_ context.Context, req *logsExportRequest) (*exportResponse, error) {
+	l.server.exportLogs(req)
+	return &exportResponse{}, nil
+}
+
+// Reset implements proto.Message
+func (r *exportRequest) Reset() { *r = exportRequest{} }
+
+// String implements proto.Message
+func (r *exportRequest) String() string { return "ExportMetricsServiceRequest" }
+
+// ProtoMessage implements proto.Message
+func (*exportRequest) ProtoMessage() {}
+
+// Unmarshal implements proto.Unmarshaler
+func (r *exportRequest) Unmarshal(buf []byte) error {
+	req, err := decodeExportRequest(buf)
+	if err != nil {
+		return err
+	}
+	*r = *req
+	return nil
+}
+
+// Reset implements proto.Message
+func (r *logsExportRequest) Reset() { *r = logsExportRequest{} }
+
+// String implements proto.Message
+func (r *logsExportRequest) String() string { return "ExportLogsServiceRequest" }
+
+// ProtoMessage implements proto.Message
+func (*logsExportRequest) ProtoMessage() {}
+
+// Unmarshal implements proto.Unmarshaler
+func (r *logsExportRequest) Unmarshal(buf []byte) error {
+	req, err := decodeLogsExportRequest(buf)
+	if err != nil {
+		return err
+	}
+	*r = *req
+	return nil
+}
+
+// exportResponse is an empty ExportMetricsServiceResponse or ExportLogsServiceResponse,
+// all the data being accepted
+type exportResponse struct{}
+
+// Reset implements proto.Message
+func (*exportResponse) Reset() {}
+
+// String implements proto.Message
+func (*exportResponse) String() string { return "ExportServiceResponse" }
+
+// ProtoMessage implements proto.Message
+func (*exportResponse) ProtoMessage() {}
+
+// Marshal implements proto.Marshaler
+func (*exportResponse) Marshal() ([]byte, error) { return nil, nil }
@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+
+	logsConfig "github.com/DataDog/datadog-agent/pkg/logs/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/tagger"
+	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/util/containers"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	logsSourceName       = "otlp"
+	containerIDAttribute = "container.id"
+	serviceAttribute     = "service.name"
+)
+
+// Attributes added to the messages of the OTLP log records
+const (
+	logTimestamp      = "otel.timestamp"
+	logSeverityText   = "otel.severity_text"
+	logSeverityNumber = "otel.severity_number"
+	logTraceID        = "otel.trace_id"
+	logSpanID         = "otel.span_id"
+	// the trace and span IDs of the Datadog tracers are the lower 64 bits of the OpenTelemetry IDs
+	logDDTraceID = "dd.trace_id"
+	logDDSpanID  = "dd.span_id"
+)
+
+// severityStatuses maps the ranges of 4 severity numbers, TRACE to FATAL, to the message statuses
+var severityStatuses = []string{
+	message.StatusDebug,
+	message.StatusDebug,
+	message.StatusInfo,
+	message.StatusWarning,
+	message.StatusError,
+	message.StatusCritical,
+}
+
+// severityTexts maps the usual severity texts to the message statuses, when there's no severity number
+var severityTexts = map[string]string{
+	"trace":    message.StatusDebug,
+	"debug":    message.StatusDebug,
+	"info":     message.StatusInfo,
+	"warn":     message.StatusWarning,
+	"warning":  message.StatusWarning,
+	"error":    message.StatusError,
+	"fatal":    message.StatusCritical,
+	"critical": message.StatusCritical,
+}
+
+// containerTags is overridden by the tests
+var containerTags = func(containerID string) []string {
+	tags, err := tagger.Tag(containers.BuildTaggerEntityName(containerID), collectors.HighCardinality)
+	if err != nil {
+		log.Debugf("Could not get the tags of the container %s: %s", containerID, err)
+	}
+	return tags
+}
+
+// logsTranslator converts the OTLP log records to messages of the logs pipeline
+type logsTranslator struct {
+	source *logsConfig.LogSource
+}
+
+func newLogsTranslator() *logsTranslator {
+	return &logsTranslator{
+		source: logsConfig.NewLogSource(logsSourceName, &logsConfig.LogsConfig{Type: logsSourceName}),
+	}
+}
+
+// translate converts the log records of a request, the attributes of their resource being the tags
+// of the messages, with the tags of their container
+func (t *logsTranslator) translate(req *logsExportRequest) []*message.Message {
+	var messages []*message.Message
+	for _, rl := range req.resourceLogs {
+		var tags []string
+		var service, containerID string
+		for _, kv := range rl.attributes {
+			switch kv.key {
+			case serviceAttribute:
+				service = kv.value
+			case containerIDAttribute:
+				containerID = kv.value
+			}
+			tags = appendTag(tags, kv)
+		}
+		if containerID != "" {
+			tags = append(tags, containerTags(containerID)...)
+		}
+
+		for _, record := range rl.records {
+			origin := message.NewOrigin(t.source)
+			origin.SetSource(logsSourceName)
+			origin.SetService(service)
+			origin.SetTags(tags)
+			msg := message.NewMessage([]byte(record.body), origin, recordStatus(record))
+			for _, kv := range record.attributes {
+				msg.SetAttribute(kv.key, kv.value)
+			}
+			setRecordAttributes(msg, record)
+			messages = append(messages, msg)
+		}
+	}
+	return messages
+}
+
+// recordStatus returns the status of a record from its severity number, or its severity text
+func recordStatus(record logRecord) string {
+	if record.severityNumber > 0 && record.severityNumber <= uint64(4*len(severityStatuses)) {
+		return severityStatuses[(record.severityNumber-1)/4]
+	}
+	if status, found := severityTexts[strings.ToLower(record.severityText)]; found {
+		return status
+	}
+	return message.StatusInfo
+}
+
+// setRecordAttributes adds the time, the severity and the trace correlation IDs of a record to its message
+func setRecordAttributes(msg *message.Message, record logRecord) {
+	recordTime := record.time
+	if recordTime == 0 {
+		recordTime = record.observedTime
+	}
+	if recordTime > 0 {
+		msg.SetAttribute(logTimestamp, time.Unix(0, int64(recordTime)).UTC().Format(time.RFC3339Nano))
+	}
+	if record.severityText != "" {
+		msg.SetAttribute(logSeverityText, record.severityText)
+	}
+	if record.severityNumber > 0 {
+		msg.SetAttribute(logSeverityNumber, strconv.FormatUint(record.severityNumber, 10))
+	}
+	if len(record.traceID) == 16 && !isZero(record.traceID) {
+		msg.SetAttribute(logTraceID, hex.EncodeToString(record.traceID))
+		msg.SetAttribute(logDDTraceID, strconv.FormatUint(binary.BigEndian.Uint64(record.traceID[8:]), 10))
+	}
+	if len(record.spanID) == 8 && !isZero(record.spanID) {
+		msg.SetAttribute(logSpanID, hex.EncodeToString(record.spanID))
+		msg.SetAttribute(logDDSpanID, strconv.FormatUint(binary.BigEndian.Uint64(record.spanID), 10))
+	}
+}
+
+// isZero returns whether an ID is the invalid all zeroes ID
+func isZero(id []byte) bool {
+	for _, b := range id {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+)
+
+type mockPipelineProvider struct {
+	messages chan *message.Message
+}
+
+func (p *mockPipelineProvider) Start()                                  {}
+func (p *mockPipelineProvider) Stop()                                   {}
+func (p *mockPipelineProvider) NextPipelineChan() chan *message.Message { return p.messages }
+
+func logRecordMessage(severityNumber uint64, severityText, body string, attributes ...*pbMessage) *pbMessage {
+	r := (&pbMessage{}).fixed64(1, 1500000000123456789).varint(2, severityNumber).string(3, severityText)
+	r.message(5, (&pbMessage{}).string(1, body))
+	for _, a := range attributes {
+		r.message(6, a)
+	}
+	return r
+}
+
+func logsExportMessage(resourceAttributes []*pbMessage, records ...*pbMessage) *pbMessage {
+	resource := &pbMessage{}
+	for _, a := range resourceAttributes {
+		resource.message(1, a)
+	}
+	scope := (&pbMessage{}).message(1, (&pbMessage{}).string(1, "io.opentelemetry.test"))
+	for _, r := range records {
+		scope.message(2, r)
+	}
+	return (&pbMessage{}).message(1, (&pbMessage{}).message(1, resource).message(2, scope))
+}
+
+func TestDecodeLogsExportRequest(t *testing.T) {
+	traceID := []byte{0, 1, 2, 3, 4, 5, 6, 7, 0, 0, 0, 0, 0, 0, 1, 0}
+	spanID := []byte{0, 0, 0, 0, 0, 0, 0, 42}
+	record := logRecordMessage(17, "ERROR", "payment failed", intAttribute("order", 12)).bytes(9, traceID).bytes(10, spanID)
+
+	decoded, err := decodeLogsExportRequest(logsExportMessage([]*pbMessage{stringAttribute("service.name", "checkout")}, record).buf)
+	require.NoError(t, err)
+	require.Len(t, decoded.resourceLogs, 1)
+	assert.Equal(t, []keyValue{{"service.name", "checkout"}}, decoded.resourceLogs[0].attributes)
+	assert.Equal(t, []logRecord{{
+		time:           1500000000123456789,
+		severityNumber: 17,
+		severityText:   "ERROR",
+		body:           "payment failed",
+		attributes:     []keyValue{{"order", "12"}},
+		traceID:        traceID,
+		spanID:         spanID,
+	}}, decoded.resourceLogs[0].records)
+
+	_, err = decodeLogsExportRequest(record.buf[:5])
+	assert.Error(t, err)
+}
+
+func TestTranslateLogs(t *testing.T) {
+	defer func(original func(string) []string) { containerTags = original }(containerTags)
+	containerTags = func(containerID string) []string {
+		return []string{"container_id:" + containerID, "image_name:checkout"}
+	}
+
+	traceID := []byte{0, 1, 2, 3, 4, 5, 6, 7, 0, 0, 0, 0, 0, 0, 1, 0}
+	spanID := []byte{0, 0, 0, 0, 0, 0, 0, 42}
+	req := logsExportMessage(
+		[]*pbMessage{stringAttribute("service.name", "checkout"), stringAttribute("container.id", "abcdef")},
+		logRecordMessage(17, "ERROR", "payment failed", intAttribute("order", 12)).bytes(9, traceID).bytes(10, spanID),
+		logRecordMessage(0, "Warning", "slow payment"),
+		logRecordMessage(0, "", "started"),
+	)
+	decoded, err := decodeLogsExportRequest(req.buf)
+	require.NoError(t, err)
+
+	messages := newLogsTranslator().translate(decoded)
+	require.Len(t, messages, 3)
+
+	msg := messages[0]
+	assert.Equal(t, "payment failed", string(msg.Content))
+	assert.Equal(t, message.StatusError, msg.GetStatus())
+	assert.Equal(t, "checkout", msg.Origin.Service())
+	assert.Equal(t, "otlp", msg.Origin.Source())
+	assert.Equal(t, []string{"service:checkout", "container.id:abcdef", "container_id:abcdef", "image_name:checkout"}, msg.Origin.Tags())
+	assert.Equal(t, map[string]string{
+		"order":                "12",
+		"otel.timestamp":       "2017-07-14T02:40:00.123456789Z",
+		"otel.severity_text":   "ERROR",
+		"otel.severity_number": "17",
+		"otel.trace_id":        "00010203040506070000000000000100",
+		"otel.span_id":         "000000000000002a",
+		"dd.trace_id":          "256",
+		"dd.span_id":           "42",
+	}, msg.Attributes)
+
+	assert.Equal(t, message.StatusWarning, messages[1].GetStatus())
+	assert.Equal(t, message.StatusInfo, messages[2].GetStatus())
+}
+
+func TestRecordStatus(t *testing.T) {
+	for severityNumber, status := range map[uint64]string{
+		1:  message.StatusDebug,
+		8:  message.StatusDebug,
+		9:  message.StatusInfo,
+		13: message.StatusWarning,
+		20: message.StatusError,
+		24: message.StatusCritical,
+		25: message.StatusInfo,
+	} {
+		assert.Equal(t, status, recordStatus(logRecord{severityNumber: severityNumber}), "severity %d", severityNumber)
+	}
+}
+
+func TestHandleLogs(t *testing.T) {
+	provider := &mockPipelineProvider{messages: make(chan *message.Message, 10)}
+	s := &Server{logsTranslator: newLogsTranslator(), logsPipeline: provider}
+	req := logsExportMessage(nil, logRecordMessage(9, "INFO", "started"), logRecordMessage(9, "INFO", "ready"))
+
+	r := httptest.NewRequest(http.MethodPost, logsPath, bytes.NewReader(req.buf))
+	r.Header.Set("Content-Type", "application/x-protobuf")
+	w := httptest.NewRecorder()
+	s.handleLogs(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, provider.messages, 2)
+	assert.Equal(t, "started", string((<-provider.messages).Content))
+	assert.Equal(t, "ready", string((<-provider.messages).Content))
+
+	resp, err := logsExportHandler(&logsService{server: s}, context.Background(), func(in interface{}) error {
+		return in.(*logsExportRequest).Unmarshal(req.buf)
+	}, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &exportResponse{}, resp)
+	assert.Len(t, provider.messages, 2)
+}
@@ -0,0 +1,236 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"github.com/DataDog/datadog-agent/pkg/aggregator"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/logs/pipeline"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+const (
+	metricsPath         = "/v1/metrics"
+	logsPath            = "/v1/logs"
+	protobufContentType = "application/x-protobuf"
+
+	// maxRequestSize is the maximum size of a decompressed request, the default of the gRPC servers
+	maxRequestSize = 4 << 20
+
+	stopTimeout = 5 * time.Second
+)
+
+// Server receives the metrics and the logs of the OpenTelemetry SDKs with OTLP over gRPC and HTTP,
+// and sends the metrics to the aggregator like the DogStatsD metrics and the logs to the pipelines
+// of the logs-agent
+type Server struct {
+	translator *translator
+	samplesOut chan<- []metrics.MetricSample
+	samplePool *metrics.MetricSamplePool
+
+	logsTranslator *logsTranslator
+	logsPipeline   pipeline.Provider
+
+	grpcServer *grpc.Server
+	httpServer *http.Server
+}
+
+// IsEnabled returns whether the OTLP receiver is enabled for the metrics or the logs
+func IsEnabled() bool {
+	return config.Datadog.GetBool("otlp_config.metrics.enabled") || config.Datadog.GetBool("otlp_config.logs.enabled")
+}
+
+// NewServer starts the gRPC and HTTP receivers whose endpoint is configured, the logs are only
+// received when the logs-agent is running
+func NewServer(agg *aggregator.BufferedAggregator, logsPipeline pipeline.Provider, hostname string) (*Server, error) {
+	s := &Server{}
+	if config.Datadog.GetBool("otlp_config.metrics.enabled") {
+		s.translator = newTranslator(hostname)
+		s.samplesOut, _, _ = agg.GetBufferedChannels()
+		s.samplePool = agg.MetricSamplePool
+	}
+	if config.Datadog.GetBool("otlp_config.logs.enabled") {
+		if logsPipeline == nil {
+			log.Warn("The OTLP logs can't be received, the logs-agent isn't running")
+		} else {
+			s.logsTranslator = newLogsTranslator()
+			s.logsPipeline = logsPipeline
+		}
+	}
+
+	if endpoint := config.Datadog.GetString("otlp_config.receiver.protocols.grpc.endpoint"); endpoint != "" {
+		l, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("could not listen to the OTLP gRPC endpoint %s: %s", endpoint, err)
+		}
+		s.grpcServer = grpc.NewServer()
+		if s.translator != nil {
+			s.grpcServer.RegisterService(&metricsServiceDesc, &metricsService{server: s})
+		}
+		if s.logsTranslator != nil {
+			s.grpcServer.RegisterService(&logsServiceDesc, &logsService{server: s})
+		}
+		go func() {
+			if err := s.grpcServer.Serve(l); err != nil {
+				log.Errorf("Error while serving the OTLP gRPC endpoint: %s", err)
+			}
+		}()
+		log.Infof("Receiving OTLP with gRPC on %s", endpoint)
+	}
+
+	if endpoint := config.Datadog.GetString("otlp_config.receiver.protocols.http.endpoint"); endpoint != "" {
+		l, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			s.Stop()
+			return nil, fmt.Errorf("could not listen to the OTLP HTTP endpoint %s: %s", endpoint, err)
+		}
+		mux := http.NewServeMux()
+		if s.translator != nil {
+			mux.HandleFunc(metricsPath, s.handleMetrics)
+		}
+		if s.logsTranslator != nil {
+			mux.HandleFunc(logsPath, s.handleLogs)
+		}
+		s.httpServer = &http.Server{Handler: mux}
+		go func() {
+			if err := s.httpServer.Serve(l); err != nil && err != http.ErrServerClosed {
+				log.Errorf("Error while serving the OTLP HTTP endpoint: %s", err)
+			}
+		}()
+		log.Infof("Receiving OTLP with HTTP on %s", endpoint)
+	}
+	return s, nil
+}
+
+// Stop stops the receivers, the requests still in flight after stopTimeout are dropped
+func (s *Server) Stop() {
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+	if s.httpServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		if err := s.httpServer.Shutdown(ctx); err != nil {
+			log.Warnf("Could not stop the OTLP HTTP endpoint: %s", err)
+		}
+	}
+}
+
+// export translates the metrics of a request and sends them to the aggregator, in batches
+// of the size of the samples pool
+func (s *Server) export(req *exportRequest) {
+	samples := s.translator.translate(req)
+	tlmSamples.Add(float64(len(samples)))
+
+	for len(samples) > 0 {
+		batch := s.samplePool.GetBatch()
+		n := copy(batch, samples)
+		samples = samples[n:]
+		s.samplesOut <- batch[:n]
+	}
+}
+
+// exportLogs translates the log records of a request and sends them to the logs pipeline
+func (s *Server) exportLogs(req *logsExportRequest) {
+	messages := s.logsTranslator.translate(req)
+	tlmLogRecords.Add(float64(len(messages)))
+
+	pipelineChan := s.logsPipeline.NextPipelineChan()
+	for _, msg := range messages {
+		pipelineChan <- msg
+	}
+}
+
+// handleMetrics handles the OTLP/HTTP requests of the metrics, whose body is a protobuf ExportMetricsServiceRequest
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	payload, ok := readHTTPRequest(w, r, "metrics")
+	if !ok {
+		return
+	}
+	req, err := decodeExportRequest(payload)
+	if err != nil {
+		tlmRequestErrors.Inc("http", "metrics", "decoding")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.export(req)
+	writeHTTPResponse(w)
+}
+
+// handleLogs handles the OTLP/HTTP requests of the logs, whose body is a protobuf ExportLogsServiceRequest
+func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
+	payload, ok := readHTTPRequest(w, r, "logs")
+	if !ok {
+		return
+	}
+	req, err := decodeLogsExportRequest(payload)
+	if err != nil {
+		tlmRequestErrors.Inc("http", "logs", "decoding")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.exportLogs(req)
+	writeHTTPResponse(w)
+}
+
+// readHTTPRequest returns the decompressed body of an OTLP/HTTP request, replying with
+// the error when the request is invalid
+func readHTTPRequest(w http.ResponseWriter, r *http.Request, signal string) ([]byte, bool) {
+	tlmRequests.Inc("http", signal)
+	if r.Method != http.MethodPost {
+		tlmRequestErrors.Inc("http", signal, "method")
+		http.Error(w, "only POST is supported", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != protobufContentType {
+		tlmRequestErrors.Inc("http", signal, "content_type")
+		http.Error(w, "only "+protobufContentType+" is supported", http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	var body io.Reader = r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			tlmRequestErrors.Inc("http", signal, "decompression")
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		defer gz.Close()
+		body = gz
+	}
+	payload, err := ioutil.ReadAll(io.LimitReader(body, maxRequestSize+1))
+	if err != nil {
+		tlmRequestErrors.Inc("http", signal, "read")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	if len(payload) > maxRequestSize {
+		tlmRequestErrors.Inc("http", signal, "size")
+		http.Error(w, "request too large", http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+	return payload, true
+}
+
+// writeHTTPResponse replies with an empty export response, all the data being accepted
+func writeHTTPResponse(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", protobufContentType)
+	w.WriteHeader(http.StatusOK)
+}
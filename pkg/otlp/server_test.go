@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestHandleMetrics(t *testing.T) {
+	samplesOut := make(chan []metrics.MetricSample, 10)
+	s := &Server{
+		translator: newTranslator("agent-host"),
+		samplesOut: samplesOut,
+		samplePool: metrics.NewMetricSamplePool(2),
+	}
+	req := exportMessage(nil,
+		gaugeMetric("queue.size", numberPoint(0, 2e9, 12)),
+		sumMetric("requests", temporalityDelta, true, numberPoint(1e9, 2e9, 5)),
+		sumMetric("errors", temporalityDelta, true, numberPoint(1e9, 2e9, 1)),
+	)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(req.buf)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	for _, tc := range []struct {
+		name        string
+		method      string
+		contentType string
+		encoding    string
+		body        []byte
+		status      int
+		samples     int
+	}{
+		{"protobuf", http.MethodPost, "application/x-protobuf", "", req.buf, http.StatusOK, 3},
+		{"gzip", http.MethodPost, "application/x-protobuf", "gzip", compressed.Bytes(), http.StatusOK, 3},
+		{"json", http.MethodPost, "application/json", "", []byte("{}"), http.StatusUnsupportedMediaType, 0},
+		{"get", http.MethodGet, "application/x-protobuf", "", nil, http.StatusMethodNotAllowed, 0},
+		{"invalid", http.MethodPost, "application/x-protobuf", "", req.buf[:10], http.StatusBadRequest, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest(tc.method, metricsPath, bytes.NewReader(tc.body))
+			r.Header.Set("Content-Type", tc.contentType)
+			if tc.encoding != "" {
+				r.Header.Set("Content-Encoding", tc.encoding)
+			}
+			w := httptest.NewRecorder()
+			s.handleMetrics(w, r)
+			assert.Equal(t, tc.status, w.Code)
+
+			// the samples are sent in batches of the size of the pool
+			var samples []metrics.MetricSample
+			for len(samplesOut) > 0 {
+				batch := <-samplesOut
+				assert.True(t, len(batch) <= 2)
+				samples = append(samples, batch...)
+			}
+			assert.Len(t, samples, tc.samples)
+		})
+	}
+}
+
+func TestMetricsExportHandler(t *testing.T) {
+	samplesOut := make(chan []metrics.MetricSample, 10)
+	s := &Server{
+		translator: newTranslator("agent-host"),
+		samplesOut: samplesOut,
+		samplePool: metrics.NewMetricSamplePool(10),
+	}
+	req := exportMessage(nil, gaugeMetric("queue.size", numberPoint(0, 2e9, 12)))
+	dec := func(in interface{}) error {
+		return in.(*exportRequest).Unmarshal(req.buf)
+	}
+
+	resp, err := metricsExportHandler(&metricsService{server: s}, context.Background(), dec, nil)
+	require.NoError(t, err)
+	assert.IsType(t, &exportResponse{}, resp)
+	require.Len(t, samplesOut, 1)
+	assert.Equal(t, "queue.size", (<-samplesOut)[0].Name)
+
+	dec = func(in interface{}) error {
+		return in.(*exportRequest).Unmarshal(req.buf[:5])
+	}
+	_, err = metricsExportHandler(&metricsService{server: s}, context.Background(), dec, nil)
+	assert.Error(t, err)
+}
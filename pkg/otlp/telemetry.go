@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import "github.com/DataDog/datadog-agent/pkg/telemetry"
+
+var (
+	tlmRequests = telemetry.NewCounter("otlp", "requests",
+		[]string{"protocol", "signal"}, "Count of the OTLP requests received")
+	tlmRequestErrors = telemetry.NewCounter("otlp", "request_errors",
+		[]string{"protocol", "signal", "reason"}, "Count of the OTLP requests which couldn't be processed")
+	tlmSamples = telemetry.NewCounter("otlp", "metric_samples",
+		nil, "Count of the metric samples sent to the aggregator")
+	tlmMetricsDropped = telemetry.NewCounter("otlp", "metrics_dropped",
+		[]string{"reason"}, "Count of the OTLP metrics or data points which couldn't be translated")
+	tlmLogRecords = telemetry.NewCounter("otlp", "log_records",
+		nil, "Count of the log records sent to the logs pipeline")
+)
@@ -0,0 +1,271 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+const (
+	// seriesTTL is the time after which the last value of a cumulative series which stopped
+	// being reported is forgotten
+	seriesTTL = 15 * time.Minute
+
+	hostnameAttribute = "host.name"
+)
+
+// attributeTags maps the OpenTelemetry semantic conventions to the Datadog unified service tags
+var attributeTags = map[string]string{
+	"service.name":           "service",
+	"service.version":        "version",
+	"deployment.environment": "env",
+}
+
+// timeNow is overridden by the tests
+var timeNow = time.Now
+
+// cumulativePoint is the last point of a cumulative series, the values being the ones
+// of a sum or the count, sum and bucket counts of a histogram
+type cumulativePoint struct {
+	startTime uint64
+	time      uint64
+	values    []float64
+	lastSeen  time.Time
+}
+
+// translator converts the OTLP metrics to metric samples, keeping the last point of the
+// cumulative series to report their deltas as counts
+type translator struct {
+	defaultHostname string
+
+	mu        sync.Mutex
+	previous  map[string]*cumulativePoint
+	lastSweep time.Time
+}
+
+func newTranslator(defaultHostname string) *translator {
+	return &translator{
+		defaultHostname: defaultHostname,
+		previous:        make(map[string]*cumulativePoint),
+		lastSweep:       timeNow(),
+	}
+}
+
+// translate converts the metrics of a request, dropping the unsupported ones
+func (t *translator) translate(req *exportRequest) []metrics.MetricSample {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.sweep()
+
+	var samples []metrics.MetricSample
+	for _, rm := range req.resourceMetrics {
+		host, resourceTags := t.resourceTags(rm.attributes)
+		for _, m := range rm.metrics {
+			switch m.kind {
+			case kindGauge:
+				samples = t.translateGauge(samples, m, host, resourceTags)
+			case kindSum:
+				samples = t.translateSum(samples, m, host, resourceTags)
+			case kindHistogram:
+				samples = t.translateHistogram(samples, m, host, resourceTags)
+			default:
+				tlmMetricsDropped.Inc("unsupported_type")
+			}
+		}
+	}
+	return samples
+}
+
+// resourceTags returns the hostname of the resource and the tags of its attributes
+func (t *translator) resourceTags(attributes []keyValue) (string, []string) {
+	host := t.defaultHostname
+	tags := make([]string, 0, len(attributes))
+	for _, kv := range attributes {
+		if kv.key == hostnameAttribute {
+			if kv.value != "" {
+				host = kv.value
+			}
+			continue
+		}
+		tags = appendTag(tags, kv)
+	}
+	return host, tags
+}
+
+func appendTag(tags []string, kv keyValue) []string {
+	if kv.value == "" {
+		return tags
+	}
+	key := kv.key
+	if tagKey, found := attributeTags[key]; found {
+		key = tagKey
+	}
+	return append(tags, key+":"+kv.value)
+}
+
+// pointTags returns the tags of the resource and of a data point, sorted to build the key of the series
+func pointTags(resourceTags []string, attributes []keyValue) []string {
+	tags := make([]string, 0, len(resourceTags)+len(attributes))
+	tags = append(tags, resourceTags...)
+	for _, kv := range attributes {
+		tags = appendTag(tags, kv)
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+func (t *translator) translateGauge(samples []metrics.MetricSample, m metric, host string, resourceTags []string) []metrics.MetricSample {
+	for _, p := range m.numberPoints {
+		samples = append(samples, newSample(m.name, metrics.GaugeType, p.value, host, pointTags(resourceTags, p.attributes), p.time))
+	}
+	return samples
+}
+
+// translateSum reports the delta sums and the monotonic cumulative sums as counts, and the
+// non-monotonic cumulative sums, like the up-down counters, as gauges
+func (t *translator) translateSum(samples []metrics.MetricSample, m metric, host string, resourceTags []string) []metrics.MetricSample {
+	for _, p := range m.numberPoints {
+		tags := pointTags(resourceTags, p.attributes)
+		switch {
+		case m.temporality == temporalityDelta:
+			samples = append(samples, newSample(m.name, metrics.CountType, p.value, host, tags, p.time))
+		case m.temporality == temporalityCumulative && !m.monotonic:
+			samples = append(samples, newSample(m.name, metrics.GaugeType, p.value, host, tags, p.time))
+		case m.temporality == temporalityCumulative:
+			deltas, ok := t.delta(seriesKey(m.name, host, tags), p.startTime, p.time, []float64{p.value})
+			if ok {
+				samples = append(samples, newSample(m.name, metrics.CountType, deltas[0], host, tags, p.time))
+			}
+		default:
+			tlmMetricsDropped.Inc("unspecified_temporality")
+		}
+	}
+	return samples
+}
+
+// translateHistogram reports the count and the sum of the histograms as <name>.count and <name>.sum,
+// and the count of each bucket as <name>.bucket tagged with the bounds of the bucket
+func (t *translator) translateHistogram(samples []metrics.MetricSample, m metric, host string, resourceTags []string) []metrics.MetricSample {
+	for _, p := range m.histogramPoints {
+		if len(p.bucketCounts) > 0 && len(p.bucketCounts) != len(p.explicitBounds)+1 {
+			tlmMetricsDropped.Inc("invalid_histogram")
+			continue
+		}
+		tags := pointTags(resourceTags, p.attributes)
+
+		values := make([]float64, 0, len(p.bucketCounts)+2)
+		values = append(values, float64(p.count), p.sum)
+		for _, c := range p.bucketCounts {
+			values = append(values, float64(c))
+		}
+		switch m.temporality {
+		case temporalityDelta:
+		case temporalityCumulative:
+			var ok bool
+			if values, ok = t.delta(seriesKey(m.name, host, tags), p.startTime, p.time, values); !ok {
+				continue
+			}
+		default:
+			tlmMetricsDropped.Inc("unspecified_temporality")
+			continue
+		}
+
+		samples = append(samples, newSample(m.name+".count", metrics.CountType, values[0], host, tags, p.time))
+		if p.hasSum {
+			samples = append(samples, newSample(m.name+".sum", metrics.CountType, values[1], host, tags, p.time))
+		}
+		for i, count := range values[2:] {
+			lowerBound, upperBound := math.Inf(-1), math.Inf(1)
+			if i > 0 {
+				lowerBound = p.explicitBounds[i-1]
+			}
+			if i < len(p.explicitBounds) {
+				upperBound = p.explicitBounds[i]
+			}
+			bucketTags := make([]string, 0, len(tags)+2)
+			bucketTags = append(bucketTags, tags...)
+			bucketTags = append(bucketTags, "lower_bound:"+formatBound(lowerBound), "upper_bound:"+formatBound(upperBound))
+			samples = append(samples, newSample(m.name+".bucket", metrics.CountType, count, host, bucketTags, p.time))
+		}
+	}
+	return samples
+}
+
+// delta returns the difference between the values of a cumulative point and the previous point of
+// its series. The first point of a series is only recorded, and a point starting after the previous
+// one was reported is a reset of the series whose values are the deltas.
+func (t *translator) delta(key string, startTime, pointTime uint64, values []float64) ([]float64, bool) {
+	prev, found := t.previous[key]
+	t.previous[key] = &cumulativePoint{startTime: startTime, time: pointTime, values: values, lastSeen: timeNow()}
+	if !found {
+		return nil, false
+	}
+	if pointTime < prev.time {
+		// out of order point, keep the most recent one as the reference
+		t.previous[key] = prev
+		return nil, false
+	}
+	if startTime != prev.startTime || len(values) != len(prev.values) || values[0] < prev.values[0] {
+		if startTime != 0 && startTime >= prev.time {
+			return values, true
+		}
+		return nil, false
+	}
+
+	deltas := make([]float64, len(values))
+	for i := range values {
+		deltas[i] = values[i] - prev.values[i]
+	}
+	return deltas, true
+}
+
+// sweep forgets the cumulative series which weren't reported for the last seriesTTL
+func (t *translator) sweep() {
+	now := timeNow()
+	if now.Sub(t.lastSweep) < seriesTTL {
+		return
+	}
+	for key, p := range t.previous {
+		if now.Sub(p.lastSeen) > seriesTTL {
+			delete(t.previous, key)
+		}
+	}
+	t.lastSweep = now
+}
+
+func seriesKey(name, host string, tags []string) string {
+	return name + "|" + host + "|" + strings.Join(tags, ",")
+}
+
+func formatBound(bound float64) string {
+	switch {
+	case math.IsInf(bound, 1):
+		return "inf"
+	case math.IsInf(bound, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(bound, 'g', -1, 64)
+	}
+}
+
+func newSample(name string, mtype metrics.MetricType, value float64, host string, tags []string, timeUnixNano uint64) metrics.MetricSample {
+	return metrics.MetricSample{
+		Name:       name,
+		Value:      value,
+		Mtype:      mtype,
+		Tags:       tags,
+		Host:       host,
+		SampleRate: 1,
+		Timestamp:  float64(timeUnixNano) / float64(time.Second),
+	}
+}
@@ -0,0 +1,150 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package otlp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func translate(t *testing.T, tr *translator, req *pbMessage) []metrics.MetricSample {
+	decoded, err := decodeExportRequest(req.buf)
+	require.NoError(t, err)
+	return tr.translate(decoded)
+}
+
+func TestTranslateResourceTags(t *testing.T) {
+	tr := newTranslator("agent-host")
+	resource := []*pbMessage{
+		stringAttribute("service.name", "checkout"),
+		stringAttribute("deployment.environment", "prod"),
+		stringAttribute("service.version", "1.2.3"),
+		stringAttribute("k8s.pod.name", "checkout-abc"),
+	}
+
+	samples := translate(t, tr, exportMessage(resource, gaugeMetric("queue.size", numberPoint(0, 2e9, 12, stringAttribute("queue", "jobs")))))
+	assert.Equal(t, []metrics.MetricSample{{
+		Name:       "queue.size",
+		Value:      12,
+		Mtype:      metrics.GaugeType,
+		Tags:       []string{"env:prod", "k8s.pod.name:checkout-abc", "queue:jobs", "service:checkout", "version:1.2.3"},
+		Host:       "agent-host",
+		SampleRate: 1,
+		Timestamp:  2,
+	}}, samples)
+
+	// host.name overrides the hostname of the agent and isn't a tag
+	samples = translate(t, tr, exportMessage([]*pbMessage{stringAttribute("host.name", "web-1")}, gaugeMetric("queue.size", numberPoint(0, 2e9, 12))))
+	require.Len(t, samples, 1)
+	assert.Equal(t, "web-1", samples[0].Host)
+	assert.Empty(t, samples[0].Tags)
+}
+
+func TestTranslateSums(t *testing.T) {
+	tr := newTranslator("agent-host")
+
+	// the delta sums are counts and the non-monotonic cumulative sums are gauges
+	samples := translate(t, tr, exportMessage(nil,
+		sumMetric("requests", temporalityDelta, true, numberPoint(1e9, 2e9, 5)),
+		sumMetric("connections", temporalityCumulative, false, numberPoint(1e9, 2e9, 7)),
+		sumMetric("unspecified", temporalityUnspecified, true, numberPoint(1e9, 2e9, 7)),
+	))
+	require.Len(t, samples, 2)
+	assert.Equal(t, "requests", samples[0].Name)
+	assert.Equal(t, metrics.CountType, samples[0].Mtype)
+	assert.Equal(t, 5.0, samples[0].Value)
+	assert.Equal(t, "connections", samples[1].Name)
+	assert.Equal(t, metrics.GaugeType, samples[1].Mtype)
+	assert.Equal(t, 7.0, samples[1].Value)
+}
+
+func TestTranslateCumulativeSum(t *testing.T) {
+	tr := newTranslator("agent-host")
+	send := func(startTime, time uint64, value float64) []metrics.MetricSample {
+		return translate(t, tr, exportMessage(nil, sumMetric("requests", temporalityCumulative, true, numberPoint(startTime, time, value))))
+	}
+
+	// the first point is the reference of the series
+	assert.Empty(t, send(1e9, 10e9, 100))
+
+	samples := send(1e9, 20e9, 130)
+	require.Len(t, samples, 1)
+	assert.Equal(t, metrics.CountType, samples[0].Mtype)
+	assert.Equal(t, 30.0, samples[0].Value)
+
+	// out of order points are ignored
+	assert.Empty(t, send(1e9, 15e9, 120))
+
+	// the application restarted after the previous point, its value is the delta
+	samples = send(25e9, 30e9, 12)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 12.0, samples[0].Value)
+
+	// a decreasing value without a new start time is a new reference
+	assert.Empty(t, send(25e9, 40e9, 5))
+	samples = send(25e9, 50e9, 8)
+	require.Len(t, samples, 1)
+	assert.Equal(t, 3.0, samples[0].Value)
+}
+
+func TestTranslateHistogram(t *testing.T) {
+	tr := newTranslator("agent-host")
+	send := func(temporality temporality, time uint64, count uint64, sum float64, buckets []uint64) []metrics.MetricSample {
+		point := histogramPoint(1e9, time, count, sum, []float64{0.1, 1}, buckets)
+		return translate(t, tr, exportMessage(nil, histogramMetric("latency", temporality, point)))
+	}
+	values := func(samples []metrics.MetricSample) map[string]float64 {
+		v := make(map[string]float64)
+		for _, s := range samples {
+			assert.Equal(t, metrics.CountType, s.Mtype)
+			key := s.Name
+			if len(s.Tags) > 0 {
+				key += "{" + s.Tags[0] + "," + s.Tags[1] + "}"
+			}
+			v[key] = s.Value
+		}
+		return v
+	}
+
+	assert.Equal(t, map[string]float64{
+		"latency.count": 6,
+		"latency.sum":   1.5,
+		"latency.bucket{lower_bound:-inf,upper_bound:0.1}": 3,
+		"latency.bucket{lower_bound:0.1,upper_bound:1}":    2,
+		"latency.bucket{lower_bound:1,upper_bound:inf}":    1,
+	}, values(send(temporalityDelta, 2e9, 6, 1.5, []uint64{3, 2, 1})))
+
+	assert.Empty(t, send(temporalityCumulative, 2e9, 6, 1.5, []uint64{3, 2, 1}))
+	assert.Equal(t, map[string]float64{
+		"latency.count": 4,
+		"latency.sum":   2.5,
+		"latency.bucket{lower_bound:-inf,upper_bound:0.1}": 1,
+		"latency.bucket{lower_bound:0.1,upper_bound:1}":    1,
+		"latency.bucket{lower_bound:1,upper_bound:inf}":    2,
+	}, values(send(temporalityCumulative, 3e9, 10, 4, []uint64{4, 3, 3})))
+
+	// the bucket counts must match the bounds
+	assert.Empty(t, send(temporalityDelta, 4e9, 6, 1.5, []uint64{3, 3}))
+}
+
+func TestTranslatorSweep(t *testing.T) {
+	now := time.Now()
+	timeNow = func() time.Time { return now }
+	defer func() { timeNow = time.Now }()
+
+	tr := newTranslator("agent-host")
+	translate(t, tr, exportMessage(nil, sumMetric("requests", temporalityCumulative, true, numberPoint(1e9, 2e9, 10))))
+	require.Len(t, tr.previous, 1)
+
+	now = now.Add(seriesTTL + time.Second)
+	translate(t, tr, exportMessage(nil))
+	assert.Empty(t, tr.previous)
+}
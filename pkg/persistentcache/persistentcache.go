@@ -38,13 +38,53 @@ func getFileForKey(key string) (string, error) {
 	return filepath.Join(parent, cleanedPath, cleanedFile), nil
 }
 
-// Write stores data on disk in the run directory.
+// Write stores data on disk in the run directory. The data is written to a temporary file renamed
+// once complete, so that a crash or a restart of the agent never leaves a truncated value.
 func Write(key, value string) error {
 	path, err := getFileForKey(key)
 	if err != nil {
 		return err
 	}
-	return ioutil.WriteFile(path, []byte(value), 0600)
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	if _, err := tmp.WriteString(value); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Delete removes a value previously stored, it doesn't fail if there is none.
+func Delete(key string) error {
+	path, err := getFileForKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// CheckKey returns the key of a value stored for a check instance, the same as the one used by
+// the Python checks, so that the values of an instance are stored in the directory of the check.
+func CheckKey(checkID, key string) string {
+	return checkID + "_" + key
 }
 
 // Read returns a value previously stored, or the empty string.
@@ -94,3 +94,51 @@ func TestWritePersistentCacheInvalidChar(t *testing.T) {
 	_, err = os.Stat(expectPathFile)
 	require.Nil(t, err)
 }
+
+func TestWritePersistentCacheOverwrite(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	defer os.RemoveAll(testDir)
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", testDir)
+
+	require.Nil(t, Write("my:key", "a longer value"))
+	require.Nil(t, Write("my:key", "short"))
+	value, err := Read("my:key")
+	assert.Nil(t, err)
+	assert.Equal(t, "short", value)
+
+	// no temporary file is left behind
+	files, err := ioutil.ReadDir(filepath.Join(testDir, "my"))
+	require.Nil(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, "key", files[0].Name())
+	assert.Equal(t, os.FileMode(0600), files[0].Mode().Perm())
+}
+
+func TestDeletePersistentCache(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	defer os.RemoveAll(testDir)
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", testDir)
+
+	require.Nil(t, Write("my:key", "myvalue"))
+	assert.Nil(t, Delete("my:key"))
+	value, err := Read("my:key")
+	assert.Nil(t, err)
+	assert.Equal(t, "", value)
+	assert.Nil(t, Delete("my:key"))
+}
+
+func TestCheckKey(t *testing.T) {
+	testDir, err := ioutil.TempDir("", "fake-datadog-run-")
+	require.Nil(t, err, fmt.Sprintf("%v", err))
+	defer os.RemoveAll(testDir)
+	mockConfig := config.Mock()
+	mockConfig.Set("run_path", testDir)
+
+	require.Nil(t, Write(CheckKey("mycheck:1234abcd", "cursor"), "42"))
+	_, err = os.Stat(filepath.Join(testDir, "mycheck", "1234abcd_cursor"))
+	assert.Nil(t, err)
+}
@@ -42,3 +42,8 @@ func WritePID(pidFilePath string) error {
 	// all good
 	return nil
 }
+
+// IsRunning returns whether a process with the given PID is running
+func IsRunning(pid int) bool {
+	return isProcess(pid)
+}
@@ -0,0 +1,206 @@
+// +build windows
+
+package checks
+
+import (
+	"fmt"
+	"time"
+	"unsafe"
+
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	cpu "github.com/DataDog/gopsutil/cpu"
+	process "github.com/DataDog/gopsutil/process"
+
+	"golang.org/x/sys/windows"
+)
+
+const (
+	// systemProcessInformationClass is the SystemProcessInformation class of NtQuerySystemInformation
+	systemProcessInformationClass = 5
+	// statusInfoLengthMismatch is returned when the buffer is too small for the process list
+	statusInfoLengthMismatch = 0xC0000004
+	// maxProcessInformationSize bounds the buffer, in case the process list keeps growing
+	maxProcessInformationSize = 64 * 1024 * 1024
+)
+
+var (
+	modntdll                     = windows.NewLazyDLL("ntdll.dll")
+	procNtQuerySystemInformation = modntdll.NewProc("NtQuerySystemInformation")
+
+	// processInformationSize is the size of the last buffer, reused so that the
+	// buffer doesn't have to grow on each check run
+	processInformationSize = 512 * 1024
+)
+
+type unicodeString struct {
+	Length        uint16
+	MaximumLength uint16
+	Buffer        *uint16
+}
+
+// systemProcessInformation is the SYSTEM_PROCESS_INFORMATION structure returned by
+// NtQuerySystemInformation for each process, followed by the thread informations.
+// The fields have the same alignment in go and in C on 386 and amd64.
+type systemProcessInformation struct {
+	NextEntryOffset              uint32
+	NumberOfThreads              uint32
+	WorkingSetPrivateSize        int64
+	HardFaultCount               uint32
+	NumberOfThreadsHighWatermark uint32
+	CycleTime                    uint64
+	CreateTime                   int64
+	UserTime                     int64
+	KernelTime                   int64
+	ImageName                    unicodeString
+	BasePriority                 int32
+	UniqueProcessID              uintptr
+	InheritedFromUniqueProcessID uintptr
+	HandleCount                  uint32
+	SessionID                    uint32
+	UniqueProcessKey             uintptr
+	PeakVirtualSize              uintptr
+	VirtualSize                  uintptr
+	PageFaultCount               uint32
+	PeakWorkingSetSize           uintptr
+	WorkingSetSize               uintptr
+	QuotaPeakPagedPoolUsage      uintptr
+	QuotaPagedPoolUsage          uintptr
+	QuotaPeakNonPagedPoolUsage   uintptr
+	QuotaNonPagedPoolUsage       uintptr
+	PagefileUsage                uintptr
+	PeakPagefileUsage            uintptr
+	PrivatePageCount             uintptr
+	ReadOperationCount           int64
+	WriteOperationCount          int64
+	OtherOperationCount          int64
+	ReadTransferCount            int64
+	WriteTransferCount           int64
+	OtherTransferCount           int64
+}
+
+// querySystemProcessInformation returns the information of all the processes in a single
+// system call, growing the buffer until the process list fits in it
+func querySystemProcessInformation() ([]byte, error) {
+	for {
+		buf := make([]byte, processInformationSize)
+		var returnedSize uint32
+		status, _, _ := procNtQuerySystemInformation.Call(
+			systemProcessInformationClass,
+			uintptr(unsafe.Pointer(&buf[0])),
+			uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&returnedSize)))
+		switch {
+		case status == 0:
+			return buf[:returnedSize], nil
+		case status == statusInfoLengthMismatch:
+			// processes may be created before the next call, leave some room for them
+			newSize := int(returnedSize) + int(returnedSize)/8
+			if newSize <= processInformationSize {
+				newSize = processInformationSize * 2
+			}
+			if newSize > maxProcessInformationSize {
+				return nil, fmt.Errorf("the process information exceeds %d bytes", maxProcessInformationSize)
+			}
+			processInformationSize = newSize
+		default:
+			return nil, fmt.Errorf("NtQuerySystemInformation failed with status 0x%x", status)
+		}
+	}
+}
+
+// parseSystemProcessInformation calls fn for each process of the buffer returned by
+// NtQuerySystemInformation
+func parseSystemProcessInformation(buf []byte, fn func(info *systemProcessInformation)) {
+	for offset := 0; offset+int(unsafe.Sizeof(systemProcessInformation{})) <= len(buf); {
+		info := (*systemProcessInformation)(unsafe.Pointer(&buf[offset]))
+		fn(info)
+		if info.NextEntryOffset == 0 {
+			return
+		}
+		offset += int(info.NextEntryOffset)
+	}
+}
+
+// imageName returns the executable name of the process, it points into the buffer
+// returned by NtQuerySystemInformation
+func (info *systemProcessInformation) imageName() string {
+	if info.ImageName.Buffer == nil || info.ImageName.Length == 0 {
+		return ""
+	}
+	n := int(info.ImageName.Length / 2)
+	return windows.UTF16ToString((*[1 << 20]uint16)(unsafe.Pointer(info.ImageName.Buffer))[:n:n])
+}
+
+// ntTimeToUnixMillis converts a time in 100ns since 1601, as returned by the NT
+// system calls, to milliseconds since the unix epoch
+func ntTimeToUnixMillis(t int64) int64 {
+	ft := windows.Filetime{LowDateTime: uint32(t), HighDateTime: uint32(t >> 32)}
+	return ft.Nanoseconds() / 1000000
+}
+
+// getAllProcessesFromSystemInformation gets the times, handles, threads, memory and IO
+// counters of all the processes with a single NtQuerySystemInformation call, instead of
+// several calls per process.  The processes are still opened once, when they are first
+// seen, to get their user and command line.
+func getAllProcessesFromSystemInformation(cfg *config.AgentConfig) (map[int32]*process.FilledProcess, error) {
+	buf, err := querySystemProcessInformation()
+	if err != nil {
+		return nil, err
+	}
+	procs := make(map[int32]*process.FilledProcess)
+
+	checkCount++
+	knownPids := makePidSet()
+	now := time.Now().UnixNano()
+
+	parseSystemProcessInformation(buf, func(info *systemProcessInformation) {
+		pid := uint32(info.UniqueProcessID)
+		if pid == 0 {
+			// this is the "system idle process".  We'll never be able to open it
+			return
+		}
+		cp, ok := cachedProcesses[pid]
+		if !ok {
+			cp = cachedProcess{}
+			if err := cp.fillFromProcEntry(pid, info.imageName()); err != nil {
+				log.Debugf("could not fill Win32 process information for pid %v %v", pid, err)
+				return
+			}
+			cachedProcesses[pid] = cp
+		}
+
+		delete(knownPids, pid)
+		procs[int32(pid)] = &process.FilledProcess{
+			Pid:     int32(pid),
+			Ppid:    int32(info.InheritedFromUniqueProcessID),
+			Cmdline: cp.parsedArgs,
+			CpuTime: cpu.TimesStat{
+				User:      float64(info.UserTime),
+				System:    float64(info.KernelTime),
+				Timestamp: now,
+			},
+
+			CreateTime:  ntTimeToUnixMillis(info.CreateTime),
+			OpenFdCount: int32(info.HandleCount),
+			NumThreads:  int32(info.NumberOfThreads),
+			CtxSwitches: &process.NumCtxSwitchesStat{},
+			MemInfo: &process.MemoryInfoStat{
+				RSS:  uint64(info.WorkingSetSize),
+				VMS:  uint64(info.QuotaPagedPoolUsage),
+				Swap: 0,
+			},
+			Exe: cp.executablePath,
+			IOStat: &process.IOCountersStat{
+				ReadCount:  uint64(info.ReadOperationCount),
+				WriteCount: uint64(info.WriteOperationCount),
+				ReadBytes:  uint64(info.ReadTransferCount),
+				WriteBytes: uint64(info.WriteTransferCount),
+			},
+			Username: cp.userName,
+		}
+	})
+	removeExitedProcesses(knownPids)
+
+	return procs, nil
+}
@@ -32,21 +32,6 @@ var (
 	haveWarnedNoArgs = false
 )
 
-type SystemProcessInformation struct {
-	NextEntryOffset   uint64
-	NumberOfThreads   uint64
-	Reserved1         [48]byte
-	Reserved2         [3]byte
-	UniqueProcessID   uintptr
-	Reserved3         uintptr
-	HandleCount       uint64
-	Reserved4         [4]byte
-	Reserved5         [11]byte
-	PeakPagefileUsage uint64
-	PrivatePageCount  uint64
-	Reserved6         [6]uint64
-}
-
 type IO_COUNTERS struct {
 	ReadOperationCount  uint64
 	WriteOperationCount uint64
@@ -81,6 +66,15 @@ func getProcessIoCounters(h windows.Handle, counters *IO_COUNTERS) (err error) {
 }
 
 func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess, error) {
+	if cfg.Windows.UseLegacyProcessCollection {
+		return getAllProcessesFromSnapshot(cfg)
+	}
+	return getAllProcessesFromSystemInformation(cfg)
+}
+
+// getAllProcessesFromSnapshot walks a toolhelp snapshot of the processes, and queries
+// the times, handles, memory and IO counters of each process with its handle
+func getAllProcessesFromSnapshot(cfg *config.AgentConfig) (map[int32]*process.FilledProcess, error) {
 	allProcsSnap := w32.CreateToolhelp32Snapshot(w32.TH32CS_SNAPPROCESS, 0)
 	if allProcsSnap == 0 {
 		return nil, windows.GetLastError()
@@ -109,7 +103,7 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 			// wasn't already in the map.
 			cp = cachedProcess{}
 
-			if err := cp.fillFromProcEntry(&pe32); err != nil {
+			if err := cp.fillFromProcEntry(pid, convertWindowsString(pe32.SzExeFile[:])); err != nil {
 				log.Debugf("could not fill Win32 process information for pid %v %v", pid, err)
 				continue
 			}
@@ -176,14 +170,19 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 			Username: cp.userName,
 		}
 	}
+	removeExitedProcesses(knownPids)
+
+	return procs, nil
+}
+
+// removeExitedProcesses closes and forgets the cached processes which were not found
+func removeExitedProcesses(knownPids map[uint32]bool) {
 	for pid := range knownPids {
 		cp := cachedProcesses[pid]
 		log.Debugf("removing process %v %v", pid, cp.executablePath)
 		cp.close()
 		delete(cachedProcesses, pid)
 	}
-
-	return procs, nil
 }
 
 func getUsernameForProcess(h windows.Handle) (name string, err error) {
@@ -275,26 +274,26 @@ type cachedProcess struct {
 	parsedArgs     []string
 }
 
-func (cp *cachedProcess) fillFromProcEntry(pe32 *w32.PROCESSENTRY32) (err error) {
+func (cp *cachedProcess) fillFromProcEntry(pid uint32, exeFile string) (err error) {
 	// 0x1000 is PROCESS_QUERY_LIMITED_INFORMATION, but that constant isn't
 	// 0x10   is PROCESS_VM_READ
 	// defined in x/sys/windows
-	cp.procHandle, err = windows.OpenProcess(0x1010, false, uint32(pe32.Th32ProcessID))
+	cp.procHandle, err = windows.OpenProcess(0x1010, false, pid)
 	if err != nil {
-		log.Debugf("Couldn't open process with PROCESS_VM_READ %v %v", pe32.Th32ProcessID, err)
-		cp.procHandle, err = windows.OpenProcess(0x1000, false, uint32(pe32.Th32ProcessID))
+		log.Debugf("Couldn't open process with PROCESS_VM_READ %v %v", pid, err)
+		cp.procHandle, err = windows.OpenProcess(0x1000, false, pid)
 		if err != nil {
-			log.Debugf("Couldn't open process %v %v", pe32.Th32ProcessID, err)
+			log.Debugf("Couldn't open process %v %v", pid, err)
 			return err
 		}
 	}
 	var usererr error
 	cp.userName, usererr = getUsernameForProcess(cp.procHandle)
 	if usererr != nil {
-		log.Debugf("Couldn't get process username %v %v", pe32.Th32ProcessID, err)
+		log.Debugf("Couldn't get process username %v %v", pid, err)
 	}
 	var cmderr error
-	cp.executablePath = convertWindowsString(pe32.SzExeFile[:])
+	cp.executablePath = exeFile
 	cp.commandLine, cmderr = winutil.GetCommandLineForProcess(cp.procHandle)
 	if cmderr != nil {
 		log.Debugf("Error retrieving full command line %v", cmderr)
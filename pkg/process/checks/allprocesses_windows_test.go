@@ -3,9 +3,14 @@
 package checks
 
 import (
+	"os"
 	"testing"
+	"unsafe"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/process/config"
 )
 
 func TestCommandLineSplitting(t *testing.T) {
@@ -78,3 +83,54 @@ func TestWindowsStringConversion(t *testing.T) {
 	}
 
 }
+
+func TestParseSystemProcessInformation(t *testing.T) {
+	entrySize := int(unsafe.Sizeof(systemProcessInformation{}))
+	// the entries are followed by thread informations, which are skipped
+	buf := make([]byte, 2*entrySize+64)
+	name := []uint16{'a', 'g', 'e', 'n', 't', '.', 'e', 'x', 'e'}
+
+	first := (*systemProcessInformation)(unsafe.Pointer(&buf[0]))
+	first.NextEntryOffset = uint32(entrySize + 64)
+	first.UniqueProcessID = 4
+	first.NumberOfThreads = 12
+	second := (*systemProcessInformation)(unsafe.Pointer(&buf[entrySize+64]))
+	second.UniqueProcessID = 1234
+	second.InheritedFromUniqueProcessID = 4
+	second.HandleCount = 42
+	second.ReadTransferCount = 1024
+	second.ImageName = unicodeString{Length: uint16(2 * len(name)), MaximumLength: uint16(2 * len(name)), Buffer: &name[0]}
+
+	var infos []systemProcessInformation
+	var names []string
+	parseSystemProcessInformation(buf, func(info *systemProcessInformation) {
+		infos = append(infos, *info)
+		names = append(names, info.imageName())
+	})
+	require.Len(t, infos, 2)
+	assert.EqualValues(t, 4, infos[0].UniqueProcessID)
+	assert.EqualValues(t, 12, infos[0].NumberOfThreads)
+	assert.EqualValues(t, 1234, infos[1].UniqueProcessID)
+	assert.EqualValues(t, 4, infos[1].InheritedFromUniqueProcessID)
+	assert.EqualValues(t, 42, infos[1].HandleCount)
+	assert.EqualValues(t, 1024, infos[1].ReadTransferCount)
+	assert.Equal(t, []string{"", "agent.exe"}, names)
+}
+
+func TestGetAllProcessesCollectionPaths(t *testing.T) {
+	pid := int32(os.Getpid())
+	for _, legacy := range []bool{false, true} {
+		cfg := config.NewDefaultAgentConfig(false)
+		cfg.Windows.UseLegacyProcessCollection = legacy
+
+		procs, err := getAllProcesses(cfg)
+		require.NoError(t, err)
+		self, found := procs[pid]
+		require.True(t, found, "the test process was not collected, legacy: %v", legacy)
+		assert.True(t, self.NumThreads > 0)
+		assert.True(t, self.OpenFdCount > 0)
+		assert.True(t, self.MemInfo.RSS > 0)
+		assert.True(t, self.CreateTime > 0)
+		assert.Equal(t, int32(os.Getppid()), self.Ppid)
+	}
+}
@@ -2,6 +2,8 @@ package checks
 
 import (
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -163,6 +165,7 @@ func fmtContainers(ctrList []*containers.Container, lastRates map[string]util.Co
 			log.Debugf("No tags found for app %s, it has probably not been discovered by the DCA, skipping.", ctr.ID)
 			continue
 		}
+		tags = append(tags, orchestrationTags(ctr)...)
 
 		containersList = append(containersList, &model.Container{
 			Id:          ctr.ID,
@@ -193,6 +196,19 @@ func fmtContainers(ctrList []*containers.Container, lastRates map[string]util.Co
 	return containersList
 }
 
+// orchestrationTags returns the owners and the restart count of a container, the container payload
+// has no field for them
+func orchestrationTags(ctr *containers.Container) []string {
+	tags := make([]string, 0, 2*len(ctr.Owners)+1)
+	for _, owner := range ctr.Owners {
+		tags = append(tags, "kube_ownerref_kind:"+strings.ToLower(owner.Kind), "kube_ownerref_name:"+owner.Name)
+	}
+	if ctr.RestartCount > 0 {
+		tags = append(tags, "restart_count:"+strconv.Itoa(ctr.RestartCount))
+	}
+	return tags
+}
+
 // chunkContainers formats and chunks the ctrList into a slice of chunks using a specific number of chunks.
 func chunkContainers(ctrList []*containers.Container, lastRates map[string]util.ContainerRateMetrics, lastRun time.Time, chunks, perChunk int) [][]*model.Container {
 	chunked := make([][]*model.Container, 0, chunks)
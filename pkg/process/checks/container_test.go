@@ -82,6 +82,22 @@ func TestContainerAddresses(t *testing.T) {
 	assert.Equal(t, results[0].Addresses, addrs)
 }
 
+func TestContainerOrchestrationTags(t *testing.T) {
+	ctr := makeContainer("haha")
+	ctr.RestartCount = 3
+	ctr.Owners = []containers.ContainerOwner{{Kind: "ReplicaSet", Name: "redis-75586d7d7c"}}
+	results := fmtContainers([]*containers.Container{ctr}, map[string]util.ContainerRateMetrics{}, time.Now())
+	assert.Equal(t, 1, len(results))
+	assert.ElementsMatch(t, []string{
+		"kube_ownerref_kind:replicaset",
+		"kube_ownerref_name:redis-75586d7d7c",
+		"restart_count:3",
+	}, results[0].Tags)
+
+	// containers that never restarted, or not managed by an orchestrator, have no additional tags
+	assert.Empty(t, orchestrationTags(makeContainer("hoho")))
+}
+
 func TestNoGardenContainerWithEmptyTags(t *testing.T) {
 	ctr := makeContainer("haha")
 	ctr.Type = containers.RuntimeNameGarden
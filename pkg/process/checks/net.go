@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 
 	model "github.com/DataDog/agent-payload/process"
@@ -12,6 +13,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/process/dockerproxy"
 	"github.com/DataDog/datadog-agent/pkg/process/net"
 	"github.com/DataDog/datadog-agent/pkg/process/net/resolver"
+	"github.com/DataDog/datadog-agent/pkg/process/statsd"
 	procutil "github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/util"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -34,6 +36,8 @@ type ConnectionsCheck struct {
 	networkID              string
 	notInitializedLogLimit *procutil.LogLimit
 	lastTelemetry          *model.CollectorConnectionsTelemetry
+	serviceStatsEnabled    bool
+	dnsStatsEnabled        bool
 }
 
 // Init initializes a ConnectionsCheck instance.
@@ -42,6 +46,8 @@ func (c *ConnectionsCheck) Init(cfg *config.AgentConfig, _ *model.SystemInfo) {
 
 	// We use the current process PID as the system-probe client ID
 	c.tracerClientID = fmt.Sprintf("%d", os.Getpid())
+	c.serviceStatsEnabled = cfg.EnableProtocolClassification
+	c.dnsStatsEnabled = cfg.CollectDNSStats
 
 	// Calling the remote tracer will cause it to initialize and check connectivity
 	net.SetSystemProbePath(cfg.SystemProbeAddress)
@@ -87,6 +93,13 @@ func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.
 
 	tel := c.diffTelemetry(conns.Telemetry)
 
+	if c.serviceStatsEnabled {
+		c.reportServiceStats()
+	}
+	if c.dnsStatsEnabled {
+		c.reportDNSDomainStats()
+	}
+
 	log.Debugf("collected connections in %s", time.Since(start))
 	return batchConnections(cfg, groupID, c.enrichConnections(conns.Conns), conns.Dns, c.networkID, tel), nil
 }
@@ -102,6 +115,79 @@ func (c *ConnectionsCheck) getConnections() (*model.Connections, error) {
 	return tu.GetConnections(c.tracerClientID)
 }
 
+// reportServiceStats sends the requests stats of the services classified by the system probe as metrics
+func (c *ConnectionsCheck) reportServiceStats() {
+	tu, err := net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return
+	}
+	services, err := tu.GetServiceStats()
+	if err != nil {
+		log.Debugf("unable to retrieve service stats: %s", err)
+		return
+	}
+
+	for _, s := range services {
+		tags := []string{
+			"protocol:" + s.Protocol,
+			"service_ip:" + s.IP,
+			"service_port:" + strconv.Itoa(int(s.Port)),
+		}
+		statsd.Client.Count("network.service.requests", int64(s.Requests), tags, 1) //nolint:errcheck
+		statsd.Client.Count("network.service.errors", int64(s.Errors), tags, 1)     //nolint:errcheck
+		if s.Requests > 0 {
+			// average latency of the requests in milliseconds, the latency sum is stored in µs
+			latency := float64(s.LatencySum) / float64(s.Requests) / 1000
+			statsd.Client.Gauge("network.service.latency", latency, tags, 1) //nolint:errcheck
+		}
+	}
+}
+
+// reportDNSDomainStats sends the DNS stats of the connections by queried domain as metrics,
+// tagged with the container of the processes sending the queries
+func (c *ConnectionsCheck) reportDNSDomainStats() {
+	tu, err := net.GetRemoteSystemProbeUtil()
+	if err != nil {
+		return
+	}
+	domainStats, err := tu.GetDNSDomainStats(c.tracerClientID)
+	if err != nil {
+		log.Debugf("unable to retrieve DNS domain stats: %s", err)
+		return
+	}
+
+	pids := make([]int32, 0, len(domainStats))
+	for _, s := range domainStats {
+		pids = append(pids, int32(s.Pid))
+	}
+	ctrIDForPID := getCtrIDsByPIDs(pids)
+
+	for _, s := range domainStats {
+		tags := []string{"domain:" + s.Domain}
+		if ctrID, ok := ctrIDForPID[int32(s.Pid)]; ok {
+			tags = append(tags, "container_id:"+ctrID)
+		}
+		// the full slice expression makes every result tag append to a copy of the tags
+		withResult := func(result string) []string {
+			return append(tags[:len(tags):len(tags)], "result:"+result)
+		}
+
+		statsd.Client.Count("network.dns.responses", int64(s.SuccessfulResponses), withResult("success"), 1)                 //nolint:errcheck
+		statsd.Client.Count("network.dns.responses", int64(s.NXDomainResponses), withResult("nxdomain"), 1)                  //nolint:errcheck
+		statsd.Client.Count("network.dns.responses", int64(s.FailedResponses-s.NXDomainResponses), withResult("failure"), 1) //nolint:errcheck
+		statsd.Client.Count("network.dns.timeouts", int64(s.Timeouts), tags, 1)                                              //nolint:errcheck
+		if s.SuccessfulResponses > 0 {
+			// average latency in milliseconds, the latency sums are stored in µs
+			latency := float64(s.SuccessLatencySum) / float64(s.SuccessfulResponses) / 1000
+			statsd.Client.Gauge("network.dns.latency", latency, withResult("success"), 1) //nolint:errcheck
+		}
+		if s.FailedResponses > 0 {
+			latency := float64(s.FailureLatencySum) / float64(s.FailedResponses) / 1000
+			statsd.Client.Gauge("network.dns.latency", latency, withResult("failure"), 1) //nolint:errcheck
+		}
+	}
+}
+
 func (c *ConnectionsCheck) enrichConnections(conns []*model.Connection) []*model.Connection {
 	// Process create-times required to construct unique process hash keys on the backend
 	createTimeForPID := Process.createTimesforPIDs(connectionPIDs(conns))
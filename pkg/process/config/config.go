@@ -15,7 +15,10 @@ import (
 	"time"
 
 	model "github.com/DataDog/agent-payload/process"
+	"github.com/DataDog/datadog-agent/pkg/api/security"
+	apiutil "github.com/DataDog/datadog-agent/pkg/api/util"
 	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
 	"github.com/DataDog/datadog-agent/pkg/process/util"
 	"github.com/DataDog/datadog-agent/pkg/process/util/api"
 	"github.com/DataDog/datadog-agent/pkg/util/fargate"
@@ -42,6 +45,8 @@ type WindowsConfig struct {
 	ArgsRefreshInterval int
 	// Controls getting process arguments immediately when a new process is discovered
 	AddNewArgs bool
+	// Queries each process separately instead of getting all of them with NtQuerySystemInformation
+	UseLegacyProcessCollection bool
 }
 
 // AgentConfig is the global config for the process-agent. This information
@@ -97,6 +102,16 @@ type AgentConfig struct {
 	CollectDNSStats bool
 	DNSTimeout      time.Duration
 
+	// Protocol classification configuration
+	EnableProtocolClassification bool
+	MaxServiceStatsBuffered      int
+
+	// Traceroute configuration
+	TracerouteDestinations []traceroute.Destination
+	TracerouteInterval     time.Duration
+	TracerouteMaxTTL       int
+	TracerouteTimeout      time.Duration
+
 	// Orchestrator collection configuration
 	OrchestrationCollectionEnabled bool
 	KubeClusterName                string
@@ -134,6 +149,8 @@ const (
 	maxConnsMessageBatch         = 1000
 	defaultMaxTrackedConnections = 65536
 	maxOffsetThreshold           = 3000
+	// agentAPITimeout is the timeout of the requests to the IPC API of the infra agent
+	agentAPITimeout = 5 * time.Second
 )
 
 // NewDefaultTransport provides a http transport configuration with sane default timeouts
@@ -211,6 +228,11 @@ func NewDefaultAgentConfig(canAccessContainers bool) *AgentConfig {
 		ConntrackRateLimit:    500,
 		OffsetGuessThreshold:  400,
 
+		// Traceroute config
+		TracerouteInterval: 5 * time.Minute,
+		TracerouteMaxTTL:   traceroute.DefaultMaxTTL,
+		TracerouteTimeout:  traceroute.DefaultTimeout,
+
 		// Check config
 		EnabledChecks: enabledChecks,
 		CheckIntervals: map[string]time.Duration{
@@ -471,6 +493,7 @@ func loadSysProbeEnvVariables() {
 		{"DD_DISABLE_DNS_INSPECTION", "system_probe_config.disable_dns_inspection"},
 		{"DD_COLLECT_LOCAL_DNS", "system_probe_config.collect_local_dns"},
 		{"DD_COLLECT_DNS_STATS", "system_probe_config.collect_dns_stats"},
+		{"DD_ENABLE_PROTOCOL_CLASSIFICATION", "system_probe_config.enable_protocol_classification"},
 	} {
 		if v, ok := os.LookupEnv(variable.env); ok {
 			config.Datadog.Set(variable.cfg, v)
@@ -498,9 +521,15 @@ func isAffirmative(value string) (bool, error) {
 	return v == "true" || v == "yes" || v == "1", nil
 }
 
-// getHostname shells out to obtain the hostname used by the infra agent
+// getHostname asks the running infra agent for its hostname, or shells out to obtain it,
 // falling back to os.Hostname() if it is unavailable
 func getHostname(ddAgentBin string) (string, error) {
+	hostname, err := getHostnameFromAgentAPI()
+	if err == nil {
+		return hostname, nil
+	}
+	log.Debugf("Unable to get the hostname from the agent API, running the agent: %v", err)
+
 	cmd := exec.Command(ddAgentBin, "hostname")
 
 	// Copying all environment variables to child process
@@ -512,13 +541,13 @@ func getHostname(ddAgentBin string) (string, error) {
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	err := cmd.Run()
+	err = cmd.Run()
 	if err != nil {
 		log.Infof("error retrieving dd-agent hostname, falling back to os.Hostname(): %v", err)
 		return os.Hostname()
 	}
 
-	hostname := strings.TrimSpace(stdout.String())
+	hostname = strings.TrimSpace(stdout.String())
 
 	if hostname == "" {
 		log.Infof("error retrieving dd-agent hostname, falling back to os.Hostname(): %s", stderr.String())
@@ -528,6 +557,36 @@ func getHostname(ddAgentBin string) (string, error) {
 	return hostname, err
 }
 
+// getHostnameFromAgentAPI gets the hostname from the IPC API of the running infra agent,
+// authenticated with the process-agent scoped token
+func getHostnameFromAgentAPI() (string, error) {
+	token, err := security.FetchScopedToken(security.ProcessAgentTokenName)
+	if err != nil {
+		return "", err
+	}
+	ipcAddress, err := config.GetIPCAddress()
+	if err != nil {
+		return "", err
+	}
+
+	c := apiutil.GetIPCClient()
+	c.Timeout = agentAPITimeout
+	urlstr := fmt.Sprintf("https://%v:%v/agent/hostname", ipcAddress, config.Datadog.GetInt("cmd_port"))
+	body, err := apiutil.DoGetWithToken(c, urlstr, token)
+	if err != nil {
+		return "", err
+	}
+
+	var hostname string
+	if err := json.Unmarshal(body, &hostname); err != nil {
+		return "", err
+	}
+	if hostname == "" {
+		return "", fmt.Errorf("the agent returned an empty hostname")
+	}
+	return hostname, nil
+}
+
 // proxyFromEnv parses out the proxy configuration from the ENV variables in a
 // similar way to getProxySettings and, if enough values are available, returns
 // a new proxy URL value. If the environment is not set for this then the
@@ -4,8 +4,12 @@ package config
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
@@ -15,6 +19,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/gopsutil/process"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var originalConfig = config.Datadog
@@ -194,6 +199,39 @@ func TestGetHostname(t *testing.T) {
 	assert.NotEqual(t, "", h)
 }
 
+func TestGetHostnameFromAgentAPI(t *testing.T) {
+	config.Datadog = config.NewConfig("datadog", "DD", strings.NewReplacer(".", "_"))
+	defer restoreGlobalConfig()
+
+	dir, err := ioutil.TempDir("", "process-agent")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	config.Datadog.Set("auth_token_file_path", filepath.Join(dir, "auth_token"))
+	token := strings.Repeat("a", 64)
+
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/agent/hostname" || r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`"agent-hostname"`))
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	config.Datadog.Set("ipc_address", serverURL.Hostname())
+	config.Datadog.Set("cmd_port", serverURL.Port())
+
+	// the core agent didn't create the token yet
+	_, err = getHostnameFromAgentAPI()
+	assert.Error(t, err)
+
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "process_agent.auth_token"), []byte(token), 0600))
+	hostname, err := getHostnameFromAgentAPI()
+	require.NoError(t, err)
+	assert.Equal(t, "agent-hostname", hostname)
+}
+
 func TestDefaultConfig(t *testing.T) {
 	assert := assert.New(t)
 	agentConfig := NewDefaultAgentConfig(false)
@@ -93,8 +93,7 @@ func compileStringsToRegex(words []string) []*regexp.Regexp {
 			continue
 		}
 
-		pattern := "(?P<key>( +| -{1,2})(?i)" + enhancedWord.String() + ")(?P<delimiter> +|=|:)(?P<value>[^\\s]*)"
-		r, err := regexp.Compile(pattern)
+		r, err := regexp.Compile(sensitiveArgumentPattern(enhancedWord.String()))
 		if err == nil {
 			compiledRegexps = append(compiledRegexps, r)
 		} else {
@@ -105,6 +104,33 @@ func compileStringsToRegex(words []string) []*regexp.Regexp {
 	return compiledRegexps
 }
 
+// compileRegexps compiles each user-defined regular expression into a regex pattern to match
+// against the cmdline arguments. The expression must match the whole name of the argument.
+func compileRegexps(expressions []string) []*regexp.Regexp {
+	compiledRegexps := make([]*regexp.Regexp, 0, len(expressions))
+	for _, expression := range expressions {
+		if _, err := regexp.Compile(expression); err != nil {
+			log.Warnf("data scrubber: %s skipped. It is not a valid regular expression: %s", expression, err)
+			continue
+		}
+
+		r, err := regexp.Compile(sensitiveArgumentPattern("(?:" + expression + ")"))
+		if err == nil {
+			compiledRegexps = append(compiledRegexps, r)
+		} else {
+			log.Warnf("data scrubber: %s skipped. It couldn't be compiled into a regex expression", expression)
+		}
+	}
+
+	return compiledRegexps
+}
+
+// sensitiveArgumentPattern returns the pattern matching an argument named after the given
+// pattern, optionally prefixed with one or two dashes, and its value
+func sensitiveArgumentPattern(name string) string {
+	return "(?P<key>( +| -{1,2})(?i)" + name + ")(?P<delimiter> +|=|:)(?P<value>[^\\s]*)"
+}
+
 // createProcessKey returns an unique identifier for a given process
 func createProcessKey(p *process.FilledProcess) string {
 	var b bytes.Buffer
@@ -186,3 +212,15 @@ func (ds *DataScrubber) AddCustomSensitiveWords(words []string) {
 	newPatterns := compileStringsToRegex(words)
 	ds.SensitivePatterns = append(ds.SensitivePatterns, newPatterns...)
 }
+
+// AddCustomSensitiveRegexps adds custom sensitive regular expressions on the DataScrubber object,
+// each one must match the whole name of a sensitive argument, case insensitively
+func (ds *DataScrubber) AddCustomSensitiveRegexps(expressions []string) {
+	newPatterns := compileRegexps(expressions)
+	ds.SensitivePatterns = append(ds.SensitivePatterns, newPatterns...)
+}
+
+// StripArguments strips away all arguments from the command line, keeping only the binary
+func (ds *DataScrubber) StripArguments(cmdline []string) []string {
+	return ds.stripArguments(cmdline)
+}
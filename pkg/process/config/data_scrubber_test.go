@@ -371,6 +371,28 @@ func TestMatchWildCards(t *testing.T) {
 	}
 }
 
+func TestMatchRegexps(t *testing.T) {
+	scrubber := NewDefaultDataScrubber()
+	scrubber.AddCustomSensitiveRegexps([]string{
+		"(db|cache)_pass(word)?",
+		"token_[0-9]{1,2}",
+		"invalid(",
+	})
+	assert.Equal(t, len(defaultSensitiveWords)+2, len(scrubber.SensitivePatterns))
+
+	cases := []testCase{
+		{[]string{"agent", "--db_pass=1234", "--cache_password", "1234"}, []string{"agent", "--db_pass=********", "--cache_password", "********"}},
+		{[]string{"agent", "-DB_PASS:1234"}, []string{"agent", "-DB_PASS:********"}},
+		{[]string{"agent", "--token_12=1234", "--token_123=1234"}, []string{"agent", "--token_12=********", "--token_123=1234"}},
+		{[]string{"agent", "--mydb_pass=1234", "--db_passes=1234"}, []string{"agent", "--mydb_pass=1234", "--db_passes=1234"}},
+	}
+
+	for i := range cases {
+		cases[i].cmdline, _ = scrubber.ScrubCommand(cases[i].cmdline)
+		assert.Equal(t, cases[i].parsedCmdline, cases[i].cmdline)
+	}
+}
+
 func TestScrubWithCache(t *testing.T) {
 	testProcs, sensible := setupTestProcesses()
 	scrubber := setupDataScrubber(t)
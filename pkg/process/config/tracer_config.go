@@ -52,6 +52,11 @@ func SysProbeConfigFromConfig(cfg *AgentConfig) *ebpf.Config {
 		tracerConfig.DNSTimeout = cfg.DNSTimeout
 	}
 
+	tracerConfig.EnableProtocolClassification = cfg.EnableProtocolClassification
+	if mssb := cfg.MaxServiceStatsBuffered; mssb > 0 {
+		tracerConfig.MaxServiceStatsBuffered = mssb
+	}
+
 	tracerConfig.MaxTrackedConnections = cfg.MaxTrackedConnections
 	tracerConfig.ProcRoot = util.GetProcRoot()
 	tracerConfig.BPFDebug = cfg.SysProbeBPFDebug
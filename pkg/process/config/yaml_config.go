@@ -51,6 +51,11 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.DNSTimeout = config.Datadog.GetDuration(key(spNS, "dns_timeout_in_s")) * time.Second
 	}
 
+	a.EnableProtocolClassification = config.Datadog.GetBool(key(spNS, "enable_protocol_classification"))
+	if mssb := config.Datadog.GetInt(key(spNS, "max_service_stats_buffered")); mssb > 0 {
+		a.MaxServiceStatsBuffered = mssb
+	}
+
 	if config.Datadog.GetBool(key(spNS, "enabled")) {
 		a.EnabledChecks = append(a.EnabledChecks, "connections")
 		if !a.Enabled {
@@ -153,6 +158,35 @@ func (a *AgentConfig) loadSysProbeYamlConfig(path string) error {
 		a.EnabledChecks = append(a.EnabledChecks, "OOM Kill")
 	}
 
+	if config.Datadog.GetBool(key(spNS, "traceroute", "enabled")) {
+		a.EnabledChecks = append(a.EnabledChecks, "traceroute")
+	}
+
+	if k := key(spNS, "traceroute", "destinations"); config.Datadog.IsSet(k) {
+		if err := config.Datadog.UnmarshalKey(k, &a.TracerouteDestinations); err != nil {
+			log.Errorf("Invalid %s: %s", k, err)
+		}
+	}
+
+	// The interval between two traceroutes to the configured destinations, 0 disables them
+	if k := key(spNS, "traceroute", "interval"); config.Datadog.IsSet(k) {
+		a.TracerouteInterval = config.Datadog.GetDuration(k)
+	}
+
+	if k := key(spNS, "traceroute", "max_ttl"); config.Datadog.IsSet(k) {
+		if maxTTL := config.Datadog.GetInt(k); maxTTL > 0 && maxTTL <= 255 {
+			a.TracerouteMaxTTL = maxTTL
+		} else {
+			log.Warnf("Invalid %s %d, must be between 1 and 255, using the default %d", k, maxTTL, a.TracerouteMaxTTL)
+		}
+	}
+
+	if k := key(spNS, "traceroute", "timeout"); config.Datadog.IsSet(k) {
+		if timeout := config.Datadog.GetDuration(k); timeout > 0 {
+			a.TracerouteTimeout = timeout
+		}
+	}
+
 	return nil
 }
 
@@ -260,6 +294,11 @@ func (a *AgentConfig) LoadProcessYamlConfig(path string) error {
 		a.Scrubber.AddCustomSensitiveWords(config.Datadog.GetStringSlice(k))
 	}
 
+	// Custom regular expressions matching the names of sensitive arguments
+	if k := key(ns, "custom_sensitive_regexps"); config.Datadog.IsSet(k) {
+		a.Scrubber.AddCustomSensitiveRegexps(config.Datadog.GetStringSlice(k))
+	}
+
 	// Strips all process arguments
 	if config.Datadog.GetBool(key(ns, "strip_proc_arguments")) {
 		a.Scrubber.StripAllArguments = true
@@ -313,6 +352,9 @@ func (a *AgentConfig) LoadProcessYamlConfig(path string) error {
 		a.Windows.AddNewArgs = config.Datadog.GetBool(addArgsKey)
 	}
 
+	// Windows: Falls back to querying each process separately
+	a.Windows.UseLegacyProcessCollection = config.Datadog.GetBool(key(ns, "windows", "use_legacy_process_collection"))
+
 	// Optional additional pairs of endpoint_url => []apiKeys to submit to other locations.
 	if k := key(ns, "additional_endpoints"); config.Datadog.IsSet(k) {
 		for endpointURL, apiKeys := range config.Datadog.GetStringMapStringSlice(k) {
@@ -351,6 +393,11 @@ func (a *AgentConfig) LoadProcessYamlConfig(path string) error {
 		util.SetContainerSources(sources)
 	}
 
+	// Exclude the pause containers from the live containers, whatever the global exclude_pause_container option.
+	if k := key(ns, "exclude_pause_container"); config.Datadog.IsSet(k) {
+		util.SetExcludePauseContainers(config.Datadog.GetBool(k))
+	}
+
 	// Pull additional parameters from the global config file.
 	if level := config.Datadog.GetString("log_level"); level != "" {
 		a.LogLevel = level
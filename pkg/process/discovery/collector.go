@@ -0,0 +1,224 @@
+package discovery
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	procconfig "github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util/api"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/version"
+	"github.com/DataDog/gopsutil/process"
+)
+
+const (
+	defaultInterval = 4 * time.Hour
+	minInterval     = 10 * time.Minute
+)
+
+var collector *Collector
+
+// Collector periodically collects the processes running on the host and sends them to the process intake.
+type Collector struct {
+	forwarder     forwarder.Forwarder
+	hostname      string
+	interval      time.Duration
+	scrubber      *procconfig.DataScrubber
+	users         *userResolver
+	listProcesses func() (map[int32]*process.FilledProcess, error)
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewCollector returns a new Collector sending the processes with fwd.
+func NewCollector(fwd forwarder.Forwarder, hostname string) *Collector {
+	interval := config.Datadog.GetDuration("process_config.process_discovery.interval")
+	if interval < minInterval {
+		log.Warnf("Invalid process_config.process_discovery.interval %s, it must be at least %s, using the default %s", interval, minInterval, defaultInterval)
+		interval = defaultInterval
+	}
+
+	return &Collector{
+		forwarder:     fwd,
+		hostname:      hostname,
+		interval:      interval,
+		scrubber:      newScrubber(),
+		users:         newUserResolver(),
+		listProcesses: listProcesses,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts the process discovery if enabled and if the live processes are not collected
+// by the process-agent, which already sends the processes to the intake.
+func Start(hostname string) error {
+	if !config.Datadog.GetBool("process_config.process_discovery.enabled") {
+		log.Info("Process discovery disabled")
+		return nil
+	}
+	if isLiveProcessesEnabled() {
+		log.Info("Process discovery disabled as the live processes are collected")
+		return nil
+	}
+	if !supported {
+		log.Info("Process discovery is not supported on this platform")
+		return nil
+	}
+
+	opts := forwarder.NewOptions(keysPerDomains())
+	opts.DisableAPIKeyChecking = true
+	fwd := forwarder.NewDefaultForwarder(opts)
+	if err := fwd.Start(); err != nil {
+		return fmt.Errorf("error starting the process discovery forwarder: %s", err)
+	}
+
+	collector = NewCollector(fwd, hostname)
+	collector.Start()
+	log.Infof("Process discovery started, collecting the processes every %s", collector.interval)
+	return nil
+}
+
+// Stop stops the process discovery.
+func Stop() {
+	if collector != nil {
+		collector.Stop()
+		collector.forwarder.Stop()
+		collector = nil
+	}
+}
+
+// Start starts the collector.
+func (c *Collector) Start() {
+	go c.run()
+}
+
+// Stop stops the collector.
+func (c *Collector) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	c.collect(time.Now())
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-ticker.C:
+			c.collect(now)
+		}
+	}
+}
+
+// collect collects the processes and sends them to the intake.
+func (c *Collector) collect(now time.Time) {
+	procs, err := c.listProcesses()
+	if err != nil {
+		log.Warnf("Could not list the processes: %s", err)
+		return
+	}
+
+	payload, err := json.Marshal(&Payload{
+		Hostname:    c.hostname,
+		CollectedAt: now.Unix(),
+		Processes:   aggregate(procs, c.scrubber, c.users),
+	})
+	if err != nil {
+		log.Errorf("Could not serialize the discovered processes: %s", err)
+		return
+	}
+
+	extra := make(http.Header)
+	extra.Set("Content-Type", "application/json")
+	extra.Set(api.TimestampHeader, strconv.Itoa(int(now.Unix())))
+	extra.Set(api.HostHeader, c.hostname)
+	extra.Set(api.ProcessVersionHeader, version.AgentVersion)
+
+	responses, err := c.forwarder.SubmitProcessDiscoveryChecks(forwarder.Payloads{&payload}, extra)
+	if err != nil {
+		log.Errorf("Unable to submit the discovered processes: %s", err)
+		return
+	}
+	log.Debugf("Sent %d discovered processes", len(procs))
+	if responses != nil {
+		go readResponses(responses)
+	}
+}
+
+func readResponses(responses <-chan forwarder.Response) {
+	for response := range responses {
+		if response.Err != nil {
+			log.Errorf("[process_discovery] Error from %s: %s", response.Domain, response.Err)
+		} else if response.StatusCode >= 300 {
+			log.Errorf("[process_discovery] Invalid response from %s: %d", response.Domain, response.StatusCode)
+		}
+	}
+}
+
+// newScrubber returns a DataScrubber configured like the one of the process-agent.
+func newScrubber() *procconfig.DataScrubber {
+	scrubber := procconfig.NewDefaultDataScrubber()
+	if config.Datadog.IsSet("process_config.scrub_args") {
+		scrubber.Enabled = config.Datadog.GetBool("process_config.scrub_args")
+	}
+	if config.Datadog.IsSet("process_config.custom_sensitive_words") {
+		scrubber.AddCustomSensitiveWords(config.Datadog.GetStringSlice("process_config.custom_sensitive_words"))
+	}
+	if config.Datadog.IsSet("process_config.custom_sensitive_regexps") {
+		scrubber.AddCustomSensitiveRegexps(config.Datadog.GetStringSlice("process_config.custom_sensitive_regexps"))
+	}
+	scrubber.StripAllArguments = config.Datadog.GetBool("process_config.strip_proc_arguments")
+	return scrubber
+}
+
+// isLiveProcessesEnabled returns true if the process-agent collects the live processes.
+func isLiveProcessesEnabled() bool {
+	enabled, _ := strconv.ParseBool(strings.TrimSpace(config.Datadog.GetString("process_config.enabled")))
+	return enabled
+}
+
+// keysPerDomains returns the API keys to use for each process intake, the main one
+// and the additional endpoints of the process-agent.
+func keysPerDomains() map[string][]string {
+	keysPerDomains := make(map[string][]string)
+	mainURL := config.GetMainEndpoint("https://process.", "process_config.process_dd_url")
+	if domain, err := removePath(mainURL); err != nil {
+		log.Errorf("Invalid process_config.process_dd_url %s: %s", mainURL, err)
+	} else {
+		keysPerDomains[domain] = []string{config.SanitizeAPIKey(config.Datadog.GetString("api_key"))}
+	}
+
+	for endpoint, keys := range config.Datadog.GetStringMapStringSlice("process_config.additional_endpoints") {
+		domain, err := removePath(endpoint)
+		if err != nil {
+			log.Errorf("Invalid process_config.additional_endpoints %s: %s", endpoint, err)
+			continue
+		}
+		for _, key := range keys {
+			keysPerDomains[domain] = append(keysPerDomains[domain], config.SanitizeAPIKey(key))
+		}
+	}
+	return keysPerDomains
+}
+
+// removePath removes the path component from the URL if it is present
+func removePath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s://%s", u.Scheme, u.Host), nil
+}
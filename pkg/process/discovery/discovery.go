@@ -0,0 +1,83 @@
+// Package discovery implements a lightweight collection of the processes running on the host,
+// run by the core agent when the live processes are not collected by the process-agent.
+// Only the name, the scrubbed command line and the user of the processes are collected,
+// aggregated by process count, to power the integration recommendations.
+package discovery
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/gopsutil/process"
+)
+
+// Process is a group of processes sharing the same name, command line and user.
+type Process struct {
+	Name    string   `json:"name"`
+	Cmdline []string `json:"cmdline"`
+	User    string   `json:"user,omitempty"`
+	Count   int      `json:"count"`
+}
+
+// Payload is the payload sent to the process intake.
+type Payload struct {
+	Hostname    string     `json:"hostname"`
+	CollectedAt int64      `json:"collected_at"`
+	Processes   []*Process `json:"processes"`
+}
+
+// aggregate groups the processes by name, scrubbed command line and user,
+// the kernel threads, which have neither a command line nor an executable, are ignored.
+func aggregate(procs map[int32]*process.FilledProcess, scrubber *config.DataScrubber, users *userResolver) []*Process {
+	groups := make(map[string]*Process)
+	for _, fp := range procs {
+		name := processName(fp)
+		if name == "" {
+			continue
+		}
+		cmdline := scrubber.ScrubProcessCommand(fp)
+		user := users.lookup(fp)
+
+		key := name + "\x00" + strings.Join(cmdline, " ") + "\x00" + user
+		if p, found := groups[key]; found {
+			p.Count++
+			continue
+		}
+		groups[key] = &Process{
+			Name:    name,
+			Cmdline: cmdline,
+			User:    user,
+			Count:   1,
+		}
+	}
+	scrubber.IncrementCacheAge()
+
+	processes := make([]*Process, 0, len(groups))
+	for _, p := range groups {
+		processes = append(processes, p)
+	}
+	sort.Slice(processes, func(i, j int) bool {
+		if processes[i].Name != processes[j].Name {
+			return processes[i].Name < processes[j].Name
+		}
+		return strings.Join(processes[i].Cmdline, " ") < strings.Join(processes[j].Cmdline, " ")
+	})
+	return processes
+}
+
+// processName returns the name of the binary of the process.
+func processName(fp *process.FilledProcess) string {
+	if len(fp.Cmdline) > 0 {
+		// the whole command line is sometimes in the first argument, and some processes
+		// rewrite it into a title like 'nginx: worker process'
+		if fields := strings.Fields(fp.Cmdline[0]); len(fields) > 0 {
+			return strings.TrimSuffix(filepath.Base(fields[0]), ":")
+		}
+	}
+	if fp.Exe != "" {
+		return filepath.Base(fp.Exe)
+	}
+	return ""
+}
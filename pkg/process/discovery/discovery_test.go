@@ -0,0 +1,80 @@
+// +build !windows
+
+package discovery
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/process/util/api"
+)
+
+func testProcesses() map[int32]*process.FilledProcess {
+	return map[int32]*process.FilledProcess{
+		1: {Pid: 1, Cmdline: []string{"/sbin/init"}, Uids: []int32{0}},
+		2: {Pid: 2},
+		3: {Pid: 3, Cmdline: []string{"nginx: worker process"}, Uids: []int32{1000}},
+		4: {Pid: 4, Cmdline: []string{"nginx: worker process"}, Uids: []int32{1000}},
+		5: {Pid: 5, Cmdline: []string{"mysqld", "--password=secret"}, Uids: []int32{1000}},
+		6: {Pid: 6, Exe: "/usr/bin/redis-server", Uids: []int32{1000}},
+	}
+}
+
+func testUserResolver() *userResolver {
+	return &userResolver{names: map[int32]string{0: "root", 1000: "dd"}}
+}
+
+func TestAggregate(t *testing.T) {
+	processes := aggregate(testProcesses(), config.NewDefaultDataScrubber(), testUserResolver())
+	assert.Equal(t, []*Process{
+		{Name: "init", Cmdline: []string{"/sbin/init"}, User: "root", Count: 1},
+		{Name: "mysqld", Cmdline: []string{"mysqld", "--password=********"}, User: "dd", Count: 1},
+		{Name: "nginx", Cmdline: []string{"nginx: worker process"}, User: "dd", Count: 2},
+		{Name: "redis-server", User: "dd", Count: 1},
+	}, processes)
+}
+
+func TestAggregateStripArguments(t *testing.T) {
+	scrubber := config.NewDefaultDataScrubber()
+	scrubber.StripAllArguments = true
+	processes := aggregate(testProcesses(), scrubber, testUserResolver())
+	require.Len(t, processes, 4)
+	assert.Equal(t, []string{"mysqld"}, processes[1].Cmdline)
+	assert.Equal(t, []string{"nginx:"}, processes[2].Cmdline)
+}
+
+func TestCollect(t *testing.T) {
+	fwd := &forwarder.MockedForwarder{}
+	c := &Collector{
+		forwarder: fwd,
+		hostname:  "myhost",
+		scrubber:  config.NewDefaultDataScrubber(),
+		users:     testUserResolver(),
+		listProcesses: func() (map[int32]*process.FilledProcess, error) {
+			return testProcesses(), nil
+		},
+	}
+
+	var payload Payload
+	fwd.On("SubmitProcessDiscoveryChecks", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		payloads := args.Get(0).(forwarder.Payloads)
+		require.NoError(t, json.Unmarshal(*payloads[0], &payload))
+		assert.Equal(t, "myhost", args.Get(1).(http.Header).Get(api.HostHeader))
+	})
+
+	now := time.Now()
+	c.collect(now)
+	fwd.AssertNumberOfCalls(t, "SubmitProcessDiscoveryChecks", 1)
+	assert.Equal(t, "myhost", payload.Hostname)
+	assert.Equal(t, now.Unix(), payload.CollectedAt)
+	assert.Len(t, payload.Processes, 4)
+}
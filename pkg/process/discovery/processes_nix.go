@@ -0,0 +1,14 @@
+// +build !windows
+
+package discovery
+
+import (
+	"github.com/DataDog/gopsutil/process"
+)
+
+// supported is true if the processes can be listed on this platform
+const supported = true
+
+func listProcesses() (map[int32]*process.FilledProcess, error) {
+	return process.AllProcesses()
+}
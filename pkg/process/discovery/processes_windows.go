@@ -0,0 +1,16 @@
+// +build windows
+
+package discovery
+
+import (
+	"errors"
+
+	"github.com/DataDog/gopsutil/process"
+)
+
+// supported is true if the processes can be listed on this platform
+const supported = false
+
+func listProcesses() (map[int32]*process.FilledProcess, error) {
+	return nil, errors.New("process discovery is not supported on Windows")
+}
@@ -0,0 +1,36 @@
+// +build !windows
+
+package discovery
+
+import (
+	"os/user"
+	"strconv"
+
+	"github.com/DataDog/gopsutil/process"
+)
+
+// userResolver resolves the names of the users running the processes,
+// the names are cached for the lifetime of the resolver.
+type userResolver struct {
+	names map[int32]string
+}
+
+func newUserResolver() *userResolver {
+	return &userResolver{names: make(map[int32]string)}
+}
+
+func (r *userResolver) lookup(fp *process.FilledProcess) string {
+	if len(fp.Uids) == 0 {
+		return ""
+	}
+	uid := fp.Uids[0]
+	if name, found := r.names[uid]; found {
+		return name
+	}
+	name := strconv.Itoa(int(uid))
+	if u, err := user.LookupId(name); err == nil {
+		name = u.Username
+	}
+	r.names[uid] = name
+	return name
+}
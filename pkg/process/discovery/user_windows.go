@@ -0,0 +1,18 @@
+// +build windows
+
+package discovery
+
+import (
+	"github.com/DataDog/gopsutil/process"
+)
+
+// userResolver returns the names of the users running the processes.
+type userResolver struct{}
+
+func newUserResolver() *userResolver {
+	return &userResolver{}
+}
+
+func (r *userResolver) lookup(fp *process.FilledProcess) string {
+	return fp.Username
+}
@@ -11,6 +11,10 @@ const (
 	statusURL      = "http://unix/status"
 	connectionsURL = "http://unix/connections"
 	statsURL       = "http://unix/debug/stats"
+	tracerouteURL  = "http://unix/traceroute"
+	servicesURL    = "http://unix/services"
+	dnsDomainsURL  = "http://unix/dns/domains"
+	debugURL       = "http://unix/debug"
 	netType        = "unix"
 )
 
@@ -5,6 +5,8 @@ package net
 import (
 	model "github.com/DataDog/agent-payload/process"
 	"github.com/DataDog/datadog-agent/pkg/ebpf"
+	"github.com/DataDog/datadog-agent/pkg/network"
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
 )
 
 // RemoteSysProbeUtil is not supported
@@ -34,3 +36,28 @@ func (r *RemoteSysProbeUtil) GetConnections(clientID string) (*model.Connections
 func (r *RemoteSysProbeUtil) GetStats() (map[string]interface{}, error) {
 	return nil, ebpf.ErrNotImplemented
 }
+
+// GetTraceroutePaths is not supported
+func (r *RemoteSysProbeUtil) GetTraceroutePaths() ([]*traceroute.Path, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// RunTraceroute is not supported
+func (r *RemoteSysProbeUtil) RunTraceroute(dest traceroute.Destination) (*traceroute.Path, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetServiceStats is not supported
+func (r *RemoteSysProbeUtil) GetServiceStats() ([]network.Service, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetDNSDomainStats is not supported
+func (r *RemoteSysProbeUtil) GetDNSDomainStats(clientID string) ([]network.DNSDomainStats, error) {
+	return nil, ebpf.ErrNotImplemented
+}
+
+// GetDebugState is not supported
+func (r *RemoteSysProbeUtil) GetDebugState(name string) (map[string]interface{}, error) {
+	return nil, ebpf.ErrNotImplemented
+}
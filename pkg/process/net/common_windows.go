@@ -8,6 +8,10 @@ const (
 	statusURL      = "http://localhost:3333/status"
 	connectionsURL = "http://localhost:3333/connections"
 	statsURL       = "http://localhost:3333/debug/stats"
+	tracerouteURL  = "http://localhost:3333/traceroute"
+	servicesURL    = "http://localhost:3333/services"
+	dnsDomainsURL  = "http://localhost:3333/dns/domains"
+	debugURL       = "http://localhost:3333/debug"
 	netType        = "tcp"
 )
 
@@ -0,0 +1,13 @@
+// +build linux windows
+
+package net
+
+// GetDebugState returns the state dumped by a debug endpoint of the system probe, e.g. conntrack
+// for /debug/conntrack
+func (r *RemoteSysProbeUtil) GetDebugState(name string) (map[string]interface{}, error) {
+	state := make(map[string]interface{})
+	if err := r.getJSON(&r.httpClient, debugURL+"/"+name, &state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
@@ -0,0 +1,19 @@
+// +build linux windows
+
+package net
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/network"
+)
+
+// GetDNSDomainStats returns the DNS stats of the connections returned to the client since the last call,
+// aggregated by process and queried domain
+func (r *RemoteSysProbeUtil) GetDNSDomainStats(clientID string) ([]network.DNSDomainStats, error) {
+	var stats []network.DNSDomainStats
+	if err := r.getJSON(&r.httpClient, fmt.Sprintf("%s?client_id=%s", dnsDomainsURL, clientID), &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
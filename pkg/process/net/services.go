@@ -0,0 +1,14 @@
+// +build linux windows
+
+package net
+
+import "github.com/DataDog/datadog-agent/pkg/network"
+
+// GetServiceStats returns the requests stats aggregated per service by the system probe since the last call
+func (r *RemoteSysProbeUtil) GetServiceStats() ([]network.Service, error) {
+	var services []network.Service
+	if err := r.getJSON(&r.httpClient, servicesURL, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
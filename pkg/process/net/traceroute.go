@@ -0,0 +1,73 @@
+// +build linux windows
+
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/network/traceroute"
+)
+
+// tracerouteTimeout bounds the duration of an on-demand traceroute, the probes
+// of all the hops being sent at once it lasts about the traceroute timeout
+const tracerouteTimeout = 30 * time.Second
+
+// GetTraceroutePaths returns the paths traced by the system probe since the last call
+func (r *RemoteSysProbeUtil) GetTraceroutePaths() ([]*traceroute.Path, error) {
+	var paths []*traceroute.Path
+	if err := r.getJSON(&r.httpClient, tracerouteURL+"/paths", &paths); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// RunTraceroute runs a traceroute to the destination from the system probe
+func (r *RemoteSysProbeUtil) RunTraceroute(dest traceroute.Destination) (*traceroute.Path, error) {
+	query := url.Values{}
+	query.Set("host", dest.Host)
+	query.Set("protocol", dest.Protocol)
+	if dest.Port > 0 {
+		query.Set("port", strconv.Itoa(dest.Port))
+	}
+
+	// the default client does not wait long enough for the response
+	client := http.Client{
+		Timeout: tracerouteTimeout,
+		Transport: &http.Transport{
+			DialContext: func(_ context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial(netType, globalSocketPath)
+			},
+		},
+	}
+
+	path := &traceroute.Path{}
+	if err := r.getJSON(&client, tracerouteURL+"?"+query.Encode(), path); err != nil {
+		return nil, err
+	}
+	return path, nil
+}
+
+func (r *RemoteSysProbeUtil) getJSON(client *http.Client, endpoint string, v interface{}) error {
+	resp, err := client.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("request failed: socket %s, url %s, status code: %d, %s", r.path, endpoint, resp.StatusCode, body)
+	}
+	return json.Unmarshal(body, v)
+}
@@ -13,6 +13,7 @@ var (
 	containerCacheDuration = 10 * time.Second
 	detectors              []*collectors.Detector
 	dedupe                 = false
+	pauseFilter            *containers.Filter
 )
 
 // SetContainerSources allows config to force one or multiple container sources
@@ -24,6 +25,21 @@ func SetContainerSources(names []string) {
 	dedupe = len(detectors) > 1
 }
 
+// SetExcludePauseContainers allows config to exclude the pause containers from the live containers,
+// even when the exclude_pause_container option keeps them for the other checks
+func SetExcludePauseContainers(exclude bool) {
+	if !exclude {
+		pauseFilter = nil
+		return
+	}
+	filter, err := containers.NewPauseContainerFilter()
+	if err != nil {
+		log.Errorf("Cannot exclude the pause containers: %s", err)
+		return
+	}
+	pauseFilter = filter
+}
+
 // GetContainers returns containers found on the machine
 // GetContainers autodetects the best backend from available sources
 // if the users don't specify the preferred container sources
@@ -69,12 +85,27 @@ func GetContainers() ([]*containers.Container, error) {
 		result = append(result, containers...)
 	}
 
+	if pauseFilter != nil {
+		result = filterPauseContainers(result)
+	}
 	if dedupe {
 		return dedupeContainers(result), nil
 	}
 	return result, nil
 }
 
+// filterPauseContainers removes the pause containers from the list
+func filterPauseContainers(ctrList []*containers.Container) []*containers.Container {
+	filtered := make([]*containers.Container, 0, len(ctrList))
+	for _, ctr := range ctrList {
+		if pauseFilter.IsExcluded(ctr.Name, ctr.Image, "") {
+			continue
+		}
+		filtered = append(filtered, ctr)
+	}
+	return filtered
+}
+
 // ExtractContainerRateMetric extracts relevant rate values from a container list
 // for later reuse, while reducing memory usage to only the needed fields
 func ExtractContainerRateMetric(containers []*containers.Container) map[string]ContainerRateMetrics {
@@ -62,3 +62,15 @@ func Test_dedupeContainers(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterPauseContainers(t *testing.T) {
+	SetExcludePauseContainers(true)
+	defer SetExcludePauseContainers(false)
+
+	ctrs := []*containers.Container{
+		{ID: "ctr1", Image: "redis:latest"},
+		{ID: "ctr2", Image: "k8s.gcr.io/pause-amd64:3.1"},
+		{ID: "ctr3", Image: "nginx:latest"},
+	}
+	assert.Equal(t, []*containers.Container{ctrs[0], ctrs[2]}, filterPauseContainers(ctrs))
+}
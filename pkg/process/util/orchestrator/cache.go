@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build orchestrator
+
+package orchestrator
+
+import (
+	"time"
+
+	"github.com/patrickmn/go-cache"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// a resource is extracted again at least once per defaultExpire, even if it did not change
+	defaultExpire = 2 * time.Minute
+	defaultPurge  = 5 * time.Minute
+)
+
+// KubernetesResourceCache keeps the last message extracted for each kubernetes resource UID,
+// so that the unchanged resources are not scrubbed and marshalled again at every collection.
+// Every collection still sends all the resources: the intake treats it as a full snapshot.
+type KubernetesResourceCache struct {
+	cache *cache.Cache
+}
+
+type cachedResource struct {
+	resourceVersion string
+	message         interface{}
+}
+
+// NewKubernetesResourceCache returns a new empty KubernetesResourceCache.
+func NewKubernetesResourceCache() *KubernetesResourceCache {
+	return &KubernetesResourceCache{
+		cache: cache.New(defaultExpire, defaultPurge),
+	}
+}
+
+// Get returns the message extracted for the resource with the given UID if it was extracted
+// with the same resourceVersion, resources without a resourceVersion are never cached.
+// A nil cache never returns a message.
+func (c *KubernetesResourceCache) Get(uid types.UID, resourceVersion string) (interface{}, bool) {
+	if c == nil || resourceVersion == "" {
+		return nil, false
+	}
+	value, hit := c.cache.Get(string(uid))
+	if !hit {
+		return nil, false
+	}
+	cached := value.(cachedResource)
+	if cached.resourceVersion != resourceVersion {
+		return nil, false
+	}
+	return cached.message, true
+}
+
+// Set records the message extracted for the resource with the given UID and resourceVersion.
+func (c *KubernetesResourceCache) Set(uid types.UID, resourceVersion string, message interface{}) {
+	if c == nil || resourceVersion == "" {
+		return
+	}
+	c.cache.Set(string(uid), cachedResource{resourceVersion: resourceVersion, message: message}, cache.DefaultExpiration)
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build orchestrator
+
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestKubernetesResourceCache(t *testing.T) {
+	c := NewKubernetesResourceCache()
+	uid := types.UID("e42e5adc-0749-11e8-a2b8-000c29dea4f6")
+
+	_, found := c.Get(uid, "1000")
+	assert.False(t, found)
+
+	c.Set(uid, "1000", "v1000")
+	msg, found := c.Get(uid, "1000")
+	assert.True(t, found)
+	assert.Equal(t, "v1000", msg)
+
+	// a new resourceVersion invalidates the message
+	_, found = c.Get(uid, "1001")
+	assert.False(t, found)
+	c.Set(uid, "1001", "v1001")
+	msg, found = c.Get(uid, "1001")
+	assert.True(t, found)
+	assert.Equal(t, "v1001", msg)
+
+	// resources without a resourceVersion are never cached
+	c.Set(uid, "", "none")
+	_, found = c.Get(uid, "")
+	assert.False(t, found)
+
+	// a nil cache is disabled
+	var disabled *KubernetesResourceCache
+	disabled.Set(uid, "1000", "v1000")
+	_, found = disabled.Get(uid, "1000")
+	assert.False(t, found)
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build orchestrator
+
+package orchestrator
+
+import (
+	model "github.com/DataDog/agent-payload/process"
+
+	jsoniter "github.com/json-iterator/go"
+	yaml "gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// extractMetadata extracts the kubernetes object metadata into the proto model
+func extractMetadata(m *metav1.ObjectMeta) *model.Metadata {
+	meta := model.Metadata{
+		Name:      m.Name,
+		Namespace: m.Namespace,
+		Uid:       string(m.UID),
+	}
+	if !m.CreationTimestamp.IsZero() {
+		meta.CreationTimestamp = m.CreationTimestamp.Unix()
+	}
+	if !m.DeletionTimestamp.IsZero() {
+		meta.DeletionTimestamp = m.DeletionTimestamp.Unix()
+	}
+	if len(m.Annotations) > 0 {
+		meta.Annotations = make([]string, len(m.Annotations))
+		i := 0
+		for k, v := range m.Annotations {
+			meta.Annotations[i] = k + ":" + v
+			i++
+		}
+	}
+	if len(m.Labels) > 0 {
+		meta.Labels = make([]string, len(m.Labels))
+		i := 0
+		for k, v := range m.Labels {
+			meta.Labels[i] = k + ":" + v
+			i++
+		}
+	}
+	for _, o := range m.OwnerReferences {
+		owner := model.OwnerReference{
+			Name: o.Name,
+			Uid:  string(o.UID),
+			Kind: o.Kind,
+		}
+		meta.OwnerReferences = append(meta.OwnerReferences, &owner)
+	}
+	return &meta
+}
+
+// toYaml marshals a kubernetes object to YAML
+func toYaml(obj interface{}) ([]byte, error) {
+	// k8s objects only have json "omitempty" annotations
+	// we're doing json<>yaml to get rid of the null properties
+	jsonObj, err := jsoniter.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var yamlObj interface{}
+	yaml.Unmarshal(jsonObj, &yamlObj) //nolint:errcheck
+	return yaml.Marshal(yamlObj)
+}
+
+// computeGroupSize returns the number of messages needed to send the given number of items
+func computeGroupSize(items, perMessage int) int {
+	groupSize := items / perMessage
+	if items%perMessage != 0 {
+		groupSize++
+	}
+	return groupSize
+}
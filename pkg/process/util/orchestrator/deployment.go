@@ -0,0 +1,157 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build orchestrator
+
+package orchestrator
+
+import (
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ProcessDeploymentList processes a deployment list into process messages, all the deployments
+// of the list are part of the messages. The deployments which did not change since they were
+// last processed are taken from the resourceCache, a nil resourceCache extracts all of them.
+func ProcessDeploymentList(deploymentList []*v1.Deployment, groupID int32, cfg *config.AgentConfig, clusterName string, clusterID string, resourceCache *KubernetesResourceCache) ([]model.MessageBody, error) {
+	start := time.Now()
+	deployMsgs := make([]*model.Deployment, 0, len(deploymentList))
+
+	for d := 0; d < len(deploymentList); d++ {
+		if cached, found := resourceCache.Get(deploymentList[d].UID, deploymentList[d].ResourceVersion); found {
+			deployMsgs = append(deployMsgs, cached.(*model.Deployment))
+			continue
+		}
+
+		// the objects are shared with the informer cache, work on a copy
+		depl := deploymentList[d].DeepCopy()
+
+		// extract deployment info
+		deployModel := extractDeployment(depl)
+
+		// scrub & generate YAML
+		scrubPodSpec(&depl.Spec.Template.Spec, cfg)
+		yamlDeploy, err := toYaml(depl)
+		if err != nil {
+			log.Debugf("Could not marshal deployment in JSON: %s", err)
+			continue
+		}
+		deployModel.Yaml = yamlDeploy
+		resourceCache.Set(depl.UID, depl.ResourceVersion, deployModel)
+
+		deployMsgs = append(deployMsgs, deployModel)
+	}
+
+	groupSize := computeGroupSize(len(deployMsgs), cfg.MaxPerMessage)
+	chunked := chunkDeployments(deployMsgs, groupSize, cfg.MaxPerMessage)
+	messages := make([]model.MessageBody, 0, groupSize)
+	for i := 0; i < groupSize; i++ {
+		messages = append(messages, &model.CollectorDeployment{
+			ClusterName: clusterName,
+			Deployments: chunked[i],
+			GroupId:     groupID,
+			GroupSize:   int32(groupSize),
+			ClusterId:   clusterID,
+		})
+	}
+
+	log.Debugf("Collected & enriched %d deployments in %s", len(deployMsgs), time.Now().Sub(start))
+	return messages, nil
+}
+
+// chunkDeployments formats and chunks the deployments into a slice of chunks using a specific number of chunks.
+func chunkDeployments(deploys []*model.Deployment, chunks, perChunk int) [][]*model.Deployment {
+	chunked := make([][]*model.Deployment, 0, chunks)
+	chunk := make([]*model.Deployment, 0, perChunk)
+
+	for _, d := range deploys {
+		chunk = append(chunk, d)
+		if len(chunk) == perChunk {
+			chunked = append(chunked, chunk)
+			chunk = make([]*model.Deployment, 0, perChunk)
+		}
+	}
+	if len(chunk) > 0 {
+		chunked = append(chunked, chunk)
+	}
+	return chunked
+}
+
+// extractDeployment extracts deployment info into the proto model
+func extractDeployment(d *v1.Deployment) *model.Deployment {
+	deploy := model.Deployment{
+		Metadata: extractMetadata(&d.ObjectMeta),
+	}
+	// spec
+	deploy.ReplicasDesired = 1 // default
+	if d.Spec.Replicas != nil {
+		deploy.ReplicasDesired = *d.Spec.Replicas
+	}
+	deploy.Paused = d.Spec.Paused
+	deploy.DeploymentStrategy = string(d.Spec.Strategy.Type)
+	if d.Spec.Strategy.RollingUpdate != nil {
+		if d.Spec.Strategy.RollingUpdate.MaxUnavailable != nil {
+			deploy.MaxUnavailable = d.Spec.Strategy.RollingUpdate.MaxUnavailable.String()
+		}
+		if d.Spec.Strategy.RollingUpdate.MaxSurge != nil {
+			deploy.MaxSurge = d.Spec.Strategy.RollingUpdate.MaxSurge.String()
+		}
+	}
+	if d.Spec.Selector != nil {
+		for _, s := range d.Spec.Selector.MatchExpressions {
+			deploy.Selectors = append(deploy.Selectors, &model.LabelSelectorRequirement{
+				Key:      s.Key,
+				Operator: string(s.Operator),
+				Values:   s.Values,
+			})
+		}
+	}
+
+	// status
+	deploy.Replicas = d.Status.Replicas
+	deploy.UpdatedReplicas = d.Status.UpdatedReplicas
+	deploy.ReadyReplicas = d.Status.ReadyReplicas
+	deploy.AvailableReplicas = d.Status.AvailableReplicas
+	deploy.UnavailableReplicas = d.Status.UnavailableReplicas
+	deploy.ConditionMessage = extractDeploymentConditionMessage(d.Status.Conditions)
+
+	return &deploy
+}
+
+// extractDeploymentConditionMessage reports the message of the first failing
+// condition, checking ReplicaFailure before Progressing and Available
+func extractDeploymentConditionMessage(conditions []v1.DeploymentCondition) string {
+	messageMap := make(map[v1.DeploymentConditionType]string)
+
+	// populate messageMap with messages for non-passing conditions,
+	// ReplicaFailure is the only condition that is failing when true
+	for _, c := range conditions {
+		failing := c.Status == corev1.ConditionFalse
+		if c.Type == v1.DeploymentReplicaFailure {
+			failing = c.Status == corev1.ConditionTrue
+		}
+		if failing && c.Message != "" {
+			messageMap[c.Type] = c.Message
+		}
+	}
+
+	// most severe first
+	for _, c := range []v1.DeploymentConditionType{
+		v1.DeploymentReplicaFailure,
+		v1.DeploymentProgressing,
+		v1.DeploymentAvailable,
+	} {
+		if m := messageMap[c]; m != "" {
+			return m
+		}
+	}
+	return ""
+}
@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build orchestrator
+
+package orchestrator
+
+import (
+	"testing"
+	"time"
+
+	model "github.com/DataDog/agent-payload/process"
+	"github.com/DataDog/datadog-agent/pkg/process/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+func newDeployment(uid, resourceVersion string) *v1.Deployment {
+	replicas := int32(3)
+	maxUnavailable := intstr.FromString("25%")
+	maxSurge := intstr.FromInt(1)
+	return &v1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "deploy",
+			Namespace:         "namespace",
+			UID:               types.UID(uid),
+			ResourceVersion:   resourceVersion,
+			CreationTimestamp: metav1.NewTime(time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC)),
+			Labels:            map[string]string{"app": "my-app"},
+		},
+		Spec: v1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "app", Operator: metav1.LabelSelectorOpIn, Values: []string{"my-app"}},
+				},
+			},
+			Strategy: v1.DeploymentStrategy{
+				Type: v1.RollingUpdateDeploymentStrategyType,
+				RollingUpdate: &v1.RollingUpdateDeployment{
+					MaxUnavailable: &maxUnavailable,
+					MaxSurge:       &maxSurge,
+				},
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name: "app",
+							Env:  []corev1.EnvVar{{Name: "password", Value: "kqhkiG9w0BAQEFAASCAl8wggJbAgEAAoGBAOLJ"}},
+						},
+					},
+				},
+			},
+		},
+		Status: v1.DeploymentStatus{
+			Replicas:            3,
+			UpdatedReplicas:     2,
+			ReadyReplicas:       2,
+			AvailableReplicas:   2,
+			UnavailableReplicas: 1,
+			Conditions: []v1.DeploymentCondition{
+				{Type: v1.DeploymentAvailable, Status: corev1.ConditionFalse, Message: "not enough replicas"},
+				{Type: v1.DeploymentReplicaFailure, Status: corev1.ConditionTrue, Message: "quota exceeded"},
+			},
+		},
+	}
+}
+
+func TestExtractDeployment(t *testing.T) {
+	d := newDeployment("e42e5adc-0749-11e8-a2b8-000c29dea4f6", "1000")
+	assert.Equal(t, &model.Deployment{
+		Metadata: &model.Metadata{
+			Name:              "deploy",
+			Namespace:         "namespace",
+			Uid:               "e42e5adc-0749-11e8-a2b8-000c29dea4f6",
+			CreationTimestamp: 1591005600,
+			Labels:            []string{"app:my-app"},
+		},
+		ReplicasDesired:    3,
+		DeploymentStrategy: "RollingUpdate",
+		MaxUnavailable:     "25%",
+		MaxSurge:           "1",
+		Selectors: []*model.LabelSelectorRequirement{
+			{Key: "app", Operator: "In", Values: []string{"my-app"}},
+		},
+		Replicas:            3,
+		UpdatedReplicas:     2,
+		ReadyReplicas:       2,
+		AvailableReplicas:   2,
+		UnavailableReplicas: 1,
+		ConditionMessage:    "quota exceeded",
+	}, extractDeployment(d))
+}
+
+func TestProcessDeploymentList(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig(true)
+	cfg.MaxPerMessage = 1
+	d := newDeployment("e42e5adc-0749-11e8-a2b8-000c29dea4f6", "1000")
+
+	msgs, err := ProcessDeploymentList([]*v1.Deployment{d}, 1, cfg, "cluster", "cluster-id", nil)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	collector := msgs[0].(*model.CollectorDeployment)
+	require.Len(t, collector.Deployments, 1)
+	assert.Equal(t, int32(1), collector.GroupSize)
+	assert.Contains(t, string(collector.Deployments[0].Yaml), redactedValue)
+	assert.NotContains(t, string(collector.Deployments[0].Yaml), "kqhkiG9w0BAQEFAASCAl8wggJbAgEAAoGBAOLJ")
+	// the informer object must not be scrubbed
+	assert.Equal(t, "kqhkiG9w0BAQEFAASCAl8wggJbAgEAAoGBAOLJ", d.Spec.Template.Spec.Containers[0].Env[0].Value)
+}
+
+func TestProcessDeploymentListCache(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig(true)
+	resourceCache := NewKubernetesResourceCache()
+	d1 := newDeployment("1", "1000")
+	d2 := newDeployment("2", "1000")
+
+	msgs, err := ProcessDeploymentList([]*v1.Deployment{d1, d2}, 1, cfg, "cluster", "cluster-id", resourceCache)
+	require.NoError(t, err)
+	first := msgs[0].(*model.CollectorDeployment).Deployments
+	require.Len(t, first, 2)
+
+	// unchanged deployments are still sent, the extracted messages are reused
+	d2 = newDeployment("2", "1001")
+	d2.Status.ReadyReplicas = 3
+	msgs, err = ProcessDeploymentList([]*v1.Deployment{d1, d2}, 2, cfg, "cluster", "cluster-id", resourceCache)
+	require.NoError(t, err)
+	second := msgs[0].(*model.CollectorDeployment).Deployments
+	require.Len(t, second, 2)
+	assert.Same(t, first[0], second[0])
+	assert.NotSame(t, first[1], second[1])
+	assert.Equal(t, int32(3), second[1].ReadyReplicas)
+}
+
+func TestChunkDeployments(t *testing.T) {
+	deploys := []*model.Deployment{
+		{Metadata: &model.Metadata{Uid: "1"}},
+		{Metadata: &model.Metadata{Uid: "2"}},
+		{Metadata: &model.Metadata{Uid: "3"}},
+	}
+	expected := [][]*model.Deployment{
+		{{Metadata: &model.Metadata{Uid: "1"}}, {Metadata: &model.Metadata{Uid: "2"}}},
+		{{Metadata: &model.Metadata{Uid: "3"}}},
+	}
+	assert.ElementsMatch(t, expected, chunkDeployments(deploys, computeGroupSize(len(deploys), 2), 2))
+}
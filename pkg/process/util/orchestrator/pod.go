@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"hash/fnv"
 	"strconv"
+	"strings"
 	"time"
 
 	model "github.com/DataDog/agent-payload/process"
@@ -20,8 +21,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/kubelet"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
-	jsoniter "github.com/json-iterator/go"
-	yaml "gopkg.in/yaml.v2"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubernetes/pkg/kubelet/pod"
@@ -57,31 +56,18 @@ func ProcessPodlist(podList []*v1.Pod, groupID int32, cfg *config.AgentConfig, h
 		}
 
 		// scrub & generate YAML
-		for c := 0; c < len(podList[p].Spec.Containers); c++ {
-			scrubContainer(&podList[p].Spec.Containers[c], cfg)
-		}
-		for c := 0; c < len(podList[p].Spec.InitContainers); c++ {
-			scrubContainer(&podList[p].Spec.InitContainers[c], cfg)
-		}
-		// k8s objects only have json "omitempty" annotations
-		// we're doing json<>yaml to get rid of the null properties
-		jsonPod, err := jsoniter.Marshal(podList[p])
+		scrubPodSpec(&podList[p].Spec, cfg)
+		yamlPod, err := toYaml(podList[p])
 		if err != nil {
 			log.Debugf("Could not marshal pod in JSON: %s", err)
 			continue
 		}
-		var jsonObj interface{}
-		yaml.Unmarshal(jsonPod, &jsonObj) //nolint:errcheck
-		yamlPod, _ := yaml.Marshal(jsonObj)
 		podModel.Yaml = yamlPod
 
 		podMsgs = append(podMsgs, podModel)
 	}
 
-	groupSize := len(podMsgs) / cfg.MaxPerMessage
-	if len(podMsgs)%cfg.MaxPerMessage != 0 {
-		groupSize++
-	}
+	groupSize := computeGroupSize(len(podMsgs), cfg.MaxPerMessage)
 	chunked := chunkPods(podMsgs, groupSize, cfg.MaxPerMessage)
 	messages := make([]model.MessageBody, 0, groupSize)
 	for i := 0; i < groupSize; i++ {
@@ -99,11 +85,20 @@ func ProcessPodlist(podList []*v1.Pod, groupID int32, cfg *config.AgentConfig, h
 	return messages, nil
 }
 
+// scrubPodSpec scrubs sensitive information in the containers of a pod spec
+func scrubPodSpec(spec *v1.PodSpec, cfg *config.AgentConfig) {
+	for c := 0; c < len(spec.Containers); c++ {
+		scrubContainer(&spec.Containers[c], cfg)
+	}
+	for c := 0; c < len(spec.InitContainers); c++ {
+		scrubContainer(&spec.InitContainers[c], cfg)
+	}
+}
+
 // scrubContainer scrubs sensitive information in the command line & env vars
 func scrubContainer(c *v1.Container, cfg *config.AgentConfig) {
 	// scrub command line
-	scrubbedCmd, _ := cfg.Scrubber.ScrubCommand(c.Command)
-	c.Command = scrubbedCmd
+	scrubContainerCommand(c, cfg.Scrubber)
 	// scrub env vars
 	for e := 0; e < len(c.Env); e++ {
 		// use the "key: value" format to work with the regular credential cleaner
@@ -115,6 +110,69 @@ func scrubContainer(c *v1.Container, cfg *config.AgentConfig) {
 	}
 }
 
+// scrubContainerCommand scrubs the command and the arguments of a container together, as the value
+// of a sensitive argument of the command may be in the arguments
+func scrubContainerCommand(c *v1.Container, scrubber *config.DataScrubber) {
+	if scrubber.StripAllArguments {
+		// without command, the binary is the entrypoint of the image and all the arguments are stripped
+		if len(c.Command) > 0 {
+			c.Command = scrubber.StripArguments(c.Command)
+		}
+		c.Args = nil
+		return
+	}
+
+	cmdline := make([]string, 0, len(c.Command)+len(c.Args))
+	cmdline = append(cmdline, c.Command...)
+	cmdline = append(cmdline, c.Args...)
+	if !scrubCmdline(cmdline, scrubber) {
+		return
+	}
+
+	if len(c.Command) > 0 {
+		c.Command = cmdline[:len(c.Command)]
+	}
+	if len(c.Args) > 0 {
+		c.Args = cmdline[len(c.Command):]
+	}
+}
+
+// scrubCmdline scrubs the elements of a command line in place and returns true if any was changed.
+// An element containing spaces is scrubbed as a whole so that the command line keeps its length,
+// the value of a sensitive argument is either in the same element or in the next one.
+func scrubCmdline(cmdline []string, scrubber *config.DataScrubber) bool {
+	changed := false
+	for i := 0; i < len(cmdline); i++ {
+		if scrubbed, ok := scrubCmdlineElement(cmdline[i], scrubber); ok {
+			cmdline[i] = scrubbed
+			changed = true
+		}
+		if i+1 < len(cmdline) && endsWithSensitiveArgument(cmdline[i], scrubber) {
+			cmdline[i+1] = redactedValue
+			changed = true
+			i++
+		}
+	}
+	return changed
+}
+
+// scrubCmdlineElement scrubs the values of the sensitive arguments contained in a single element,
+// the leading space makes its first argument scrubbable.
+func scrubCmdlineElement(element string, scrubber *config.DataScrubber) (string, bool) {
+	scrubbed, changed := scrubber.ScrubCommand([]string{"", element})
+	if !changed {
+		return element, false
+	}
+	return strings.Join(scrubbed, " ")[1:], true
+}
+
+// endsWithSensitiveArgument returns true if the element ends with the name of a sensitive argument
+// without its value, in which case the value is the next element of the command line.
+func endsWithSensitiveArgument(element string, scrubber *config.DataScrubber) bool {
+	scrubbed, changed := scrubber.ScrubCommand([]string{"", element, "value"})
+	return changed && scrubbed[len(scrubbed)-1] == redactedValue
+}
+
 // chunkPods formats and chunks the pods into a slice of chunks using a specific number of chunks.
 func chunkPods(pods []*model.Pod, chunks, perChunk int) [][]*model.Pod {
 	chunked := make([][]*model.Pod, 0, chunks)
@@ -135,44 +193,8 @@ func chunkPods(pods []*model.Pod, chunks, perChunk int) [][]*model.Pod {
 
 // extractPodMessage extracts pod info into the proto model
 func extractPodMessage(p *v1.Pod) *model.Pod {
-	// pod medatadata
-	podMetadata := model.Metadata{
-		Name:      p.Name,
-		Namespace: p.Namespace,
-		Uid:       string(p.UID),
-	}
-	if !p.ObjectMeta.CreationTimestamp.IsZero() {
-		podMetadata.CreationTimestamp = p.ObjectMeta.CreationTimestamp.Unix()
-	}
-	if !p.ObjectMeta.DeletionTimestamp.IsZero() {
-		podMetadata.DeletionTimestamp = p.ObjectMeta.DeletionTimestamp.Unix()
-	}
-	if len(p.Annotations) > 0 {
-		podMetadata.Annotations = make([]string, len(p.Annotations))
-		i := 0
-		for k, v := range p.Annotations {
-			podMetadata.Annotations[i] = k + ":" + v
-			i++
-		}
-	}
-	if len(p.Labels) > 0 {
-		podMetadata.Labels = make([]string, len(p.Labels))
-		i := 0
-		for k, v := range p.Labels {
-			podMetadata.Labels[i] = k + ":" + v
-			i++
-		}
-	}
-	for _, o := range p.OwnerReferences {
-		owner := model.OwnerReference{
-			Name: o.Name,
-			Uid:  string(o.UID),
-			Kind: o.Kind,
-		}
-		podMetadata.OwnerReferences = append(podMetadata.OwnerReferences, &owner)
-	}
 	podModel := model.Pod{
-		Metadata: &podMetadata,
+		Metadata: extractMetadata(&p.ObjectMeta),
 	}
 	// pod spec
 	podModel.NodeName = p.Spec.NodeName
@@ -531,6 +531,54 @@ func TestScrubContainer(t *testing.T) {
 				},
 			},
 		},
+		"sensitive args": {
+			input: v1.Container{
+				Command: []string{"mysql"},
+				Args:    []string{"--user", "root", "--password=afztyerbzio1234"},
+			},
+			expected: v1.Container{
+				Command: []string{"mysql"},
+				Args:    []string{"--user", "root", "--password=********"},
+			},
+		},
+		"sensitive argument value in args": {
+			input: v1.Container{
+				Command: []string{"mysql", "--password"},
+				Args:    []string{"afztyerbzio1234"},
+			},
+			expected: v1.Container{
+				Command: []string{"mysql", "--password"},
+				Args:    []string{"********"},
+			},
+		},
+		"sensitive args without command": {
+			input: v1.Container{
+				Args: []string{"--password", "afztyerbzio1234"},
+			},
+			expected: v1.Container{
+				Args: []string{"--password", "********"},
+			},
+		},
+		"sensitive args with spaces": {
+			input: v1.Container{
+				Command: []string{"sh", "-c"},
+				Args:    []string{"mysql --password afztyerbzio1234"},
+			},
+			expected: v1.Container{
+				Command: []string{"sh", "-c"},
+				Args:    []string{"mysql --password ********"},
+			},
+		},
+		"sensitive argument value after args with spaces": {
+			input: v1.Container{
+				Command: []string{"sh", "-c"},
+				Args:    []string{"mysql --user root --password", "afztyerbzio1234"},
+			},
+			expected: v1.Container{
+				Command: []string{"sh", "-c"},
+				Args:    []string{"mysql --user root --password", "********"},
+			},
+		},
 	}
 	for name, tc := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -540,6 +588,28 @@ func TestScrubContainer(t *testing.T) {
 	}
 }
 
+func TestScrubContainerCustomPatterns(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig(true)
+	cfg.Scrubber.AddCustomSensitiveRegexps([]string{"db_pass(word)?"})
+
+	c := v1.Container{
+		Command: []string{"app", "--db_pass=afztyerbzio1234"},
+		Args:    []string{"--db_password", "afztyerbzio1234"},
+	}
+	scrubContainer(&c, cfg)
+	assert.Equal(t, []string{"app", "--db_pass=********"}, c.Command)
+	assert.Equal(t, []string{"--db_password", "********"}, c.Args)
+
+	cfg.Scrubber.StripAllArguments = true
+	c = v1.Container{
+		Command: []string{"app", "--db_pass=afztyerbzio1234"},
+		Args:    []string{"--verbose"},
+	}
+	scrubContainer(&c, cfg)
+	assert.Equal(t, []string{"app"}, c.Command)
+	assert.Nil(t, c.Args)
+}
+
 func TestComputeStatus(t *testing.T) {
 	for nb, tc := range []struct {
 		pod    *v1.Pod
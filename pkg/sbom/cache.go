@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"time"
+)
+
+// cache keeps track of the SBOMs sent by ID, an image digest or a hostname,
+// so that the same image is scanned and sent only once per ttl.
+// The cache is not thread-safe.
+type cache struct {
+	ttl     time.Duration
+	entries map[string]time.Time
+}
+
+func newCache(ttl time.Duration) *cache {
+	return &cache{
+		ttl:     ttl,
+		entries: make(map[string]time.Time),
+	}
+}
+
+// contains returns true if the SBOM with the ID was sent less than ttl ago.
+func (c *cache) contains(id string, now time.Time) bool {
+	sentAt, found := c.entries[id]
+	return found && now.Sub(sentAt) < c.ttl
+}
+
+// add records that the SBOM with the ID was sent.
+func (c *cache) add(id string, now time.Time) {
+	c.entries[id] = now
+}
+
+// prune removes the expired entries.
+func (c *cache) prune(now time.Time) {
+	for id, sentAt := range c.entries {
+		if now.Sub(sentAt) >= c.ttl {
+			delete(c.entries, id)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	now := time.Now()
+	c := newCache(time.Hour)
+	assert.False(t, c.contains("sha256:abc", now))
+
+	c.add("sha256:abc", now)
+	assert.True(t, c.contains("sha256:abc", now.Add(59*time.Minute)))
+	assert.False(t, c.contains("sha256:abc", now.Add(time.Hour)))
+
+	c.prune(now.Add(30 * time.Minute))
+	assert.Len(t, c.entries, 1)
+	c.prune(now.Add(time.Hour))
+	assert.Len(t, c.entries, 0)
+}
@@ -0,0 +1,232 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// maxPendingSBOMs bounds the number of SBOMs waiting to be sent,
+// the resources which do not fit are scanned again at the next scan.
+const maxPendingSBOMs = 100
+
+var collector *Collector
+
+// image is a container image with the path to the root filesystem of one of its containers.
+type image struct {
+	id    string
+	names []string
+	root  string
+}
+
+func (i *image) addName(name string) {
+	if name == "" {
+		return
+	}
+	for _, n := range i.names {
+		if n == name {
+			return
+		}
+	}
+	i.names = append(i.names, name)
+}
+
+// Collector periodically scans the host and the container images for their packages,
+// and trickle-feeds the resulting SBOMs to the intake, one every sendInterval, to smooth
+// the load on the host and on the intake. An image is scanned once per cache ttl whatever
+// the number of containers running it.
+type Collector struct {
+	forwarder     forwarder.Forwarder
+	hostname      string
+	hostRoot      string
+	hostEnabled   bool
+	imagesEnabled bool
+	scanInterval  time.Duration
+	sendInterval  time.Duration
+	listImages    func(skip func(id string) bool) ([]*image, error)
+
+	cache   *cache
+	pending []*SBOM
+	stop    chan struct{}
+	done    chan struct{}
+}
+
+// NewCollector returns a new Collector configured from the sbom section of the configuration.
+func NewCollector(fwd forwarder.Forwarder, hostname string) *Collector {
+	return &Collector{
+		forwarder:     fwd,
+		hostname:      hostname,
+		hostRoot:      getHostRoot(),
+		hostEnabled:   config.Datadog.GetBool("sbom.host.enabled"),
+		imagesEnabled: config.Datadog.GetBool("sbom.container_image.enabled"),
+		scanInterval:  config.Datadog.GetDuration("sbom.scan_interval"),
+		sendInterval:  config.Datadog.GetDuration("sbom.send_interval"),
+		listImages:    listImages,
+		cache:         newCache(config.Datadog.GetDuration("sbom.cache_ttl")),
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
+	}
+}
+
+// Start starts the SBOM collection if enabled.
+func Start(fwd forwarder.Forwarder, hostname string) {
+	if !config.Datadog.GetBool("sbom.enabled") {
+		log.Info("SBOM collection disabled")
+		return
+	}
+	collector = NewCollector(fwd, hostname)
+	collector.Start()
+	log.Info("SBOM collection started")
+}
+
+// Stop stops the SBOM collection.
+func Stop() {
+	if collector != nil {
+		collector.Stop()
+		collector = nil
+	}
+}
+
+// Start starts the collector.
+func (c *Collector) Start() {
+	go c.run()
+}
+
+// Stop stops the collector, the SBOMs not sent yet are dropped.
+func (c *Collector) Stop() {
+	close(c.stop)
+	<-c.done
+}
+
+func (c *Collector) run() {
+	defer close(c.done)
+	scanTicker := time.NewTicker(c.scanInterval)
+	defer scanTicker.Stop()
+	sendTicker := time.NewTicker(c.sendInterval)
+	defer sendTicker.Stop()
+
+	c.scan(time.Now())
+	for {
+		select {
+		case <-c.stop:
+			return
+		case now := <-scanTicker.C:
+			c.scan(now)
+		case now := <-sendTicker.C:
+			c.sendNext(now)
+		}
+	}
+}
+
+// scan generates the SBOMs of the host and of the images not sent recently.
+func (c *Collector) scan(now time.Time) {
+	c.cache.prune(now)
+
+	if c.hostEnabled && !c.isKnown(KindHost, c.hostname, now) {
+		packages, err := ScanRootFS(c.hostRoot)
+		if err != nil {
+			log.Warnf("Could not scan the packages of the host: %v", err)
+		} else {
+			c.enqueue(&SBOM{
+				Host:        c.hostname,
+				Kind:        KindHost,
+				ID:          c.hostname,
+				GeneratedAt: now.Unix(),
+				Packages:    packages,
+			})
+		}
+	}
+
+	if !c.imagesEnabled {
+		return
+	}
+	images, err := c.listImages(func(id string) bool {
+		return c.isKnown(KindContainerImage, id, now)
+	})
+	if err != nil {
+		log.Debugf("Could not list the container images: %v", err)
+		return
+	}
+	for _, img := range images {
+		packages, err := ScanRootFS(img.root)
+		if err != nil {
+			log.Debugf("Could not scan the packages of image %s: %v", img.id, err)
+			continue
+		}
+		c.enqueue(&SBOM{
+			Host:        c.hostname,
+			Kind:        KindContainerImage,
+			ID:          img.id,
+			ImageNames:  img.names,
+			GeneratedAt: now.Unix(),
+			Packages:    packages,
+		})
+	}
+}
+
+// isKnown returns true if the SBOM of the resource is waiting to be sent or was sent recently.
+func (c *Collector) isKnown(kind, id string, now time.Time) bool {
+	if c.cache.contains(cacheKey(kind, id), now) {
+		return true
+	}
+	for _, sbom := range c.pending {
+		if sbom.Kind == kind && sbom.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Collector) enqueue(sbom *SBOM) {
+	if len(c.pending) >= maxPendingSBOMs {
+		log.Debugf("Too many SBOMs waiting to be sent, dropping the one of %s %s", sbom.Kind, sbom.ID)
+		return
+	}
+	c.pending = append(c.pending, sbom)
+}
+
+// sendNext sends the oldest pending SBOM, it is scanned again at the next scan if it could not be sent.
+func (c *Collector) sendNext(now time.Time) {
+	if len(c.pending) == 0 {
+		return
+	}
+	sbom := c.pending[0]
+	c.pending = c.pending[1:]
+
+	payload, err := json.Marshal(sbom)
+	if err != nil {
+		log.Errorf("Could not serialize the SBOM of %s %s: %v", sbom.Kind, sbom.ID, err)
+		return
+	}
+	extra := http.Header{}
+	extra.Set("Content-Type", "application/json")
+	if err := c.forwarder.SubmitSBOM(forwarder.Payloads{&payload}, extra); err != nil {
+		log.Warnf("Could not send the SBOM of %s %s: %v", sbom.Kind, sbom.ID, err)
+		return
+	}
+	log.Debugf("Sent the SBOM of %s %s with %d packages", sbom.Kind, sbom.ID, len(sbom.Packages))
+	c.cache.add(cacheKey(sbom.Kind, sbom.ID), now)
+}
+
+func cacheKey(kind, id string) string {
+	return kind + ":" + id
+}
+
+// getHostRoot returns the path to the root filesystem of the host, it is read
+// through the root of the init process when the agent runs in a container.
+func getHostRoot() string {
+	if config.IsContainerized() {
+		return filepath.Join(config.Datadog.GetString("container_proc_root"), "1", "root")
+	}
+	return "/"
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/forwarder"
+)
+
+func newTestCollector(t *testing.T, fwd forwarder.Forwarder, images []*image) *Collector {
+	hostRoot, err := ioutil.TempDir("", "sbom-host")
+	require.NoError(t, err)
+	writeFile(t, filepath.Join(hostRoot, "var/lib/dpkg/status"), dpkgStatus)
+
+	return &Collector{
+		forwarder:     fwd,
+		hostname:      "myhost",
+		hostRoot:      hostRoot,
+		hostEnabled:   true,
+		imagesEnabled: true,
+		listImages: func(skip func(id string) bool) ([]*image, error) {
+			var res []*image
+			for _, img := range images {
+				if !skip(img.id) {
+					res = append(res, img)
+				}
+			}
+			return res, nil
+		},
+		cache: newCache(time.Hour),
+	}
+}
+
+func TestCollectorSendsOneSBOMAtATime(t *testing.T) {
+	imageRoot, err := ioutil.TempDir("", "sbom-image")
+	require.NoError(t, err)
+	defer os.RemoveAll(imageRoot)
+	writeFile(t, filepath.Join(imageRoot, "lib/apk/db/installed"), apkInstalled)
+
+	var sent []SBOM
+	fwd := &forwarder.MockedForwarder{}
+	fwd.On("SubmitSBOM", mock.Anything, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+		var sbom SBOM
+		payloads := args.Get(0).(forwarder.Payloads)
+		require.NoError(t, json.Unmarshal(*payloads[0], &sbom))
+		sent = append(sent, sbom)
+	})
+
+	c := newTestCollector(t, fwd, []*image{{id: "sha256:abc", names: []string{"alpine:3.12"}, root: imageRoot}})
+	defer os.RemoveAll(c.hostRoot)
+
+	now := time.Now()
+	c.scan(now)
+	assert.Len(t, c.pending, 2)
+
+	// pending SBOMs are not generated twice
+	c.scan(now)
+	assert.Len(t, c.pending, 2)
+
+	c.sendNext(now)
+	require.Len(t, sent, 1)
+	assert.Equal(t, KindHost, sent[0].Kind)
+	assert.Equal(t, "myhost", sent[0].ID)
+	assert.Len(t, sent[0].Packages, 2)
+
+	c.sendNext(now)
+	require.Len(t, sent, 2)
+	assert.Equal(t, KindContainerImage, sent[1].Kind)
+	assert.Equal(t, "sha256:abc", sent[1].ID)
+	assert.Equal(t, []string{"alpine:3.12"}, sent[1].ImageNames)
+	assert.Len(t, sent[1].Packages, 2)
+
+	c.sendNext(now)
+	assert.Len(t, sent, 2)
+
+	// sent SBOMs are cached until the ttl expires
+	c.scan(now.Add(30 * time.Minute))
+	assert.Len(t, c.pending, 0)
+	c.scan(now.Add(time.Hour))
+	assert.Len(t, c.pending, 2)
+}
+
+func TestCollectorRetriesFailedSends(t *testing.T) {
+	fwd := &forwarder.MockedForwarder{}
+	fwd.On("SubmitSBOM", mock.Anything, mock.Anything).Return(assert.AnError)
+
+	c := newTestCollector(t, fwd, nil)
+	defer os.RemoveAll(c.hostRoot)
+
+	now := time.Now()
+	c.scan(now)
+	c.sendNext(now)
+	assert.Len(t, c.pending, 0)
+
+	c.scan(now)
+	assert.Len(t, c.pending, 1)
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build docker
+
+package sbom
+
+import (
+	"path/filepath"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// listImages returns the images of the running docker containers, the root filesystem of an image
+// is read through the root of one of its containers, the images for which skip returns true are ignored.
+func listImages(skip func(id string) bool) ([]*image, error) {
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		return nil, err
+	}
+	containers, err := du.RawContainerList(types.ContainerListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	procRoot := config.Datadog.GetString("container_proc_root")
+
+	var images []*image
+	imagesByID := make(map[string]*image)
+	for _, c := range containers {
+		if c.ImageID == "" || skip(c.ImageID) {
+			continue
+		}
+		name, err := du.ResolveImageName(c.Image)
+		if err != nil {
+			log.Debugf("Could not resolve the name of image %s: %v", c.Image, err)
+		}
+		if img, found := imagesByID[c.ImageID]; found {
+			img.addName(name)
+			continue
+		}
+		inspect, err := du.Inspect(c.ID, false)
+		if err != nil {
+			log.Debugf("Could not inspect container %s: %v", c.ID, err)
+			continue
+		}
+		if inspect.ContainerJSONBase == nil || inspect.State == nil || inspect.State.Pid == 0 {
+			continue
+		}
+		img := &image{
+			id:   c.ImageID,
+			root: filepath.Join(procRoot, strconv.Itoa(inspect.State.Pid), "root"),
+		}
+		img.addName(name)
+		imagesByID[c.ImageID] = img
+		images = append(images, img)
+	}
+	return images, nil
+}
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package sbom generates software bills of materials, the inventories of the packages
+// installed on the host and in the container images, and sends them to Datadog
+// so that they can be analyzed for vulnerabilities.
+package sbom
+
+// Kinds of inventoried resources
+const (
+	KindHost           = "host"
+	KindContainerImage = "container_image"
+)
+
+// Package types
+const (
+	PackageTypeDeb = "deb"
+	PackageTypeApk = "apk"
+)
+
+// Package is a software package installed in a filesystem.
+type Package struct {
+	Name         string `json:"name"`
+	Version      string `json:"version"`
+	Type         string `json:"type"`
+	Architecture string `json:"architecture,omitempty"`
+	SourceName   string `json:"source_name,omitempty"`
+}
+
+// SBOM is the inventory of the packages of a host or a container image.
+type SBOM struct {
+	Host        string    `json:"host"`
+	Kind        string    `json:"kind"`
+	ID          string    `json:"id"`
+	ImageNames  []string  `json:"image_names,omitempty"`
+	GeneratedAt int64     `json:"generated_at"`
+	Packages    []Package `json:"packages"`
+}
@@ -0,0 +1,129 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// packageDatabase is a database of installed packages relative to the root of a filesystem.
+type packageDatabase struct {
+	path  string
+	parse func(io.Reader) ([]Package, error)
+}
+
+var packageDatabases = []packageDatabase{
+	{path: "var/lib/dpkg/status", parse: parseDpkgStatus},
+	{path: "lib/apk/db/installed", parse: parseApkInstalled},
+}
+
+// ScanRootFS returns the packages installed in the filesystem mounted at root,
+// the databases that do not exist are ignored.
+func ScanRootFS(root string) ([]Package, error) {
+	packages := []Package{}
+	for _, db := range packageDatabases {
+		f, err := os.Open(filepath.Join(root, db.path))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		pkgs, err := db.parse(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+		packages = append(packages, pkgs...)
+	}
+	return packages, nil
+}
+
+// parseDpkgStatus parses the status file of dpkg, made of paragraphs of 'Field: value'
+// lines separated by empty lines, only the installed packages are returned.
+func parseDpkgStatus(reader io.Reader) ([]Package, error) {
+	var packages []Package
+	fields := make(map[string]string)
+	flush := func() {
+		if fields["Package"] != "" && strings.HasSuffix(fields["Status"], " installed") {
+			source := fields["Source"]
+			if i := strings.IndexByte(source, ' '); i >= 0 {
+				// the source may be followed by its version, e.g. 'glibc (2.28-10)'
+				source = source[:i]
+			}
+			packages = append(packages, Package{
+				Name:         fields["Package"],
+				Version:      fields["Version"],
+				Type:         PackageTypeDeb,
+				Architecture: fields["Architecture"],
+				SourceName:   source,
+			})
+		}
+		fields = make(map[string]string)
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			flush()
+		case line[0] == ' ' || line[0] == '\t':
+			// continuation of a multiline field
+		default:
+			if i := strings.IndexByte(line, ':'); i > 0 {
+				fields[line[:i]] = strings.TrimSpace(line[i+1:])
+			}
+		}
+	}
+	flush()
+	return packages, scanner.Err()
+}
+
+// parseApkInstalled parses the database of apk, made of paragraphs of 'K:value'
+// lines separated by empty lines.
+func parseApkInstalled(reader io.Reader) ([]Package, error) {
+	var packages []Package
+	var pkg Package
+	flush := func() {
+		if pkg.Name != "" {
+			pkg.Type = PackageTypeApk
+			packages = append(packages, pkg)
+		}
+		pkg = Package{}
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			flush()
+			continue
+		}
+		if len(line) < 2 || line[1] != ':' {
+			continue
+		}
+		value := line[2:]
+		switch line[0] {
+		case 'P':
+			pkg.Name = value
+		case 'V':
+			pkg.Version = value
+		case 'A':
+			pkg.Architecture = value
+		case 'o':
+			pkg.SourceName = value
+		}
+	}
+	flush()
+	return packages, scanner.Err()
+}
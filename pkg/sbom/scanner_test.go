@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package sbom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dpkgStatus = `Package: libc6
+Status: install ok installed
+Architecture: amd64
+Source: glibc (2.28-10)
+Version: 2.28-10
+Description: GNU C Library: Shared libraries
+ Contains the standard libraries that are used by nearly all programs on
+ the system.
+
+Package: removed
+Status: deinstall ok config-files
+Architecture: amd64
+Version: 1.0
+
+Package: bash
+Status: install ok installed
+Architecture: amd64
+Version: 5.0-4
+`
+
+const apkInstalled = `C:Q1abc=
+P:musl
+V:1.1.24-r9
+A:x86_64
+o:musl
+
+C:Q1def=
+P:busybox
+V:1.31.1-r19
+A:x86_64
+o:busybox
+`
+
+func TestParseDpkgStatus(t *testing.T) {
+	packages, err := parseDpkgStatus(strings.NewReader(dpkgStatus))
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{Name: "libc6", Version: "2.28-10", Type: PackageTypeDeb, Architecture: "amd64", SourceName: "glibc"},
+		{Name: "bash", Version: "5.0-4", Type: PackageTypeDeb, Architecture: "amd64"},
+	}, packages)
+}
+
+func TestParseApkInstalled(t *testing.T) {
+	packages, err := parseApkInstalled(strings.NewReader(apkInstalled))
+	require.NoError(t, err)
+	assert.Equal(t, []Package{
+		{Name: "musl", Version: "1.1.24-r9", Type: PackageTypeApk, Architecture: "x86_64", SourceName: "musl"},
+		{Name: "busybox", Version: "1.31.1-r19", Type: PackageTypeApk, Architecture: "x86_64", SourceName: "busybox"},
+	}, packages)
+}
+
+func TestScanRootFS(t *testing.T) {
+	root, err := ioutil.TempDir("", "sbom")
+	require.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	packages, err := ScanRootFS(root)
+	require.NoError(t, err)
+	assert.Len(t, packages, 0)
+
+	writeFile(t, filepath.Join(root, "lib/apk/db/installed"), apkInstalled)
+	packages, err = ScanRootFS(root)
+	require.NoError(t, err)
+	assert.Len(t, packages, 2)
+
+	writeFile(t, filepath.Join(root, "var/lib/dpkg/status"), dpkgStatus)
+	packages, err = ScanRootFS(root)
+	require.NoError(t, err)
+	assert.Len(t, packages, 4)
+}
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
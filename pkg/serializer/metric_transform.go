@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package serializer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+// prefixTags are the tags added to the metrics whose name starts with the prefix
+type prefixTags struct {
+	prefix string
+	tags   []string
+}
+
+// metricTransformer applies the metric_namespace and extra_tags_by_prefix settings to the
+// series and sketches, whether they come from the checks or from dogstatsd
+type metricTransformer struct {
+	namespace          string
+	namespaceBlacklist []string
+	tagsByPrefix       []prefixTags
+}
+
+// newMetricTransformerFromConfig returns the metricTransformer of the configuration, nil
+// when the metrics are sent unchanged
+func newMetricTransformerFromConfig() *metricTransformer {
+	return newMetricTransformer(
+		config.Datadog.GetString("metric_namespace"),
+		config.Datadog.GetStringSlice("metric_namespace_blacklist"),
+		config.Datadog.GetStringMapStringSlice("extra_tags_by_prefix"),
+	)
+}
+
+func newMetricTransformer(namespace string, namespaceBlacklist []string, extraTagsByPrefix map[string][]string) *metricTransformer {
+	if namespace != "" && !strings.HasSuffix(namespace, ".") {
+		namespace = namespace + "."
+	}
+
+	var tagsByPrefix []prefixTags
+	for prefix, tags := range extraTagsByPrefix {
+		if len(tags) == 0 {
+			continue
+		}
+		tagsByPrefix = append(tagsByPrefix, prefixTags{prefix: prefix, tags: tags})
+	}
+	// the tags are added in the same order on each flush
+	sort.Slice(tagsByPrefix, func(i, j int) bool { return tagsByPrefix[i].prefix < tagsByPrefix[j].prefix })
+
+	if namespace == "" && len(tagsByPrefix) == 0 {
+		return nil
+	}
+	return &metricTransformer{
+		namespace:          namespace,
+		namespaceBlacklist: namespaceBlacklist,
+		tagsByPrefix:       tagsByPrefix,
+	}
+}
+
+// transformName returns the namespaced name of a metric
+func (t *metricTransformer) transformName(name string) string {
+	if t.namespace == "" || strings.HasPrefix(name, t.namespace) {
+		return name
+	}
+	for _, prefix := range t.namespaceBlacklist {
+		if strings.HasPrefix(name, prefix) {
+			return name
+		}
+	}
+	return t.namespace + name
+}
+
+// transformTags returns the tags of a metric with the extra tags of the prefixes matching its
+// name, before it is namespaced. The tags are copied, as they may be shared with the contexts
+// of the aggregator.
+func (t *metricTransformer) transformTags(name string, tags []string) []string {
+	var extraTags []string
+	for _, pt := range t.tagsByPrefix {
+		if strings.HasPrefix(name, pt.prefix) {
+			extraTags = append(extraTags, pt.tags...)
+		}
+	}
+	if len(extraTags) == 0 {
+		return tags
+	}
+	newTags := make([]string, 0, len(tags)+len(extraTags))
+	newTags = append(newTags, tags...)
+	return append(newTags, extraTags...)
+}
+
+// transformSeries renames and tags the series in place
+func (t *metricTransformer) transformSeries(series metrics.Series) {
+	for _, serie := range series {
+		serie.Tags = t.transformTags(serie.Name, serie.Tags)
+		serie.Name = t.transformName(serie.Name)
+	}
+}
+
+// transformSketches renames and tags the sketches in place
+func (t *metricTransformer) transformSketches(sketches metrics.SketchSeriesList) {
+	for i := range sketches {
+		sketches[i].Tags = t.transformTags(sketches[i].Name, sketches[i].Tags)
+		sketches[i].Name = t.transformName(sketches[i].Name)
+	}
+}
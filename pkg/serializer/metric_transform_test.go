@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package serializer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/metrics"
+)
+
+func TestMetricTransformerDisabled(t *testing.T) {
+	assert.Nil(t, newMetricTransformer("", nil, nil))
+	assert.Nil(t, newMetricTransformer("", nil, map[string][]string{"foo.": {}}))
+}
+
+func TestMetricTransformerSeries(t *testing.T) {
+	transformer := newMetricTransformer("platform", []string{"datadog.agent"}, map[string][]string{
+		"mycorp.db.": {"team:db"},
+		"mycorp.":    {"org:mycorp"},
+	})
+	require.NotNil(t, transformer)
+
+	sharedTags := make([]string, 1, 10)
+	sharedTags[0] = "env:prod"
+	series := metrics.Series{
+		{Name: "mycorp.db.queries", Tags: sharedTags},
+		{Name: "mycorp.web.requests", Tags: []string{"env:prod"}},
+		{Name: "system.cpu.user"},
+		{Name: "datadog.agent.running"},
+		{Name: "platform.already.namespaced"},
+	}
+	transformer.transformSeries(series)
+
+	assert.Equal(t, "platform.mycorp.db.queries", series[0].Name)
+	assert.Equal(t, []string{"env:prod", "org:mycorp", "team:db"}, series[0].Tags)
+	assert.Equal(t, "platform.mycorp.web.requests", series[1].Name)
+	assert.Equal(t, []string{"env:prod", "org:mycorp"}, series[1].Tags)
+	assert.Equal(t, "platform.system.cpu.user", series[2].Name)
+	assert.Nil(t, series[2].Tags)
+	assert.Equal(t, "datadog.agent.running", series[3].Name)
+	assert.Equal(t, "platform.already.namespaced", series[4].Name)
+
+	// the tags shared with the aggregator contexts are not modified, even past their length
+	assert.Equal(t, []string{"env:prod"}, sharedTags)
+	assert.Equal(t, "", sharedTags[:2][1])
+}
+
+func TestMetricTransformerSketches(t *testing.T) {
+	transformer := newMetricTransformer("", nil, map[string][]string{"mycorp.": {"org:mycorp"}})
+	require.NotNil(t, transformer)
+
+	sketches := metrics.SketchSeriesList{
+		{Name: "mycorp.latency", Tags: []string{"env:prod"}},
+		{Name: "other.latency", Tags: []string{"env:prod"}},
+	}
+	transformer.transformSketches(sketches)
+
+	assert.Equal(t, "mycorp.latency", sketches[0].Name)
+	assert.Equal(t, []string{"env:prod", "org:mycorp"}, sketches[0].Tags)
+	assert.Equal(t, "other.latency", sketches[1].Name)
+	assert.Equal(t, []string{"env:prod"}, sketches[1].Tags)
+}
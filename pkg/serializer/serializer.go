@@ -14,6 +14,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/forwarder"
+	"github.com/DataDog/datadog-agent/pkg/metrics"
 	"github.com/DataDog/datadog-agent/pkg/serializer/jsonstream"
 	"github.com/DataDog/datadog-agent/pkg/serializer/marshaler"
 	"github.com/DataDog/datadog-agent/pkg/serializer/split"
@@ -105,6 +106,10 @@ type Serializer struct {
 
 	seriesPayloadBuilder *jsonstream.PayloadBuilder
 
+	// metricTransformer namespaces and tags the series and sketches, it is nil when
+	// metric_namespace and extra_tags_by_prefix are not set
+	metricTransformer *metricTransformer
+
 	// Those variables allow users to blacklist any kind of payload
 	// from being sent by the agent. This was introduced for
 	// environment where, for example, events or serviceChecks
@@ -126,6 +131,7 @@ func NewSerializer(forwarder forwarder.Forwarder) *Serializer {
 	s := &Serializer{
 		Forwarder:                     forwarder,
 		seriesPayloadBuilder:          jsonstream.NewPayloadBuilder(),
+		metricTransformer:             newMetricTransformerFromConfig(),
 		enableEvents:                  config.Datadog.GetBool("enable_payloads.events"),
 		enableSeries:                  config.Datadog.GetBool("enable_payloads.series"),
 		enableServiceChecks:           config.Datadog.GetBool("enable_payloads.service_checks"),
@@ -287,6 +293,12 @@ func (s *Serializer) SendSeries(series marshaler.StreamJSONMarshaler) error {
 		return nil
 	}
 
+	if s.metricTransformer != nil {
+		if seriesList, ok := series.(metrics.Series); ok {
+			s.metricTransformer.transformSeries(seriesList)
+		}
+	}
+
 	useV1API := !config.Datadog.GetBool("use_v2_api.series")
 
 	var seriesPayloads forwarder.Payloads
@@ -316,6 +328,12 @@ func (s *Serializer) SendSketch(sketches marshaler.Marshaler) error {
 		return nil
 	}
 
+	if s.metricTransformer != nil {
+		if sl, ok := sketches.(metrics.SketchSeriesList); ok {
+			s.metricTransformer.transformSketches(sl)
+		}
+	}
+
 	compress := true
 	useV1API := false // Sketches only have a v2 endpoint
 	splitSketches, extraHeaders, err := s.serializePayload(sketches, compress, useV1API)
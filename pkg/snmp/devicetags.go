@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import "sync"
+
+// deviceTags holds the tags of the devices discovered by the SNMP listener, by IP address,
+// so that the other network devices features, e.g. the flows, can be tagged like the devices
+var deviceTags = struct {
+	sync.RWMutex
+	tags map[string][]string
+}{tags: make(map[string][]string)}
+
+// SetDeviceTags sets the tags of the device with the given IP address
+func SetDeviceTags(ip string, tags []string) {
+	deviceTags.Lock()
+	defer deviceTags.Unlock()
+	deviceTags.tags[ip] = append([]string{}, tags...)
+}
+
+// DeleteDeviceTags removes the tags of the device with the given IP address
+func DeleteDeviceTags(ip string) {
+	deviceTags.Lock()
+	defer deviceTags.Unlock()
+	delete(deviceTags.tags, ip)
+}
+
+// GetDeviceTags returns the tags of the device with the given IP address, nil if the
+// device hasn't been discovered
+func GetDeviceTags(ip string) []string {
+	deviceTags.RLock()
+	defer deviceTags.RUnlock()
+	tags, found := deviceTags.tags[ip]
+	if !found {
+		return nil
+	}
+	return append([]string{}, tags...)
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package snmp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceTags(t *testing.T) {
+	assert.Nil(t, GetDeviceTags("10.0.0.1"))
+
+	tags := []string{"snmp_device:10.0.0.1"}
+	SetDeviceTags("10.0.0.1", tags)
+	tags[0] = "modified"
+	assert.Equal(t, []string{"snmp_device:10.0.0.1"}, GetDeviceTags("10.0.0.1"))
+
+	DeleteDeviceTags("10.0.0.1")
+	assert.Nil(t, GetDeviceTags("10.0.0.1"))
+}
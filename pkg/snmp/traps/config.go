@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+
+	"github.com/soniah/gosnmp"
+)
+
+// UserV3 holds the credentials of a SNMPv3 user sending traps
+type UserV3 struct {
+	User         string `mapstructure:"user"`
+	AuthKey      string `mapstructure:"authentication_key"`
+	AuthProtocol string `mapstructure:"authentication_protocol"`
+	PrivKey      string `mapstructure:"privacy_key"`
+	PrivProtocol string `mapstructure:"privacy_protocol"`
+}
+
+// Config holds the configuration of the traps listener
+type Config struct {
+	Port             uint16   `mapstructure:"port"`
+	BindHost         string   `mapstructure:"bind_host"`
+	CommunityStrings []string `mapstructure:"community_strings"`
+	Users            []UserV3 `mapstructure:"users"`
+	StopTimeout      int      `mapstructure:"stop_timeout"`
+}
+
+// ReadConfig parses and validates the network_devices.snmp_traps configuration
+func ReadConfig() (*Config, error) {
+	var c Config
+	if err := config.Datadog.UnmarshalKey("network_devices.snmp_traps", &c); err != nil {
+		return nil, err
+	}
+	if len(c.CommunityStrings) == 0 && len(c.Users) == 0 {
+		return nil, errors.New("no community_strings nor users configured, the traps would all be rejected")
+	}
+	// the trap listener authenticates the SNMPv3 packets with a single set of credentials
+	if len(c.Users) > 1 {
+		return nil, fmt.Errorf("only one SNMPv3 user is supported, %d are configured", len(c.Users))
+	}
+	return &c, nil
+}
+
+// Addr returns the address the listener binds to
+func (c *Config) Addr() string {
+	return fmt.Sprintf("%s:%d", c.BindHost, c.Port)
+}
+
+// BuildSNMPParams returns the parameters of the trap listener, holding the credentials of the
+// SNMPv3 user when there is one
+func (c *Config) BuildSNMPParams() (*gosnmp.GoSNMP, error) {
+	if len(c.Users) == 0 {
+		return &gosnmp.GoSNMP{
+			Port:      c.Port,
+			Transport: "udp",
+			Version:   gosnmp.Version2c,
+		}, nil
+	}
+	user := c.Users[0]
+	snmpConfig := snmp.Config{
+		Port:         c.Port,
+		Version:      "3",
+		User:         user.User,
+		AuthKey:      user.AuthKey,
+		AuthProtocol: user.AuthProtocol,
+		PrivKey:      user.PrivKey,
+		PrivProtocol: user.PrivProtocol,
+	}
+	return snmpConfig.BuildSNMPParams()
+}
+
+// isCommunityAllowed returns whether a SNMPv2c trap with the given community is accepted
+func (c *Config) isCommunityAllowed(community string) bool {
+	for _, allowed := range c.CommunityStrings {
+		if subtle.ConstantTimeCompare([]byte(community), []byte(allowed)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/soniah/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func readTestConfig(t *testing.T, yamlConfig string) (*Config, error) {
+	config.Datadog.SetConfigType("yaml")
+	require.NoError(t, config.Datadog.ReadConfig(strings.NewReader(yamlConfig)))
+	return ReadConfig()
+}
+
+func TestReadConfig(t *testing.T) {
+	c, err := readTestConfig(t, `
+network_devices:
+  snmp_traps:
+    port: 1162
+    community_strings: ["public"]
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "0.0.0.0:1162", c.Addr())
+	assert.True(t, c.isCommunityAllowed("public"))
+	assert.False(t, c.isCommunityAllowed("private"))
+
+	params, err := c.BuildSNMPParams()
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Version2c, params.Version)
+}
+
+func TestReadConfigV3(t *testing.T) {
+	c, err := readTestConfig(t, `
+network_devices:
+  snmp_traps:
+    users:
+      - user: admin
+        authentication_key: secret-key
+        authentication_protocol: sha
+`)
+	require.NoError(t, err)
+
+	params, err := c.BuildSNMPParams()
+	require.NoError(t, err)
+	assert.Equal(t, gosnmp.Version3, params.Version)
+	assert.Equal(t, gosnmp.AuthNoPriv, params.MsgFlags)
+}
+
+func TestReadConfigErrors(t *testing.T) {
+	_, err := readTestConfig(t, `
+network_devices:
+  snmp_traps:
+    port: 1162
+`)
+	assert.Error(t, err)
+
+	_, err = readTestConfig(t, `
+network_devices:
+  snmp_traps:
+    users:
+      - user: admin
+      - user: other
+`)
+	assert.Error(t, err)
+}
@@ -0,0 +1,127 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"unicode/utf8"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+
+	"github.com/soniah/gosnmp"
+)
+
+// The OIDs of the variables of the SNMPv2 traps identifying them
+const (
+	sysUpTimeInstanceOID = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOID          = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// SnmpPacket is a trap received by the listener
+type SnmpPacket struct {
+	Content   *gosnmp.SnmpPacket
+	Addr      *net.UDPAddr
+	Timestamp int64
+}
+
+// trapPayload is the structured event of a trap sent to the intake
+type trapPayload struct {
+	Device      string                 `json:"device"`
+	SnmpVersion string                 `json:"snmp_version"`
+	Timestamp   int64                  `json:"timestamp"`
+	TrapOID     string                 `json:"snmpTrapOID"`
+	TrapName    string                 `json:"snmpTrapName,omitempty"`
+	TrapMIB     string                 `json:"snmpTrapMIB,omitempty"`
+	Uptime      uint32                 `json:"uptime"`
+	Variables   map[string]interface{} `json:"variables"`
+	Tags        []string               `json:"tags"`
+}
+
+var snmpVersions = map[gosnmp.SnmpVersion]string{
+	gosnmp.Version1:  "1",
+	gosnmp.Version2c: "2",
+	gosnmp.Version3:  "3",
+}
+
+// Formatter formats the traps as structured events, naming the trap and its variables after
+// their definitions in the MIBs
+type Formatter struct {
+	resolver OIDResolver
+}
+
+// NewFormatter returns a Formatter resolving the OIDs with the resolver
+func NewFormatter(resolver OIDResolver) *Formatter {
+	return &Formatter{resolver: resolver}
+}
+
+// FormatPacket returns the JSON structured event of a trap
+func (f *Formatter) FormatPacket(packet *SnmpPacket) ([]byte, error) {
+	variables := packet.Content.Variables
+	if len(variables) < 2 || normalizeOID(variables[0].Name) != sysUpTimeInstanceOID || normalizeOID(variables[1].Name) != snmpTrapOID {
+		return nil, errors.New("the trap doesn't start with the sysUpTime.0 and snmpTrapOID.0 variables")
+	}
+	uptime, _ := variables[0].Value.(uint32)
+	trapOID, ok := variables[1].Value.(string)
+	if !ok {
+		return nil, errors.New("the snmpTrapOID.0 variable is not an OID")
+	}
+	trapOID = normalizeOID(trapOID)
+
+	device := packet.Addr.IP.String()
+	payload := trapPayload{
+		Device:      device,
+		SnmpVersion: snmpVersions[packet.Content.Version],
+		Timestamp:   packet.Timestamp,
+		TrapOID:     trapOID,
+		Uptime:      uptime,
+		Variables:   make(map[string]interface{}, len(variables)-2),
+		Tags:        append(snmp.GetDeviceTags(device), "snmp_version:"+snmpVersions[packet.Content.Version]),
+	}
+	if trap, err := f.resolver.GetTrapMetadata(trapOID); err == nil {
+		payload.TrapName = trap.Name
+		payload.TrapMIB = trap.MIBName
+	}
+	if len(payload.Tags) == 1 {
+		// the device hasn't been discovered by the SNMP listener
+		payload.Tags = append(payload.Tags, "snmp_device:"+device)
+	}
+
+	for _, v := range variables[2:] {
+		name := normalizeOID(v.Name)
+		value := formatValue(v)
+		if variable, err := f.resolver.GetVariableMetadata(name); err == nil {
+			name = variable.Name
+			if i, ok := value.(int); ok && variable.Enum != nil {
+				if enumValue, found := variable.Enum[i]; found {
+					value = enumValue
+				}
+			}
+		}
+		payload.Variables[name] = value
+	}
+	return json.Marshal(payload)
+}
+
+// formatValue converts the value of a variable to a JSON value
+func formatValue(v gosnmp.SnmpPDU) interface{} {
+	switch value := v.Value.(type) {
+	case []byte:
+		if utf8.Valid(value) {
+			return string(value)
+		}
+		return "0x" + hex.EncodeToString(value)
+	case string:
+		if v.Type == gosnmp.ObjectIdentifier {
+			return normalizeOID(value)
+		}
+		return value
+	default:
+		return value
+	}
+}
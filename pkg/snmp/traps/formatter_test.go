@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/snmp"
+
+	"github.com/soniah/gosnmp"
+)
+
+func newTestResolver() *MultiFilesOIDResolver {
+	r := &MultiFilesOIDResolver{
+		traps:     make(map[string]TrapMetadata),
+		variables: make(map[string]VariableMetadata),
+	}
+	r.add(builtinTrapDB)
+	return r
+}
+
+func newLinkDownPacket(version gosnmp.SnmpVersion) *SnmpPacket {
+	return &SnmpPacket{
+		Content: &gosnmp.SnmpPacket{
+			Version:   version,
+			Community: "public",
+			Variables: []gosnmp.SnmpPDU{
+				{Name: ".1.3.6.1.2.1.1.3.0", Type: gosnmp.TimeTicks, Value: uint32(1000)},
+				{Name: ".1.3.6.1.6.3.1.1.4.1.0", Type: gosnmp.ObjectIdentifier, Value: ".1.3.6.1.6.3.1.1.5.3"},
+				{Name: ".1.3.6.1.2.1.2.2.1.1.12", Type: gosnmp.Integer, Value: 12},
+				{Name: ".1.3.6.1.2.1.2.2.1.7.12", Type: gosnmp.Integer, Value: 2},
+				{Name: ".1.3.6.1.2.1.2.2.1.2.12", Type: gosnmp.OctetString, Value: []byte("eth0")},
+				{Name: ".1.3.6.1.4.1.9999.1.0", Type: gosnmp.OctetString, Value: []byte{0xff, 0x01}},
+			},
+		},
+		Addr:      &net.UDPAddr{IP: net.ParseIP("10.0.0.1"), Port: 162},
+		Timestamp: 1600000000000,
+	}
+}
+
+func TestFormatPacket(t *testing.T) {
+	snmp.SetDeviceTags("10.0.0.1", []string{"snmp_device:10.0.0.1", "autodiscovery_subnet:10.0.0.0/24"})
+	defer snmp.DeleteDeviceTags("10.0.0.1")
+
+	content, err := NewFormatter(newTestResolver()).FormatPacket(newLinkDownPacket(gosnmp.Version2c))
+	require.NoError(t, err)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &payload))
+	assert.Equal(t, map[string]interface{}{
+		"device":       "10.0.0.1",
+		"snmp_version": "2",
+		"timestamp":    float64(1600000000000),
+		"snmpTrapOID":  "1.3.6.1.6.3.1.1.5.3",
+		"snmpTrapName": "linkDown",
+		"snmpTrapMIB":  "IF-MIB",
+		"uptime":       float64(1000),
+		"variables": map[string]interface{}{
+			"ifIndex":              float64(12),
+			"ifAdminStatus":        "down",
+			"ifDescr":              "eth0",
+			"1.3.6.1.4.1.9999.1.0": "0xff01",
+		},
+		"tags": []interface{}{"snmp_device:10.0.0.1", "autodiscovery_subnet:10.0.0.0/24", "snmp_version:2"},
+	}, payload)
+}
+
+func TestFormatPacketUndiscoveredDevice(t *testing.T) {
+	content, err := NewFormatter(newTestResolver()).FormatPacket(newLinkDownPacket(gosnmp.Version3))
+	require.NoError(t, err)
+
+	var payload trapPayload
+	require.NoError(t, json.Unmarshal(content, &payload))
+	assert.Equal(t, []string{"snmp_version:3", "snmp_device:10.0.0.1"}, payload.Tags)
+}
+
+func TestFormatPacketInvalidTrap(t *testing.T) {
+	packet := newLinkDownPacket(gosnmp.Version2c)
+	packet.Content.Variables = packet.Content.Variables[2:]
+
+	_, err := NewFormatter(newTestResolver()).FormatPacket(packet)
+	assert.Error(t, err)
+}
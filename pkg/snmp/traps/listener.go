@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"github.com/soniah/gosnmp"
+)
+
+// listenTimeout is how long the listener is given to bind to its address
+const listenTimeout = 5 * time.Second
+
+var (
+	tlmPacketsReceived = telemetry.NewCounter("snmp_traps", "received",
+		nil, "Count of the traps received")
+	tlmPacketsRejected = telemetry.NewCounter("snmp_traps", "rejected",
+		[]string{"reason"}, "Count of the traps rejected")
+)
+
+// TrapListener receives the traps sent by the devices and authenticates them
+type TrapListener struct {
+	config   *Config
+	listener *gosnmp.TrapListener
+	packets  chan *SnmpPacket
+	errors   chan error
+}
+
+// NewTrapListener returns a listener sending the authenticated traps to its packets channel
+func NewTrapListener(config *Config, packets chan *SnmpPacket) (*TrapListener, error) {
+	params, err := config.BuildSNMPParams()
+	if err != nil {
+		return nil, err
+	}
+	l := &TrapListener{
+		config:   config,
+		listener: gosnmp.NewTrapListener(),
+		packets:  packets,
+		errors:   make(chan error, 1),
+	}
+	l.listener.Params = params
+	l.listener.OnNewTrap = l.receivePacket
+	return l, nil
+}
+
+// Start starts listening, and returns once the listener is bound to its address
+func (l *TrapListener) Start() error {
+	go func() {
+		l.errors <- l.listener.Listen(l.config.Addr())
+	}()
+	select {
+	case <-l.listener.Listening():
+		log.Infof("Listening to the SNMP traps on %s", l.config.Addr())
+		return nil
+	case err := <-l.errors:
+		return fmt.Errorf("could not listen to the SNMP traps on %s: %v", l.config.Addr(), err)
+	case <-time.After(listenTimeout):
+		return fmt.Errorf("could not listen to the SNMP traps on %s before the timeout", l.config.Addr())
+	}
+}
+
+// Stop stops listening
+func (l *TrapListener) Stop() {
+	l.listener.Close()
+}
+
+func (l *TrapListener) receivePacket(p *gosnmp.SnmpPacket, addr *net.UDPAddr) {
+	tlmPacketsReceived.Inc()
+	// the SNMPv3 traps are authenticated by gosnmp with the credentials of the user
+	if p.Version != gosnmp.Version3 && !l.config.isCommunityAllowed(p.Community) {
+		tlmPacketsRejected.Inc("unknown_community")
+		log.Debugf("Rejecting a trap from %s with an unknown community", addr)
+		return
+	}
+	select {
+	case l.packets <- &SnmpPacket{Content: p, Addr: addr, Timestamp: time.Now().UnixNano() / int64(time.Millisecond)}:
+	default:
+		tlmPacketsRejected.Inc("queue_full")
+	}
+}
@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TrapMetadata is the metadata of a trap, compiled from its MIB
+type TrapMetadata struct {
+	Name    string `json:"name" yaml:"name"`
+	MIBName string `json:"mib" yaml:"mib"`
+}
+
+// VariableMetadata is the metadata of a variable bound in the traps, compiled from its MIB
+type VariableMetadata struct {
+	Name string `json:"name" yaml:"name"`
+	// Enum maps the integer values of the variable to their names
+	Enum map[int]string `json:"enum" yaml:"enum"`
+}
+
+// trapDBFile is a file of the traps database, compiled from MIBs: it maps the OIDs of the
+// traps and of their variables to their metadata
+type trapDBFile struct {
+	Traps     map[string]TrapMetadata     `json:"traps" yaml:"traps"`
+	Variables map[string]VariableMetadata `json:"vars" yaml:"vars"`
+}
+
+// OIDResolver resolves the OIDs of the traps and of their variables to their names
+type OIDResolver interface {
+	GetTrapMetadata(trapOID string) (TrapMetadata, error)
+	GetVariableMetadata(varOID string) (VariableMetadata, error)
+}
+
+// builtinTrapDB holds the generic traps of SNMPv2-MIB and IF-MIB, sent by all the devices
+var builtinTrapDB = trapDBFile{
+	Traps: map[string]TrapMetadata{
+		"1.3.6.1.6.3.1.1.5.1": {Name: "coldStart", MIBName: "SNMPv2-MIB"},
+		"1.3.6.1.6.3.1.1.5.2": {Name: "warmStart", MIBName: "SNMPv2-MIB"},
+		"1.3.6.1.6.3.1.1.5.3": {Name: "linkDown", MIBName: "IF-MIB"},
+		"1.3.6.1.6.3.1.1.5.4": {Name: "linkUp", MIBName: "IF-MIB"},
+		"1.3.6.1.6.3.1.1.5.5": {Name: "authenticationFailure", MIBName: "SNMPv2-MIB"},
+	},
+	Variables: map[string]VariableMetadata{
+		"1.3.6.1.2.1.2.2.1.1": {Name: "ifIndex"},
+		"1.3.6.1.2.1.2.2.1.2": {Name: "ifDescr"},
+		"1.3.6.1.2.1.2.2.1.7": {Name: "ifAdminStatus", Enum: map[int]string{1: "up", 2: "down", 3: "testing"}},
+		"1.3.6.1.2.1.2.2.1.8": {Name: "ifOperStatus", Enum: map[int]string{
+			1: "up", 2: "down", 3: "testing", 4: "unknown", 5: "dormant", 6: "notPresent", 7: "lowerLayerDown",
+		}},
+		"1.3.6.1.2.1.31.1.1.1.1": {Name: "ifName"},
+	},
+}
+
+// MultiFilesOIDResolver resolves the OIDs with the built-in traps database and the files of
+// conf.d/snmp.d/traps_db, the files being loaded in name order and overriding the OIDs of the
+// previous ones
+type MultiFilesOIDResolver struct {
+	traps     map[string]TrapMetadata
+	variables map[string]VariableMetadata
+}
+
+// NewMultiFilesOIDResolver loads the traps database files
+func NewMultiFilesOIDResolver() (*MultiFilesOIDResolver, error) {
+	r := &MultiFilesOIDResolver{
+		traps:     make(map[string]TrapMetadata),
+		variables: make(map[string]VariableMetadata),
+	}
+	r.add(builtinTrapDB)
+
+	dir := filepath.Join(config.Datadog.GetString("confd_path"), "snmp.d", "traps_db")
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		log.Debugf("No traps database in %s, only the generic traps will be resolved: %v", dir, err)
+		return r, nil
+	}
+	names := make([]string, 0, len(files))
+	for _, f := range files {
+		if !f.IsDir() {
+			names = append(names, f.Name())
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := r.load(filepath.Join(dir, name)); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+func (r *MultiFilesOIDResolver) load(path string) error {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var db trapDBFile
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &db)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &db)
+	default:
+		log.Debugf("Ignoring %s, the traps database files are JSON or YAML files", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not parse the traps database file %s: %v", path, err)
+	}
+	r.add(db)
+	log.Debugf("Loaded %d traps and %d variables from %s", len(db.Traps), len(db.Variables), path)
+	return nil
+}
+
+func (r *MultiFilesOIDResolver) add(db trapDBFile) {
+	for oid, trap := range db.Traps {
+		r.traps[normalizeOID(oid)] = trap
+	}
+	for oid, variable := range db.Variables {
+		r.variables[normalizeOID(oid)] = variable
+	}
+}
+
+// GetTrapMetadata returns the metadata of a trap
+func (r *MultiFilesOIDResolver) GetTrapMetadata(trapOID string) (TrapMetadata, error) {
+	trap, found := r.traps[normalizeOID(trapOID)]
+	if !found {
+		return TrapMetadata{}, fmt.Errorf("trap OID %s is not defined in the traps database", trapOID)
+	}
+	return trap, nil
+}
+
+// GetVariableMetadata returns the metadata of a variable. The variables of the tables are bound
+// with the index of their row appended to their OID, the OID is shortened until it matches one
+// of the database.
+func (r *MultiFilesOIDResolver) GetVariableMetadata(varOID string) (VariableMetadata, error) {
+	oid := normalizeOID(varOID)
+	for {
+		if variable, found := r.variables[oid]; found {
+			return variable, nil
+		}
+		i := strings.LastIndex(oid, ".")
+		if i < 0 {
+			return VariableMetadata{}, fmt.Errorf("variable OID %s is not defined in the traps database", varOID)
+		}
+		oid = oid[:i]
+	}
+}
+
+// normalizeOID removes the leading dot of the OIDs returned by gosnmp
+func normalizeOID(oid string) string {
+	return strings.TrimPrefix(oid, ".")
+}
@@ -0,0 +1,85 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func setupTrapsDB(t *testing.T, files map[string]string) func() {
+	confdPath, err := ioutil.TempDir("", "confd")
+	require.NoError(t, err)
+	dir := filepath.Join(confdPath, "snmp.d", "traps_db")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range files {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	mockConfig := config.Mock()
+	previous := mockConfig.GetString("confd_path")
+	mockConfig.Set("confd_path", confdPath)
+	return func() {
+		mockConfig.Set("confd_path", previous)
+		os.RemoveAll(confdPath)
+	}
+}
+
+func TestBuiltinTrapDB(t *testing.T) {
+	defer setupTrapsDB(t, nil)()
+
+	r, err := NewMultiFilesOIDResolver()
+	require.NoError(t, err)
+
+	trap, err := r.GetTrapMetadata(".1.3.6.1.6.3.1.1.5.3")
+	require.NoError(t, err)
+	assert.Equal(t, TrapMetadata{Name: "linkDown", MIBName: "IF-MIB"}, trap)
+
+	// the index of the row is appended to the OIDs of the variables of the tables
+	variable, err := r.GetVariableMetadata(".1.3.6.1.2.1.2.2.1.7.12")
+	require.NoError(t, err)
+	assert.Equal(t, "ifAdminStatus", variable.Name)
+
+	_, err = r.GetTrapMetadata("1.3.6.1.4.1.9999.1")
+	assert.Error(t, err)
+	_, err = r.GetVariableMetadata("1.3.6.1.4.1.9999.1.1")
+	assert.Error(t, err)
+}
+
+func TestTrapDBFiles(t *testing.T) {
+	defer setupTrapsDB(t, map[string]string{
+		"a.json": `{"traps": {"1.3.6.1.4.1.9999.1": {"name": "fooTrap", "mib": "FOO-MIB"}},
+			"vars": {"1.3.6.1.4.1.9999.2": {"name": "fooStatus", "enum": {"1": "ok", "2": "ko"}}}}`,
+		"b.yaml": "traps:\n  1.3.6.1.4.1.9999.1:\n    name: barTrap\n    mib: BAR-MIB\n",
+		"README": "ignored",
+	})()
+
+	r, err := NewMultiFilesOIDResolver()
+	require.NoError(t, err)
+
+	// b.yaml is loaded after a.json
+	trap, err := r.GetTrapMetadata("1.3.6.1.4.1.9999.1")
+	require.NoError(t, err)
+	assert.Equal(t, TrapMetadata{Name: "barTrap", MIBName: "BAR-MIB"}, trap)
+
+	variable, err := r.GetVariableMetadata("1.3.6.1.4.1.9999.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, VariableMetadata{Name: "fooStatus", Enum: map[int]string{1: "ok", 2: "ko"}}, variable)
+}
+
+func TestTrapDBInvalidFile(t *testing.T) {
+	defer setupTrapsDB(t, map[string]string{"a.json": "{"})()
+
+	_, err := NewMultiFilesOIDResolver()
+	assert.Error(t, err)
+}
@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2020 Datadog, Inc.
+
+package traps
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/epforwarder"
+	"github.com/DataDog/datadog-agent/pkg/logs/message"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// packetsChanSize is the number of traps buffered between the listener and the forwarder
+const packetsChanSize = 100
+
+var (
+	tlmTrapsForwarded = telemetry.NewCounter("snmp_traps", "forwarded",
+		nil, "Count of the traps forwarded to the intake")
+	tlmTrapsDropped = telemetry.NewCounter("snmp_traps", "dropped",
+		[]string{"reason"}, "Count of the traps which couldn't be forwarded")
+)
+
+// IsEnabled returns whether the SNMP traps listener is enabled
+func IsEnabled() bool {
+	return config.Datadog.GetBool("network_devices.snmp_traps.enabled")
+}
+
+// Server receives the SNMP traps and forwards them as structured events to the intake
+type Server struct {
+	config    *Config
+	listener  *TrapListener
+	formatter *Formatter
+	sender    epforwarder.EventPlatformForwarder
+	packets   chan *SnmpPacket
+	stopChan  chan struct{}
+	done      chan struct{}
+}
+
+// NewServer loads the traps database and starts listening to the traps
+func NewServer(sender epforwarder.EventPlatformForwarder) (*Server, error) {
+	trapsConfig, err := ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	resolver, err := NewMultiFilesOIDResolver()
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		config:    trapsConfig,
+		formatter: NewFormatter(resolver),
+		sender:    sender,
+		packets:   make(chan *SnmpPacket, packetsChanSize),
+		stopChan:  make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	s.listener, err = NewTrapListener(trapsConfig, s.packets)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.listener.Start(); err != nil {
+		return nil, err
+	}
+	go s.run()
+	return s, nil
+}
+
+func (s *Server) run() {
+	defer close(s.done)
+	for {
+		select {
+		case packet := <-s.packets:
+			s.forward(packet)
+		case <-s.stopChan:
+			return
+		}
+	}
+}
+
+func (s *Server) forward(packet *SnmpPacket) {
+	payload, err := s.formatter.FormatPacket(packet)
+	if err != nil {
+		tlmTrapsDropped.Inc("invalid_trap")
+		log.Debugf("Dropping a trap from %s: %v", packet.Addr, err)
+		return
+	}
+	if err := s.sender.SendEventPlatformEvent(message.NewMessage(payload, nil, ""), epforwarder.EventTypeSnmpTraps); err != nil {
+		tlmTrapsDropped.Inc("forwarder")
+		log.Debugf("Could not forward a trap from %s: %v", packet.Addr, err)
+		return
+	}
+	tlmTrapsForwarded.Inc()
+}
+
+// Stop stops listening and forwarding the traps, giving up after stop_timeout
+func (s *Server) Stop() {
+	s.listener.Stop()
+	close(s.stopChan)
+	select {
+	case <-s.done:
+	case <-time.After(time.Duration(s.config.StopTimeout) * time.Second):
+		log.Warn("Could not stop the SNMP traps forwarder before the timeout")
+	}
+}
@@ -26,35 +26,16 @@ func FormatStatus(data []byte) (string, error) {
 
 	stats := make(map[string]interface{})
 	json.Unmarshal(data, &stats) //nolint:errcheck
-	forwarderStats := stats["forwarderStats"]
-	runnerStats := stats["runnerStats"]
-	pyLoaderStats := stats["pyLoaderStats"]
-	pythonInit := stats["pythonInit"]
-	autoConfigStats := stats["autoConfigStats"]
-	checkSchedulerStats := stats["checkSchedulerStats"]
-	aggregatorStats := stats["aggregatorStats"]
-	dogstatsdStats := stats["dogstatsdStats"]
-	logsStats := stats["logsStats"]
-	dcaStats := stats["clusterAgentStatus"]
-	endpointsInfos := stats["endpointsInfos"]
-	inventoriesStats := stats["inventories"]
-	systemProbeStats := stats["systemProbeStats"]
 	title := fmt.Sprintf("Agent (v%s)", stats["version"])
 	stats["title"] = title
-	renderStatusTemplate(b, "/header.tmpl", stats)
-	renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats, "")
-	renderStatusTemplate(b, "/jmxfetch.tmpl", stats)
-	renderStatusTemplate(b, "/forwarder.tmpl", forwarderStats)
-	renderStatusTemplate(b, "/endpoints.tmpl", endpointsInfos)
-	renderStatusTemplate(b, "/logsagent.tmpl", logsStats)
-	if config.Datadog.GetBool("system_probe_config.enabled") {
-		renderStatusTemplate(b, "/systemprobe.tmpl", systemProbeStats)
-	}
-	renderStatusTemplate(b, "/trace-agent.tmpl", stats["apmStats"])
-	renderStatusTemplate(b, "/aggregator.tmpl", aggregatorStats)
-	renderStatusTemplate(b, "/dogstatsd.tmpl", dogstatsdStats)
-	if config.Datadog.GetBool("cluster_agent.enabled") || config.Datadog.GetBool("cluster_checks.enabled") {
-		renderStatusTemplate(b, "/clusteragent.tmpl", dcaStats)
+	for _, section := range []string{"header", "collector", "forwarder", "endpoints", "logsAgent", "systemProbe", "apm", "aggregator", "dogstatsd", "clusterAgent"} {
+		if section == "systemProbe" && !config.Datadog.GetBool("system_probe_config.enabled") {
+			continue
+		}
+		if section == "clusterAgent" && !config.Datadog.GetBool("cluster_agent.enabled") && !config.Datadog.GetBool("cluster_checks.enabled") {
+			continue
+		}
+		renderStatusSection(b, section, stats)
 	}
 
 	return b.String(), nil
@@ -74,7 +55,7 @@ func FormatDCAStatus(data []byte) (string, error) {
 	title := fmt.Sprintf("Datadog Cluster Agent (v%s)", stats["version"])
 	stats["title"] = title
 	renderStatusTemplate(b, "/header.tmpl", stats)
-	renderChecksStats(b, runnerStats, nil, nil, autoConfigStats, checkSchedulerStats, nil, "")
+	renderChecksStats(b, runnerStats, nil, nil, nil, nil, autoConfigStats, checkSchedulerStats, nil, "")
 	renderStatusTemplate(b, "/forwarder.tmpl", forwarderStats)
 	renderStatusTemplate(b, "/endpoints.tmpl", endpointsInfos)
 
@@ -97,10 +78,11 @@ func FormatSecurityAgentStatus(data []byte) (string, error) {
 	stats := make(map[string]interface{})
 	json.Unmarshal(data, &stats) //nolint:errcheck
 	runnerStats := stats["runnerStats"]
+	complianceStatus := stats["complianceStatus"]
 	title := fmt.Sprintf("Datadog Security Agent (v%s)", stats["version"])
 	stats["title"] = title
 	renderStatusTemplate(b, "/header.tmpl", stats)
-	renderComplianceChecksStats(b, runnerStats)
+	renderComplianceChecksStats(b, runnerStats, complianceStatus)
 
 	return b.String(), nil
 }
@@ -118,11 +100,13 @@ func FormatMetadataMapCLI(data []byte) (string, error) {
 	return b.String(), nil
 }
 
-func renderChecksStats(w io.Writer, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats interface{}, onlyCheck string) {
+func renderChecksStats(w io.Writer, runnerStats, pyLoaderStats, pythonInit, pythonMemory, checkDiagnoses, autoConfigStats, checkSchedulerStats, inventoriesStats interface{}, onlyCheck string) {
 	checkStats := make(map[string]interface{})
 	checkStats["RunnerStats"] = runnerStats
 	checkStats["pyLoaderStats"] = pyLoaderStats
 	checkStats["pythonInit"] = pythonInit
+	checkStats["pythonMemory"] = pythonMemory
+	checkStats["checkDiagnoses"] = checkDiagnoses
 	checkStats["AutoConfigStats"] = autoConfigStats
 	checkStats["CheckSchedulerStats"] = checkSchedulerStats
 	checkStats["OnlyCheck"] = onlyCheck
@@ -138,17 +122,20 @@ func renderCheckStats(data []byte, checkName string) (string, error) {
 	runnerStats := stats["runnerStats"]
 	pyLoaderStats := stats["pyLoaderStats"]
 	pythonInit := stats["pythonInit"]
+	pythonMemory := stats["pythonMemory"]
+	checkDiagnoses := stats["checkDiagnoses"]
 	autoConfigStats := stats["autoConfigStats"]
 	checkSchedulerStats := stats["checkSchedulerStats"]
 	inventoriesStats := stats["inventories"]
-	renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, autoConfigStats, checkSchedulerStats, inventoriesStats, checkName)
+	renderChecksStats(b, runnerStats, pyLoaderStats, pythonInit, pythonMemory, checkDiagnoses, autoConfigStats, checkSchedulerStats, inventoriesStats, checkName)
 
 	return b.String(), nil
 }
 
-func renderComplianceChecksStats(w io.Writer, runnerStats /*, checkSchedulerStats*/ interface{} /*, onlyCheck string*/) {
+func renderComplianceChecksStats(w io.Writer, runnerStats, complianceStatus interface{}) {
 	checkStats := make(map[string]interface{})
 	checkStats["RunnerStats"] = runnerStats
+	checkStats["ComplianceStatus"] = complianceStatus
 	renderStatusTemplate(w, "/compliance.tmpl", checkStats)
 }
 
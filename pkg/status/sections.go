@@ -0,0 +1,109 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// statusSections maps the sections of the agent status to the keys of the status holding their data
+var statusSections = map[string][]string{
+	"header": {
+		"version", "flavor", "conf_file", "pid", "go_version", "python_version", "agent_start", "build_arch", "build_components", "time",
+		"config", "ntpOffset", "clockJumps", "hostinfo", "metadata", "hostTags", "hostnameStats", "cloudProvider", "runnerStats",
+	},
+	"collector": {
+		"runnerStats", "pyLoaderStats", "pythonInit", "autoConfigStats", "checkSchedulerStats", "inventories",
+		"JMXStatus", "JMXStartupError", "config",
+	},
+	"forwarder":    {"forwarderStats"},
+	"endpoints":    {"endpointsInfos"},
+	"logsAgent":    {"logsStats"},
+	"systemProbe":  {"systemProbeStats"},
+	"apm":          {"apmStats"},
+	"aggregator":   {"aggregatorStats"},
+	"dogstatsd":    {"dogstatsdStats"},
+	"clusterAgent": {"clusterAgentStatus"},
+}
+
+// Sections returns the names of the sections of the agent status
+func Sections() []string {
+	sections := make([]string, 0, len(statusSections))
+	for section := range statusSections {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections
+}
+
+// IsSection returns whether the given name is a section of the agent status
+func IsSection(name string) bool {
+	_, found := statusSections[name]
+	return found
+}
+
+// FilterSection returns the part of the agent status holding the data of a section
+func FilterSection(stats map[string]interface{}, section string) (map[string]interface{}, error) {
+	keys, found := statusSections[section]
+	if !found {
+		return nil, fmt.Errorf("unknown status section %s, the sections are: %v", section, Sections())
+	}
+
+	filtered := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		if value, found := stats[key]; found {
+			filtered[key] = value
+		}
+	}
+	return filtered, nil
+}
+
+// FormatSection takes a json bytestring and prints out the formatted section of the statuspage
+func FormatSection(section string, data []byte) (string, error) {
+	if !IsSection(section) {
+		return "", fmt.Errorf("unknown status section %s, the sections are: %v", section, Sections())
+	}
+
+	var b = new(bytes.Buffer)
+	stats := make(map[string]interface{})
+	json.Unmarshal(data, &stats) //nolint:errcheck
+	if section == "header" {
+		stats["title"] = fmt.Sprintf("Agent (v%s)", stats["version"])
+	}
+	renderStatusSection(b, section, stats)
+	return b.String(), nil
+}
+
+// renderStatusSection renders a section of the agent status
+func renderStatusSection(w io.Writer, section string, stats map[string]interface{}) {
+	switch section {
+	case "header":
+		renderStatusTemplate(w, "/header.tmpl", stats)
+	case "collector":
+		renderChecksStats(w, stats["runnerStats"], stats["pyLoaderStats"], stats["pythonInit"], stats["pythonMemory"], stats["checkDiagnoses"], stats["autoConfigStats"], stats["checkSchedulerStats"], stats["inventories"], "")
+		renderStatusTemplate(w, "/jmxfetch.tmpl", stats)
+	case "forwarder":
+		renderStatusTemplate(w, "/forwarder.tmpl", stats["forwarderStats"])
+	case "endpoints":
+		renderStatusTemplate(w, "/endpoints.tmpl", stats["endpointsInfos"])
+	case "logsAgent":
+		renderStatusTemplate(w, "/logsagent.tmpl", stats["logsStats"])
+	case "systemProbe":
+		renderStatusTemplate(w, "/systemprobe.tmpl", stats["systemProbeStats"])
+	case "apm":
+		renderStatusTemplate(w, "/trace-agent.tmpl", stats["apmStats"])
+	case "aggregator":
+		renderStatusTemplate(w, "/aggregator.tmpl", stats["aggregatorStats"])
+	case "dogstatsd":
+		renderStatusTemplate(w, "/dogstatsd.tmpl", stats["dogstatsdStats"])
+	case "clusterAgent":
+		renderStatusTemplate(w, "/clusteragent.tmpl", stats["clusterAgentStatus"])
+	}
+}
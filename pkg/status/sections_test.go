@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package status
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterSection(t *testing.T) {
+	stats := map[string]interface{}{
+		"version":         "7.0.0",
+		"forwarderStats":  map[string]interface{}{"Transactions": 1},
+		"aggregatorStats": map[string]interface{}{"Flush": 2},
+	}
+
+	filtered, err := FilterSection(stats, "forwarder")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"forwarderStats": stats["forwarderStats"]}, filtered)
+
+	filtered, err = FilterSection(stats, "dogstatsd")
+	require.NoError(t, err)
+	assert.Empty(t, filtered)
+
+	_, err = FilterSection(stats, "unknown")
+	assert.Error(t, err)
+}
+
+func TestSections(t *testing.T) {
+	assert.Contains(t, Sections(), "collector")
+	assert.True(t, IsSection("logsAgent"))
+	assert.False(t, IsSection("py"))
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/admission"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/clusterchecks"
 	"github.com/DataDog/datadog-agent/pkg/clusteragent/custommetrics"
+	"github.com/DataDog/datadog-agent/pkg/clusteragent/externalmetrics"
 	"github.com/DataDog/datadog-agent/pkg/util/flavor"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -25,6 +26,8 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/logs"
 	"github.com/DataDog/datadog-agent/pkg/metadata/host"
 	"github.com/DataDog/datadog-agent/pkg/util"
+	"github.com/DataDog/datadog-agent/pkg/util/capabilities"
+	"github.com/DataDog/datadog-agent/pkg/util/clock"
 	"github.com/DataDog/datadog-agent/pkg/util/kubernetes/apiserver"
 	"github.com/DataDog/datadog-agent/pkg/version"
 )
@@ -32,6 +35,11 @@ import (
 var startTime = time.Now()
 var timeFormat = "2006-01-02 15:04:05.000000 MST"
 
+// GetStartTime returns the time the agent started at
+func GetStartTime() time.Time {
+	return startTime
+}
+
 // GetStatus grabs the status from expvar and puts it into a map
 func GetStatus() (map[string]interface{}, error) {
 	stats, err := getCommonStatus()
@@ -49,6 +57,8 @@ func GetStatus() (map[string]interface{}, error) {
 	pythonVersion := host.GetPythonVersion()
 	stats["python_version"] = strings.Split(pythonVersion, " ")[0]
 	stats["hostinfo"] = host.GetStatusInformation()
+	stats["cloudProvider"] = util.GetDetectedCloudProvider()
+	stats["capabilities"] = capabilities.Get()
 
 	stats["JMXStatus"] = GetJMXStatus()
 	stats["JMXStartupError"] = GetJMXStartupError()
@@ -67,12 +77,25 @@ func GetStatus() (map[string]interface{}, error) {
 	}
 
 	if config.Datadog.GetBool("system_probe_config.enabled") {
-		stats["systemProbeStats"] = GetSystemProbeStats(config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+		if err := requireSystemProbeCapabilities(); err != nil {
+			stats["systemProbeStats"] = map[string]interface{}{"Errors": err.Error()}
+		} else {
+			stats["systemProbeStats"] = GetSystemProbeStats(config.Datadog.GetString("system_probe_config.sysprobe_socket"))
+		}
 	}
 
 	return stats, nil
 }
 
+// requireSystemProbeCapabilities returns why the system-probe can't run on Linux, if the kernel
+// doesn't support eBPF
+func requireSystemProbeCapabilities() error {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	return capabilities.Require(capabilities.EBPF)
+}
+
 // GetAndFormatStatus gets and formats the status all in one go
 func GetAndFormatStatus() ([]byte, error) {
 	s, err := GetStatus()
@@ -149,6 +172,9 @@ func GetDCAStatus() (map[string]interface{}, error) {
 		stats["admissionWebhook"] = map[string]string{"Error": err.Error()}
 	} else {
 		stats["custommetrics"] = custommetrics.GetStatus(apiCl.Cl)
+		if config.Datadog.GetBool("external_metrics_provider.enabled") && config.Datadog.GetBool("external_metrics_provider.use_datadogmetric_crd") {
+			stats["externalmetrics"] = externalmetrics.GetStatus()
+		}
 		stats["admissionWebhook"] = admission.GetStatus(apiCl.Cl)
 	}
 
@@ -268,6 +294,7 @@ func getCommonStatus() (map[string]interface{}, error) {
 	stats["go_version"] = runtime.Version()
 	stats["agent_start"] = startTime.Format(timeFormat)
 	stats["build_arch"] = runtime.GOARCH
+	stats["build_components"] = strings.Join(version.Components(), ", ")
 	now := time.Now()
 	stats["time"] = now.Format(timeFormat)
 
@@ -328,6 +355,26 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 		stats["pyLoaderStats"] = nil
 	}
 
+	pythonMemoryData := expvar.Get("pythonMemory")
+	if pythonMemoryData != nil {
+		pythonMemoryJSON := []byte(pythonMemoryData.String())
+		pythonMemory := make(map[string]interface{})
+		json.Unmarshal(pythonMemoryJSON, &pythonMemory) //nolint:errcheck
+		stats["pythonMemory"] = pythonMemory
+	} else {
+		stats["pythonMemory"] = nil
+	}
+
+	checkDiagnosesData := expvar.Get("checkDiagnoses")
+	if checkDiagnosesData != nil {
+		checkDiagnosesJSON := []byte(checkDiagnosesData.String())
+		checkDiagnoses := make(map[string]interface{})
+		json.Unmarshal(checkDiagnosesJSON, &checkDiagnoses) //nolint:errcheck
+		stats["checkDiagnoses"] = checkDiagnoses
+	} else {
+		stats["checkDiagnoses"] = nil
+	}
+
 	pythonInitData := expvar.Get("pythonInit")
 	if pythonInitData != nil {
 		pythonInitJSON := []byte(pythonInitData.String())
@@ -348,6 +395,10 @@ func expvarStats(stats map[string]interface{}) (map[string]interface{}, error) {
 		stats["ntpOffset"], err = strconv.ParseFloat(expvar.Get("ntpOffset").String(), 64)
 	}
 
+	if clockStats := clock.Default().Stats(); clockStats.Count > 0 {
+		stats["clockJumps"] = clockStats
+	}
+
 	inventories := expvar.Get("inventories")
 	var inventoriesStats map[string]interface{}
 	if inventories != nil {
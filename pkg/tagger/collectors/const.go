@@ -13,6 +13,10 @@ const (
 	tagKeyVersion = "version"
 	tagKeyService = "service"
 
+	// Source code tags - Tag keys
+	tagKeyGitCommitSha     = "git.commit.sha"
+	tagKeyGitRepositoryURL = "git.repository_url"
+
 	// Standard K8s labels - Tag keys
 	tagKeyKubeAppName      = "kube_app_name"
 	tagKeyKubeAppInstance  = "kube_app_instance"
@@ -30,4 +34,8 @@ const (
 	dockerLabelEnv     = "com.datadoghq.tags.env"
 	dockerLabelVersion = "com.datadoghq.tags.version"
 	dockerLabelService = "com.datadoghq.tags.service"
+
+	// OCI image label keys
+	dockerLabelGitCommitSha     = "org.opencontainers.image.revision"
+	dockerLabelGitRepositoryURL = "org.opencontainers.image.source"
 )
@@ -87,6 +87,7 @@ func dockerExtractImage(tags *utils.TagList, co types.ContainerJSON, resolve res
 // extracts hard-coded labels from:
 // - Docker swarm
 // - Rancher
+// - OCI image annotations (git commit and repository)
 // - Custom
 func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string, labelsAsTags map[string]string) {
 	for labelName, labelValue := range containerLabels {
@@ -113,6 +114,12 @@ func dockerExtractLabels(tags *utils.TagList, containerLabels map[string]string,
 		case dockerLabelService:
 			tags.AddStandard(tagKeyService, labelValue)
 
+		// Source code tags
+		case dockerLabelGitCommitSha:
+			tags.AddLow(tagKeyGitCommitSha, labelValue)
+		case dockerLabelGitRepositoryURL:
+			tags.AddLow(tagKeyGitRepositoryURL, labelValue)
+
 		// Custom labels as tags
 		case "com.datadoghq.ad.tags":
 			tagNames := []string{}
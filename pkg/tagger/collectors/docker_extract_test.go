@@ -216,6 +216,27 @@ func TestDockerRecordsFromInspect(t *testing.T) {
 			},
 			expectedStandard: []string{},
 		},
+		{
+			testName: "extractOCISourceLabels",
+			co: &types.ContainerJSON{
+				Config: &container.Config{
+					Env: []string{"PATH=/bin"},
+					Labels: map[string]string{
+						"org.opencontainers.image.revision": "0fd2f4a2ba5d6b1a1e2f1d5b0f2f8b6a3b8a1c2d",
+						"org.opencontainers.image.source":   "https://github.com/DataDog/datadog-agent",
+					},
+				},
+			},
+			toRecordEnvAsTags:    map[string]string{},
+			toRecordLabelsAsTags: map[string]string{},
+			expectedLow: []string{
+				"git.commit.sha:0fd2f4a2ba5d6b1a1e2f1d5b0f2f8b6a3b8a1c2d",
+				"git.repository_url:https://github.com/DataDog/datadog-agent",
+			},
+			expectedOrch:     []string{},
+			expectedHigh:     []string{},
+			expectedStandard: []string{},
+		},
 		{
 			testName: "extractNomad",
 			co: &types.ContainerJSON{
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package collectors
+
+import (
+	"time"
+)
+
+// TagProvider is implemented by the custom tag providers compiled in the Agent, to attach
+// business metadata, like the one of a CMDB, to the entities of the tagger. Unlike the
+// other collectors, a provider doesn't detect the entities: the tagger asks it for the tags
+// of an entity on cache misses, and again once the tags have expired.
+type TagProvider interface {
+	// ProvideTags returns the low, orchestrator and high cardinality tags of an entity,
+	// like container_id://<id>. It returns an errors.NotFound error when it has no tags for
+	// the entity. It is called concurrently.
+	ProvideTags(entity string) (low []string, orchestrator []string, high []string, err error)
+}
+
+// TagProviderFactory creates a TagProvider when the tagger starts, an error disables the provider
+type TagProviderFactory func() (TagProvider, error)
+
+// CollectorTTLs holds the durations the tags of the collectors are cached for, the tags
+// of the collectors not listed don't expire
+var CollectorTTLs = make(map[string]time.Duration)
+
+// RegisterTagProvider adds a custom tag provider to the default catalog, it is meant to be
+// called in the init function of the package of the provider. The tags it provides are
+// cached by the tagger for ttl, and are added to the ones of the other collectors, taking
+// precedence over them on conflicts when priority is higher.
+func RegisterTagProvider(name string, factory TagProviderFactory, priority CollectorPriority, ttl time.Duration) {
+	registerCollector(name, func() Collector {
+		return &providerCollector{factory: factory}
+	}, priority)
+	if ttl > 0 {
+		CollectorTTLs[name] = ttl
+	}
+}
+
+// providerCollector runs a TagProvider as a fetch only collector
+type providerCollector struct {
+	factory  TagProviderFactory
+	provider TagProvider
+}
+
+// Detect creates the provider
+func (c *providerCollector) Detect(out chan<- []*TagInfo) (CollectionMode, error) {
+	provider, err := c.factory()
+	if err != nil {
+		return NoCollection, err
+	}
+	c.provider = provider
+	return FetchOnlyCollection, nil
+}
+
+// Fetch asks the provider for the tags of an entity
+func (c *providerCollector) Fetch(entity string) ([]string, []string, []string, error) {
+	return c.provider.ProvideTags(entity)
+}
@@ -5,17 +5,20 @@
 
 package collectors
 
+import "time"
+
 // TagInfo holds the tag information for a given entity and source. It's meant
 // to be created from collectors and read by the store.
 type TagInfo struct {
-	Source               string   // source collector's name
-	Entity               string   // entity name ready for lookup
-	HighCardTags         []string // high cardinality tags that can create a lot of different timeseries (typically one per container, user request, etc.)
-	OrchestratorCardTags []string // orchestrator cardinality tags that have as many combination as pods/tasks
-	LowCardTags          []string // low cardinality tags safe for every pipeline
-	StandardTags         []string // the discovered standard tags (env, version, service) for the entity
-	DeleteEntity         bool     // true if the entity is to be deleted from the store
-	CacheMiss            bool     // true if the TagInfo is generated by a tag miss
+	Source               string    // source collector's name
+	Entity               string    // entity name ready for lookup
+	HighCardTags         []string  // high cardinality tags that can create a lot of different timeseries (typically one per container, user request, etc.)
+	OrchestratorCardTags []string  // orchestrator cardinality tags that have as many combination as pods/tasks
+	LowCardTags          []string  // low cardinality tags safe for every pipeline
+	StandardTags         []string  // the discovered standard tags (env, version, service) for the entity
+	DeleteEntity         bool      // true if the entity is to be deleted from the store
+	CacheMiss            bool      // true if the TagInfo is generated by a tag miss
+	ExpiryDate           time.Time // if set, the tags of the source are fetched again after this date
 }
 
 // CollectionMode informs the Tagger of how to schedule a Collector
@@ -268,14 +268,18 @@ IterCollectors:
 			tagArrays = append(tagArrays, high)
 		}
 		// Submit to cache for next lookup
-		t.tagStore.processTagInfo(&collectors.TagInfo{ //nolint:errcheck
+		info := &collectors.TagInfo{
 			Entity:               entity,
 			Source:               name,
 			LowCardTags:          low,
 			OrchestratorCardTags: orch,
 			HighCardTags:         high,
 			CacheMiss:            cacheMiss,
-		})
+		}
+		if ttl, found := collectors.CollectorTTLs[name]; found {
+			info.ExpiryDate = time.Now().Add(ttl)
+		}
+		t.tagStore.processTagInfo(info) //nolint:errcheck
 	}
 	t.RUnlock()
 
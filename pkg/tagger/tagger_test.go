@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"sort"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -305,3 +306,64 @@ func TestSafeCache(t *testing.T) {
 	assert.NoError(t, err)
 	assert.ElementsMatch(t, []string{"low1", "low2", "low3"}, tags2)
 }
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) ProvideTags(entity string) ([]string, []string, []string, error) {
+	p.calls++
+	return []string{fmt.Sprintf("cmdb_lookup:%d", p.calls)}, nil, nil, nil
+}
+
+func TestTagProvider(t *testing.T) {
+	provider := &countingProvider{}
+	collectors.RegisterTagProvider("cmdb", func() (collectors.TagProvider, error) {
+		return provider, nil
+	}, collectors.NodeRuntime, time.Hour)
+	defer delete(collectors.DefaultCatalog, "cmdb")
+	defer delete(collectors.CollectorPriorities, "cmdb")
+	defer delete(collectors.CollectorTTLs, "cmdb")
+
+	catalog := collectors.Catalog{"pull": NewDummyPuller, "cmdb": collectors.DefaultCatalog["cmdb"]}
+	tagger := newTagger()
+	tagger.Init(catalog)
+	assert.Len(t, tagger.fetchers, 2)
+
+	puller := tagger.pullers["pull"].(*DummyCollector)
+	puller.On("Fetch", "entity_name").Return([]string{"low"}, []string{}, []string{}, nil)
+
+	tags, err := tagger.Tag("entity_name", collectors.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"low", "cmdb_lookup:1"}, tags)
+
+	// the tags of the provider are cached until they expire
+	tags, err = tagger.Tag("entity_name", collectors.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"low", "cmdb_lookup:1"}, tags)
+	assert.Equal(t, 1, provider.calls)
+
+	tagger.tagStore.storeMutex.RLock()
+	tagger.tagStore.store["entity_name"].expiryDates["cmdb"] = time.Now().Add(-time.Second)
+	tagger.tagStore.store["entity_name"].cacheValid = false
+	tagger.tagStore.storeMutex.RUnlock()
+
+	tags, err = tagger.Tag("entity_name", collectors.LowCardinality)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{"low", "cmdb_lookup:2"}, tags)
+	assert.Equal(t, 2, provider.calls)
+	puller.AssertNumberOfCalls(t, "Fetch", 1)
+}
+
+func TestTagProviderInitError(t *testing.T) {
+	collectors.RegisterTagProvider("broken", func() (collectors.TagProvider, error) {
+		return nil, fmt.Errorf("cannot connect")
+	}, collectors.NodeRuntime, time.Hour)
+	defer delete(collectors.DefaultCatalog, "broken")
+	defer delete(collectors.CollectorPriorities, "broken")
+	defer delete(collectors.CollectorTTLs, "broken")
+
+	tagger := newTagger()
+	tagger.Init(collectors.Catalog{"broken": collectors.DefaultCatalog["broken"]})
+	assert.Len(t, tagger.fetchers, 0)
+}
@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -20,7 +21,9 @@ type entityTags struct {
 	orchestratorCardTags map[string][]string
 	highCardTags         map[string][]string
 	standardTags         map[string][]string
+	expiryDates          map[string]time.Time // expiry dates of the sources whose tags expire
 	cacheValid           bool
+	cacheExpiryDate      time.Time // the cache is invalid after the first expiry date of the sources
 	cachedSource         []string
 	cachedAll            []string // Low + orchestrator + high
 	cachedOrchestrator   []string // Low + orchestrator (subslice of cachedAll)
@@ -71,6 +74,7 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 			orchestratorCardTags: make(map[string][]string),
 			highCardTags:         make(map[string][]string),
 			standardTags:         make(map[string][]string),
+			expiryDates:          make(map[string]time.Time),
 		}
 		s.store[info.Entity] = storedTags
 	}
@@ -78,7 +82,7 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 	storedTags.Lock()
 	defer storedTags.Unlock()
 	_, found := storedTags.lowCardTags[info.Source]
-	if found && info.CacheMiss {
+	if found && info.CacheMiss && !storedTags.isExpired(info.Source, time.Now()) {
 		// check if the source tags is already present for this entry
 		// Only check once since we always write all cardinality tag levels.
 		err := fmt.Errorf("try to overwrite an existing entry with and empty cache-miss entry, info.Source: %s, info.Entity: %s", info.Source, info.Entity)
@@ -89,6 +93,11 @@ func (s *tagStore) processTagInfo(info *collectors.TagInfo) error {
 	storedTags.orchestratorCardTags[info.Source] = info.OrchestratorCardTags
 	storedTags.highCardTags[info.Source] = info.HighCardTags
 	storedTags.standardTags[info.Source] = info.StandardTags
+	if info.ExpiryDate.IsZero() {
+		delete(storedTags.expiryDates, info.Source)
+	} else {
+		storedTags.expiryDates[info.Source] = info.ExpiryDate
+	}
 	storedTags.cacheValid = false
 
 	return nil
@@ -179,8 +188,10 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	e.Lock()
 	defer e.Unlock()
 
+	now := time.Now()
+
 	// Cache hit
-	if e.cacheValid {
+	if e.cacheValid && (e.cacheExpiryDate.IsZero() || now.Before(e.cacheExpiryDate)) {
 		if cardinality == collectors.HighCardinality {
 			return e.cachedAll, e.cachedSource, e.tagsHash
 		} else if cardinality == collectors.OrchestratorCardinality {
@@ -193,16 +204,33 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	var sources []string
 	tagPrioMapper := make(map[string][]tagPriority)
 
+	// The expired sources are left out, so that the tagger fetches their tags again
+	var cacheExpiryDate time.Time
+	for _, expiryDate := range e.expiryDates {
+		if now.Before(expiryDate) && (cacheExpiryDate.IsZero() || expiryDate.Before(cacheExpiryDate)) {
+			cacheExpiryDate = expiryDate
+		}
+	}
+
 	for source, tags := range e.lowCardTags {
+		if e.isExpired(source, now) {
+			continue
+		}
 		sources = append(sources, source)
 		insertWithPriority(tagPrioMapper, tags, source, collectors.LowCardinality)
 	}
 
 	for source, tags := range e.orchestratorCardTags {
+		if e.isExpired(source, now) {
+			continue
+		}
 		insertWithPriority(tagPrioMapper, tags, source, collectors.OrchestratorCardinality)
 	}
 
 	for source, tags := range e.highCardTags {
+		if e.isExpired(source, now) {
+			continue
+		}
 		insertWithPriority(tagPrioMapper, tags, source, collectors.HighCardinality)
 	}
 
@@ -238,6 +266,7 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 
 	// Write cache
 	e.cacheValid = true
+	e.cacheExpiryDate = cacheExpiryDate
 	e.cachedSource = sources
 	e.cachedAll = tags
 	e.cachedLow = e.cachedAll[:len(lowCardTags)]
@@ -252,6 +281,12 @@ func (e *entityTags) get(cardinality collectors.TagCardinality) ([]string, []str
 	return lowCardTags, sources, e.tagsHash
 }
 
+// isExpired returns whether the tags of a source have expired, the caller must hold the lock
+func (e *entityTags) isExpired(source string, now time.Time) bool {
+	expiryDate, found := e.expiryDates[source]
+	return found && !now.Before(expiryDate)
+}
+
 func insertWithPriority(tagPrioMapper map[string][]tagPriority, tags []string, source string, cardinality collectors.TagCardinality) {
 	priority, found := collectors.CollectorPriorities[source]
 	if !found {
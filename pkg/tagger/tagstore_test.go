@@ -8,6 +8,7 @@ package tagger
 import (
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
@@ -123,6 +124,46 @@ func (s *StoreTestSuite) TestLookupNotPresent() {
 	assert.Nil(s.T(), sources)
 }
 
+func (s *StoreTestSuite) TestLookupExpired() {
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source1",
+		Entity:      "test",
+		LowCardTags: []string{"tag1"},
+	})
+	s.store.processTagInfo(&collectors.TagInfo{
+		Source:      "source2",
+		Entity:      "test",
+		LowCardTags: []string{"tag2"},
+		ExpiryDate:  time.Now().Add(time.Hour),
+	})
+
+	tags, sources, _ := s.store.lookup("test", collectors.LowCardinality)
+	assert.ElementsMatch(s.T(), []string{"tag1", "tag2"}, tags)
+	assert.ElementsMatch(s.T(), []string{"source1", "source2"}, sources)
+
+	// the expired tags are left out, even if the cache was computed before
+	s.store.storeMutex.RLock()
+	s.store.store["test"].cacheExpiryDate = time.Now().Add(-time.Second)
+	s.store.store["test"].expiryDates["source2"] = time.Now().Add(-time.Second)
+	s.store.storeMutex.RUnlock()
+
+	tags, sources, _ = s.store.lookup("test", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"tag1"}, tags)
+	assert.Equal(s.T(), []string{"source1"}, sources)
+
+	// a cache miss can replace expired tags
+	err := s.store.processTagInfo(&collectors.TagInfo{
+		Source:     "source2",
+		Entity:     "test",
+		CacheMiss:  true,
+		ExpiryDate: time.Now().Add(time.Hour),
+	})
+	assert.NoError(s.T(), err)
+	tags, sources, _ = s.store.lookup("test", collectors.LowCardinality)
+	assert.Equal(s.T(), []string{"tag1"}, tags)
+	assert.ElementsMatch(s.T(), []string{"source1", "source2"}, sources)
+}
+
 func (s *StoreTestSuite) TestPrune() {
 	s.store.toDeleteMutex.RLock()
 	assert.Len(s.T(), s.store.toDelete, 0)
@@ -38,6 +38,7 @@ type Agent struct {
 	Concentrator       *stats.Concentrator
 	Blacklister        *filters.Blacklister
 	Replacer           *filters.Replacer
+	MetaFilter         *filters.MetaFilter
 	ScoreSampler       *Sampler
 	ErrorsScoreSampler *Sampler
 	ExceptionSampler   *sampler.ExceptionSampler
@@ -67,12 +68,17 @@ func NewAgent(ctx context.Context, conf *config.AgentConfig) *Agent {
 	in := make(chan *api.Trace, 5000)
 	out := make(chan *writer.SampledSpans, 1000)
 	statsChan := make(chan []stats.Bucket)
+	aggregators := conf.ExtraAggregators
+	if conf.ContainerMetadataTags {
+		aggregators = aggregatorsWithContainerMetadata(aggregators)
+	}
 
 	return &Agent{
 		Receiver:           api.NewHTTPReceiver(conf, dynConf, in),
-		Concentrator:       stats.NewConcentrator(conf.ExtraAggregators, conf.BucketInterval.Nanoseconds(), statsChan),
+		Concentrator:       stats.NewConcentrator(aggregators, conf.BucketInterval.Nanoseconds(), conf.BucketWindowCount, statsChan),
 		Blacklister:        filters.NewBlacklister(conf.Ignore["resource"]),
 		Replacer:           filters.NewReplacer(conf.ReplaceTags),
+		MetaFilter:         filters.NewMetaFilter(conf.MetaAllowlist, conf.MetaBlocklist),
 		ScoreSampler:       NewScoreSampler(conf),
 		ExceptionSampler:   sampler.NewExceptionSampler(),
 		ErrorsScoreSampler: NewErrorsSampler(conf),
@@ -126,8 +132,12 @@ func (a *Agent) work() {
 }
 
 func (a *Agent) loop() {
+	infoTicker := time.NewTicker(10 * time.Second)
+	defer infoTicker.Stop()
 	for {
 		select {
+		case <-infoTicker.C:
+			info.UpdateMetaFilterInfo(a.MetaFilter.Info())
 		case <-a.ctx.Done():
 			log.Info("Exiting...")
 			if err := a.Receiver.Stop(); err != nil {
@@ -172,9 +182,10 @@ func (a *Agent) Process(t *api.Trace, sublayerCalculator *stats.SublayerCalculat
 	// Extra sanitization steps of the trace.
 	for _, span := range t.Spans {
 		a.obfuscator.Obfuscate(span)
-		Truncate(span)
+		truncateWithRules(span, a.conf.NormalizationRules)
 	}
 	a.Replacer.Replace(t.Spans)
+	a.MetaFilter.Filter(t.Spans)
 
 	{
 		// this section sets up any necessary tags on the root:
@@ -193,6 +204,9 @@ func (a *Agent) Process(t *api.Trace, sublayerCalculator *stats.SublayerCalculat
 	// Figure out the top-level spans and sublayers now as it involves modifying the Metrics map
 	// which is not thread-safe while samplers and Concentrator might modify it too.
 	traceutil.ComputeTopLevel(t.Spans)
+	if a.conf.ContainerMetadataTags {
+		setContainerMetadata(t.Spans, t.ContainerTags)
+	}
 
 	pt := ProcessedTrace{
 		Trace:         t.Spans,
@@ -250,6 +264,7 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpa
 	atomic.AddInt64(stat, 1)
 
 	if priority < 0 {
+		a.TraceWriter.RecordSamplerDrop(len(pt.Trace))
 		return nil, false
 	}
 
@@ -258,6 +273,8 @@ func (a *Agent) sample(ts *info.TagStats, pt ProcessedTrace) (*writer.SampledSpa
 	if sampled {
 		sampler.AddGlobalRate(pt.Root, rate)
 		ss.Trace = pt.Trace
+	} else {
+		a.TraceWriter.RecordSamplerDrop(len(pt.Trace))
 	}
 
 	events, numExtracted := a.EventProcessor.Process(pt.Root, pt.Trace)
@@ -321,5 +338,5 @@ func newEventProcessor(conf *config.AgentConfig) *event.Processor {
 		extractors = append(extractors, event.NewLegacyExtractor(conf.AnalyzedRateByServiceLegacy))
 	}
 
-	return event.NewProcessor(extractors, conf.MaxEPS)
+	return event.NewProcessor(extractors, conf.MaxEPS, conf.MaxEPSByService)
 }
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// containerMetadataKeys are the container tags describing the source code and the image
+// of the service, they are set on the top-level spans and used as stats aggregators so
+// that the versions of a service can be compared.
+var containerMetadataKeys = []string{"git.commit.sha", "image_tag"}
+
+// aggregatorsWithContainerMetadata returns the stats aggregators, with the container
+// metadata keys added when they are not already part of them.
+func aggregatorsWithContainerMetadata(aggregators []string) []string {
+	aggs := make([]string, len(aggregators), len(aggregators)+len(containerMetadataKeys))
+	copy(aggs, aggregators)
+IterKeys:
+	for _, key := range containerMetadataKeys {
+		for _, agg := range aggregators {
+			if agg == key {
+				continue IterKeys
+			}
+		}
+		aggs = append(aggs, key)
+	}
+	return aggs
+}
+
+// setContainerMetadata sets the container metadata found in containerTags, a comma separated
+// list of key:value tags, on the top-level spans of the trace. The values already set by the
+// tracer are kept. ComputeTopLevel must have been called on the trace.
+func setContainerMetadata(spans pb.Trace, containerTags string) {
+	if containerTags == "" {
+		return
+	}
+	metadata := make(map[string]string, len(containerMetadataKeys))
+	for _, tag := range strings.Split(containerTags, ",") {
+		parts := strings.SplitN(tag, ":", 2)
+		if len(parts) != 2 || parts[1] == "" {
+			continue
+		}
+		for _, key := range containerMetadataKeys {
+			if parts[0] == key {
+				metadata[key] = parts[1]
+			}
+		}
+	}
+	if len(metadata) == 0 {
+		return
+	}
+	for _, span := range spans {
+		if !traceutil.HasTopLevel(span) {
+			continue
+		}
+		for key, value := range metadata {
+			if _, ok := traceutil.GetMeta(span, key); !ok {
+				traceutil.SetMeta(span, key, value)
+			}
+		}
+	}
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package agent
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAggregatorsWithContainerMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	extra := []string{"http.status_code", "version"}
+	aggs := aggregatorsWithContainerMetadata(extra)
+	assert.Equal([]string{"http.status_code", "version", "git.commit.sha", "image_tag"}, aggs)
+	assert.Equal([]string{"http.status_code", "version"}, extra)
+
+	aggs = aggregatorsWithContainerMetadata([]string{"image_tag"})
+	assert.Equal([]string{"image_tag", "git.commit.sha"}, aggs)
+}
+
+func TestSetContainerMetadata(t *testing.T) {
+	assert := assert.New(t)
+
+	trace := pb.Trace{
+		{TraceID: 1, SpanID: 1, Service: "web", Meta: map[string]string{}},
+		{TraceID: 1, SpanID: 2, ParentID: 1, Service: "web"},
+		{TraceID: 1, SpanID: 3, ParentID: 1, Service: "db", Meta: map[string]string{"image_tag": "tracer"}},
+	}
+	traceutil.ComputeTopLevel(trace)
+
+	setContainerMetadata(trace, "container_name:web,image_tag:1.2.3,git.commit.sha:abc123,short_image:")
+
+	assert.Equal(map[string]string{"git.commit.sha": "abc123", "image_tag": "1.2.3"}, trace[0].Meta)
+	assert.Nil(trace[1].Meta)
+	assert.Equal(map[string]string{"git.commit.sha": "abc123", "image_tag": "tracer"}, trace[2].Meta)
+
+	setContainerMetadata(trace, "")
+	setContainerMetadata(trace, "container_name:web")
+	assert.Nil(trace[1].Meta)
+}
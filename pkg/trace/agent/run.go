@@ -25,6 +25,7 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
+	"github.com/DataDog/datadog-agent/pkg/util/profiling"
 )
 
 const messageAgentDisabled = `trace-agent not enabled. Set the environment variable
@@ -92,6 +93,14 @@ func Run(ctx context.Context) {
 
 	defer watchdog.LogOnPanic()
 
+	if profiling.IsEnabled() {
+		if err := profiling.StartFromConfig(profiling.ProfileTraceService); err != nil {
+			log.Errorf("Error starting profiler: %v", err)
+		} else {
+			defer profiling.Stop()
+		}
+	}
+
 	if flags.CPUProfile != "" {
 		f, err := os.Create(flags.CPUProfile)
 		if err != nil {
@@ -33,10 +33,24 @@ const (
 // Truncate checks that the span resource, meta and metrics are within the max length
 // and modifies them if they are not
 func Truncate(s *pb.Span) {
+	truncate(s, MaxResourceLen)
+}
+
+// truncateWithRules is Truncate, the maximum resource length being overridden by the
+// normalization rule of the service of the span, if any
+func truncateWithRules(s *pb.Span, rules map[string]*config.NormalizationRule) {
+	maxResourceLen := MaxResourceLen
+	if rule, ok := rules[s.Service]; ok && rule != nil && rule.MaxResourceLen > 0 {
+		maxResourceLen = rule.MaxResourceLen
+	}
+	truncate(s, maxResourceLen)
+}
+
+func truncate(s *pb.Span, maxResourceLen int) {
 	// Resource
-	if len(s.Resource) > MaxResourceLen {
-		s.Resource = traceutil.TruncateUTF8(s.Resource, MaxResourceLen)
-		log.Debugf("span.truncate: truncated `Resource` (max %d chars): %s", MaxResourceLen, s.Resource)
+	if len(s.Resource) > maxResourceLen {
+		s.Resource = traceutil.TruncateUTF8(s.Resource, maxResourceLen)
+		log.Debugf("span.truncate: truncated `Resource` (max %d chars): %s", maxResourceLen, s.Resource)
 	}
 	// Error - Nothing to do
 	// Optional data, Meta & Metrics can be nil
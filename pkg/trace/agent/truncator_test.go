@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/stretchr/testify/assert"
 )
@@ -49,6 +50,23 @@ func TestTruncateLongResource(t *testing.T) {
 	assert.Equal(t, 5000, len(s.Resource))
 }
 
+func TestTruncateLongResourceWithRules(t *testing.T) {
+	rules := map[string]*config.NormalizationRule{
+		"legacy": {MaxResourceLen: 20000},
+	}
+
+	s := testSpan()
+	s.Service = "legacy"
+	s.Resource = strings.Repeat("TOOLONG", 5000)
+	truncateWithRules(s, rules)
+	assert.Equal(t, 20000, len(s.Resource))
+
+	s = testSpan()
+	s.Resource = strings.Repeat("TOOLONG", 5000)
+	truncateWithRules(s, rules)
+	assert.Equal(t, MaxResourceLen, len(s.Resource))
+}
+
 func TestTruncateMetricsPassThru(t *testing.T) {
 	s := testSpan()
 	before := s.Metrics
@@ -32,6 +32,7 @@ import (
 	mainconfig "github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/tagger"
 	"github.com/DataDog/datadog-agent/pkg/tagger/collectors"
+	"github.com/DataDog/datadog-agent/pkg/telemetry"
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/logutil"
@@ -76,9 +77,12 @@ type HTTPReceiver struct {
 	conf    *config.AgentConfig
 	dynConf *sampler.DynamicConfig
 	server  *http.Server
+	// decoders decode the trace payloads off the HTTP handlers goroutines
+	decoders *decoderPool
 
 	debug               bool
-	rateLimiterResponse int // HTTP status code when refusing
+	rateLimiterResponse int   // HTTP status code when refusing
+	shedding            int32 // sheddingLevel set by the watchdog, accessed atomically
 
 	wg   sync.WaitGroup // waits for all requests to be processed
 	exit chan struct{}
@@ -87,10 +91,10 @@ type HTTPReceiver struct {
 // NewHTTPReceiver returns a pointer to a new HTTPReceiver
 func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, out chan *Trace) *HTTPReceiver {
 	rateLimiterResponse := http.StatusOK
-	if config.HasFeature("429") {
+	if config.HasFeature("429") || conf.WatchdogShedding {
 		rateLimiterResponse = http.StatusTooManyRequests
 	}
-	return &HTTPReceiver{
+	r := &HTTPReceiver{
 		Stats:       info.NewReceiverStats(),
 		RateLimiter: newRateLimiter(),
 		out:         out,
@@ -103,6 +107,8 @@ func NewHTTPReceiver(conf *config.AgentConfig, dynConf *sampler.DynamicConfig, o
 
 		exit: make(chan struct{}),
 	}
+	r.decoders = newDecoderPool(r.decodeTraces, conf.DecoderWorkers, conf.DecoderQueueSize)
+	return r
 }
 
 // Start starts doing the HTTP server and is ready to receive traces
@@ -111,17 +117,24 @@ func (r *HTTPReceiver) Start() {
 
 	r.attachDebugHandlers(mux)
 
-	mux.HandleFunc("/spans", r.handleWithVersion(v01, r.handleTraces))
-	mux.HandleFunc("/services", r.handleWithVersion(v01, r.handleServices))
-	mux.HandleFunc("/v0.1/spans", r.handleWithVersion(v01, r.handleTraces))
-	mux.HandleFunc("/v0.1/services", r.handleWithVersion(v01, r.handleServices))
-	mux.HandleFunc("/v0.2/traces", r.handleWithVersion(v02, r.handleTraces))
-	mux.HandleFunc("/v0.2/services", r.handleWithVersion(v02, r.handleServices))
-	mux.HandleFunc("/v0.3/traces", r.handleWithVersion(v03, r.handleTraces))
-	mux.HandleFunc("/v0.3/services", r.handleWithVersion(v03, r.handleServices))
-	mux.HandleFunc("/v0.4/traces", r.handleWithVersion(v04, r.handleTraces))
-	mux.HandleFunc("/v0.4/services", r.handleWithVersion(v04, r.handleServices))
-	mux.Handle("/profiling/v1/input", r.profileProxyHandler())
+	// endpoints lists the endpoints of the tracers, reported by the /info endpoint
+	var endpoints []string
+	handle := func(pattern string, handler http.Handler) {
+		mux.Handle(pattern, handler)
+		endpoints = append(endpoints, pattern)
+	}
+	handle("/spans", r.handleWithVersion(v01, r.handleTraces))
+	handle("/services", r.handleWithVersion(v01, r.handleServices))
+	handle("/v0.1/spans", r.handleWithVersion(v01, r.handleTraces))
+	handle("/v0.1/services", r.handleWithVersion(v01, r.handleServices))
+	handle("/v0.2/traces", r.handleWithVersion(v02, r.handleTraces))
+	handle("/v0.2/services", r.handleWithVersion(v02, r.handleServices))
+	handle("/v0.3/traces", r.handleWithVersion(v03, r.handleTraces))
+	handle("/v0.3/services", r.handleWithVersion(v03, r.handleServices))
+	handle("/v0.4/traces", r.handleWithVersion(v04, r.handleTraces))
+	handle("/v0.4/services", r.handleWithVersion(v04, r.handleServices))
+	handle("/profiling/v1/input", r.profileProxyHandler())
+	mux.Handle("/info", r.makeInfoHandler(append(endpoints, "/info")))
 
 	timeout := 5 * time.Second
 	if r.conf.ReceiverTimeout > 0 {
@@ -196,6 +209,10 @@ func (r *HTTPReceiver) attachDebugHandlers(mux *http.ServeMux) {
 		runtime.SetBlockProfileRate(0)
 	})
 
+	if mainconfig.Datadog.GetBool("telemetry.enabled") {
+		mux.Handle("/telemetry", telemetry.Handler())
+	}
+
 	mux.Handle("/debug/vars", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		// allow the GUI to call this endpoint so that the status can be reported
 		w.Header().Set("Access-Control-Allow-Origin", "http://127.0.0.1:"+mainconfig.Datadog.GetString("GUI_port"))
@@ -256,6 +273,7 @@ func (r *HTTPReceiver) Stop() error {
 		return err
 	}
 	r.wg.Wait()
+	r.decoders.stop()
 	close(r.out)
 	return nil
 }
@@ -268,7 +286,14 @@ func (r *HTTPReceiver) handleWithVersion(v Version, f func(Version, http.Respons
 			return
 		}
 
-		req.Body = NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+		if r.conf.MaxSpooledRequestBytes > r.conf.MaxRequestBytes && req.ContentLength > r.conf.MaxRequestBytes {
+			// the payload is above the in-memory limit, it's decoded from the disk
+			spooled := newSpooledReader(req.Body, r.conf.SpoolDir, r.conf.MaxSpooledRequestBytes)
+			defer spooled.Close()
+			req.Body = NewLimitedReader(spooled, r.conf.MaxSpooledRequestBytes)
+		} else {
+			req.Body = NewLimitedReader(req.Body, r.conf.MaxRequestBytes)
+		}
 
 		f(v, w, req)
 	}
@@ -319,13 +344,14 @@ const (
 	headerTracerVersion = "Datadog-Meta-Tracer-Version"
 )
 
-func (r *HTTPReceiver) tagStats(req *http.Request) *info.TagStats {
+func (r *HTTPReceiver) tagStats(v Version, req *http.Request) *info.TagStats {
 	return r.Stats.GetTagStats(info.Tags{
-		Lang:          req.Header.Get(headerLang),
-		LangVersion:   req.Header.Get(headerLangVersion),
-		Interpreter:   req.Header.Get(headerLangInterpreter),
-		LangVendor:    req.Header.Get(headerLangInterpreterVendor),
-		TracerVersion: req.Header.Get(headerTracerVersion),
+		Lang:            req.Header.Get(headerLang),
+		LangVersion:     req.Header.Get(headerLangVersion),
+		Interpreter:     req.Header.Get(headerLangInterpreter),
+		LangVendor:      req.Header.Get(headerLangInterpreterVendor),
+		TracerVersion:   req.Header.Get(headerTracerVersion),
+		EndpointVersion: string(v),
 	})
 }
 
@@ -355,13 +381,13 @@ func (r *HTTPReceiver) replyOK(v Version, w http.ResponseWriter) {
 
 // handleTraces knows how to handle a bunch of traces
 func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.Request) {
-	ts := r.tagStats(req)
+	ts := r.tagStats(v, req)
 	traceCount, err := traceCount(req)
 	if err != nil {
 		log.Warnf("Error getting trace count: %q. Functionality may be limited.", err)
 	}
 
-	if !r.RateLimiter.Permits(traceCount) {
+	if atomic.LoadInt32(&r.shedding) == int32(sheddingRefuseAll) || !r.RateLimiter.Permits(traceCount) {
 		// this payload can not be accepted
 		io.Copy(ioutil.Discard, req.Body)
 		w.WriteHeader(r.rateLimiterResponse)
@@ -370,7 +396,15 @@ func (r *HTTPReceiver) handleTraces(v Version, w http.ResponseWriter, req *http.
 		return
 	}
 
-	traces, err := r.decodeTraces(v, req)
+	traces, err := r.decoders.decode(v, req)
+	if err == errDecoderQueueFull {
+		// all the decoders are busy, the client should retry later
+		io.Copy(ioutil.Discard, req.Body)
+		w.WriteHeader(http.StatusTooManyRequests)
+		r.replyOK(v, w)
+		atomic.AddInt64(&ts.PayloadRefused, 1)
+		return
+	}
 	if err != nil {
 		httpDecodingError(err, []string{"handler:traces", fmt.Sprintf("v:%s", v)}, w)
 		if err == ErrLimitedReaderLimitReached {
@@ -421,7 +455,7 @@ func (r *HTTPReceiver) processTraces(ts *info.TagStats, containerID string, trac
 
 		atomic.AddInt64(&ts.SpansReceived, int64(spans))
 
-		err := normalizeTrace(ts, trace)
+		err := normalizeTrace(ts, trace, r.conf.NormalizationRules)
 		if err != nil {
 			log.Debug("Dropping invalid trace: %s", err)
 			atomic.AddInt64(&ts.SpansDropped, int64(spans))
@@ -465,6 +499,7 @@ func (r *HTTPReceiver) loop() {
 		case now := <-t.C:
 			metrics.Gauge("datadog.trace_agent.heartbeat", 1, nil, 1)
 			metrics.Gauge("datadog.trace_agent.receiver.out_chan_fill", float64(len(r.out))/float64(cap(r.out)), nil, 1)
+			metrics.Gauge("datadog.trace_agent.receiver.decoder_queue_fill", r.decoders.fill(), nil, 1)
 
 			// We update accStats with the new stats we collected
 			accStats.Acc(r.Stats)
@@ -496,24 +531,70 @@ func (r *HTTPReceiver) loop() {
 // killProcess exits the process with the given msg; replaced in tests.
 var killProcess = func(format string, a ...interface{}) { osutil.Exitf(format, a...) }
 
+// sheddingLevel is how much of the ingestion the receiver sheds to stay within its resource limits
+type sheddingLevel int32
+
+const (
+	// sheddingNone means that every payload is accepted
+	sheddingNone sheddingLevel = iota
+	// sheddingRateLimit means that the payloads are refused by the rate limiter
+	sheddingRateLimit
+	// sheddingRefuseAll means that every payload is refused, the memory being way above its limit
+	sheddingRefuseAll
+)
+
+func (l sheddingLevel) String() string {
+	switch l {
+	case sheddingRateLimit:
+		return "rate_limit"
+	case sheddingRefuseAll:
+		return "refuse_all"
+	default:
+		return "none"
+	}
+}
+
+// sheddingRecoveryFactor is the maximum factor by which the rate limiting rate is raised on each
+// watchdog check once the resource usage is back within its limits, when shedding is enabled
+const sheddingRecoveryFactor = 2
+
+// setShedding updates the shedding level, reporting its changes
+func (r *HTTPReceiver) setShedding(level sheddingLevel) {
+	previous := sheddingLevel(atomic.SwapInt32(&r.shedding, int32(level)))
+	if previous != level {
+		log.Infof("Ingestion shedding level changed from %s to %s", previous, level)
+		metrics.Count("datadog.trace_agent.receiver.shedding_change", 1, []string{"level:" + level.String()}, 1)
+	}
+	metrics.Gauge("datadog.trace_agent.receiver.shedding_level", float64(level), nil, 1)
+}
+
 // watchdog checks the trace-agent's heap and CPU usage and updates the rate limiter using a correct
 // sampling rate to maintain resource usage within set thresholds. These thresholds are defined by
 // the configuration MaxMemory and MaxCPU. If these values are 0, all limits are disabled and the rate
-// limiter will accept everything.
+// limiter will accept everything. When WatchdogShedding is enabled, the receiver refuses every payload
+// instead of killing the process when the memory goes above 1.5x MaxMemory, and the rate limiting rate
+// is raised progressively once the usage is back within the limits.
 func (r *HTTPReceiver) watchdog(now time.Time) {
 	wi := watchdog.Info{
 		Mem: watchdog.Mem(),
 		CPU: watchdog.CPU(now),
 	}
+	level := sheddingNone
 	rateMem := 1.0
 	if r.conf.MaxMemory > 0 {
 		if current, allowed := float64(wi.Mem.Alloc), r.conf.MaxMemory*1.5; current > allowed {
-			// This is a safety mechanism: if the agent is using more than 1.5x max. memory, there
-			// is likely a leak somewhere; we'll kill the process to avoid polluting host memory.
-			metrics.Count("datadog.trace_agent.receiver.oom_kill", 1, nil, 1)
-			metrics.Flush()
-			log.Criticalf("Killing process. Memory threshold exceeded: %.2fM / %.2fM", current/1024/1024, allowed/1024/1024)
-			killProcess("OOM")
+			if r.conf.WatchdogShedding {
+				metrics.Count("datadog.trace_agent.receiver.oom_shedding", 1, nil, 1)
+				log.Errorf("Refusing all payloads. Memory threshold exceeded: %.2fM / %.2fM", current/1024/1024, allowed/1024/1024)
+				level = sheddingRefuseAll
+			} else {
+				// This is a safety mechanism: if the agent is using more than 1.5x max. memory, there
+				// is likely a leak somewhere; we'll kill the process to avoid polluting host memory.
+				metrics.Count("datadog.trace_agent.receiver.oom_kill", 1, nil, 1)
+				metrics.Flush()
+				log.Criticalf("Killing process. Memory threshold exceeded: %.2fM / %.2fM", current/1024/1024, allowed/1024/1024)
+				killProcess("OOM")
+			}
 		}
 		rateMem = computeRateLimitingRate(r.conf.MaxMemory, float64(wi.Mem.Alloc), r.RateLimiter.RealRate())
 		if rateMem < 1 {
@@ -528,7 +609,16 @@ func (r *HTTPReceiver) watchdog(now time.Time) {
 		}
 	}
 
-	r.RateLimiter.SetTargetRate(math.Min(rateCPU, rateMem))
+	rate := math.Min(rateCPU, rateMem)
+	if previous := r.RateLimiter.TargetRate(); r.conf.WatchdogShedding && rate > previous {
+		// recover progressively, not to go over the limits again right away
+		rate = math.Min(rate, previous*sheddingRecoveryFactor)
+	}
+	r.RateLimiter.SetTargetRate(rate)
+	if level == sheddingNone && rate < 1 {
+		level = sheddingRateLimit
+	}
+	r.setShedding(level)
 
 	stats := r.RateLimiter.Stats()
 
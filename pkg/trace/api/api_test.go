@@ -11,6 +11,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -112,6 +113,41 @@ func TestReceiverRequestBodyLength(t *testing.T) {
 	testBody(http.StatusRequestEntityTooLarge, " []")
 }
 
+func TestReceiverSpooledRequestBody(t *testing.T) {
+	assert := assert.New(t)
+
+	conf := newTestReceiverConfig()
+	conf.MaxRequestBytes = 2
+	conf.MaxSpooledRequestBytes = 10
+	receiver := newTestReceiverFromConfig(conf)
+	go receiver.Start()
+
+	defer receiver.Stop()
+
+	url := fmt.Sprintf("http://%s:%d/v0.4/traces",
+		conf.ReceiverHost, conf.ReceiverPort)
+
+	// Before going further, make sure receiver is started
+	// since it's running in another goroutine
+	for i := 0; i < 10; i++ {
+		resp, err := http.Post(url, "", bytes.NewBufferString("[]"))
+		if err == nil && resp.StatusCode == http.StatusOK {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	testBody := func(expectedStatus int, bodyData string) {
+		resp, err := http.Post(url, "", bytes.NewBufferString(bodyData))
+		assert.Nil(err)
+		assert.Equal(expectedStatus, resp.StatusCode)
+	}
+
+	// above the in-memory limit, the payloads are spooled up to MaxSpooledRequestBytes
+	testBody(http.StatusOK, "   []")
+	testBody(http.StatusRequestEntityTooLarge, "          []")
+}
+
 func TestLegacyReceiver(t *testing.T) {
 	// testing traces without content-type in agent endpoints, it should use JSON decoding
 	assert := assert.New(t)
@@ -344,7 +380,7 @@ func TestReceiverDecodingError(t *testing.T) {
 		assert.NoError(err)
 
 		assert.Equal(400, resp.StatusCode)
-		assert.EqualValues(0, r.Stats.GetTagStats(info.Tags{}).TracesDropped.DecodingError)
+		assert.EqualValues(0, r.Stats.GetTagStats(info.Tags{EndpointVersion: string(v04)}).TracesDropped.DecodingError)
 	})
 
 	t.Run("with-header", func(t *testing.T) {
@@ -358,7 +394,7 @@ func TestReceiverDecodingError(t *testing.T) {
 		assert.NoError(err)
 
 		assert.Equal(400, resp.StatusCode)
-		assert.EqualValues(traceCount, r.Stats.GetTagStats(info.Tags{}).TracesDropped.DecodingError)
+		assert.EqualValues(traceCount, r.Stats.GetTagStats(info.Tags{EndpointVersion: string(v04)}).TracesDropped.DecodingError)
 	})
 }
 
@@ -431,7 +467,7 @@ func TestHandleTraces(t *testing.T) {
 
 	// We test stats for each app
 	for _, lang := range langs {
-		ts, ok := rs.Stats[info.Tags{Lang: lang}]
+		ts, ok := rs.Stats[info.Tags{Lang: lang, EndpointVersion: string(v04)}]
 		assert.True(ok)
 		assert.Equal(int64(20), ts.TracesReceived)
 		assert.Equal(int64(59222), ts.TracesBytes)
@@ -736,6 +772,34 @@ func TestWatchdog(t *testing.T) {
 	})
 }
 
+func TestWatchdogShedding(t *testing.T) {
+	cfg := config.New()
+	cfg.MaxCPU = 0
+	r := &HTTPReceiver{
+		conf:        cfg,
+		RateLimiter: newRateLimiter(),
+	}
+
+	// way above the memory limit, every payload is refused
+	cfg.MaxMemory = 1
+	r.watchdog(time.Now())
+	assert.Equal(t, int32(sheddingRefuseAll), atomic.LoadInt32(&r.shedding))
+	rate := r.RateLimiter.TargetRate()
+	assert.True(t, rate < 1)
+
+	// back within the limits, the rate is raised progressively
+	cfg.MaxMemory = 1e12
+	for rate < 1 {
+		r.watchdog(time.Now())
+		assert.Equal(t, math.Min(1, 2*rate), r.RateLimiter.TargetRate())
+		rate = r.RateLimiter.TargetRate()
+		if rate < 1 {
+			assert.Equal(t, int32(sheddingRateLimit), atomic.LoadInt32(&r.shedding))
+		}
+	}
+	assert.Equal(t, int32(sheddingNone), atomic.LoadInt32(&r.shedding))
+}
+
 func TestOOMKill(t *testing.T) {
 	var kills uint64
 
@@ -750,6 +814,7 @@ func TestOOMKill(t *testing.T) {
 	conf := config.New()
 	conf.Endpoints[0].APIKey = "apikey_2"
 	conf.WatchdogInterval = time.Millisecond
+	conf.WatchdogShedding = false
 	conf.MaxMemory = 0.5 * 1000 * 1000 // 0.5M
 
 	r := newTestReceiverFromConfig(conf)
@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
+)
+
+// defaultDecoderQueueSize is the number of payloads waiting for a decoder above which the
+// payloads are refused, when not configured
+const defaultDecoderQueueSize = 100
+
+var (
+	// errDecoderQueueFull is returned when a payload is refused because all the decoders
+	// are busy and the queue is full
+	errDecoderQueueFull = errors.New("decoder queue is full")
+	// errDecodersStopped is returned when a payload is queued while the receiver stops
+	errDecodersStopped = errors.New("receiver is stopping")
+)
+
+// decodeJob is a trace payload queued for a decoder
+type decodeJob struct {
+	v        Version
+	req      *http.Request
+	queuedAt time.Time
+	done     chan decodeResult
+}
+
+type decodeResult struct {
+	traces pb.Traces
+	err    error
+}
+
+// decoderPool decodes the trace payloads with a bounded number of workers, so that a burst of
+// large payloads doesn't blow up the number of goroutines and the memory
+type decoderPool struct {
+	queue chan *decodeJob
+	exit  chan struct{}
+}
+
+// newDecoderPool starts the given number of decoders, using the number of CPUs when it's not
+// positive, which take the payloads from a queue of the given size
+func newDecoderPool(decode func(Version, *http.Request) (pb.Traces, error), workers, queueSize int) *decoderPool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if queueSize <= 0 {
+		queueSize = defaultDecoderQueueSize
+	}
+	p := &decoderPool{
+		queue: make(chan *decodeJob, queueSize),
+		exit:  make(chan struct{}),
+	}
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer watchdog.LogOnPanic()
+			p.run(decode)
+		}()
+	}
+	return p
+}
+
+func (p *decoderPool) run(decode func(Version, *http.Request) (pb.Traces, error)) {
+	for {
+		select {
+		case <-p.exit:
+			return
+		case job := <-p.queue:
+			metrics.Timing("datadog.trace_agent.receiver.decoder_queue_time", time.Since(job.queuedAt), nil, 1)
+			traces, err := decode(job.v, job.req)
+			job.done <- decodeResult{traces: traces, err: err}
+		}
+	}
+}
+
+// decode queues a payload and waits for it to be decoded. It returns errDecoderQueueFull
+// without waiting when the queue is full.
+func (p *decoderPool) decode(v Version, req *http.Request) (pb.Traces, error) {
+	job := &decodeJob{
+		v:        v,
+		req:      req,
+		queuedAt: time.Now(),
+		done:     make(chan decodeResult, 1),
+	}
+	select {
+	case p.queue <- job:
+	default:
+		return nil, errDecoderQueueFull
+	}
+	select {
+	case res := <-job.done:
+		return res.traces, res.err
+	case <-p.exit:
+		return nil, errDecodersStopped
+	}
+}
+
+// fill returns the ratio of the queue used by the payloads waiting for a decoder
+func (p *decoderPool) fill() float64 {
+	return float64(len(p.queue)) / float64(cap(p.queue))
+}
+
+// stop stops the decoders, the payloads still queued are not decoded
+func (p *decoderPool) stop() {
+	close(p.exit)
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/test/testutil"
+)
+
+func TestDecoderPool(t *testing.T) {
+	assert := assert.New(t)
+
+	release := make(chan struct{})
+	var started sync.WaitGroup
+	started.Add(1)
+	pool := newDecoderPool(func(v Version, req *http.Request) (pb.Traces, error) {
+		if req.Method == "BLOCK" {
+			started.Done()
+			<-release
+		}
+		return nil, errors.New(req.Method)
+	}, 1, 1)
+	defer pool.stop()
+
+	// the single decoder is busy with the first payload, the second one is queued
+	// and the third one is refused
+	results := make(chan error, 2)
+	go func() {
+		_, err := pool.decode(v04, &http.Request{Method: "BLOCK"})
+		results <- err
+	}()
+	started.Wait()
+	go func() {
+		_, err := pool.decode(v04, &http.Request{Method: "QUEUED"})
+		results <- err
+	}()
+	for pool.fill() < 1 {
+		time.Sleep(time.Millisecond)
+	}
+	_, err := pool.decode(v04, &http.Request{Method: "REFUSED"})
+	assert.Equal(errDecoderQueueFull, err)
+
+	close(release)
+	errs := []string{(<-results).Error(), (<-results).Error()}
+	sort.Strings(errs)
+	assert.Equal([]string{"BLOCK", "QUEUED"}, errs)
+}
+
+func TestHandleTracesDecoderQueueFull(t *testing.T) {
+	assert := assert.New(t)
+
+	var buf bytes.Buffer
+	msgp.Encode(&buf, testutil.GetTestTraces(10, 10, true))
+
+	conf := newTestReceiverConfig()
+	receiver := newTestReceiverFromConfig(conf)
+	// replace the decoders by a pool whose queue is full
+	receiver.decoders.stop()
+	receiver.decoders = &decoderPool{queue: make(chan *decodeJob, 1), exit: make(chan struct{})}
+	receiver.decoders.queue <- &decodeJob{}
+
+	handler := http.HandlerFunc(receiver.handleWithVersion(v04, receiver.handleTraces))
+	rr := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v0.4/traces", bytes.NewReader(buf.Bytes()))
+	req.Header.Set("Content-Type", "application/msgpack")
+	handler.ServeHTTP(rr, req)
+
+	assert.Equal(http.StatusTooManyRequests, rr.Code)
+	ts := receiver.Stats.GetTagStats(info.Tags{EndpointVersion: string(v04)})
+	assert.EqualValues(1, ts.PayloadRefused)
+	assert.EqualValues(0, ts.TracesReceived)
+}
@@ -0,0 +1,98 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
+)
+
+// infoResponse is the response of the /info endpoint, which lets the tracers discover the
+// capabilities and the configuration of the agent instead of assuming them.
+type infoResponse struct {
+	Version      string     `json:"version"`
+	GitCommit    string     `json:"git_commit"`
+	Endpoints    []string   `json:"endpoints"`
+	FeatureFlags []string   `json:"feature_flags"`
+	Config       infoConfig `json:"config"`
+}
+
+// infoConfig is the part of the configuration of the agent relevant to the tracers.
+type infoConfig struct {
+	DefaultEnv             string                        `json:"default_env"`
+	ReceiverPort           int                           `json:"receiver_port"`
+	ReceiverSocket         string                        `json:"receiver_socket,omitempty"`
+	MaxRequestBytes        int64                         `json:"max_request_bytes"`
+	TargetTPS              float64                       `json:"target_tps"`
+	MaxEPS                 float64                       `json:"max_eps"`
+	AnalyzedSpansByService map[string]map[string]float64 `json:"analyzed_spans_by_service"`
+	Obfuscation            infoObfuscation               `json:"obfuscation"`
+}
+
+// infoObfuscation reports which obfuscators are enabled, the tracers can skip obfuscating
+// what the agent already does.
+type infoObfuscation struct {
+	ElasticSearch         bool `json:"elasticsearch"`
+	Mongo                 bool `json:"mongo"`
+	HTTPRemoveQueryString bool `json:"http_remove_query_string"`
+	HTTPRemovePathDigits  bool `json:"http_remove_path_digits"`
+	RemoveStackTraces     bool `json:"remove_stack_traces"`
+	Redis                 bool `json:"redis"`
+	Memcached             bool `json:"memcached"`
+	GraphQL               bool `json:"graphql"`
+	GRPC                  bool `json:"grpc"`
+}
+
+// makeInfoHandler returns the handler of the /info endpoint, listing the given endpoints. The
+// response is computed once, as the configuration doesn't change once the agent is started.
+func (r *HTTPReceiver) makeInfoHandler(endpoints []string) http.HandlerFunc {
+	response := infoResponse{
+		Version:      info.Version,
+		GitCommit:    info.GitCommit,
+		Endpoints:    endpoints,
+		FeatureFlags: config.Features(),
+		Config: infoConfig{
+			DefaultEnv:             r.conf.DefaultEnv,
+			ReceiverPort:           r.conf.ReceiverPort,
+			ReceiverSocket:         r.conf.ReceiverSocket,
+			MaxRequestBytes:        r.conf.MaxRequestBytes,
+			TargetTPS:              r.conf.MaxTPS,
+			MaxEPS:                 r.conf.MaxEPS,
+			AnalyzedSpansByService: r.conf.AnalyzedSpansByService,
+		},
+	}
+	if o := r.conf.Obfuscation; o != nil {
+		response.Config.Obfuscation = infoObfuscation{
+			ElasticSearch:         o.ES.Enabled,
+			Mongo:                 o.Mongo.Enabled,
+			HTTPRemoveQueryString: o.HTTP.RemoveQueryString,
+			HTTPRemovePathDigits:  o.HTTP.RemovePathDigits,
+			RemoveStackTraces:     o.RemoveStackTraces,
+			Redis:                 o.Redis.Enabled,
+			Memcached:             o.Memcached.Enabled,
+			GraphQL:               o.GraphQL.Enabled,
+			GRPC:                  o.GRPC.Enabled,
+		}
+	}
+	if response.FeatureFlags == nil {
+		response.FeatureFlags = []string{}
+	}
+	body, err := json.MarshalIndent(response, "", "\t")
+	if err != nil {
+		// can't happen, the response only holds basic types
+		panic(err)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if _, err := w.Write(body); err != nil {
+			metrics.Count(receiverErrorKey, 1, []string{"error:response-error"}, 1)
+		}
+	}
+}
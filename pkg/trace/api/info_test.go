@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInfoHandler(t *testing.T) {
+	assert := assert.New(t)
+
+	defer os.Setenv("DD_APM_FEATURES", os.Getenv("DD_APM_FEATURES"))
+	os.Setenv("DD_APM_FEATURES", "feature_a, feature_b")
+
+	conf := newTestReceiverConfig()
+	conf.DefaultEnv = "prod"
+	conf.AnalyzedSpansByService = map[string]map[string]float64{"web": {"request": 0.5}}
+	conf.Obfuscation = &config.ObfuscationConfig{
+		Redis:   config.Enablable{Enabled: true},
+		GraphQL: config.Enablable{Enabled: true},
+	}
+	r := newTestReceiverFromConfig(conf)
+
+	rec := httptest.NewRecorder()
+	r.makeInfoHandler([]string{"/v0.4/traces", "/info"}).ServeHTTP(rec, httptest.NewRequest("GET", "/info", nil))
+	assert.Equal(http.StatusOK, rec.Code)
+	assert.Equal("application/json", rec.Header().Get("Content-Type"))
+
+	var resp infoResponse
+	assert.NoError(json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Equal([]string{"/v0.4/traces", "/info"}, resp.Endpoints)
+	assert.Equal([]string{"feature_a", "feature_b"}, resp.FeatureFlags)
+	assert.Equal("prod", resp.Config.DefaultEnv)
+	assert.Equal(8126, resp.Config.ReceiverPort)
+	assert.Equal(conf.MaxRequestBytes, resp.Config.MaxRequestBytes)
+	assert.Equal(conf.MaxTPS, resp.Config.TargetTPS)
+	assert.Equal(conf.MaxEPS, resp.Config.MaxEPS)
+	assert.Equal(conf.AnalyzedSpansByService, resp.Config.AnalyzedSpansByService)
+	assert.Equal(infoObfuscation{Redis: true, GraphQL: true}, resp.Config.Obfuscation)
+}
+
+func TestInfoEndpoint(t *testing.T) {
+	if testing.Short() {
+		return
+	}
+
+	r := newTestReceiverFromConfig(newTestReceiverConfig())
+	r.Start()
+	defer r.Stop()
+
+	resp, err := http.Get("http://localhost:8126/info")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var info infoResponse
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&info))
+	assert.Contains(t, info.Endpoints, "/v0.4/traces")
+	assert.Contains(t, info.Endpoints, "/profiling/v1/input")
+	assert.Contains(t, info.Endpoints, "/info")
+}
@@ -17,6 +17,7 @@ import (
 	"unicode"
 	"unicode/utf8"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
@@ -42,8 +43,8 @@ var (
 )
 
 // normalize makes sure a Span is properly initialized and encloses the minimum required info, returning error if it
-// is invalid beyond repair
-func normalize(ts *info.TagStats, s *pb.Span) error {
+// is invalid beyond repair. The limits are overridden by the normalization rule of the service of the span, if any.
+func normalize(ts *info.TagStats, s *pb.Span, rules map[string]*config.NormalizationRule) error {
 	fallbackServiceName := DefaultServiceName
 	if ts.Lang != "" {
 		fallbackServiceName = fmt.Sprintf("unnamed-%s-service", ts.Lang)
@@ -75,18 +76,24 @@ func normalize(ts *info.TagStats, s *pb.Span) error {
 	}
 	s.Service = svc
 
+	maxNameLen := MaxNameLen
+	rule := rules[s.Service]
+	if rule != nil && rule.MaxNameLen > 0 {
+		maxNameLen = rule.MaxNameLen
+	}
+
 	if s.Name == "" {
 		atomic.AddInt64(&ts.SpansMalformed.SpanNameEmpty, 1)
 		log.Debugf("Fixing malformed trace. Name is empty (reason:span_name_empty), setting span.name=%s: %s", DefaultSpanName, s)
 		s.Name = DefaultSpanName
 	}
-	if len(s.Name) > MaxNameLen {
+	if len(s.Name) > maxNameLen {
 		atomic.AddInt64(&ts.SpansMalformed.SpanNameTruncate, 1)
-		log.Debugf("Fixing malformed trace. Name is too long (reason:span_name_truncate), truncating span.name to length=%d: %s", MaxServiceLen, s)
-		s.Name = traceutil.TruncateUTF8(s.Name, MaxNameLen)
+		log.Debugf("Fixing malformed trace. Name is too long (reason:span_name_truncate), truncating span.name to length=%d: %s", maxNameLen, s)
+		s.Name = traceutil.TruncateUTF8(s.Name, maxNameLen)
 	}
 	// name shall comply with Datadog metric name normalization
-	name, ok := normMetricNameParse(s.Name)
+	name, ok := normMetricNameParse(s.Name, maxNameLen)
 	if !ok {
 		atomic.AddInt64(&ts.SpansMalformed.SpanNameInvalid, 1)
 		log.Debugf("Fixing malformed trace. Name is invalid (reason:span_name_invalid), setting span.name=%s: %s", DefaultSpanName, s)
@@ -140,6 +147,11 @@ func normalize(ts *info.TagStats, s *pb.Span) error {
 		log.Debugf("Fixing malformed trace. Type is too long (reason:type_truncate), truncating span.type to length=%d: %s", MaxTypeLen, s)
 		s.Type = traceutil.TruncateUTF8(s.Type, MaxTypeLen)
 	}
+	if rule != nil && len(rule.AllowedTypes) > 0 && s.Type != "" && !isAllowedType(s.Type, rule.AllowedTypes) {
+		atomic.AddInt64(&ts.SpansMalformed.TypeNotAllowed, 1)
+		log.Debugf("Fixing malformed trace. Type is not allowed for the service (reason:type_not_allowed), setting span.type=\"\": %s", s)
+		s.Type = ""
+	}
 	for k, v := range s.Meta {
 		utf8K := toUTF8(k)
 		if k != utf8K {
@@ -169,7 +181,7 @@ func normalize(ts *info.TagStats, s *pb.Span) error {
 // * return the normalized trace and an error:
 //   - nil if the trace can be accepted
 //   - a reason tag explaining the reason the traces failed normalization
-func normalizeTrace(ts *info.TagStats, t pb.Trace) error {
+func normalizeTrace(ts *info.TagStats, t pb.Trace, rules map[string]*config.NormalizationRule) error {
 	if len(t) == 0 {
 		atomic.AddInt64(&ts.TracesDropped.EmptyTrace, 1)
 		return errors.New("trace is empty (reason:empty_trace)")
@@ -183,7 +195,7 @@ func normalizeTrace(ts *info.TagStats, t pb.Trace) error {
 			atomic.AddInt64(&ts.TracesDropped.ForeignSpan, 1)
 			return fmt.Errorf("trace has foreign span (reason:foreign_span): %s", span)
 		}
-		if err := normalize(ts, span); err != nil {
+		if err := normalize(ts, span, rules); err != nil {
 			return err
 		}
 		if _, ok := spanIDs[span.SpanID]; ok {
@@ -196,6 +208,15 @@ func normalizeTrace(ts *info.TagStats, t pb.Trace) error {
 	return nil
 }
 
+func isAllowedType(typ string, allowed []string) bool {
+	for _, t := range allowed {
+		if typ == t {
+			return true
+		}
+	}
+	return false
+}
+
 func isValidStatusCode(sc string) bool {
 	if code, err := strconv.ParseUint(sc, 10, 64); err == nil {
 		return 100 <= code && code < 600
@@ -217,8 +238,8 @@ func isAlphaNum(b byte) bool {
 
 // normMetricNameParse normalizes metric names with a parser instead of using
 // garbage-creating string replacement routines.
-func normMetricNameParse(name string) (string, bool) {
-	if name == "" || len(name) > MaxNameLen {
+func normMetricNameParse(name string, maxLen int) (string, bool) {
+	if name == "" || len(name) > maxLen {
 		return name, false
 	}
 
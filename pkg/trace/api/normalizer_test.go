@@ -15,6 +15,7 @@ import (
 	"time"
 	"unicode"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/stretchr/testify/assert"
@@ -59,7 +60,7 @@ func tsDropped(td *info.TracesDropped) *info.TagStats {
 func TestNormalizeOK(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, newTagStats(), ts)
 }
 
@@ -67,7 +68,7 @@ func TestNormalizeServicePassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Service
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Service)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -76,7 +77,7 @@ func TestNormalizeEmptyServiceNoLang(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Service = ""
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, DefaultServiceName, s.Service)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{ServiceEmpty: 1}), ts)
 }
@@ -86,7 +87,7 @@ func TestNormalizeEmptyServiceWithLang(t *testing.T) {
 	s := newTestSpan()
 	s.Service = ""
 	ts.Lang = "java"
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Service, fmt.Sprintf("unnamed-%s-service", ts.Lang))
 	tsExpected := tsMalformed(&info.SpansMalformed{ServiceEmpty: 1})
 	tsExpected.Lang = ts.Lang
@@ -97,7 +98,7 @@ func TestNormalizeLongService(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Service = strings.Repeat("CAMEMBERT", 100)
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Service, s.Service[:MaxServiceLen])
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{ServiceTruncate: 1}), ts)
 }
@@ -106,7 +107,7 @@ func TestNormalizeNamePassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Name
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Name)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -115,7 +116,7 @@ func TestNormalizeEmptyName(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Name = ""
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Name, DefaultSpanName)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{SpanNameEmpty: 1}), ts)
 }
@@ -124,7 +125,7 @@ func TestNormalizeLongName(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Name = strings.Repeat("CAMEMBERT", 100)
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Name, s.Name[:MaxNameLen])
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{SpanNameTruncate: 1}), ts)
 }
@@ -133,7 +134,7 @@ func TestNormalizeNameNoAlphanumeric(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Name = "/"
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Name, DefaultSpanName)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{SpanNameInvalid: 1}), ts)
 }
@@ -148,7 +149,7 @@ func TestNormalizeNameForMetrics(t *testing.T) {
 	s := newTestSpan()
 	for name, expName := range expNames {
 		s.Name = name
-		assert.NoError(t, normalize(ts, s))
+		assert.NoError(t, normalize(ts, s, nil))
 		assert.Equal(t, expName, s.Name)
 		assert.Equal(t, newTagStats(), ts)
 	}
@@ -158,7 +159,7 @@ func TestNormalizeResourcePassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Resource
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Resource)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -167,7 +168,7 @@ func TestNormalizeEmptyResource(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Resource = ""
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, s.Resource, s.Name)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{ResourceEmpty: 1}), ts)
 }
@@ -176,7 +177,7 @@ func TestNormalizeTraceIDPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.TraceID
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.TraceID)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -185,7 +186,7 @@ func TestNormalizeNoTraceID(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.TraceID = 0
-	assert.Error(t, normalize(ts, s))
+	assert.Error(t, normalize(ts, s, nil))
 	assert.Equal(t, tsDropped(&info.TracesDropped{TraceIDZero: 1}), ts)
 }
 
@@ -193,7 +194,7 @@ func TestNormalizeSpanIDPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.SpanID
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.SpanID)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -202,7 +203,7 @@ func TestNormalizeNoSpanID(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.SpanID = 0
-	assert.Error(t, normalize(ts, s))
+	assert.Error(t, normalize(ts, s, nil))
 	assert.Equal(t, tsDropped(&info.TracesDropped{SpanIDZero: 1}), ts)
 }
 
@@ -211,7 +212,7 @@ func TestNormalizeStart(t *testing.T) {
 		ts := newTagStats()
 		s := newTestSpan()
 		before := s.Start
-		assert.NoError(t, normalize(ts, s))
+		assert.NoError(t, normalize(ts, s, nil))
 		assert.Equal(t, before, s.Start)
 		assert.Equal(t, newTagStats(), ts)
 	})
@@ -221,7 +222,7 @@ func TestNormalizeStart(t *testing.T) {
 		s := newTestSpan()
 		s.Start = 42
 		minStart := time.Now().UnixNano() - s.Duration
-		assert.NoError(t, normalize(ts, s))
+		assert.NoError(t, normalize(ts, s, nil))
 		assert.True(t, s.Start >= minStart)
 		assert.True(t, s.Start <= time.Now().UnixNano()-s.Duration)
 		assert.Equal(t, tsMalformed(&info.SpansMalformed{InvalidStartDate: 1}), ts)
@@ -233,7 +234,7 @@ func TestNormalizeStart(t *testing.T) {
 		s.Start = 42
 		s.Duration = time.Now().UnixNano() * 2
 		minStart := time.Now().UnixNano()
-		assert.NoError(t, normalize(ts, s))
+		assert.NoError(t, normalize(ts, s, nil))
 		assert.Equal(t, tsMalformed(&info.SpansMalformed{InvalidStartDate: 1}), ts)
 		assert.True(t, s.Start >= minStart, "start should have been reset to current time")
 		assert.True(t, s.Start <= time.Now().UnixNano(), "start should have been reset to current time")
@@ -244,7 +245,7 @@ func TestNormalizeDurationPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Duration
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Duration)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -253,7 +254,7 @@ func TestNormalizeEmptyDuration(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Duration = 0
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.EqualValues(t, s.Duration, 0)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -262,7 +263,7 @@ func TestNormalizeNegativeDuration(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Duration = -50
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.EqualValues(t, s.Duration, 0)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{InvalidDuration: 1}), ts)
 }
@@ -271,7 +272,7 @@ func TestNormalizeLargeDuration(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Duration = int64(math.MaxInt64)
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.EqualValues(t, s.Duration, 0)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{InvalidDuration: 1}), ts)
 }
@@ -280,7 +281,7 @@ func TestNormalizeErrorPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Error
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Error)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -289,7 +290,7 @@ func TestNormalizeMetricsPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Metrics
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Metrics)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -298,7 +299,7 @@ func TestNormalizeMetaPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Meta
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Meta)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -307,7 +308,7 @@ func TestNormalizeParentIDPassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.ParentID
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.ParentID)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -316,7 +317,7 @@ func TestNormalizeTypePassThru(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	before := s.Type
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, before, s.Type)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -325,15 +326,45 @@ func TestNormalizeTypeTooLong(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Type = strings.Repeat("sql", 1000)
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{TypeTruncate: 1}), ts)
 }
 
+func TestNormalizeRules(t *testing.T) {
+	rules := map[string]*config.NormalizationRule{
+		"legacy": {MaxNameLen: 200, AllowedTypes: []string{"web", "sql"}},
+	}
+
+	ts := newTagStats()
+	s := newTestSpan()
+	s.Service = "legacy"
+	s.Name = strings.Repeat("a", 150)
+	s.Type = "web"
+	assert.NoError(t, normalize(ts, s, rules))
+	assert.Equal(t, strings.Repeat("a", 150), s.Name)
+	assert.Equal(t, "web", s.Type)
+	assert.Equal(t, newTagStats(), ts)
+
+	s.Type = "http"
+	assert.NoError(t, normalize(ts, s, rules))
+	assert.Equal(t, "", s.Type)
+	assert.Equal(t, tsMalformed(&info.SpansMalformed{TypeNotAllowed: 1}), ts)
+
+	// the default limits apply to the other services
+	ts = newTagStats()
+	s = newTestSpan()
+	s.Name = strings.Repeat("a", 150)
+	assert.NoError(t, normalize(ts, s, rules))
+	assert.Equal(t, MaxNameLen, len(s.Name))
+	assert.Equal(t, "http", s.Type)
+	assert.Equal(t, tsMalformed(&info.SpansMalformed{SpanNameTruncate: 1}), ts)
+}
+
 func TestNormalizeServiceTag(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Service = "retargeting(api-Staging "
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, "retargeting_api-staging", s.Service)
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -342,7 +373,7 @@ func TestNormalizeEnv(t *testing.T) {
 	ts := newTagStats()
 	s := newTestSpan()
 	s.Meta["env"] = "DEVELOPMENT"
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, "development", s.Meta["env"])
 	assert.Equal(t, newTagStats(), ts)
 }
@@ -355,7 +386,7 @@ func TestSpecialZipkinRootSpan(t *testing.T) {
 	s.SpanID = 42
 	beforeTraceID := s.TraceID
 	beforeSpanID := s.SpanID
-	assert.NoError(t, normalize(ts, s))
+	assert.NoError(t, normalize(ts, s, nil))
 	assert.Equal(t, uint64(0), s.ParentID)
 	assert.Equal(t, beforeTraceID, s.TraceID)
 	assert.Equal(t, beforeSpanID, s.SpanID)
@@ -364,7 +395,7 @@ func TestSpecialZipkinRootSpan(t *testing.T) {
 
 func TestNormalizeTraceEmpty(t *testing.T) {
 	ts, trace := newTagStats(), pb.Trace{}
-	err := normalizeTrace(ts, trace)
+	err := normalizeTrace(ts, trace, nil)
 	assert.Error(t, err)
 	assert.Equal(t, tsDropped(&info.TracesDropped{EmptyTrace: 1}), ts)
 }
@@ -376,7 +407,7 @@ func TestNormalizeTraceTraceIdMismatch(t *testing.T) {
 	span1.TraceID = 1
 	span2.TraceID = 2
 	trace := pb.Trace{span1, span2}
-	err := normalizeTrace(ts, trace)
+	err := normalizeTrace(ts, trace, nil)
 	assert.Error(t, err)
 	assert.Equal(t, tsDropped(&info.TracesDropped{ForeignSpan: 1}), ts)
 }
@@ -387,7 +418,7 @@ func TestNormalizeTraceInvalidSpan(t *testing.T) {
 
 	span2.Name = "" // invalid
 	trace := pb.Trace{span1, span2}
-	err := normalizeTrace(ts, trace)
+	err := normalizeTrace(ts, trace, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{SpanNameEmpty: 1}), ts)
 }
@@ -398,7 +429,7 @@ func TestNormalizeTraceDuplicateSpanID(t *testing.T) {
 
 	span2.SpanID = span1.SpanID
 	trace := pb.Trace{span1, span2}
-	err := normalizeTrace(ts, trace)
+	err := normalizeTrace(ts, trace, nil)
 	assert.NoError(t, err)
 	assert.Equal(t, tsMalformed(&info.SpansMalformed{DuplicateSpanID: 1}), ts)
 }
@@ -409,7 +440,7 @@ func TestNormalizeTrace(t *testing.T) {
 
 	span2.SpanID++
 	trace := pb.Trace{span1, span2}
-	err := normalizeTrace(ts, trace)
+	err := normalizeTrace(ts, trace, nil)
 	assert.NoError(t, err)
 }
 
@@ -433,7 +464,7 @@ func TestNormalizeInvalidUTF8(t *testing.T) {
 
 		span.Service = invalidUTF8
 
-		err := normalize(ts, span)
+		err := normalize(ts, span, nil)
 
 		assert.Nil(err)
 		assert.Equal("test", span.Service)
@@ -447,7 +478,7 @@ func TestNormalizeInvalidUTF8(t *testing.T) {
 
 		span.Resource = invalidUTF8
 
-		err := normalize(ts, span)
+		err := normalize(ts, span, nil)
 
 		assert.Nil(err)
 		assert.Equal("test��", span.Resource)
@@ -461,7 +492,7 @@ func TestNormalizeInvalidUTF8(t *testing.T) {
 
 		span.Name = invalidUTF8
 
-		err := normalize(ts, span)
+		err := normalize(ts, span, nil)
 
 		assert.Nil(err)
 		assert.Equal("test", span.Name)
@@ -475,7 +506,7 @@ func TestNormalizeInvalidUTF8(t *testing.T) {
 
 		span.Type = invalidUTF8
 
-		err := normalize(ts, span)
+		err := normalize(ts, span, nil)
 
 		assert.Nil(err)
 		assert.Equal("test��", span.Type)
@@ -492,7 +523,7 @@ func TestNormalizeInvalidUTF8(t *testing.T) {
 			"test2":     invalidUTF8,
 		}
 
-		err := normalize(ts, span)
+		err := normalize(ts, span, nil)
 
 		assert.Nil(err)
 		assert.EqualValues(map[string]string{
@@ -509,7 +540,7 @@ func BenchmarkNormalization(b *testing.B) {
 		ts := newTagStats()
 		span := newTestSpan()
 
-		normalize(ts, span)
+		normalize(ts, span, nil)
 	}
 }
 
@@ -0,0 +1,81 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"bufio"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// spooledReader copies a request body to a temporary file on its first read, the payload
+// then being decoded from the disk. It's used for the payloads above the in-memory limit,
+// so that a slow decoding doesn't hold the connection and its buffers.
+type spooledReader struct {
+	body  io.ReadCloser
+	dir   string
+	limit int64
+
+	file *os.File
+	r    *bufio.Reader
+	err  error
+}
+
+// newSpooledReader returns a reader spooling the body to a temporary file of dir, up to limit
+// bytes. Reading from it returns ErrLimitedReaderLimitReached when the body is bigger.
+func newSpooledReader(body io.ReadCloser, dir string, limit int64) *spooledReader {
+	return &spooledReader{
+		body:  body,
+		dir:   dir,
+		limit: limit,
+	}
+}
+
+// Read reads the spooled body, spooling it first if needed.
+func (s *spooledReader) Read(buf []byte) (int, error) {
+	if s.r == nil && s.err == nil {
+		s.err = s.spool()
+	}
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.r.Read(buf)
+}
+
+func (s *spooledReader) spool() error {
+	f, err := ioutil.TempFile(s.dir, "trace-payload-")
+	if err != nil {
+		return err
+	}
+	s.file = f
+	n, err := io.Copy(f, io.LimitReader(s.body, s.limit+1))
+	if err != nil {
+		return err
+	}
+	if n > s.limit {
+		return ErrLimitedReaderLimitReached
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	s.r = bufio.NewReader(f)
+	return nil
+}
+
+// Close closes the request body and removes the temporary file.
+func (s *spooledReader) Close() error {
+	err := s.body.Close()
+	if s.file != nil {
+		s.file.Close()
+		if rerr := os.Remove(s.file.Name()); rerr != nil {
+			log.Errorf("Could not remove the spooled payload %s: %v", s.file.Name(), rerr)
+		}
+	}
+	return err
+}
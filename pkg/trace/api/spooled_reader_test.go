@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package api
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpooledReader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	body := ioutil.NopCloser(bytes.NewBufferString("some trace payload"))
+	r := newSpooledReader(body, dir, 100)
+
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal(t, "some trace payload", string(data))
+
+	files, err := filepath.Glob(filepath.Join(dir, "trace-payload-*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	// the temporary file is removed on close
+	require.NoError(t, r.Close())
+	files, err = filepath.Glob(filepath.Join(dir, "trace-payload-*"))
+	require.NoError(t, err)
+	assert.Len(t, files, 0)
+}
+
+func TestSpooledReaderLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	body := ioutil.NopCloser(bytes.NewBufferString("some trace payload"))
+	r := newSpooledReader(body, dir, 10)
+	defer r.Close()
+
+	_, err = ioutil.ReadAll(r)
+	assert.Equal(t, ErrLimitedReaderLimitReached, err)
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
+	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
@@ -45,6 +46,14 @@ type ObfuscationConfig struct {
 	// Memcached holds the configuration for obfuscating the "memcached.command" tag
 	// for spans of type "memcached".
 	Memcached Enablable `mapstructure:"memcached"`
+
+	// GraphQL holds the configuration for obfuscating the GraphQL queries of the resource
+	// and of the "graphql.source" tag for spans of type "graphql".
+	GraphQL Enablable `mapstructure:"graphql"`
+
+	// GRPC holds the configuration for obfuscating the identifiers in the methods of the
+	// resource and of the "grpc.method" tag for spans of type "grpc".
+	GRPC Enablable `mapstructure:"grpc"`
 }
 
 // HTTPObfuscationConfig holds the configuration settings for HTTP obfuscation.
@@ -72,6 +81,26 @@ type JSONObfuscationConfig struct {
 	KeepValues []string `mapstructure:"keep_values"`
 }
 
+// NormalizationRule overrides the normalization limits of the spans of a service, so that the legacy
+// services with long names or resources are not truncated. A zero limit keeps the default one.
+type NormalizationRule struct {
+	// MaxNameLen is the maximum length of the span names
+	MaxNameLen int `mapstructure:"max_name_length"`
+	// MaxResourceLen is the maximum length of the span resources
+	MaxResourceLen int `mapstructure:"max_resource_length"`
+	// AllowedTypes are the span types allowed, the others are removed. Any type is allowed when empty.
+	AllowedTypes []string `mapstructure:"allowed_span_types"`
+}
+
+// MetaRule specifies a span tag which is removed or truncated before the spans are sent.
+type MetaRule struct {
+	// Name is the name of the tag. A name ending with "*" targets all the tags with that prefix.
+	Name string `mapstructure:"name"`
+	// MaxLength is the length above which the tag value is truncated. The tag is removed when
+	// it's not positive.
+	MaxLength int `mapstructure:"max_length"`
+}
+
 // ReplaceRule specifies a replace rule.
 type ReplaceRule struct {
 	// Name specifies the name of the tag that the replace rule addresses. However,
@@ -146,22 +175,21 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	}
 
 	if config.Datadog.IsSet("proxy.no_proxy") {
-		proxyList := config.Datadog.GetStringSlice("proxy.no_proxy")
-		noProxy := make(map[string]bool, len(proxyList))
-		for _, host := range proxyList {
-			// map of hosts that need to be skipped by proxy
-			noProxy[host] = true
-		}
+		noProxy := config.Datadog.GetStringSlice("proxy.no_proxy")
 		for _, e := range c.Endpoints {
-			e.NoProxy = noProxy[e.Host]
+			e.NoProxy = endpointMatchesNoProxy(e.Host, noProxy)
 		}
 	}
-	if addr := config.Datadog.GetString("proxy.https"); addr != "" {
+	addr := config.Datadog.GetString("proxy.apm")
+	if addr == "" {
+		addr = config.Datadog.GetString("proxy.https")
+	}
+	if addr != "" {
 		url, err := url.Parse(addr)
 		if err == nil {
 			c.ProxyURL = url
 		} else {
-			log.Errorf("Failed to parse proxy URL from proxy.https configuration: %s", err)
+			log.Errorf("Failed to parse proxy URL from proxy.apm or proxy.https configuration: %s", err)
 		}
 	}
 
@@ -204,6 +232,20 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.max_events_per_second") {
 		c.MaxEPS = config.Datadog.GetFloat64("apm_config.max_events_per_second")
 	}
+	if config.Datadog.IsSet("apm_config.max_events_per_second_by_service") {
+		epsByService := make(map[string]float64)
+		if err := config.Datadog.UnmarshalKey("apm_config.max_events_per_second_by_service", &epsByService); err != nil {
+			return err
+		}
+		c.MaxEPSByService = epsByService
+	}
+	if k := "apm_config.min_tracer_versions"; config.Datadog.IsSet(k) {
+		minVersions := make(map[string]string)
+		if err := config.Datadog.UnmarshalKey(k, &minVersions); err != nil {
+			return err
+		}
+		c.MinTracerVersions = minVersions
+	}
 	if config.Datadog.IsSet("apm_config.max_traces_per_second") {
 		c.MaxTPS = config.Datadog.GetFloat64("apm_config.max_traces_per_second")
 	}
@@ -213,6 +255,18 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if k := "apm_config.max_payload_size"; config.Datadog.IsSet(k) {
 		c.MaxRequestBytes = config.Datadog.GetInt64(k)
 	}
+	if k := "apm_config.max_spooled_payload_size"; config.Datadog.IsSet(k) {
+		c.MaxSpooledRequestBytes = config.Datadog.GetInt64(k)
+	}
+	if k := "apm_config.spool_dir"; config.Datadog.IsSet(k) {
+		c.SpoolDir = config.Datadog.GetString(k)
+	}
+	if k := "apm_config.decoders"; config.Datadog.IsSet(k) {
+		c.DecoderWorkers = config.Datadog.GetInt(k)
+	}
+	if k := "apm_config.decoder_queue_size"; config.Datadog.IsSet(k) {
+		c.DecoderQueueSize = config.Datadog.GetInt(k)
+	}
 
 	if config.Datadog.IsSet("apm_config.replace_tags") {
 		rt := make([]*ReplaceRule, 0)
@@ -255,6 +309,9 @@ func (c *AgentConfig) applyDatadogConfig() error {
 	if config.Datadog.IsSet("apm_config.max_memory") {
 		c.MaxMemory = config.Datadog.GetFloat64("apm_config.max_memory")
 	}
+	if config.Datadog.IsSet("apm_config.watchdog_shedding") {
+		c.WatchdogShedding = config.Datadog.GetBool("apm_config.watchdog_shedding")
+	}
 
 	// undocumented writers
 	for key, cfg := range map[string]*WriterConfig{
@@ -300,6 +357,30 @@ func (c *AgentConfig) applyDatadogConfig() error {
 		}
 	}
 
+	if config.Datadog.IsSet("apm_config.normalization_rules") {
+		rules := make(map[string]*NormalizationRule)
+		if err := config.Datadog.UnmarshalKey("apm_config.normalization_rules", &rules); err != nil {
+			return err
+		}
+		c.NormalizationRules = rules
+	}
+
+	if k := "apm_config.meta_allowlist"; config.Datadog.IsSet(k) {
+		c.MetaAllowlist = config.Datadog.GetStringSlice(k)
+	}
+	if k := "apm_config.meta_blocklist"; config.Datadog.IsSet(k) {
+		rules := make([]*MetaRule, 0)
+		if err := config.Datadog.UnmarshalKey(k, &rules); err != nil {
+			return err
+		}
+		for _, r := range rules {
+			if r.Name == "" {
+				return errors.New(`meta_blocklist: all rules must have a "name" property`)
+			}
+		}
+		c.MetaBlocklist = rules
+	}
+
 	// undocumented
 	if config.Datadog.IsSet("apm_config.dd_agent_bin") {
 		c.DDAgentBin = config.Datadog.GetString("apm_config.dd_agent_bin")
@@ -336,6 +417,9 @@ func (c *AgentConfig) loadDeprecatedValues() error {
 		}
 		c.ExtraAggregators = append(c.ExtraAggregators, aggs...)
 	}
+	if cfg.IsSet("apm_config.container_metadata_tags") {
+		c.ContainerMetadataTags = cfg.GetBool("apm_config.container_metadata_tags")
+	}
 	if cfg.IsSet("apm_config.log_throttling") {
 		c.LogThrottling = cfg.GetBool("apm_config.log_throttling")
 	}
@@ -343,6 +427,9 @@ func (c *AgentConfig) loadDeprecatedValues() error {
 		d := time.Duration(cfg.GetInt("apm_config.bucket_size_seconds"))
 		c.BucketInterval = d * time.Second
 	}
+	if cfg.IsSet("apm_config.bucket_window_count") {
+		c.BucketWindowCount = cfg.GetInt("apm_config.bucket_window_count")
+	}
 	if cfg.IsSet("apm_config.receiver_timeout") {
 		c.ReceiverTimeout = cfg.GetInt("apm_config.receiver_timeout")
 	}
@@ -387,6 +474,22 @@ func compileReplaceRules(rules []*ReplaceRule) error {
 	return nil
 }
 
+// endpointMatchesNoProxy reports whether the endpoint host, a URL, is listed in the no_proxy
+// entries, either as is or through a host, domain or CIDR entry.
+func endpointMatchesNoProxy(host string, noProxy []string) bool {
+	for _, entry := range noProxy {
+		if entry == host {
+			return true
+		}
+	}
+	u, err := url.Parse(host)
+	if err != nil {
+		return false
+	}
+	_, ok := httputils.NoProxyMatch(u, noProxy)
+	return ok
+}
+
 // getDuration returns the duration of the provided value in seconds
 func getDuration(seconds int) time.Duration {
 	return time.Duration(seconds) * time.Second
@@ -62,13 +62,20 @@ type AgentConfig struct {
 	Endpoints []*Endpoint
 
 	// Concentrator
-	BucketInterval   time.Duration // the size of our pre-aggregation per bucket
-	ExtraAggregators []string
+	BucketInterval    time.Duration // the size of our pre-aggregation per bucket
+	BucketWindowCount int           // the number of buckets kept open for the late spans before being flushed
+	ExtraAggregators  []string
+
+	// ContainerMetadataTags enables the tagging of the traces and stats with the
+	// source code and image metadata of the container they come from.
+	ContainerMetadataTags bool
 
 	// Sampler configuration
 	ExtraSampleRate float64
 	MaxTPS          float64
 	MaxEPS          float64
+	// MaxEPSByService overrides MaxEPS for some services, which get a dedicated events limiter
+	MaxEPSByService map[string]float64
 
 	// Receiver
 	ReceiverHost    string
@@ -78,6 +85,22 @@ type AgentConfig struct {
 	ReceiverTimeout int
 	MaxRequestBytes int64 // specifies the maximum allowed request size for incoming trace payloads
 
+	// MinTracerVersions maps the tracer languages to the oldest tracer version for which no
+	// warning is reported
+	MinTracerVersions map[string]string
+
+	// MaxSpooledRequestBytes is the maximum size of the incoming trace payloads above MaxRequestBytes
+	// that are spooled to a temporary file of SpoolDir and decoded from the disk. It's disabled when
+	// not above MaxRequestBytes.
+	MaxSpooledRequestBytes int64
+	SpoolDir               string
+
+	// DecoderWorkers is the number of goroutines decoding the trace payloads, the number of CPUs
+	// when not positive. The payloads are refused with 429 responses when DecoderQueueSize of them
+	// are already waiting for a decoder.
+	DecoderWorkers   int
+	DecoderQueueSize int
+
 	// Writers
 	StatsWriter             *WriterConfig
 	TraceWriter             *WriterConfig
@@ -96,6 +119,7 @@ type AgentConfig struct {
 	MaxMemory        float64       // MaxMemory is the threshold (bytes allocated) above which program panics and exits, to be restarted
 	MaxCPU           float64       // MaxCPU is the max UserAvg CPU the program should consume
 	WatchdogInterval time.Duration // WatchdogInterval is the delay between 2 watchdog checks
+	WatchdogShedding bool          // WatchdogShedding refuses the payloads with 429s above 1.5x MaxMemory instead of exiting
 
 	// http/s proxying
 	ProxyURL          *url.URL
@@ -108,6 +132,15 @@ type AgentConfig struct {
 	// It maps tag keys to a set of replacements. Only supported in A6.
 	ReplaceTags []*ReplaceRule
 
+	// NormalizationRules overrides the normalization limits of the spans per service
+	NormalizationRules map[string]*NormalizationRule
+
+	// MetaAllowlist lists the span tags which are kept, the others are removed. All the tags are
+	// kept when empty.
+	MetaAllowlist []string
+	// MetaBlocklist lists the span tags which are removed or truncated.
+	MetaBlocklist []*MetaRule
+
 	// transaction analytics
 	AnalyzedRateByServiceLegacy map[string]float64
 	AnalyzedSpansByService      map[string]map[string]float64
@@ -126,8 +159,11 @@ func New() *AgentConfig {
 		DefaultEnv: "none",
 		Endpoints:  []*Endpoint{{Host: "https://trace.agent.datadoghq.com"}},
 
-		BucketInterval:   time.Duration(10) * time.Second,
-		ExtraAggregators: []string{"http.status_code", "version"},
+		BucketInterval:    time.Duration(10) * time.Second,
+		BucketWindowCount: 2,
+		ExtraAggregators:  []string{"http.status_code", "version"},
+
+		ContainerMetadataTags: true,
 
 		ExtraSampleRate: 1.0,
 		MaxTPS:          10,
@@ -151,6 +187,7 @@ func New() *AgentConfig {
 		MaxMemory:        5e8, // 500 Mb, should rarely go above 50 Mb
 		MaxCPU:           0.5, // 50%, well behaving agents keep below 5%
 		WatchdogInterval: 10 * time.Second,
+		WatchdogShedding: true,
 
 		Ignore:                      make(map[string][]string),
 		AnalyzedRateByServiceLegacy: make(map[string]float64),
@@ -224,7 +261,7 @@ func (c *AgentConfig) HTTPClient() *http.Client {
 		TLSHandshakeTimeout:   10 * time.Second,
 		ExpectContinueTimeout: 1 * time.Second,
 	}
-	if p := coreconfig.GetProxies(); p != nil {
+	if p := coreconfig.GetProxiesForIntake(coreconfig.ProxyIntakeAPM); p != nil {
 		transport.Proxy = httputils.GetProxyTransportFunc(p)
 	}
 	return &http.Client{
@@ -272,3 +309,11 @@ func prepareConfig(path string) (*AgentConfig, error) {
 func HasFeature(f string) bool {
 	return strings.Contains(os.Getenv("DD_APM_FEATURES"), f)
 }
+
+// Features returns the list of features of the DD_APM_FEATURES environment variable,
+// separated by commas or spaces.
+func Features() []string {
+	return strings.FieldsFunc(os.Getenv("DD_APM_FEATURES"), func(r rune) bool {
+		return r == ',' || r == ' '
+	})
+}
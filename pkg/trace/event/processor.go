@@ -6,14 +6,31 @@
 package event
 
 import (
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
 )
 
+// eventsReportFrequency is the frequency at which the rates of extracted and sampled events are reported.
+const eventsReportFrequency = 10 * time.Second
+
 // Processor is responsible for all the logic surrounding extraction and sampling of APM events from processed traces.
 type Processor struct {
 	extractors    []Extractor
 	maxEPSSampler eventSampler
+	// serviceSamplers holds the dedicated max EPS samplers of the services overriding the max EPS, by
+	// lower-cased service name. Their events don't count towards the global max EPS.
+	serviceSamplers map[string]eventSampler
+
+	// extracted and sampled count the events since the last report, they are accessed atomically.
+	extracted  int64
+	sampled    int64
+	lastReport time.Time
+	reportDone chan bool
 }
 
 // NewProcessor returns a new instance of Processor configured with the provided extractors and max eps limitation.
@@ -27,25 +44,71 @@ type Processor struct {
 //   discarded.
 // * A max events per second maxEPSSampler is applied to all non-PriorityUserKeep events that survived the first step
 //   and will ensure that, in average, the total rate of events returned by the processor is not bigger than maxEPS.
-func NewProcessor(extractors []Extractor, maxEPS float64) *Processor {
-	return newProcessor(extractors, newMaxEPSSampler(maxEPS))
+//   The services found in maxEPSByService get a dedicated sampler, limiting their events to their own max EPS.
+func NewProcessor(extractors []Extractor, maxEPS float64, maxEPSByService map[string]float64) *Processor {
+	serviceSamplers := make(map[string]eventSampler, len(maxEPSByService))
+	for service, eps := range maxEPSByService {
+		// lower-case keys for case insensitive matching (see #3113)
+		serviceSamplers[strings.ToLower(service)] = newMaxEPSSampler(eps)
+	}
+	return newProcessor(extractors, newMaxEPSSampler(maxEPS), serviceSamplers)
 }
 
-func newProcessor(extractors []Extractor, maxEPSSampler eventSampler) *Processor {
+func newProcessor(extractors []Extractor, maxEPSSampler eventSampler, serviceSamplers map[string]eventSampler) *Processor {
 	return &Processor{
-		extractors:    extractors,
-		maxEPSSampler: maxEPSSampler,
+		extractors:      extractors,
+		maxEPSSampler:   maxEPSSampler,
+		serviceSamplers: serviceSamplers,
+		reportDone:      make(chan bool),
 	}
 }
 
 // Start starts the processor.
 func (p *Processor) Start() {
 	p.maxEPSSampler.Start()
+	for _, s := range p.serviceSamplers {
+		s.Start()
+	}
+
+	p.lastReport = time.Now()
+	go func() {
+		ticker := time.NewTicker(eventsReportFrequency)
+		defer close(p.reportDone)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-p.reportDone:
+				return
+			case now := <-ticker.C:
+				p.report(now)
+			}
+		}
+	}()
 }
 
 // Stop stops the processor.
 func (p *Processor) Stop() {
+	p.reportDone <- true
+	<-p.reportDone
+
 	p.maxEPSSampler.Stop()
+	for _, s := range p.serviceSamplers {
+		s.Stop()
+	}
+}
+
+// report publishes the rates of the events extracted and sampled since the last report.
+func (p *Processor) report(now time.Time) {
+	elapsed := now.Sub(p.lastReport).Seconds()
+	p.lastReport = now
+	if elapsed <= 0 {
+		return
+	}
+	info.UpdateEventsInfo(info.EventsInfo{
+		ExtractedPerSecond: float64(atomic.SwapInt64(&p.extracted, 0)) / elapsed,
+		SampledPerSecond:   float64(atomic.SwapInt64(&p.sampled, 0)) / elapsed,
+	})
 }
 
 // Process takes a processed trace, extracts events from it and samples them, returning a collection of
@@ -90,6 +153,8 @@ func (p *Processor) Process(root *pb.Span, t pb.Trace) (events []*pb.Span, numEx
 		events = append(events, span)
 	}
 
+	atomic.AddInt64(&p.extracted, numExtracted)
+	atomic.AddInt64(&p.sampled, int64(len(events)))
 	return events, numExtracted
 }
 
@@ -106,6 +171,9 @@ func (p *Processor) maxEPSSample(event *pb.Span, priority sampler.SamplingPriori
 	if priority == sampler.PriorityUserKeep {
 		return true, 1
 	}
+	if s, ok := p.serviceSamplers[strings.ToLower(event.Service)]; ok {
+		return s.Sample(event)
+	}
 	return p.maxEPSSampler.Sample(event)
 }
 
@@ -8,6 +8,7 @@ package event
 import (
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/sampler"
@@ -58,7 +59,7 @@ func TestProcessor(t *testing.T) {
 			}
 
 			testSampler := &MockEventSampler{Rate: test.samplerRate}
-			p := newProcessor(extractors, testSampler)
+			p := newProcessor(extractors, testSampler, nil)
 
 			testTrace := createTestSpans("test", "test")
 			root := testTrace[0]
@@ -105,6 +106,34 @@ func TestProcessor(t *testing.T) {
 	}
 }
 
+func TestProcessorServiceSamplers(t *testing.T) {
+	assert := assert.New(t)
+
+	globalSampler := &MockEventSampler{Rate: 1}
+	serviceSampler := &MockEventSampler{Rate: 0}
+	p := newProcessor([]Extractor{&MockExtractor{Rate: 1}}, globalSampler, map[string]eventSampler{
+		"checkout": serviceSampler,
+	})
+
+	p.Start()
+	events, extracted := p.Process(&pb.Span{Service: "web"}, createTestSpans("web", "request"))
+	assert.EqualValues(1000, extracted)
+	assert.Len(events, 1000)
+	events, extracted = p.Process(&pb.Span{Service: "CheckOut"}, createTestSpans("CheckOut", "request"))
+	assert.EqualValues(1000, extracted)
+	assert.Len(events, 0)
+
+	p.report(p.lastReport.Add(10 * time.Second))
+	p.Stop()
+
+	assert.EqualValues(1000, globalSampler.SampleCalls)
+	assert.EqualValues(1000, serviceSampler.SampleCalls)
+	assert.EqualValues(1, serviceSampler.StartCalls)
+	assert.EqualValues(1, serviceSampler.StopCalls)
+	assert.EqualValues(0, p.extracted)
+	assert.EqualValues(0, p.sampled)
+}
+
 type MockExtractor struct {
 	Rate float64
 }
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package filters
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/DataDog/datadog-agent/pkg/trace/traceutil"
+)
+
+// metaAlwaysAllowed are the tags kept whatever the allowlist, as they're used by the agent
+// and the backend.
+var metaAlwaysAllowed = map[string]bool{
+	"env":     true,
+	"version": true,
+}
+
+// MetaFilter is a filter which removes or truncates the span tags based on an allowlist
+// and a blocklist, to avoid sending bulky tags which aren't needed. It keeps all spans.
+type MetaFilter struct {
+	allow *metaMatcher
+	block []metaBlockRule
+
+	tagsRemoved   int64 // atomic
+	tagsTruncated int64 // atomic
+	bytesSaved    int64 // atomic
+}
+
+type metaBlockRule struct {
+	match     *metaMatcher
+	maxLength int
+}
+
+// NewMetaFilter returns a new MetaFilter using the given allowlist and blocklist. All the
+// tags are allowed when the allowlist is empty.
+func NewMetaFilter(allowlist []string, blocklist []*config.MetaRule) *MetaFilter {
+	f := &MetaFilter{}
+	if len(allowlist) > 0 {
+		f.allow = newMetaMatcher(allowlist...)
+	}
+	for _, r := range blocklist {
+		f.block = append(f.block, metaBlockRule{match: newMetaMatcher(r.Name), maxLength: r.MaxLength})
+	}
+	return f
+}
+
+// Filter removes the tags of the spans which aren't allowed and removes or truncates the
+// tags which are blocked.
+func (f *MetaFilter) Filter(trace pb.Trace) {
+	if f.allow == nil && len(f.block) == 0 {
+		return
+	}
+	var removed, truncated, saved int64
+	for _, s := range trace {
+		for k, v := range s.Meta {
+			if f.allow != nil && !f.allowed(k) {
+				delete(s.Meta, k)
+				removed++
+				saved += int64(len(k) + len(v))
+				continue
+			}
+			for _, r := range f.block {
+				if !r.match.matches(k) {
+					continue
+				}
+				if r.maxLength <= 0 {
+					delete(s.Meta, k)
+					removed++
+					saved += int64(len(k) + len(v))
+				} else if len(v) > r.maxLength {
+					s.Meta[k] = traceutil.TruncateUTF8(v, r.maxLength)
+					truncated++
+					saved += int64(len(v) - len(s.Meta[k]))
+				}
+				break
+			}
+		}
+	}
+	if removed > 0 {
+		atomic.AddInt64(&f.tagsRemoved, removed)
+	}
+	if truncated > 0 {
+		atomic.AddInt64(&f.tagsTruncated, truncated)
+	}
+	if saved > 0 {
+		atomic.AddInt64(&f.bytesSaved, saved)
+	}
+}
+
+func (f *MetaFilter) allowed(key string) bool {
+	return metaAlwaysAllowed[key] || strings.HasPrefix(key, "_dd.") || f.allow.matches(key)
+}
+
+// Info returns the totals of the tags removed and truncated by the filter.
+func (f *MetaFilter) Info() info.MetaFilterInfo {
+	return info.MetaFilterInfo{
+		TagsRemoved:   atomic.LoadInt64(&f.tagsRemoved),
+		TagsTruncated: atomic.LoadInt64(&f.tagsTruncated),
+		BytesSaved:    atomic.LoadInt64(&f.bytesSaved),
+	}
+}
+
+// metaMatcher matches the tag names exactly, or by prefix for the names ending with "*".
+type metaMatcher struct {
+	names    map[string]bool
+	prefixes []string
+}
+
+func newMetaMatcher(names ...string) *metaMatcher {
+	m := &metaMatcher{names: make(map[string]bool)}
+	for _, name := range names {
+		if strings.HasSuffix(name, "*") {
+			m.prefixes = append(m.prefixes, strings.TrimSuffix(name, "*"))
+		} else {
+			m.names[name] = true
+		}
+	}
+	return m
+}
+
+func (m *metaMatcher) matches(key string) bool {
+	if m.names[key] {
+		return true
+	}
+	for _, p := range m.prefixes {
+		if strings.HasPrefix(key, p) {
+			return true
+		}
+	}
+	return false
+}
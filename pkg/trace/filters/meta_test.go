@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package filters
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/config"
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetaFilter(t *testing.T) {
+	stack := strings.Repeat("a", 100)
+	newTrace := func() pb.Trace {
+		return pb.Trace{
+			{Meta: map[string]string{
+				"env":               "prod",
+				"_dd.origin":        "synthetics",
+				"http.url":          "/users",
+				"http.method":       "GET",
+				"sql.query":         "SELECT * FROM users",
+				"error.stack":       stack,
+				"custom.tag":        "value",
+				"http.request.body": "{}",
+			}},
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		allow []string
+		block []*config.MetaRule
+		want  map[string]string
+		info  info.MetaFilterInfo
+	}{
+		"none": {
+			want: newTrace()[0].Meta,
+		},
+		"allowlist": {
+			allow: []string{"http.*", "error.stack"},
+			want: map[string]string{
+				"env":               "prod",
+				"_dd.origin":        "synthetics",
+				"http.url":          "/users",
+				"http.method":       "GET",
+				"error.stack":       stack,
+				"http.request.body": "{}",
+			},
+			info: info.MetaFilterInfo{TagsRemoved: 2, BytesSaved: int64(len("sql.query") + len("SELECT * FROM users") + len("custom.tag") + len("value"))},
+		},
+		"blocklist": {
+			block: []*config.MetaRule{
+				{Name: "sql.query"},
+				{Name: "error.stack", MaxLength: 10},
+				{Name: "http.request.*"},
+				{Name: "http.url", MaxLength: 100},
+			},
+			want: map[string]string{
+				"env":         "prod",
+				"_dd.origin":  "synthetics",
+				"http.url":    "/users",
+				"http.method": "GET",
+				"error.stack": stack[:10],
+				"custom.tag":  "value",
+			},
+			info: info.MetaFilterInfo{TagsRemoved: 2, TagsTruncated: 1, BytesSaved: int64(len("sql.query") + len("SELECT * FROM users") + len("http.request.body") + len("{}") + 90)},
+		},
+		"both": {
+			allow: []string{"http.url", "sql.query"},
+			block: []*config.MetaRule{{Name: "sql.query"}},
+			want: map[string]string{
+				"env":        "prod",
+				"_dd.origin": "synthetics",
+				"http.url":   "/users",
+			},
+			info: info.MetaFilterInfo{TagsRemoved: 5, BytesSaved: int64(len("http.methodGET") + len("sql.querySELECT * FROM users") + len("error.stack") + len(stack) + len("custom.tagvalue") + len("http.request.body{}"))},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := NewMetaFilter(tt.allow, tt.block)
+			trace := newTrace()
+			f.Filter(trace)
+			assert.Equal(t, tt.want, trace[0].Meta)
+			assert.Equal(t, tt.info, f.Info())
+		})
+	}
+}
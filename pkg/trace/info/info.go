@@ -40,6 +40,8 @@ var (
 	errorsSamplerInfo   SamplerInfo
 	rateByService       map[string]float64
 	rateLimiterStats    RateLimiterStats
+	eventsInfo          EventsInfo
+	metaFilterInfo      MetaFilterInfo
 	start               = time.Now()
 	once                sync.Once
 	infoTmpl            *template.Template
@@ -233,6 +235,52 @@ func publishRateLimiterStats() interface{} {
 	return rateLimiterStats
 }
 
+// EventsInfo contains the rates of the APM events extracted from the traces and sampled
+// by the max events per second limiters.
+type EventsInfo struct {
+	// ExtractedPerSecond is the recent rate of the extracted events, per second.
+	ExtractedPerSecond float64
+	// SampledPerSecond is the recent rate of the events kept after sampling, per second.
+	SampledPerSecond float64
+}
+
+// UpdateEventsInfo updates internal stats about the APM events.
+func UpdateEventsInfo(ei EventsInfo) {
+	infoMu.Lock()
+	defer infoMu.Unlock()
+	eventsInfo = ei
+}
+
+func publishEventsInfo() interface{} {
+	infoMu.RLock()
+	defer infoMu.RUnlock()
+	return eventsInfo
+}
+
+// MetaFilterInfo contains the totals of the span tags removed or truncated by the
+// meta_allowlist and meta_blocklist settings.
+type MetaFilterInfo struct {
+	// TagsRemoved is the number of span tags removed.
+	TagsRemoved int64
+	// TagsTruncated is the number of span tag values truncated.
+	TagsTruncated int64
+	// BytesSaved is the number of bytes of tag keys and values not sent.
+	BytesSaved int64
+}
+
+// UpdateMetaFilterInfo updates internal stats about the span tags filtering.
+func UpdateMetaFilterInfo(mi MetaFilterInfo) {
+	infoMu.Lock()
+	defer infoMu.Unlock()
+	metaFilterInfo = mi
+}
+
+func publishMetaFilterInfo() interface{} {
+	infoMu.RLock()
+	defer infoMu.RUnlock()
+	return metaFilterInfo
+}
+
 func publishUptime() interface{} {
 	return int(time.Since(start) / time.Second)
 }
@@ -254,6 +302,8 @@ func InitInfo(conf *config.AgentConfig) error {
 		},
 	}
 
+	SetMinTracerVersions(conf.MinTracerVersions)
+
 	once.Do(func() {
 		expvar.NewInt("pid").Set(int64(os.Getpid()))
 		expvar.Publish("uptime", expvar.Func(publishUptime))
@@ -267,6 +317,8 @@ func InitInfo(conf *config.AgentConfig) error {
 		expvar.Publish("ratebyservice", expvar.Func(publishRateByService))
 		expvar.Publish("watchdog", expvar.Func(publishWatchdogInfo))
 		expvar.Publish("ratelimiter", expvar.Func(publishRateLimiterStats))
+		expvar.Publish("events", expvar.Func(publishEventsInfo))
+		expvar.Publish("meta_filter", expvar.Func(publishMetaFilterInfo))
 
 		// copy the config to ensure we don't expose sensitive data such as API keys
 		c := *conf
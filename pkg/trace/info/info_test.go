@@ -291,12 +291,12 @@ func TestInfoReceiverStats(t *testing.T) {
 
 	stats := NewReceiverStats()
 	t1 := &TagStats{
-		Tags{Lang: "python"},
-		Stats{TracesReceived: 23, TracesBytes: 3244, SpansReceived: 213, SpansDropped: 14},
+		Tags:  Tags{Lang: "python"},
+		Stats: Stats{TracesReceived: 23, TracesBytes: 3244, SpansReceived: 213, SpansDropped: 14},
 	}
 	t2 := &TagStats{
-		Tags{Lang: "go"},
-		Stats{},
+		Tags:  Tags{Lang: "go"},
+		Stats: Stats{},
 	}
 	stats.Stats = map[Tags]*TagStats{
 		t1.Tags: t1,
@@ -118,10 +118,17 @@ func (rs *ReceiverStats) LogStats() {
 type TagStats struct {
 	Tags
 	Stats
+	// MinTracerVersion is the minimum version recommended for the tracer of these tags,
+	// it is only set when the tracer is older than that.
+	MinTracerVersion string `json:",omitempty"`
 }
 
 func newTagStats(tags Tags) *TagStats {
-	return &TagStats{tags, Stats{TracesDropped: &TracesDropped{}, SpansMalformed: &SpansMalformed{}}}
+	return &TagStats{
+		Tags:             tags,
+		Stats:            Stats{TracesDropped: &TracesDropped{}, SpansMalformed: &SpansMalformed{}},
+		MinTracerVersion: outdatedTracer(tags),
+	}
 }
 
 func (ts *TagStats) publish() {
@@ -161,6 +168,9 @@ func (ts *TagStats) publish() {
 	metrics.Count("datadog.trace_agent.receiver.events_sampled", eventsSampled, tags, 1)
 	metrics.Count("datadog.trace_agent.receiver.payload_accepted", requestsMade, tags, 1)
 	metrics.Count("datadog.trace_agent.receiver.payload_refused", requestsRejected, tags, 1)
+	if ts.MinTracerVersion != "" {
+		metrics.Count("datadog.trace_agent.receiver.outdated_tracer_payloads", requestsMade, append(tags, "min_tracer_version:"+ts.MinTracerVersion), 1)
+	}
 
 	for reason, count := range ts.TracesDropped.tagValues() {
 		metrics.Count("datadog.trace_agent.normalizer.traces_dropped", count, append(tags, "reason:"+reason), 1)
@@ -242,6 +252,8 @@ type SpansMalformed struct {
 	ResourceEmpty int64
 	// TypeTruncate is when a span's Type is truncated for exceeding the max length
 	TypeTruncate int64
+	// TypeNotAllowed is when a span's Type is not allowed by the normalization rule of its service
+	TypeNotAllowed int64
 	// InvalidStartDate is when a span's Start date is invalid
 	InvalidStartDate int64
 	// InvalidDuration is when a span's Duration is invalid
@@ -262,6 +274,7 @@ func (s *SpansMalformed) tagValues() map[string]int64 {
 		"span_name_invalid":        atomic.LoadInt64(&s.SpanNameInvalid),
 		"resource_empty":           atomic.LoadInt64(&s.ResourceEmpty),
 		"type_truncate":            atomic.LoadInt64(&s.TypeTruncate),
+		"type_not_allowed":         atomic.LoadInt64(&s.TypeNotAllowed),
 		"invalid_start_date":       atomic.LoadInt64(&s.InvalidStartDate),
 		"invalid_duration":         atomic.LoadInt64(&s.InvalidDuration),
 		"invalid_http_status_code": atomic.LoadInt64(&s.InvalidHTTPStatusCode),
@@ -328,6 +341,7 @@ func (s *Stats) update(recent *Stats) {
 	atomic.AddInt64(&s.SpansMalformed.SpanNameInvalid, atomic.LoadInt64(&recent.SpansMalformed.SpanNameInvalid))
 	atomic.AddInt64(&s.SpansMalformed.ResourceEmpty, atomic.LoadInt64(&recent.SpansMalformed.ResourceEmpty))
 	atomic.AddInt64(&s.SpansMalformed.TypeTruncate, atomic.LoadInt64(&recent.SpansMalformed.TypeTruncate))
+	atomic.AddInt64(&s.SpansMalformed.TypeNotAllowed, atomic.LoadInt64(&recent.SpansMalformed.TypeNotAllowed))
 	atomic.AddInt64(&s.SpansMalformed.InvalidStartDate, atomic.LoadInt64(&recent.SpansMalformed.InvalidStartDate))
 	atomic.AddInt64(&s.SpansMalformed.InvalidDuration, atomic.LoadInt64(&recent.SpansMalformed.InvalidDuration))
 	atomic.AddInt64(&s.SpansMalformed.InvalidHTTPStatusCode, atomic.LoadInt64(&recent.SpansMalformed.InvalidHTTPStatusCode))
@@ -365,6 +379,7 @@ func (s *Stats) reset() {
 	atomic.StoreInt64(&s.SpansMalformed.SpanNameInvalid, 0)
 	atomic.StoreInt64(&s.SpansMalformed.ResourceEmpty, 0)
 	atomic.StoreInt64(&s.SpansMalformed.TypeTruncate, 0)
+	atomic.StoreInt64(&s.SpansMalformed.TypeNotAllowed, 0)
 	atomic.StoreInt64(&s.SpansMalformed.InvalidStartDate, 0)
 	atomic.StoreInt64(&s.SpansMalformed.InvalidDuration, 0)
 	atomic.StoreInt64(&s.SpansMalformed.InvalidHTTPStatusCode, 0)
@@ -425,18 +440,23 @@ func (ts *TagStats) WarnString() string {
 	if len(m) > 0 {
 		w = append(w, fmt.Sprintf("spans_malformed(%s)", m))
 	}
+	if ts.MinTracerVersion != "" {
+		w = append(w, fmt.Sprintf("outdated_tracer(%s < %s)", ts.TracerVersion, ts.MinTracerVersion))
+	}
 	return strings.Join(w, ", ")
 }
 
 // Tags holds the tags we parse when we handle the header of the payload.
 type Tags struct {
 	Lang, LangVersion, LangVendor, Interpreter, TracerVersion string
+	// EndpointVersion is the version of the API endpoint which received the payload.
+	EndpointVersion string
 }
 
 // toArray will transform the Tags struct into a slice of string.
 // We only publish the non-empty tags.
 func (t *Tags) toArray() []string {
-	tags := make([]string, 0, 5)
+	tags := make([]string, 0, 6)
 
 	if t.Lang != "" {
 		tags = append(tags, "lang:"+t.Lang)
@@ -453,6 +473,9 @@ func (t *Tags) toArray() []string {
 	if t.TracerVersion != "" {
 		tags = append(tags, "tracer_version:"+t.TracerVersion)
 	}
+	if t.EndpointVersion != "" {
+		tags = append(tags, "endpoint_version:"+t.EndpointVersion)
+	}
 
 	return tags
 }
@@ -58,6 +58,7 @@ func TestSpansMalformed(t *testing.T) {
 			"service_invalid":          1,
 			"span_name_truncate":       1,
 			"type_truncate":            1,
+			"type_not_allowed":         0,
 		}, s.tagValues())
 	})
 
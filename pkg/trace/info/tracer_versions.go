@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package info
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	minTracerVersionsMu sync.RWMutex
+	// minTracerVersions maps the lowercased languages to the oldest tracer version for
+	// which no warning is reported
+	minTracerVersions map[string]string
+)
+
+// SetMinTracerVersions sets the minimum tracer version expected for each language. The stats
+// of the payloads sent by older tracers report them as outdated.
+func SetMinTracerVersions(versions map[string]string) {
+	m := make(map[string]string, len(versions))
+	for lang, version := range versions {
+		m[strings.ToLower(lang)] = version
+	}
+	minTracerVersionsMu.Lock()
+	minTracerVersions = m
+	minTracerVersionsMu.Unlock()
+}
+
+// outdatedTracer returns the minimum version expected for the tracer of the given tags
+// when the tracer is older than that, or an empty string otherwise.
+func outdatedTracer(tags Tags) string {
+	if tags.Lang == "" || tags.TracerVersion == "" {
+		return ""
+	}
+	minTracerVersionsMu.RLock()
+	min, ok := minTracerVersions[strings.ToLower(tags.Lang)]
+	minTracerVersionsMu.RUnlock()
+	if !ok || compareVersions(tags.TracerVersion, min) >= 0 {
+		return ""
+	}
+	return min
+}
+
+// compareVersions compares the numeric components of two dotted versions such as "1.2.3",
+// ignoring any leading "v" and pre-release or build suffix. It returns a negative number when
+// a is older than b, a positive number when a is newer than b and 0 when they're the same.
+func compareVersions(a, b string) int {
+	va, vb := versionComponents(a), versionComponents(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var ca, cb int
+		if i < len(va) {
+			ca = va[i]
+		}
+		if i < len(vb) {
+			cb = vb[i]
+		}
+		if ca != cb {
+			return ca - cb
+		}
+	}
+	return 0
+}
+
+// versionComponents returns the numeric components of a dotted version, stopping at the first
+// component which doesn't start with a digit.
+func versionComponents(version string) []int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if i := strings.IndexAny(version, "-+"); i >= 0 {
+		version = version[:i]
+	}
+	var components []int
+	for _, part := range strings.Split(version, ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end++
+		}
+		n, err := strconv.Atoi(part[:end])
+		if err != nil {
+			break
+		}
+		components = append(components, n)
+	}
+	return components
+}
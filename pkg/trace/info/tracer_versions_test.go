@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package info
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	for _, tc := range []struct {
+		a, b string
+		cmp  int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2", "1.2.0", 0},
+		{"v1.2.3", "1.2.3", 0},
+		{"1.2.3-beta1", "1.2.3", 0},
+		{"1.2.3+build", "1.2.3", 0},
+		{"0.9.0", "0.10.0", -1},
+		{"1.2.3", "1.10", -1},
+		{"2.0", "1.99.99", 1},
+		{"1.2.4rc1", "1.2.3", 1},
+	} {
+		cmp := compareVersions(tc.a, tc.b)
+		switch {
+		case tc.cmp < 0:
+			assert.True(t, cmp < 0, "%s < %s", tc.a, tc.b)
+		case tc.cmp > 0:
+			assert.True(t, cmp > 0, "%s > %s", tc.a, tc.b)
+		default:
+			assert.Equal(t, 0, cmp, "%s == %s", tc.a, tc.b)
+		}
+	}
+}
+
+func TestOutdatedTracer(t *testing.T) {
+	SetMinTracerVersions(map[string]string{"python": "0.10.0", "Go": "1.20.0"})
+	defer SetMinTracerVersions(nil)
+
+	assert := assert.New(t)
+	assert.Equal("0.10.0", outdatedTracer(Tags{Lang: "python", TracerVersion: "0.9.0"}))
+	assert.Equal("", outdatedTracer(Tags{Lang: "python", TracerVersion: "0.10.0"}))
+	assert.Equal("1.20.0", outdatedTracer(Tags{Lang: "go", TracerVersion: "v1.19.1"}))
+	assert.Equal("", outdatedTracer(Tags{Lang: "go"}))
+	assert.Equal("", outdatedTracer(Tags{Lang: "ruby", TracerVersion: "0.1.0"}))
+
+	rs := NewReceiverStats()
+	ts := rs.GetTagStats(Tags{Lang: "python", TracerVersion: "0.9.0", EndpointVersion: "v0.4"})
+	assert.Equal("0.10.0", ts.MinTracerVersion)
+	assert.Equal("outdated_tracer(0.9.0 < 0.10.0)", ts.WarnString())
+	assert.Equal([]string{"lang:python", "tracer_version:0.9.0", "endpoint_version:v0.4"}, ts.Tags.toArray())
+}
@@ -0,0 +1,175 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// obfuscateGraphQL obfuscates the GraphQL query of the span, found in its resource or in its
+// "graphql.source" tag, so that the queries only differing by their literal values are grouped.
+func (*Obfuscator) obfuscateGraphQL(span *pb.Span) {
+	// the resource is either the operation name or the whole query document
+	if strings.ContainsRune(span.Resource, '{') {
+		span.Resource = obfuscateGraphQLQuery(span.Resource)
+	}
+	const k = "graphql.source"
+	if span.Meta == nil || span.Meta[k] == "" {
+		return
+	}
+	span.Meta[k] = obfuscateGraphQLQuery(span.Meta[k])
+}
+
+// obfuscateGraphQLQuery replaces the literal values of a GraphQL query by "?" and removes its
+// comments and extra whitespaces. The operation names, the selections, the arguments names and
+// the variables are kept, for example:
+//
+//	query User($id: ID!) { user(id: "1a2b", status: ACTIVE) { name friends(first: 10) { name } } }
+//
+// becomes:
+//
+//	query User($id: ID!) { user(id: ?, status: ?) { name friends(first: ?) { name } } }
+func obfuscateGraphQLQuery(query string) string {
+	var (
+		out strings.Builder
+		// parenDepth is the depth of parentheses, braceDepth the depth of the selection
+		// sets, the braces within parentheses being input objects.
+		parenDepth, braceDepth int
+		// varDefs is true within the variable definitions of an operation, where only the
+		// default values, following an '=', are literals.
+		varDefs, defaultValue bool
+		// variable is true when the previous token is a '$'
+		variable bool
+		space    bool
+	)
+	write := func(token string) {
+		if space && out.Len() > 0 {
+			out.WriteByte(' ')
+		}
+		space = false
+		out.WriteString(token)
+	}
+	out.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			space = true
+			i++
+		case c == '#':
+			// comments run until the end of the line
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+			space = true
+		case c == '"':
+			i = skipGraphQLString(query, i)
+			write("?")
+		case isDigit(rune(c)) || (c == '-' && i+1 < len(query) && isDigit(rune(query[i+1]))):
+			i++
+			for i < len(query) && (isDigit(rune(query[i])) || strings.IndexByte(".eE+-", query[i]) != -1) {
+				i++
+			}
+			write("?")
+		case isGraphQLNameStart(c):
+			start := i
+			for i < len(query) && (isGraphQLNameStart(query[i]) || isDigit(rune(query[i]))) {
+				i++
+			}
+			inValue := defaultValue || (parenDepth > 0 && !varDefs)
+			if inValue && !variable && !graphQLKeyFollows(query, i) {
+				// enum values, booleans and null
+				write("?")
+			} else {
+				write(query[start:i])
+			}
+		default:
+			switch c {
+			case '(':
+				parenDepth++
+				if parenDepth == 1 {
+					varDefs = braceDepth == 0
+				}
+			case ')':
+				parenDepth--
+				if parenDepth <= 0 {
+					parenDepth = 0
+					varDefs, defaultValue = false, false
+				}
+			case '{':
+				if parenDepth == 0 {
+					braceDepth++
+				}
+			case '}':
+				if parenDepth == 0 && braceDepth > 0 {
+					braceDepth--
+				}
+			case '$':
+				if varDefs {
+					defaultValue = false
+				}
+			case '=':
+				if varDefs {
+					defaultValue = true
+				}
+			}
+			write(string(c))
+			i++
+		}
+		variable = c == '$'
+	}
+	return out.String()
+}
+
+// skipGraphQLString returns the position following the string or block string starting at i.
+func skipGraphQLString(query string, i int) int {
+	if strings.HasPrefix(query[i:], `"""`) {
+		for i += 3; i < len(query); i++ {
+			if query[i] == '\\' && strings.HasPrefix(query[i+1:], `"""`) {
+				i += 3
+				continue
+			}
+			if strings.HasPrefix(query[i:], `"""`) {
+				return i + 3
+			}
+		}
+		return i
+	}
+	for i++; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n', '\r':
+			// unterminated string
+			return i
+		}
+	}
+	return i
+}
+
+// graphQLKeyFollows reports whether the next token after i is a ':', meaning that the name
+// ending at i is an argument or an input object field name.
+func graphQLKeyFollows(query string, i int) bool {
+	for ; i < len(query); i++ {
+		switch query[i] {
+		case ' ', '\t', '\n', '\r', ',':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func isGraphQLNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGraphQLQuery(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{
+			`{ user { name } }`,
+			`{ user { name } }`,
+		},
+		{
+			`query User($id: ID!) { user(id: "1a2b", status: ACTIVE) { name friends(first: 10) { name } } }`,
+			`query User($id: ID!) { user(id: ?, status: ?) { name friends(first: ?) { name } } }`,
+		},
+		{
+			"query Users($first: Int = 10, $order: Order = ASC) {\n  # the users\n  users(first: $first, order: $order) {\n    id\n  }\n}",
+			"query Users($first: Int = ?, $order: Order = ?) { users(first: $first, order: $order) { id } }",
+		},
+		{
+			`mutation { createUser(input: {name: "jane", age: -42.5e1, admin: true, tags: [A, B], boss: null}) { id } }`,
+			`mutation { createUser(input: {name: ?, age: ?, admin: ?, tags: [?, ?], boss: ?}) { id } }`,
+		},
+		{
+			`{ me { name @include(if: $full) avatar(size: 64) ...details } } fragment details on User { email }`,
+			`{ me { name @include(if: $full) avatar(size: ?) ...details } } fragment details on User { email }`,
+		},
+		{
+			`{ search(text: "say \"hi\"", description: """multi` + "\n" + `line""") { id } }`,
+			`{ search(text: ?, description: ?) { id } }`,
+		},
+		{
+			`{ first: user(id: 1) { id } second: user(id: 2) { id } }`,
+			`{ first: user(id: ?) { id } second: user(id: ?) { id } }`,
+		},
+	} {
+		assert.Equal(t, tt.out, obfuscateGraphQLQuery(tt.in))
+	}
+}
+
+func TestObfuscateGraphQL(t *testing.T) {
+	assert := assert.New(t)
+	o := NewObfuscator(nil)
+
+	span := pb.Span{
+		Type:     "graphql",
+		Resource: "GetUser",
+		Meta:     map[string]string{"graphql.source": `query GetUser { user(id: 123) { name } }`},
+	}
+	o.obfuscateGraphQL(&span)
+	assert.Equal("GetUser", span.Resource)
+	assert.Equal(`query GetUser { user(id: ?) { name } }`, span.Meta["graphql.source"])
+
+	span = pb.Span{
+		Type:     "graphql",
+		Resource: `{ user(id: 123) { name } }`,
+	}
+	o.obfuscateGraphQL(&span)
+	assert.Equal(`{ user(id: ?) { name } }`, span.Resource)
+}
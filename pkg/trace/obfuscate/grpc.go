@@ -0,0 +1,82 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+)
+
+// obfuscateGRPC replaces the identifiers found in the gRPC method of the span, in its resource
+// and in its "grpc.method" tag, by "?", so that the calls of a method are grouped when some
+// clients or proxies put identifiers in the method names.
+func (*Obfuscator) obfuscateGRPC(span *pb.Span) {
+	span.Resource = obfuscateGRPCMethod(span.Resource)
+	const k = "grpc.method"
+	if span.Meta == nil || span.Meta[k] == "" {
+		return
+	}
+	span.Meta[k] = obfuscateGRPCMethod(span.Meta[k])
+}
+
+// obfuscateGRPCMethod replaces the identifiers of a full method name, such as
+// "/package.Service/Method", by "?". The parts separated by slashes or dots are identifiers
+// when they are numbers, UUIDs or hexadecimal strings of at least 16 characters.
+func obfuscateGRPCMethod(method string) string {
+	segs := strings.Split(method, "/")
+	var changed bool
+	for i, seg := range segs {
+		parts := strings.Split(seg, ".")
+		var segChanged bool
+		for j, part := range parts {
+			if isIdentifier(part) {
+				parts[j] = "?"
+				segChanged = true
+			}
+		}
+		if segChanged {
+			segs[i] = strings.Join(parts, ".")
+			changed = true
+		}
+	}
+	if !changed {
+		return method
+	}
+	return strings.Join(segs, "/")
+}
+
+// isIdentifier reports whether s looks like an identifier rather than a name: a number, a UUID
+// or a hexadecimal string of at least 16 characters containing a digit.
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	var digits, hex, dashes int
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F'):
+			hex++
+		case c == '-':
+			dashes++
+		default:
+			return false
+		}
+	}
+	switch {
+	case digits == len(s):
+		return true
+	case dashes == 4 && len(s) == 36:
+		// UUID
+		return s[8] == '-' && s[13] == '-' && s[18] == '-' && s[23] == '-'
+	case dashes == 0 && len(s) >= 16:
+		return digits > 0
+	}
+	return false
+}
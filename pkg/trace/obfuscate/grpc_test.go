@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateGRPCMethod(t *testing.T) {
+	for _, tt := range []struct {
+		in, out string
+	}{
+		{"/helloworld.Greeter/SayHello", "/helloworld.Greeter/SayHello"},
+		{"/api.v2.Users/GetUser", "/api.v2.Users/GetUser"},
+		{"/tenant.12345.Users/GetUser", "/tenant.?.Users/GetUser"},
+		{"/users.Users/Get/8e969193-2bc7-4a58-9a54-9eed44b01bb2", "/users.Users/Get/?"},
+		{"/cache.Cache/Get/0123456789abcdef0123", "/cache.Cache/Get/?"},
+		{"/cache.Cache/Deadbeefdeadbeef", "/cache.Cache/Deadbeefdeadbeef"},
+		{"", ""},
+	} {
+		assert.Equal(t, tt.out, obfuscateGRPCMethod(tt.in))
+	}
+}
+
+func TestObfuscateGRPC(t *testing.T) {
+	span := pb.Span{
+		Type:     "grpc",
+		Resource: "/orders.Orders/Get/42",
+		Meta:     map[string]string{"grpc.method": "/orders.Orders/Get/42"},
+	}
+	NewObfuscator(nil).obfuscateGRPC(&span)
+	assert.Equal(t, "/orders.Orders/Get/?", span.Resource)
+	assert.Equal(t, "/orders.Orders/Get/?", span.Meta["grpc.method"])
+}
@@ -15,6 +15,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 )
 
+// Version is the version of the obfuscation rules. It is sent with the payloads, so that
+// the intake can tell how their spans were obfuscated, and must be bumped whenever the
+// obfuscated output changes.
+const Version = 1
+
 // Obfuscator quantizes and obfuscates spans. The obfuscator is not safe for
 // concurrent use.
 type Obfuscator struct {
@@ -78,6 +83,14 @@ func (o *Obfuscator) Obfuscate(span *pb.Span) {
 		o.obfuscateJSON(span, "mongodb.query", o.mongo)
 	case "elasticsearch":
 		o.obfuscateJSON(span, "elasticsearch.body", o.es)
+	case "graphql":
+		if o.opts.GraphQL.Enabled {
+			o.obfuscateGraphQL(span)
+		}
+	case "grpc":
+		if o.opts.GRPC.Enabled {
+			o.obfuscateGRPC(span)
+		}
 	}
 }
 
@@ -218,6 +218,38 @@ func TestObfuscateConfig(t *testing.T) {
 		"set key 0 0 0 noreply\r\nvalue",
 		&config.ObfuscationConfig{},
 	))
+
+	t.Run("graphql/enabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`{ user(id: 1) { name } }`,
+		`{ user(id: ?) { name } }`,
+		&config.ObfuscationConfig{GraphQL: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("graphql/disabled", testConfig(
+		"graphql",
+		"graphql.source",
+		`{ user(id: 1) { name } }`,
+		`{ user(id: 1) { name } }`,
+		&config.ObfuscationConfig{},
+	))
+
+	t.Run("grpc/enabled", testConfig(
+		"grpc",
+		"grpc.method",
+		"/users.Users/Get/1234",
+		"/users.Users/Get/?",
+		&config.ObfuscationConfig{GRPC: config.Enablable{Enabled: true}},
+	))
+
+	t.Run("grpc/disabled", testConfig(
+		"grpc",
+		"grpc.method",
+		"/users.Users/Get/1234",
+		"/users.Users/Get/1234",
+		&config.ObfuscationConfig{},
+	))
 }
 
 func TestLiteralEscapes(t *testing.T) {
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// planSQLKeys are the keys of the execution plans holding SQL expressions, which are obfuscated as SQL.
+var planSQLKeys = map[string]bool{
+	// postgres
+	"Cache Key":           true,
+	"Filter":              true,
+	"Function Call":       true,
+	"Group Key":           true,
+	"Hash Cond":           true,
+	"Index Cond":          true,
+	"Join Filter":         true,
+	"Merge Cond":          true,
+	"One-Time Filter":     true,
+	"Output":              true,
+	"Recheck Cond":        true,
+	"Repeatable Seed":     true,
+	"Sampling Parameters": true,
+	"Sort Key":            true,
+	"TID Cond":            true,
+	"Table Function Call": true,
+	// mysql
+	"attached_condition": true,
+	"condition":          true,
+	"index_condition":    true,
+	"ref":                true,
+}
+
+// planKeepKeys are the keys of the execution plans whose string values describe the plan, and don't
+// hold any data from the queries. The string values of the other keys are replaced with "?".
+var planKeepKeys = map[string]bool{
+	// postgres
+	"Alias":               true,
+	"CTE Name":            true,
+	"Command":             true,
+	"Index Name":          true,
+	"Join Type":           true,
+	"Node Type":           true,
+	"Operation":           true,
+	"Parent Relationship": true,
+	"Partial Mode":        true,
+	"Relation Name":       true,
+	"Scan Direction":      true,
+	"Schema":              true,
+	"Sort Method":         true,
+	"Sort Space Type":     true,
+	"Strategy":            true,
+	"Subplan Name":        true,
+	// mysql
+	"access_type":           true,
+	"data_read_per_join":    true,
+	"eval_cost":             true,
+	"key":                   true,
+	"possible_keys":         true,
+	"prefix_cost":           true,
+	"query_cost":            true,
+	"read_cost":             true,
+	"select_type":           true,
+	"table_name":            true,
+	"used_columns":          true,
+	"used_key_parts":        true,
+	"using_filesort":        true,
+	"using_index":           true,
+	"using_temporary_table": true,
+}
+
+// planCostKeys are the keys of the execution plans holding estimates and measures, which vary between
+// two executions of a query and are removed when normalizing a plan.
+var planCostKeys = map[string]bool{
+	// postgres
+	"Actual Loops":           true,
+	"Actual Rows":            true,
+	"Actual Startup Time":    true,
+	"Actual Total Time":      true,
+	"Execution Time":         true,
+	"Plan Rows":              true,
+	"Plan Width":             true,
+	"Planning Time":          true,
+	"Rows Removed by Filter": true,
+	"Startup Cost":           true,
+	"Total Cost":             true,
+	// mysql
+	"cost_info":              true,
+	"filtered":               true,
+	"rows_examined_per_scan": true,
+	"rows_produced_per_join": true,
+}
+
+// ObfuscateSQLExecPlan obfuscates a JSON execution plan, as returned by EXPLAIN (FORMAT JSON) on postgres or
+// EXPLAIN FORMAT=JSON on mysql. The SQL expressions are obfuscated like queries, the other string values are
+// replaced with "?" unless they describe the plan, and the structure of the plan is kept. When normalize is
+// true, the estimated costs and rows are removed as well, so that the plans of a query can be grouped.
+func (o *Obfuscator) ObfuscateSQLExecPlan(jsonPlan string, normalize bool) (string, error) {
+	decoder := json.NewDecoder(strings.NewReader(jsonPlan))
+	decoder.UseNumber()
+	var plan interface{}
+	if err := decoder.Decode(&plan); err != nil {
+		return "", err
+	}
+
+	plan = o.obfuscatePlanValue("", plan, normalize)
+
+	var out bytes.Buffer
+	encoder := json.NewEncoder(&out)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(plan); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+// obfuscatePlanValue obfuscates the value of a key of a plan, the key being the one of the closest object
+// for the values of an array
+func (o *Obfuscator) obfuscatePlanValue(key string, value interface{}, normalize bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if normalize && planCostKeys[k] {
+				delete(v, k)
+				continue
+			}
+			v[k] = o.obfuscatePlanValue(k, child, normalize)
+		}
+		return v
+	case []interface{}:
+		for i, child := range v {
+			v[i] = o.obfuscatePlanValue(key, child, normalize)
+		}
+		return v
+	case string:
+		switch {
+		case planSQLKeys[key]:
+			oq, err := o.ObfuscateSQLString(v)
+			if err != nil {
+				return "?"
+			}
+			return oq.Query
+		case planKeepKeys[key]:
+			return v
+		default:
+			return "?"
+		}
+	default:
+		// numbers, booleans and nulls don't hold any data from the queries
+		return v
+	}
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package obfuscate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscateSQLExecPlan(t *testing.T) {
+	postgresPlan := `[{"Plan":{"Node Type":"Index Scan","Relation Name":"users","Alias":"u","Index Name":"users_pkey",` +
+		`"Index Cond":"(id = 42)","Filter":"((name)::text = 'bob'::text)","Startup Cost":0.29,"Total Cost":8.3,"Plan Rows":1,` +
+		`"Output":["id","name"],"Plans":[{"Node Type":"Seq Scan","Filter":"(email ~~ '%@example.com')","Subplan Name":"InitPlan 1"}]},` +
+		`"Planning Time":0.05}]`
+	mysqlPlan := `{"query_block":{"select_id":1,"cost_info":{"query_cost":"1.00"},"table":{"table_name":"users","access_type":"const",` +
+		`"key":"PRIMARY","ref":["const"],"attached_condition":"(` + "`db`.`users`.`name`" + ` = 'bob')","used_columns":["id","name"],` +
+		`"message":"secret"}}}`
+
+	for _, tc := range []struct {
+		name      string
+		plan      string
+		normalize bool
+		expected  string
+	}{
+		{
+			name: "postgres",
+			plan: postgresPlan,
+			expected: `[{"Plan":{"Alias":"u","Filter":"( ( name ) ::text = ? ::text )","Index Cond":"( id = ? )","Index Name":"users_pkey",` +
+				`"Node Type":"Index Scan","Output":["id","name"],"Plan Rows":1,"Plans":[{"Filter":"( email ~ ~ ? )","Node Type":"Seq Scan",` +
+				`"Subplan Name":"InitPlan 1"}],"Relation Name":"users","Startup Cost":0.29,"Total Cost":8.3},"Planning Time":0.05}]`,
+		},
+		{
+			name:      "postgres normalized",
+			plan:      postgresPlan,
+			normalize: true,
+			expected: `[{"Plan":{"Alias":"u","Filter":"( ( name ) ::text = ? ::text )","Index Cond":"( id = ? )","Index Name":"users_pkey",` +
+				`"Node Type":"Index Scan","Output":["id","name"],"Plans":[{"Filter":"( email ~ ~ ? )","Node Type":"Seq Scan",` +
+				`"Subplan Name":"InitPlan 1"}],"Relation Name":"users"}}]`,
+		},
+		{
+			name: "mysql",
+			plan: mysqlPlan,
+			expected: `{"query_block":{"cost_info":{"query_cost":"1.00"},"select_id":1,"table":{"access_type":"const",` +
+				`"attached_condition":"( db . users . name = ? )","key":"PRIMARY","message":"?","ref":["const"],"table_name":"users",` +
+				`"used_columns":["id","name"]}}}`,
+		},
+		{
+			name:      "mysql normalized",
+			plan:      mysqlPlan,
+			normalize: true,
+			expected: `{"query_block":{"select_id":1,"table":{"access_type":"const","attached_condition":"( db . users . name = ? )",` +
+				`"key":"PRIMARY","message":"?","ref":["const"],"table_name":"users","used_columns":["id","name"]}}}`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			obfuscated, err := NewObfuscator(nil).ObfuscateSQLExecPlan(tc.plan, tc.normalize)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, obfuscated)
+		})
+	}
+}
+
+func TestObfuscateSQLExecPlanInvalid(t *testing.T) {
+	_, err := NewObfuscator(nil).ObfuscateSQLExecPlan("not json", false)
+	assert.Error(t, err)
+}
@@ -0,0 +1,177 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package pb
+
+import (
+	"fmt"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// dictionarySpanLength is the number of elements of a span in the v0.5 format:
+// service, name, resource, trace_id, span_id, parent_id, start, duration, error, meta, metrics, type
+const dictionarySpanLength = 12
+
+// DecodeMsgDictionary decodes the traces of a payload in the v0.5 format: an array of two elements, a
+// dictionary of strings and the traces, each span being an array of 12 elements in which the strings
+// are replaced by their index in the dictionary. A nil dictionary entry is decoded as an empty string.
+func (t *Traces) DecodeMsgDictionary(dc *msgp.Reader) error {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if sz != 2 {
+		return fmt.Errorf("expected an array of 2 elements, the dictionary and the traces, found %d", sz)
+	}
+
+	dict, err := decodeDictionary(dc)
+	if err != nil {
+		return err
+	}
+
+	sz, err = dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if cap(*t) >= int(sz) {
+		*t = (*t)[:sz]
+	} else {
+		*t = make(Traces, sz)
+	}
+	for i := range *t {
+		if err := (*t)[i].decodeMsgDictionary(dc, dict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeDictionary(dc *msgp.Reader) ([]string, error) {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return nil, err
+	}
+	dict := make([]string, sz)
+	for i := range dict {
+		if dc.IsNil() {
+			if err := dc.ReadNil(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if dict[i], err = parseString(dc); err != nil {
+			return nil, err
+		}
+	}
+	return dict, nil
+}
+
+func (t *Trace) decodeMsgDictionary(dc *msgp.Reader, dict []string) error {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if cap(*t) >= int(sz) {
+		*t = (*t)[:sz]
+	} else {
+		*t = make(Trace, sz)
+	}
+	for i := range *t {
+		if (*t)[i] == nil {
+			(*t)[i] = new(Span)
+		}
+		if err := (*t)[i].decodeMsgDictionary(dc, dict); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseStringDict reads the index of a string in the dictionary and returns the string
+func parseStringDict(dc *msgp.Reader, dict []string) (string, error) {
+	i, err := parseUint64(dc)
+	if err != nil {
+		return "", err
+	}
+	if i >= uint64(len(dict)) {
+		return "", fmt.Errorf("dictionary index %d out of range, the dictionary has %d entries", i, len(dict))
+	}
+	return dict[i], nil
+}
+
+func (z *Span) decodeMsgDictionary(dc *msgp.Reader, dict []string) error {
+	sz, err := dc.ReadArrayHeader()
+	if err != nil {
+		return err
+	}
+	if sz != dictionarySpanLength {
+		return fmt.Errorf("expected a span of %d elements, found %d", dictionarySpanLength, sz)
+	}
+	if z.Service, err = parseStringDict(dc, dict); err != nil {
+		return err
+	}
+	if z.Name, err = parseStringDict(dc, dict); err != nil {
+		return err
+	}
+	if z.Resource, err = parseStringDict(dc, dict); err != nil {
+		return err
+	}
+	if z.TraceID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.SpanID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.ParentID, err = parseUint64(dc); err != nil {
+		return err
+	}
+	if z.Start, err = parseInt64(dc); err != nil {
+		return err
+	}
+	if z.Duration, err = parseInt64(dc); err != nil {
+		return err
+	}
+	if z.Error, err = parseInt32(dc); err != nil {
+		return err
+	}
+
+	sz, err = dc.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	z.Meta = make(map[string]string, sz)
+	for ; sz > 0; sz-- {
+		key, err := parseStringDict(dc, dict)
+		if err != nil {
+			return err
+		}
+		value, err := parseStringDict(dc, dict)
+		if err != nil {
+			return err
+		}
+		z.Meta[key] = value
+	}
+
+	sz, err = dc.ReadMapHeader()
+	if err != nil {
+		return err
+	}
+	z.Metrics = make(map[string]float64, sz)
+	for ; sz > 0; sz-- {
+		key, err := parseStringDict(dc, dict)
+		if err != nil {
+			return err
+		}
+		value, err := parseFloat64(dc)
+		if err != nil {
+			return err
+		}
+		z.Metrics[key] = value
+	}
+
+	z.Type, err = parseStringDict(dc, dict)
+	return err
+}
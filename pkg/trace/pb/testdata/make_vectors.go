@@ -0,0 +1,357 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build ignore
+
+// The 'make_vectors' program is run by go generate to write the test vectors of testdata/vectors:
+// msgpack payloads of traces in the v0.4 and v0.5 formats, with the traces the agent decodes from
+// the valid ones. Tracer implementations can use them to check their encoders against the decoder
+// of the agent, see pb.Validate.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/DataDog/datadog-agent/pkg/trace/pb"
+	"github.com/tinylib/msgp/msgp"
+)
+
+const vectorsDir = "testdata/vectors"
+
+// vector is a test vector, written as <name>.msgp, with the expected traces in <name>.json when it's valid
+type vector struct {
+	Name        string `json:"name"`
+	Format      string `json:"format"`
+	Valid       bool   `json:"valid"`
+	Description string `json:"description"`
+	payload     []byte
+	traces      pb.Traces
+}
+
+// encoder writes a msgpack payload
+type encoder struct {
+	buf bytes.Buffer
+	w   *msgp.Writer
+}
+
+func newEncoder() *encoder {
+	e := &encoder{}
+	e.w = msgp.NewWriter(&e.buf)
+	return e
+}
+
+func (e *encoder) write(values ...interface{}) *encoder {
+	for _, v := range values {
+		var err error
+		switch v := v.(type) {
+		case arr:
+			err = e.w.WriteArrayHeader(uint32(v))
+		case obj:
+			err = e.w.WriteMapHeader(uint32(v))
+		case bin:
+			err = e.w.WriteBytes([]byte(v))
+		case string:
+			err = e.w.WriteString(v)
+		case int64:
+			err = e.w.WriteInt64(v)
+		case int:
+			err = e.w.WriteInt64(int64(v))
+		case uint64:
+			err = e.w.WriteUint64(v)
+		case float64:
+			err = e.w.WriteFloat64(v)
+		case nil:
+			err = e.w.WriteNil()
+		default:
+			log.Fatalf("unsupported value %#v", v)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	return e
+}
+
+func (e *encoder) bytes() []byte {
+	if err := e.w.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	return e.buf.Bytes()
+}
+
+// arr, obj and bin are the array and map headers and the binary strings of the payloads
+type (
+	arr uint32
+	obj uint32
+	bin string
+)
+
+// v04Span writes a span of the v0.4 format with the given string values
+func v04Span(e *encoder, service, name, resource string, traceID, spanID, parentID uint64, start, duration int64, meta map[string]string, metrics map[string]float64) {
+	e.write(obj(11),
+		"service", service, "name", name, "resource", resource,
+		"trace_id", traceID, "span_id", spanID, "parent_id", parentID,
+		"start", start, "duration", duration, "error", 0,
+		"meta", obj(len(meta)))
+	for _, k := range sortedKeys(meta) {
+		e.write(k, meta[k])
+	}
+	e.write("metrics", obj(len(metrics)))
+	for _, k := range sortedMetricsKeys(metrics) {
+		e.write(k, metrics[k])
+	}
+}
+
+func span(service, name, resource string, traceID, spanID, parentID uint64, start, duration int64, errorCode int32, meta map[string]string, metrics map[string]float64, typ string) *pb.Span {
+	return &pb.Span{
+		Service:  service,
+		Name:     name,
+		Resource: resource,
+		TraceID:  traceID,
+		SpanID:   spanID,
+		ParentID: parentID,
+		Start:    start,
+		Duration: duration,
+		Error:    errorCode,
+		Meta:     meta,
+		Metrics:  metrics,
+		Type:     typ,
+	}
+}
+
+func v04Vectors() []vector {
+	var vectors []vector
+
+	e := newEncoder().write(arr(1), arr(2))
+	v04Span(e, "web", "http.request", "GET /users", 1, 1, 0, 1500000000000000000, 2000000, map[string]string{"http.method": "GET"}, map[string]float64{"_sampling_priority_v1": 1})
+	v04Span(e, "db", "postgres.query", "SELECT * FROM users", 1, 2, 1, 1500000000000500000, 1000000, map[string]string{}, map[string]float64{})
+	vectors = append(vectors, vector{
+		Name:        "v04_basic",
+		Format:      "v0.4",
+		Valid:       true,
+		Description: "a trace of two spans",
+		payload:     e.bytes(),
+		traces: pb.Traces{{
+			span("web", "http.request", "GET /users", 1, 1, 0, 1500000000000000000, 2000000, 0, map[string]string{"http.method": "GET"}, map[string]float64{"_sampling_priority_v1": 1}, ""),
+			span("db", "postgres.query", "SELECT * FROM users", 1, 2, 1, 1500000000000500000, 1000000, 0, nil, nil, ""),
+		}},
+	})
+
+	vectors = append(vectors, vector{
+		Name:        "v04_empty",
+		Format:      "v0.4",
+		Valid:       true,
+		Description: "no traces",
+		payload:     newEncoder().write(arr(0)).bytes(),
+		traces:      pb.Traces{},
+	})
+
+	vectors = append(vectors, vector{
+		Name:   "v04_uint_int_crossover",
+		Format: "v0.4",
+		Valid:  true,
+		Description: "the integers are encoded with the other signedness: negative int64 trace_id, " +
+			"uint64 start, duration and error, int and uint metrics",
+		payload: newEncoder().write(arr(1), arr(1), obj(8),
+			"service", "svc", "trace_id", int64(-1), "span_id", int64(2), "start", uint64(1500000000000000000),
+			"duration", uint64(100), "error", uint64(1), "metrics", obj(2), "int", int64(-3), "uint", uint64(4),
+			"type", "web").bytes(),
+		traces: pb.Traces{{
+			span("svc", "", "", math.MaxUint64, 2, 0, 1500000000000000000, 100, 1, nil, map[string]float64{"int": -3, "uint": 4}, "web"),
+		}},
+	})
+
+	vectors = append(vectors, vector{
+		Name:        "v04_binary_strings_and_nils",
+		Format:      "v0.4",
+		Valid:       true,
+		Description: "the strings are encoded as binary, the nil values are decoded as zero values",
+		payload: newEncoder().write(arr(1), arr(1), obj(6),
+			"service", bin("svc"), "name", nil, "resource", bin("res"), "trace_id", nil, "meta", nil, "type", bin("db")).bytes(),
+		traces: pb.Traces{{
+			span("svc", "", "res", 0, 0, 0, 0, 0, 0, nil, nil, "db"),
+		}},
+	})
+
+	vectors = append(vectors,
+		vector{
+			Name:        "v04_invalid_start_overflow",
+			Format:      "v0.4",
+			Description: "the start is an uint64 overflowing int64",
+			payload:     newEncoder().write(arr(1), arr(1), obj(1), "start", uint64(math.MaxUint64)).bytes(),
+		},
+		vector{
+			Name:        "v04_invalid_string_type",
+			Format:      "v0.4",
+			Description: "the service is an integer",
+			payload:     newEncoder().write(arr(1), arr(1), obj(1), "service", 42).bytes(),
+		},
+		vector{
+			Name:        "v04_invalid_truncated",
+			Format:      "v0.4",
+			Description: "the payload announces two traces but holds one",
+			payload:     newEncoder().write(arr(2), arr(1), obj(1), "service", "svc").bytes(),
+		},
+		vector{
+			Name:        "v04_invalid_trailing_data",
+			Format:      "v0.4",
+			Description: "bytes follow the traces",
+			payload:     newEncoder().write(arr(0), "trailing").bytes(),
+		},
+	)
+	return vectors
+}
+
+// v05Span writes a span of the v0.5 format, the strings being indexes in the dictionary
+func v05Span(e *encoder, service, name, resource int, traceID, spanID, parentID uint64, start, duration int64, errorCode int, meta [][2]int, metrics map[int]float64, typ int) {
+	e.write(arr(12), service, name, resource, traceID, spanID, parentID, start, duration, errorCode, obj(len(meta)))
+	for _, kv := range meta {
+		e.write(kv[0], kv[1])
+	}
+	keys := make([]int, 0, len(metrics))
+	for k := range metrics {
+		keys = append(keys, k)
+	}
+	sort.Ints(keys)
+	e.write(obj(len(metrics)))
+	for _, k := range keys {
+		e.write(k, metrics[k])
+	}
+	e.write(typ)
+}
+
+func v05Vectors() []vector {
+	var vectors []vector
+
+	dict := []interface{}{"", "web", "http.request", "GET /users", "http.method", "GET", "_sampling_priority_v1", "http", "db", "postgres.query"}
+	e := newEncoder().write(arr(2), arr(len(dict))).write(dict...).write(arr(1), arr(2))
+	v05Span(e, 1, 2, 3, 1, 1, 0, 1500000000000000000, 2000000, 0, [][2]int{{4, 5}}, map[int]float64{6: 1}, 7)
+	v05Span(e, 8, 9, 0, 1, 2, 1, 1500000000000500000, 1000000, 1, nil, nil, 8)
+	vectors = append(vectors, vector{
+		Name:        "v05_basic",
+		Format:      "v0.5",
+		Valid:       true,
+		Description: "a trace of two spans, the strings are indexes in the dictionary",
+		payload:     e.bytes(),
+		traces: pb.Traces{{
+			span("web", "http.request", "GET /users", 1, 1, 0, 1500000000000000000, 2000000, 0, map[string]string{"http.method": "GET"}, map[string]float64{"_sampling_priority_v1": 1}, "http"),
+			span("db", "postgres.query", "", 1, 2, 1, 1500000000000500000, 1000000, 1, map[string]string{}, map[string]float64{}, "db"),
+		}},
+	})
+
+	vectors = append(vectors, vector{
+		Name:        "v05_empty",
+		Format:      "v0.5",
+		Valid:       true,
+		Description: "an empty dictionary and no traces",
+		payload:     newEncoder().write(arr(2), arr(0), arr(0)).bytes(),
+		traces:      pb.Traces{},
+	})
+
+	e = newEncoder().write(arr(2), arr(3), nil, "svc", bin("op"), arr(1), arr(1))
+	v05Span(e, 1, 2, 0, 1, 1, 0, 1, 1, 0, [][2]int{{1, 0}}, nil, 0)
+	vectors = append(vectors, vector{
+		Name:        "v05_nil_dictionary_entry",
+		Format:      "v0.5",
+		Valid:       true,
+		Description: "a nil dictionary entry is decoded as an empty string, a binary one as a string",
+		payload:     e.bytes(),
+		traces: pb.Traces{{
+			span("svc", "op", "", 1, 1, 0, 1, 1, 0, map[string]string{"svc": ""}, map[string]float64{}, ""),
+		}},
+	})
+
+	vectors = append(vectors, vector{
+		Name:        "v05_uint_int_crossover",
+		Format:      "v0.5",
+		Valid:       true,
+		Description: "the integers are encoded with the other signedness, the dictionary indexes as int64 and uint64",
+		payload: newEncoder().write(arr(2), arr(2), "", "m", arr(1), arr(1),
+			arr(12), int64(0), uint64(0), int64(0), int64(-1), uint64(2), int64(1), uint64(1500000000000000000), uint64(100), uint64(1),
+			obj(0), obj(1), int64(1), int64(-3), uint64(0)).bytes(),
+		traces: pb.Traces{{
+			span("", "", "", math.MaxUint64, 2, 1, 1500000000000000000, 100, 1, map[string]string{}, map[string]float64{"m": -3}, ""),
+		}},
+	})
+
+	e = newEncoder().write(arr(2), arr(1), "svc", arr(1), arr(1))
+	v05Span(e, 0, 1, 0, 1, 1, 0, 1, 1, 0, nil, nil, 0)
+	vectors = append(vectors,
+		vector{
+			Name:        "v05_invalid_index_out_of_range",
+			Format:      "v0.5",
+			Description: "the name is the index 1 of a dictionary of 1 entry",
+			payload:     e.bytes(),
+		},
+		vector{
+			Name:        "v05_invalid_span_length",
+			Format:      "v0.5",
+			Description: "the span has 11 elements instead of 12",
+			payload:     newEncoder().write(arr(2), arr(1), "svc", arr(1), arr(1), arr(11), 0, 0, 0, 1, 1, 0, 1, 1, 0, obj(0), obj(0)).bytes(),
+		},
+		vector{
+			Name:        "v05_invalid_dictionary_type",
+			Format:      "v0.5",
+			Description: "a dictionary entry is an integer",
+			payload:     newEncoder().write(arr(2), arr(2), "svc", 42, arr(0)).bytes(),
+		},
+	)
+	return vectors
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedMetricsKeys(m map[string]float64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func writeJSON(path string, v interface{}) {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, append(b, '\n'), 0644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func main() {
+	log.SetPrefix("make_vectors: ")
+	log.SetFlags(0)
+
+	if err := os.MkdirAll(vectorsDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
+	vectors := append(v04Vectors(), v05Vectors()...)
+	for _, v := range vectors {
+		if err := ioutil.WriteFile(filepath.Join(vectorsDir, v.Name+".msgp"), v.payload, 0644); err != nil {
+			log.Fatal(err)
+		}
+		if v.Valid {
+			writeJSON(filepath.Join(vectorsDir, v.Name+".json"), v.traces)
+		}
+	}
+	writeJSON(filepath.Join(vectorsDir, "vectors.json"), vectors)
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+//go:generate go run testdata/make_vectors.go
+
+package pb
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// errTrailingData is returned by Validate when the payload has bytes after the traces
+var errTrailingData = errors.New("unexpected data after the traces")
+
+// Validate decodes a msgpack payload of traces with the decoder of the agent, so that tracer
+// implementations can check their payloads. The payload can be in the v0.4 format, an array of
+// traces made of spans encoded as maps, or in the v0.5 format, see (*Traces).DecodeMsgDictionary.
+// The test vectors of testdata/vectors are valid and invalid payloads of both formats.
+func Validate(payload []byte) error {
+	_, err := decodePayload(payload)
+	return err
+}
+
+// decodePayload decodes a payload in the v0.4 or the v0.5 format
+func decodePayload(payload []byte) (Traces, error) {
+	var traces Traces
+	dc := msgp.NewReader(bytes.NewReader(payload))
+	var err error
+	if isDictionaryPayload(payload) {
+		err = traces.DecodeMsgDictionary(dc)
+	} else {
+		err = traces.DecodeMsg(dc)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dc.R.Peek(1); err != io.EOF {
+		return nil, errTrailingData
+	}
+	return traces, nil
+}
+
+// isDictionaryPayload returns whether the payload is in the v0.5 format: an array of two
+// arrays, the first one holding strings and the second one traces of spans encoded as arrays.
+// In the v0.4 format, the spans are encoded as maps.
+func isDictionaryPayload(payload []byte) bool {
+	sz, rest, err := msgp.ReadArrayHeaderBytes(payload)
+	if err != nil || sz != 2 {
+		return false
+	}
+
+	dictSize, rest, err := msgp.ReadArrayHeaderBytes(rest)
+	if err != nil {
+		return false
+	}
+	if dictSize > 0 {
+		// the traces of a v0.4 payload start with a span, encoded as a map
+		t := msgp.NextType(rest)
+		return t == msgp.StrType || t == msgp.BinType || t == msgp.NilType
+	}
+
+	// empty dictionary, or empty first trace: look at the first span of the second element
+	tracesSize, rest, err := msgp.ReadArrayHeaderBytes(rest)
+	if err != nil || tracesSize == 0 {
+		return true
+	}
+	spansSize, rest, err := msgp.ReadArrayHeaderBytes(rest)
+	if err != nil {
+		return false
+	}
+	return spansSize == 0 || msgp.NextType(rest) == msgp.ArrayType
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package pb
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateVectors checks the test vectors written by testdata/make_vectors.go: the valid
+// payloads are decoded into the expected traces and the invalid ones are rejected.
+func TestValidateVectors(t *testing.T) {
+	index, err := ioutil.ReadFile("testdata/vectors/vectors.json")
+	require.NoError(t, err)
+	var vectors []struct {
+		Name   string `json:"name"`
+		Format string `json:"format"`
+		Valid  bool   `json:"valid"`
+	}
+	require.NoError(t, json.Unmarshal(index, &vectors))
+	require.NotEmpty(t, vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			payload, err := ioutil.ReadFile(filepath.Join("testdata/vectors", v.Name+".msgp"))
+			require.NoError(t, err)
+			assert.Equal(t, v.Format == "v0.5", isDictionaryPayload(payload))
+
+			if !v.Valid {
+				assert.Error(t, Validate(payload))
+				return
+			}
+			require.NoError(t, Validate(payload))
+
+			traces, err := decodePayload(payload)
+			require.NoError(t, err)
+			if len(traces) == 0 {
+				traces = Traces{}
+			}
+			expected, err := ioutil.ReadFile(filepath.Join("testdata/vectors", v.Name+".json"))
+			require.NoError(t, err)
+			actual, err := json.Marshal(traces)
+			require.NoError(t, err)
+			assert.JSONEq(t, string(expected), string(actual))
+		})
+	}
+}
+
+func TestValidateFormatDetection(t *testing.T) {
+	// a v0.4 payload of two traces, the first one being empty
+	v04 := []byte{0x92, 0x90, 0x91, 0x81, 0xa7, 's', 'e', 'r', 'v', 'i', 'c', 'e', 0xa1, 'a'}
+	assert.False(t, isDictionaryPayload(v04))
+	assert.NoError(t, Validate(v04))
+
+	// a v0.5 payload with an empty dictionary and an empty trace
+	v05 := []byte{0x92, 0x90, 0x91, 0x90}
+	assert.True(t, isDictionaryPayload(v05))
+	assert.NoError(t, Validate(v05))
+
+	assert.Error(t, Validate(nil))
+}
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/metrics"
 	"github.com/DataDog/datadog-agent/pkg/trace/pb"
 	"github.com/DataDog/datadog-agent/pkg/trace/watchdog"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -36,6 +37,9 @@ type Concentrator struct {
 	// wait such time before flushing the stats.
 	// This only applies to past buckets. Stats buckets in the future are allowed with no restriction.
 	bufferLen int
+	// lateSpans counts the spans ending before the oldest bucket still open since the last flush,
+	// which are added to the oldest bucket
+	lateSpans int64
 
 	In  chan *Input
 	Out chan []Bucket
@@ -47,17 +51,20 @@ type Concentrator struct {
 	mu      sync.Mutex
 }
 
-// NewConcentrator initializes a new concentrator ready to be started
-func NewConcentrator(aggregators []string, bsize int64, out chan []Bucket) *Concentrator {
+// NewConcentrator initializes a new concentrator ready to be started, keeping bufferLen buckets
+// open, or the default number of buckets when it's not positive
+func NewConcentrator(aggregators []string, bsize int64, bufferLen int, out chan []Bucket) *Concentrator {
+	if bufferLen <= 0 {
+		bufferLen = defaultBufferLen
+	}
 	c := Concentrator{
 		aggregators: aggregators,
 		bsize:       bsize,
 		buckets:     make(map[int64]*RawBucket),
 		// At start, only allow stats for the current time bucket. Ensure we don't
 		// override buckets which could have been sent before an Agent restart.
-		oldestTs: alignTs(time.Now().UnixNano(), bsize),
-		// TODO: Move to configuration.
-		bufferLen: defaultBufferLen,
+		oldestTs:  alignTs(time.Now().UnixNano(), bsize),
+		bufferLen: bufferLen,
 
 		In:  make(chan *Input, 1000),
 		Out: out,
@@ -138,6 +145,7 @@ func (c *Concentrator) addNow(i *Input, now int64) {
 		// If too far in the past, count in the oldest-allowed time bucket instead.
 		if btime < c.oldestTs {
 			btime = c.oldestTs
+			c.lateSpans++
 		}
 
 		b, ok := c.buckets[btime]
@@ -181,9 +189,16 @@ func (c *Concentrator) flushNow(now int64) []Bucket {
 		log.Debugf("update oldestTs to %d", newOldestTs)
 		c.oldestTs = newOldestTs
 	}
+	lateSpans := c.lateSpans
+	c.lateSpans = 0
 
 	c.mu.Unlock()
 
+	if lateSpans > 0 {
+		log.Debugf("%d spans ended before the oldest open bucket, they were counted in it", lateSpans)
+	}
+	metrics.Count("datadog.trace_agent.stats.late_spans", lateSpans, nil, 1)
+
 	return sb
 }
 
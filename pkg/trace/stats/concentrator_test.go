@@ -23,7 +23,7 @@ var (
 
 func NewTestConcentrator() *Concentrator {
 	statsChan := make(chan []Bucket)
-	return NewConcentrator([]string{}, time.Second.Nanoseconds(), statsChan)
+	return NewConcentrator([]string{}, time.Second.Nanoseconds(), defaultBufferLen, statsChan)
 }
 
 // getTsInBucket gives a timestamp in ns which is `offset` buckets late
@@ -160,7 +160,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 	t.Run("cold", func(t *testing.T) {
 		// Running cold, all spans in the past should end up in the current time bucket.
 		flushTime := now
-		c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+		c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 		c.addNow(testTrace, time.Now().UnixNano())
 
 		for i := 0; i < c.bufferLen; i++ {
@@ -189,7 +189,7 @@ func TestConcentratorOldestTs(t *testing.T) {
 
 	t.Run("hot", func(t *testing.T) {
 		flushTime := now
-		c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+		c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 		c.oldestTs = alignTs(now, c.bsize) - int64(c.bufferLen-1)*c.bsize
 		c.addNow(testTrace, time.Now().UnixNano())
 
@@ -231,12 +231,58 @@ func TestConcentratorOldestTs(t *testing.T) {
 	})
 }
 
+// TestConcentratorBucketWindow tests that the spans ending in the open buckets are counted in their
+// own bucket, and that the late ones are counted in the oldest bucket.
+func TestConcentratorBucketWindow(t *testing.T) {
+	assert := assert.New(t)
+	statsChan := make(chan []Bucket)
+
+	now := time.Now().UnixNano()
+	trace := pb.Trace{
+		testSpan(1, 0, 50, 5, "A1", "resource1", 0),
+		testSpan(1, 0, 40, 4, "A1", "resource1", 0),
+		testSpan(1, 0, 30, 3, "A1", "resource1", 0),
+		testSpan(1, 0, 20, 2, "A1", "resource1", 0),
+		testSpan(1, 0, 10, 1, "A1", "resource1", 0),
+		testSpan(1, 0, 1, 0, "A1", "resource1", 0),
+	}
+	traceutil.ComputeTopLevel(trace)
+	testTrace := &Input{
+		Env:   "none",
+		Trace: NewWeightedTrace(trace, traceutil.GetRoot(trace)),
+	}
+
+	for _, tc := range []struct {
+		bufferLen int
+		buckets   int
+		lateSpans int64
+	}{
+		{bufferLen: 2, buckets: 2, lateSpans: 4},
+		{bufferLen: 6, buckets: 6, lateSpans: 0},
+	} {
+		t.Run(fmt.Sprintf("window:%d", tc.bufferLen), func(t *testing.T) {
+			c := NewConcentrator([]string{}, testBucketInterval, tc.bufferLen, statsChan)
+			c.oldestTs = alignTs(now, c.bsize) - int64(c.bufferLen-1)*c.bsize
+			c.addNow(testTrace, now)
+
+			assert.Len(c.buckets, tc.buckets)
+			assert.Equal(tc.lateSpans, c.lateSpans)
+
+			c.flushNow(now)
+			assert.Equal(int64(0), c.lateSpans)
+		})
+	}
+
+	// the default window is used when it's not configured
+	assert.Equal(defaultBufferLen, NewConcentrator([]string{}, testBucketInterval, 0, statsChan).bufferLen)
+}
+
 //TestConcentratorStatsTotals tests that the total stats are correct, independently of the
 // time bucket they end up.
 func TestConcentratorStatsTotals(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+	c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := alignTs(now, c.bsize)
@@ -299,7 +345,7 @@ func TestConcentratorStatsTotals(t *testing.T) {
 func TestConcentratorStatsCounts(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+	c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := alignTs(now, c.bsize)
@@ -418,7 +464,7 @@ func TestConcentratorStatsCounts(t *testing.T) {
 func TestConcentratorSublayersStatsCounts(t *testing.T) {
 	assert := assert.New(t)
 	statsChan := make(chan []Bucket)
-	c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+	c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 
 	now := time.Now().UnixNano()
 	alignedNow := now - now%c.bsize
@@ -569,7 +615,7 @@ func TestConcentratorAddNow(t *testing.T) {
 				sublayers[subtrace.Root] = subtraceSublayers
 			}
 			testTrace.Sublayers = sublayers
-			c := NewConcentrator([]string{}, testBucketInterval, statsChan)
+			c := NewConcentrator([]string{}, testBucketInterval, defaultBufferLen, statsChan)
 			c.addNow(testTrace, time.Now().UnixNano())
 			stats := c.flushNow(now + (int64(c.bufferLen) * testBucketInterval))
 			countValsEq(t, test.out, stats[0].Counts)
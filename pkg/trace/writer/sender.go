@@ -22,6 +22,7 @@ import (
 
 	"github.com/DataDog/datadog-agent/pkg/trace/config"
 	"github.com/DataDog/datadog-agent/pkg/trace/info"
+	"github.com/DataDog/datadog-agent/pkg/trace/obfuscate"
 	"github.com/DataDog/datadog-agent/pkg/trace/osutil"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
@@ -302,13 +303,21 @@ type retriableError struct{ err error }
 func (e retriableError) Error() string { return e.err.Error() }
 
 const (
-	headerAPIKey    = "DD-Api-Key"
-	headerUserAgent = "User-Agent"
+	headerAPIKey             = "DD-Api-Key"
+	headerUserAgent          = "User-Agent"
+	headerAgentVersion       = "X-Datadog-Agent-Version"
+	headerObfuscationVersion = "X-Datadog-Obfuscation-Version"
+	// headerRequestID is the response header holding the ID given by the intake to a request
+	headerRequestID = "X-Request-Id"
 )
 
+var obfuscationVersion = strconv.Itoa(obfuscate.Version)
+
 func (s *sender) do(req *http.Request) error {
 	req.Header.Set(headerAPIKey, s.cfg.apiKey)
 	req.Header.Set(headerUserAgent, userAgent)
+	req.Header.Set(headerAgentVersion, info.Version)
+	req.Header.Set(headerObfuscationVersion, obfuscationVersion)
 	resp, err := s.cfg.client.Do(req)
 	if err != nil {
 		// request errors include timeouts or name resolution errors and
@@ -321,20 +330,34 @@ func (s *sender) do(req *http.Request) error {
 	io.Copy(ioutil.Discard, resp.Body)
 	resp.Body.Close()
 
+	// the request ID correlates the payload with the logs of the intake
+	requestID := resp.Header.Get(headerRequestID)
+	if requestID != "" {
+		log.Debugf("Payload sent to %s, status: %q, request ID: %s", req.URL.Path, resp.Status, requestID)
+	}
+
 	if resp.StatusCode/100 == 5 {
 		// 5xx errors can be retried
 		return &retriableError{
-			fmt.Errorf("server responded with %q", resp.Status),
+			fmt.Errorf("server responded with %q%s", resp.Status, requestIDSuffix(requestID)),
 		}
 	}
 	if resp.StatusCode/100 != 2 {
 		// status codes that are neither 2xx nor 5xx are considered
 		// non-retriable failures
-		return errors.New(resp.Status)
+		return errors.New(resp.Status + requestIDSuffix(requestID))
 	}
 	return nil
 }
 
+// requestIDSuffix returns the request ID to append to an error message, if there is one.
+func requestIDSuffix(requestID string) string {
+	if requestID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (request ID: %s)", requestID)
+}
+
 // payloads specifies a payload to be sent by the sender.
 type payload struct {
 	body    *bytes.Buffer     // request body
@@ -430,7 +453,9 @@ const (
 
 // backoffDuration returns the backoff duration necessary for the given attempt.
 // The formula is "Full Jitter":
-//   random_between(0, min(cap, base * 2 ** attempt))
+//
+//	random_between(0, min(cap, base * 2 ** attempt))
+//
 // https://aws.amazon.com/blogs/architecture/exponential-backoff-and-jitter/
 var backoffDuration = func(attempt int) time.Duration {
 	if attempt == 0 {
@@ -21,6 +21,7 @@ import (
 	"github.com/cihub/seelog"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/DataDog/datadog-agent/pkg/trace/info"
 	httputils "github.com/DataDog/datadog-agent/pkg/util/http"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -179,6 +180,8 @@ func TestSender(t *testing.T) {
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 			assert.Equal(testAPIKey, req.Header.Get(headerAPIKey))
 			assert.Equal(userAgent, req.Header.Get(headerUserAgent))
+			assert.Equal(info.Version, req.Header.Get(headerAgentVersion))
+			assert.Equal(obfuscationVersion, req.Header.Get(headerObfuscationVersion))
 			wg.Done()
 		}))
 		defer server.Close()
@@ -188,6 +191,19 @@ func TestSender(t *testing.T) {
 		wg.Wait()
 	})
 
+	t.Run("request ID", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set(headerRequestID, "abc-123")
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer server.Close()
+		s := newSender(testSenderConfig(server.URL))
+		defer s.Stop()
+		req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+		assert.NoError(t, err)
+		assert.EqualError(t, s.do(req), "400 Bad Request (request ID: abc-123)")
+	})
+
 	t.Run("events", func(t *testing.T) {
 		assert := assert.New(t)
 		server := newTestServer()
@@ -7,6 +7,7 @@ package writer
 
 import (
 	"math"
+	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -34,6 +35,11 @@ const (
 	maxEntriesPerPayload = 12000
 )
 
+const (
+	headerStatsBucketCount = "X-Datadog-Stats-Bucket-Count"
+	headerStatsEntryCount  = "X-Datadog-Stats-Entry-Count"
+)
+
 // StatsWriter ingests stats buckets and flushes them to the API.
 type StatsWriter struct {
 	in       <-chan []stats.Bucket
@@ -115,10 +121,16 @@ func (w *StatsWriter) addStats(s []stats.Bucket) {
 	log.Debugf("Flushing %d entries (buckets=%d payloads=%v)", entryCount, bucketCount, len(payloads))
 
 	for _, p := range payloads {
+		entries := 0
+		for _, b := range p.Stats {
+			entries += len(b.Counts)
+		}
 		req := newPayload(map[string]string{
-			headerLanguages:    strings.Join(info.Languages(), "|"),
-			"Content-Type":     "application/json",
-			"Content-Encoding": "gzip",
+			headerLanguages:        strings.Join(info.Languages(), "|"),
+			"Content-Type":         "application/json",
+			"Content-Encoding":     "gzip",
+			headerStatsBucketCount: strconv.Itoa(len(p.Stats)),
+			headerStatsEntryCount:  strconv.Itoa(entries),
 		})
 		if err := stats.EncodePayload(req.body, p); err != nil {
 			log.Errorf("Stats encoding error: %v", err)
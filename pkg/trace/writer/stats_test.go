@@ -55,6 +55,7 @@ func TestStatsWriter(t *testing.T) {
 			"Content-Type":                 "application/json",
 			"Content-Encoding":             "gzip",
 			"Dd-Api-Key":                   "123",
+			"X-Datadog-Stats-Bucket-Count": "3",
 		}
 		assertPayload(assert, expectedHeaders, testSets, srv.Payloads())
 	})
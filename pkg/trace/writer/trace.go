@@ -8,6 +8,7 @@ package writer
 import (
 	"compress/gzip"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -68,6 +69,11 @@ type TraceWriter struct {
 	events       []*pb.Span     // events buffered
 	bufferedSize int            // estimated buffer size
 
+	// droppedTraces and droppedSpans count the traces dropped by the samplers since
+	// the last payload, sent in its headers
+	droppedTraces int64
+	droppedSpans  int64
+
 	easylog *logutil.ThrottledLogger
 }
 
@@ -169,13 +175,27 @@ func (w *TraceWriter) addSpans(pkg *SampledSpans) {
 	w.bufferedSize += size
 }
 
+// RecordSamplerDrop counts a trace of the given number of spans dropped by the samplers,
+// reported in the headers of the next payload.
+func (w *TraceWriter) RecordSamplerDrop(spans int) {
+	atomic.AddInt64(&w.droppedTraces, 1)
+	atomic.AddInt64(&w.droppedSpans, int64(spans))
+}
+
 func (w *TraceWriter) resetBuffer() {
 	w.bufferedSize = 0
 	w.traces = w.traces[:0]
 	w.events = w.events[:0]
 }
 
-const headerLanguages = "X-Datadog-Reported-Languages"
+const (
+	headerLanguages            = "X-Datadog-Reported-Languages"
+	headerTraceCount           = "X-Datadog-Trace-Count"
+	headerSpanCount            = "X-Datadog-Span-Count"
+	headerEventCount           = "X-Datadog-Event-Count"
+	headerSamplerDroppedTraces = "X-Datadog-Sampler-Dropped-Traces"
+	headerSamplerDroppedSpans  = "X-Datadog-Sampler-Dropped-Spans"
+)
 
 func (w *TraceWriter) flush() {
 	if len(w.traces) == 0 && len(w.events) == 0 {
@@ -202,15 +222,27 @@ func (w *TraceWriter) flush() {
 	atomic.AddInt64(&w.stats.BytesUncompressed, int64(len(b)))
 	atomic.AddInt64(&w.stats.BytesEstimated, int64(w.bufferedSize))
 
+	// the counts of the payload help correlating the data sent with the intake
+	spans := 0
+	for _, t := range w.traces {
+		spans += len(t.Spans)
+	}
+	headers := map[string]string{
+		"Content-Type":             "application/x-protobuf",
+		"Content-Encoding":         "gzip",
+		headerLanguages:            strings.Join(info.Languages(), "|"),
+		headerTraceCount:           strconv.Itoa(len(w.traces)),
+		headerSpanCount:            strconv.Itoa(spans),
+		headerEventCount:           strconv.Itoa(len(w.events)),
+		headerSamplerDroppedTraces: strconv.FormatInt(atomic.SwapInt64(&w.droppedTraces, 0), 10),
+		headerSamplerDroppedSpans:  strconv.FormatInt(atomic.SwapInt64(&w.droppedSpans, 0), 10),
+	}
+
 	w.wg.Add(1)
 	go func() {
 		defer timing.Since("datadog.trace_agent.trace_writer.compress_ms", time.Now())
 		defer w.wg.Done()
-		p := newPayload(map[string]string{
-			"Content-Type":     "application/x-protobuf",
-			"Content-Encoding": "gzip",
-			headerLanguages:    strings.Join(info.Languages(), "|"),
-		})
+		p := newPayload(headers)
 		gzipw, err := gzip.NewWriterLevel(p.body, gzip.BestSpeed)
 		if err != nil {
 			// it will never happen, unless an invalid compression is chosen;
@@ -54,6 +54,38 @@ func TestTraceWriter(t *testing.T) {
 	})
 }
 
+func TestTraceWriterHeaders(t *testing.T) {
+	srv := newTestServer()
+	cfg := &config.AgentConfig{
+		Hostname:   testHostname,
+		DefaultEnv: testEnv,
+		Endpoints: []*config.Endpoint{{
+			APIKey: "123",
+			Host:   srv.URL,
+		}},
+		TraceWriter: &config.WriterConfig{ConnectionLimit: 200, QueueSize: 40},
+	}
+	in := make(chan *SampledSpans)
+	tw := NewTraceWriter(cfg, in)
+	go tw.Run()
+	tw.RecordSamplerDrop(3)
+	tw.RecordSamplerDrop(5)
+	in <- randomSampledSpans(20, 8)
+	in <- randomSampledSpans(10, 0)
+	tw.Stop()
+
+	assert := assert.New(t)
+	payloads := srv.Payloads()
+	assert.Len(payloads, 1)
+	headers := payloads[0].headers
+	assert.Equal("2", headers[headerTraceCount])
+	assert.Equal("30", headers[headerSpanCount])
+	assert.Equal("8", headers[headerEventCount])
+	assert.Equal("2", headers[headerSamplerDroppedTraces])
+	assert.Equal("8", headers[headerSamplerDroppedSpans])
+	assert.Equal(obfuscationVersion, headers[headerObfuscationVersion])
+}
+
 func TestTraceWriterMultipleEndpointsConcurrent(t *testing.T) {
 	var (
 		srv = newTestServer()
@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Alibaba Metadata availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("Alibaba Metadata availability", diagnose)
 }
 
 // diagnose the alibaba metadata API availability
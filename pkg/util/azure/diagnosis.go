@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Azure Metadata availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("Azure Metadata availability", diagnose)
 }
 
 // diagnose the azure metadata API availability
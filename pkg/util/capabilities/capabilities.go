@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package capabilities
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Names of the system capabilities probed at startup
+const (
+	// Cgroups is the cgroup hierarchy, used to collect the containers metrics
+	Cgroups = "cgroups"
+	// EBPF is the support of eBPF programs by the kernel, required by the system-probe
+	EBPF = "ebpf"
+	// BTF is the BPF Type Format information of the kernel
+	BTF = "btf"
+	// Docker is the socket of the Docker daemon
+	Docker = "docker"
+	// Containerd is the socket of containerd
+	Containerd = "containerd"
+	// CRIO is the socket of CRI-O
+	CRIO = "cri-o"
+	// SELinux is the SELinux confinement of the agent
+	SELinux = "selinux"
+	// AppArmor is the AppArmor confinement of the agent
+	AppArmor = "apparmor"
+)
+
+// Capability is the result of the probe of a system capability
+type Capability struct {
+	Name      string `json:"name"`
+	Available bool   `json:"available"`
+	// Detail describes the capability when it's available, e.g. the cgroup version
+	Detail string `json:"detail,omitempty"`
+	// Reason explains why the capability isn't available
+	Reason string `json:"reason,omitempty"`
+}
+
+// Report holds the system capabilities detected at startup
+type Report struct {
+	KernelVersion string       `json:"kernel_version,omitempty"`
+	Capabilities  []Capability `json:"capabilities"`
+}
+
+var (
+	report     *Report
+	reportOnce sync.Once
+)
+
+// Get returns the system capabilities, probing them on the first call
+func Get() *Report {
+	reportOnce.Do(func() {
+		report = Detect()
+	})
+	return report
+}
+
+// Detect probes the system capabilities
+func Detect() *Report {
+	return &Report{
+		KernelVersion: kernelVersion(),
+		Capabilities:  probe(),
+	}
+}
+
+// Get returns the capability with the given name
+func (r *Report) Get(name string) (Capability, bool) {
+	for _, c := range r.Capabilities {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Capability{}, false
+}
+
+// Require returns an error explaining why a component depending on the capability can't run,
+// or nil when the capability is available
+func Require(name string) error {
+	return Get().Require(name)
+}
+
+// Require returns an error explaining why a component depending on the capability can't run,
+// or nil when the capability is available
+func (r *Report) Require(name string) error {
+	c, found := r.Get(name)
+	if !found {
+		return fmt.Errorf("%s is not available: it wasn't probed", name)
+	}
+	if !c.Available {
+		return fmt.Errorf("%s is not available: %s", name, c.Reason)
+	}
+	return nil
+}
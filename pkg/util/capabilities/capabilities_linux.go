@@ -0,0 +1,213 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package capabilities
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+const (
+	// eBPF programs need the perf events output of the kernel 4.4
+	minEBPFKernelMajor = 4
+	minEBPFKernelMinor = 4
+)
+
+var (
+	// For testing purposes
+	sysRoot = "/sys"
+	// the sockets are also looked up under /host when the agent runs in a container
+	socketRoots = []string{"", "/host"}
+)
+
+func kernelVersion() string {
+	release, err := ioutil.ReadFile(filepath.Join(config.Datadog.GetString("container_proc_root"), "sys/kernel/osrelease"))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(release))
+}
+
+func probe() []Capability {
+	procRoot := config.Datadog.GetString("container_proc_root")
+	return []Capability{
+		probeCgroups(config.Datadog.GetString("container_cgroup_root"), procRoot),
+		probeEBPF(kernelVersion()),
+		probeBTF(),
+		probeSocket(Docker, "/var/run/docker.sock"),
+		probeSocket(Containerd, containerdSocket()),
+		probeSocket(CRIO, "/var/run/crio/crio.sock"),
+		probeSELinux(),
+		probeAppArmor(procRoot),
+	}
+}
+
+// probeCgroups detects the cgroup version: v2 when the unified hierarchy is mounted on the
+// cgroup root, v1 when cgroup controllers are mounted
+func probeCgroups(cgroupRoot, procRoot string) Capability {
+	c := Capability{Name: Cgroups}
+	if _, err := os.Stat(filepath.Join(cgroupRoot, "cgroup.controllers")); err == nil {
+		c.Available = true
+		c.Detail = "v2"
+		return c
+	}
+
+	mounts, err := os.Open(filepath.Join(procRoot, "self/mounts"))
+	if err != nil {
+		c.Reason = fmt.Sprintf("unable to read the mounts: %s", err)
+		return c
+	}
+	defer mounts.Close()
+
+	var v1, v2 bool
+	scanner := bufio.NewScanner(mounts)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 {
+			continue
+		}
+		switch fields[2] {
+		case "cgroup":
+			v1 = true
+		case "cgroup2":
+			v2 = true
+		}
+	}
+
+	switch {
+	case v1 && v2:
+		c.Available = true
+		c.Detail = "v1 (hybrid)"
+	case v1:
+		c.Available = true
+		c.Detail = "v1"
+	case v2:
+		c.Reason = fmt.Sprintf("the cgroup v2 hierarchy isn't mounted on %s", cgroupRoot)
+	default:
+		c.Reason = "no cgroup hierarchy is mounted"
+	}
+	return c
+}
+
+// probeEBPF checks that the kernel is recent enough to run the eBPF programs of the system-probe
+func probeEBPF(release string) Capability {
+	c := Capability{Name: EBPF}
+	if release == "" {
+		c.Reason = "unable to read the kernel version"
+		return c
+	}
+
+	major, minor, err := parseKernelRelease(release)
+	if err != nil {
+		c.Reason = err.Error()
+		return c
+	}
+	if major < minEBPFKernelMajor || (major == minEBPFKernelMajor && minor < minEBPFKernelMinor) {
+		c.Reason = fmt.Sprintf("the kernel %s is older than %d.%d", release, minEBPFKernelMajor, minEBPFKernelMinor)
+		return c
+	}
+	c.Available = true
+	return c
+}
+
+// parseKernelRelease returns the major and minor versions of a kernel release, e.g. 5.4.0-42-generic
+func parseKernelRelease(release string) (int, int, error) {
+	parts := strings.SplitN(release, ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, fmt.Errorf("unable to parse the kernel version %q", release)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse the kernel version %q", release)
+	}
+	minor, err := strconv.Atoi(strings.TrimRightFunc(parts[1], func(r rune) bool { return r < '0' || r > '9' }))
+	if err != nil {
+		return 0, 0, fmt.Errorf("unable to parse the kernel version %q", release)
+	}
+	return major, minor, nil
+}
+
+func probeBTF() Capability {
+	c := Capability{Name: BTF}
+	if _, err := os.Stat(filepath.Join(sysRoot, "kernel/btf/vmlinux")); err != nil {
+		c.Reason = "the kernel doesn't expose its BTF information"
+		return c
+	}
+	c.Available = true
+	return c
+}
+
+func containerdSocket() string {
+	if socket := config.Datadog.GetString("cri_socket_path"); socket != "" {
+		return socket
+	}
+	return "/var/run/containerd/containerd.sock"
+}
+
+// probeSocket checks that the socket of a container runtime exists
+func probeSocket(name, socket string) Capability {
+	c := Capability{Name: name}
+	for _, root := range socketRoots {
+		path := root + socket
+		if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+			c.Available = true
+			c.Detail = path
+			return c
+		}
+	}
+	c.Reason = fmt.Sprintf("no socket found at %s", socket)
+	return c
+}
+
+// probeSELinux reports the SELinux mode, the agent being confined when it's enforcing
+func probeSELinux() Capability {
+	c := Capability{Name: SELinux}
+	enforce, err := ioutil.ReadFile(filepath.Join(sysRoot, "fs/selinux/enforce"))
+	if err != nil {
+		c.Reason = "SELinux is disabled"
+		return c
+	}
+	c.Available = true
+	if strings.TrimSpace(string(enforce)) == "1" {
+		c.Detail = "enforcing"
+	} else {
+		c.Detail = "permissive"
+	}
+	return c
+}
+
+// probeAppArmor reports the AppArmor profile confining the agent
+func probeAppArmor(procRoot string) Capability {
+	c := Capability{Name: AppArmor}
+	enabled, err := ioutil.ReadFile(filepath.Join(sysRoot, "module/apparmor/parameters/enabled"))
+	if err != nil || strings.TrimSpace(string(enabled)) != "Y" {
+		c.Reason = "AppArmor is disabled"
+		return c
+	}
+
+	profile, err := ioutil.ReadFile(filepath.Join(procRoot, "self/attr/current"))
+	if err != nil {
+		c.Reason = fmt.Sprintf("unable to read the AppArmor profile: %s", err)
+		return c
+	}
+	current := strings.TrimSpace(strings.TrimRight(string(profile), "\x00"))
+	if current == "" || current == "unconfined" {
+		c.Reason = "the agent is unconfined"
+		return c
+	}
+	c.Available = true
+	c.Detail = current
+	return c
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package capabilities
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0644))
+}
+
+func TestProbeCgroups(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		controllers bool
+		mounts      string
+		available   bool
+		detail      string
+	}{
+		{"v2", true, "", true, "v2"},
+		{"v1", false, "cgroup /sys/fs/cgroup/memory cgroup rw,memory 0 0\n", true, "v1"},
+		{"hybrid", false, "cgroup /sys/fs/cgroup/memory cgroup rw,memory 0 0\ncgroup2 /sys/fs/cgroup/unified cgroup2 rw 0 0\n", true, "v1 (hybrid)"},
+		{"none", false, "proc /proc proc rw 0 0\n", false, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "capabilities")
+			require.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			cgroupRoot := filepath.Join(dir, "cgroup")
+			require.NoError(t, os.MkdirAll(cgroupRoot, 0755))
+			if tt.controllers {
+				writeFile(t, filepath.Join(cgroupRoot, "cgroup.controllers"), "cpu memory")
+			}
+			writeFile(t, filepath.Join(dir, "proc/self/mounts"), tt.mounts)
+
+			c := probeCgroups(cgroupRoot, filepath.Join(dir, "proc"))
+			assert.Equal(t, tt.available, c.Available)
+			assert.Equal(t, tt.detail, c.Detail)
+			if !tt.available {
+				assert.NotEmpty(t, c.Reason)
+			}
+		})
+	}
+}
+
+func TestProbeEBPF(t *testing.T) {
+	assert.True(t, probeEBPF("5.4.0-42-generic").Available)
+	assert.True(t, probeEBPF("4.4.0").Available)
+	assert.True(t, probeEBPF("4.14+").Available)
+
+	c := probeEBPF("3.10.0-1127.el7.x86_64")
+	assert.False(t, c.Available)
+	assert.Equal(t, "the kernel 3.10.0-1127.el7.x86_64 is older than 4.4", c.Reason)
+
+	assert.False(t, probeEBPF("").Available)
+	assert.False(t, probeEBPF("unknown").Available)
+}
+
+func TestProbeConfinement(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capabilities")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	defer func(root string) { sysRoot = root }(sysRoot)
+	sysRoot = filepath.Join(dir, "sys")
+	procRoot := filepath.Join(dir, "proc")
+
+	assert.False(t, probeSELinux().Available)
+	assert.False(t, probeAppArmor(procRoot).Available)
+
+	writeFile(t, filepath.Join(sysRoot, "fs/selinux/enforce"), "1")
+	c := probeSELinux()
+	assert.True(t, c.Available)
+	assert.Equal(t, "enforcing", c.Detail)
+
+	writeFile(t, filepath.Join(sysRoot, "module/apparmor/parameters/enabled"), "Y\n")
+	writeFile(t, filepath.Join(procRoot, "self/attr/current"), "unconfined\n")
+	c = probeAppArmor(procRoot)
+	assert.False(t, c.Available)
+	assert.Equal(t, "the agent is unconfined", c.Reason)
+
+	writeFile(t, filepath.Join(procRoot, "self/attr/current"), "docker-default (enforce)\n")
+	c = probeAppArmor(procRoot)
+	assert.True(t, c.Available)
+	assert.Equal(t, "docker-default (enforce)", c.Detail)
+}
+
+func TestProbeSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "capabilities")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	socket := filepath.Join(dir, "runtime.sock")
+	assert.False(t, probeSocket(Docker, socket).Available)
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	defer listener.Close()
+
+	c := probeSocket(Docker, socket)
+	assert.True(t, c.Available)
+	assert.Equal(t, socket, c.Detail)
+
+	// a regular file isn't a socket
+	writeFile(t, filepath.Join(dir, "file.sock"), "")
+	assert.False(t, probeSocket(Docker, filepath.Join(dir, "file.sock")).Available)
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build !linux
+
+package capabilities
+
+func kernelVersion() string {
+	return ""
+}
+
+// probe reports the Linux capabilities as unavailable
+func probe() []Capability {
+	var capabilities []Capability
+	for _, name := range []string{Cgroups, EBPF, BTF, Docker, Containerd, CRIO, SELinux, AppArmor} {
+		capabilities = append(capabilities, Capability{
+			Name:   name,
+			Reason: "only probed on Linux",
+		})
+	}
+	return capabilities
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package capabilities
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReportRequire(t *testing.T) {
+	r := &Report{
+		Capabilities: []Capability{
+			{Name: Cgroups, Available: true, Detail: "v2"},
+			{Name: EBPF, Reason: "the kernel 3.10.0 is older than 4.4"},
+		},
+	}
+
+	assert.NoError(t, r.Require(Cgroups))
+	assert.EqualError(t, r.Require(EBPF), "ebpf is not available: the kernel 3.10.0 is older than 4.4")
+	assert.EqualError(t, r.Require(Docker), "docker is not available: it wasn't probed")
+
+	c, found := r.Get(Cgroups)
+	assert.True(t, found)
+	assert.Equal(t, "v2", c.Detail)
+}
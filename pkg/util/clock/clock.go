@@ -0,0 +1,146 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// Package clock provides the time used to timestamp the metrics, service checks and events.
+// The wall clock of the host can go back, when NTP steps it or when a VM snapshot is restored,
+// and the points timestamped during the jump would be misordered or rejected by the backend.
+// The time provided never goes back: after a backward jump it advances at half the speed of
+// the monotonic clock until the wall clock catches up.
+package clock
+
+import (
+	"expvar"
+	"sync"
+	"time"
+)
+
+const (
+	// jumpThreshold is the minimum backward jump of the wall clock that is reported, the
+	// smaller ones being the usual NTP adjustments
+	jumpThreshold = time.Second
+	// maxPendingJumps bounds the jumps kept until they are taken, when nobody takes them
+	maxPendingJumps = 10
+)
+
+var defaultProvider = NewMonotonicProvider()
+
+func init() {
+	expvar.Publish("clock", expvar.Func(func() interface{} {
+		return defaultProvider.Stats()
+	}))
+}
+
+// Provider provides the current time
+type Provider interface {
+	Now() time.Time
+}
+
+// Jump is a backward jump of the wall clock
+type Jump struct {
+	// Time is the wall clock time after the jump
+	Time time.Time
+	// Duration is how far the wall clock went back
+	Duration time.Duration
+}
+
+// JumpStats summarizes the backward jumps of the wall clock detected by a MonotonicProvider
+type JumpStats struct {
+	Count int64
+	// LastJump is the unix time of the last jump
+	LastJump int64
+	// LastDuration is how far the wall clock went back on the last jump, in seconds
+	LastDuration float64
+}
+
+// MonotonicProvider is a Provider smoothing the backward jumps of the wall clock
+type MonotonicProvider struct {
+	m sync.Mutex
+
+	wallNow func() time.Time
+	monoNow func() time.Duration
+
+	initialized  bool
+	lastWall     time.Time
+	lastMono     time.Duration
+	lastProvided time.Time
+
+	pendingJumps []Jump
+	stats        JumpStats
+}
+
+// NewMonotonicProvider returns a MonotonicProvider reading the wall and monotonic clocks of the host
+func NewMonotonicProvider() *MonotonicProvider {
+	start := time.Now()
+	return newMonotonicProvider(time.Now, func() time.Duration { return time.Since(start) })
+}
+
+func newMonotonicProvider(wallNow func() time.Time, monoNow func() time.Duration) *MonotonicProvider {
+	return &MonotonicProvider{
+		wallNow: wallNow,
+		monoNow: monoNow,
+	}
+}
+
+// Now returns the wall clock time, or the smoothed time while it catches up a backward jump.
+// The time returned has no monotonic clock reading.
+func (p *MonotonicProvider) Now() time.Time {
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	wall := p.wallNow().Round(0)
+	mono := p.monoNow()
+	if !p.initialized {
+		p.initialized = true
+		p.lastWall, p.lastMono, p.lastProvided = wall, mono, wall
+		return wall
+	}
+
+	elapsed := mono - p.lastMono
+	if drift := wall.Sub(p.lastWall) - elapsed; drift <= -jumpThreshold {
+		p.addJump(Jump{Time: wall, Duration: -drift})
+	}
+
+	now := wall
+	if slewed := p.lastProvided.Add(elapsed / 2); now.Before(slewed) {
+		now = slewed
+	}
+	p.lastWall, p.lastMono, p.lastProvided = wall, mono, now
+	return now
+}
+
+func (p *MonotonicProvider) addJump(jump Jump) {
+	p.stats.Count++
+	p.stats.LastJump = jump.Time.Unix()
+	p.stats.LastDuration = jump.Duration.Seconds()
+	if len(p.pendingJumps) < maxPendingJumps {
+		p.pendingJumps = append(p.pendingJumps, jump)
+	}
+}
+
+// TakeJumps returns the backward jumps detected since its last call
+func (p *MonotonicProvider) TakeJumps() []Jump {
+	p.m.Lock()
+	defer p.m.Unlock()
+	jumps := p.pendingJumps
+	p.pendingJumps = nil
+	return jumps
+}
+
+// Stats returns the stats of the backward jumps detected since the creation of the provider
+func (p *MonotonicProvider) Stats() JumpStats {
+	p.m.Lock()
+	defer p.m.Unlock()
+	return p.stats
+}
+
+// Default returns the provider used by the aggregator and the checks
+func Default() *MonotonicProvider {
+	return defaultProvider
+}
+
+// Now returns the time of the default provider
+func Now() time.Time {
+	return defaultProvider.Now()
+}
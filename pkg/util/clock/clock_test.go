@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClocks struct {
+	wall time.Time
+	mono time.Duration
+}
+
+func (c *fakeClocks) advance(d time.Duration) {
+	c.wall = c.wall.Add(d)
+	c.mono += d
+}
+
+func newFakeProvider(c *fakeClocks) *MonotonicProvider {
+	return newMonotonicProvider(
+		func() time.Time { return c.wall },
+		func() time.Duration { return c.mono },
+	)
+}
+
+func TestMonotonicProviderNoJump(t *testing.T) {
+	c := &fakeClocks{wall: time.Unix(1000, 0)}
+	p := newFakeProvider(c)
+
+	assert.Equal(t, time.Unix(1000, 0), p.Now())
+	c.advance(15 * time.Second)
+	assert.Equal(t, time.Unix(1015, 0), p.Now())
+
+	// forward jumps are not smoothed
+	c.wall = c.wall.Add(time.Hour)
+	assert.Equal(t, time.Unix(4615, 0), p.Now())
+
+	// small adjustments are smoothed but not reported
+	c.wall = c.wall.Add(-100 * time.Millisecond)
+	assert.Equal(t, time.Unix(4615, 0), p.Now())
+
+	assert.Empty(t, p.TakeJumps())
+	assert.Equal(t, int64(0), p.Stats().Count)
+}
+
+func TestMonotonicProviderBackwardJump(t *testing.T) {
+	c := &fakeClocks{wall: time.Unix(1000, 0)}
+	p := newFakeProvider(c)
+	p.Now()
+
+	c.advance(10 * time.Second)
+	c.wall = c.wall.Add(-20 * time.Second)
+	// the time provided advances at half speed until the wall clock catches up
+	assert.Equal(t, time.Unix(1005, 0), p.Now())
+	c.advance(20 * time.Second)
+	assert.Equal(t, time.Unix(1015, 0), p.Now())
+	c.advance(20 * time.Second)
+	assert.Equal(t, time.Unix(1030, 0), p.Now())
+
+	jumps := p.TakeJumps()
+	require.Len(t, jumps, 1)
+	assert.Equal(t, time.Unix(990, 0), jumps[0].Time)
+	assert.Equal(t, 20*time.Second, jumps[0].Duration)
+	assert.Empty(t, p.TakeJumps())
+
+	stats := p.Stats()
+	assert.Equal(t, int64(1), stats.Count)
+	assert.Equal(t, int64(990), stats.LastJump)
+	assert.Equal(t, 20.0, stats.LastDuration)
+}
+
+func TestMonotonicProviderPendingJumpsBounded(t *testing.T) {
+	c := &fakeClocks{wall: time.Unix(1000, 0)}
+	p := newFakeProvider(c)
+	p.Now()
+
+	for i := 0; i < 2*maxPendingJumps; i++ {
+		c.wall = c.wall.Add(-time.Minute)
+		p.Now()
+	}
+	assert.Len(t, p.TakeJumps(), maxPendingJumps)
+	assert.Equal(t, int64(2*maxPendingJumps), p.Stats().Count)
+}
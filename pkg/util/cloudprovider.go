@@ -6,6 +6,8 @@
 package util
 
 import (
+	"sync"
+
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
 	"github.com/DataDog/datadog-agent/pkg/util/alibaba"
 	"github.com/DataDog/datadog-agent/pkg/util/azure"
@@ -17,6 +19,11 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/tencent"
 )
 
+var (
+	detectedCloudProvider     string
+	detectedCloudProviderLock sync.RWMutex
+)
+
 type cloudProviderDetector struct {
 	name     string
 	callback func() bool
@@ -42,9 +49,20 @@ func DetectCloudProvider() {
 	for _, cloudDetector := range detectors {
 		if cloudDetector.callback() {
 			inventories.SetAgentMetadata(inventories.CloudProviderMetatadaName, cloudDetector.name)
+			detectedCloudProviderLock.Lock()
+			detectedCloudProvider = cloudDetector.name
+			detectedCloudProviderLock.Unlock()
 			log.Infof("Cloud provider %s detected", cloudDetector.name)
 			return
 		}
 	}
 	log.Info("No cloud provider detected")
 }
+
+// GetDetectedCloudProvider returns the cloud provider found by DetectCloudProvider,
+// an empty string if none was detected
+func GetDetectedCloudProvider() string {
+	detectedCloudProviderLock.RLock()
+	defer detectedCloudProviderLock.RUnlock()
+	return detectedCloudProvider
+}
@@ -17,6 +17,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/DataDog/datadog-agent/pkg/api/debugport"
 	"github.com/DataDog/datadog-agent/pkg/autodiscovery/integration"
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/version"
@@ -181,8 +182,9 @@ func GetGoRoutinesDump() (string, error) {
 		return "", err
 	}
 
-	pprofURL := fmt.Sprintf("http://%v:%s/debug/pprof/goroutine?debug=2",
-		ipcAddress, config.Datadog.GetString("expvar_port"))
+	// the agent advertises its expvar port, which differs from `expvar_port` when that one was in use
+	port := debugport.Lookup(debugport.Agent, debugport.Expvar, config.Datadog.GetInt("expvar_port"))
+	pprofURL := fmt.Sprintf("http://%v:%d/debug/pprof/goroutine?debug=2", ipcAddress, port)
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 	client := http.Client{}
@@ -12,11 +12,14 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Containerd availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("Containerd availability", diagnose)
 }
 
 // diagnose the Containerd socket connectivity
 func diagnose() error {
 	_, err := GetContainerdUtil()
+	if diagnosis.IsPermissionDenied(err) {
+		return diagnosis.NewMissingCredentialsError("access to the containerd socket set in cri_socket_path", err)
+	}
 	return err
 }
@@ -49,6 +49,19 @@ type Filter struct {
 
 var sharedFilter *Filter
 
+// pauseContainerPatterns match the images of the pause, or infra, containers of the orchestrators
+var pauseContainerPatterns = []string{
+	pauseContainerGCR,
+	pauseContainerOpenshift3,
+	pauseContainerKubernetes,
+	pauseContainerAzure,
+	pauseContainerECS,
+	pauseContainerEKS,
+	pauseContainerRancher,
+	pauseContainerAKS,
+	pauseContainerECR,
+}
+
 func parseFilters(filters []string) (imageFilters, nameFilters, namespaceFilters []*regexp.Regexp, err error) {
 	for _, filter := range filters {
 		switch {
@@ -138,21 +151,17 @@ func NewFilterFromConfig() (*Filter, error) {
 	}
 
 	if config.Datadog.GetBool("exclude_pause_container") {
-		blacklist = append(blacklist,
-			pauseContainerGCR,
-			pauseContainerOpenshift3,
-			pauseContainerKubernetes,
-			pauseContainerAzure,
-			pauseContainerECS,
-			pauseContainerEKS,
-			pauseContainerRancher,
-			pauseContainerAKS,
-			pauseContainerECR,
-		)
+		blacklist = append(blacklist, pauseContainerPatterns...)
 	}
 	return NewFilter(whitelist, blacklist)
 }
 
+// NewPauseContainerFilter creates a new container filter excluding only the pause containers,
+// whatever the exclude_pause_container option
+func NewPauseContainerFilter() (*Filter, error) {
+	return NewFilter(nil, pauseContainerPatterns)
+}
+
 // NewAutodiscoveryFilter creates a new container filter for Autodiscovery
 // It sources patterns from the pkg/config options but ignores the exclude_pause_container options
 // It allows to filter metrics and logs separately
@@ -287,6 +287,17 @@ func TestNewFilterFromConfig(t *testing.T) {
 	config.Datadog.SetDefault("ac_exclude", []string{})
 }
 
+func TestNewPauseContainerFilter(t *testing.T) {
+	config.Datadog.SetDefault("exclude_pause_container", false)
+	defer config.Datadog.SetDefault("exclude_pause_container", true)
+
+	f, err := NewPauseContainerFilter()
+	require.NoError(t, err)
+	assert.True(t, f.IsExcluded("dummy", "k8s.gcr.io/pause-amd64:3.1", ""))
+	assert.True(t, f.IsExcluded("dummy", "amazon/amazon-ecs-pause:0.1.0", ""))
+	assert.False(t, f.IsExcluded("dummy", "redis:latest", ""))
+}
+
 func TestNewAutodiscoveryFilter(t *testing.T) {
 	resetConfig()
 
@@ -47,8 +47,25 @@ func (c ContainerCgroup) ContainerStartTime() (int64, error) {
 	return stat.ModTime().Unix(), nil
 }
 
+// isCgroupV2 returns whether the controller of the target is on the cgroup v2 unified hierarchy,
+// which is the case when it isn't mounted as a cgroup v1 controller
+func (c ContainerCgroup) isCgroupV2(target string) bool {
+	if _, ok := c.Mounts[target]; ok {
+		if _, ok := c.Paths[target]; ok {
+			return false
+		}
+	}
+	_, mounted := c.Mounts[unifiedHierarchy]
+	_, found := c.Paths[unifiedHierarchy]
+	return mounted && found
+}
+
 // cgroupFilePath constructs file path to get targeted stats file.
+// The targets not mounted as cgroup v1 controllers are looked up in the cgroup v2 hierarchy.
 func (c ContainerCgroup) cgroupFilePath(target, file string) string {
+	if c.isCgroupV2(target) {
+		target = unifiedHierarchy
+	}
 	mount, ok := c.Mounts[target]
 	if !ok {
 		log.Debugf("Missing target %s from mounts", target)
@@ -96,7 +113,11 @@ func (c ContainerCgroup) cgroupParentFilePath(target, file string) string {
 //	 cgroup /sys/fs/cgroup/perf_event cgroup rw,relatime,perf_event 0 0
 //	 cgroup /sys/fs/cgroup/hugetlb cgroup rw,relatime,hugetlb 0 0
 //
-// Returns a map for every target (cpuset, cpu, cpuacct) => path
+// On hosts using cgroup v2, the unified hierarchy is mounted with the cgroup2 type, like
+//	 cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime 0 0
+//
+// Returns a map for every target (cpuset, cpu, cpuacct) => path, the unified hierarchy
+// being mapped to the unifiedHierarchy target
 func cgroupMountPoints() (map[string]string, error) {
 	mountsFile := "/proc/mounts"
 	if !pathExists(mountsFile) {
@@ -132,6 +153,13 @@ func parseCgroupMountPoints(r io.Reader) map[string]string {
 				mountPoints[target] = cgroupPath
 			}
 		}
+		if len(tokens) >= 3 && tokens[2] == "cgroup2" {
+			cgroupPath := tokens[1]
+			if !strings.HasPrefix(cgroupPath+"/", cgroupRoot) {
+				continue
+			}
+			mountPoints[unifiedHierarchy] = cgroupPath
+		}
 	}
 	if len(mountPoints) == 0 {
 		log.Warnf("No mountPoints were detected, current cgroup root is: %s", cgroupRoot)
@@ -214,6 +242,11 @@ func readCgroupsForPath(pidCgroupPath, prefix string) (string, map[string]string
 // 8:memory:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 // 7:blkio:/kubepods/besteffort/pod2baa3444-4d37-11e7-bd2f-080027d2bf10/47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e
 //
+// On hosts using cgroup v2, the unified hierarchy has no controller list, its path is stored
+// for the unifiedHierarchy target:
+//
+// 0::/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope
+//
 // Returns the common containerID and a mapping of target => path
 // If any line doesn't have a valid container ID we will return an empty string and an empty slice of paths
 func parseCgroupPaths(r io.Reader, prefix string) (string, map[string]string, error) {
@@ -126,6 +126,51 @@ func TestParseCgroupMountPoints(t *testing.T) {
 	}
 }
 
+func TestParseCgroupMountPointsV2(t *testing.T) {
+	for _, tc := range []struct {
+		contents []string
+		expected map[string]string
+	}{
+		{
+			// unified hierarchy only
+			contents: []string{
+				"sysfs /sys sysfs rw,nosuid,nodev,noexec,relatime 0 0",
+				"cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0",
+			},
+			expected: map[string]string{
+				unifiedHierarchy: "/sys/fs/cgroup",
+			},
+		},
+		{
+			// hybrid hierarchy, the controllers are on cgroup v1
+			contents: []string{
+				"cgroup2 /sys/fs/cgroup/unified cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0",
+				"cgroup /sys/fs/cgroup/memory cgroup rw,nosuid,nodev,noexec,relatime,memory 0 0",
+				"cgroup /sys/fs/cgroup/cpu,cpuacct cgroup rw,nosuid,nodev,noexec,relatime,cpu,cpuacct 0 0",
+			},
+			expected: map[string]string{
+				unifiedHierarchy: "/sys/fs/cgroup/unified",
+				"memory":         "/sys/fs/cgroup/memory",
+				"cpu":            "/sys/fs/cgroup/cpu,cpuacct",
+				"cpuacct":        "/sys/fs/cgroup/cpu,cpuacct",
+			},
+		},
+	} {
+		contents := strings.NewReader(strings.Join(tc.contents, "\n"))
+		assert.Equal(t, tc.expected, parseCgroupMountPoints(contents))
+	}
+}
+
+func TestParseCgroupPathsV2(t *testing.T) {
+	contents := strings.NewReader("0::/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope")
+	c, p, err := parseCgroupPaths(contents, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e", c)
+	assert.Equal(t, map[string]string{
+		unifiedHierarchy: "/system.slice/docker-47fc31db38b4fa0f4db44b99d0cad10e3cd4d5f142135a7721c1c95c1aadfb2e.scope",
+	}, p)
+}
+
 func TestParseCgroupPaths(t *testing.T) {
 	for _, tc := range []struct {
 		contents          []string
@@ -33,6 +33,9 @@ var (
 // Mem returns the memory statistics for a Cgroup. If the cgroup file is not
 // available then we return an empty stats file.
 func (c ContainerCgroup) Mem() (*metrics.ContainerMemStats, error) {
+	if c.isCgroupV2("memory") {
+		return c.memV2()
+	}
 	ret := &metrics.ContainerMemStats{}
 	statfile := c.cgroupFilePath("memory", "memory.stat")
 
@@ -122,6 +125,9 @@ func (c ContainerCgroup) Mem() (*metrics.ContainerMemStats, error) {
 // MemLimit returns the memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) MemLimit() (uint64, error) {
+	if c.isCgroupV2("memory") {
+		return c.parseLimitV2("memory", "memory.max")
+	}
 	v, err := c.ParseSingleStat("memory", "memory.limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -141,6 +147,9 @@ func (c ContainerCgroup) MemLimit() (uint64, error) {
 // FailedMemoryCount returns the number of times this cgroup reached its memory limit, if it exists.
 // If the file does not exist or there is no limit, then this will default to 0
 func (c ContainerCgroup) FailedMemoryCount() (uint64, error) {
+	if c.isCgroupV2("memory") {
+		return c.failedMemoryCountV2()
+	}
 	v, err := c.ParseSingleStat("memory", "memory.failcnt")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -155,6 +164,9 @@ func (c ContainerCgroup) FailedMemoryCount() (uint64, error) {
 // KernelMemoryUsage returns the number of bytes of kernel memory used by this cgroup, if it exists.
 // If the file does not exist or there is an error, then this will default to 0
 func (c ContainerCgroup) KernelMemoryUsage() (uint64, error) {
+	if c.isCgroupV2("memory") {
+		return c.kernelMemoryUsageV2()
+	}
 	v, err := c.ParseSingleStat("memory", "memory.kmem.usage_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -169,6 +181,10 @@ func (c ContainerCgroup) KernelMemoryUsage() (uint64, error) {
 // SoftMemLimit returns the soft memory limit of the cgroup, if it exists. If the file does not
 // exist or there is no limit then this will default to 0.
 func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
+	if c.isCgroupV2("memory") {
+		// the memory reservation of the containers is set as memory.low by the runtimes
+		return c.parseLimitV2("memory", "memory.low")
+	}
 	v, err := c.ParseSingleStat("memory", "memory.soft_limit_in_bytes")
 	if os.IsNotExist(err) {
 		log.Debugf("Missing cgroup file: %s",
@@ -188,6 +204,9 @@ func (c ContainerCgroup) SoftMemLimit() (uint64, error) {
 // CPU returns the CPU status for this cgroup instance
 // If the cgroup file does not exist then we just log debug return nothing.
 func (c ContainerCgroup) CPU() (*metrics.ContainerCPUStats, error) {
+	if c.isCgroupV2("cpu") {
+		return c.cpuV2()
+	}
 	ret := &metrics.ContainerCPUStats{}
 	statfile := c.cgroupFilePath("cpuacct", "cpuacct.stat")
 	f, err := os.Open(statfile)
@@ -240,6 +259,9 @@ func (c ContainerCgroup) CPU() (*metrics.ContainerCPUStats, error) {
 // throttle/limited because of CPU quota / limit
 // If the cgroup file does not exist then we just log debug and return 0.
 func (c ContainerCgroup) CPUPeriods() (throttledNr uint64, throttledTime float64, err error) {
+	if c.isCgroupV2("cpu") {
+		return c.cpuPeriodsV2()
+	}
 	statfile := c.cgroupFilePath("cpu", "cpu.stat")
 	f, err := os.Open(statfile)
 	if os.IsNotExist(err) {
@@ -285,6 +307,9 @@ func (c ContainerCgroup) CPUPeriods() (throttledNr uint64, throttledTime float64
 // If the limits files aren't available (on older version) then
 // we'll return the default value of numCPU * 100.
 func (c ContainerCgroup) CPULimit() (float64, error) {
+	if c.isCgroupV2("cpu") {
+		return c.cpuLimitV2()
+	}
 	limit := numCPU * 100.0
 
 	periodFile := c.cgroupFilePath("cpu", "cpu.cfs_period_us")
@@ -359,6 +384,9 @@ func (c ContainerCgroup) CPULimit() (float64, error) {
 // 252:0 Total 58945536
 //
 func (c ContainerCgroup) IO() (*metrics.ContainerIOStats, error) {
+	if c.isCgroupV2("blkio") {
+		return c.ioV2()
+	}
 	ret := &metrics.ContainerIOStats{
 		DeviceReadBytes:  make(map[string]uint64),
 		DeviceWriteBytes: make(map[string]uint64),
@@ -413,6 +441,13 @@ func (c ContainerCgroup) IO() (*metrics.ContainerIOStats, error) {
 		return ret, fmt.Errorf("error reading %s: %s", statfile, err)
 	}
 
+	ret.OpenFiles = c.openFilesCount()
+
+	return ret, nil
+}
+
+// openFilesCount returns the number of file descriptors opened by the processes of the cgroup
+func (c ContainerCgroup) openFilesCount() uint64 {
 	var fileDescCount uint64
 	for _, pid := range c.Pids {
 		fdCount, err := GetFileDescriptorLen(int(pid))
@@ -422,9 +457,7 @@ func (c ContainerCgroup) IO() (*metrics.ContainerIOStats, error) {
 		}
 		fileDescCount += uint64(fdCount)
 	}
-	ret.OpenFiles = fileDescCount
-
-	return ret, nil
+	return fileDescCount
 }
 
 // ThreadCount returns the number of threads in the pid cgroup
@@ -0,0 +1,306 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package cgroup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/containers/metrics"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// The cgroup v2 files report the same statistics as the cgroup v1 ones with different names
+// and units, they are converted so that the metrics are the same on both versions.
+// ref: https://www.kernel.org/doc/Documentation/cgroup-v2.txt
+
+// microToUserHZDivisor converts the microseconds of the cgroup v2 cpu.stat to USER_HZ
+const microToUserHZDivisor float64 = 1e6 / 100
+
+// readStatFileV2 reads a flat keyed file, like memory.stat or cpu.stat, returning nil
+// when the file doesn't exist
+func (c ContainerCgroup) readStatFileV2(file string) (map[string]uint64, error) {
+	statfile := c.cgroupFilePath(unifiedHierarchy, file)
+	f, err := os.Open(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	stats := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		stats[fields[0]] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return stats, fmt.Errorf("error reading %s: %s", statfile, err)
+	}
+	return stats, nil
+}
+
+// parseLimitV2 reads a limit file like memory.max, whose value is "max" when there is no limit.
+// If the file does not exist or there is no limit then this will default to 0.
+func (c ContainerCgroup) parseLimitV2(target, file string) (uint64, error) {
+	statFile := c.cgroupFilePath(target, file)
+	lines, err := readLines(statFile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statFile)
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if len(lines) != 1 {
+		return 0, fmt.Errorf("wrong file format: %s", statFile)
+	}
+	if lines[0] == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(lines[0], 10, 64)
+}
+
+// memV2 returns the memory statistics of memory.stat, whose anon and file entries are the
+// rss and cache of cgroup v1. The cgroup v2 stats are always hierarchical, they are reported
+// as the total ones as well.
+func (c ContainerCgroup) memV2() (*metrics.ContainerMemStats, error) {
+	ret := &metrics.ContainerMemStats{}
+	stats, err := c.readStatFileV2("memory.stat")
+	if err != nil {
+		return nil, err
+	}
+	ret.RSS = stats["anon"]
+	ret.Cache = stats["file"]
+	ret.RSSHuge = stats["anon_thp"]
+	ret.MappedFile = stats["file_mapped"]
+	ret.Pgfault = stats["pgfault"]
+	ret.Pgmajfault = stats["pgmajfault"]
+	ret.InactiveAnon = stats["inactive_anon"]
+	ret.ActiveAnon = stats["active_anon"]
+	ret.InactiveFile = stats["inactive_file"]
+	ret.ActiveFile = stats["active_file"]
+	ret.Unevictable = stats["unevictable"]
+
+	ret.TotalRSS = ret.RSS
+	ret.TotalCache = ret.Cache
+	ret.TotalRSSHuge = ret.RSSHuge
+	ret.TotalMappedFile = ret.MappedFile
+	ret.TotalPgFault = ret.Pgfault
+	ret.TotalPgMajFault = ret.Pgmajfault
+	ret.TotalInactiveAnon = ret.InactiveAnon
+	ret.TotalActiveAnon = ret.ActiveAnon
+	ret.TotalInactiveFile = ret.InactiveFile
+	ret.TotalActiveFile = ret.ActiveFile
+	ret.TotalUnevictable = ret.Unevictable
+
+	if usage, err := c.ParseSingleStat("memory", "memory.current"); err == nil {
+		ret.MemUsageInBytes = usage
+	} else {
+		log.Debugf("Missing memory usage stat for %s: %s", c.ContainerID, err)
+	}
+	if swap, err := c.ParseSingleStat("memory", "memory.swap.current"); err == nil {
+		ret.Swap = swap
+		ret.SwapPresent = true
+	}
+	if limit, err := c.parseLimitV2("memory", "memory.max"); err == nil {
+		ret.HierarchicalMemoryLimit = limit
+	}
+	if swapLimit, err := c.parseLimitV2("memory", "memory.swap.max"); err == nil && swapLimit > 0 {
+		// memsw_limit of cgroup v1 includes the memory
+		ret.HierarchicalMemSWLimit = ret.HierarchicalMemoryLimit + swapLimit
+	}
+	return ret, nil
+}
+
+// failedMemoryCountV2 returns the number of times the cgroup reached its memory limit, from memory.events
+func (c ContainerCgroup) failedMemoryCountV2() (uint64, error) {
+	stats, err := c.readStatFileV2("memory.events")
+	if err != nil {
+		return 0, err
+	}
+	return stats["max"], nil
+}
+
+// kernelMemoryUsageV2 returns the kernel memory of memory.stat, the kernel stacks and the slab
+func (c ContainerCgroup) kernelMemoryUsageV2() (uint64, error) {
+	stats, err := c.readStatFileV2("memory.stat")
+	if err != nil {
+		return 0, err
+	}
+	return stats["kernel_stack"] + stats["slab"], nil
+}
+
+// cpuV2 returns the CPU times of cpu.stat, in USER_HZ like the cgroup v1 ones
+func (c ContainerCgroup) cpuV2() (*metrics.ContainerCPUStats, error) {
+	ret := &metrics.ContainerCPUStats{}
+	stats, err := c.readStatFileV2("cpu.stat")
+	if err != nil {
+		return nil, err
+	}
+	ret.Timestsamp = time.Now()
+	if stats == nil {
+		return ret, nil
+	}
+	ret.User = uint64(float64(stats["user_usec"]) / microToUserHZDivisor)
+	ret.System = uint64(float64(stats["system_usec"]) / microToUserHZDivisor)
+	ret.UsageTotal = float64(stats["usage_usec"]) / microToUserHZDivisor
+
+	// cpu.weight ranges from 1 to 10000, it is converted back to the cpu.shares set
+	// by the runtimes, which range from 2 to 262144
+	weight, err := c.ParseSingleStat("cpu", "cpu.weight")
+	if err == nil && weight > 0 {
+		ret.Shares = 2 + (weight-1)*262142/9999
+	} else {
+		log.Debugf("Missing cpu weight stat for %s: %v", c.ContainerID, err)
+	}
+
+	return ret, nil
+}
+
+// cpuPeriodsV2 returns the number of times the cgroup has been throttled, and for how long
+func (c ContainerCgroup) cpuPeriodsV2() (uint64, float64, error) {
+	stats, err := c.readStatFileV2("cpu.stat")
+	if err != nil {
+		return 0, 0, err
+	}
+	return stats["nr_throttled"], float64(stats["throttled_usec"]) / microToUserHZDivisor, nil
+}
+
+// cpuLimitV2 returns the CPU limit of cpu.max, which holds the quota and the period,
+// the quota being "max" when there is no limit. Like cgroup v1, the limit of the parent
+// is used when the cgroup has none.
+func (c ContainerCgroup) cpuLimitV2() (float64, error) {
+	limit := numCPU * 100.0
+
+	quota, period, err := parseCPUMax(c.cgroupFilePath("cpu", "cpu.max"))
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", c.cgroupFilePath("cpu", "cpu.max"))
+		return limit, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if quota == -1 {
+		// We ignore failures as we already have current cgroup values
+		if parentQuota, parentPeriod, err := parseCPUMax(c.cgroupParentFilePath("cpu", "cpu.max")); err == nil {
+			quota, period = parentQuota, parentPeriod
+		}
+	}
+
+	if (period > 0) && (quota > 0) {
+		limit = quota / period * 100.0
+	}
+	return limit, nil
+}
+
+// parseCPUMax parses a cpu.max file, the quota is -1 when it is "max"
+func parseCPUMax(file string) (quota float64, period float64, err error) {
+	lines, err := readLines(file)
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(lines) != 1 {
+		return 0, 0, fmt.Errorf("wrong file format: %s", file)
+	}
+	fields := strings.Fields(lines[0])
+	if len(fields) != 2 {
+		return 0, 0, fmt.Errorf("wrong file format: %s", file)
+	}
+	period, err = strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if fields[0] == "max" {
+		return -1, period, nil
+	}
+	quota, err = strconv.ParseFloat(fields[0], 64)
+	return quota, period, err
+}
+
+// ioV2 returns the disk read and write bytes of io.stat
+// Format:
+//
+// 8:0 rbytes=49225728 wbytes=9850880 rios=1204 wios=240 dbytes=0 dios=0
+// 252:0 rbytes=49094656 wbytes=9850880 rios=1198 wios=240 dbytes=0 dios=0
+//
+func (c ContainerCgroup) ioV2() (*metrics.ContainerIOStats, error) {
+	ret := &metrics.ContainerIOStats{
+		DeviceReadBytes:  make(map[string]uint64),
+		DeviceWriteBytes: make(map[string]uint64),
+	}
+
+	statfile := c.cgroupFilePath("blkio", "io.stat")
+	f, err := os.Open(statfile)
+	if os.IsNotExist(err) {
+		log.Debugf("Missing cgroup file: %s", statfile)
+		return ret, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Get device id->name mapping
+	var devices map[string]string
+	mapping, err := getDiskDeviceMapping()
+	if err != nil {
+		log.Debugf("Cannot get per-device stats: %s", err)
+		// devices will stay nil, lookups are safe in nil maps
+	} else {
+		devices = mapping.idToName
+	}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		deviceName := devices[fields[0]]
+		for _, field := range fields[1:] {
+			kv := strings.SplitN(field, "=", 2)
+			if len(kv) != 2 {
+				continue
+			}
+			v, err := strconv.ParseUint(kv[1], 10, 64)
+			if err != nil {
+				continue
+			}
+			switch kv[0] {
+			case "rbytes":
+				ret.ReadBytes += v
+				if deviceName != "" {
+					ret.DeviceReadBytes[deviceName] = v
+				}
+			case "wbytes":
+				ret.WriteBytes += v
+				if deviceName != "" {
+					ret.DeviceWriteBytes[deviceName] = v
+				}
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ret, fmt.Errorf("error reading %s: %s", statfile, err)
+	}
+
+	ret.OpenFiles = c.openFilesCount()
+
+	return ret, nil
+}
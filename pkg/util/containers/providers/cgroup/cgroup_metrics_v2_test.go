@@ -0,0 +1,168 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build linux
+
+package cgroup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newDummyContainerCgroupV2 returns a cgroup on the unified hierarchy, in the container
+// folder of the root path
+func newDummyContainerCgroupV2(rootPath string) *ContainerCgroup {
+	return &ContainerCgroup{
+		ContainerID: "dummy",
+		Mounts:      map[string]string{unifiedHierarchy: rootPath},
+		Paths:       map[string]string{unifiedHierarchy: "container"},
+	}
+}
+
+func TestCgroupV2Detection(t *testing.T) {
+	assert.True(t, newDummyContainerCgroupV2("/sys/fs/cgroup").isCgroupV2("memory"))
+	assert.False(t, newDummyContainerCgroup("/sys/fs/cgroup", "memory").isCgroupV2("memory"))
+
+	// hybrid hierarchy, the controllers are on cgroup v1
+	hybrid := newDummyContainerCgroup("/sys/fs/cgroup", "memory")
+	hybrid.Mounts[unifiedHierarchy] = "/sys/fs/cgroup/unified"
+	hybrid.Paths[unifiedHierarchy] = "container"
+	assert.False(t, hybrid.isCgroupV2("memory"))
+}
+
+func TestMemV2(t *testing.T) {
+	tempFolder, err := newTempFolder("mem-stats-v2")
+	require.NoError(t, err)
+	defer tempFolder.removeAll()
+
+	memoryStats := dummyCgroupStat{
+		"anon":          1024,
+		"file":          2048,
+		"file_mapped":   512,
+		"pgfault":       10,
+		"pgmajfault":    2,
+		"inactive_file": 1000,
+		"kernel_stack":  64,
+		"slab":          128,
+	}
+	tempFolder.add("container/memory.stat", memoryStats.String())
+	tempFolder.add("container/memory.current", "4096")
+	tempFolder.add("container/memory.swap.current", "256")
+	tempFolder.add("container/memory.max", "8192")
+	tempFolder.add("container/memory.low", "max")
+	tempFolder.add("container/memory.events", "low 0\nhigh 0\nmax 3\noom 1\noom_kill 1")
+
+	cgroup := newDummyContainerCgroupV2(tempFolder.RootPath)
+
+	mem, err := cgroup.Mem()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1024), mem.RSS)
+	assert.Equal(t, uint64(1024), mem.TotalRSS)
+	assert.Equal(t, uint64(2048), mem.Cache)
+	assert.Equal(t, uint64(512), mem.MappedFile)
+	assert.Equal(t, uint64(2), mem.Pgmajfault)
+	assert.Equal(t, uint64(1000), mem.InactiveFile)
+	assert.Equal(t, uint64(4096), mem.MemUsageInBytes)
+	assert.Equal(t, uint64(256), mem.Swap)
+	assert.True(t, mem.SwapPresent)
+	assert.Equal(t, uint64(8192), mem.HierarchicalMemoryLimit)
+
+	limit, err := cgroup.MemLimit()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(8192), limit)
+
+	softLimit, err := cgroup.SoftMemLimit()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), softLimit)
+
+	failed, err := cgroup.FailedMemoryCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3), failed)
+
+	kmem, err := cgroup.KernelMemoryUsage()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(192), kmem)
+}
+
+func TestCPUV2(t *testing.T) {
+	tempFolder, err := newTempFolder("cpu-stats-v2")
+	require.NoError(t, err)
+	defer tempFolder.removeAll()
+
+	cpuStats := dummyCgroupStat{
+		"usage_usec":     9152664182,
+		"user_usec":      641400000,
+		"system_usec":    183270000,
+		"nr_periods":     20,
+		"nr_throttled":   10,
+		"throttled_usec": 183270,
+	}
+	tempFolder.add("container/cpu.stat", cpuStats.String())
+	tempFolder.add("container/cpu.weight", "100")
+	tempFolder.add("container/cpu.max", "50000 100000")
+
+	cgroup := newDummyContainerCgroupV2(tempFolder.RootPath)
+
+	cpu, err := cgroup.CPU()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(64140), cpu.User)
+	assert.Equal(t, uint64(18327), cpu.System)
+	assert.InDelta(t, 915266.4182, cpu.UsageTotal, 0.0000001)
+	// the weight is converted back to cpu shares
+	assert.Equal(t, uint64(2+99*262142/9999), cpu.Shares)
+
+	throttled, throttledTime, err := cgroup.CPUPeriods()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10), throttled)
+	assert.InDelta(t, 18.327, throttledTime, 0.0000001)
+
+	limit, err := cgroup.CPULimit()
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, limit)
+
+	// no limit on the container, the one of the parent is used
+	tempFolder.add("container/cpu.max", "max 100000")
+	tempFolder.add("cpu.max", "200000 100000")
+	limit, err = cgroup.CPULimit()
+	require.NoError(t, err)
+	assert.Equal(t, 200.0, limit)
+}
+
+func TestThreadsV2(t *testing.T) {
+	tempFolder, err := newTempFolder("pids-v2")
+	require.NoError(t, err)
+	defer tempFolder.removeAll()
+
+	tempFolder.add("container/pids.current", "12")
+	tempFolder.add("container/pids.max", "max")
+
+	cgroup := newDummyContainerCgroupV2(tempFolder.RootPath)
+
+	count, err := cgroup.ThreadCount()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(12), count)
+
+	limit, err := cgroup.ThreadLimit()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(0), limit)
+}
+
+func TestIOV2(t *testing.T) {
+	tempFolder, err := newTempFolder("io-v2")
+	require.NoError(t, err)
+	defer tempFolder.removeAll()
+
+	tempFolder.add("container/io.stat", "8:0 rbytes=1024 wbytes=2048 rios=1 wios=2 dbytes=0 dios=0\n252:0 rbytes=10 wbytes=20 rios=1 wios=2 dbytes=0 dios=0")
+
+	cgroup := newDummyContainerCgroupV2(tempFolder.RootPath)
+
+	io, err := cgroup.IO()
+	require.NoError(t, err)
+	assert.Equal(t, uint64(1034), io.ReadBytes)
+	assert.Equal(t, uint64(2068), io.WriteBytes)
+}
@@ -15,6 +15,10 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 )
 
+// unifiedHierarchy is the target of the cgroup v2 unified hierarchy in the mounts and paths,
+// its controllers are listed with an empty name in /proc/$pid/cgroup
+const unifiedHierarchy = ""
+
 // ContainerCgroup is a structure that stores paths and mounts for a cgroup.
 // It provides several methods for collecting stats about the cgroup using the
 // paths and mounts metadata.
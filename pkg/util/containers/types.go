@@ -75,6 +75,9 @@ type Container struct {
 	Excluded    bool
 	AddressList []NetworkAddress
 	StartedAt   int64
+	// RestartCount and Owners are only set when the orchestrator knows them
+	RestartCount int
+	Owners       []ContainerOwner
 
 	metrics.ContainerMetrics
 	Limits  metrics.ContainerLimits
@@ -88,6 +91,13 @@ type NetworkAddress struct {
 	Protocol string
 }
 
+// ContainerOwner is a reference to the object managing a container, like the
+// ReplicaSet or the DaemonSet of its pod
+type ContainerOwner struct {
+	Kind string
+	Name string
+}
+
 // NetworkDestination holds one network destination subnet and it's linked interface name
 type NetworkDestination struct {
 	Interface string
@@ -13,7 +13,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Docker availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("Docker availability", diagnose)
 }
 
 // diagnose the docker availability on the system
@@ -21,6 +21,9 @@ func diagnose() error {
 	_, err := GetDockerUtil()
 	if err != nil {
 		log.Error(err)
+		if diagnosis.IsPermissionDenied(err) {
+			return diagnosis.NewMissingCredentialsError("access to the docker socket, add the agent user to the docker group", err)
+		}
 	} else {
 		log.Info("successfully connected to docker")
 	}
@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("EC2 Metadata availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("EC2 Metadata availability", diagnose)
 }
 
 // diagnose the ec2 metadata API availability
@@ -19,6 +19,9 @@ func diagnose() error {
 	_, err := GetHostname()
 	if err != nil {
 		log.Error(err)
+		if diagnosis.IsUnauthorized(err) {
+			return diagnosis.NewMissingCredentialsError("an IMDSv2 session token, check the hop limit of the instance metadata options", err)
+		}
 	}
 	return err
 }
@@ -10,7 +10,9 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -28,8 +30,84 @@ var (
 
 	// CloudProviderName contains the inventory name of for EC2
 	CloudProviderName = "AWS"
+
+	// tokenRenewalWindow is the delay before the expiration of the IMDSv2 token when a new one is requested
+	tokenRenewalWindow = 15 * time.Second
+	// unreachableCacheDuration is the delay during which the requests to an unreachable metadata endpoint
+	// fail without waiting for the timeout, when the agent is not running on EC2
+	unreachableCacheDuration = 5 * time.Minute
+
+	token       = &ec2Token{}
+	unreachable = &unreachableHosts{hosts: make(map[string]time.Time)}
 )
 
+// ec2Token caches the IMDSv2 session token
+type ec2Token struct {
+	sync.Mutex
+	value          string
+	expirationDate time.Time
+}
+
+// get returns the cached token, it requests a new token when the cached one is about to expire
+func (t *ec2Token) get() (string, error) {
+	t.Lock()
+	defer t.Unlock()
+
+	if t.value != "" && time.Now().Before(t.expirationDate.Add(-tokenRenewalWindow)) {
+		return t.value, nil
+	}
+
+	lifetime := time.Duration(config.Datadog.GetInt("ec2_metadata_token_lifetime")) * time.Second
+	value, err := getToken(lifetime)
+	if err != nil {
+		return "", err
+	}
+	t.value = value
+	t.expirationDate = time.Now().Add(lifetime)
+	return t.value, nil
+}
+
+// cached returns the cached token if it is still valid, an empty string otherwise
+func (t *ec2Token) cached() string {
+	t.Lock()
+	defer t.Unlock()
+
+	if time.Now().Before(t.expirationDate.Add(-tokenRenewalWindow)) {
+		return t.value
+	}
+	return ""
+}
+
+func (t *ec2Token) invalidate() {
+	t.Lock()
+	defer t.Unlock()
+	t.value = ""
+	t.expirationDate = time.Time{}
+}
+
+// unreachableHosts remembers the metadata hosts which could not be reached
+type unreachableHosts struct {
+	sync.Mutex
+	hosts map[string]time.Time
+}
+
+func (u *unreachableHosts) isUnreachable(host string) bool {
+	u.Lock()
+	defer u.Unlock()
+	until, ok := u.hosts[host]
+	if ok && time.Now().After(until) {
+		delete(u.hosts, host)
+		return false
+	}
+	return ok
+}
+
+func (u *unreachableHosts) markUnreachable(host string) {
+	u.Lock()
+	defer u.Unlock()
+	u.hosts[host] = time.Now().Add(unreachableCacheDuration)
+}
+
 // GetInstanceID fetches the instance id for current host from the EC2 metadata API
 func GetInstanceID() (string, error) {
 	if !config.IsCloudProviderEnabled(CloudProviderName) {
@@ -173,27 +251,48 @@ func doHTTPRequest(url string, method string, headers map[string]string, retriab
 		req.Header.Add(header, value)
 	}
 
+	if unreachable.isUnreachable(req.URL.Host) {
+		return nil, fmt.Errorf("the metadata endpoint %s was recently unreachable", req.URL.Host)
+	}
+
+	// IMDSv2: use the session token once one was needed, or right away if preferred
+	if req.Header.Get("X-aws-ec2-metadata-token") == "" && retriableWithFreshToken {
+		if value := token.cached(); value != "" {
+			req.Header.Set("X-aws-ec2-metadata-token", value)
+		} else if config.Datadog.GetBool("ec2_prefer_imdsv2") {
+			if value, err := token.get(); err == nil {
+				req.Header.Set("X-aws-ec2-metadata-token", value)
+			} else {
+				log.Debugf("unable to get an IMDSv2 token, falling back to IMDSv1: %s", err)
+			}
+		}
+	}
+
 	res, err := client.Do(req)
 	if err != nil {
+		unreachable.markUnreachable(req.URL.Host)
 		return nil, err
 	}
 	if res.StatusCode == 401 && retriableWithFreshToken {
+		res.Body.Close()
 		// Most of 401 errors can be solved by retrying with a fresh token
-		token, err := getToken()
+		token.invalidate()
+		value, err := token.get()
 		if err != nil {
 			return nil, err
 		}
-		headers["X-aws-ec2-metadata-token"] = token
+		headers["X-aws-ec2-metadata-token"] = value
 		return doHTTPRequest(url, method, headers, false)
 
 	} else if res.StatusCode != 200 {
+		res.Body.Close()
 		return nil, fmt.Errorf("status code %d trying to fetch %s", res.StatusCode, url)
 	}
 
 	return res, nil
 }
 
-func getToken() (string, error) {
+func getToken(lifetime time.Duration) (string, error) {
 	client := http.Client{
 		Timeout: timeout,
 	}
@@ -203,7 +302,7 @@ func getToken() (string, error) {
 		return "", err
 	}
 
-	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	req.Header.Add("X-aws-ec2-metadata-token-ttl-seconds", strconv.Itoa(int(lifetime.Seconds())))
 	res, err := client.Do(req)
 	if err != nil {
 		return "", err
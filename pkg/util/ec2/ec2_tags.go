@@ -12,11 +12,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/ec2"
@@ -29,6 +31,57 @@ var (
 )
 
 func fetchEc2Tags() ([]string, error) {
+	tags, err := fetchEc2TagsFromAPI()
+	if err == nil {
+		return tags, nil
+	}
+
+	// the instance may have no IAM role or a role without the ec2:DescribeTags permission,
+	// the tags can also be exposed by the instance metadata endpoint
+	metadataTags, metadataErr := fetchEc2TagsFromMetadata()
+	if metadataErr != nil {
+		if isPermissionError(err) {
+			log.Warnf("the instance IAM role is missing the ec2:DescribeTags permission, " +
+				"grant it or allow the tags in the instance metadata to collect the EC2 tags")
+		}
+		return nil, fmt.Errorf("%s, and unable to get the tags from the instance metadata: %s", err, metadataErr)
+	}
+	log.Debugf("unable to get tags from the EC2 API, using the instance metadata tags: %s", err)
+	return metadataTags, nil
+}
+
+// isPermissionError returns whether the EC2 API call failed because the instance role is not allowed to make it
+func isPermissionError(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == "UnauthorizedOperation" || awsErr.Code() == "AccessDenied"
+	}
+	return false
+}
+
+// fetchEc2TagsFromMetadata reads the tags from the instance metadata, available when the
+// access to the tags in the instance metadata is enabled on the instance
+func fetchEc2TagsFromMetadata() ([]string, error) {
+	keys, err := getMetadataItem("/tags/instance")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := []string{}
+	for _, key := range strings.Split(keys, "\n") {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			continue
+		}
+		value, err := getMetadataItem("/tags/instance/" + key)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, fmt.Sprintf("%s:%s", key, value))
+	}
+	return tags, nil
+}
+
+func fetchEc2TagsFromAPI() ([]string, error) {
 	instanceIdentity, err := getInstanceIdentity()
 	if err != nil {
 		return nil, err
@@ -152,3 +152,49 @@ func TestGetTagsFullWorkflow(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Equal(t, []string{"tag1", "tag2"}, tags)
 }
+
+func TestFetchEc2TagsFromMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags/instance":
+			io.WriteString(w, "Name\nenv")
+		case "/tags/instance/Name":
+			io.WriteString(w, "web-1")
+		case "/tags/instance/env":
+			io.WriteString(w, "prod")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	timeout = time.Second
+	defer resetPackageVars()
+
+	tags, err := fetchEc2TagsFromMetadata()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Name:web-1", "env:prod"}, tags)
+}
+
+func TestFetchEc2TagsFallbackToMetadata(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/tags/instance":
+			io.WriteString(w, "env")
+		case "/tags/instance/env":
+			io.WriteString(w, "prod")
+		default:
+			// no IAM role attached to the instance
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	instanceIdentityURL = ts.URL + "/dynamic/instance-identity/document/"
+	timeout = time.Second
+	defer resetPackageVars()
+
+	tags, err := fetchEc2Tags()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"env:prod"}, tags)
+}
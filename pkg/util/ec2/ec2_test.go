@@ -29,6 +29,8 @@ func resetPackageVars() {
 	timeout = initialTimeout
 	metadataURL = initialMetadataURL
 	tokenURL = initialTokenURL
+	token = &ec2Token{}
+	unreachable = &unreachableHosts{hosts: make(map[string]time.Time)}
 }
 
 func TestIsDefaultHostname(t *testing.T) {
@@ -257,9 +259,9 @@ func TestGetToken(t *testing.T) {
 	timeout = time.Second
 	defer resetPackageVars()
 
-	token, err := getToken()
+	value, err := getToken(time.Minute)
 	require.NoError(t, err)
-	assert.Equal(t, originalToken, token)
+	assert.Equal(t, originalToken, value)
 }
 
 func TestMetedataRequestWithToken(t *testing.T) {
@@ -324,10 +326,93 @@ func TestMetedataRequestWithToken(t *testing.T) {
 	assert.Equal(t, "", requestWithoutToken.Header.Get("X-aws-ec2-metadata-token"))
 	assert.Equal(t, "/local-ipv4", requestWithoutToken.RequestURI)
 	assert.Equal(t, http.MethodGet, requestWithoutToken.Method)
-	assert.Equal(t, "60", requestForToken.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+	assert.Equal(t, "21600", requestForToken.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
 	assert.Equal(t, http.MethodPut, requestForToken.Method)
 	assert.Equal(t, "/", requestForToken.RequestURI)
 	assert.Equal(t, token, requestWithToken.Header.Get("X-aws-ec2-metadata-token"))
 	assert.Equal(t, "/local-ipv4", requestWithToken.RequestURI)
 	assert.Equal(t, http.MethodGet, requestWithToken.Method)
 }
+
+func TestMetadataRequestReusesToken(t *testing.T) {
+	var tokenRequests, unauthorizedRequests int
+	const value = "AQAAAFKw7LyqwVmmBMkqXHpDBuDWw2GnfGswTHi2yiIOGvzD7OMaWw=="
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			tokenRequests++
+			io.WriteString(w, value)
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != value {
+			unauthorizedRequests++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, "i-0123456789abcdef0")
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL
+	timeout = time.Second
+	defer resetPackageVars()
+
+	for i := 0; i < 3; i++ {
+		id, err := GetInstanceID()
+		require.NoError(t, err)
+		assert.Equal(t, "i-0123456789abcdef0", id)
+	}
+	assert.Equal(t, 1, tokenRequests)
+	assert.Equal(t, 1, unauthorizedRequests)
+}
+
+func TestMetadataRequestPreferIMDSv2(t *testing.T) {
+	var unauthorizedRequests int
+	const value = "AQAAAFKw7LyqwVmmBMkqXHpDBuDWw2GnfGswTHi2yiIOGvzD7OMaWw=="
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			io.WriteString(w, value)
+			return
+		}
+		if r.Header.Get("X-aws-ec2-metadata-token") != value {
+			unauthorizedRequests++
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		io.WriteString(w, "i-0123456789abcdef0")
+	}))
+	defer ts.Close()
+	metadataURL = ts.URL
+	tokenURL = ts.URL
+	timeout = time.Second
+	defer resetPackageVars()
+
+	config.Datadog.Set("ec2_prefer_imdsv2", true)
+	defer config.Datadog.Set("ec2_prefer_imdsv2", false)
+
+	id, err := GetInstanceID()
+	require.NoError(t, err)
+	assert.Equal(t, "i-0123456789abcdef0", id)
+	assert.Equal(t, 0, unauthorizedRequests)
+}
+
+func TestMetadataUnreachableIsCached(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "i-0123456789abcdef0")
+	}))
+	metadataURL = ts.URL
+	timeout = time.Second
+	defer resetPackageVars()
+
+	// the endpoint is unreachable once the server is closed
+	ts.Close()
+	_, err := GetInstanceID()
+	require.Error(t, err)
+
+	start := time.Now()
+	_, err = GetInstanceID()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "recently unreachable")
+	assert.True(t, time.Since(start) < timeout)
+}
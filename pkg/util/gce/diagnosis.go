@@ -11,7 +11,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("GCE Metadata availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("GCE Metadata availability", diagnose)
 }
 
 // diagnose the GCE metadata API availability
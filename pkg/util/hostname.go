@@ -9,7 +9,6 @@ import (
 	"expvar"
 	"fmt"
 	"net"
-	"os"
 	"runtime"
 
 	"github.com/DataDog/datadog-agent/pkg/metadata/inventories"
@@ -19,8 +18,6 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/cache"
 	"github.com/DataDog/datadog-agent/pkg/util/ec2"
-	"github.com/DataDog/datadog-agent/pkg/util/ecs"
-	"github.com/DataDog/datadog-agent/pkg/util/fargate"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname"
 	"github.com/DataDog/datadog-agent/pkg/util/hostname/validate"
 )
@@ -122,171 +119,78 @@ func saveHostnameData(cacheHostnameKey string, hostname string, provider string)
 	return hostnameData
 }
 
-// GetHostnameData retrieves the host name for the Agent and hostname provider, trying to query these
-// environments/api, in order:
-// * GCE
-// * Docker
-// * kubernetes
-// * os
-// * EC2
+// GetHostnameData retrieves the host name for the Agent and hostname provider, going through the
+// hostname resolution chain returned by getHostnameProviders.
 func GetHostnameData() (HostnameData, error) {
 	cacheHostnameKey := cache.BuildAgentKey("hostname")
 	if cacheHostname, found := cache.Cache.Get(cacheHostnameKey); found {
 		return cacheHostname.(HostnameData), nil
 	}
 
-	var hostName string
-	var err error
-	var provider string
-
-	// try the name provided in the configuration file
-	configName := config.Datadog.GetString("hostname")
-	err = validate.ValidHostname(configName)
-	if err == nil {
-		hostnameData := saveHostnameData(cacheHostnameKey, configName, HostnameProviderConfiguration)
-		if !isHostnameCanonicalForIntake(configName) && !config.Datadog.GetBool("hostname_force_config_as_canonical") {
-			_ = log.Warnf("Hostname '%s' defined in configuration will not be used as the in-app hostname. For more information: https://dtdg.co/agent-hostname-force-config-as-canonical", configName)
+	log.Debug("Trying to determine a reliable host name...")
+	providers := getHostnameProviders()
+	data, results := resolveHostname(providers, false)
+	for i, result := range results {
+		err := result.Error
+		if err == nil {
+			err = result.Unused
 		}
-		return hostnameData, err
-	}
-
-	expErr := new(expvar.String)
-	expErr.Set(err.Error())
-	hostnameErrors.Set("configuration/environment", expErr)
-
-	log.Debugf("Unable to get the hostname from the config file: %s", err)
-	log.Debug("Trying to determine a reliable host name automatically...")
-
-	// if fargate we strip the hostname
-	if fargate.IsFargateInstance() {
-		hostnameData := saveHostnameData(cacheHostnameKey, "", "")
-		return hostnameData, nil
-	}
-
-	// GCE metadata
-	log.Debug("GetHostname trying GCE metadata...")
-	if getGCEHostname, found := hostname.ProviderCatalog["gce"]; found {
-		gceName, err := getGCEHostname()
 		if err == nil {
-			hostnameData := saveHostnameData(cacheHostnameKey, gceName, "gce")
-			return hostnameData, err
+			continue
 		}
+		log.Debugf("Unable to get the hostname from %s: %s", result.Provider, err)
 		expErr := new(expvar.String)
 		expErr.Set(err.Error())
-		hostnameErrors.Set("gce", expErr)
-		log.Debug("Unable to get hostname from GCE: ", err)
+		hostnameErrors.Set(providers[i].expvarName, expErr)
 	}
 
-	// FQDN
-	var fqdn string
-	canUseOSHostname := isOSHostnameUsable()
-	if canUseOSHostname {
-		log.Debug("GetHostname trying FQDN/`hostname -f`...")
-		fqdn, err = getSystemFQDN()
-		if config.Datadog.GetBool("hostname_fqdn") && err == nil {
-			hostName = fqdn
-			provider = "fqdn"
-		} else {
-			if err != nil {
-				expErr := new(expvar.String)
-				expErr.Set(err.Error())
-				hostnameErrors.Set("fqdn", expErr)
-			}
-			log.Debug("Unable to get FQDN from system: ", err)
+	switch data.Provider {
+	case HostnameProviderConfiguration:
+		if !isHostnameCanonicalForIntake(data.Hostname) && !config.Datadog.GetBool("hostname_force_config_as_canonical") {
+			_ = log.Warnf("Hostname '%s' defined in configuration will not be used as the in-app hostname. For more information: https://dtdg.co/agent-hostname-force-config-as-canonical", data.Hostname)
 		}
+		return saveHostnameData(cacheHostnameKey, data.Hostname, data.Provider), nil
+	case "fargate":
+		return saveHostnameData(cacheHostnameKey, "", ""), nil
 	}
 
-	isContainerized, containerName := getContainerHostname()
-	if isContainerized {
-		if containerName != "" {
-			hostName = containerName
-			provider = "container"
-		} else {
-			expErr := new(expvar.String)
-			expErr.Set("Unable to get hostname from container API")
-			hostnameErrors.Set("container", expErr)
-		}
-	}
+	hostName := data.Hostname
 
-	if canUseOSHostname && hostName == "" {
-		// os
-		log.Debug("GetHostname trying os...")
-		systemName, err := os.Hostname()
-		if err == nil {
-			hostName = systemName
-			provider = "os"
-		} else {
-			expErr := new(expvar.String)
-			expErr.Set(err.Error())
-			hostnameErrors.Set("os", expErr)
-			log.Debug("Unable to get hostname from OS: ", err)
-		}
-	}
-
-	/* at this point we've either the hostname from the os or an empty string */
+	// Display a message when enabling `ec2_use_windows_prefix_detection` would make the hostname resolution change.
+	if getEC2Hostname, found := hostname.ProviderCatalog["ec2"]; found && data.Provider != "aws" && ec2.IsWindowsDefaultHostname(hostName) {
+		// `ec2.IsDefaultHostname(hostName)` is false, if `ec2.IsWindowsDefaultHostname(hostName)`
+		// is `true` that means `ec2_use_windows_prefix_detection` is set to false.
+		ec2Hostname, err := getValidEC2Hostname(getEC2Hostname)
 
-	// We use the instance id if we're on an ECS cluster or we're on EC2
-	// and the hostname is one of the default ones
-	if getEC2Hostname, found := hostname.ProviderCatalog["ec2"]; found {
-		log.Debug("GetHostname trying EC2 metadata...")
-
-		if ecs.IsECSInstance() || ec2.IsDefaultHostname(hostName) {
-			ec2Hostname, err := getValidEC2Hostname(getEC2Hostname)
-
-			if err == nil {
-				hostName = ec2Hostname
-				provider = "aws"
-			} else {
-				expErr := new(expvar.String)
-				expErr.Set(err.Error())
-				hostnameErrors.Set("aws", expErr)
-				log.Debug(err)
-			}
-		} else {
-			err := fmt.Errorf("not retrieving hostname from AWS: the host is not an ECS instance and other providers already retrieve non-default hostnames")
-			log.Debug(err.Error())
-			expErr := new(expvar.String)
-			expErr.Set(err.Error())
-			hostnameErrors.Set("aws", expErr)
-
-			// Display a message when enabling `ec2_use_windows_prefix_detection` would make the hostname resolution change.
-			if ec2.IsWindowsDefaultHostname(hostName) {
-				// As we are in the else clause `ec2.IsDefaultHostname(hostName)` is false. If `ec2.IsWindowsDefaultHostname(hostName)`
-				// is `true` that means `ec2_use_windows_prefix_detection` is set to false.
-				ec2Hostname, err := getValidEC2Hostname(getEC2Hostname)
-
-				// Check if we get a valid hostname when enabling `ec2_use_windows_prefix_detection` and the hostnames are different.
-				if err == nil && ec2Hostname != hostName {
-					// REMOVEME: This should be removed if/when the default `ec2_use_windows_prefix_detection` is set to true
-					log.Infof("The agent resolved your hostname as '%s'. You may want to use the EC2 instance-id ('%s') for the in-app hostname."+
-						" For more information: https://docs.datadoghq.com/ec2-use-win-prefix-detection", hostName, ec2Hostname)
-				}
-			}
+		// Check if we get a valid hostname when enabling `ec2_use_windows_prefix_detection` and the hostnames are different.
+		if err == nil && ec2Hostname != hostName {
+			// REMOVEME: This should be removed if/when the default `ec2_use_windows_prefix_detection` is set to true
+			log.Infof("The agent resolved your hostname as '%s'. You may want to use the EC2 instance-id ('%s') for the in-app hostname."+
+				" For more information: https://docs.datadoghq.com/ec2-use-win-prefix-detection", hostName, ec2Hostname)
 		}
 	}
 
-	h, err := os.Hostname()
-	if err == nil && !config.Datadog.GetBool("hostname_fqdn") && fqdn != "" && hostName == h && h != fqdn {
-		if runtime.GOOS != "windows" {
-			// REMOVEME: This should be removed when the default `hostname_fqdn` is set to true
-			log.Warnf("DEPRECATION NOTICE: The agent resolved your hostname as '%s'. However in a future version, it will be resolved as '%s' by default. To enable the future behavior, please enable the `hostname_fqdn` flag in the configuration. For more information: https://dtdg.co/flag-hostname-fqdn", h, fqdn)
-		} else { // OS is Windows
-			log.Warnf("The agent resolved your hostname as '%s', and will be reported this way to maintain compatibility with version 5. To enable reporting as '%s', please enable the `hostname_fqdn` flag in the configuration. For more information: https://dtdg.co/flag-hostname-fqdn", h, fqdn)
+	if data.Provider == "os" && !config.Datadog.GetBool("hostname_fqdn") {
+		if fqdn, err := getSystemFQDN(); err == nil && fqdn != "" && hostName != fqdn {
+			if runtime.GOOS != "windows" {
+				// REMOVEME: This should be removed when the default `hostname_fqdn` is set to true
+				log.Warnf("DEPRECATION NOTICE: The agent resolved your hostname as '%s'. However in a future version, it will be resolved as '%s' by default. To enable the future behavior, please enable the `hostname_fqdn` flag in the configuration. For more information: https://dtdg.co/flag-hostname-fqdn", hostName, fqdn)
+			} else { // OS is Windows
+				log.Warnf("The agent resolved your hostname as '%s', and will be reported this way to maintain compatibility with version 5. To enable reporting as '%s', please enable the `hostname_fqdn` flag in the configuration. For more information: https://dtdg.co/flag-hostname-fqdn", hostName, fqdn)
+			}
 		}
 	}
 
 	// If at this point we don't have a name, bail out
+	var err error
 	if hostName == "" {
 		err = fmt.Errorf("unable to reliably determine the host name. You can define one in the agent config file or in your hosts file")
-	} else {
-		// we got a hostname, residual errors are irrelevant now
-		err = nil
 	}
 
-	hostnameData := saveHostnameData(cacheHostnameKey, hostName, provider)
+	hostnameData := saveHostnameData(cacheHostnameKey, hostName, data.Provider)
 	if err != nil {
 		expErr := new(expvar.String)
-		expErr.Set(fmt.Sprintf(err.Error()))
+		expErr.Set(err.Error())
 		hostnameErrors.Set("all", expErr)
 	}
 	return hostnameData, err
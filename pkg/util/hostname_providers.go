@@ -0,0 +1,250 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package util
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/ec2"
+	"github.com/DataDog/datadog-agent/pkg/util/ecs"
+	"github.com/DataDog/datadog-agent/pkg/util/fargate"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname"
+	"github.com/DataDog/datadog-agent/pkg/util/hostname/validate"
+)
+
+// hostnameProvider is a step of the hostname resolution chain
+type hostnameProvider struct {
+	name string
+	// expvarName is the name of the provider in the hostname errors expvar
+	expvarName string
+	// cb queries the provider
+	cb func() (string, error)
+	// usable returns an error when the answer of the provider can't replace currentHostname,
+	// the hostname found by the previous providers of the chain. It is nil when the answer is always usable.
+	usable func(currentHostname string) error
+	// stopIfSuccessful ends the resolution when the provider finds a hostname
+	stopIfSuccessful bool
+}
+
+// HostnameProviderResult is the answer of a provider of the hostname resolution chain
+type HostnameProviderResult struct {
+	Provider string
+	Hostname string
+	// Error is set when the provider was queried and failed
+	Error error
+	// Unused explains why a hostname found by the provider was not used
+	Unused error
+	// Disabled is true when the provider is not part of the configured `hostname_providers`
+	Disabled bool
+	// Selected is true for the provider of the resolved hostname
+	Selected bool
+}
+
+// getHostnameProviders returns the hostname resolution chain in order of precedence. A provider
+// can override the hostname found by the previous ones unless a provider before it stops the resolution:
+// * configuration: the `hostname` option, stops the resolution
+// * fargate: an empty hostname on Fargate, stops the resolution
+// * gce: the GCE instance name, stops the resolution
+// * fqdn: the system FQDN, when `hostname_fqdn` is enabled
+// * container: the hostname from the container runtime or the kubernetes API
+// * os: the OS hostname, when no hostname was found yet
+// * aws: the EC2 instance ID, on ECS or when the hostname is an EC2 default one
+func getHostnameProviders() []hostnameProvider {
+	var osHostnameUsable *bool
+	canUseOSHostname := func() error {
+		if osHostnameUsable == nil {
+			usable := isOSHostnameUsable()
+			osHostnameUsable = &usable
+		}
+		if !*osHostnameUsable {
+			return fmt.Errorf("the OS hostname is not the host one in this container")
+		}
+		return nil
+	}
+
+	return []hostnameProvider{
+		{
+			name:       HostnameProviderConfiguration,
+			expvarName: "configuration/environment",
+			cb: func() (string, error) {
+				name := config.Datadog.GetString("hostname")
+				return name, validate.ValidHostname(name)
+			},
+			stopIfSuccessful: true,
+		},
+		{
+			name:       "fargate",
+			expvarName: "fargate",
+			cb: func() (string, error) {
+				if fargate.IsFargateInstance() {
+					// the hostname is stripped on Fargate
+					return "", nil
+				}
+				return "", fmt.Errorf("not running on Fargate")
+			},
+			stopIfSuccessful: true,
+		},
+		{
+			name:             "gce",
+			expvarName:       "gce",
+			cb:               catalogProvider("gce"),
+			stopIfSuccessful: true,
+		},
+		{
+			name:       "fqdn",
+			expvarName: "fqdn",
+			cb: func() (string, error) {
+				if err := canUseOSHostname(); err != nil {
+					return "", err
+				}
+				return getSystemFQDN()
+			},
+			usable: func(string) error {
+				if !config.Datadog.GetBool("hostname_fqdn") {
+					return fmt.Errorf("`hostname_fqdn` is disabled")
+				}
+				return nil
+			},
+		},
+		{
+			name:       "container",
+			expvarName: "container",
+			cb: func() (string, error) {
+				isContainerized, containerName := getContainerHostname()
+				if !isContainerized {
+					return "", fmt.Errorf("not running in a container")
+				}
+				if containerName == "" {
+					return "", fmt.Errorf("Unable to get hostname from container API")
+				}
+				return containerName, nil
+			},
+		},
+		{
+			name:       "os",
+			expvarName: "os",
+			cb: func() (string, error) {
+				if err := canUseOSHostname(); err != nil {
+					return "", err
+				}
+				return os.Hostname()
+			},
+			usable: func(currentHostname string) error {
+				if currentHostname != "" {
+					return fmt.Errorf("a hostname was already found by a previous provider")
+				}
+				return nil
+			},
+		},
+		{
+			name:       "aws",
+			expvarName: "aws",
+			cb: func() (string, error) {
+				getEC2Hostname, found := hostname.ProviderCatalog["ec2"]
+				if !found {
+					return "", fmt.Errorf("the ec2 hostname provider is not available")
+				}
+				return getValidEC2Hostname(getEC2Hostname)
+			},
+			usable: func(currentHostname string) error {
+				if currentHostname == "" || ecs.IsECSInstance() || ec2.IsDefaultHostname(currentHostname) {
+					return nil
+				}
+				return fmt.Errorf("not retrieving hostname from AWS: the host is not an ECS instance and other providers already retrieve non-default hostnames")
+			},
+		},
+	}
+}
+
+// catalogProvider returns a callback querying a provider of the hostname provider catalog
+func catalogProvider(name string) func() (string, error) {
+	return func() (string, error) {
+		provider, found := hostname.ProviderCatalog[name]
+		if !found {
+			return "", fmt.Errorf("the %s hostname provider is not available", name)
+		}
+		return provider()
+	}
+}
+
+// isHostnameProviderEnabled returns whether the provider is part of the configured `hostname_providers`,
+// all the providers are enabled when the option is empty
+func isHostnameProviderEnabled(name string) bool {
+	enabled := config.Datadog.GetStringSlice("hostname_providers")
+	if len(enabled) == 0 {
+		return true
+	}
+	for _, n := range enabled {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveHostname runs the hostname resolution chain. With diagnose, every enabled provider is queried
+// so that its answer can be reported, without changing the resolved hostname.
+func resolveHostname(providers []hostnameProvider, diagnose bool) (HostnameData, []HostnameProviderResult) {
+	var data HostnameData
+	selected := -1
+	resolved := false
+	results := make([]HostnameProviderResult, 0, len(providers))
+
+	for _, p := range providers {
+		result := HostnameProviderResult{Provider: p.name}
+		if !isHostnameProviderEnabled(p.name) {
+			result.Disabled = true
+			results = append(results, result)
+			continue
+		}
+		if resolved && !diagnose {
+			break
+		}
+
+		var unused error
+		if resolved {
+			unused = fmt.Errorf("the hostname was already resolved by a previous provider")
+		} else if p.usable != nil {
+			unused = p.usable(data.Hostname)
+		}
+
+		// don't query a provider whose answer would not be used, unless diagnosing
+		if unused != nil && !diagnose {
+			result.Unused = unused
+			results = append(results, result)
+			continue
+		}
+
+		result.Hostname, result.Error = p.cb()
+		if result.Error != nil {
+			result.Hostname = ""
+		} else if unused != nil {
+			result.Unused = unused
+		} else {
+			data = HostnameData{Hostname: result.Hostname, Provider: p.name}
+			selected = len(results)
+			resolved = p.stopIfSuccessful
+		}
+		results = append(results, result)
+	}
+
+	if selected >= 0 {
+		results[selected].Selected = true
+	}
+	return data, results
+}
+
+// GetHostnameDiagnosis queries every hostname provider, bypassing the cache, and returns the resolved
+// hostname along with the answer of each provider
+func GetHostnameDiagnosis() (HostnameData, []HostnameProviderResult, error) {
+	data, results := resolveHostname(getHostnameProviders(), true)
+	if data.Hostname == "" && data.Provider != "fargate" {
+		return data, results, fmt.Errorf("unable to reliably determine the host name. You can define one in the agent config file or in your hosts file")
+	}
+	return data, results, nil
+}
@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+)
+
+func testHostnameProviders(queried map[string]bool) []hostnameProvider {
+	answer := func(name, hostname string, err error) func() (string, error) {
+		return func() (string, error) {
+			queried[name] = true
+			return hostname, err
+		}
+	}
+	return []hostnameProvider{
+		{name: "first", cb: answer("first", "", fmt.Errorf("no hostname")), stopIfSuccessful: true},
+		{name: "second", cb: answer("second", "second-host", nil)},
+		{name: "third", cb: answer("third", "third-host", nil), usable: func(current string) error {
+			if current != "" {
+				return fmt.Errorf("already found")
+			}
+			return nil
+		}},
+		{name: "fourth", cb: answer("fourth", "fourth-host", nil), stopIfSuccessful: true},
+		{name: "fifth", cb: answer("fifth", "fifth-host", nil)},
+	}
+}
+
+func TestResolveHostname(t *testing.T) {
+	queried := make(map[string]bool)
+	data, results := resolveHostname(testHostnameProviders(queried), false)
+
+	assert.Equal(t, HostnameData{Hostname: "fourth-host", Provider: "fourth"}, data)
+	assert.Len(t, results, 4)
+	assert.Error(t, results[0].Error)
+	assert.Equal(t, "second-host", results[1].Hostname)
+	assert.False(t, results[1].Selected)
+	assert.Error(t, results[2].Unused)
+	assert.True(t, results[3].Selected)
+
+	// the providers whose answer is not used are not queried
+	assert.False(t, queried["third"])
+	assert.False(t, queried["fifth"])
+}
+
+func TestResolveHostnameDiagnose(t *testing.T) {
+	queried := make(map[string]bool)
+	data, results := resolveHostname(testHostnameProviders(queried), true)
+
+	assert.Equal(t, HostnameData{Hostname: "fourth-host", Provider: "fourth"}, data)
+	assert.Len(t, results, 5)
+	assert.Equal(t, "third-host", results[2].Hostname)
+	assert.Error(t, results[2].Unused)
+	assert.True(t, results[3].Selected)
+	assert.Equal(t, "fifth-host", results[4].Hostname)
+	assert.Error(t, results[4].Unused)
+	assert.False(t, results[4].Selected)
+	assert.True(t, queried["third"])
+	assert.True(t, queried["fifth"])
+}
+
+func TestResolveHostnameEnabledProviders(t *testing.T) {
+	config.Datadog.Set("hostname_providers", []string{"second", "third", "fifth"})
+	defer config.Datadog.Set("hostname_providers", []string{})
+
+	queried := make(map[string]bool)
+	data, results := resolveHostname(testHostnameProviders(queried), false)
+
+	// the providers without a usable condition override the previous answers
+	assert.Equal(t, HostnameData{Hostname: "fifth-host", Provider: "fifth"}, data)
+	assert.True(t, results[0].Disabled)
+	assert.False(t, results[1].Selected)
+	assert.Error(t, results[2].Unused)
+	assert.True(t, results[3].Disabled)
+	assert.True(t, results[4].Selected)
+	assert.False(t, queried["first"])
+	assert.False(t, queried["fourth"])
+}
@@ -11,6 +11,7 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -19,6 +20,12 @@ import (
 
 // CreateHTTPTransport creates an *http.Transport for use in the agent
 func CreateHTTPTransport() *http.Transport {
+	return CreateHTTPTransportForIntake("")
+}
+
+// CreateHTTPTransportForIntake creates an *http.Transport for use in the agent, using the
+// proxy override of the intake if any, see config.ProxyIntakeLogs and config.ProxyIntakeAPM
+func CreateHTTPTransportForIntake(intake string) *http.Transport {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: config.Datadog.GetBool("skip_ssl_validation"),
 	}
@@ -47,7 +54,7 @@ func CreateHTTPTransport() *http.Transport {
 		ExpectContinueTimeout: 1 * time.Second,
 	}
 
-	if proxies := config.GetProxies(); proxies != nil {
+	if proxies := config.GetProxiesForIntake(intake); proxies != nil {
 		transport.Proxy = GetProxyTransportFunc(proxies)
 	}
 
@@ -59,11 +66,9 @@ func CreateHTTPTransport() *http.Transport {
 func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error) {
 	return func(r *http.Request) (*url.URL, error) {
 		// check no_proxy list first
-		for _, host := range p.NoProxy {
-			if r.URL.Host == host {
-				log.Debugf("URL match no_proxy list item '%s': not using any proxy", host)
-				return nil, nil
-			}
+		if entry, ok := NoProxyMatch(r.URL, p.NoProxy); ok {
+			log.Debugf("URL match no_proxy list item '%s': not using any proxy", entry)
+			return nil, nil
 		}
 
 		// check proxy by scheme
@@ -83,16 +88,7 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 				log.Error(err.Error())
 				return nil, err
 			}
-			userInfo := ""
-			if proxyURL.User != nil {
-				if _, isSet := proxyURL.User.Password(); isSet {
-					userInfo = "*****:*****@"
-				} else {
-					userInfo = "*****@"
-				}
-			}
-
-			log.Debugf("Using proxy %s://%s%s for URL '%s'", proxyURL.Scheme, userInfo, proxyURL.Host, SanitizeURL(r.URL.String()))
+			log.Debugf("Using proxy %s for URL '%s'", RedactProxyURL(proxyURL), SanitizeURL(r.URL.String()))
 			return proxyURL, nil
 		}
 
@@ -100,3 +96,72 @@ func GetProxyTransportFunc(p *config.Proxy) func(*http.Request) (*url.URL, error
 		return nil, nil
 	}
 }
+
+// RedactProxyURL returns the proxy URL without its path, with its credentials masked
+func RedactProxyURL(proxyURL *url.URL) string {
+	userInfo := ""
+	if proxyURL.User != nil {
+		if _, isSet := proxyURL.User.Password(); isSet {
+			userInfo = "*****:*****@"
+		} else {
+			userInfo = "*****@"
+		}
+	}
+	return fmt.Sprintf("%s://%s%s", proxyURL.Scheme, userInfo, proxyURL.Host)
+}
+
+// NoProxyMatch returns the first entry of the no_proxy list matching the URL, if any. An entry can be
+// "*", matching every URL, a host with an optional port, matching it and its subdomains, a domain
+// starting with "." or "*.", matching its subdomains only, an IP address with an optional port, or
+// a CIDR range, matching the IP addresses it contains.
+func NoProxyMatch(u *url.URL, noProxy []string) (string, bool) {
+	hostname := strings.ToLower(u.Hostname())
+	port := u.Port()
+	ip := net.ParseIP(hostname)
+
+	for _, rawEntry := range noProxy {
+		entry := strings.ToLower(strings.TrimSpace(rawEntry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*" || entry == strings.ToLower(u.Host):
+			return rawEntry, true
+		}
+
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return rawEntry, true
+			}
+			continue
+		}
+
+		entryHost := entry
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			if p != port {
+				continue
+			}
+			entryHost = h
+		}
+
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return rawEntry, true
+			}
+			continue
+		}
+
+		if strings.HasPrefix(entryHost, "*.") {
+			entryHost = entryHost[1:]
+		}
+		if strings.HasPrefix(entryHost, ".") {
+			if strings.HasSuffix(hostname, entryHost) {
+				return rawEntry, true
+			}
+			continue
+		}
+		if hostname == entryHost || strings.HasSuffix(hostname, "."+entryHost) {
+			return rawEntry, true
+		}
+	}
+	return "", false
+}
@@ -325,7 +325,8 @@ func (c *APIClient) connect() error {
 // metadataMapperBundle maps pod names to associated metadata.
 type metadataMapperBundle struct {
 	Services apiv1.NamespacesPodsStringsSet
-	mapOnIP  bool // temporary opt-out of the new mapping logic
+	Labels   map[string]string // labels of the node, replaced as a whole on changes
+	mapOnIP  bool              // temporary opt-out of the new mapping logic
 }
 
 func newMetadataMapperBundle() *metadataMapperBundle {
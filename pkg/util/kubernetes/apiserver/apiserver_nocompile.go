@@ -54,3 +54,11 @@ func GetNodeLabels(nodeName string) (map[string]string, error) {
 	log.Errorf("GetNodeLabels not implemented %s", ErrNotCompiled.Error())
 	return nil, nil
 }
+
+// MetadataReplicationEvent is a change of the cluster metadata of a node
+type MetadataReplicationEvent struct{}
+
+// StreamMetadata streams the cluster metadata to the followers
+func StreamMetadata(_ func() bool, _ <-chan struct{}, _ func(MetadataReplicationEvent) error) error {
+	return ErrNotCompiled
+}
@@ -62,6 +62,7 @@ type ControllerContext struct {
 	DDInformerFactory  dd_informers.SharedInformerFactory
 	Client             kubernetes.Interface
 	IsLeaderFunc       func() bool
+	LeaderIPFunc       func() (string, error)
 	EventRecorder      record.EventRecorder
 	StopCh             chan struct{}
 }
@@ -111,6 +112,11 @@ func StartControllers(ctx ControllerContext) errors.Aggregate {
 // startMetadataController starts the informers needed for metadata collection.
 // The synchronization of the informers is handled by the controller.
 func startMetadataController(ctx ControllerContext, c chan error) {
+	if metadataReplicationEnabled() && ctx.LeaderIPFunc != nil {
+		go runReplicatedMetadataController(ctx)
+		return
+	}
+
 	metaController := NewMetadataController(
 		ctx.InformerFactory.Core().V1().Nodes(),
 		ctx.InformerFactory.Core().V1().Endpoints(),
@@ -118,6 +124,23 @@ func startMetadataController(ctx ControllerContext, c chan error) {
 	go metaController.Run(ctx.StopCh)
 }
 
+// runReplicatedMetadataController replicates the cluster metadata from the leader until this
+// instance becomes the leader, it then starts the informers needed for metadata collection.
+func runReplicatedMetadataController(ctx ControllerContext) {
+	if !newMetadataReplicator(ctx.IsLeaderFunc, ctx.LeaderIPFunc).run(ctx.StopCh) {
+		return
+	}
+
+	log.Infof("Leading the cluster agents, collecting the cluster metadata from the apiserver")
+	metaController := NewMetadataController(
+		ctx.InformerFactory.Core().V1().Nodes(),
+		ctx.InformerFactory.Core().V1().Endpoints(),
+	)
+	// the factory only starts the informers which weren't started yet
+	ctx.InformerFactory.Start(ctx.StopCh)
+	metaController.Run(ctx.StopCh)
+}
+
 // startAutoscalersController starts the informers needed for autoscaling.
 // The synchronization of the informers is handled by the controller.
 func startAutoscalersController(ctx ControllerContext, c chan error) {
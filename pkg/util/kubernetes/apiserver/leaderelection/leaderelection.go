@@ -16,6 +16,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	coordinationv1 "k8s.io/client-go/kubernetes/typed/coordination/v1"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/leaderelection"
 	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
@@ -50,28 +51,39 @@ type LeaderEngine struct {
 	m       sync.Mutex
 	once    sync.Once
 
-	HolderIdentity      string
-	LeaseDuration       time.Duration
-	LeaseName           string
-	LeaderNamespace     string
-	coreClient          corev1.CoreV1Interface
-	ServiceName         string
-	leaderIdentityMutex sync.RWMutex
-	leaderElector       *leaderelection.LeaderElector
+	HolderIdentity         string
+	LeaseDuration          time.Duration
+	LeaseName              string
+	LeaderNamespace        string
+	LeaderElectionResource string
+	coreClient             corev1.CoreV1Interface
+	coordClient            coordinationv1.CoordinationV1Interface
+	ServiceName            string
+	leaderIdentityMutex    sync.RWMutex
+	leaderElector          *leaderelection.LeaderElector
 
 	// leaderIdentity is the HolderIdentity of the current leader.
 	leaderIdentity string
 
+	// observedLeader is the last leader reported by the elector, it is not reset when
+	// this instance stops leading so that only the changes of identity are counted.
+	observedLeader string
+
 	// leaderMetric indicates whether this instance is leader
 	leaderMetric telemetry.Gauge
+
+	// transitionsMetric counts the leadership changes
+	transitionsMetric telemetry.Counter
 }
 
 func newLeaderEngine() *LeaderEngine {
 	return &LeaderEngine{
-		LeaseName:       defaultLeaseName,
-		LeaderNamespace: common.GetResourcesNamespace(),
-		ServiceName:     config.Datadog.GetString("cluster_agent.kubernetes_service_name"),
-		leaderMetric:    metrics.NewLeaderMetric(),
+		LeaseName:              defaultLeaseName,
+		LeaderNamespace:        common.GetResourcesNamespace(),
+		LeaderElectionResource: config.Datadog.GetString("leader_election_resource"),
+		ServiceName:            config.Datadog.GetString("cluster_agent.kubernetes_service_name"),
+		leaderMetric:           metrics.NewLeaderMetric(),
+		transitionsMetric:      metrics.NewTransitionsMetric(),
 	}
 }
 
@@ -136,11 +148,17 @@ func (le *LeaderEngine) init() error {
 	}
 
 	le.coreClient = apiClient.Cl.CoreV1().(*corev1.CoreV1Client)
-
-	// check if we can get ConfigMap.
-	_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
+	le.coordClient = apiClient.Cl.CoordinationV1()
+
+	// check if we can get the lock object.
+	switch le.LeaderElectionResource {
+	case ConfigMapResource:
+		_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
+	case LeaseResource:
+		_, err = le.coordClient.Leases(le.LeaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
+	}
 	if err != nil && errors.IsNotFound(err) == false {
-		log.Errorf("Cannot retrieve ConfigMap from the %s namespace: %s", le.LeaderNamespace, err)
+		log.Errorf("Cannot retrieve the %s %s from the %s namespace: %s", le.LeaderElectionResource, defaultLeaseName, le.LeaderNamespace, err)
 		return err
 	}
 
@@ -244,9 +262,17 @@ func GetLeaderElectionRecord() (leaderDetails rl.LeaderElectionRecord, err error
 		return led, err
 	}
 
-	c := client.Cl.CoreV1()
-
 	leaderNamespace := common.GetResourcesNamespace()
+	if config.Datadog.GetString("leader_election_resource") == LeaseResource {
+		lease, err := client.Cl.CoordinationV1().Leases(leaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
+		if err != nil {
+			return led, err
+		}
+		log.Debugf("LeaderElection lease is %#v", lease)
+		return *rl.LeaseSpecToLeaderElectionRecord(&lease.Spec), nil
+	}
+
+	c := client.Cl.CoreV1()
 	leaderElectionCM, err := c.ConfigMaps(leaderNamespace).Get(defaultLeaseName, metav1.GetOptions{})
 	if err != nil {
 		return led, err
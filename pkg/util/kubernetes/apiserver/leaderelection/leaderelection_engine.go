@@ -9,13 +9,13 @@ package leaderelection
 
 import (
 	"context"
-	"encoding/json"
 	"strconv"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	ld "k8s.io/client-go/tools/leaderelection"
 	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
@@ -24,58 +24,22 @@ import (
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
 
-func (le *LeaderEngine) getCurrentLeader() (string, *v1.ConfigMap, error) {
-	configMap, err := le.coreClient.ConfigMaps(le.LeaderNamespace).Get(le.LeaseName, metav1.GetOptions{})
-	if err != nil {
-		return "", nil, err
-	}
-
-	val, found := configMap.Annotations[rl.LeaderElectionRecordAnnotationKey]
-	if !found {
-		log.Debugf("The configmap/%s in the namespace %s doesn't have the annotation %q: no one is leading yet", le.LeaseName, le.LeaderNamespace, rl.LeaderElectionRecordAnnotationKey)
-		return "", configMap, nil
-	}
-
-	electionRecord := rl.LeaderElectionRecord{}
-	if err := json.Unmarshal([]byte(val), &electionRecord); err != nil {
-		return "", nil, err
-	}
-	return electionRecord.HolderIdentity, configMap, err
-}
-
 // newElection creates an election.
 // If `namespace`/`election` does not exist, it is created.
 func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
-	// We first want to check if the ConfigMap the Leader Election is based on exists.
-	_, err := le.coreClient.ConfigMaps(le.LeaderNamespace).Get(le.LeaseName, metav1.GetOptions{})
-
-	if err != nil {
-		if errors.IsNotFound(err) == false {
-			return nil, err
-		}
-
-		_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Create(&v1.ConfigMap{
-			TypeMeta: metav1.TypeMeta{
-				Kind: "ConfigMap",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: le.LeaseName,
-			},
-		})
-		if err != nil && !errors.IsConflict(err) {
+	if le.LeaderElectionResource == ConfigMapResource {
+		if err := le.ensureConfigMap(); err != nil {
 			return nil, err
 		}
 	}
 
-	currentLeader, configMap, err := le.getCurrentLeader()
-	if err != nil {
-		return nil, err
-	}
-	log.Debugf("Current registered leader is %q, building leader elector %q as candidate", currentLeader, le.HolderIdentity)
 	callbacks := ld.LeaderCallbacks{
 		OnNewLeader: func(identity string) {
 			le.updateLeaderIdentity(identity)
 			le.reportLeaderMetric(false)
+			if le.observeLeader(identity) {
+				le.transitionsMetric.Inc()
+			}
 			log.Infof("New leader %q", identity)
 		},
 		OnStartedLeading: func(ctx context.Context) {
@@ -97,24 +61,31 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 		Host:      le.HolderIdentity,
 	}
 	broadcaster := record.NewBroadcaster()
-	evRec := broadcaster.NewRecorder(runtime.NewScheme(), eventSource)
+	evRec := broadcaster.NewRecorder(scheme.Scheme, eventSource)
 	resourceLockConfig := rl.ResourceLockConfig{
 		Identity:      le.HolderIdentity,
 		EventRecorder: evRec,
 	}
 
-	leaderElectorInterface, err := rl.New(
-		rl.ConfigMapsResourceLock,
-		configMap.ObjectMeta.Namespace,
-		configMap.ObjectMeta.Name,
-		le.coreClient,
-		nil, // relying on CM so unnecessary.
-		resourceLockConfig,
-	)
+	leaderElectorInterface, err := le.newResourceLock(resourceLockConfig)
 	if err != nil {
+		broadcaster.Shutdown()
 		return nil, err
 	}
 
+	currentLeader := ""
+	if electionRecord, err := leaderElectorInterface.Get(); err == nil {
+		currentLeader = electionRecord.HolderIdentity
+	} else if !errors.IsNotFound(err) {
+		broadcaster.Shutdown()
+		return nil, err
+	}
+
+	// Leadership transitions are published as events on the lock object,
+	// the recording only starts once the lock is built so that no goroutine is leaked on error
+	broadcaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: le.coreClient.Events(le.LeaderNamespace)})
+	log.Debugf("Current registered leader is %q, building leader elector %q as candidate on %s", currentLeader, le.HolderIdentity, leaderElectorInterface.Describe())
+
 	electionConfig := ld.LeaderElectionConfig{
 		Lock:          leaderElectorInterface,
 		LeaseDuration: le.LeaseDuration,
@@ -125,6 +96,30 @@ func (le *LeaderEngine) newElection() (*ld.LeaderElector, error) {
 	return ld.NewLeaderElector(electionConfig)
 }
 
+// ensureConfigMap creates the ConfigMap the leader election is based on if it does not exist
+func (le *LeaderEngine) ensureConfigMap() error {
+	_, err := le.coreClient.ConfigMaps(le.LeaderNamespace).Get(le.LeaseName, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if errors.IsNotFound(err) == false {
+		return err
+	}
+
+	_, err = le.coreClient.ConfigMaps(le.LeaderNamespace).Create(&v1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind: "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: le.LeaseName,
+		},
+	})
+	if err != nil && !errors.IsConflict(err) {
+		return err
+	}
+	return nil
+}
+
 // updateLeaderIdentity sets leaderIdentity
 func (le *LeaderEngine) updateLeaderIdentity(identity string) {
 	le.leaderIdentityMutex.Lock()
@@ -132,6 +127,16 @@ func (le *LeaderEngine) updateLeaderIdentity(identity string) {
 	le.leaderIdentity = identity
 }
 
+// observeLeader records the leader identity reported by the elector, it returns true
+// if it replaces a previously observed leader: the first observation is not a transition.
+func (le *LeaderEngine) observeLeader(identity string) bool {
+	le.leaderIdentityMutex.Lock()
+	defer le.leaderIdentityMutex.Unlock()
+	transition := le.observedLeader != "" && le.observedLeader != identity
+	le.observedLeader = identity
+	return transition
+}
+
 // reportLeaderMetric updates the label of the leader metric on every leadership change
 func (le *LeaderEngine) reportLeaderMetric(isLeader bool) {
 	// We want to make sure only one (the latest) context is exposed for this metric
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package leaderelection
+
+import (
+	"fmt"
+	"sync"
+
+	rl "k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// ConfigMapResource runs the leader election on the annotations of a ConfigMap
+	ConfigMapResource = "configmap"
+	// LeaseResource runs the leader election on a coordination.k8s.io Lease
+	LeaseResource = "lease"
+)
+
+// ResourceLockFactory builds the lock the leader election relies on.
+// Backends other than the Kubernetes objects (e.g. a Consul lock) can
+// be used by registering a factory returning their own rl.Interface.
+type ResourceLockFactory func(le *LeaderEngine, lockConfig rl.ResourceLockConfig) (rl.Interface, error)
+
+var (
+	resourceLocksMutex sync.RWMutex
+	resourceLocks      = map[string]ResourceLockFactory{
+		ConfigMapResource: newConfigMapLock,
+		LeaseResource:     newLeaseLock,
+	}
+)
+
+// RegisterResourceLock registers a leader election backend, selectable
+// with the `leader_election_resource` setting
+func RegisterResourceLock(name string, factory ResourceLockFactory) {
+	resourceLocksMutex.Lock()
+	defer resourceLocksMutex.Unlock()
+	resourceLocks[name] = factory
+}
+
+// newResourceLock builds the lock of the configured leader election backend
+func (le *LeaderEngine) newResourceLock(lockConfig rl.ResourceLockConfig) (rl.Interface, error) {
+	resourceLocksMutex.RLock()
+	factory, found := resourceLocks[le.LeaderElectionResource]
+	resourceLocksMutex.RUnlock()
+	if !found {
+		return nil, fmt.Errorf("unknown leader election resource %q", le.LeaderElectionResource)
+	}
+	return factory(le, lockConfig)
+}
+
+func newConfigMapLock(le *LeaderEngine, lockConfig rl.ResourceLockConfig) (rl.Interface, error) {
+	return rl.New(
+		rl.ConfigMapsResourceLock,
+		le.LeaderNamespace,
+		le.LeaseName,
+		le.coreClient,
+		nil, // relying on CM so unnecessary.
+		lockConfig,
+	)
+}
+
+func newLeaseLock(le *LeaderEngine, lockConfig rl.ResourceLockConfig) (rl.Interface, error) {
+	return rl.New(
+		rl.LeasesResourceLock,
+		le.LeaderNamespace,
+		le.LeaseName,
+		nil, // relying on Lease so unnecessary.
+		le.coordClient,
+		lockConfig,
+	)
+}
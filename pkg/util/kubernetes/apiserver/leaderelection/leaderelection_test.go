@@ -74,6 +74,9 @@ func TestNewLeaseAcquiring(t *testing.T) {
 		LeaseDuration:   1 * time.Second,
 		coreClient:      client.CoreV1(),
 		leaderMetric:    &dummyGauge{},
+
+		LeaderElectionResource: ConfigMapResource,
+		transitionsMetric:      &dummyCounter{},
 	}
 	_, err := client.CoreV1().ConfigMaps("default").Get(leaseName, metav1.GetOptions{})
 	require.True(t, errors.IsNotFound(err))
@@ -98,6 +101,67 @@ func TestNewLeaseAcquiring(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+// TestNewLeaseAcquiringLease checks the acquisition of the leadership
+// when the leader election runs on a coordination.k8s.io Lease.
+func TestNewLeaseAcquiringLease(t *testing.T) {
+	const leaseName = "datadog-leader-election"
+
+	client := fake.NewSimpleClientset()
+
+	le := &LeaderEngine{
+		HolderIdentity:  "foo",
+		LeaseName:       leaseName,
+		LeaderNamespace: "default",
+		LeaseDuration:   1 * time.Second,
+		coreClient:      client.CoreV1(),
+		coordClient:     client.CoordinationV1(),
+		leaderMetric:    &dummyGauge{},
+
+		LeaderElectionResource: LeaseResource,
+		transitionsMetric:      &dummyCounter{},
+	}
+
+	var err error
+	le.leaderElector, err = le.newElection()
+	require.NoError(t, err)
+
+	// No ConfigMap is created for the Lease backend
+	_, err = client.CoreV1().ConfigMaps("default").Get(leaseName, metav1.GetOptions{})
+	require.True(t, errors.IsNotFound(err))
+
+	le.EnsureLeaderElectionRuns()
+	lease, err := client.CoordinationV1().Leases("default").Get(leaseName, metav1.GetOptions{})
+	require.NoError(t, err)
+	require.NotNil(t, lease.Spec.HolderIdentity)
+	assert.Equal(t, "foo", *lease.Spec.HolderIdentity)
+	require.True(t, le.IsLeader())
+}
+
+func TestUnknownLeaderElectionResource(t *testing.T) {
+	le := &LeaderEngine{
+		HolderIdentity:         "foo",
+		LeaseName:              "datadog-leader-election",
+		LeaderNamespace:        "default",
+		LeaderElectionResource: "zookeeper",
+		coreClient:             fake.NewSimpleClientset().CoreV1(),
+	}
+	_, err := le.newElection()
+	assert.EqualError(t, err, `unknown leader election resource "zookeeper"`)
+}
+
+func TestObserveLeader(t *testing.T) {
+	le := &LeaderEngine{}
+
+	// The first observed leader is not a transition
+	assert.False(t, le.observeLeader("foo"))
+	assert.False(t, le.observeLeader("foo"))
+
+	// Only the changes of identity are transitions
+	assert.True(t, le.observeLeader("bar"))
+	assert.False(t, le.observeLeader("bar"))
+	assert.True(t, le.observeLeader("foo"))
+}
+
 func TestGetLeaderIPFollower(t *testing.T) {
 	const leaseName = "datadog-leader-election"
 	const endpointsName = "datadog-cluster-agent"
@@ -111,6 +175,9 @@ func TestGetLeaderIPFollower(t *testing.T) {
 		LeaseDuration:   120 * time.Second,
 		coreClient:      client.CoreV1(),
 		leaderMetric:    &dummyGauge{},
+
+		LeaderElectionResource: ConfigMapResource,
+		transitionsMetric:      &dummyCounter{},
 	}
 
 	// Create leader-election configmap with current node as follower
@@ -183,3 +250,12 @@ func (g *dummyGauge) Dec(tagsValue ...string)                {}
 func (g *dummyGauge) Add(value float64, tagsValue ...string) {}
 func (g *dummyGauge) Sub(value float64, tagsValue ...string) {}
 func (g *dummyGauge) Delete(tagsValue ...string)             {}
+
+type dummyCounter struct{}
+
+func (c *dummyCounter) Inc(tagsValue ...string)                           {}
+func (c *dummyCounter) Add(value float64, tagsValue ...string)            {}
+func (c *dummyCounter) Delete(tagsValue ...string)                        {}
+func (c *dummyCounter) IncWithTags(tags map[string]string)                {}
+func (c *dummyCounter) AddWithTags(value float64, tags map[string]string) {}
+func (c *dummyCounter) DeleteWithTags(tags map[string]string)             {}
@@ -28,3 +28,14 @@ func NewLeaderMetric() telemetry.Gauge {
 		telemetry.Options{NoDoubleUnderscoreSep: true},
 	)
 }
+
+// NewTransitionsMetric returns the leader_election_transitions metric
+func NewTransitionsMetric() telemetry.Counter {
+	return telemetry.NewCounterWithOpts(
+		"leader_election",
+		"transitions",
+		[]string{},
+		"Number of leadership changes observed by the reporting pod.",
+		telemetry.Options{NoDoubleUnderscoreSep: true},
+	)
+}
@@ -9,6 +9,7 @@ package apiserver
 
 import (
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
@@ -52,6 +53,7 @@ func NewMetadataController(nodeInformer coreinformers.NodeInformer, endpointsInf
 	}
 	nodeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    m.addNode,
+		UpdateFunc: m.updateNode,
 		DeleteFunc: m.deleteNode,
 	})
 	m.nodeLister = nodeInformer.Lister()
@@ -111,11 +113,30 @@ func (m *MetadataController) addNode(obj interface{}) {
 	}
 
 	bundle := m.store.getCopyOrNew(node.Name)
+	bundle.Labels = node.Labels
 	m.store.set(node.Name, bundle)
 
 	log.Debugf("Detected node %s", node.Name)
 }
 
+// updateNode keeps the labels of the bundle up to date for the followers replicating it
+func (m *MetadataController) updateNode(old, cur interface{}) {
+	oldNode, ok := old.(*corev1.Node)
+	if !ok {
+		return
+	}
+	newNode, ok := cur.(*corev1.Node)
+	if !ok || reflect.DeepEqual(oldNode.Labels, newNode.Labels) {
+		return
+	}
+
+	bundle := m.store.getCopyOrNew(newNode.Name)
+	bundle.Labels = newNode.Labels
+	m.store.set(newNode.Name, bundle)
+
+	log.Tracef("Updated the labels of the node %s", newNode.Name)
+}
+
 func (m *MetadataController) deleteNode(obj interface{}) {
 	node, ok := obj.(*corev1.Node)
 	if !ok {
@@ -309,7 +330,8 @@ func GetPodMetadataNames(nodeName, ns, podName string) ([]string, error) {
 	return metaList, nil
 }
 
-// GetNodeLabels retrieves the labels of the queried node from the cache of the shared informer.
+// GetNodeLabels retrieves the labels of the queried node from the cache of the shared informer,
+// or from the metadata replicated from the leader.
 func GetNodeLabels(nodeName string) (map[string]string, error) {
 	as, err := GetAPIClient()
 	if err != nil {
@@ -318,6 +340,14 @@ func GetNodeLabels(nodeName string) (map[string]string, error) {
 	if !config.Datadog.GetBool("kubernetes_collect_metadata_tags") {
 		return nil, log.Errorf("Metadata collection is disabled on the Cluster Agent")
 	}
+	if isMetadataReplicated() {
+		// the followers don't watch the nodes, their labels are replicated from the leader
+		metaBundle, found := globalMetaBundleStore.get(nodeName)
+		if !found {
+			return nil, fmt.Errorf("cannot get node %s from the metadata replicated from the leader", nodeName)
+		}
+		return metaBundle.Labels, nil
+	}
 	node, err := as.InformerFactory.Core().V1().Nodes().Lister().Get(nodeName)
 	if err != nil {
 		return nil, err
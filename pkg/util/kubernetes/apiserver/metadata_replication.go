@@ -0,0 +1,244 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/api/util"
+	apiv1 "github.com/DataDog/datadog-agent/pkg/clusteragent/api/v1"
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// With `cluster_agent.metadata_replication.enabled`, only the leader watches the nodes and
+// endpoints to build the cluster metadata, the followers stream it from the leader to serve
+// the queries of the node agents. A follower which becomes the leader starts its own metadata
+// controller and keeps it from then on.
+
+const (
+	// leadershipCheckPeriod is how often the leader and the followers check whether the
+	// leadership changed while streaming
+	leadershipCheckPeriod = 5 * time.Second
+	// metadataReplicationRetryPeriod is the pause between two connections to the leader
+	metadataReplicationRetryPeriod = 5 * time.Second
+)
+
+// metadataReplicationEnabled returns whether the followers replicate the cluster metadata, it
+// requires the leader election
+func metadataReplicationEnabled() bool {
+	return config.Datadog.GetBool("cluster_agent.metadata_replication.enabled") && config.Datadog.GetBool("leader_election")
+}
+
+// metadataReplicated is set to 1 while the cluster metadata comes from the leader
+var metadataReplicated int32
+
+func isMetadataReplicated() bool {
+	return atomic.LoadInt32(&metadataReplicated) == 1
+}
+
+// MetadataReplicationEvent is a change of the cluster metadata of a node, streamed by the
+// leader to the followers
+type MetadataReplicationEvent struct {
+	Node     string                         `json:"node,omitempty"`
+	Services apiv1.NamespacesPodsStringsSet `json:"services,omitempty"`
+	Labels   map[string]string              `json:"labels,omitempty"`
+	Deleted  bool                           `json:"deleted,omitempty"`
+	// Synced marks the end of the initial metadata of all the nodes
+	Synced bool `json:"synced,omitempty"`
+}
+
+// ErrNotLeaderAnymore is returned by StreamMetadata when the leadership is lost
+var ErrNotLeaderAnymore = errors.New("not the leader anymore")
+
+// StreamMetadata sends the cluster metadata of all the nodes, then its changes, until stop is
+// closed, send fails or this instance stops leading
+func StreamMetadata(isLeader func() bool, stop <-chan struct{}, send func(MetadataReplicationEvent) error) error {
+	return streamMetadata(globalMetaBundleStore, isLeader, stop, send)
+}
+
+func streamMetadata(store *metaBundleStore, isLeader func() bool, stop <-chan struct{}, send func(MetadataReplicationEvent) error) error {
+	if !isLeader() {
+		return ErrNotLeader
+	}
+
+	metaBundles, changes := store.subscribe()
+	defer store.unsubscribe(changes)
+
+	for nodeName, metaBundle := range metaBundles {
+		if err := send(newMetadataReplicationEvent(nodeName, metaBundle)); err != nil {
+			return err
+		}
+	}
+	if err := send(MetadataReplicationEvent{Synced: true}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(leadershipCheckPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case event, ok := <-changes:
+			if !ok {
+				return errors.New("the follower is lagging behind")
+			}
+			if err := send(newMetadataReplicationEvent(event.nodeName, event.metaBundle)); err != nil {
+				return err
+			}
+		case <-ticker.C:
+			if !isLeader() {
+				return ErrNotLeaderAnymore
+			}
+		case <-stop:
+			return nil
+		}
+	}
+}
+
+func newMetadataReplicationEvent(nodeName string, metaBundle *metadataMapperBundle) MetadataReplicationEvent {
+	if metaBundle == nil {
+		return MetadataReplicationEvent{Node: nodeName, Deleted: true}
+	}
+	return MetadataReplicationEvent{
+		Node:     nodeName,
+		Services: metaBundle.Services,
+		Labels:   metaBundle.Labels,
+	}
+}
+
+// metadataReplicator streams the cluster metadata from the leader into the store of a follower
+type metadataReplicator struct {
+	store    *metaBundleStore
+	isLeader func() bool
+	leaderIP func() (string, error)
+	client   *http.Client
+}
+
+func newMetadataReplicator(isLeader func() bool, leaderIP func() (string, error)) *metadataReplicator {
+	return &metadataReplicator{
+		store:    globalMetaBundleStore,
+		isLeader: isLeader,
+		leaderIP: leaderIP,
+		client:   util.GetClient(false), // the leader presents a self-signed certificate
+	}
+}
+
+// run replicates the cluster metadata from the leader until this instance becomes the leader,
+// it returns true then, or false when stopCh is closed
+func (r *metadataReplicator) run(stopCh <-chan struct{}) bool {
+	atomic.StoreInt32(&metadataReplicated, 1)
+	defer atomic.StoreInt32(&metadataReplicated, 0)
+
+	for {
+		if r.isLeader() {
+			return true
+		}
+		if err := r.replicate(stopCh); err != nil {
+			log.Warnf("Error replicating the cluster metadata from the leader, retrying in %s: %v", metadataReplicationRetryPeriod, err)
+		}
+		select {
+		case <-stopCh:
+			return false
+		case <-time.After(metadataReplicationRetryPeriod):
+		}
+	}
+}
+
+// replicate connects to the leader and applies its stream of metadata until it ends
+func (r *metadataReplicator) replicate(stopCh <-chan struct{}) error {
+	ip, err := r.leaderIP()
+	if err != nil {
+		return err
+	}
+	if ip == "" {
+		return errors.New("the leader is unknown")
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://%s:%d/api/v1/metadata/stream", ip, config.Datadog.GetInt("cluster_agent.cmd_port")), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+util.GetDCAAuthToken())
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code from the leader: %d", resp.StatusCode)
+	}
+
+	// close the stream when this instance becomes the leader or stops
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(leadershipCheckPeriod)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if r.isLeader() {
+					resp.Body.Close()
+					return
+				}
+			case <-stopCh:
+				resp.Body.Close()
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	log.Infof("Replicating the cluster metadata from the leader at %s", ip)
+	return r.apply(json.NewDecoder(resp.Body))
+}
+
+// apply applies the events to the store, the nodes missing from the initial metadata are
+// deleted once it's received
+func (r *metadataReplicator) apply(decoder *json.Decoder) error {
+	seen := make(map[string]struct{})
+	for {
+		var event MetadataReplicationEvent
+		if err := decoder.Decode(&event); err != nil {
+			if err == io.EOF {
+				return errors.New("the leader closed the stream")
+			}
+			return err
+		}
+
+		switch {
+		case event.Synced:
+			for _, nodeName := range r.store.nodeNames() {
+				if _, ok := seen[nodeName]; !ok {
+					r.store.delete(nodeName)
+				}
+			}
+			log.Debugf("Replicated the cluster metadata of %d nodes", len(seen))
+			seen = nil
+		case event.Deleted:
+			r.store.delete(event.Node)
+		default:
+			metaBundle := newMetadataMapperBundle()
+			if event.Services != nil {
+				metaBundle.Services = event.Services
+			}
+			metaBundle.Labels = event.Labels
+			r.store.set(event.Node, metaBundle)
+			if seen != nil {
+				seen[event.Node] = struct{}{}
+			}
+		}
+	}
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+// +build kubeapiserver
+
+package apiserver
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	gocache "github.com/patrickmn/go-cache"
+)
+
+func newTestMetaBundleStore() *metaBundleStore {
+	return &metaBundleStore{
+		cache: gocache.New(gocache.NoExpiration, 5*time.Second),
+	}
+}
+
+func newTestMetaBundle(labels map[string]string, namespace, podName string, services ...string) *metadataMapperBundle {
+	metaBundle := newMetadataMapperBundle()
+	metaBundle.Services.Set(namespace, podName, services...)
+	metaBundle.Labels = labels
+	return metaBundle
+}
+
+func TestMetadataReplication(t *testing.T) {
+	leader := newTestMetaBundleStore()
+	leader.set("node1", newTestMetaBundle(map[string]string{"zone": "a"}, "default", "pod1", "svc1"))
+	leader.set("node2", newTestMetaBundle(nil, "default", "pod2", "svc2"))
+
+	// the follower has stale metadata from a previous leader
+	follower := newTestMetaBundleStore()
+	follower.set("node3", newTestMetaBundle(nil, "default", "pod3", "svc3"))
+	replicator := &metadataReplicator{store: follower}
+
+	isLeader := true
+	stop := make(chan struct{})
+	reader, writer := io.Pipe()
+	streamed := make(chan error)
+	go func() {
+		encoder := json.NewEncoder(writer)
+		err := streamMetadata(leader, func() bool { return isLeader }, stop, func(event MetadataReplicationEvent) error {
+			return encoder.Encode(event)
+		})
+		writer.Close()
+		streamed <- err
+	}()
+	applied := make(chan error)
+	go func() {
+		applied <- replicator.apply(json.NewDecoder(reader))
+	}()
+
+	assert.Eventually(t, func() bool {
+		_, found := follower.get("node3")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+	metaBundle, found := follower.get("node1")
+	require.True(t, found)
+	services, _ := metaBundle.ServicesForPod("default", "pod1")
+	assert.Equal(t, []string{"svc1"}, services)
+	assert.Equal(t, map[string]string{"zone": "a"}, metaBundle.Labels)
+	_, found = follower.get("node2")
+	assert.True(t, found)
+
+	// the changes are streamed
+	leader.set("node1", newTestMetaBundle(map[string]string{"zone": "b"}, "default", "pod1", "svc1", "svc4"))
+	leader.delete("node2")
+	assert.Eventually(t, func() bool {
+		_, found := follower.get("node2")
+		return !found
+	}, 5*time.Second, 10*time.Millisecond)
+	metaBundle, found = follower.get("node1")
+	require.True(t, found)
+	services, _ = metaBundle.ServicesForPod("default", "pod1")
+	assert.ElementsMatch(t, []string{"svc1", "svc4"}, services)
+	assert.Equal(t, map[string]string{"zone": "b"}, metaBundle.Labels)
+
+	close(stop)
+	assert.NoError(t, <-streamed)
+	assert.Error(t, <-applied)
+	assert.Empty(t, leader.subscribers)
+}
+
+func TestMetadataReplicationNotLeader(t *testing.T) {
+	err := streamMetadata(newTestMetaBundleStore(), func() bool { return false }, nil, func(MetadataReplicationEvent) error {
+		return nil
+	})
+	assert.Equal(t, ErrNotLeader, err)
+}
+
+func TestMetaBundleStoreLaggingSubscriber(t *testing.T) {
+	store := newTestMetaBundleStore()
+	_, changes := store.subscribe()
+	for i := 0; i <= metaBundleSubscriberBufferSize; i++ {
+		store.set("node1", newMetadataMapperBundle())
+	}
+
+	// the subscriber is dropped once its buffer is full
+	received := 0
+	for range changes {
+		received++
+	}
+	assert.Equal(t, metaBundleSubscriberBufferSize, received)
+	assert.Empty(t, store.subscribers)
+	store.unsubscribe(changes)
+}
@@ -27,6 +27,7 @@ func (metaBundle *metadataMapperBundle) DeepCopy(old *metadataMapperBundle) *met
 		return metaBundle
 	}
 	metaBundle.Services = metaBundle.Services.DeepCopy(&old.Services)
+	metaBundle.Labels = old.Labels
 	metaBundle.mapOnIP = old.mapOnIP
 	return metaBundle
 }
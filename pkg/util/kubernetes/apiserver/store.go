@@ -8,6 +8,7 @@
 package apiserver
 
 import (
+	"strings"
 	"sync"
 
 	agentcache "github.com/DataDog/datadog-agent/pkg/util/cache"
@@ -31,8 +32,22 @@ type metaBundleStore struct {
 	// to delete items for nodes that were deleted in the apiserver to prevent data
 	// from going missing until the next resync period.
 	cache *cache.Cache
+
+	// subscribers are notified of the changes, to replicate them to the followers
+	subscribers map[chan metaBundleEvent]struct{}
+}
+
+// metaBundleEvent is a change of the meta bundle of a node, the bundle is nil when
+// the node is deleted
+type metaBundleEvent struct {
+	nodeName   string
+	metaBundle *metadataMapperBundle
 }
 
+// metaBundleSubscriberBufferSize is the number of changes a subscriber can lag behind before
+// it's dropped
+const metaBundleSubscriberBufferSize = 1000
+
 func (m *metaBundleStore) get(nodeName string) (*metadataMapperBundle, bool) {
 	cacheKey := agentcache.BuildAgentKey(metadataMapperCachePrefix, nodeName)
 
@@ -83,6 +98,7 @@ func (m *metaBundleStore) set(nodeName string, metaBundle *metadataMapperBundle)
 	defer m.mu.Unlock()
 
 	m.cache.Set(cacheKey, metaBundle, cache.NoExpiration)
+	m.notify(metaBundleEvent{nodeName: nodeName, metaBundle: metaBundle})
 }
 
 func (m *metaBundleStore) delete(nodeName string) {
@@ -92,4 +108,72 @@ func (m *metaBundleStore) delete(nodeName string) {
 	defer m.mu.Unlock()
 
 	m.cache.Delete(cacheKey)
+	m.notify(metaBundleEvent{nodeName: nodeName})
+}
+
+// subscribe returns the meta bundles of all the nodes and a channel receiving their changes
+// from then on. The bundles must not be modified. The channel is closed if the subscriber
+// can't keep up, it must then subscribe again.
+func (m *metaBundleStore) subscribe() (map[string]*metadataMapperBundle, chan metaBundleEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metaBundles := m.all()
+	ch := make(chan metaBundleEvent, metaBundleSubscriberBufferSize)
+	if m.subscribers == nil {
+		m.subscribers = make(map[chan metaBundleEvent]struct{})
+	}
+	m.subscribers[ch] = struct{}{}
+	return metaBundles, ch
+}
+
+// nodeNames returns the names of the nodes in the store
+func (m *metaBundleStore) nodeNames() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var nodeNames []string
+	for nodeName := range m.all() {
+		nodeNames = append(nodeNames, nodeName)
+	}
+	return nodeNames
+}
+
+// all returns the meta bundles of all the nodes, m.mu must be locked
+func (m *metaBundleStore) all() map[string]*metadataMapperBundle {
+	keyPrefix := agentcache.BuildAgentKey(metadataMapperCachePrefix) + "/"
+	metaBundles := make(map[string]*metadataMapperBundle)
+	for key, item := range m.cache.Items() {
+		if !strings.HasPrefix(key, keyPrefix) {
+			continue
+		}
+		if metaBundle, ok := item.Object.(*metadataMapperBundle); ok {
+			metaBundles[strings.TrimPrefix(key, keyPrefix)] = metaBundle
+		}
+	}
+	return metaBundles
+}
+
+// unsubscribe stops sending the changes to the channel
+func (m *metaBundleStore) unsubscribe(ch chan metaBundleEvent) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.subscribers[ch]; ok {
+		delete(m.subscribers, ch)
+		close(ch)
+	}
+}
+
+// notify sends a change to the subscribers, m.mu must be locked
+func (m *metaBundleStore) notify(event metaBundleEvent) {
+	for ch := range m.subscribers {
+		select {
+		case ch <- event:
+		default:
+			log.Warnf("Dropping a subscriber of the cluster metadata which is lagging behind, it will resync")
+			delete(m.subscribers, ch)
+			close(ch)
+		}
+	}
 }
@@ -30,4 +30,5 @@ const (
 	SecretsInformer   InformerName = "secrets"
 	WebhooksInformer  InformerName = "webhooks"
 	PodsInformer      InformerName = "pods"
+	DeploysInformer   InformerName = "deployments"
 )
@@ -54,6 +54,7 @@ func (suite *ContainersTestSuite) TestParseContainerInPod() {
 			Created:     1517487458,
 			State:       "running",
 			Health:      "healthy",
+			Owners:      []containers.ContainerOwner{{Kind: "DaemonSet", Name: "kube-scheduler"}},
 			AddressList: []containers.NetworkAddress{},
 		},
 		{
@@ -65,6 +66,7 @@ func (suite *ContainersTestSuite) TestParseContainerInPod() {
 			Created:  1517490715,
 			State:    "running",
 			Health:   "unhealthy",
+			Owners:   []containers.ContainerOwner{{Kind: "ReplicaSet", Name: "nginx-99d8b564"}},
 			AddressList: []containers.NetworkAddress{
 				{IP: net.ParseIP("192.168.128.141"), Port: 80, Protocol: "TCP"},
 				{IP: net.ParseIP("192.168.128.141"), Port: 443, Protocol: "TCP"},
@@ -90,6 +92,7 @@ func (suite *ContainersTestSuite) TestParseContainerInPod() {
 			Created:     1517487458,
 			State:       "running",
 			Health:      "healthy",
+			Owners:      []containers.ContainerOwner{{Kind: "DaemonSet", Name: "kube-proxy"}},
 			AddressList: []containers.NetworkAddress{},
 		},
 		{
@@ -101,6 +104,7 @@ func (suite *ContainersTestSuite) TestParseContainerInPod() {
 			Created:  1517501194,
 			State:    "running",
 			Health:   "healthy",
+			Owners:   []containers.ContainerOwner{{Kind: "ReplicaSet", Name: "redis-75586d7d7c"}},
 			AddressList: []containers.NetworkAddress{
 				{IP: net.ParseIP("172.17.0.3"), Port: 6379, Protocol: "TCP"},
 				{IP: net.ParseIP("192.168.128.141"), Port: 1337, Protocol: "TCP"},
@@ -126,6 +130,36 @@ func (suite *ContainersTestSuite) TestParseContainerInPod() {
 
 }
 
+func (suite *ContainersTestSuite) TestParseContainerRestartCountAndOwners() {
+	sourcePods, err := loadPodsFixture("./testdata/podlist_init_container_terminated.json")
+	require.Nil(suite.T(), err)
+	require.Len(suite.T(), sourcePods, 5)
+
+	restartCounts := make(map[string]int)
+	owners := make(map[string][]containers.ContainerOwner)
+	for _, pod := range sourcePods {
+		for _, c := range pod.Status.GetAllContainers() {
+			ctr, err := parseContainerInPod(c, pod)
+			require.Nil(suite.T(), err)
+			require.NotNil(suite.T(), ctr)
+			restartCounts[ctr.Name] = ctr.RestartCount
+			owners[ctr.Name] = ctr.Owners
+		}
+	}
+
+	assert.Equal(suite.T(), map[string]int{
+		"kube-scheduler-8mpwh-kube-scheduler":             5,
+		"coredns-747dbcf5df-qmtnl-coredns":                4,
+		"myapp-pod-init-myservice":                        0,
+		"myapp-pod-myapp-container":                       0,
+		"kube-proxy-fbv92-kube-proxy":                     0,
+		"kube-controller-manager-kube-controller-manager": 4,
+	}, restartCounts)
+	assert.Equal(suite.T(), []containers.ContainerOwner{{Kind: "ReplicaSet", Name: "coredns-747dbcf5df"}}, owners["coredns-747dbcf5df-qmtnl-coredns"])
+	assert.Equal(suite.T(), []containers.ContainerOwner{{Kind: "DaemonSet", Name: "kube-proxy"}}, owners["kube-proxy-fbv92-kube-proxy"])
+	assert.Nil(suite.T(), owners["myapp-pod-myapp-container"])
+}
+
 func (suite *ContainersTestSuite) TestParseContainerReadiness() {
 	sourcePods, err := loadPodsFixture("./testdata/podlist_1.8-1.json")
 	require.Nil(suite.T(), err)
@@ -127,6 +127,9 @@ func parseContainerInPod(status ContainerStatus, pod *Pod) (*containers.Containe
 		EntityID: entity,
 		Name:     fmt.Sprintf("%s-%s", pod.Metadata.Name, status.Name),
 		Image:    status.Image,
+
+		RestartCount: status.RestartCount,
+		Owners:       parseContainerOwners(pod),
 	}
 
 	switch {
@@ -153,6 +156,18 @@ func parseContainerInPod(status ContainerStatus, pod *Pod) (*containers.Containe
 	return c, nil
 }
 
+// parseContainerOwners returns the owner references of the pod of a container
+func parseContainerOwners(pod *Pod) []containers.ContainerOwner {
+	var owners []containers.ContainerOwner
+	for _, owner := range pod.Owners() {
+		if owner.Kind == "" {
+			continue
+		}
+		owners = append(owners, containers.ContainerOwner{Kind: owner.Kind, Name: owner.Name})
+	}
+	return owners
+}
+
 func parseContainerNetworkAddresses(status ContainerStatus, pod *Pod) []containers.NetworkAddress {
 	addrList := []containers.NetworkAddress{}
 	podIP := net.ParseIP(pod.Status.PodIP)
@@ -14,7 +14,7 @@ import (
 )
 
 func init() {
-	diagnosis.Register("Kubelet availability", diagnose)
+	diagnosis.RegisterMetadataAvailability("Kubelet availability", diagnose)
 }
 
 // diagnose the API server availability
@@ -22,6 +22,12 @@ func diagnose() error {
 	_, err := GetKubeUtil()
 	if err != nil {
 		log.Error(err)
+		if diagnosis.IsUnauthorized(err) {
+			if !isTokenPathConfigured() && !isCertificatesConfigured() {
+				return diagnosis.NewMissingCredentialsError("kubelet_auth_token_path or kubelet_client_crt and kubelet_client_key", err)
+			}
+			return diagnosis.NewMissingCredentialsError("permissions of the agent service account on the kubelet API", err)
+		}
 	}
 	return err
 }
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package profiling
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/config"
+	"github.com/DataDog/datadog-agent/pkg/version"
+)
+
+// IsEnabled returns whether the internal profiling of the agent processes is enabled
+func IsEnabled() bool {
+	return config.Datadog.GetBool("internal_profiling.enabled")
+}
+
+// ProfileURL returns the URL of the profiling intake, based on the configured site
+func ProfileURL() string {
+	// allow full url override for development use
+	if config.Datadog.IsSet("internal_profiling.profile_dd_url") {
+		return config.Datadog.GetString("internal_profiling.profile_dd_url")
+	}
+
+	site := config.DefaultSite
+	if config.Datadog.IsSet("site") {
+		site = config.Datadog.GetString("site")
+	}
+	return fmt.Sprintf(ProfileURLTemplate, site)
+}
+
+// StartFromConfig starts profiling the agent process identified by service with
+// the settings of the agent configuration. The profiles are tagged with the process
+// and the agent version.
+func StartFromConfig(service string) error {
+	v, _ := version.Agent()
+	return Start(
+		config.Datadog.GetString("api_key"),
+		ProfileURL(),
+		config.Datadog.GetString("env"),
+		service,
+		config.Datadog.GetDuration("internal_profiling.period"),
+		fmt.Sprintf("version:%v", v),
+		fmt.Sprintf("process:%s", service),
+	)
+}
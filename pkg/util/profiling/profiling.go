@@ -6,7 +6,9 @@
 package profiling
 
 import (
+	"runtime"
 	"sync"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 
@@ -23,6 +25,21 @@ const (
 	ProfileURLTemplate = "https://intake.profile.%s/v1/input"
 	// ProfileCoreService default service for the core agent profiler.
 	ProfileCoreService = "datadog-agent"
+	// ProfileDogstatsdService default service for the standalone dogstatsd profiler.
+	ProfileDogstatsdService = "datadog-dogstatsd"
+	// ProfileProcessService default service for the process agent profiler.
+	ProfileProcessService = "datadog-process-agent"
+	// ProfileTraceService default service for the trace agent profiler.
+	ProfileTraceService = "datadog-trace-agent"
+	// ProfileSystemProbeService default service for the system probe profiler.
+	ProfileSystemProbeService = "datadog-system-probe"
+	// ProfileSecurityService default service for the security agent profiler.
+	ProfileSecurityService = "datadog-security-agent"
+	// ProfileClusterService default service for the cluster agent profiler.
+	ProfileClusterService = "datadog-cluster-agent"
+
+	// mutexProfileFraction is the rate of mutex contention events reported in the mutex profile
+	mutexProfileFraction = 10
 )
 
 // Active returns a boolean indicating whether profiling is active or not;
@@ -36,7 +53,8 @@ func Active() bool {
 
 // Start initiates profiling with the supplied parameters;
 // this function is thread-safe.
-func Start(apiKey, site, env, service string, tags ...string) error {
+// The CPU, heap and mutex profiles are captured and submitted every period.
+func Start(apiKey, site, env, service string, period time.Duration, tags ...string) error {
 	if Active() {
 		return nil
 	}
@@ -46,6 +64,9 @@ func Start(apiKey, site, env, service string, tags ...string) error {
 		profiler.WithEnv(env),
 		profiler.WithService(service),
 		profiler.WithURL(site),
+		profiler.WithPeriod(period),
+		profiler.WithProfileTypes(profiler.CPUProfile, profiler.HeapProfile, profiler.MutexProfile),
+		profiler.MutexProfileFraction(mutexProfileFraction),
 		profiler.WithTags(tags...),
 	)
 	if err == nil {
@@ -66,6 +87,7 @@ func Stop() {
 		defer mu.Unlock()
 
 		profiler.Stop()
+		runtime.SetMutexProfileFraction(0)
 		running = false
 	}
 }
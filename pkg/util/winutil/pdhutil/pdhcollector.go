@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+// +build windows
+
+package pdhutil
+
+import (
+	"fmt"
+
+	"github.com/DataDog/datadog-agent/pkg/util/log"
+)
+
+// PdhCounterSpec describes a counter collected by a PdhCounterCollector.  The class
+// and counter names are the english ones, they are translated to the names of
+// the locale of the host when the counter is created
+type PdhCounterSpec struct {
+	ClassName   string
+	CounterName string
+	// MultiInstance is true when the class has instances, like Process or
+	// Web Service, false for single-instance classes like Memory
+	MultiInstance bool
+	// Instances are the names of the instances to collect, or wildcard patterns
+	// like w3wp*.  All the instances are collected when empty
+	Instances []string
+	// Verify is called for each new instance, see CounterInstanceVerify
+	Verify CounterInstanceVerify
+}
+
+// PdhCounterValueHandler is called by PdhCounterCollector.Collect for each value
+// collected, the instance is empty for single-instance counters
+type PdhCounterValueHandler func(spec PdhCounterSpec, instance string, value float64)
+
+// pdhCollectedCounter is a counter of a PdhCounterCollector, whose counter set is
+// nil until it could be created
+type pdhCollectedCounter struct {
+	spec   PdhCounterSpec
+	single *PdhSingleInstanceCounterSet
+	multi  *PdhMultiInstanceCounterSet
+}
+
+// PdhCounterCollector collects a list of counters, for the checks reporting
+// several counters of an application (IIS, .NET CLR, Exchange...).
+//
+// The counters whose class isn't registered yet, or which have no instance yet,
+// are retried on each collection, so that the applications started or installed
+// after the agent are picked up.  The instances appearing later are added to the
+// counters as they show up.
+type PdhCounterCollector struct {
+	counters []*pdhCollectedCounter
+}
+
+// NewPdhCounterCollector returns a collector for the given counters, they are
+// created on the first collection
+func NewPdhCounterCollector(specs []PdhCounterSpec) *PdhCounterCollector {
+	c := &PdhCounterCollector{}
+	for _, spec := range specs {
+		c.counters = append(c.counters, &pdhCollectedCounter{spec: spec})
+	}
+	return c
+}
+
+// Collect collects the values of the counters and passes them to the handler.
+// The counters which couldn't be created yet are skipped, the returned error
+// reports the counters which failed to collect.
+func (c *PdhCounterCollector) Collect(handler PdhCounterValueHandler) error {
+	var failed []string
+	for _, counter := range c.counters {
+		if !counter.initialized() {
+			if err := counter.initialize(); err != nil {
+				// the class or its instances may appear later, this is
+				// expected when the application isn't running
+				log.Debugf("Counter %s\\%s is not available yet: %v", counter.spec.ClassName, counter.spec.CounterName, err)
+				continue
+			}
+		}
+		if err := counter.collect(handler); err != nil {
+			log.Debugf("Failed to collect counter %s\\%s: %v", counter.spec.ClassName, counter.spec.CounterName, err)
+			failed = append(failed, counter.spec.ClassName+"\\"+counter.spec.CounterName)
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("Failed to collect the counters %v", failed)
+	}
+	return nil
+}
+
+// Close frees the queries of the counters
+func (c *PdhCounterCollector) Close() {
+	for _, counter := range c.counters {
+		if counter.single != nil {
+			counter.single.Close()
+			counter.single = nil
+		}
+		if counter.multi != nil {
+			counter.multi.Close()
+			counter.multi = nil
+		}
+	}
+}
+
+func (c *pdhCollectedCounter) initialized() bool {
+	return c.single != nil || c.multi != nil
+}
+
+func (c *pdhCollectedCounter) initialize() error {
+	var err error
+	if c.spec.MultiInstance {
+		c.multi, err = GetMultiInstanceCounter(c.spec.ClassName, c.spec.CounterName, &c.spec.Instances, c.spec.Verify)
+	} else {
+		c.single, err = GetSingleInstanceCounter(c.spec.ClassName, c.spec.CounterName)
+	}
+	return err
+}
+
+func (c *pdhCollectedCounter) collect(handler PdhCounterValueHandler) error {
+	if c.single != nil {
+		val, err := c.single.GetValue()
+		if err != nil {
+			return err
+		}
+		handler(c.spec, "", val)
+		return nil
+	}
+	values, err := c.multi.GetAllValues()
+	if err != nil {
+		return err
+	}
+	for inst, val := range values {
+		handler(c.spec, inst, val)
+	}
+	return nil
+}
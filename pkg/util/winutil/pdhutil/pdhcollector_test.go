@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+// +build windows
+
+package pdhutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the counters of the system corechecks tests
+var testfilesDir = filepath.Join("..", "..", "..", "collector", "corechecks", "system", "testfiles")
+
+func setupCollectorTesting() {
+	SetupTesting(filepath.Join(testfilesDir, "counter_indexes_en-us.txt"), filepath.Join(testfilesDir, "allcounters_en-us.txt"))
+}
+
+func TestMultiInstanceCounterWildcard(t *testing.T) {
+	setupCollectorTesting()
+	SetQueryReturnValue("\\\\.\\LogicalDisk(HarddiskVolume1)\\% Free Space", 1.5)
+	SetQueryReturnValue("\\\\.\\LogicalDisk(C:)\\% Free Space", 2.5)
+
+	counter, err := GetMultiInstanceCounter("LogicalDisk", "% Free Space", &[]string{"Harddisk*", "C:"}, nil)
+	require.NoError(t, err)
+	defer counter.Close()
+
+	values, err := counter.GetAllValues()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"HarddiskVolume1": 1.5, "C:": 2.5}, values)
+}
+
+func TestMultiInstanceCounterInvalidPattern(t *testing.T) {
+	setupCollectorTesting()
+	_, err := GetMultiInstanceCounter("LogicalDisk", "% Free Space", &[]string{"Harddisk["}, nil)
+	assert.Error(t, err)
+}
+
+func TestCounterCollectorInstancesAppearLater(t *testing.T) {
+	setupCollectorTesting()
+	instances := []string{"HarddiskVolume1", "C:", "_Total"}
+	for _, inst := range instances {
+		RemoveCounterInstance("LogicalDisk", inst)
+	}
+
+	collector := NewPdhCounterCollector([]PdhCounterSpec{
+		{ClassName: "Memory", CounterName: "Available Bytes"},
+		{ClassName: "LogicalDisk", CounterName: "% Free Space", MultiInstance: true, Instances: []string{"Harddisk*"}},
+	})
+	defer collector.Close()
+
+	collected := make(map[string]float64)
+	handler := func(spec PdhCounterSpec, instance string, value float64) {
+		collected[spec.CounterName+"/"+instance] = value
+	}
+
+	// the disks don't have instances yet, only the memory is collected
+	SetQueryReturnValue("\\\\.\\Memory\\Available Bytes", 1024)
+	require.NoError(t, collector.Collect(handler))
+	assert.Equal(t, map[string]float64{"Available Bytes/": 1024}, collected)
+
+	for _, inst := range instances {
+		AddCounterInstance("LogicalDisk", inst)
+	}
+	SetQueryReturnValue("\\\\.\\Memory\\Available Bytes", 2048)
+	SetQueryReturnValue("\\\\.\\LogicalDisk(HarddiskVolume1)\\% Free Space", 42)
+	require.NoError(t, collector.Collect(handler))
+	assert.Equal(t, map[string]float64{"Available Bytes/": 2048, "% Free Space/HarddiskVolume1": 42}, collected)
+}
@@ -8,6 +8,8 @@ package pdhutil
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
 	"github.com/DataDog/datadog-agent/pkg/util/log"
 )
@@ -50,6 +52,7 @@ type PdhMultiInstanceCounterSet struct {
 	PdhCounterSet
 	requestedCounterName string
 	requestedInstances   map[string]bool
+	requestedPatterns    []string                // wildcard patterns of the requested instances, like w3wp*
 	countermap           map[string]PDH_HCOUNTER // map instance name to counter handle
 	verifyfn             CounterInstanceVerify
 }
@@ -95,15 +98,18 @@ func GetSingleInstanceCounter(className, counterName string) (*PdhSingleInstance
 	// check to make sure this is really a single instance counter
 	allcounters, instances, _ := pfnPdhEnumObjectItems(p.className)
 	if len(instances) > 0 {
+		p.Close()
 		return nil, fmt.Errorf("Requested counter is not single-instance: %s", p.className)
 	}
 	path, err := p.MakeCounterPath("", counterName, "", allcounters)
 	if err != nil {
 		log.Warnf("Failed pdhEnumObjectItems %v", err)
+		p.Close()
 		return nil, err
 	}
 	winerror := pfnPdhAddCounter(p.query, path, uintptr(0), &p.singleCounter)
 	if ERROR_SUCCESS != winerror {
+		p.Close()
 		return nil, fmt.Errorf("Failed to add single counter %d", winerror)
 	}
 
@@ -125,16 +131,27 @@ func GetMultiInstanceCounter(className, counterName string, requestedInstances *
 	// check to make sure this is really a single instance counter
 	_, instances, _ := pfnPdhEnumObjectItems(p.className)
 	if len(instances) <= 0 {
+		p.Close()
 		return nil, fmt.Errorf("Requested counter is a single-instance: %s", p.className)
 	}
-	// save the requested instances
+	// save the requested instances, the ones with wildcards are matched against
+	// the instance names each time the instance list is refreshed
 	if requestedInstances != nil && len(*requestedInstances) > 0 {
 		p.requestedInstances = make(map[string]bool)
 		for _, inst := range *requestedInstances {
+			if isInstancePattern(inst) {
+				if _, err := path.Match(inst, ""); err != nil {
+					p.Close()
+					return nil, fmt.Errorf("Invalid instance pattern %s: %v", inst, err)
+				}
+				p.requestedPatterns = append(p.requestedPatterns, inst)
+				continue
+			}
 			p.requestedInstances[inst] = true
 		}
 	}
 	if err := p.MakeInstanceList(); err != nil {
+		p.Close()
 		return nil, err
 	}
 	return &p, nil
@@ -154,7 +171,7 @@ func (p *PdhMultiInstanceCounterSet) MakeInstanceList() error {
 		// they're here.  If not, add them to the list of instances to make
 		if p.requestedInstances != nil {
 			// if it's not in the requestedInstances, don't bother
-			if !p.requestedInstances[actualInstance] {
+			if !p.isRequestedInstance(actualInstance) {
 				continue
 			}
 			// ok.  it was requested.  If it's not in our map
@@ -201,6 +218,25 @@ func (p *PdhMultiInstanceCounterSet) MakeInstanceList() error {
 	return nil
 }
 
+// isRequestedInstance returns whether the instance was requested, by its name
+// or by one of the wildcard patterns
+func (p *PdhMultiInstanceCounterSet) isRequestedInstance(instance string) bool {
+	if p.requestedInstances[instance] {
+		return true
+	}
+	for _, pattern := range p.requestedPatterns {
+		if matched, _ := path.Match(pattern, instance); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isInstancePattern returns whether a requested instance is a wildcard pattern
+func isInstancePattern(instance string) bool {
+	return strings.ContainsAny(instance, "*?[")
+}
+
 //RemoveInvalidInstance removes an instance from the counter that is no longer valid
 func (p *PdhMultiInstanceCounterSet) RemoveInvalidInstance(badInstance string) {
 	hc := p.countermap[badInstance]
@@ -301,7 +337,7 @@ func (p *PdhSingleInstanceCounterSet) GetValue() (val float64, err error) {
 
 // Close closes the query handle, freeing the underlying windows resources.
 func (p *PdhCounterSet) Close() {
-	PdhCloseQuery(p.query)
+	pfnPdhCloseQuery(p.query)
 }
 
 func getCounterIndexList(cname string) ([]int, error) {
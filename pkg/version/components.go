@@ -0,0 +1,33 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package version
+
+import "sort"
+
+// components holds the optional components compiled in the binary. Each component is
+// selected at compile time by the build tag of the same name, and registered by the
+// file of this package built with that tag.
+var components = map[string]struct{}{}
+
+func registerComponent(name string) {
+	components[name] = struct{}{}
+}
+
+// Components returns the sorted list of the optional components compiled in the binary
+func Components() []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HasComponent returns whether the optional component is compiled in the binary
+func HasComponent(name string) bool {
+	_, found := components[name]
+	return found
+}
@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016-2020 Datadog, Inc.
+
+package version
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponents(t *testing.T) {
+	defer func(c map[string]struct{}) { components = c }(components)
+	components = map[string]struct{}{}
+
+	registerComponent("python")
+	registerComponent("apm")
+
+	assert.Equal(t, []string{"apm", "python"}, Components())
+	assert.True(t, HasComponent("python"))
+	assert.False(t, HasComponent("jmx"))
+}
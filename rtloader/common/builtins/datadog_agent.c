@@ -17,10 +17,12 @@ static cb_tracemalloc_enabled_t cb_tracemalloc_enabled = NULL;
 static cb_get_version_t cb_get_version = NULL;
 static cb_headers_t cb_headers = NULL;
 static cb_set_check_metadata_t cb_set_check_metadata = NULL;
+static cb_set_check_diagnoses_t cb_set_check_diagnoses = NULL;
 static cb_set_external_tags_t cb_set_external_tags = NULL;
 static cb_write_persistent_cache_t cb_write_persistent_cache = NULL;
 static cb_read_persistent_cache_t cb_read_persistent_cache = NULL;
 static cb_obfuscate_sql_t cb_obfuscate_sql = NULL;
+static cb_obfuscate_sql_exec_plan_t cb_obfuscate_sql_exec_plan = NULL;
 
 // forward declarations
 static PyObject *get_clustername(PyObject *self, PyObject *args);
@@ -31,10 +33,12 @@ static PyObject *get_version(PyObject *self, PyObject *args);
 static PyObject *headers(PyObject *self, PyObject *args, PyObject *kwargs);
 static PyObject *log_message(PyObject *self, PyObject *args);
 static PyObject *set_check_metadata(PyObject *self, PyObject *args);
+static PyObject *set_check_diagnoses(PyObject *self, PyObject *args);
 static PyObject *set_external_tags(PyObject *self, PyObject *args);
 static PyObject *write_persistent_cache(PyObject *self, PyObject *args);
 static PyObject *read_persistent_cache(PyObject *self, PyObject *args);
 static PyObject *obfuscate_sql(PyObject *self, PyObject *args);
+static PyObject *obfuscate_sql_exec_plan(PyObject *self, PyObject *args);
 
 static PyMethodDef methods[] = {
     { "get_clustername", get_clustername, METH_NOARGS, "Get the cluster name." },
@@ -45,10 +49,13 @@ static PyMethodDef methods[] = {
     { "headers", (PyCFunction)headers, METH_VARARGS | METH_KEYWORDS, "Get standard set of HTTP headers." },
     { "log", log_message, METH_VARARGS, "Log a message through the agent logger." },
     { "set_check_metadata", set_check_metadata, METH_VARARGS, "Send metadata for Checks." },
+    { "set_check_diagnoses", set_check_diagnoses, METH_VARARGS, "Send diagnoses for Checks." },
     { "set_external_tags", set_external_tags, METH_VARARGS, "Send external host tags." },
     { "write_persistent_cache", write_persistent_cache, METH_VARARGS, "Store a value for a given key." },
     { "read_persistent_cache", read_persistent_cache, METH_VARARGS, "Retrieve the value associated with a key." },
     { "obfuscate_sql", (PyCFunction)obfuscate_sql, METH_VARARGS, "Obfuscate & normalize a SQL string." },
+    { "obfuscate_sql_exec_plan", (PyCFunction)obfuscate_sql_exec_plan, METH_VARARGS,
+      "Obfuscate & normalize a JSON SQL execution plan." },
     { NULL, NULL } // guards
 };
 
@@ -99,6 +106,11 @@ void _set_set_check_metadata_cb(cb_set_check_metadata_t cb)
     cb_set_check_metadata = cb;
 }
 
+void _set_set_check_diagnoses_cb(cb_set_check_diagnoses_t cb)
+{
+    cb_set_check_diagnoses = cb;
+}
+
 void _set_write_persistent_cache_cb(cb_write_persistent_cache_t cb)
 {
     cb_write_persistent_cache = cb;
@@ -124,6 +136,11 @@ void _set_obfuscate_sql_cb(cb_obfuscate_sql_t cb)
     cb_obfuscate_sql = cb;
 }
 
+void _set_obfuscate_sql_exec_plan_cb(cb_obfuscate_sql_exec_plan_t cb)
+{
+    cb_obfuscate_sql_exec_plan = cb;
+}
+
 /*! \fn PyObject *get_version(PyObject *self, PyObject *args)
     \brief This function implements the `datadog-agent.get_version` method, collecting
     the agent version from the agent.
@@ -422,6 +439,41 @@ static PyObject *set_check_metadata(PyObject *self, PyObject *args)
     Py_RETURN_NONE;
 }
 
+/*! \fn PyObject *set_check_diagnoses(PyObject *self, PyObject *args)
+    \brief This function implements the `datadog_agent.set_check_diagnoses` method, replacing
+    the diagnoses of a check instance shown in the agent status and the flare.
+    \param self A PyObject* pointer to the `datadog_agent` module.
+    \param args A PyObject* pointer to a 2-ary tuple containing the unique ID of a check
+    instance and the JSON list of its diagnoses.
+    \return A PyObject* pointer to `None`.
+
+    This function is callable as the `datadog_agent.set_check_diagnoses` Python method and
+    uses the `cb_set_check_diagnoses()` callback to send the diagnoses to the agent with CGO.
+    If the callback has not been set `None` will be returned.
+*/
+static PyObject *set_check_diagnoses(PyObject *self, PyObject *args)
+{
+    // callback must be set
+    if (cb_set_check_diagnoses == NULL) {
+        Py_RETURN_NONE;
+    }
+
+    char *check_id, *diagnoses;
+
+    PyGILState_STATE gstate = PyGILState_Ensure();
+
+    // datadog_agent.set_check_diagnoses(check_id, diagnoses)
+    if (!PyArg_ParseTuple(args, "ss", &check_id, &diagnoses)) {
+        PyGILState_Release(gstate);
+        return NULL;
+    }
+
+    PyGILState_Release(gstate);
+    cb_set_check_diagnoses(check_id, diagnoses);
+
+    Py_RETURN_NONE;
+}
+
 /*! \fn PyObject *write_persistent_cache(PyObject *self, PyObject *args)
     \brief This function implements the `datadog_agent.write_persistent_cache` method, storing
     the value for the key.
@@ -707,3 +759,51 @@ static PyObject *obfuscate_sql(PyObject *self, PyObject *args)
     PyGILState_Release(gstate);
     return retval;
 }
+
+/*! \fn PyObject *obfuscate_sql_exec_plan(PyObject *self, PyObject *args)
+    \brief This function implements the `datadog_agent.obfuscate_sql_exec_plan` method, obfuscating
+    the provided JSON execution plan.
+    \param self A PyObject* pointer to the `datadog_agent` module.
+    \param args A PyObject* pointer to a tuple containing the JSON plan and, optionally, whether
+    the costs and the rows estimates should be removed from the plan.
+    \return A PyObject* pointer to the obfuscated plan.
+
+    This function is callable as the `datadog_agent.obfuscate_sql_exec_plan` Python method and
+    uses the `cb_obfuscate_sql_exec_plan()` callback to retrieve the value from the agent
+    with CGO. If the callback has not been set `None` will be returned.
+*/
+static PyObject *obfuscate_sql_exec_plan(PyObject *self, PyObject *args)
+{
+    // callback must be set
+    if (cb_obfuscate_sql_exec_plan == NULL) {
+        Py_RETURN_NONE;
+    }
+
+    PyGILState_STATE gstate = PyGILState_Ensure();
+
+    char *rawPlan;
+    int normalize = 0;
+    if (!PyArg_ParseTuple(args, "s|i", &rawPlan, &normalize)) {
+        PyGILState_Release(gstate);
+        return NULL;
+    }
+
+    char *obfPlan = NULL;
+    char *error_message = NULL;
+    obfPlan = cb_obfuscate_sql_exec_plan(rawPlan, normalize != 0, &error_message);
+
+    PyObject *retval = NULL;
+    if (error_message != NULL) {
+        PyErr_SetString(PyExc_RuntimeError, error_message);
+    } else if (obfPlan == NULL) {
+        // no error message and a null response. this should never happen so the go code is misbehaving
+        PyErr_SetString(PyExc_RuntimeError, "internal error: empty cb_obfuscate_sql_exec_plan response");
+    } else {
+        retval = PyStringFromCString(obfPlan);
+    }
+
+    cgo_free(error_message);
+    cgo_free(obfPlan);
+    PyGILState_Release(gstate);
+    return retval;
+}
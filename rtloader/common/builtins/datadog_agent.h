@@ -82,6 +82,14 @@
 
     The callback is expected to be provided by the rtloader caller - in go-context: CGO.
 */
+/*! \fn void _set_set_check_diagnoses_cb(cb_set_check_diagnoses_t)
+    \brief Sets a callback to be used by rtloader to allow setting the diagnoses of a given
+    check instance.
+    \param object A function pointer with cb_set_check_diagnoses_t prototype to the callback
+    function.
+
+    The callback is expected to be provided by the rtloader caller - in go-context: CGO.
+*/
 /*! \fn void _set_set_external_tags_cb(cb_set_external_tags_t)
     \brief Sets a callback to be used by rtloader to allow setting external tags for a given
     hostname.
@@ -143,10 +151,12 @@ void _set_get_version_cb(cb_get_version_t);
 void _set_headers_cb(cb_headers_t);
 void _set_log_cb(cb_log_t);
 void _set_set_check_metadata_cb(cb_set_check_metadata_t);
+void _set_set_check_diagnoses_cb(cb_set_check_diagnoses_t);
 void _set_set_external_tags_cb(cb_set_external_tags_t);
 void _set_write_persistent_cache_cb(cb_write_persistent_cache_t);
 void _set_read_persistent_cache_cb(cb_read_persistent_cache_t);
 void _set_obfuscate_sql_cb(cb_obfuscate_sql_t);
+void _set_obfuscate_sql_exec_plan_cb(cb_obfuscate_sql_exec_plan_t);
 
 PyObject *_public_headers(PyObject *self, PyObject *args, PyObject *kwargs);
 
@@ -385,6 +385,15 @@ DATADOG_AGENT_RTLOADER_API void set_submit_event_cb(rtloader_t *, cb_submit_even
 */
 DATADOG_AGENT_RTLOADER_API void set_submit_histogram_bucket_cb(rtloader_t *, cb_submit_histogram_bucket_t);
 
+/*! \fn void set_submit_event_platform_event_cb(rtloader_t *, cb_submit_event_platform_event_t)
+    \brief Sets the submit event platform event callback to be used by rtloader for event platform event submission.
+    \param cb A function pointer with cb_submit_event_platform_event_t prototype to the callback
+    function.
+
+    The callback is expected to be provided by the rtloader caller - in go-context: CGO.
+*/
+DATADOG_AGENT_RTLOADER_API void set_submit_event_platform_event_cb(rtloader_t *, cb_submit_event_platform_event_t);
+
 // DATADOG_AGENT API
 /*! \fn void set_get_version_cb(rtloader_t *, cb_get_version_t)
     \brief Sets a callback to be used by rtloader to collect the agent version.
@@ -472,6 +481,17 @@ DATADOG_AGENT_RTLOADER_API void set_log_cb(rtloader_t *, cb_log_t);
 */
 DATADOG_AGENT_RTLOADER_API void set_set_check_metadata_cb(rtloader_t *, cb_set_check_metadata_t);
 
+/*! \fn void set_set_check_diagnoses_cb(rtloader_t *, cb_set_check_diagnoses_t)
+    \brief Sets a callback to be used by rtloader to allow setting the diagnoses of a given
+    check instance.
+    \param rtloader_t A rtloader_t * pointer to the RtLoader instance.
+    \param object A function pointer with cb_set_check_diagnoses_t prototype to the callback
+    function.
+
+    The callback is expected to be provided by the rtloader caller - in go-context: CGO.
+*/
+DATADOG_AGENT_RTLOADER_API void set_set_check_diagnoses_cb(rtloader_t *, cb_set_check_diagnoses_t);
+
 /*! \fn void set_set_external_tags_cb(rtloader_t *, cb_set_external_tags_t)
     \brief Sets a callback to be used by rtloader to allow setting external tags for a given
     hostname.
@@ -581,6 +601,17 @@ DATADOG_AGENT_RTLOADER_API void set_read_persistent_cache_cb(rtloader_t *, cb_re
 */
 DATADOG_AGENT_RTLOADER_API void set_obfuscate_sql_cb(rtloader_t *, cb_obfuscate_sql_t);
 
+/*! \fn void set_obfuscate_sql_exec_plan_cb(rtloader_t *, cb_obfuscate_sql_exec_plan_t)
+    \brief Sets a callback to be used by rtloader to allow obfuscating the execution plans
+    collected by the database checks.
+    \param rtloader_t A rtloader_t * pointer to the RtLoader instance.
+    \param object A function pointer with cb_obfuscate_sql_exec_plan_t prototype to the callback
+    function.
+
+    The callback is expected to be provided by the rtloader caller - in go-context: CGO.
+*/
+DATADOG_AGENT_RTLOADER_API void set_obfuscate_sql_exec_plan_cb(rtloader_t *, cb_obfuscate_sql_exec_plan_t);
+
 #ifdef __cplusplus
 }
 #endif
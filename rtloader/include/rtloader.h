@@ -276,6 +276,14 @@ public:
     */
     virtual void setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t) = 0;
 
+    //! setSubmitEventPlatformEventCb member.
+    /*!
+      \param A cb_submit_event_platform_event_t function pointer to the CGO callback.
+
+      Actual event platform events are submitted from go-land, this allows us to set the CGO callback.
+    */
+    virtual void setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t) = 0;
+
     // datadog_agent API
 
     //! setGetVersionCb member.
@@ -348,6 +356,15 @@ public:
     */
     virtual void setSetCheckMetadataCb(cb_set_check_metadata_t) = 0;
 
+    //! setCheckDiagnosesCb member.
+    /*!
+      \param A cb_set_check_diagnoses_t function pointer to the CGO callback.
+
+      This allows us to set the relevant CGO callback that will allow replacing the diagnoses
+      of specific check instances shown in the agent status and the flare.
+    */
+    virtual void setSetCheckDiagnosesCb(cb_set_check_diagnoses_t) = 0;
+
     //! setExternalTagsCb member.
     /*!
       \param A cb_set_external_tags_t function pointer to the CGO callback.
@@ -436,6 +453,15 @@ public:
     */
     virtual void setObfuscateSqlCb(cb_obfuscate_sql_t) = 0;
 
+    //! setObfuscateSqlExecPlanCb member.
+    /*!
+      \param A cb_obfuscate_sql_exec_plan_t function pointer to the CGO callback.
+
+      This allows us to set the relevant CGO callback that will allow obfuscating the
+      execution plans collected by the database checks.
+    */
+    virtual void setObfuscateSqlExecPlanCb(cb_obfuscate_sql_exec_plan_t) = 0;
+
 private:
     mutable std::string _error; /*!< string containing a RtLoader error */
     mutable bool _errorFlag; /*!< boolean indicating whether an error was set on RtLoader */
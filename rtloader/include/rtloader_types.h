@@ -100,6 +100,8 @@ typedef void (*cb_submit_service_check_t)(char *, char *, int, char **, char *,
 typedef void (*cb_submit_event_t)(char *, event_t *);
 // (id, metric_name, value, lower_bound, upper_bound, monotonic, hostname, tags)
 typedef void (*cb_submit_histogram_bucket_t)(char *, char *, long long, float, float, int, char *, char **);
+// (id, raw_event, event_type)
+typedef void (*cb_submit_event_platform_event_t)(char *, char *, char *);
 
 // datadog_agent
 //
@@ -119,6 +121,8 @@ typedef bool (*cb_tracemalloc_enabled_t)(void);
 typedef void (*cb_log_t)(char *, int);
 // (check_id, name, value)
 typedef void (*cb_set_check_metadata_t)(char *, char *, char *);
+// (check_id, diagnoses)
+typedef void (*cb_set_check_diagnoses_t)(char *, char *);
 // (hostname, source_type_name, list of tags)
 typedef void (*cb_set_external_tags_t)(char *, char *, char **);
 // (key, value)
@@ -127,6 +131,8 @@ typedef void (*cb_write_persistent_cache_t)(char *, char *);
 typedef char *(*cb_read_persistent_cache_t)(char *);
 // (sql_query, error_message)
 typedef char *(*cb_obfuscate_sql_t)(char *, char **);
+// (exec_plan, normalize, error_message)
+typedef char *(*cb_obfuscate_sql_exec_plan_t)(char *, bool, char **);
 
 // _util
 // (argv, argc, raise, stdout, stderr, ret_code, exception)
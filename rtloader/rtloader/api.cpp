@@ -382,6 +382,11 @@ void set_submit_histogram_bucket_cb(rtloader_t *rtloader, cb_submit_histogram_bu
     AS_TYPE(RtLoader, rtloader)->setSubmitHistogramBucketCb(cb);
 }
 
+void set_submit_event_platform_event_cb(rtloader_t *rtloader, cb_submit_event_platform_event_t cb)
+{
+    AS_TYPE(RtLoader, rtloader)->setSubmitEventPlatformEventCb(cb);
+}
+
 /*
  * datadog_agent API
  */
@@ -426,6 +431,11 @@ void set_set_check_metadata_cb(rtloader_t *rtloader, cb_set_check_metadata_t cb)
     AS_TYPE(RtLoader, rtloader)->setSetCheckMetadataCb(cb);
 }
 
+void set_set_check_diagnoses_cb(rtloader_t *rtloader, cb_set_check_diagnoses_t cb)
+{
+    AS_TYPE(RtLoader, rtloader)->setSetCheckDiagnosesCb(cb);
+}
+
 void set_set_external_tags_cb(rtloader_t *rtloader, cb_set_external_tags_t cb)
 {
     AS_TYPE(RtLoader, rtloader)->setSetExternalTagsCb(cb);
@@ -456,6 +466,11 @@ void set_obfuscate_sql_cb(rtloader_t *rtloader, cb_obfuscate_sql_t cb)
     AS_TYPE(RtLoader, rtloader)->setObfuscateSqlCb(cb);
 }
 
+void set_obfuscate_sql_exec_plan_cb(rtloader_t *rtloader, cb_obfuscate_sql_exec_plan_t cb)
+{
+    AS_TYPE(RtLoader, rtloader)->setObfuscateSqlExecPlanCb(cb);
+}
+
 /*
  * _util API
  */
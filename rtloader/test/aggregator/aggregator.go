@@ -21,12 +21,14 @@ extern void submitMetric(char *, metric_type_t, char *, float, char **, char *);
 extern void submitServiceCheck(char *, char *, int, char **, char *, char *);
 extern void submitEvent(char*, event_t*);
 extern void submitHistogramBucket(char *, char *, long long, float, float, int, char *, char **);
+extern void submitEventPlatformEvent(char *, char *, char *);
 
 static void initAggregatorTests(rtloader_t *rtloader) {
    set_submit_metric_cb(rtloader, submitMetric);
    set_submit_service_check_cb(rtloader, submitServiceCheck);
    set_submit_event_cb(rtloader, submitEvent);
    set_submit_histogram_bucket_cb(rtloader, submitHistogramBucket);
+   set_submit_event_platform_event_cb(rtloader, submitEventPlatformEvent);
 }
 */
 import "C"
@@ -47,6 +49,8 @@ var (
 	lowerBound float64
 	upperBound float64
 	monotonic  bool
+	rawEvent   string
+	eventType  string
 )
 
 type event struct {
@@ -77,6 +81,8 @@ func resetOuputValues() {
 	lowerBound = 1.0
 	upperBound = 1.0
 	monotonic = false
+	rawEvent = ""
+	eventType = ""
 }
 
 func setUp() error {
@@ -223,3 +229,10 @@ func submitHistogramBucket(id *C.char, cMetricName *C.char, cVal C.longlong, cLo
 		tags = append(tags, charArrayToSlice(t)...)
 	}
 }
+
+//export submitEventPlatformEvent
+func submitEventPlatformEvent(id *C.char, cRawEvent *C.char, cEventType *C.char) {
+	checkID = C.GoString(id)
+	rawEvent = C.GoString(cRawEvent)
+	eventType = C.GoString(cEventType)
+}
@@ -25,10 +25,12 @@ extern bool getTracemallocEnabled();
 extern void getVersion(char **);
 extern void headers(char **);
 extern void setCheckMetadata(char*, char*, char*);
+extern void setCheckDiagnoses(char*, char*);
 extern void setExternalHostTags(char*, char*, char**);
 extern void writePersistentCache(char*, char*);
 extern char* readPersistentCache(char*);
 extern char* obfuscateSQL(char*, char**);
+extern char* obfuscateSQLExecPlan(char*, bool, char**);
 
 
 static void initDatadogAgentTests(rtloader_t *rtloader) {
@@ -41,10 +43,12 @@ static void initDatadogAgentTests(rtloader_t *rtloader) {
    set_headers_cb(rtloader, headers);
    set_log_cb(rtloader, doLog);
    set_set_check_metadata_cb(rtloader, setCheckMetadata);
+   set_set_check_diagnoses_cb(rtloader, setCheckDiagnoses);
    set_set_external_tags_cb(rtloader, setExternalHostTags);
    set_write_persistent_cache_cb(rtloader, writePersistentCache);
    set_read_persistent_cache_cb(rtloader, readPersistentCache);
    set_obfuscate_sql_cb(rtloader, obfuscateSQL);
+   set_obfuscate_sql_exec_plan_cb(rtloader, obfuscateSQLExecPlan);
 }
 */
 import "C"
@@ -187,6 +191,17 @@ func setCheckMetadata(checkID, name, value *C.char) {
 	f.WriteString(strings.Join([]string{cid, key, val}, ","))
 }
 
+//export setCheckDiagnoses
+func setCheckDiagnoses(checkID, diagnoses *C.char) {
+	cid := C.GoString(checkID)
+	d := C.GoString(diagnoses)
+
+	f, _ := os.OpenFile(tmpfile.Name(), os.O_APPEND|os.O_RDWR|os.O_CREATE, 0666)
+	defer f.Close()
+
+	f.WriteString(strings.Join([]string{cid, d}, ","))
+}
+
 //export setExternalHostTags
 func setExternalHostTags(hostname *C.char, sourceType *C.char, tags **C.char) {
 	hname := C.GoString(hostname)
@@ -247,3 +262,21 @@ func obfuscateSQL(rawQuery *C.char, errResult **C.char) *C.char {
 		return nil
 	}
 }
+
+//export obfuscateSQLExecPlan
+func obfuscateSQLExecPlan(rawPlan *C.char, normalize C.bool, errResult **C.char) *C.char {
+	switch C.GoString(rawPlan) {
+	case `{"Plan":{"Filter":"(id = 1)","Total Cost":1}}`:
+		if bool(normalize) {
+			return (*C.char)(helpers.TrackedCString(`{"Plan":{"Filter":"( id = ? )"}}`))
+		}
+		return (*C.char)(helpers.TrackedCString(`{"Plan":{"Filter":"( id = ? )","Total Cost":1}}`))
+	// expected error results from obfuscator
+	case "":
+		*errResult = (*C.char)(helpers.TrackedCString("result is empty"))
+		return nil
+	default:
+		*errResult = (*C.char)(helpers.TrackedCString("unknown test case"))
+		return nil
+	}
+}
@@ -186,6 +186,19 @@ func TestSetCheckMetadata(t *testing.T) {
 	}
 }
 
+func TestSetCheckDiagnoses(t *testing.T) {
+	code := `
+	datadog_agent.set_check_diagnoses("redis:test:12345", '[{"name": "auth", "severity": "error"}]')
+	`
+	out, err := run(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != `redis:test:12345,[{"name": "auth", "severity": "error"}]` {
+		t.Errorf("Unexpected printed value: '%s'", out)
+	}
+}
+
 func TestSetExternalTags(t *testing.T) {
 	// Reset memory counters
 	helpers.ResetMemoryStats()
@@ -495,3 +508,53 @@ func TestObfuscateSQLErrors(t *testing.T) {
 
 	helpers.AssertMemoryUsage(t)
 }
+
+func TestObfuscateSQLExecPlan(t *testing.T) {
+	helpers.ResetMemoryStats()
+
+	testCases := []struct {
+		args     string
+		expected string
+	}{
+		{`'{"Plan":{"Filter":"(id = 1)","Total Cost":1}}'`, `{"Plan":{"Filter":"( id = ? )","Total Cost":1}}`},
+		{`'{"Plan":{"Filter":"(id = 1)","Total Cost":1}}', True`, `{"Plan":{"Filter":"( id = ? )"}}`},
+	}
+
+	for _, c := range testCases {
+		code := fmt.Sprintf(`
+	result = datadog_agent.obfuscate_sql_exec_plan(%s)
+	with open(r'%s', 'w') as f:
+		f.write(str(result))
+	`, c.args, tmpfile.Name())
+		out, err := run(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != c.expected {
+			t.Fatalf("expected: '%s', found: '%s'", c.expected, out)
+		}
+	}
+
+	helpers.AssertMemoryUsage(t)
+}
+
+func TestObfuscateSQLExecPlanErrors(t *testing.T) {
+	helpers.ResetMemoryStats()
+
+	code := fmt.Sprintf(`
+	try:
+		result = datadog_agent.obfuscate_sql_exec_plan("")
+	except Exception as e:
+		with open(r'%s', 'w') as f:
+			f.write(str(e))
+	`, tmpfile.Name())
+	out, err := run(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "result is empty" {
+		t.Fatalf("expected: 'result is empty', found: '%s'", out)
+	}
+
+	helpers.AssertMemoryUsage(t)
+}
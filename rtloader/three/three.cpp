@@ -806,6 +806,11 @@ void Three::setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t cb)
     _set_submit_histogram_bucket_cb(cb);
 }
 
+void Three::setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t cb)
+{
+    _set_submit_event_platform_event_cb(cb);
+}
+
 void Three::setGetVersionCb(cb_get_version_t cb)
 {
     _set_get_version_cb(cb);
@@ -846,6 +851,11 @@ void Three::setSetCheckMetadataCb(cb_set_check_metadata_t cb)
     _set_set_check_metadata_cb(cb);
 }
 
+void Three::setSetCheckDiagnosesCb(cb_set_check_diagnoses_t cb)
+{
+    _set_set_check_diagnoses_cb(cb);
+}
+
 void Three::setSetExternalTagsCb(cb_set_external_tags_t cb)
 {
     _set_set_external_tags_cb(cb);
@@ -891,6 +901,11 @@ void Three::setObfuscateSqlCb(cb_obfuscate_sql_t cb)
     _set_obfuscate_sql_cb(cb);
 }
 
+void Three::setObfuscateSqlExecPlanCb(cb_obfuscate_sql_exec_plan_t cb)
+{
+    _set_obfuscate_sql_exec_plan_cb(cb);
+}
+
 // Python Helpers
 
 // get_integration_list return a list of every datadog's wheels installed.
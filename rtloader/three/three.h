@@ -90,6 +90,7 @@ public:
     void setSubmitServiceCheckCb(cb_submit_service_check_t);
     void setSubmitEventCb(cb_submit_event_t);
     void setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t);
+    void setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t);
 
     // datadog_agent API
     void setGetVersionCb(cb_get_version_t);
@@ -100,10 +101,12 @@ public:
     void setGetTracemallocEnabledCb(cb_tracemalloc_enabled_t);
     void setLogCb(cb_log_t);
     void setSetCheckMetadataCb(cb_set_check_metadata_t);
+    void setSetCheckDiagnosesCb(cb_set_check_diagnoses_t);
     void setSetExternalTagsCb(cb_set_external_tags_t);
     void setWritePersistentCacheCb(cb_write_persistent_cache_t);
     void setReadPersistentCacheCb(cb_read_persistent_cache_t);
     void setObfuscateSqlCb(cb_obfuscate_sql_t);
+    void setObfuscateSqlExecPlanCb(cb_obfuscate_sql_exec_plan_t);
 
     // _util API
     virtual void setSubprocessOutputCb(cb_get_subprocess_output_t);
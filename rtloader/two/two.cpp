@@ -803,6 +803,11 @@ void Two::setSubmitHistogramBucketCb(cb_submit_histogram_bucket_t cb)
     _set_submit_histogram_bucket_cb(cb);
 }
 
+void Two::setSubmitEventPlatformEventCb(cb_submit_event_platform_event_t cb)
+{
+    _set_submit_event_platform_event_cb(cb);
+}
+
 void Two::setGetVersionCb(cb_get_version_t cb)
 {
     _set_get_version_cb(cb);
@@ -843,6 +848,11 @@ void Two::setSetCheckMetadataCb(cb_set_check_metadata_t cb)
     _set_set_check_metadata_cb(cb);
 }
 
+void Two::setSetCheckDiagnosesCb(cb_set_check_diagnoses_t cb)
+{
+    _set_set_check_diagnoses_cb(cb);
+}
+
 void Two::setSetExternalTagsCb(cb_set_external_tags_t cb)
 {
     _set_set_external_tags_cb(cb);
@@ -888,6 +898,11 @@ void Two::setObfuscateSqlCb(cb_obfuscate_sql_t cb)
     _set_obfuscate_sql_cb(cb);
 }
 
+void Two::setObfuscateSqlExecPlanCb(cb_obfuscate_sql_exec_plan_t cb)
+{
+    _set_obfuscate_sql_exec_plan_cb(cb);
+}
+
 // Python Helpers
 
 // get_integration_list return a list of every datadog's wheels installed.